@@ -1,18 +1,28 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"net/http"
 
 	logging "github.com/op/go-logging"
 	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	litecoin "github.com/skycoin/skycoin-exchange/src/coin/litecoin"
 	"github.com/skycoin/skycoin-exchange/src/coin/mzcoin"
 	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
 	"github.com/skycoin/skycoin-exchange/src/server"
+	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/skycoin/skycoin-exchange/src/sklog"
 	"github.com/skycoin/skycoin/src/cipher"
 )
 
@@ -24,9 +34,12 @@ var (
 		"exchange.main",
 		"exchange.server",
 		"exchange.account",
+		"exchange.order",
 		"exchange.api",
+		"exchange.router",
 		"exchange.bitcoin",
 		"exchange.skycoin",
+		"exchange.litecoin",
 		"exchange.gin",
 	}
 )
@@ -34,15 +47,47 @@ var (
 func registerFlags(cfg *server.Config) {
 	flag.StringVar(&cfg.Server, "server", "127.0.0.1", "server ip")
 	flag.IntVar(&cfg.Port, "port", 8080, "server listen port")
+	flag.IntVar(&cfg.StreamPort, "stream-port", 8081, "websocket port of order book stream, 0 disables the stream")
 	flag.IntVar(&cfg.BtcFee, "btc-fee", 10000, "transaction fee in satoish")
-	flag.StringVar(&cfg.DataDir, "data-dir", ".skycoin-exchange", "data directory")
+	flag.Uint64Var(&cfg.BtcFeeRate, "btc-fee-rate", bitcoin.FeeRate, "default withdrawal fee rate in satoshis per vbyte, 0 uses btc-fee")
+	flag.StringVar(&cfg.DataDir, "data-dir", ".skycoin-exchange", "data directory, relative to the home directory if it's not absolute")
 	flag.StringVar(&cfg.Seed, "seed", "", "wallet's seed")
 	flag.IntVar(&cfg.UtxoPoolSize, "poolsize", 1000, "utxo pool size")
 	flag.StringVar(&cfg.Admins, "admins", "", "admin pubkey list")
+	flag.Uint64Var(&cfg.FeeRate, "fee-rate", 0, "taker fee rate in basis points")
+	flag.StringVar(&cfg.FeeAccount, "fee-account", "", "pubkey of the account which receives the trade fees")
+	flag.IntVar(&cfg.MaxOrders, "max-orders", 0, "max open orders of each coin pair, 0 means no limit")
+	flag.Uint64Var(&cfg.PriceBand, "price-band", 0, "max distance of the limit price from the last trade price in basis points, 0 disables the band")
+	flag.StringVar(&cfg.OrderFullPolicy, "order-full-policy", "reject", "what happens to the new order when the book is full, reject or evict_worst")
+	flag.DurationVar(&cfg.OrderSaveInterval, "order-save-interval", order.DefaultSaveInterval, "interval of saving the changed order books")
+	flag.DurationVar(&cfg.OrderSnapshotInterval, "order-snapshot-interval", order.DefaultSnapshotInterval, "interval of the order book snapshots, 0 disables them")
+	flag.IntVar(&cfg.OrderSnapshotKeep, "order-snapshot-keep", order.DefaultSnapshotKeep, "number of snapshots kept for each order book")
+	flag.IntVar(&cfg.BroadcastRetries, "broadcast-retries", 3, "max retries of the withdrawal broadcast failed transiently")
+	flag.DurationVar(&cfg.BroadcastBackoff, "broadcast-backoff", time.Second, "wait before the first broadcast retry, doubled for each of the next")
+	flag.Float64Var(&cfg.RateLimit, "rate-limit", 10, "requests per second of each account to the signed apis, 0 disables the limit")
+	flag.IntVar(&cfg.RateBurst, "rate-burst", 20, "max requests of each account in a burst")
+	flag.StringVar(&cfg.LogFormat, "log-format", "text", "log format, text or json")
+	flag.DurationVar(&cfg.DepositAddrTTL, "deposit-addr-ttl", 0, "unfunded deposit addresses handed out longer than it are reclaimed, 0 disables reclaiming")
+	flag.BoolVar(&cfg.Testnet, "testnet", false, "run the bitcoin and litecoin gateways on their testnets")
+	flag.DurationVar(&cfg.FeeSweepInterval, "fee-sweep-interval", 0, "interval of sweeping the fee account to the cold addresses, 0 disables sweeping")
+	flag.DurationVar(&cfg.WithdrawalAddrCooldown, "withdrawal-addr-cooldown", 24*time.Hour, "wait before the newly whitelisted withdrawal address can be used")
+	flag.IntVar(&cfg.TOTPSkew, "totp-skew", 1, "time steps before and after the current one whose totp codes are accepted")
 	var (
-		skyNodeAddr string
-		mzNodeAddr  string
+		skyNodeAddr     string
+		mzNodeAddr      string
+		seeds           string
+		confirms        string
+		sweepAddrs      string
+		sweepThresholds string
+		btcBroadcast    string
+		ltcBroadcast    string
 	)
+	flag.StringVar(&seeds, "seeds", "", "seeds of extra wallets, like cold:seed1,backup:seed2")
+	flag.StringVar(&confirms, "min-confirmations", "bitcoin:1,litecoin:1", "min confirmations before crediting deposits, like bitcoin:3,litecoin:6")
+	flag.StringVar(&sweepAddrs, "fee-sweep-addrs", "", "cold addresses receiving the swept fees, like bitcoin:addr1,skycoin:addr2")
+	flag.StringVar(&sweepThresholds, "fee-sweep-thresholds", "", "fee balance above which it's swept, like bitcoin:1000000")
+	flag.StringVar(&btcBroadcast, "btc-broadcast-apis", "", "insight apis broadcasting the bitcoin transactions, tried in order, like https://a/api,https://b/api")
+	flag.StringVar(&ltcBroadcast, "ltc-broadcast-apis", "", "insight apis broadcasting the litecoin transactions, tried in order")
 	flag.StringVar(&skyNodeAddr, "skycoin-node-addr", "127.0.0.1:6420", "skycoin node address")
 	flag.StringVar(&mzNodeAddr, "mzcoin-node-addr", "127.0.0.1:7420", "mzcoin node address")
 	flag.BoolVar(&cfg.HttpProf, "http-prof", false, "enable http profiling")
@@ -52,11 +97,71 @@ func registerFlags(cfg *server.Config) {
 	flag.Parse()
 	cfg.NodeAddresses[skycoin.Type] = skyNodeAddr
 	cfg.NodeAddresses[mzcoin.Type] = mzNodeAddr
+
+	for tp, apis := range map[string]string{bitcoin.Type: btcBroadcast, litecoin.Type: ltcBroadcast} {
+		for _, s := range strings.Split(apis, ",") {
+			if s = strings.TrimSuffix(strings.TrimSpace(s), "/"); s != "" {
+				cfg.BroadcastAPIs[tp] = append(cfg.BroadcastAPIs[tp], s)
+			}
+		}
+	}
+
+	for _, s := range strings.Split(seeds, ",") {
+		if s == "" {
+			continue
+		}
+		v := strings.SplitN(s, ":", 2)
+		if len(v) != 2 || v[0] == "" || v[1] == "" {
+			panic(fmt.Sprintf("invalid wallet seed %s", s))
+		}
+		cfg.Seeds[v[0]] = v[1]
+	}
+
+	for _, s := range strings.Split(confirms, ",") {
+		if s == "" {
+			continue
+		}
+		v := strings.SplitN(s, ":", 2)
+		if len(v) != 2 || v[0] == "" {
+			panic(fmt.Sprintf("invalid min confirmations %s", s))
+		}
+		n, err := strconv.ParseUint(v[1], 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("invalid min confirmations %s", s))
+		}
+		cfg.MinConfirmations[v[0]] = n
+	}
+
+	for _, s := range strings.Split(sweepAddrs, ",") {
+		if s == "" {
+			continue
+		}
+		v := strings.SplitN(s, ":", 2)
+		if len(v) != 2 || v[0] == "" || v[1] == "" {
+			panic(fmt.Sprintf("invalid fee sweep address %s", s))
+		}
+		cfg.FeeSweepAddrs[v[0]] = v[1]
+	}
+
+	for _, s := range strings.Split(sweepThresholds, ",") {
+		if s == "" {
+			continue
+		}
+		v := strings.SplitN(s, ":", 2)
+		if len(v) != 2 || v[0] == "" {
+			panic(fmt.Sprintf("invalid fee sweep threshold %s", s))
+		}
+		n, err := strconv.ParseUint(v[1], 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("invalid fee sweep threshold %s", s))
+		}
+		cfg.FeeSweepThresholds[v[0]] = n
+	}
 }
 
 func main() {
-	initLogging(logging.DEBUG, true)
 	cfg := initConfig()
+	initLogging(logging.DEBUG, cfg.LogFormat)
 	initProfiling(cfg.HttpProf)
 
 	// print pubkey so that client can use that to communicate with server
@@ -67,9 +172,21 @@ func main() {
 	// Bind supported coins
 	s.BindCoins(
 		&bitcoin.Bitcoin{},
+		litecoin.New(),
 		skycoin.New(cfg.NodeAddresses[skycoin.Type]),
 		mzcoin.New(cfg.NodeAddresses[mzcoin.Type]))
-	s.Run()
+	go s.Run()
+
+	// shutdown the server gracefully on interrupt.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	<-sigs
+	logger.Info("shutting down the server")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		logger.Error("shutdown server failed: %v", err)
+	}
 }
 
 func initConfig() *server.Config {
@@ -80,14 +197,24 @@ func initConfig() *server.Config {
 		panic("seed must be set")
 	}
 
+	if cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+		panic(fmt.Sprintf("invalid log format %s", cfg.LogFormat))
+	}
+
 	return cfg
 }
 
-func initLogging(level logging.Level, color bool) {
-	format := logging.MustStringFormatter(logFormat)
-	logging.SetFormatter(format)
-	bk := logging.NewLogBackend(os.Stdout, "", 0)
-	bk.Color = true
+func initLogging(level logging.Level, format string) {
+	var bk logging.Backend
+	switch format {
+	case "json":
+		bk = sklog.NewJSONBackend(os.Stdout)
+	default:
+		logging.SetFormatter(logging.MustStringFormatter(logFormat))
+		lbk := logging.NewLogBackend(os.Stdout, "", 0)
+		lbk.Color = true
+		bk = lbk
+	}
 	bkLvd := logging.AddModuleLevel(bk)
 	for _, s := range logModules {
 		bkLvd.SetLevel(level, s)
@@ -4,8 +4,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	"github.com/skycoin/skycoin-exchange/src/coin/ethereum"
+	litecoin "github.com/skycoin/skycoin-exchange/src/coin/litecoin"
+	"github.com/skycoin/skycoin-exchange/src/pp"
 	"github.com/skycoin/skycoin-exchange/src/sknet"
 	"github.com/skycoin/skycoin-exchange/src/wallet"
 	bip39 "github.com/tyler-smith/go-bip39"
@@ -14,6 +23,7 @@ import (
 // gobind doc: https://godoc.org/golang.org/x/mobile/cmd/gobind
 var config Config
 var coinMap map[string]Coiner
+var balances = newBalanceCache(DefaultBalanceCacheTTL * time.Second)
 
 // Config used for init the api env, includes wallet dir path, skycoin node and bitcoin node address.
 // the node address is consisted of ip and port, eg: 127.0.0.1:6420
@@ -21,6 +31,19 @@ type Config struct {
 	WalletDirPath string `json:"wallet_dir_path"`
 	ServerAddr    string `json:"server_addr"`
 	ServerPubkey  string `json:"server_pubkey"`
+
+	// EnableEthereum enables the ethereum wallet and coin, which are served by the
+	// ethereum node at EthereumNodeAddr through its JSON-RPC api.
+	EnableEthereum   bool   `json:"enable_ethereum"`
+	EthereumNodeAddr string `json:"ethereum_node_addr"`
+
+	// Testnet makes and validates the bitcoin and litecoin addresses of their testnets,
+	// the exchange server must run in testnet mode too.
+	Testnet bool `json:"testnet"`
+
+	// BalanceCacheTTL the seconds GetWalletBalance caches the wallet balances, 0 means
+	// DefaultBalanceCacheTTL, and negative disables the cache.
+	BalanceCacheTTL int `json:"balance_cache_ttl"`
 }
 
 // NewConfig create config instance.
@@ -30,10 +53,24 @@ func NewConfig() *Config {
 
 // Init initialize wallet dir and node instance.
 func Init(cfg *Config) {
-	initConfig(cfg,
+	bitcoin.SetTestnet(cfg.Testnet)
+	litecoin.SetTestnet(cfg.Testnet)
+
+	coins := []Coiner{
 		newCoin("skycoin", config.ServerAddr),
 		newCoin("mzcoin", config.ServerAddr),
-		newBitcoin(config.ServerAddr))
+		newBitcoin(config.ServerAddr),
+		newLitecoin(config.ServerAddr),
+	}
+
+	if cfg.EnableEthereum {
+		// the wallet type must be registered before loading the wallets, the error
+		// of registering it again is ignored, Init can be called more than once.
+		wallet.RegisterCreator(ethereum.Type, wallet.NewEthWltCreator())
+		coins = append(coins, newEthereum(cfg.EthereumNodeAddr))
+	}
+
+	initConfig(cfg, coins...)
 }
 
 func initConfig(cfg *Config, coins ...Coiner) {
@@ -43,6 +80,7 @@ func initConfig(cfg *Config, coins ...Coiner) {
 
 	wallet.InitDir(cfg.WalletDirPath)
 	config = *cfg
+	balances = newBalanceCache(balanceCacheTTL(cfg))
 
 	coinMap = make(map[string]Coiner)
 	for i := range coins {
@@ -50,21 +88,74 @@ func initConfig(cfg *Config, coins ...Coiner) {
 	}
 }
 
-// NewWallet create a new wallet base on the wallet type and seed
-func NewWallet(coinType string, seed string) (string, error) {
-	wlt, err := wallet.New(coinType, seed)
+// NewWallet create a new wallet base on the wallet type, seed and the optional BIP39
+// passphrase, empty passphrase creates the same wallet as before.
+func NewWallet(coinType string, seed string, passphrase string) (string, error) {
+	wlt, err := wallet.New(coinType, seed, wallet.Passphrase(passphrase))
 	if err != nil {
 		return "", err
 	}
 	return wlt.GetID(), nil
 }
 
+// ImportWalletFromWIF creates the bitcoin or litecoin wallet of the WIF private key, returns the wallet id,
+// the wallet has only the address of the key.
+func ImportWalletFromWIF(coinType string, wif string) (string, error) {
+	wlt, err := wallet.ImportFromWIF(coinType, wif)
+	if err != nil {
+		return "", err
+	}
+	return wlt.GetID(), nil
+}
+
+// ImportWalletFromSeckey creates the skycoin or mzcoin wallet of the hex private key, returns the wallet id,
+// the wallet has only the address of the key.
+func ImportWalletFromSeckey(coinType string, seckey string) (string, error) {
+	wlt, err := wallet.ImportFromSeckey(coinType, seckey)
+	if err != nil {
+		return "", err
+	}
+	return wlt.GetID(), nil
+}
+
+// EncryptWallet encrypts the wallet file with password, the addresses of encrypted wallet
+// can still be queried, but it must be decrypted before generating addresses or sending coins.
+func EncryptWallet(walletID string, password string) error {
+	return wallet.Encrypt(walletID, password)
+}
+
+// DecryptWallet decrypts the wallet with password, and stores it in plaintext again.
+func DecryptWallet(walletID string, password string) error {
+	return wallet.Decrypt(walletID, password)
+}
+
 // NewAddress generate address in specific wallet.
 func NewAddress(walletID string, num int) (string, error) {
 	es, err := wallet.NewAddresses(walletID, num)
 	if err != nil {
 		return "", err
 	}
+	// the new addresses may have coins when recovering the wallet.
+	balances.invalidate(walletID)
+	return marshalAddressEntries(es)
+}
+
+// ScanAddresses generates addresses in the wallet until gapLimit consecutive addresses are
+// unused, only the used addresses are returned, this is used for recovering the wallet.
+func ScanAddresses(walletID string, gapLimit int) (string, error) {
+	if gapLimit <= 0 {
+		return "", fmt.Errorf("invalid gap limit: %d", gapLimit)
+	}
+
+	es, err := scanAddresses(walletID, gapLimit)
+	if err != nil {
+		return "", err
+	}
+	balances.invalidate(walletID)
+	return marshalAddressEntries(es)
+}
+
+func marshalAddressEntries(es []coin.AddressEntry) (string, error) {
 	var res = struct {
 		Entries []coin.AddressEntry `json:"addresses"`
 	}{
@@ -78,6 +169,38 @@ func NewAddress(walletID string, num int) (string, error) {
 	return string(d), nil
 }
 
+// scanAddresses generates addresses in the wallet one by one, and stops after gapLimit
+// consecutive addresses are unused, like the BIP44 account discovery. An address is
+// used if it has balance. The used addresses are returned.
+func scanAddresses(walletID string, gapLimit int) ([]coin.AddressEntry, error) {
+	coinType := strings.Split(walletID, "_")[0]
+	c, ok := coinMap[coinType]
+	if !ok {
+		return nil, fmt.Errorf("%s is not supported", coinType)
+	}
+
+	used := []coin.AddressEntry{}
+	for gap := 0; gap < gapLimit; {
+		es, err := wallet.NewAddresses(walletID, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		bal, err := c.GetBalance([]string{es[0].Address})
+		if err != nil {
+			return nil, err
+		}
+
+		if bal > 0 {
+			used = append(used, es[0])
+			gap = 0
+			continue
+		}
+		gap++
+	}
+	return used, nil
+}
+
 // GetAddresses return all addresses in the wallet.
 func GetAddresses(walletID string) (string, error) {
 	addrs, err := wallet.GetAddresses(walletID)
@@ -98,9 +221,35 @@ func GetAddresses(walletID string) (string, error) {
 	return string(d), nil
 }
 
-// GetKeyPairOfAddr get pubkey and seckey pair of address in specific wallet.
-func GetKeyPairOfAddr(walletID string, addr string) (string, error) {
-	p, s, err := wallet.GetKeypair(walletID, addr)
+// SetAddressLabel sets the label of address in the wallet, empty label removes it.
+func SetAddressLabel(walletID string, addr string, label string) error {
+	return wallet.SetLabel(walletID, addr, label)
+}
+
+// GetAddressLabels returns the labels of addresses in the wallet.
+func GetAddressLabels(walletID string) (string, error) {
+	labels, err := wallet.GetLabels(walletID)
+	if err != nil {
+		return "", err
+	}
+	var res = struct {
+		Labels map[string]string `json:"labels"`
+	}{
+		labels,
+	}
+
+	d, err := json.Marshal(res)
+	if err != nil {
+		return "", err
+	}
+
+	return string(d), nil
+}
+
+// GetKeyPairOfAddr get pubkey and seckey pair of address in specific wallet,
+// the password is required if the wallet is encrypted.
+func GetKeyPairOfAddr(walletID string, addr string, password string) (string, error) {
+	p, s, err := wallet.GetKeypairWithPassword(walletID, addr, password)
 	if err != nil {
 		return "", err
 	}
@@ -119,7 +268,8 @@ func GetKeyPairOfAddr(walletID string, addr string) (string, error) {
 	return string(d), nil
 }
 
-// GetBalance return balance of a specific address.
+// GetBalance return balance of a specific address, eg: {"balance":1500000,"balance_formatted":"1.5"}
+// the balance is in the coin's base units, and the formatted one is in decimal coins.
 func GetBalance(coinType string, address string) (string, error) {
 	coin, ok := coinMap[coinType]
 	if !ok {
@@ -136,9 +286,48 @@ func GetBalance(coinType string, address string) (string, error) {
 	}
 
 	var res = struct {
-		Balance uint64 `json:"balance"`
+		Balance          uint64 `json:"balance"`
+		BalanceFormatted string `json:"balance_formatted"`
+	}{
+		bal,
+		formatAmount(bal, coin.Decimals()),
+	}
+
+	d, err := json.Marshal(res)
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
+}
+
+// GetWalletBalance return balance of wallet, the balance is cached for Config.BalanceCacheTTL
+// seconds, set forceRefresh to query it from the coin gateway anyway. The cache of the wallet
+// is cleared after sending coins from it.
+func GetWalletBalance(coinType string, wltID string, forceRefresh bool) (string, error) {
+	coin, ok := coinMap[coinType]
+	if !ok {
+		return "", fmt.Errorf("%s is not supported", coinType)
+	}
+
+	bal, ok := balances.get(wltID, coinType)
+	if !ok || forceRefresh {
+		addrs, err := wallet.GetAddresses(wltID)
+		if err != nil {
+			return "", err
+		}
+
+		bal, err = coin.GetBalance(addrs)
+		if err != nil {
+			return "", err
+		}
+		balances.set(wltID, coinType, bal)
+	}
+	var res = struct {
+		Balance          uint64 `json:"balance"`
+		BalanceFormatted string `json:"balance_formatted"`
 	}{
 		bal,
+		formatAmount(bal, coin.Decimals()),
 	}
 
 	d, err := json.Marshal(res)
@@ -148,8 +337,10 @@ func GetBalance(coinType string, address string) (string, error) {
 	return string(d), nil
 }
 
-// GetWalletBalance return balance of wallet.
-func GetWalletBalance(coinType string, wltID string) (string, error) {
+// GetWalletDetailedBalance returns the confirmed and unconfirmed balance of wallet, eg:
+// {"confirmed":1000,"confirmed_formatted":"0.00001","unconfirmed":300,"unconfirmed_formatted":"0.000003","total":1300,"total_formatted":"0.000013"}
+// the unconfirmed balance is the pending deposits and changes, it's always queried from the coin gateway.
+func GetWalletDetailedBalance(coinType string, wltID string) (string, error) {
 	coin, ok := coinMap[coinType]
 	if !ok {
 		return "", fmt.Errorf("%s is not supported", coinType)
@@ -160,14 +351,26 @@ func GetWalletBalance(coinType string, wltID string) (string, error) {
 		return "", err
 	}
 
-	bal, err := coin.GetBalance(addrs)
+	bal, err := coin.GetDetailedBalance(addrs)
 	if err != nil {
 		return "", err
 	}
+
+	dec := coin.Decimals()
 	var res = struct {
-		Balance uint64 `json:"balance"`
+		Confirmed            uint64 `json:"confirmed"`
+		ConfirmedFormatted   string `json:"confirmed_formatted"`
+		Unconfirmed          uint64 `json:"unconfirmed"`
+		UnconfirmedFormatted string `json:"unconfirmed_formatted"`
+		Total                uint64 `json:"total"`
+		TotalFormatted       string `json:"total_formatted"`
 	}{
-		bal,
+		bal.Confirmed,
+		formatAmount(bal.Confirmed, dec),
+		bal.Unconfirmed,
+		formatAmount(bal.Unconfirmed, dec),
+		bal.Total,
+		formatAmount(bal.Total, dec),
 	}
 
 	d, err := json.Marshal(res)
@@ -177,34 +380,347 @@ func GetWalletBalance(coinType string, wltID string) (string, error) {
 	return string(d), nil
 }
 
-// SendSky sends skycoins to an address from a specific wallet
-func SendSky(walletID string, toAddr string, amount string) (string, error) {
-	coin, ok := coinMap["skycoin"]
+// GetTicker returns the ticker of coin pair from the exchange server, eg:
+// {"coin_pair":"bitcoin/skycoin","best_bid":100,"best_ask":103,"spread":3,"last_price":101,"volume":5,"time":1500000000}
+// the best bid, best ask, spread and last price are null if the book side is empty or there's no trade.
+func GetTicker(coinPair string) (string, error) {
+	req := pp.GetTickerReq{CoinPair: pp.PtrString(coinPair)}
+	res := pp.GetTickerRes{}
+	if err := sknet.EncryGet(config.ServerAddr, "/get/ticker", req, &res); err != nil {
+		return "", err
+	}
+
+	if !res.Result.GetSuccess() {
+		return "", fmt.Errorf("get ticker failed: %v", res.Result.GetReason())
+	}
+
+	return makeTickerJSON(&res)
+}
+
+// makeTickerJSON converts the ticker response to json, the missing fields are null.
+func makeTickerJSON(res *pp.GetTickerRes) (string, error) {
+	tk := struct {
+		CoinPair  string  `json:"coin_pair"`
+		BestBid   *uint64 `json:"best_bid"`
+		BestAsk   *uint64 `json:"best_ask"`
+		Spread    *uint64 `json:"spread"`
+		LastPrice *uint64 `json:"last_price"`
+		Volume    uint64  `json:"volume"`
+		Time      int64   `json:"time"`
+	}{
+		res.GetCoinPair(),
+		res.BestBid,
+		res.BestAsk,
+		res.Spread,
+		res.LastPrice,
+		res.GetVolume(),
+		res.GetTime(),
+	}
+
+	d, err := json.Marshal(tk)
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
+}
+
+// GetWalletTransactions return transactions of all addresses in the wallet, sorted
+// by time in descending order, the unconfirmed transactions come first.
+func GetWalletTransactions(coinType string, wltID string) (string, error) {
+	coin, ok := coinMap[coinType]
 	if !ok {
-		return "", errors.New("skycoin is not supported")
+		return "", fmt.Errorf("%s is not supported", coinType)
 	}
 
-	return coin.Send(walletID, toAddr, amount)
+	if tp := strings.Split(wltID, "_")[0]; tp != coinType {
+		return "", fmt.Errorf("invalid wallet %v", tp)
+	}
+
+	addrs, err := wallet.GetAddresses(wltID)
+	if err != nil {
+		return "", err
+	}
+
+	txs, err := coin.GetTransactions(addrs)
+	if err != nil {
+		return "", err
+	}
+
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txOrder(txs[i]) > txOrder(txs[j])
+	})
+
+	d, err := json.Marshal(txs)
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
 }
 
-// SendMzc sends mzcoin to an address from specific wallet.
+// txOrder returns the sort key of transaction, bitcoin transactions are ordered by
+// time, and skycoin transactions are ordered by block height as they carry no time.
+func txOrder(tx *pp.Tx) uint64 {
+	if btx := tx.GetBtc(); btx != nil {
+		if btx.GetTime() <= 0 {
+			return math.MaxUint64
+		}
+		return uint64(btx.GetTime())
+	}
+
+	if !tx.GetSky().GetConfirmed() {
+		return math.MaxUint64
+	}
+	return tx.GetSky().GetHeight()
+}
+
+// feeCoins the coins whose transaction fee is set by the caller, the others ignore the fee of Send.
+var feeCoins = map[string]bool{
+	bitcoin.Type:  true,
+	litecoin.Type: true,
+}
+
+// Send sends coins of coinType, like skycoin or bitcoin, to an address from a specific wallet,
+// amount is in decimal coins, eg: "1.5", and can't be more precise than the coin's decimals.
+// fee is in the coin's base units, and only used by bitcoin and litecoin, empty fee means the
+// default fee of the coin, the other coins ignore it.
+func Send(coinType, walletID, toAddr, amount, fee string) (string, error) {
+	var ops []Option
+	if feeCoins[coinType] && fee != "" {
+		ops = append(ops, Fee(fee))
+	}
+	return send(coinType, walletID, toAddr, amount, ops...)
+}
+
+// SendSky sends skycoins to an address from a specific wallet, amount is in decimal
+// coins, eg: "1.5", and can't be more precise than the skycoin's decimals.
+func SendSky(walletID string, toAddr string, amount string) (string, error) {
+	return Send("skycoin", walletID, toAddr, amount, "")
+}
+
+// SendMzc sends mzcoin to an address from specific wallet, amount is in decimal coins.
 func SendMzc(walletID string, toAddr string, amount string) (string, error) {
-	coin, ok := coinMap["mzcoin"]
+	return Send("mzcoin", walletID, toAddr, amount, "")
+}
+
+// SendBtc sends bitcoins to an address from a specific wallet, amount is in decimal
+// bitcoins, eg: "0.015", fee is in satoshis.
+func SendBtc(walletID string, toAddr string, amount string, fee string) (string, error) {
+	return Send(bitcoin.Type, walletID, toAddr, amount, fee)
+}
+
+// SendLtc sends litecoins to an address from a specific wallet, amount is in decimal
+// litecoins, fee is in litoshis.
+func SendLtc(walletID string, toAddr string, amount string, fee string) (string, error) {
+	return Send(litecoin.Type, walletID, toAddr, amount, fee)
+}
+
+// SendEth sends ether to an address from a specific wallet, amount is in decimal ether,
+// the fee is paid by the gas price of the node. Ethereum must be enabled in Config.
+func SendEth(walletID string, toAddr string, amount string) (string, error) {
+	return Send(ethereum.Type, walletID, toAddr, amount, "")
+}
+
+// PrepareSend builds the transaction of sending amount decimal coins to toAddr from the wallet,
+// but doesn't sign or broadcast it, returns the unsigned transaction json, eg:
+// {"inputs":[{"txid":"xxx","vout":0,"address":"xxx"}],"outputs":[{"address":"xxx","amount":1000}],"fee":2000}
+// the amounts in it are in the coin's base units.
+func PrepareSend(coinType, walletID, toAddr, amount string) (string, error) {
+	return coinSend(coinType, walletID, toAddr, amount, DryRun())
+}
+
+// Recipient is an output of SendMany, amount is in decimal coins.
+type Recipient struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+}
+
+// SendMany sends coins to multiple recipients from a specific wallet in one transaction,
+// recipientsJSON is an array of recipients, eg: [{"address":"xxx", "amount":"1.5"}],
+// the amounts are in decimal coins, duplicate addresses are not allowed.
+func SendMany(coinType string, walletID string, recipientsJSON string) (string, error) {
+	coin, ok := coinMap[coinType]
 	if !ok {
-		return "", errors.New("mzcoin is not supported")
+		return "", fmt.Errorf("%s is not supported", coinType)
+	}
+
+	var rs []Recipient
+	if err := json.Unmarshal([]byte(recipientsJSON), &rs); err != nil {
+		return "", fmt.Errorf("invalid recipients: %v", err)
+	}
+
+	outs := make([]TxOut, len(rs))
+	for i, r := range rs {
+		amt, err := parseAmount(r.Amount, coin.Decimals())
+		if err != nil {
+			return "", err
+		}
+		outs[i] = TxOut{Address: r.Address, Amount: amt}
 	}
 
-	return coin.Send(walletID, toAddr, amount)
+	rlt, err := coin.SendMany(walletID, outs)
+	if err != nil {
+		return "", err
+	}
+	balances.invalidate(walletID)
+	return rlt, nil
 }
 
-// SendBtc sends bitcoins to an address from a specific wallet
-func SendBtc(walletID string, toAddr string, amount string, fee string) (string, error) {
-	coin, ok := coinMap["bitcoin"]
+// send sends the decimal amount of coins, and clears the cached balance of the wallet.
+func send(coinType, walletID, toAddr, amount string, ops ...Option) (string, error) {
+	rlt, err := coinSend(coinType, walletID, toAddr, amount, ops...)
+	if err != nil {
+		return "", err
+	}
+	balances.invalidate(walletID)
+	return rlt, nil
+}
+
+// coinSend converts the decimal amount to the coin's base units, and sends it.
+func coinSend(coinType, walletID, toAddr, amount string, ops ...Option) (string, error) {
+	coin, ok := coinMap[coinType]
+	if !ok {
+		return "", fmt.Errorf("%s is not supported", coinType)
+	}
+
+	amt, err := parseAmount(amount, coin.Decimals())
+	if err != nil {
+		return "", err
+	}
+
+	return coin.Send(walletID, toAddr, strconv.FormatUint(amt, 10), ops...)
+}
+
+// FormatAmount converts the raw amount in the coin's base units to decimal coins, eg:
+// 150000000 satoshis of bitcoin is "1.5", the trailing zeros are trimmed.
+func FormatAmount(coinType string, raw uint64) (string, error) {
+	coin, ok := coinMap[coinType]
+	if !ok {
+		return "", fmt.Errorf("%s is not supported", coinType)
+	}
+	return formatAmount(raw, coin.Decimals()), nil
+}
+
+// ParseAmount converts the amount in decimal coins to the coin's base units, eg:
+// "1.5" skycoin is 1500000, the amount more precise than the coin is rejected.
+func ParseAmount(coinType, amount string) (uint64, error) {
+	coin, ok := coinMap[coinType]
+	if !ok {
+		return 0, fmt.Errorf("%s is not supported", coinType)
+	}
+	return parseAmount(amount, coin.Decimals())
+}
+
+// formatAmount converts the integer base units to the decimal amount string of the coin
+// with decimals decimal places, it's the reverse of parseAmount.
+func formatAmount(raw uint64, decimals int) string {
+	s := strconv.FormatUint(raw, 10)
+	if decimals <= 0 {
+		return s
+	}
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+
+	intPart, fracPart := s[:len(s)-decimals], strings.TrimRight(s[len(s)-decimals:], "0")
+	if fracPart == "" {
+		return intPart
+	}
+	return intPart + "." + fracPart
+}
+
+// parseAmount converts the decimal amount string to integer base units of the coin
+// with decimals decimal places, eg: "1.5" of skycoin is 1500000. Returns error if the
+// amount is negative, malformed, more precise than decimals, or overflows uint64.
+func parseAmount(amount string, decimals int) (uint64, error) {
+	if strings.HasPrefix(amount, "-") {
+		return 0, fmt.Errorf("invalid amount %q: negative amount", amount)
+	}
+
+	intPart, fracPart := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		intPart, fracPart = amount[:i], amount[i+1:]
+		if fracPart == "" {
+			return 0, fmt.Errorf("invalid amount %q", amount)
+		}
+	}
+	if intPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	// the trailing zeros don't add precision.
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > decimals {
+		return 0, fmt.Errorf("invalid amount %q: more than %d decimal places", amount, decimals)
+	}
+	fracPart += strings.Repeat("0", decimals-len(fracPart))
+
+	amt, err := strconv.ParseUint(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %v", amount, err)
+	}
+	return amt, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// EstimateFee estimates the fee of transaction with nIn inputs and nOut outputs, eg:
+// {"fee":7480,"fee_formatted":"0.0000748"}
+func EstimateFee(coinType string, nIn, nOut int) (string, error) {
+	coin, ok := coinMap[coinType]
 	if !ok {
-		return "", errors.New("bitcoin is not supported")
+		return "", fmt.Errorf("%s is not supported", coinType)
 	}
 
-	return coin.Send(walletID, toAddr, amount, Fee(fee))
+	fee, err := coin.EstimateFee(nIn, nOut)
+	if err != nil {
+		return "", err
+	}
+
+	var res = struct {
+		Fee          uint64 `json:"fee"`
+		FeeFormatted string `json:"fee_formatted"`
+	}{
+		fee,
+		formatAmount(fee, coin.Decimals()),
+	}
+
+	d, err := json.Marshal(res)
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
+}
+
+// GetFeeEstimates returns the fee rates for the transaction to be confirmed within 1, 3 and 6 blocks.
+func GetFeeEstimates(coinType string) (string, error) {
+	coin, ok := coinMap[coinType]
+	if !ok {
+		return "", fmt.Errorf("%s is not supported", coinType)
+	}
+
+	fes, err := coin.GetFeeEstimates()
+	if err != nil {
+		return "", err
+	}
+
+	var res = struct {
+		Estimates interface{} `json:"estimates"`
+	}{
+		fes,
+	}
+
+	d, err := json.Marshal(res)
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
 }
 
 // GetTransactionByID gets transaction verbose info by id
@@ -217,6 +733,76 @@ func GetTransactionByID(coinType, txid string) (string, error) {
 	return coin.GetTransactionByID(txid)
 }
 
+// The simplified transaction status returned by GetTransactionStatus.
+const (
+	TxPending   = "pending"
+	TxConfirmed = "confirmed"
+	TxFailed    = "failed"
+)
+
+// GetTransactionStatus gets the simplified status of transaction, for polling it after sending, eg:
+// {"status":"confirmed","confirmations":3}
+// the status is pending, confirmed or failed, the skycoin transaction is failed if the node doesn't know it
+// any more, the ethereum transaction has 1 confirmation once it's in a block.
+func GetTransactionStatus(coinType, txid string) (string, error) {
+	coin, ok := coinMap[coinType]
+	if !ok {
+		return "", fmt.Errorf("%s is not supported", coinType)
+	}
+
+	s, err := coin.GetTransactionByID(txid)
+	if err != nil {
+		return "", err
+	}
+
+	tx := pp.Tx{}
+	if err := json.Unmarshal([]byte(s), &tx); err != nil {
+		return "", fmt.Errorf("decode %s transaction failed: %v", coinType, err)
+	}
+
+	st, err := txStatus(&tx)
+	if err != nil {
+		return "", err
+	}
+
+	d, err := json.Marshal(st)
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
+}
+
+type transactionStatus struct {
+	Status        string `json:"status"`
+	Confirmations uint64 `json:"confirmations"`
+}
+
+// txStatus converts the per-coin status of the transaction into the simplified one.
+func txStatus(tx *pp.Tx) (transactionStatus, error) {
+	switch {
+	case tx.Btc != nil:
+		if n := tx.Btc.GetConfirmations(); n > 0 {
+			return transactionStatus{TxConfirmed, n}, nil
+		}
+		return transactionStatus{Status: TxPending}, nil
+	case tx.Sky != nil:
+		switch {
+		case tx.Sky.GetConfirmed():
+			return transactionStatus{TxConfirmed, tx.Sky.GetHeight()}, nil
+		case tx.Sky.GetUnknow():
+			return transactionStatus{Status: TxFailed}, nil
+		}
+		return transactionStatus{Status: TxPending}, nil
+	case tx.Eth != nil:
+		if tx.Eth.GetBlockNumber() > 0 {
+			return transactionStatus{TxConfirmed, 1}, nil
+		}
+		return transactionStatus{Status: TxPending}, nil
+	default:
+		return transactionStatus{}, errors.New("unknown transaction")
+	}
+}
+
 // GetOutputByID gets output info by id, Note: bitcoin is not supported.
 func GetOutputByID(coinType, id string) (string, error) {
 	coin, ok := coinMap[coinType]
@@ -241,7 +827,7 @@ func ValidateAddress(coinType, addr string) (bool, error) {
 	return true, nil
 }
 
-// NewSeed generates mnemonic seed
+// NewSeed generates mnemonic seed, it can be protected by the BIP39 passphrase in NewWallet.
 func NewSeed() string {
 	entropy, err := bip39.NewEntropy(128)
 	if err != nil {
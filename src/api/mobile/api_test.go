@@ -12,7 +12,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
+	"github.com/skycoin/skycoin-exchange/src/pp"
 	"github.com/skycoin/skycoin-exchange/src/wallet"
+	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -52,16 +57,19 @@ func TestGetBalance(t *testing.T) {
 	skyM.On("Name").Return("skycoin")
 	skyM.On("ValidateAddr", "cBnu9sUvv12dovBmjQKTtfE4rbjMmf3fzW").Return(nil)
 	skyM.On("GetBalance", []string{"cBnu9sUvv12dovBmjQKTtfE4rbjMmf3fzW"}).Return(uint64(6e6), nil)
+	skyM.On("Decimals").Return(6)
 
 	mzM := NewCoinerMock()
 	mzM.On("Name").Return("mzcoin")
 	mzM.On("ValidateAddr", "2BMHv3PEyat9K9snsnDyRv7UBuRuycMPyWH").Return(nil)
 	mzM.On("GetBalance", []string{"2BMHv3PEyat9K9snsnDyRv7UBuRuycMPyWH"}).Return(uint64(998e6), nil)
+	mzM.On("Decimals").Return(6)
 
 	btcM := NewCoinerMock()
 	btcM.On("Name").Return("bitcoin")
 	btcM.On("ValidateAddr", "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6").Return(nil)
 	btcM.On("GetBalance", []string{"1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6"}).Return(uint64(936000), nil)
+	btcM.On("Decimals").Return(8)
 
 	initConfig(&Config{}, skyM, mzM, btcM)
 
@@ -69,10 +77,11 @@ func TestGetBalance(t *testing.T) {
 		coinType string
 		address  string
 		expect   uint64
+		format   string
 	}{
-		{"skycoin", "cBnu9sUvv12dovBmjQKTtfE4rbjMmf3fzW", 6000000},
-		{"bitcoin", "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6", 936000},
-		{"mzcoin", "2BMHv3PEyat9K9snsnDyRv7UBuRuycMPyWH", 998000000},
+		{"skycoin", "cBnu9sUvv12dovBmjQKTtfE4rbjMmf3fzW", 6000000, "6"},
+		{"bitcoin", "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6", 936000, "0.00936"},
+		{"mzcoin", "2BMHv3PEyat9K9snsnDyRv7UBuRuycMPyWH", 998000000, "998"},
 	}
 	for _, td := range testData {
 		b, err := GetBalance(td.coinType, td.address)
@@ -80,13 +89,15 @@ func TestGetBalance(t *testing.T) {
 			t.Fatal(err)
 		}
 		var res struct {
-			Balance uint64 `json:"balance"`
+			Balance          uint64 `json:"balance"`
+			BalanceFormatted string `json:"balance_formatted"`
 		}
 
 		if err := json.Unmarshal([]byte(b), &res); err != nil {
 			t.Fatal(err)
 		}
 		assert.Equal(t, res.Balance, td.expect)
+		assert.Equal(t, td.format, res.BalanceFormatted)
 	}
 }
 
@@ -153,7 +164,7 @@ func TestGetAddresses(t *testing.T) {
 	}
 
 	for _, td := range testData {
-		id, err := NewWallet(td.coinType, td.seed)
+		id, err := NewWallet(td.coinType, td.seed, "")
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -187,10 +198,9 @@ func TestSendBtc(t *testing.T) {
 	txid := "32444c08568cf03f4be5bb1110124d6a00bb94bc5338abddc9fb2497f3825a91"
 	m := NewCoinerMock()
 	m.On("Name").Return("bitcoin")
+	m.On("Decimals").Return(8)
 	m.On("Send", "bitcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "10000", mock.AnythingOfType("[]mobile.Option")).
 		Return(fmt.Sprintf(`{"txid":"%s"}`, txid), nil)
-	m.On("Send", "bitcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "100ss", mock.AnythingOfType("[]mobile.Option")).
-		Return("", fmt.Errorf(`parse amount string to uint64 failed: strconv.ParseUint: parsing "100ss": invalid syntax"`))
 
 	initConfig(&Config{}, m)
 
@@ -212,7 +222,7 @@ func TestSendBtc(t *testing.T) {
 			args{
 				"bitcoin_abc",
 				"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz",
-				"10000",
+				"0.0001",
 				"1000",
 			},
 			fmt.Sprintf(`{"txid":"%s"}`, txid),
@@ -229,6 +239,28 @@ func TestSendBtc(t *testing.T) {
 			"",
 			true,
 		},
+		{
+			"over-precise amount",
+			args{
+				"bitcoin_abc",
+				"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz",
+				"0.000100001",
+				"1000",
+			},
+			"",
+			true,
+		},
+		{
+			"negative amount",
+			args{
+				"bitcoin_abc",
+				"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz",
+				"-0.0001",
+				"1000",
+			},
+			"",
+			true,
+		},
 	}
 	for _, tt := range tests {
 		got, err := SendBtc(tt.args.walletID, tt.args.toAddr, tt.args.amount, tt.args.fee)
@@ -246,11 +278,10 @@ func TestSendSky(t *testing.T) {
 	txid := "32444c08568cf03f4be5bb1110124d6a00bb94bc5338abddc9fb2497f3825a91"
 	m := NewCoinerMock()
 	m.On("Name").Return("skycoin")
+	m.On("Decimals").Return(6)
 
-	m.On("Send", "skycoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "1e6", []Option(nil)).
+	m.On("Send", "skycoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "1000000", []Option(nil)).
 		Return(fmt.Sprintf(`{"txid":"%s"}`, txid), nil)
-	m.On("Send", "skycoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "100ss", []Option(nil)).
-		Return("", errors.New(`parse amount string to uint64 failed: strconv.ParseUint: parsing "100ss": invalid syntax"`))
 
 	initConfig(&Config{}, m)
 
@@ -271,7 +302,7 @@ func TestSendSky(t *testing.T) {
 			args{
 				"skycoin_abc",
 				"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz",
-				"1e6",
+				"1",
 			},
 			fmt.Sprintf(`{"txid":"%s"}`, txid),
 			false,
@@ -286,6 +317,26 @@ func TestSendSky(t *testing.T) {
 			"",
 			true,
 		},
+		{
+			"over-precise amount",
+			args{
+				"skycoin_abc",
+				"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz",
+				"1.0000001",
+			},
+			"",
+			true,
+		},
+		{
+			"negative amount",
+			args{
+				"skycoin_abc",
+				"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz",
+				"-1",
+			},
+			"",
+			true,
+		},
 	}
 	for _, tt := range tests {
 		got, err := SendSky(tt.args.walletID, tt.args.toAddr, tt.args.amount)
@@ -303,11 +354,10 @@ func TestSendMzc(t *testing.T) {
 	txid := "32444c08568cf03f4be5bb1110124d6a00bb94bc5338abddc9fb2497f3825a91"
 	m := NewCoinerMock()
 	m.On("Name").Return("mzcoin")
+	m.On("Decimals").Return(6)
 
-	m.On("Send", "mzcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "1e6", []Option(nil)).
+	m.On("Send", "mzcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "1000000", []Option(nil)).
 		Return(fmt.Sprintf(`{"txid":"%s"}`, txid), nil)
-	m.On("Send", "mzcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "100ss", []Option(nil)).
-		Return("", errors.New(`parse amount string to uint64 failed: strconv.ParseUint: parsing "100ss": invalid syntax"`))
 
 	initConfig(&Config{}, m)
 
@@ -328,7 +378,7 @@ func TestSendMzc(t *testing.T) {
 			args{
 				"mzcoin_abc",
 				"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz",
-				"1e6",
+				"1",
 			},
 			fmt.Sprintf(`{"txid":"%s"}`, txid),
 			false,
@@ -343,6 +393,26 @@ func TestSendMzc(t *testing.T) {
 			"",
 			true,
 		},
+		{
+			"over-precise amount",
+			args{
+				"mzcoin_abc",
+				"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz",
+				"1.0000001",
+			},
+			"",
+			true,
+		},
+		{
+			"negative amount",
+			args{
+				"mzcoin_abc",
+				"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz",
+				"-1",
+			},
+			"",
+			true,
+		},
 	}
 	for _, tt := range tests {
 		got, err := SendMzc(tt.args.walletID, tt.args.toAddr, tt.args.amount)
@@ -356,6 +426,373 @@ func TestSendMzc(t *testing.T) {
 	}
 }
 
+func TestSend(t *testing.T) {
+	// the fee set by the options on the bitcoin client.
+	withFee := func(fee string) interface{} {
+		return mock.MatchedBy(func(ops []Option) bool {
+			var c bitcoinCli
+			for _, op := range ops {
+				op(&c)
+			}
+			return c.fee == fee
+		})
+	}
+
+	sky := NewCoinerMock()
+	sky.On("Name").Return("skycoin")
+	sky.On("Decimals").Return(6)
+	sky.On("Send", "skycoin_abc", "2GgFvqoyk9RjwVzj8tqfcXVXB4orBwoc9qv", "1500000", []Option(nil)).Return(`{"txid":"sky"}`, nil)
+
+	btc := NewCoinerMock()
+	btc.On("Name").Return("bitcoin")
+	btc.On("Decimals").Return(8)
+	btc.On("Send", "bitcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "10000", withFee("3000")).Return(`{"txid":"btc"}`, nil)
+	// empty fee keeps the default fee of the client.
+	btc.On("Send", "bitcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "20000", []Option(nil)).Return(`{"txid":"btc default"}`, nil)
+
+	ltc := NewCoinerMock()
+	ltc.On("Name").Return("litecoin")
+	ltc.On("Decimals").Return(8)
+	ltc.On("Send", "litecoin_abc", "LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", "100000000", withFee("200000")).Return(`{"txid":"ltc"}`, nil)
+
+	eth := NewCoinerMock()
+	eth.On("Name").Return("ethereum")
+	eth.On("Decimals").Return(18)
+	eth.On("Send", "ethereum_abc", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "1000000000000000000", []Option(nil)).Return(`{"txid":"eth"}`, nil)
+
+	initConfig(&Config{}, sky, btc, ltc, eth)
+
+	tests := []struct {
+		coinType string
+		walletID string
+		toAddr   string
+		amount   string
+		fee      string
+		want     string
+	}{
+		// the fee is ignored by the coins that don't take it.
+		{"skycoin", "skycoin_abc", "2GgFvqoyk9RjwVzj8tqfcXVXB4orBwoc9qv", "1.5", "1000", `{"txid":"sky"}`},
+		{"bitcoin", "bitcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "0.0001", "3000", `{"txid":"btc"}`},
+		{"bitcoin", "bitcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "0.0002", "", `{"txid":"btc default"}`},
+		{"litecoin", "litecoin_abc", "LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", "1", "200000", `{"txid":"ltc"}`},
+		{"ethereum", "ethereum_abc", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "1", "", `{"txid":"eth"}`},
+	}
+	for _, tt := range tests {
+		got, err := Send(tt.coinType, tt.walletID, tt.toAddr, tt.amount, tt.fee)
+		assert.Nil(t, err, tt.coinType)
+		assert.Equal(t, tt.want, got, tt.coinType)
+	}
+	sky.AssertNumberOfCalls(t, "Send", 1)
+	btc.AssertNumberOfCalls(t, "Send", 2)
+	ltc.AssertNumberOfCalls(t, "Send", 1)
+	eth.AssertNumberOfCalls(t, "Send", 1)
+
+	// the per-coin apis go through Send.
+	got, err := SendBtc("bitcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "0.0001", "3000")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"txid":"btc"}`, got)
+
+	_, err = Send("dogecoin", "dogecoin_abc", "DH5yaieqoZN36fDVciNyRueRGvGLR3mr7L", "1", "")
+	assert.EqualError(t, err, "dogecoin is not supported")
+	_, err = SendMzc("mzcoin_abc", "2GgFvqoyk9RjwVzj8tqfcXVXB4orBwoc9qv", "1")
+	assert.EqualError(t, err, "mzcoin is not supported")
+	_, err = Send("skycoin", "skycoin_abc", "2GgFvqoyk9RjwVzj8tqfcXVXB4orBwoc9qv", "1.0000001", "")
+	assert.NotNil(t, err)
+	sky.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func TestSendMany(t *testing.T) {
+	txid := "32444c08568cf03f4be5bb1110124d6a00bb94bc5338abddc9fb2497f3825a91"
+	m := NewCoinerMock()
+	m.On("Name").Return("bitcoin")
+	m.On("Decimals").Return(8)
+	m.On("SendMany", "bitcoin_abc", []TxOut{
+		{Address: "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", Amount: 1e5},
+		{Address: "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6", Amount: 15e7},
+	}, []Option(nil)).Return(fmt.Sprintf(`{"txid":"%s"}`, txid), nil)
+
+	initConfig(&Config{}, m)
+
+	tests := []struct {
+		name       string
+		coinType   string
+		recipients string
+		want       string
+		wantErr    bool
+	}{
+		{
+			"normal",
+			"bitcoin",
+			`[{"address":"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz","amount":"0.001"},{"address":"1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6","amount":"1.5"}]`,
+			fmt.Sprintf(`{"txid":"%s"}`, txid),
+			false,
+		},
+		{
+			"invalid json",
+			"bitcoin",
+			`{"address":"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz","amount":"0.001"}`,
+			"",
+			true,
+		},
+		{
+			"over-precise amount",
+			"bitcoin",
+			`[{"address":"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz","amount":"0.000000001"}]`,
+			"",
+			true,
+		},
+		{
+			"unsupported coin",
+			"dogecoin",
+			`[{"address":"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz","amount":"1"}]`,
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := SendMany(tt.coinType, "bitcoin_abc", tt.recipients)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. SendMany() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q. SendMany() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildBtcTx(t *testing.T) {
+	bn := newBitcoin("")
+	chgAddr := "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6"
+	utxos := []*pp.BtcUtxo{
+		{Address: pp.PtrString(chgAddr), Txid: pp.PtrString("a1"), Vout: pp.PtrUint32(0), Amount: pp.PtrUint64(5e5)},
+		{Address: pp.PtrString(chgAddr), Txid: pp.PtrString("a2"), Vout: pp.PtrUint32(1), Amount: pp.PtrUint64(7e5)},
+	}
+	outs := []TxOut{
+		{Address: "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", Amount: 1e5},
+		{Address: "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", Amount: 2e5},
+		{Address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", Amount: 3e5},
+	}
+
+	txIns, txOuts, err := bn.buildTx(btcSendParams{WalletID: "bitcoin_abc", Outs: outs, Fee: 2000}, utxos, chgAddr)
+	assert.Nil(t, err)
+	assert.Len(t, txIns, 2)
+
+	// 3 recipients and the change.
+	assert.Len(t, txOuts, 4)
+	for i, o := range outs {
+		assert.Equal(t, o.Address, txOuts[i].Addr)
+		assert.Equal(t, o.Amount, txOuts[i].Value)
+	}
+	assert.Equal(t, chgAddr, txOuts[3].Addr)
+	assert.Equal(t, uint64(12e5-6e5-2000), txOuts[3].Value)
+
+	// no change.
+	outs[2].Amount = 12e5 - 3e5 - 2000
+	_, txOuts, err = bn.buildTx(btcSendParams{WalletID: "bitcoin_abc", Outs: outs, Fee: 2000}, utxos, chgAddr)
+	assert.Nil(t, err)
+	assert.Len(t, txOuts, 3)
+
+	// insufficient balance.
+	outs[2].Amount++
+	_, _, err = bn.buildTx(btcSendParams{WalletID: "bitcoin_abc", Outs: outs, Fee: 2000}, utxos, chgAddr)
+	assert.NotNil(t, err)
+
+	// duplicate recipients.
+	outs[2] = TxOut{Address: outs[0].Address, Amount: 1e5}
+	_, _, err = bn.buildTx(btcSendParams{WalletID: "bitcoin_abc", Outs: outs, Fee: 2000}, utxos, chgAddr)
+	assert.NotNil(t, err)
+}
+
+func TestBuildSkyTx(t *testing.T) {
+	cn := newCoin("skycoin", "")
+	chgAddr := "fyqX5YuwXMUs4GEUE3LjLyhrqvNztFHQ4B"
+	toAddr := "2YyLVUMwjNCRZT5mBGmF13wS8yXe79eqEtu"
+	utxos := []*pp.SkyUtxo{
+		{Hash: pp.PtrString("a1"), Address: pp.PtrString(chgAddr), Coins: pp.PtrUint64(5e6), Hours: pp.PtrUint64(30)},
+		{Hash: pp.PtrString("a2"), Address: pp.PtrString(chgAddr), Coins: pp.PtrUint64(3e6), Hours: pp.PtrUint64(70)},
+	}
+
+	// the recipient and the change share 1/4 of the hours.
+	params := sendParams{WalletID: "skycoin_abc", Outs: []TxOut{{Address: toAddr, Amount: 6e6}}}
+	txIns, txOuts, err := cn.buildTx(params, utxos, chgAddr)
+	assert.Nil(t, err)
+	assert.Len(t, txIns, 2)
+	assert.Len(t, txOuts, 2)
+	assert.Equal(t, uint64(6e6), txOuts[0].Coins)
+	assert.Equal(t, uint64(12), txOuts[0].Hours)
+	assert.Equal(t, cipher.MustDecodeBase58Address(chgAddr), txOuts[1].Address)
+	assert.Equal(t, uint64(2e6), txOuts[1].Coins)
+	assert.Equal(t, uint64(12), txOuts[1].Hours)
+
+	// the dry run shows the burnt hours.
+	tx, err := makeUnsignedTx(txIns, txOuts, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100-24), tx.HoursFee)
+	assert.Equal(t, uint64(100), tx.Inputs[0].Hours+tx.Inputs[1].Hours)
+
+	// no change, the recipient takes all the shared hours.
+	params.Outs[0].Amount = 8e6
+	_, txOuts, err = cn.buildTx(params, utxos, chgAddr)
+	assert.Nil(t, err)
+	assert.Len(t, txOuts, 1)
+	assert.Equal(t, uint64(25), txOuts[0].Hours)
+
+	// the utxo with hours is added if the chosen ones have none.
+	utxos = []*pp.SkyUtxo{
+		{Hash: pp.PtrString("b1"), Address: pp.PtrString(chgAddr), Coins: pp.PtrUint64(8e6), Hours: pp.PtrUint64(0)},
+		{Hash: pp.PtrString("b2"), Address: pp.PtrString(toAddr), Coins: pp.PtrUint64(1e6), Hours: pp.PtrUint64(8)},
+		{Hash: pp.PtrString("b3"), Address: pp.PtrString(toAddr), Coins: pp.PtrUint64(1e6), Hours: pp.PtrUint64(40)},
+	}
+	params.Outs[0].Amount = 3e6
+	_, _, err = cn.buildTx(params, utxos[:1], chgAddr)
+	assert.True(t, errors.Is(err, skycoin.ErrInsufficientHours))
+
+	txIns, txOuts, err = cn.buildTx(params, utxos, chgAddr)
+	assert.Nil(t, err)
+	var hours uint64
+	for _, in := range txIns {
+		hours += in.Hours
+	}
+	assert.True(t, hours > 0)
+	assert.Equal(t, hours/4/2, txOuts[0].Hours)
+
+	// insufficient coins.
+	params.Outs[0].Amount = 11e6
+	_, _, err = cn.buildTx(params, utxos, chgAddr)
+	assert.NotNil(t, err)
+}
+
+func TestValidateOuts(t *testing.T) {
+	validate := func(addr string) error {
+		if addr == "invalid" {
+			return errors.New("invalid address")
+		}
+		return nil
+	}
+
+	total, err := validateOuts([]TxOut{{"a", 1}, {"b", 2}, {"c", 3}}, validate)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(6), total)
+
+	for _, outs := range [][]TxOut{
+		nil,
+		{{"a", 1}, {"invalid", 2}},
+		{{"a", 1}, {"b", 2}, {"a", 3}},
+		{{"a", 0}},
+		{{"a", 1 << 63}, {"b", 1 << 63}},
+	} {
+		_, err := validateOuts(outs, validate)
+		assert.NotNil(t, err, "%v", outs)
+	}
+}
+
+func TestPrepareSend(t *testing.T) {
+	unsignedTx := `{"inputs":[{"txid":"a1","vout":0,"address":"1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6"}],"outputs":[{"address":"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz","amount":10000}],"fee":2000}`
+	isDryRun := mock.MatchedBy(func(ops []Option) bool {
+		var c bitcoinCli
+		for _, op := range ops {
+			op(&c)
+		}
+		return c.dryRun
+	})
+
+	m := NewCoinerMock()
+	m.On("Name").Return("bitcoin")
+	m.On("Decimals").Return(8)
+	m.On("Send", "bitcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "10000", isDryRun).Return(unsignedTx, nil)
+	initConfig(&Config{}, m)
+
+	got, err := PrepareSend("bitcoin", "bitcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "0.0001")
+	assert.Nil(t, err)
+	assert.Equal(t, unsignedTx, got)
+
+	_, err = PrepareSend("bitcoin", "bitcoin_abc", "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "-0.0001")
+	assert.NotNil(t, err)
+}
+
+func TestSendTxDryRun(t *testing.T) {
+	txIns := []coin.TxIn{
+		{Txid: "a1", Vout: 0, Address: "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6"},
+		{Txid: "a2", Vout: 3, Address: "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6"},
+	}
+	txOuts := []bitcoin.TxOut{
+		{Addr: "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", Value: 1e5},
+		{Addr: "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6", Value: 98000},
+	}
+	params := btcSendParams{WalletID: "bitcoin_abc", Fee: 2000}
+
+	m := NewCoinerMock()
+	m.On("PrepareTx", params).Return(txIns, txOuts, nil)
+	m.On("CreateRawTx", txIns, mock.Anything, txOuts).Return("rawtx", nil)
+	m.On("BroadcastTx", "rawtx").Return("txid", nil)
+
+	// dry run returns the unsigned transaction, without signing or broadcasting it.
+	got, err := sendTx(m, "bitcoin_abc", params, 2000, true)
+	assert.Nil(t, err)
+	m.AssertNotCalled(t, "CreateRawTx", txIns, mock.Anything, txOuts)
+	m.AssertNotCalled(t, "BroadcastTx", "rawtx")
+
+	var tx UnsignedTx
+	assert.Nil(t, json.Unmarshal([]byte(got), &tx))
+	assert.Equal(t, UnsignedTx{
+		Inputs: []UnsignedTxIn{
+			{Txid: "a1", Vout: 0, Address: "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6"},
+			{Txid: "a2", Vout: 3, Address: "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6"},
+		},
+		Outputs: []UnsignedTxOut{
+			{Address: "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", Amount: 1e5},
+			{Address: "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6", Amount: 98000},
+		},
+		Fee: 2000,
+	}, tx)
+
+	got, err = sendTx(m, "bitcoin_abc", params, 2000, false)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"txid":"txid"}`, got)
+	m.AssertCalled(t, "BroadcastTx", "rawtx")
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int
+		want     uint64
+		wantErr  bool
+	}{
+		{"1", 6, 1e6, false},
+		{"1.5", 6, 15e5, false},
+		{"0.000001", 6, 1, false},
+		{"1.5000000", 6, 15e5, false}, // trailing zeros are allowed.
+		{"0.015", 8, 15e5, false},
+		{"21000000", 8, 21e14, false},
+		{"0", 8, 0, false},
+		{"1.0000001", 6, 0, true},   // over-precise.
+		{"0.000000001", 8, 0, true}, // over-precise.
+		{"-1", 6, 0, true},
+		{"-0.5", 8, 0, true},
+		{"", 6, 0, true},
+		{".5", 6, 0, true},
+		{"1.", 6, 0, true},
+		{"1e6", 6, 0, true},
+		{"1.2.3", 6, 0, true},
+		{"+1", 6, 0, true},
+		{" 1", 6, 0, true},
+		{"18446744073709551616", 0, 0, true}, // overflow.
+		{"184467440737.09551616", 8, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseAmount(tt.amount, tt.decimals)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAmount(%q, %d) error = %v, wantErr %v", tt.amount, tt.decimals, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseAmount(%q, %d) = %v, want %v", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
+
 var skyTxStr = `{
     "status": {
         "confirmed": true,
@@ -591,6 +1028,56 @@ var outStr = `{
     "spent_tx": "b1481d614ffcc27408fe2131198d9d2821c78601a0aa23d8e9965b2a5196edc0"
 }`
 
+func TestGetTransactionStatus(t *testing.T) {
+	btcM := NewCoinerMock()
+	btcM.On("Name").Return("bitcoin")
+	btcM.On("GetTransactionByID", "btc_pending").Return(`{"btc":{"txid":"btc_pending"}}`, nil)
+	btcM.On("GetTransactionByID", "btc_confirmed").Return(`{"btc":{"txid":"btc_confirmed","confirmations":3}}`, nil)
+	btcM.On("GetTransactionByID", "btc_unknown").Return("", errors.New("not found"))
+	btcM.On("GetTransactionByID", "btc_invalid").Return(`invalid`, nil)
+
+	skyM := NewCoinerMock()
+	skyM.On("Name").Return("skycoin")
+	skyM.On("GetTransactionByID", "sky_pending").Return(`{"sky":{"hash":"sky_pending","confirmed":false}}`, nil)
+	skyM.On("GetTransactionByID", "sky_confirmed").Return(`{"sky":{"hash":"sky_confirmed","confirmed":true,"height":5}}`, nil)
+	skyM.On("GetTransactionByID", "sky_failed").Return(`{"sky":{"hash":"sky_failed","unknow":true}}`, nil)
+
+	ethM := NewCoinerMock()
+	ethM.On("Name").Return("ethereum")
+	ethM.On("GetTransactionByID", "eth_pending").Return(`{"eth":{"txid":"eth_pending"}}`, nil)
+	ethM.On("GetTransactionByID", "eth_confirmed").Return(`{"eth":{"txid":"eth_confirmed","block_number":100}}`, nil)
+	ethM.On("GetTransactionByID", "eth_empty").Return(`{}`, nil)
+
+	initConfig(&Config{}, btcM, skyM, ethM)
+
+	tests := []struct {
+		coinType string
+		txid     string
+		want     string
+		wantErr  bool
+	}{
+		{"bitcoin", "btc_pending", `{"status":"pending","confirmations":0}`, false},
+		{"bitcoin", "btc_confirmed", `{"status":"confirmed","confirmations":3}`, false},
+		{"bitcoin", "btc_unknown", "", true},
+		{"bitcoin", "btc_invalid", "", true},
+		{"skycoin", "sky_pending", `{"status":"pending","confirmations":0}`, false},
+		{"skycoin", "sky_confirmed", `{"status":"confirmed","confirmations":5}`, false},
+		{"skycoin", "sky_failed", `{"status":"failed","confirmations":0}`, false},
+		{"ethereum", "eth_pending", `{"status":"pending","confirmations":0}`, false},
+		{"ethereum", "eth_confirmed", `{"status":"confirmed","confirmations":1}`, false},
+		{"ethereum", "eth_empty", "", true},
+		{"dogecoin", "txid", "", true},
+	}
+	for _, tt := range tests {
+		got, err := GetTransactionStatus(tt.coinType, tt.txid)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s %s: GetTransactionStatus() error = %v, wantErr %v", tt.coinType, tt.txid, err, tt.wantErr)
+			continue
+		}
+		assert.Equal(t, tt.want, got, tt.txid)
+	}
+}
+
 func TestGetOutputByID(t *testing.T) {
 	skyM := NewCoinerMock()
 	skyM.On("Name").Return("skycoin")
@@ -752,10 +1239,11 @@ func TestGetWalletBalance(t *testing.T) {
 	skyM := NewCoinerMock()
 	skyM.On("Name").Return("skycoin")
 	skyM.On("GetBalance", skyAddressSet).Return(uint64(10e6), nil)
+	skyM.On("Decimals").Return(6)
 
 	initConfig(&Config{WalletDirPath: tmpDir}, skyM)
 
-	id, err := NewWallet("skycoin", "123")
+	id, err := NewWallet("skycoin", "123", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -782,14 +1270,14 @@ func TestGetWalletBalance(t *testing.T) {
 			"normal",
 			args{
 				"skycoin",
-				"skycoin_123",
+				id,
 			},
-			`{"balance":10000000}`,
+			`{"balance":10000000,"balance_formatted":"10"}`,
 			false,
 		},
 	}
 	for _, tt := range tests {
-		got, err := GetWalletBalance(tt.args.coinType, tt.args.wltID)
+		got, err := GetWalletBalance(tt.args.coinType, tt.args.wltID, false)
 		if (err != nil) != tt.wantErr {
 			t.Errorf("%q. GetWalletBalance() error = %v, wantErr %v", tt.name, err, tt.wantErr)
 			continue
@@ -799,3 +1287,403 @@ func TestGetWalletBalance(t *testing.T) {
 		}
 	}
 }
+
+func TestWalletBalanceCache(t *testing.T) {
+	tmpDir, teardown, err := setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	btcM := NewCoinerMock()
+	btcM.On("Name").Return("bitcoin")
+	btcM.On("Decimals").Return(8)
+	btcM.On("GetBalance", mock.AnythingOfType("[]string")).Return(uint64(1000), nil)
+	btcM.On("Send", mock.Anything, "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "100", mock.Anything).Return(`{"txid":"abc"}`, nil)
+	btcM.On("SendMany", mock.Anything, mock.Anything, []Option(nil)).Return("", errors.New("send failed"))
+
+	initConfig(&Config{WalletDirPath: tmpDir, BalanceCacheTTL: 5}, btcM)
+	now := time.Now()
+	balances.now = func() time.Time { return now }
+
+	id, err := NewWallet("bitcoin", "123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewAddress(id, 2)
+	assert.Nil(t, err)
+
+	// the second query hits the cache.
+	for i := 0; i < 2; i++ {
+		bal, err := GetWalletBalance("bitcoin", id, false)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"balance":1000,"balance_formatted":"0.00001"}`, bal)
+	}
+	btcM.AssertNumberOfCalls(t, "GetBalance", 1)
+
+	_, err = GetWalletBalance("bitcoin", id, true)
+	assert.Nil(t, err)
+	btcM.AssertNumberOfCalls(t, "GetBalance", 2)
+
+	// the balance expires after the ttl.
+	now = now.Add(4 * time.Second)
+	_, err = GetWalletBalance("bitcoin", id, false)
+	assert.Nil(t, err)
+	btcM.AssertNumberOfCalls(t, "GetBalance", 2)
+
+	now = now.Add(time.Second)
+	_, err = GetWalletBalance("bitcoin", id, false)
+	assert.Nil(t, err)
+	btcM.AssertNumberOfCalls(t, "GetBalance", 3)
+
+	// the failed send keeps the cache, the successful one clears it.
+	_, err = SendMany("bitcoin", id, `[{"address":"14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz","amount":"0.000001"}]`)
+	assert.NotNil(t, err)
+	_, err = GetWalletBalance("bitcoin", id, false)
+	assert.Nil(t, err)
+	btcM.AssertNumberOfCalls(t, "GetBalance", 3)
+
+	_, err = SendBtc(id, "14NAt8DhxMYKUwP5ZyH1yu7m1psYsn9Wqz", "0.000001", "1000")
+	assert.Nil(t, err)
+	_, err = GetWalletBalance("bitcoin", id, false)
+	assert.Nil(t, err)
+	btcM.AssertNumberOfCalls(t, "GetBalance", 4)
+
+	// negative ttl disables the cache.
+	initConfig(&Config{WalletDirPath: tmpDir, BalanceCacheTTL: -1}, btcM)
+	for i := 0; i < 2; i++ {
+		_, err = GetWalletBalance("bitcoin", id, false)
+		assert.Nil(t, err)
+	}
+	btcM.AssertNumberOfCalls(t, "GetBalance", 6)
+}
+
+func TestGetWalletDetailedBalance(t *testing.T) {
+	tmpDir, teardown, err := setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	btcM := NewCoinerMock()
+	btcM.On("Name").Return("bitcoin")
+	btcM.On("Decimals").Return(8)
+	btcM.On("GetDetailedBalance", mock.AnythingOfType("[]string")).Return(coin.DetailedBalance{Confirmed: 1000, Unconfirmed: 300, Total: 1300}, nil).Once()
+	btcM.On("GetDetailedBalance", mock.AnythingOfType("[]string")).Return(nil, errors.New("node unavailable"))
+
+	initConfig(&Config{WalletDirPath: tmpDir}, btcM)
+
+	id, err := NewWallet("bitcoin", "123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewAddress(id, 1)
+	assert.Nil(t, err)
+
+	bal, err := GetWalletDetailedBalance("bitcoin", id)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"confirmed":1000,"confirmed_formatted":"0.00001","unconfirmed":300,"unconfirmed_formatted":"0.000003","total":1300,"total_formatted":"0.000013"}`, bal)
+
+	_, err = GetWalletDetailedBalance("bitcoin", id)
+	assert.NotNil(t, err)
+
+	_, err = GetWalletDetailedBalance("dogecoin", id)
+	assert.NotNil(t, err)
+	btcM.AssertNumberOfCalls(t, "GetDetailedBalance", 2)
+}
+
+func TestMakeTickerJSON(t *testing.T) {
+	res := pp.GetTickerRes{
+		CoinPair: pp.PtrString("bitcoin/skycoin"),
+		BestBid:  pp.PtrUint64(25),
+		Volume:   pp.PtrUint64(0),
+		Time:     pp.PtrInt64(1470196900),
+	}
+	d, err := makeTickerJSON(&res)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"coin_pair":"bitcoin/skycoin","best_bid":25,"best_ask":null,"spread":null,"last_price":null,"volume":0,"time":1470196900}`, d)
+
+	res.BestAsk = pp.PtrUint64(26)
+	res.Spread = pp.PtrUint64(1)
+	res.LastPrice = pp.PtrUint64(26)
+	res.Volume = pp.PtrUint64(230000)
+	d, err = makeTickerJSON(&res)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"coin_pair":"bitcoin/skycoin","best_bid":25,"best_ask":26,"spread":1,"last_price":26,"volume":230000,"time":1470196900}`, d)
+}
+
+func TestGetWalletTransactions(t *testing.T) {
+	tmpDir, teardown, err := setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	btcTx := func(txid string, tm int64) *pp.Tx {
+		return &pp.Tx{Btc: &pp.BtcTx{Txid: pp.PtrString(txid), Time: pp.PtrInt64(tm)}}
+	}
+	skyTx := func(hash string, confirmed bool, height uint64) *pp.Tx {
+		return &pp.Tx{Sky: &pp.SkyTx{
+			Hash:      pp.PtrString(hash),
+			Confirmed: pp.PtrBool(confirmed),
+			Height:    pp.PtrUint64(height),
+		}}
+	}
+
+	btcM := NewCoinerMock()
+	btcM.On("Name").Return("bitcoin")
+	btcM.On("GetTransactions", mock.AnythingOfType("[]string")).
+		Return([]*pp.Tx{btcTx("a", 100), btcTx("b", 0), btcTx("c", 300)}, nil)
+
+	skyM := NewCoinerMock()
+	skyM.On("Name").Return("skycoin")
+	skyM.On("GetTransactions", mock.AnythingOfType("[]string")).
+		Return([]*pp.Tx{skyTx("a", true, 10), skyTx("b", true, 20), skyTx("c", false, 0)}, nil)
+
+	mzM := NewCoinerMock()
+	mzM.On("Name").Return("mzcoin")
+	mzM.On("GetTransactions", mock.AnythingOfType("[]string")).
+		Return(nil, errors.New("get mzcoin transactions failed"))
+
+	initConfig(&Config{WalletDirPath: tmpDir}, btcM, skyM, mzM)
+
+	for _, tp := range []string{"bitcoin", "skycoin", "mzcoin"} {
+		id, err := NewWallet(tp, "123", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewAddress(id, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		coinType string
+		wltID    string
+		want     []string
+		wantErr  bool
+	}{
+		{"bitcoin normal", "bitcoin", wallet.MakeWltID("bitcoin", "123"), []string{"b", "c", "a"}, false},
+		{"skycoin normal", "skycoin", wallet.MakeWltID("skycoin", "123"), []string{"c", "b", "a"}, false},
+		{"gateway error", "mzcoin", wallet.MakeWltID("mzcoin", "123"), nil, true},
+		{"unmatched wallet", "bitcoin", wallet.MakeWltID("skycoin", "123"), nil, true},
+		{"unknown coin", "unknown", "unknown_123", nil, true},
+		{"unknown wallet", "bitcoin", "bitcoin_456", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := GetWalletTransactions(tt.coinType, tt.wltID)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. GetWalletTransactions() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+
+		txs := []*pp.Tx{}
+		if err := json.Unmarshal([]byte(got), &txs); err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]string, len(txs))
+		for i, tx := range txs {
+			if tt.coinType == "bitcoin" {
+				ids[i] = tx.GetBtc().GetTxid()
+			} else {
+				ids[i] = tx.GetSky().GetHash()
+			}
+		}
+		assert.Equal(t, tt.want, ids, tt.name)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		raw      uint64
+		decimals int
+		want     string
+	}{
+		{0, 8, "0"},
+		{1, 8, "0.00000001"},
+		{150000000, 8, "1.5"},
+		{2100000000000000, 8, "21000000"},
+		{123456789, 8, "1.23456789"},
+		{0, 6, "0"},
+		{1, 6, "0.000001"},
+		{1500000, 6, "1.5"},
+		{998000000, 6, "998"},
+		{18446744073709551615, 6, "18446744073709.551615"},
+		{123, 0, "123"},
+	}
+	for _, tt := range tests {
+		got := formatAmount(tt.raw, tt.decimals)
+		assert.Equal(t, tt.want, got, "%d with %d decimals", tt.raw, tt.decimals)
+
+		// parsing the formatted amount gives back the raw one.
+		amt, err := parseAmount(got, tt.decimals)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.raw, amt)
+	}
+
+	btcM := NewCoinerMock()
+	btcM.On("Name").Return("bitcoin")
+	btcM.On("Decimals").Return(8)
+	skyM := NewCoinerMock()
+	skyM.On("Name").Return("skycoin")
+	skyM.On("Decimals").Return(6)
+	initConfig(&Config{}, btcM, skyM)
+
+	s, err := FormatAmount("bitcoin", 150000000)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.5", s)
+	s, err = FormatAmount("skycoin", 150000000)
+	assert.Nil(t, err)
+	assert.Equal(t, "150", s)
+	_, err = FormatAmount("dogecoin", 1)
+	assert.NotNil(t, err)
+
+	amt, err := ParseAmount("bitcoin", "0.015")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1500000), amt)
+	amt, err = ParseAmount("skycoin", "0.015")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(15000), amt)
+	_, err = ParseAmount("skycoin", "0.0000001")
+	assert.NotNil(t, err)
+	_, err = ParseAmount("dogecoin", "1")
+	assert.NotNil(t, err)
+}
+
+func TestEstimateFee(t *testing.T) {
+	btcM := NewCoinerMock()
+	btcM.On("Name").Return("bitcoin")
+	btcM.On("EstimateFee", 2, 2).Return(uint64(7480), nil)
+	btcM.On("Decimals").Return(8)
+	btcM.On("EstimateFee", 0, 2).Return(uint64(0), errors.New("invalid inputs number 0 or outputs number 2"))
+
+	skyM := NewCoinerMock()
+	skyM.On("Name").Return("skycoin")
+	skyM.On("EstimateFee", 2, 2).Return(uint64(0), nil)
+	skyM.On("Decimals").Return(6)
+
+	initConfig(&Config{}, btcM, skyM)
+
+	tests := []struct {
+		name     string
+		coinType string
+		nIn      int
+		nOut     int
+		want     string
+		wantErr  bool
+	}{
+		{"bitcoin normal", "bitcoin", 2, 2, `{"fee":7480,"fee_formatted":"0.0000748"}`, false},
+		{"bitcoin invalid inputs", "bitcoin", 0, 2, "", true},
+		{"skycoin normal", "skycoin", 2, 2, `{"fee":0,"fee_formatted":"0"}`, false},
+		{"unknown coin", "unknown", 2, 2, "", true},
+	}
+	for _, tt := range tests {
+		got, err := EstimateFee(tt.coinType, tt.nIn, tt.nOut)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. EstimateFee() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q. EstimateFee() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetFeeEstimates(t *testing.T) {
+	btcM := NewCoinerMock()
+	btcM.On("Name").Return("bitcoin")
+	btcM.On("GetFeeEstimates").Return([]coin.FeeEstimate{{Blocks: 1, FeeRate: 45}, {Blocks: 3, FeeRate: 30}}, nil).Once()
+	btcM.On("GetFeeEstimates").Return(nil, errors.New("access node failed"))
+
+	skyM := NewCoinerMock()
+	skyM.On("Name").Return("skycoin")
+	skyM.On("GetFeeEstimates").Return(coin.StaticFeeEstimates(0), nil)
+
+	initConfig(&Config{}, btcM, skyM)
+
+	tests := []struct {
+		name     string
+		coinType string
+		want     string
+		wantErr  bool
+	}{
+		{"bitcoin normal", "bitcoin", `{"estimates":[{"blocks":1,"fee_rate":45},{"blocks":3,"fee_rate":30}]}`, false},
+		{"bitcoin node error", "bitcoin", "", true},
+		{"skycoin normal", "skycoin", `{"estimates":[{"blocks":1,"fee_rate":0},{"blocks":3,"fee_rate":0},{"blocks":6,"fee_rate":0}]}`, false},
+		{"unknown coin", "unknown", "", true},
+	}
+	for _, tt := range tests {
+		got, err := GetFeeEstimates(tt.coinType)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. GetFeeEstimates() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q. GetFeeEstimates() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScanAddresses(t *testing.T) {
+	tmpDir, teardown, err := setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	// the 1st and 4th addresses are used.
+	btcM := NewCoinerMock()
+	btcM.On("Name").Return("bitcoin")
+	for _, bal := range []uint64{100, 0, 0, 50} {
+		btcM.On("GetBalance", mock.AnythingOfType("[]string")).Return(bal, nil).Once()
+	}
+	btcM.On("GetBalance", mock.AnythingOfType("[]string")).Return(uint64(0), nil).Times(3)
+
+	skyM := NewCoinerMock()
+	skyM.On("Name").Return("skycoin")
+	skyM.On("GetBalance", mock.AnythingOfType("[]string")).Return(uint64(0), errors.New("get balance failed"))
+
+	initConfig(&Config{WalletDirPath: tmpDir}, btcM, skyM)
+
+	id, err := NewWallet("bitcoin", "123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := ScanAddresses(id, 3)
+	assert.Nil(t, err)
+	var es struct {
+		Entries []struct {
+			Address string `json:"address"`
+		} `json:"addresses"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(res), &es))
+	assert.Equal(t, 2, len(es.Entries))
+
+	// the scan stops after 3 consecutive unused addresses.
+	btcM.AssertNumberOfCalls(t, "GetBalance", 7)
+	addrs, err := wallet.GetAddresses(id)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(addrs))
+
+	// gateway error.
+	skyID, err := NewWallet("skycoin", "123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ScanAddresses(skyID, 3)
+	assert.NotNil(t, err)
+
+	// unknown coin.
+	_, err = ScanAddresses("unknown_123", 3)
+	assert.NotNil(t, err)
+
+	// gap limit is required.
+	_, err = ScanAddresses(id, 0)
+	assert.NotNil(t, err)
+}
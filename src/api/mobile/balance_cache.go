@@ -0,0 +1,71 @@
+package mobile
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultBalanceCacheTTL the seconds a wallet balance is cached if Config.BalanceCacheTTL is 0.
+const DefaultBalanceCacheTTL = 10
+
+type balanceEntry struct {
+	balance uint64
+	expire  time.Time
+}
+
+// balanceCache caches the wallet balances by wallet id and coin type, so that the
+// repeated queries don't go through the slow mobile networks.
+type balanceCache struct {
+	mtx     sync.Mutex
+	ttl     time.Duration
+	entries map[string]map[string]balanceEntry // wallet id -> coin type -> balance
+	now     func() time.Time
+}
+
+// newBalanceCache creates the cache, the balances are not cached if ttl is not positive.
+func newBalanceCache(ttl time.Duration) *balanceCache {
+	return &balanceCache{
+		ttl:     ttl,
+		entries: make(map[string]map[string]balanceEntry),
+		now:     time.Now,
+	}
+}
+
+// get returns the cached balance if it's not expired.
+func (bc *balanceCache) get(walletID, coinType string) (uint64, bool) {
+	bc.mtx.Lock()
+	defer bc.mtx.Unlock()
+	e, ok := bc.entries[walletID][coinType]
+	if !ok || !bc.now().Before(e.expire) {
+		return 0, false
+	}
+	return e.balance, true
+}
+
+func (bc *balanceCache) set(walletID, coinType string, bal uint64) {
+	if bc.ttl <= 0 {
+		return
+	}
+
+	bc.mtx.Lock()
+	defer bc.mtx.Unlock()
+	if _, ok := bc.entries[walletID]; !ok {
+		bc.entries[walletID] = make(map[string]balanceEntry)
+	}
+	bc.entries[walletID][coinType] = balanceEntry{balance: bal, expire: bc.now().Add(bc.ttl)}
+}
+
+// invalidate removes the cached balances of the wallet.
+func (bc *balanceCache) invalidate(walletID string) {
+	bc.mtx.Lock()
+	defer bc.mtx.Unlock()
+	delete(bc.entries, walletID)
+}
+
+// balanceCacheTTL returns the ttl of Config.BalanceCacheTTL seconds.
+func balanceCacheTTL(cfg *Config) time.Duration {
+	if cfg.BalanceCacheTTL == 0 {
+		return DefaultBalanceCacheTTL * time.Second
+	}
+	return time.Duration(cfg.BalanceCacheTTL) * time.Second
+}
@@ -10,44 +10,71 @@ import (
 
 	"github.com/skycoin/skycoin-exchange/src/coin"
 	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	litecoin "github.com/skycoin/skycoin-exchange/src/coin/litecoin"
 	"github.com/skycoin/skycoin-exchange/src/pp"
 	"github.com/skycoin/skycoin-exchange/src/sknet"
 	"github.com/skycoin/skycoin-exchange/src/wallet"
-	"github.com/skycoin/skycoin/src/cipher"
 )
 
+// bitcoinCli serves bitcoin and the coins sharing bitcoin's utxo model, like litecoin.
 type bitcoinCli struct {
-	NodeAddr string
-	fee      string // bitcoin fee
+	NodeAddr     string
+	fee          string                  // transaction fee
+	name         string                  // coin type
+	gateway      coin.TxHandler          // for creating and signing raw transactions
+	validateAddr func(addr string) error // address validator
+	decimals     int                     // decimal places of the base unit
+	dryRun       bool                    // build the unsigned transaction only
 }
 
 type btcSendParams struct {
 	WalletID string
-	ToAddr   string
-	Amount   uint64
+	Outs     []TxOut
 	Fee      uint64
 }
 
 func newBitcoin(nodeAddr string) *bitcoinCli {
-	return &bitcoinCli{NodeAddr: nodeAddr, fee: "2000"} // default transaction fee is 2000
+	return &bitcoinCli{
+		NodeAddr:     nodeAddr,
+		fee:          "2000", // default transaction fee is 2000
+		name:         bitcoin.Type,
+		gateway:      &bitcoin.Bitcoin{},
+		validateAddr: bitcoin.ValidateAddr,
+		decimals:     (&bitcoin.Bitcoin{}).Decimals(),
+	}
+}
+
+func newLitecoin(nodeAddr string) *bitcoinCli {
+	return &bitcoinCli{
+		NodeAddr:     nodeAddr,
+		fee:          "100000", // default transaction fee is 0.001 LTC
+		name:         litecoin.Type,
+		gateway:      litecoin.New(),
+		validateAddr: litecoin.ValidateAddr,
+		decimals:     litecoin.New().Decimals(),
+	}
 }
 
 func (bn bitcoinCli) Name() string {
-	return "bitcoin"
+	return bn.name
 }
 
 func (bn bitcoinCli) GetNodeAddr() string {
 	return bn.NodeAddr
 }
 
+// Decimals returns the decimal places of the coin's base unit.
+func (bn bitcoinCli) Decimals() int {
+	return bn.decimals
+}
+
 func (bn bitcoinCli) ValidateAddr(address string) error {
-	_, err := cipher.BitcoinDecodeBase58Address(address)
-	return err
+	return bn.validateAddr(address)
 }
 
 func (bn bitcoinCli) GetBalance(addrs []string) (uint64, error) {
 	req := pp.GetUtxoReq{
-		CoinType:  pp.PtrString("bitcoin"),
+		CoinType:  pp.PtrString(bn.name),
 		Addresses: addrs,
 	}
 	res := pp.GetUtxoRes{}
@@ -62,19 +89,23 @@ func (bn bitcoinCli) GetBalance(addrs []string) (uint64, error) {
 	return bal, nil
 }
 
+// GetDetailedBalance gets the confirmed and unconfirmed balance of specific addresses.
+func (bn bitcoinCli) GetDetailedBalance(addrs []string) (coin.DetailedBalance, error) {
+	return getDetailedBalance(bn.NodeAddr, bn.name, addrs)
+}
+
 func (bn bitcoinCli) CreateRawTx(txIns []coin.TxIn, getKey coin.GetPrivKey, txOuts interface{}) (string, error) {
-	coin := bitcoin.Bitcoin{}
-	rawtx, err := coin.CreateRawTx(txIns, txOuts)
+	rawtx, err := bn.gateway.CreateRawTx(txIns, txOuts)
 	if err != nil {
 		return "", fmt.Errorf("create raw tx failed:%v", err)
 	}
 
-	return coin.SignRawTx(rawtx, getKey)
+	return bn.gateway.SignRawTx(rawtx, getKey)
 }
 
 func (bn bitcoinCli) BroadcastTx(rawtx string) (string, error) {
 	req := pp.InjectTxnReq{
-		CoinType: pp.PtrString("bitcoin"),
+		CoinType: pp.PtrString(bn.name),
 		Tx:       pp.PtrString(rawtx),
 	}
 	res := pp.InjectTxnRes{}
@@ -91,7 +122,7 @@ func (bn bitcoinCli) BroadcastTx(rawtx string) (string, error) {
 
 func (bn bitcoinCli) GetTransactionByID(txid string) (string, error) {
 	req := pp.GetTxReq{
-		CoinType: pp.PtrString("bitcoin"),
+		CoinType: pp.PtrString(bn.name),
 		Txid:     pp.PtrString(txid),
 	}
 	res := pp.GetTxRes{}
@@ -100,7 +131,7 @@ func (bn bitcoinCli) GetTransactionByID(txid string) (string, error) {
 	}
 
 	if !res.Result.GetSuccess() {
-		return "", fmt.Errorf("get bitcoin transaction by id failed: %v", res.Result.GetReason())
+		return "", fmt.Errorf("get %s transaction by id failed: %v", bn.name, res.Result.GetReason())
 	}
 
 	d, err := json.Marshal(res.GetTx())
@@ -110,6 +141,30 @@ func (bn bitcoinCli) GetTransactionByID(txid string) (string, error) {
 	return string(d), nil
 }
 
+func (bn bitcoinCli) GetTransactions(addrs []string) ([]*pp.Tx, error) {
+	req := pp.GetAddrTxsReq{
+		CoinType:  pp.PtrString(bn.name),
+		Addresses: addrs,
+	}
+	res := pp.GetAddrTxsRes{}
+	if err := sknet.EncryGet(bn.NodeAddr, "/get/address/txs", req, &res); err != nil {
+		return nil, err
+	}
+
+	if !res.Result.GetSuccess() {
+		return nil, fmt.Errorf("get %s transactions failed: %v", bn.name, res.Result.GetReason())
+	}
+	return res.GetTxs(), nil
+}
+
+func (bn bitcoinCli) EstimateFee(nIn, nOut int) (uint64, error) {
+	return bn.gateway.EstimateFee(nIn, nOut)
+}
+
+func (bn bitcoinCli) GetFeeEstimates() ([]coin.FeeEstimate, error) {
+	return bn.gateway.GetFeeEstimates()
+}
+
 // Fee option for setting transaction fee.
 func Fee(n string) Option {
 	return func(v interface{}) {
@@ -118,19 +173,39 @@ func Fee(n string) Option {
 	}
 }
 
-// Send amount bitcoins to address from specific wallet
-func (bn bitcoinCli) Send(walletID, toAddr, amount string, ops ...Option) (string, error) {
-	btc := newBitcoin(bn.NodeAddr)
-	for _, op := range ops {
-		op(btc)
+// DryRun option for building the unsigned transaction only, the transaction
+// won't be signed or broadcasted.
+func DryRun() Option {
+	return func(v interface{}) {
+		switch c := v.(type) {
+		case *bitcoinCli:
+			c.dryRun = true
+		case *coinEx:
+			c.dryRun = true
+		case *ethereumCli:
+			c.dryRun = true
+		}
 	}
+}
 
+// Send amount coins to address from specific wallet
+func (bn bitcoinCli) Send(walletID, toAddr, amount string, ops ...Option) (string, error) {
 	// validate amount
 	amt, err := strconv.ParseUint(amount, 10, 64)
 	if err != nil {
 		return "", fmt.Errorf("parse amount string to uint64 failed: %v", err)
 	}
 
+	return bn.SendMany(walletID, []TxOut{{Address: toAddr, Amount: amt}}, ops...)
+}
+
+// SendMany sends coins to multiple recipients from specific wallet in one transaction.
+func (bn bitcoinCli) SendMany(walletID string, outs []TxOut, ops ...Option) (string, error) {
+	btc := bn
+	for _, op := range ops {
+		op(&btc)
+	}
+
 	// validate fee
 	fe, err := strconv.ParseUint(btc.fee, 10, 64)
 	if err != nil {
@@ -141,39 +216,23 @@ func (bn bitcoinCli) Send(walletID, toAddr, amount string, ops ...Option) (strin
 		return "", fmt.Errorf("insufficient fee")
 	}
 
-	params := btcSendParams{WalletID: walletID, ToAddr: toAddr, Amount: amt, Fee: fe}
-
-	txIns, txOut, err := bn.PrepareTx(params)
-	if err != nil {
-		return "", err
-	}
-
-	rawtx, err := bn.CreateRawTx(txIns, getPrivateKey(walletID), txOut)
-	if err != nil {
-		return "", fmt.Errorf("create raw transaction failed:%v", err)
-	}
-
-	txid, err := bn.BroadcastTx(rawtx)
-	if err != nil {
-		return "", err
-	}
-	return fmt.Sprintf(`{"txid":"%s"}`, txid), nil
+	params := btcSendParams{WalletID: walletID, Outs: outs, Fee: fe}
+	return sendTx(btc, walletID, params, fe, btc.dryRun)
 }
 
 func (bn bitcoinCli) GetOutputByID(outid string) (string, error) {
-	return "", errors.New("bitcion does not support GetOutputByID method")
+	return "", fmt.Errorf("%s does not support GetOutputByID method", bn.name)
 }
 
 func (bn bitcoinCli) PrepareTx(params interface{}) ([]coin.TxIn, interface{}, error) {
 	p := params.(btcSendParams)
 
 	tp := strings.Split(p.WalletID, "_")[0]
-	if tp != "bitcoin" {
+	if tp != bn.name {
 		return nil, nil, fmt.Errorf("invalid wallet %v", tp)
 	}
 
-	// valid address
-	if err := bn.ValidateAddr(p.ToAddr); err != nil {
+	if _, err := validateOuts(p.Outs, bn.ValidateAddr); err != nil {
 		return nil, nil, err
 	}
 
@@ -187,7 +246,18 @@ func (bn bitcoinCli) PrepareTx(params interface{}) ([]coin.TxIn, interface{}, er
 		return nil, nil, err
 	}
 
-	utxos, bal, err := bn.getSufficientOutputs(totalUtxos, p.Amount+p.Fee)
+	return bn.buildTx(p, totalUtxos, addrs[0])
+}
+
+// buildTx chooses sufficient utxos for the outputs and fee, and makes the transaction
+// inputs and outputs, the change goes back to chgAddr.
+func (bn bitcoinCli) buildTx(p btcSendParams, totalUtxos []*pp.BtcUtxo, chgAddr string) ([]coin.TxIn, []bitcoin.TxOut, error) {
+	amount, err := validateOuts(p.Outs, bn.ValidateAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	utxos, bal, err := bn.getSufficientOutputs(totalUtxos, amount+p.Fee)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -202,14 +272,11 @@ func (bn bitcoinCli) PrepareTx(params interface{}) ([]coin.TxIn, interface{}, er
 	}
 
 	var txOut []bitcoin.TxOut
-	chgAmt := bal - p.Amount - p.Fee
-	chgAddr := addrs[0]
-	if chgAmt > 0 {
-		txOut = append(txOut,
-			bn.makeTxOut(p.ToAddr, p.Amount),
-			bn.makeTxOut(chgAddr, chgAmt))
-	} else {
-		txOut = append(txOut, bn.makeTxOut(p.ToAddr, p.Amount))
+	for _, o := range p.Outs {
+		txOut = append(txOut, bn.makeTxOut(o.Address, o.Amount))
+	}
+	if chgAmt := bal - amount - p.Fee; chgAmt > 0 {
+		txOut = append(txOut, bn.makeTxOut(chgAddr, chgAmt))
 	}
 
 	return txIns, txOut, nil
@@ -252,7 +319,7 @@ func (bn bitcoinCli) getSufficientOutputs(utxos []*pp.BtcUtxo, amt uint64) ([]*p
 
 func (bn bitcoinCli) getOutputs(addrs []string) ([]*pp.BtcUtxo, error) {
 	req := pp.GetUtxoReq{
-		CoinType:  pp.PtrString("bitcoin"),
+		CoinType:  pp.PtrString(bn.name),
 		Addresses: addrs,
 	}
 	res := pp.GetUtxoRes{}
@@ -11,6 +11,8 @@ import (
 	"strings"
 
 	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	"github.com/skycoin/skycoin-exchange/src/coin/ethereum"
 	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
 	"github.com/skycoin/skycoin-exchange/src/pp"
 	"github.com/skycoin/skycoin-exchange/src/sknet"
@@ -25,26 +27,38 @@ type Option func(c interface{})
 type Coiner interface {
 	Name() string
 	GetBalance(addrs []string) (uint64, error)
+	GetDetailedBalance(addrs []string) (coin.DetailedBalance, error)
 	ValidateAddr(addr string) error
 	PrepareTx(params interface{}) ([]coin.TxIn, interface{}, error)
 	CreateRawTx(txIns []coin.TxIn, getKey coin.GetPrivKey, txOuts interface{}) (string, error)
 	BroadcastTx(rawtx string) (string, error)
 	GetTransactionByID(txid string) (string, error)
 	GetOutputByID(outid string) (string, error)
+	GetTransactions(addrs []string) ([]*pp.Tx, error)
+	EstimateFee(nIn, nOut int) (uint64, error)
+	GetFeeEstimates() ([]coin.FeeEstimate, error)
 	GetNodeAddr() string
+	Decimals() int // decimal places of the coin's base unit.
 	Send(walletID string, toAddr string, amount string, ops ...Option) (string, error)
+	SendMany(walletID string, outs []TxOut, ops ...Option) (string, error)
+}
+
+// TxOut is a recipient of the transaction, amount is in the coin's base units.
+type TxOut struct {
+	Address string
+	Amount  uint64
 }
 
 // CoinEx implements the Coin interface.
 type coinEx struct {
 	name     string
 	nodeAddr string
+	dryRun   bool
 }
 
 type sendParams struct {
 	WalletID string
-	ToAddr   string
-	Amount   uint64
+	Outs     []TxOut
 }
 
 func newCoin(name, nodeAddr string) *coinEx {
@@ -60,6 +74,11 @@ func (cn coinEx) GetNodeAddr() string {
 	return cn.nodeAddr
 }
 
+// Decimals returns the decimal places of skycoin, mzcoin shares it.
+func (cn coinEx) Decimals() int {
+	return (&skycoin.Skycoin{}).Decimals()
+}
+
 func (cn coinEx) getOutputs(addrs []string) ([]*pp.SkyUtxo, error) {
 	req := pp.GetUtxoReq{
 		CoinType:  pp.PtrString(cn.name),
@@ -92,6 +111,33 @@ func (cn coinEx) GetBalance(addrs []string) (uint64, error) {
 	return bal, nil
 }
 
+// GetDetailedBalance gets the confirmed and unconfirmed balance of specific addresses.
+func (cn coinEx) GetDetailedBalance(addrs []string) (coin.DetailedBalance, error) {
+	return getDetailedBalance(cn.nodeAddr, cn.name, addrs)
+}
+
+// getDetailedBalance queries the detailed balance of addresses from the exchange server.
+func getDetailedBalance(servAddr, coinType string, addrs []string) (coin.DetailedBalance, error) {
+	req := pp.GetAddrDetailedBalanceReq{
+		CoinType: pp.PtrString(coinType),
+		Addrs:    pp.PtrString(strings.Join(addrs, ",")),
+	}
+	res := pp.GetAddrDetailedBalanceRes{}
+	if err := sknet.EncryGet(servAddr, "/get/address/balance/detail", req, &res); err != nil {
+		return coin.DetailedBalance{}, err
+	}
+
+	if !res.Result.GetSuccess() {
+		return coin.DetailedBalance{}, fmt.Errorf("get detailed balance failed: %v", res.Result.GetReason())
+	}
+
+	return coin.DetailedBalance{
+		Confirmed:   res.GetConfirmed(),
+		Unconfirmed: res.GetUnconfirmed(),
+		Total:       res.GetTotal(),
+	}, nil
+}
+
 // ValidateAddr check if the address is validated
 func (cn coinEx) ValidateAddr(address string) error {
 	_, err := cipher.DecodeBase58Address(address)
@@ -114,8 +160,8 @@ func (cn coinEx) CreateRawTx(txIns []coin.TxIn, getKey coin.GetPrivKey, txOuts i
 		outs[i] = s.Index(i).Interface()
 	}
 
-	if len(outs) > 2 {
-		return "", errors.New("out address more than 2")
+	if len(outs) == 0 {
+		return "", errors.New("no output address")
 	}
 
 	for _, o := range outs {
@@ -188,6 +234,33 @@ func (cn coinEx) GetTransactionByID(txid string) (string, error) {
 	return string(d), nil
 }
 
+// GetTransactions gets transactions of specific addresses
+func (cn coinEx) GetTransactions(addrs []string) ([]*pp.Tx, error) {
+	req := pp.GetAddrTxsReq{
+		CoinType:  pp.PtrString(cn.name),
+		Addresses: addrs,
+	}
+	res := pp.GetAddrTxsRes{}
+	if err := sknet.EncryGet(cn.nodeAddr, "/get/address/txs", req, &res); err != nil {
+		return nil, err
+	}
+
+	if !res.Result.GetSuccess() {
+		return nil, fmt.Errorf("get %s transactions failed: %v", cn.Name(), res.Result.GetReason())
+	}
+	return res.GetTxs(), nil
+}
+
+// EstimateFee estimates the transaction fee
+func (cn coinEx) EstimateFee(nIn, nOut int) (uint64, error) {
+	return skycoin.New(cn.nodeAddr).EstimateFee(nIn, nOut)
+}
+
+// GetFeeEstimates returns the fee rates of the confirmation targets.
+func (cn coinEx) GetFeeEstimates() ([]coin.FeeEstimate, error) {
+	return skycoin.New(cn.nodeAddr).GetFeeEstimates()
+}
+
 // PrepareTx prepares the transaction info
 func (cn coinEx) PrepareTx(params interface{}) ([]coin.TxIn, interface{}, error) {
 	p := params.(sendParams)
@@ -197,58 +270,100 @@ func (cn coinEx) PrepareTx(params interface{}) ([]coin.TxIn, interface{}, error)
 		return nil, nil, fmt.Errorf("invalid wallet %v", tp)
 	}
 
-	// validate address
-	if err := cn.ValidateAddr(p.ToAddr); err != nil {
+	addrs, err := wallet.GetAddresses(p.WalletID)
+	if err != nil {
 		return nil, nil, err
 	}
 
-	addrs, err := wallet.GetAddresses(p.WalletID)
+	totalUtxos, err := cn.getOutputs(addrs)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// outMap := make(map[string][]*pp.SkyUtxo)
-	totalUtxos, err := cn.getOutputs(addrs)
+	return cn.buildTx(p, totalUtxos, addrs[0])
+}
+
+// buildTx chooses the utxos for the recipients, and makes the outputs, the rest coins are
+// sent to chgAddr. The coin hours of the inputs are shared by the outputs as skycoin.DistributeHours,
+// if the chosen utxos have no coin hours, the utxo with most hours is added to pay the fee.
+func (cn coinEx) buildTx(p sendParams, totalUtxos []*pp.SkyUtxo, chgAddr string) ([]coin.TxIn, []skycoin.TxOut, error) {
+	amount, err := validateOuts(p.Outs, cn.ValidateAddr)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	utxos, err := cn.getSufficientOutputs(totalUtxos, p.Amount)
+	utxos, err := cn.getSufficientOutputs(totalUtxos, amount)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	bal, hours := func(utxos []*pp.SkyUtxo) (uint64, uint64) {
-		var c, h uint64
-		for _, u := range utxos {
-			c += u.GetCoins()
-			h += u.GetHours()
+	bal, hours := sumOutputs(utxos)
+	if hours == 0 {
+		if u := mostHoursOutput(totalUtxos, utxos); u != nil {
+			utxos = append(utxos, u)
+			bal, hours = sumOutputs(utxos)
 		}
-		return c, h
-	}(utxos)
+	}
 
 	txIns := make([]coin.TxIn, len(utxos))
 	for i, u := range utxos {
 		txIns[i] = coin.TxIn{
 			Txid:    u.GetHash(),
 			Address: u.GetAddress(),
+			Hours:   u.GetHours(),
 		}
 	}
 
+	chgAmt := bal - amount
+	nOuts := len(p.Outs)
+	if chgAmt > 0 {
+		nOuts++
+	}
+
+	outHours, _, err := skycoin.DistributeHours(hours, nOuts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", cn.Name(), err)
+	}
+
 	var txOut []skycoin.TxOut
-	chgAmt := bal - p.Amount
-	chgHours := hours / 4
-	chgAddr := addrs[0]
+	for _, o := range p.Outs {
+		txOut = append(txOut, cn.makeTxOut(o.Address, o.Amount, outHours))
+	}
 	if chgAmt > 0 {
-		txOut = append(txOut,
-			cn.makeTxOut(p.ToAddr, p.Amount, chgHours/2),
-			cn.makeTxOut(chgAddr, chgAmt, chgHours/2))
-	} else {
-		txOut = append(txOut, cn.makeTxOut(p.ToAddr, p.Amount, chgHours/2))
+		txOut = append(txOut, cn.makeTxOut(chgAddr, chgAmt, outHours))
 	}
 	return txIns, txOut, nil
 }
 
+// sumOutputs returns the total coins and coin hours of the utxos.
+func sumOutputs(utxos []*pp.SkyUtxo) (uint64, uint64) {
+	var c, h uint64
+	for _, u := range utxos {
+		c += u.GetCoins()
+		h += u.GetHours()
+	}
+	return c, h
+}
+
+// mostHoursOutput returns the utxo with most coin hours that is not chosen, nil if none has hours.
+func mostHoursOutput(utxos, chosen []*pp.SkyUtxo) *pp.SkyUtxo {
+	used := make(map[string]bool, len(chosen))
+	for _, u := range chosen {
+		used[u.GetHash()] = true
+	}
+
+	var most *pp.SkyUtxo
+	for _, u := range utxos {
+		if used[u.GetHash()] || u.GetHours() == 0 {
+			continue
+		}
+		if most == nil || u.GetHours() > most.GetHours() {
+			most = u
+		}
+	}
+	return most
+}
+
 // Send sends numbers of coins to toAddr from specific wallet
 func (cn *coinEx) Send(walletID, toAddr, amount string, ops ...Option) (string, error) {
 	for _, op := range ops {
@@ -261,24 +376,20 @@ func (cn *coinEx) Send(walletID, toAddr, amount string, ops ...Option) (string,
 		return "", fmt.Errorf("parse amount string to uint64 failed: %v", err)
 	}
 
-	params := sendParams{WalletID: walletID, ToAddr: toAddr, Amount: amt}
+	return cn.SendMany(walletID, []TxOut{{Address: toAddr, Amount: amt}})
+}
 
-	txIns, txOut, err := cn.PrepareTx(params)
-	if err != nil {
-		return "", err
+// SendMany sends coins to multiple recipients from specific wallet in one transaction.
+func (cn *coinEx) SendMany(walletID string, outs []TxOut, ops ...Option) (string, error) {
+	c := *cn
+	for _, op := range ops {
+		op(&c)
 	}
 
-	// prepare keys
-	rawtx, err := cn.CreateRawTx(txIns, getPrivateKey(walletID), txOut)
-	if err != nil {
-		return "", fmt.Errorf("create raw transaction failed:%v", err)
-	}
+	params := sendParams{WalletID: walletID, Outs: outs}
 
-	txid, err := cn.BroadcastTx(rawtx)
-	if err != nil {
-		return "", err
-	}
-	return fmt.Sprintf(`{"txid":"%s"}`, txid), nil
+	// the fee of skycoin is paid by coin hours.
+	return sendTx(&c, walletID, params, 0, c.dryRun)
 }
 
 func (cn coinEx) GetOutputByID(outid string) (string, error) {
@@ -340,3 +451,124 @@ func (cn coinEx) makeTxOut(addr string, coins uint64, hours uint64) skycoin.TxOu
 	out.Hours = hours
 	return out
 }
+
+// validateOuts checks the recipients' addresses and amounts, duplicate addresses are
+// rejected, returns the total amount of the outputs.
+func validateOuts(outs []TxOut, validateAddr func(string) error) (uint64, error) {
+	if len(outs) == 0 {
+		return 0, errors.New("no recipient")
+	}
+
+	var total uint64
+	addrs := make(map[string]bool, len(outs))
+	for _, o := range outs {
+		if err := validateAddr(o.Address); err != nil {
+			return 0, fmt.Errorf("invalid address %s: %v", o.Address, err)
+		}
+		if addrs[o.Address] {
+			return 0, fmt.Errorf("duplicate recipient %s", o.Address)
+		}
+		addrs[o.Address] = true
+
+		if o.Amount == 0 {
+			return 0, fmt.Errorf("zero amount to %s", o.Address)
+		}
+		if total+o.Amount < total {
+			return 0, errors.New("total amount overflows")
+		}
+		total += o.Amount
+	}
+	return total, nil
+}
+
+// UnsignedTx is the transaction built in dry run mode, the fee of skycoin is paid
+// by burning the coin hours, which is shown by HoursFee.
+type UnsignedTx struct {
+	Inputs   []UnsignedTxIn  `json:"inputs"`
+	Outputs  []UnsignedTxOut `json:"outputs"`
+	Fee      uint64          `json:"fee"`
+	HoursFee uint64          `json:"hours_fee,omitempty"`
+}
+
+// UnsignedTxIn the utxo spent by the unsigned transaction, vout is not used by skycoin,
+// and hours is only used by skycoin.
+type UnsignedTxIn struct {
+	Txid    string `json:"txid"`
+	Vout    uint32 `json:"vout"`
+	Address string `json:"address"`
+	Hours   uint64 `json:"hours,omitempty"`
+}
+
+// UnsignedTxOut the output of unsigned transaction, hours is only used by skycoin.
+type UnsignedTxOut struct {
+	Address string `json:"address"`
+	Amount  uint64 `json:"amount"`
+	Hours   uint64 `json:"hours,omitempty"`
+}
+
+// sendTx prepares the transaction of params, signs and broadcasts it, returns the txid json.
+// In dry run mode, the unsigned transaction json is returned instead, and nothing is broadcasted.
+func sendTx(c Coiner, walletID string, params interface{}, fee uint64, dryRun bool) (string, error) {
+	txIns, txOuts, err := c.PrepareTx(params)
+	if err != nil {
+		return "", err
+	}
+
+	if dryRun {
+		tx, err := makeUnsignedTx(txIns, txOuts, fee)
+		if err != nil {
+			return "", err
+		}
+		d, err := json.Marshal(tx)
+		if err != nil {
+			return "", err
+		}
+		return string(d), nil
+	}
+
+	rawtx, err := c.CreateRawTx(txIns, getPrivateKey(walletID), txOuts)
+	if err != nil {
+		return "", fmt.Errorf("create raw transaction failed:%v", err)
+	}
+
+	txid, err := c.BroadcastTx(rawtx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`{"txid":"%s"}`, txid), nil
+}
+
+func makeUnsignedTx(txIns []coin.TxIn, txOuts interface{}, fee uint64) (*UnsignedTx, error) {
+	tx := UnsignedTx{
+		Inputs: make([]UnsignedTxIn, len(txIns)),
+		Fee:    fee,
+	}
+	var inHours uint64
+	for i, in := range txIns {
+		tx.Inputs[i] = UnsignedTxIn{Txid: in.Txid, Vout: in.Vout, Address: in.Address, Hours: in.Hours}
+		inHours += in.Hours
+	}
+
+	switch outs := txOuts.(type) {
+	case []bitcoin.TxOut:
+		for _, o := range outs {
+			tx.Outputs = append(tx.Outputs, UnsignedTxOut{Address: o.Addr, Amount: o.Value})
+		}
+	case []ethereum.TxOut:
+		for _, o := range outs {
+			tx.Outputs = append(tx.Outputs, UnsignedTxOut{Address: o.Addr, Amount: o.Value})
+		}
+	case []skycoin.TxOut:
+		var outHours uint64
+		for _, o := range outs {
+			tx.Outputs = append(tx.Outputs, UnsignedTxOut{Address: o.Address.String(), Amount: o.Coins, Hours: o.Hours})
+			outHours += o.Hours
+		}
+		if inHours > outHours {
+			tx.HoursFee = inHours - outHours
+		}
+	default:
+		return nil, fmt.Errorf("unknown tx out type %T", txOuts)
+	}
+	return &tx, nil
+}
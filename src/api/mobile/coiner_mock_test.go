@@ -10,6 +10,7 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	coin "github.com/skycoin/skycoin-exchange/src/coin"
+	pp "github.com/skycoin/skycoin-exchange/src/pp"
 )
 
 // CoinerMock mock
@@ -75,6 +76,78 @@ func (m *CoinerMock) CreateRawTx(p0 []coin.TxIn, p1 coin.GetPrivKey, p2 interfac
 
 }
 
+// Decimals mocked method
+func (m *CoinerMock) Decimals() int {
+
+	ret := m.Called()
+
+	var r0 int
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case int:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0
+
+}
+
+// EstimateFee mocked method
+func (m *CoinerMock) EstimateFee(p0 int, p1 int) (uint64, error) {
+
+	ret := m.Called(p0, p1)
+
+	var r0 uint64
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case uint64:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
+// GetFeeEstimates mocked method
+func (m *CoinerMock) GetFeeEstimates() ([]coin.FeeEstimate, error) {
+
+	ret := m.Called()
+
+	var r0 []coin.FeeEstimate
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []coin.FeeEstimate:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetBalance mocked method
 func (m *CoinerMock) GetBalance(p0 []string) (uint64, error) {
 
@@ -102,6 +175,33 @@ func (m *CoinerMock) GetBalance(p0 []string) (uint64, error) {
 
 }
 
+// GetDetailedBalance mocked method
+func (m *CoinerMock) GetDetailedBalance(p0 []string) (coin.DetailedBalance, error) {
+
+	ret := m.Called(p0)
+
+	var r0 coin.DetailedBalance
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case coin.DetailedBalance:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // GetNodeAddr mocked method
 func (m *CoinerMock) GetNodeAddr() string {
 
@@ -174,6 +274,33 @@ func (m *CoinerMock) GetTransactionByID(p0 string) (string, error) {
 
 }
 
+// GetTransactions mocked method
+func (m *CoinerMock) GetTransactions(p0 []string) ([]*pp.Tx, error) {
+
+	ret := m.Called(p0)
+
+	var r0 []*pp.Tx
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case []*pp.Tx:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // Name mocked method
 func (m *CoinerMock) Name() string {
 
@@ -255,6 +382,33 @@ func (m *CoinerMock) Send(p0 string, p1 string, p2 string, p3 ...Option) (string
 
 }
 
+// SendMany mocked method
+func (m *CoinerMock) SendMany(p0 string, p1 []TxOut, p2 ...Option) (string, error) {
+
+	ret := m.Called(p0, p1, p2)
+
+	var r0 string
+	switch res := ret.Get(0).(type) {
+	case nil:
+	case string:
+		r0 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	var r1 error
+	switch res := ret.Get(1).(type) {
+	case nil:
+	case error:
+		r1 = res
+	default:
+		panic(fmt.Sprintf("unexpected type: %v", res))
+	}
+
+	return r0, r1
+
+}
+
 // ValidateAddr mocked method
 func (m *CoinerMock) ValidateAddr(p0 string) error {
 
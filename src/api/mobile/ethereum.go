@@ -0,0 +1,192 @@
+package mobile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/coin/ethereum"
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/wallet"
+)
+
+// ethereumCli serves ethereum through the JSON-RPC api of the node directly, the exchange
+// server doesn't relay ethereum. Each transaction sends ether from one wallet address to
+// one recipient, the amounts are in wei.
+type ethereumCli struct {
+	gateway *ethereum.Gateway
+	dryRun  bool // build the unsigned transaction only
+}
+
+type ethSendParams struct {
+	WalletID string
+	Outs     []TxOut
+	Fee      uint64
+}
+
+func newEthereum(nodeAddr string) *ethereumCli {
+	return &ethereumCli{gateway: ethereum.New(nodeAddr)}
+}
+
+func (en ethereumCli) Name() string {
+	return ethereum.Type
+}
+
+func (en ethereumCli) GetNodeAddr() string {
+	return en.gateway.NodeAddr
+}
+
+// Decimals returns the decimal places of ether.
+func (en ethereumCli) Decimals() int {
+	return en.gateway.Decimals()
+}
+
+func (en ethereumCli) ValidateAddr(address string) error {
+	return ethereum.ValidateAddr(address)
+}
+
+func (en ethereumCli) GetBalance(addrs []string) (uint64, error) {
+	bal, err := en.gateway.GetBalance(addrs)
+	if err != nil {
+		return 0, err
+	}
+	return bal.GetAmount(), nil
+}
+
+// GetDetailedBalance is not supported, the ethereum node only reports the balance of the latest block.
+func (en ethereumCli) GetDetailedBalance(addrs []string) (coin.DetailedBalance, error) {
+	return coin.DetailedBalance{}, fmt.Errorf("detailed balance of %s is not supported", ethereum.Type)
+}
+
+// CreateRawTx creates and signs the transaction, the nonce of the sender is reused
+// if the signing fails.
+func (en ethereumCli) CreateRawTx(txIns []coin.TxIn, getKey coin.GetPrivKey, txOuts interface{}) (string, error) {
+	rawtx, err := en.gateway.CreateRawTx(txIns, txOuts)
+	if err != nil {
+		return "", fmt.Errorf("create raw tx failed:%v", err)
+	}
+
+	signed, err := en.gateway.SignRawTx(rawtx, getKey)
+	if err != nil {
+		en.gateway.ResetNonce(txIns[0].Address)
+		return "", err
+	}
+	return signed, nil
+}
+
+func (en ethereumCli) BroadcastTx(rawtx string) (string, error) {
+	return en.gateway.InjectTx(rawtx)
+}
+
+func (en ethereumCli) GetTransactionByID(txid string) (string, error) {
+	tx, err := en.gateway.GetTx(txid)
+	if err != nil {
+		return "", fmt.Errorf("get %s transaction by id failed: %v", ethereum.Type, err)
+	}
+
+	d, err := json.Marshal(tx)
+	if err != nil {
+		return "", err
+	}
+	return string(d), nil
+}
+
+func (en ethereumCli) GetTransactions(addrs []string) ([]*pp.Tx, error) {
+	return en.gateway.GetAddressTxs(addrs)
+}
+
+func (en ethereumCli) GetOutputByID(outid string) (string, error) {
+	return "", fmt.Errorf("%s does not support GetOutputByID method", ethereum.Type)
+}
+
+// EstimateFee returns the fee of the ether transfer in wei.
+func (en ethereumCli) EstimateFee(nIn, nOut int) (uint64, error) {
+	return en.gateway.EstimateFee(nIn, nOut)
+}
+
+// GetFeeEstimates returns the gas price of the node in wei per gas.
+func (en ethereumCli) GetFeeEstimates() ([]coin.FeeEstimate, error) {
+	return en.gateway.GetFeeEstimates()
+}
+
+// PrepareTx chooses the first wallet address whose balance covers the amount and fee as the sender.
+func (en ethereumCli) PrepareTx(params interface{}) ([]coin.TxIn, interface{}, error) {
+	p := params.(ethSendParams)
+
+	tp := strings.Split(p.WalletID, "_")[0]
+	if tp != ethereum.Type {
+		return nil, nil, fmt.Errorf("invalid wallet %v", tp)
+	}
+
+	amount, err := validateOuts(p.Outs, en.ValidateAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(p.Outs) != 1 {
+		return nil, nil, errors.New("ethereum transaction has only one recipient")
+	}
+	if amount+p.Fee < amount {
+		return nil, nil, errors.New("total amount overflows")
+	}
+
+	addrs, err := wallet.GetAddresses(p.WalletID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, a := range addrs {
+		bal, err := en.GetBalance([]string{a})
+		if err != nil {
+			return nil, nil, err
+		}
+		if bal >= amount+p.Fee {
+			txOuts := []ethereum.TxOut{{Addr: p.Outs[0].Address, Value: p.Outs[0].Amount}}
+			return []coin.TxIn{{Address: a}}, txOuts, nil
+		}
+	}
+	return nil, nil, errors.New("no address has sufficient balance")
+}
+
+// Send sends amount wei to address from specific wallet.
+func (en ethereumCli) Send(walletID, toAddr, amount string, ops ...Option) (string, error) {
+	amt, err := strconv.ParseUint(amount, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("parse amount string to uint64 failed: %v", err)
+	}
+
+	return en.SendMany(walletID, []TxOut{{Address: toAddr, Amount: amt}}, ops...)
+}
+
+// SendMany sends ether to the only recipient in outs, ethereum transaction can't have more.
+func (en ethereumCli) SendMany(walletID string, outs []TxOut, ops ...Option) (string, error) {
+	eth := en
+	for _, op := range ops {
+		op(&eth)
+	}
+
+	fee, err := eth.EstimateFee(1, 1)
+	if err != nil {
+		return "", err
+	}
+
+	params := ethSendParams{WalletID: walletID, Outs: outs, Fee: fee}
+	if eth.dryRun {
+		return sendTx(eth, walletID, params, fee, true)
+	}
+
+	txIns, txOuts, err := eth.PrepareTx(params)
+	if err != nil {
+		return "", err
+	}
+
+	// the gateway reuses the nonce if the broadcast fails.
+	out := txOuts.([]ethereum.TxOut)[0]
+	txid, err := eth.gateway.Send(txIns[0].Address, out.Addr, out.Value, getPrivateKey(walletID))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`{"txid":"%s"}`, txid), nil
+}
@@ -147,3 +147,35 @@ func ActiveAccount(se Servicer) httprouter.Handle {
 		sendJSON(w, rlt)
 	}
 }
+
+// GetNonce returns the nonce of the active account's last signed request on the exchange server.
+// mode: GET
+// url: /api/v1/account/nonce
+func GetNonce(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			req := pp.GetAccountNonceReq{
+				Pubkey: pp.PtrString(a.Pubkey),
+			}
+
+			var res pp.GetAccountNonceRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/account/nonce", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
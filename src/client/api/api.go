@@ -5,6 +5,8 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"sync"
+	"time"
 
 	logging "github.com/op/go-logging"
 	"github.com/skycoin/skycoin-exchange/src/coin"
@@ -12,7 +14,12 @@ import (
 	"github.com/skycoin/skycoin/src/cipher"
 )
 
-var logger = logging.MustGetLogger("client.api")
+var (
+	logger = logging.MustGetLogger("client.api")
+
+	lastNonce uint64
+	nonceMtx  sync.Mutex
+)
 
 // Servicer api service interface
 type Servicer interface {
@@ -89,11 +96,17 @@ func makeEncryptReq(r interface{}, pubkey string, seckey string) (*pp.EncryptReq
 		return nil, err
 	}
 
+	sig, err := pp.Sign(r, seckey)
+	if err != nil {
+		return nil, err
+	}
+
 	p := cipher.PubKeyFromSecKey(s)
 	return &pp.EncryptReq{
 		Pubkey:      pp.PtrString(p.Hex()),
 		Nonce:       nonce,
 		Encryptdata: encData,
+		Signature:   pp.PtrString(sig),
 	}, nil
 }
 
@@ -116,3 +129,17 @@ func decodeRsp(r io.Reader, pubkey string, seckey string, v interface{}) (interf
 	}
 	return v, nil
 }
+
+// nextNonce returns the nonce of the next signed request, the unix time in nanoseconds is used,
+// so that the nonces keep increasing after restart, and it's greater than the last one even if
+// the clock goes back.
+func nextNonce() uint64 {
+	nonceMtx.Lock()
+	defer nonceMtx.Unlock()
+	n := uint64(time.Now().UnixNano())
+	if n <= lastNonce {
+		n = lastNonce + 1
+	}
+	lastNonce = n
+	return n
+}
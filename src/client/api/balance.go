@@ -49,3 +49,33 @@ func GetBalance(se Servicer) httprouter.Handle {
 		sendJSON(w, rlt)
 	}
 }
+
+// GetBalances get the available and reserved balances of all coins in the active account.
+func GetBalances(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			req := pp.GetAccountBalancesReq{
+				Pubkey: pp.PtrString(a.Pubkey),
+			}
+
+			var res pp.GetAccountBalancesRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/account/balances", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
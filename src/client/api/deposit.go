@@ -33,11 +33,12 @@ func GetDepositAddress(se Servicer) httprouter.Handle {
 			req := pp.GetDepositAddrReq{
 				Pubkey:   pp.PtrString(a.Pubkey),
 				CoinType: pp.PtrString(cp),
+				Nonce:    pp.PtrUint64(nextNonce()),
 			}
 
 			var res pp.GetDepositAddrRes
 
-			if err := sknet.EncryGet(se.GetServAddr(), "/create/deposit_address", req, &res); err != nil {
+			if err := sknet.SignedGet(se.GetServAddr(), "/create/deposit_address", a.Seckey, req, &res); err != nil {
 				logger.Error(err.Error())
 				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
 				break
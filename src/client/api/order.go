@@ -13,12 +13,17 @@ import (
 
 // CreateOrder create order through exchange server.
 // mode: POST
-// url: /api/v1/account/order?coin_pair=[:coin_pair]&type=[:type]&price=[:price]&amt=[:amt]
+// url: /api/v1/account/order?coin_pair=[:coin_pair]&type=[:type]&kind=[:kind]&price=[:price]&amt=[:amt]&tif=[:tif]&expire_at=[:expire_at]&stop_price=[:stop_price]&amt_currency=[:amt_currency]
 // params:
 // 		coin_pair: order coin pair.
 // 		type: order type, can be bid or ask.
-// 		price: price.
+// 		kind: order kind, can be limit or market, default is limit.
+// 		price: price, ignored by market order.
 // 		amt: amount.
+// 		tif: time in force of limit order, can be gtc, ioc or gtd, default is gtc.
+// 		expire_at: expiry unix time of gtd order.
+// 		stop_price: optional, the order is inactive until the last trade price reaches it.
+// 		amt_currency: optional, the coin of amt, can be base or quote, default is base.
 func CreateOrder(se Servicer) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		rlt := &pp.EmptyRes{}
@@ -38,8 +43,9 @@ func CreateOrder(se Servicer) httprouter.Handle {
 			}
 
 			req.Pubkey = pp.PtrString(a.Pubkey)
+			req.Nonce = pp.PtrUint64(nextNonce())
 			var res pp.OrderRes
-			if err := sknet.EncryGet(se.GetServAddr(), "/create/order", req, &res); err != nil {
+			if err := sknet.SignedGet(se.GetServAddr(), "/create/order", a.Seckey, req, &res); err != nil {
 				logger.Error(err.Error())
 				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
 				break
@@ -65,14 +71,19 @@ func makeOrderReq(r *http.Request) (*pp.OrderReq, error) {
 		return nil, errors.New("type is empty")
 	}
 
-	// get price
-	pc := r.FormValue("price")
-	if pc == "" {
-		return nil, errors.New("price is empty")
-	}
-	price, err := strconv.ParseUint(pc, 10, 64)
-	if err != nil {
-		return nil, err
+	// get kind, market order has no price.
+	kind := r.FormValue("kind")
+	var price uint64
+	if kind != "market" {
+		pc := r.FormValue("price")
+		if pc == "" {
+			return nil, errors.New("price is empty")
+		}
+		var err error
+		price, err = strconv.ParseUint(pc, 10, 64)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// get amount
@@ -85,14 +96,94 @@ func makeOrderReq(r *http.Request) (*pp.OrderReq, error) {
 		return nil, err
 	}
 
+	// get time in force, gtd order must have expiry time.
+	tif := r.FormValue("tif")
+	var expireAt int64
+	if tif == "gtd" {
+		exp := r.FormValue("expire_at")
+		if exp == "" {
+			return nil, errors.New("expire_at is empty")
+		}
+		expireAt, err = strconv.ParseInt(exp, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// get stop price of stop order.
+	var stopPrice uint64
+	if sp := r.FormValue("stop_price"); sp != "" {
+		stopPrice, err = strconv.ParseUint(sp, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &pp.OrderReq{
-		CoinPair: pp.PtrString(cp),
-		Type:     pp.PtrString(tp),
-		Price:    pp.PtrUint64(price),
-		Amount:   pp.PtrUint64(amount),
+		CoinPair:    pp.PtrString(cp),
+		Type:        pp.PtrString(tp),
+		Kind:        pp.PtrString(kind),
+		Price:       pp.PtrUint64(price),
+		Amount:      pp.PtrUint64(amount),
+		TimeInForce: pp.PtrString(tif),
+		ExpireAt:    pp.PtrInt64(expireAt),
+		StopPrice:   pp.PtrUint64(stopPrice),
+
+		AmountCurrency: pp.PtrString(r.FormValue("amt_currency")),
 	}, nil
 }
 
+// CancelOrder cancel order through exchange server.
+// mode: DELETE
+// url: /api/v1/account/order?coin_pair=[:coin_pair]&id=[:id]
+// params:
+// 		coin_pair: order coin pair.
+// 		id: order id.
+func CancelOrder(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp := r.FormValue("coin_pair")
+			if cp == "" {
+				logger.Error("coin_pair is empty")
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			req := pp.CancelOrderReq{
+				Pubkey:   pp.PtrString(a.Pubkey),
+				CoinPair: pp.PtrString(cp),
+				OrderId:  pp.PtrUint64(id),
+				Nonce:    pp.PtrUint64(nextNonce()),
+			}
+			var res pp.CancelOrderRes
+			if err := sknet.SignedGet(se.GetServAddr(), "/cancel/order", a.Seckey, req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
 // GetBidOrders get bid orders through exchange server.
 func GetBidOrders(se Servicer) httprouter.Handle {
 	return getOrders(se, "bid")
@@ -134,6 +225,7 @@ func getOrders(se Servicer, tp string) httprouter.Handle {
 				Type:     pp.PtrString(tp),
 				Start:    &start,
 				End:      &end,
+				ByTime:   pp.PtrBool(r.FormValue("by_time") == "true"),
 			}
 
 			var res pp.GetOrderRes
@@ -149,3 +241,156 @@ func getOrders(se Servicer, tp string) httprouter.Handle {
 		sendJSON(w, rlt)
 	}
 }
+
+// GetOrder get the open or recently closed order by id through exchange server.
+func GetOrder(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp := r.FormValue("coin_pair")
+			id, err := strconv.ParseUint(r.FormValue("id"), 10, 64)
+			if cp == "" || err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			req := pp.GetOrderByIDReq{
+				CoinPair: &cp,
+				OrderId:  &id,
+			}
+
+			var res pp.GetOrderByIDRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/order", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+// GetDepth get the aggregated depth of order book through exchange server.
+func GetDepth(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp := r.FormValue("coin_pair")
+			lv := r.FormValue("levels")
+			if cp == "" || lv == "" {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			levels, err := strconv.ParseInt(lv, 10, 64)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			req := pp.GetDepthReq{
+				CoinPair: &cp,
+				Levels:   &levels,
+			}
+
+			var res pp.GetDepthRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/depth", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+// GetTicker get the ticker of coin pair through exchange server.
+func GetTicker(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp := r.FormValue("coin_pair")
+			if cp == "" {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			req := pp.GetTickerReq{CoinPair: &cp}
+			var res pp.GetTickerRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/ticker", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+// GetCandles get the OHLCV candles of trades through exchange server.
+func GetCandles(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			cp := r.FormValue("coin_pair")
+			interval := r.FormValue("interval")
+			if cp == "" || interval == "" {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			start, err := strconv.ParseInt(r.FormValue("start"), 10, 64)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			end, err := strconv.ParseInt(r.FormValue("end"), 10, 64)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			var fill bool
+			if f := r.FormValue("fill"); f != "" {
+				fill, err = strconv.ParseBool(f)
+				if err != nil {
+					logger.Error(err.Error())
+					rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+					break
+				}
+			}
+
+			req := pp.GetCandlesReq{
+				CoinPair: &cp,
+				Interval: &interval,
+				Start:    &start,
+				End:      &end,
+				Fill:     &fill,
+			}
+
+			var res pp.GetCandlesRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/candles", req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/skycoin/skycoin-exchange/src/client/account"
@@ -53,10 +54,76 @@ func Withdraw(se Servicer) httprouter.Handle {
 				CoinType:      &cp,
 				Coins:         &amt,
 				OutputAddress: &toAddr,
+				Nonce:         pp.PtrUint64(nextNonce()),
+			}
+
+			// the retried withdrawal must use the same key, so that it won't be made twice.
+			if key := r.FormValue("idempotency_key"); key != "" {
+				req.IdempotencyKey = &key
+			}
+
+			if rate := r.FormValue("fee_rate"); rate != "" {
+				feeRate, err := strconv.ParseUint(rate, 10, 64)
+				if err != nil {
+					rlt = pp.MakeErrRes(errors.New("invalid fee_rate"))
+					break
+				}
+				req.FeeRate = &feeRate
+			}
+
+			if memo := r.FormValue("memo"); memo != "" {
+				req.Memo = &memo
+			}
+
+			if utxos := r.FormValue("utxos"); utxos != "" {
+				req.Utxos = strings.Split(utxos, ",")
+			}
+
+			if code := r.FormValue("totp_code"); code != "" {
+				req.TotpCode = &code
 			}
 
 			var res pp.WithdrawalRes
-			if err := sknet.EncryGet(se.GetServAddr(), "/withdrawl", req, &res); err != nil {
+			if err := sknet.SignedGet(se.GetServAddr(), "/withdrawl", a.Seckey, req, &res); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
+
+			sendJSON(w, res)
+			return
+		}
+		sendJSON(w, rlt)
+	}
+}
+
+// GetWithdrawal returns the recent withdrawal of the active account by txid.
+// mode: GET
+// url: /api/v1/account/withdrawal?txid=[:txid]
+func GetWithdrawal(se Servicer) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		rlt := &pp.EmptyRes{}
+		for {
+			a, err := account.GetActive()
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			txid := r.FormValue("txid")
+			if txid == "" {
+				rlt = pp.MakeErrRes(errors.New("txid empty"))
+				break
+			}
+
+			req := pp.GetWithdrawalReq{
+				Pubkey: pp.PtrString(a.Pubkey),
+				Txid:   pp.PtrString(txid),
+			}
+
+			var res pp.GetWithdrawalRes
+			if err := sknet.EncryGet(se.GetServAddr(), "/get/withdrawal", req, &res); err != nil {
 				logger.Error(err.Error())
 				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
 				break
@@ -25,14 +25,22 @@ func registerBaseHandlers(rt *httprouter.Router, se api.Servicer) {
 	rt.PUT("/api/v1/account/state", api.ActiveAccount(se))
 	rt.POST("/api/v1/account/deposit_address", api.GetDepositAddress(se))
 	rt.GET("/api/v1/account/balance", api.GetBalance(se))
+	rt.GET("/api/v1/account/balances", api.GetBalances(se))
+	rt.GET("/api/v1/account/nonce", api.GetNonce(se))
 	rt.POST("/api/v1/account/withdrawal", api.Withdraw(se))
+	rt.GET("/api/v1/account/withdrawal", api.GetWithdrawal(se))
 }
 
 // order handlers
 func registerOrderHandlers(rt *httprouter.Router, se api.Servicer) {
 	rt.POST("/api/v1/account/order", api.CreateOrder(se))
+	rt.DELETE("/api/v1/account/order", api.CancelOrder(se))
 	rt.GET("/api/v1/orders/bid", api.GetBidOrders(se))
 	rt.GET("/api/v1/orders/ask", api.GetAskOrders(se))
+	rt.GET("/api/v1/order", api.GetOrder(se))
+	rt.GET("/api/v1/depth", api.GetDepth(se))
+	rt.GET("/api/v1/candles", api.GetCandles(se))
+	rt.GET("/api/v1/ticker", api.GetTicker(se))
 }
 
 // utxos handlers
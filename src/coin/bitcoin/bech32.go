@@ -0,0 +1,198 @@
+package bitcoin_interface
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil"
+)
+
+// SegwitHRP the human readable part of bitcoin mainnet segwit addresses.
+const SegwitHRP = "bc"
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Gen = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= bech32Gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HrpExpand(hrp string) []byte {
+	v := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		v = append(v, hrp[i]>>5)
+	}
+	v = append(v, 0)
+	for i := 0; i < len(hrp); i++ {
+		v = append(v, hrp[i]&31)
+	}
+	return v
+}
+
+func bech32Checksum(hrp string, data []byte) []byte {
+	values := append(bech32HrpExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ 1
+	chk := make([]byte, 6)
+	for i := range chk {
+		chk[i] = byte((mod >> uint(5*(5-i))) & 31)
+	}
+	return chk
+}
+
+// bech32Encode encodes the 5-bit data with the hrp, as defined in BIP173.
+func bech32Encode(hrp string, data []byte) string {
+	combined := append(data, bech32Checksum(hrp, data)...)
+	s := make([]byte, 0, len(hrp)+1+len(combined))
+	s = append(s, hrp...)
+	s = append(s, '1')
+	for _, v := range combined {
+		s = append(s, bech32Charset[v])
+	}
+	return string(s)
+}
+
+// bech32Decode decodes the bech32 string, returns the hrp and the 5-bit data without checksum.
+func bech32Decode(s string) (string, []byte, error) {
+	if len(s) > 90 {
+		return "", nil, errors.New("bech32 string too long")
+	}
+
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("bech32 string has mixed case")
+	}
+	s = strings.ToLower(s)
+
+	pos := strings.LastIndex(s, "1")
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, errors.New("invalid bech32 separator position")
+	}
+
+	hrp := s[:pos]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, fmt.Errorf("invalid bech32 hrp character: %q", hrp[i])
+		}
+	}
+
+	data := make([]byte, 0, len(s)-pos-1)
+	for i := pos + 1; i < len(s); i++ {
+		v := strings.IndexByte(bech32Charset, s[i])
+		if v < 0 {
+			return "", nil, fmt.Errorf("invalid bech32 character: %q", s[i])
+		}
+		data = append(data, byte(v))
+	}
+
+	if bech32Polymod(append(bech32HrpExpand(hrp), data...)) != 1 {
+		return "", nil, errors.New("invalid bech32 checksum")
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// convertBits regroups the bits of data from fromBits per byte to toBits per byte.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc, bits uint
+	maxv := uint(1)<<toBits - 1
+	ret := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, v := range data {
+		if uint(v)>>fromBits != 0 {
+			return nil, errors.New("invalid data range")
+		}
+		acc = acc<<fromBits | uint(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			ret = append(ret, byte(acc>>bits&maxv))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			ret = append(ret, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	return ret, nil
+}
+
+// EncodeSegwitAddress encodes the witness program into segwit address, only
+// witness version 0 is supported.
+func EncodeSegwitAddress(hrp string, version byte, program []byte) (string, error) {
+	if err := checkWitnessProgram(version, program); err != nil {
+		return "", err
+	}
+
+	data, err := convertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32Encode(hrp, append([]byte{version}, data...)), nil
+}
+
+// DecodeSegwitAddress decodes the segwit address of specific hrp, returns
+// the witness version and program.
+func DecodeSegwitAddress(hrp, addr string) (byte, []byte, error) {
+	h, data, err := bech32Decode(addr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if h != hrp {
+		return 0, nil, fmt.Errorf("invalid segwit address hrp: %s", h)
+	}
+
+	if len(data) == 0 {
+		return 0, nil, errors.New("empty segwit address data")
+	}
+
+	program, err := convertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err := checkWitnessProgram(data[0], program); err != nil {
+		return 0, nil, err
+	}
+	return data[0], program, nil
+}
+
+// checkWitnessProgram checks the witness program is P2WPKH or P2WSH.
+func checkWitnessProgram(version byte, program []byte) error {
+	if version != 0 {
+		return fmt.Errorf("unsupported witness version: %d", version)
+	}
+
+	if len(program) != 20 && len(program) != 32 {
+		return fmt.Errorf("invalid witness program length: %d", len(program))
+	}
+	return nil
+}
+
+// SegwitAddressFromPubkey makes the native segwit(P2WPKH) address of the compressed pubkey.
+func SegwitAddressFromPubkey(pubkey []byte) (string, error) {
+	if len(pubkey) != 33 {
+		return "", errors.New("segwit address requires compressed pubkey")
+	}
+	return EncodeSegwitAddress(segwitHRP(), 0, btcutil.Hash160(pubkey))
+}
+
+// isSegwitAddress checks if the address is in bech32 format of mainnet or testnet, the address
+// may still be invalid or belong to the other network.
+func isSegwitAddress(addr string) bool {
+	a := strings.ToLower(addr)
+	return strings.HasPrefix(a, SegwitHRP+"1") || strings.HasPrefix(a, TestnetSegwitHRP+"1")
+}
@@ -0,0 +1,113 @@
+package bitcoin_interface
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the valid and invalid bech32 strings of BIP173.
+func TestBech32Checksum(t *testing.T) {
+	valid := []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j",
+		"?1ezyfcl",
+	}
+
+	for _, s := range valid {
+		hrp, data, err := bech32Decode(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, strings.ToLower(s), bech32Encode(hrp, data))
+	}
+
+	invalid := []string{
+		"\x201nwldj5", // hrp character out of range.
+		"\x7f1axkwrx", // hrp character out of range.
+		"\x801eym55h", // hrp character out of range.
+		"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx", // overall max length exceeded.
+		"pzry9x0s0muk",  // no separator character.
+		"1pzry9x0s0muk", // empty hrp.
+		"x1b4n0q5v",     // invalid data character.
+		"li1dgmt3",      // too short checksum.
+		"de1lg7wt\xff",  // invalid character in checksum.
+		"A1G7SGD8",      // checksum calculated with uppercase hrp.
+		"10a06t8",       // empty hrp.
+		"1qzzfhee",      // empty hrp.
+	}
+
+	for _, s := range invalid {
+		_, _, err := bech32Decode(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+// test vectors are from BIP173.
+func TestDecodeSegwitAddress(t *testing.T) {
+	testData := []struct {
+		HRP     string
+		Addr    string
+		Program string
+	}{
+		{SegwitHRP, "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{SegwitHRP, "bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3", "1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+		{TestnetSegwitHRP, "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", "1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+		{TestnetSegwitHRP, "tb1qqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesrxh6hy", "000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+	}
+
+	for _, d := range testData {
+		version, program, err := DecodeSegwitAddress(d.HRP, d.Addr)
+		assert.Nil(t, err, d.Addr)
+		assert.Equal(t, byte(0), version)
+		assert.Equal(t, d.Program, hex.EncodeToString(program))
+
+		addr, err := EncodeSegwitAddress(d.HRP, version, program)
+		assert.Nil(t, err)
+		assert.Equal(t, strings.ToLower(d.Addr), addr)
+		if d.HRP == SegwitHRP {
+			assert.Nil(t, ValidateAddr(d.Addr))
+		}
+	}
+}
+
+func TestDecodeInvalidSegwitAddress(t *testing.T) {
+	testData := []string{
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", // invalid checksum.
+		"tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", // testnet hrp.
+		"bc1QW508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", // mixed case.
+		"BC1SW50QA3JX3S", // version 16.
+		"bc1zw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvaryvg6kx", // unsupported version 2.
+		"bc1rw5uspcuh",                               // invalid program length.
+		"BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P",       // invalid v0 program length.
+		"bc1zw508d6qejxtdg4y5r3zarvaryvqyzf3du",      // invalid padding.
+		"bc1gmk9yu",                                  // empty data.
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3tb", // invalid character.
+		"tc1qw508d6qejxtdg4y5r3zarvary0c5xw7kg3g4ty", // invalid hrp.
+		"BC13W508D6QEJXTDG4Y5R3ZARVARY0C5XW7KN40WF2", // invalid witness version.
+		"bc10w508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kw5rljs90", // invalid program length.
+		"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3pjxtptv",               // non-zero padding in 8-to-5 conversion.
+	}
+
+	for _, addr := range testData {
+		_, _, err := DecodeSegwitAddress(SegwitHRP, addr)
+		assert.NotNil(t, err, addr)
+		if isSegwitAddress(addr) {
+			assert.NotNil(t, ValidateAddr(addr), addr)
+		}
+	}
+}
+
+func TestSegwitAddressFromPubkey(t *testing.T) {
+	pub, _ := hex.DecodeString("0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798")
+	addr, err := SegwitAddressFromPubkey(pub)
+	assert.Nil(t, err)
+	assert.Equal(t, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", addr)
+
+	// uncompressed pubkey is not allowed.
+	_, err = SegwitAddressFromPubkey(append([]byte{0x04}, make([]byte, 64)...))
+	assert.NotNil(t, err)
+}
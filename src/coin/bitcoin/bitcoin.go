@@ -10,8 +10,11 @@ import (
 
 	"net/http"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil"
 	logging "github.com/op/go-logging"
 	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/coin/insight"
 	"github.com/skycoin/skycoin/src/cipher"
 )
 
@@ -20,15 +23,12 @@ var (
 	logger     = logging.MustGetLogger("exchange.bitcoin")
 	// GatewayIns = Gateway{}
 	Type = "bitcoin"
+	// FeeRate default transaction fee rate in satoshis per vbyte.
+	FeeRate uint64 = 20
 )
 
 // Utxo unspent output
-type Utxo interface {
-	GetTxid() string
-	GetVout() uint32
-	GetAmount() uint64
-	GetAddress() string
-}
+type Utxo = insight.Utxo
 
 // UtxoWithkey unspent output with privkey.
 type UtxoWithkey interface {
@@ -42,11 +42,15 @@ type TxOut struct {
 	Value uint64
 }
 
-// GenerateAddresses generates bitcoin addresses.
+// GenerateAddresses generates bitcoin addresses of the current network.
 func GenerateAddresses(seed []byte, num int) (string, []coin.AddressEntry) {
 	sd, seckeys := cipher.GenerateDeterministicKeyPairsSeed(seed, num)
 	entries := make([]coin.AddressEntry, num)
 	for i, sec := range seckeys {
+		if testnet {
+			entries[i] = testnetAddressEntry(sec[:])
+			continue
+		}
 		pub := cipher.PubKeyFromSecKey(sec)
 		entries[i].Address = cipher.BitcoinAddressFromPubkey(pub)
 		entries[i].Public = pub.Hex()
@@ -57,6 +61,27 @@ func GenerateAddresses(seed []byte, num int) (string, []coin.AddressEntry) {
 	return fmt.Sprintf("%2x", sd), entries
 }
 
+// testnetAddressEntry makes the testnet address entry of the secret key, cipher
+// only knows the mainnet formats.
+func testnetAddressEntry(sec []byte) coin.AddressEntry {
+	privKey, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), sec)
+	pub := pubKey.SerializeCompressed()
+	addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(pub), NetParams())
+	if err != nil {
+		panic(err)
+	}
+
+	entry := coin.AddressEntry{Address: addr.EncodeAddress(), Public: fmt.Sprintf("%x", pub)}
+	if !HideSeckey {
+		wif, err := btcutil.NewWIF(privKey, NetParams(), true)
+		if err != nil {
+			panic(err)
+		}
+		entry.Secret = wif.String()
+	}
+	return entry
+}
+
 // GetBalance query balance of address through the API of blockexplorer.com.
 func GetBalance(addr []string) (uint64, error) {
 	for _, a := range addr {
@@ -67,7 +92,7 @@ func GetBalance(addr []string) (uint64, error) {
 
 	// blkEplUrl := fmt.Sprintf("https://blockexplorer.com/api/addr/%s/balance", addr)
 	addrs := strings.Join(addr, "|")
-	blkChnUrl := fmt.Sprintf("https://blockchain.info/q/addressbalance/%s", addrs)
+	blkChnUrl := fmt.Sprintf("%s/q/addressbalance/%s", blkChnAPI(), addrs)
 	data, err := getDataOfUrl(blkChnUrl)
 	if err != nil {
 		return 0, err
@@ -81,7 +106,7 @@ func GetBalance(addr []string) (uint64, error) {
 
 // GetUnspentOutputs return the unspent outputs
 func GetUnspentOutputs(addrs []string) ([]Utxo, error) {
-	return getUtxosBlkExplr(addrs)
+	return insightNet.GetUnspentOutputs(addrs)
 }
 
 // NewUtxoWithKey create UtxoWithkey struct
@@ -107,7 +132,29 @@ func getDataOfUrl(url string) ([]byte, error) {
 	return data, nil
 }
 
-func validateAddress(addr string) bool {
+// ValidateAddr check if the bitcoin address is valid, both the legacy
+// base58 address and the native segwit(bech32) address are accepted,
+// the addresses of the other network are rejected.
+func ValidateAddr(addr string) error {
+	if isSegwitAddress(addr) {
+		_, _, err := DecodeSegwitAddress(segwitHRP(), addr)
+		return err
+	}
+
+	if testnet {
+		a, err := btcutil.DecodeAddress(addr, NetParams())
+		if err != nil {
+			return err
+		}
+		if !a.IsForNet(NetParams()) {
+			return fmt.Errorf("%s is not bitcoin testnet address", addr)
+		}
+		return nil
+	}
 	_, err := cipher.BitcoinDecodeBase58Address(addr)
-	return err == nil
+	return err
+}
+
+func validateAddress(addr string) bool {
+	return ValidateAddr(addr) == nil
 }
@@ -51,13 +51,22 @@ func (bk BlkChnUtxo) GetAddress() string {
 	return ""
 }
 
+func (bk BlkChnUtxo) GetConfirmations() uint64 {
+	return bk.Confirmations
+}
+
+// GetBlockHash blockchain.info doesn't report the block of unspent output.
+func (bk BlkChnUtxo) GetBlockHash() string {
+	return ""
+}
+
 // GetUtxosBlkChnInfo get unspent outputs from blockchain.info
 // https://blockchain.info/unspent?active=1SakrZuzQmGwn7MSiJj5awqJZjSYeBWC3
 func getUtxosBlkChnInfo(addr string) []Utxo {
 	// if AddressValid(addr) != nil {
 	// log.Fatal("Address is invalid")
 	// }
-	url := fmt.Sprintf("https://blockchain.info/unspent?active=%s", addr)
+	url := fmt.Sprintf("%s/unspent?active=%s", blkChnAPI(), addr)
 	// fmt.Println(url)
 
 	resp, err := http.Get(url)
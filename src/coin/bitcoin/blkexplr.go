@@ -3,41 +3,33 @@ package bitcoin_interface
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"math"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/skycoin/skycoin-exchange/src/pp"
-	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/coin/insight"
 )
 
-type BlkExplrUtxo struct {
-	Address      string `json:"address"`
-	Txid         string `json:"txid"`
-	Vout         uint32 `json:"vout"`
-	ScriptPubkey string `json:"criptPubKey"`
-	Amount       uint64 `json:"satoshis"`
-	Confirms     uint64 `json:"confirmations"`
-}
-
-func (be BlkExplrUtxo) GetTxid() string {
-	return be.Txid
-}
-
-func (be BlkExplrUtxo) GetVout() uint32 {
-	return be.Vout
-}
+var (
+	// BlkExplrAPI the base url of the blockexplorer.com api.
+	BlkExplrAPI = mainBlkExplrAPI
+	// BalanceBatchSize max number of addresses queried in one balance request,
+	// too many addresses will make the url exceed the limit of the server.
+	BalanceBatchSize = 50
+)
 
-func (be BlkExplrUtxo) GetAmount() uint64 {
-	return be.Amount
-}
+// BlkExplrUtxo the unspent output returned by blockexplorer.com, which serves the insight api.
+type BlkExplrUtxo = insight.UnspentOutput
 
-func (be BlkExplrUtxo) GetAddress() string {
-	return be.Address
+// insightNet the bitcoin network served by blockexplorer.com.
+var insightNet = &insight.Network{
+	Type:         Type,
+	API:          func() string { return BlkExplrAPI },
+	ValidateAddr: ValidateAddr,
 }
 
 // BlkChnUtxo with private key
@@ -50,116 +42,134 @@ func (beu BlkExplrUtxoWithkey) GetPrivKey() string {
 	return beu.Privkey
 }
 
-func getUtxosBlkExplr(addrs []string) ([]Utxo, error) {
-	if len(addrs) == 0 {
-		return []Utxo{}, nil
-	}
+// BalanceStats the metrics of balance queries.
+type BalanceStats struct {
+	Queries   uint64        // number of GetBalance calls.
+	Requests  uint64        // number of requests sent to blockexplorer.com.
+	Fallbacks uint64        // number of batches that fell back to per-address requests.
+	Duration  time.Duration // total time spent in the queries.
+}
+
+var (
+	balanceStats    BalanceStats
+	balanceStatsMtx sync.Mutex
+)
+
+// GetBalanceStats returns the metrics of balance queries.
+func GetBalanceStats() BalanceStats {
+	balanceStatsMtx.Lock()
+	defer balanceStatsMtx.Unlock()
+	return balanceStats
+}
+
+func addBalanceStats(requests, fallbacks uint64, d time.Duration) {
+	balanceStatsMtx.Lock()
+	balanceStats.Queries++
+	balanceStats.Requests += requests
+	balanceStats.Fallbacks += fallbacks
+	balanceStats.Duration += d
+	balanceStatsMtx.Unlock()
+}
 
+// getBalanceBatchExplr queries the balance of addresses in batches of BalanceBatchSize,
+// the balance of each batch is summed up from the utxos fetched in one request, if the
+// batch request fails, the balance of the batch is queried address by address.
+func getBalanceBatchExplr(addrs []string) (uint64, error) {
 	for _, a := range addrs {
 		if !validateAddress(a) {
-			return []Utxo{}, fmt.Errorf("invalid bitcoin address %v", a)
+			return 0, fmt.Errorf("invalid bitcoin address %v", a)
 		}
 	}
 
-	url := fmt.Sprintf("https://blockexplorer.com/api/addrs/%s/utxo", strings.Join(addrs, ","))
-	rsp, err := http.Get(url)
-	if err != nil {
-		return []Utxo{}, fmt.Errorf("get utxo from blockexplorer.com failed")
-	}
+	start := time.Now()
+	var requests, fallbacks uint64
+	defer func() { addBalanceStats(requests, fallbacks, time.Since(start)) }()
 
-	if rsp.StatusCode != 200 {
-		return []Utxo{}, errors.New("get unspent output from blockexplorer.com failed")
-	}
-	defer rsp.Body.Close()
-	data, err := ioutil.ReadAll(rsp.Body)
-	if err != nil {
-		return []Utxo{}, err
-	}
-	us := []BlkExplrUtxo{}
-	err = json.Unmarshal(data, &us)
-	if err != nil {
-		return []Utxo{}, err
+	size := BalanceBatchSize
+	if size <= 0 {
+		size = 1
 	}
 
-	utxos := make([]Utxo, len(us))
-	for i, u := range us {
-		utxos[i] = u
+	var totalBal uint64
+	for i := 0; i < len(addrs); i += size {
+		end := i + size
+		if end > len(addrs) {
+			end = len(addrs)
+		}
+		batch := addrs[i:end]
+
+		requests++
+		utxos, err := insightNet.GetUnspentOutputs(batch)
+		if err == nil {
+			for _, u := range utxos {
+				totalBal += u.GetAmount()
+			}
+			continue
+		}
+
+		logger.Debug("batch balance query failed: %v, fall back to per-address query", err)
+		fallbacks++
+		requests += uint64(len(batch))
+		v, err := insightNet.GetBalance(batch)
+		if err != nil {
+			return 0, err
+		}
+		totalBal += v
 	}
-	return utxos, nil
+	return totalBal, nil
 }
 
-// get tx verbose from blockexplorer.com
-func getTxVerboseExplr(txid string) (*pp.Tx, error) {
-	d, err := getDataOfUrl(fmt.Sprintf("https://blockexplorer.com/api/tx/%s", txid))
-	if err != nil {
-		return nil, err
-	}
+// FeeEstimatesTTL how long the fee estimates are cached before querying the node again.
+var FeeEstimatesTTL = time.Minute
 
-	if strings.ToLower(string(d)) == "not found" {
-		return nil, fmt.Errorf("not found")
-	}
+var feeEstimatesCache struct {
+	sync.Mutex
+	url       string // the api queried, so that changing BlkExplrAPI invalidates the cache.
+	time      time.Time
+	estimates []coin.FeeEstimate
+}
 
-	tx := pp.Tx{}
-	if err := json.Unmarshal(d, &tx.Btc); err != nil {
-		return nil, err
+// getFeeEstimatesExplr queries the fee rates of FeeTargets through the estimatefee api of
+// blockexplorer.com, which are in BTC per kilobyte and converted to satoshis per vbyte. The
+// targets that the node doesn't have enough data for, which are -1, fall back to FeeRate.
+func getFeeEstimatesExplr() ([]coin.FeeEstimate, error) {
+	url := fmt.Sprintf("%s/utils/estimatefee?nbBlocks=%s", BlkExplrAPI, joinInts(coin.FeeTargets, ","))
+	c := &feeEstimatesCache
+	c.Lock()
+	defer c.Unlock()
+	if c.url == url && time.Since(c.time) < FeeEstimatesTTL {
+		return append([]coin.FeeEstimate{}, c.estimates...), nil
 	}
-	return &tx, nil
-}
 
-func getRawtxExplr(txid string) (string, error) {
-	d, err := getDataOfUrl(fmt.Sprintf("https://blockexplorer.com/api/rawtx/%s", txid))
+	d, err := getDataOfUrl(url)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	v := struct {
-		Rawtx string `json:"rawtx"`
-	}{}
+
+	v := map[string]float64{}
 	if err := json.Unmarshal(d, &v); err != nil {
-		return "", err
+		return nil, fmt.Errorf("decode fee estimates failed: %v", err)
 	}
-	return v.Rawtx, nil
-}
-
-type balanceResult struct {
-	balance uint64
-	err     error
-}
-
-func getBalanceExplr(addrs []string) (uint64, error) {
-	var wg sync.WaitGroup
 
-	valueChan := make(chan balanceResult, len(addrs))
-
-	for _, addr := range addrs {
-		// verify the address.
-		_, err := cipher.BitcoinDecodeBase58Address(addr)
-		if err != nil {
-			return 0, err
+	fes := make([]coin.FeeEstimate, len(coin.FeeTargets))
+	for i, n := range coin.FeeTargets {
+		rate := FeeRate
+		if btcPerKB, ok := v[strconv.Itoa(n)]; ok && btcPerKB > 0 {
+			rate = uint64(math.Ceil(btcPerKB * 1e8 / 1000))
 		}
-
-		wg.Add(1)
-		go func(addr string, wg *sync.WaitGroup, vc chan balanceResult) {
-			defer wg.Done()
-			d, err := getDataOfUrl(fmt.Sprintf("https://blockexplorer.com/api/addr/%s/balance", addr))
-			if err != nil {
-				vc <- balanceResult{0, err}
-				return
-			}
-			v, err := strconv.ParseUint(string(d), 10, 64)
-			if err != nil {
-				vc <- balanceResult{0, err}
-			}
-			vc <- balanceResult{v, nil}
-		}(addr, &wg, valueChan)
+		fes[i] = coin.FeeEstimate{Blocks: n, FeeRate: rate}
 	}
-	wg.Wait()
-	close(valueChan)
-	var totalBal uint64
-	for v := range valueChan {
-		if v.err != nil {
-			return 0, v.err
-		}
-		totalBal += v.balance
+
+	c.url = url
+	c.time = time.Now()
+	c.estimates = fes
+	return append([]coin.FeeEstimate{}, fes...), nil
+}
+
+func joinInts(ns []int, sep string) string {
+	ss := make([]string, len(ns))
+	for i, n := range ns {
+		ss[i] = strconv.Itoa(n)
 	}
-	return totalBal, nil
+	return strings.Join(ss, sep)
 }
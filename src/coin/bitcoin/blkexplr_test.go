@@ -1,8 +1,16 @@
 package bitcoin_interface
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/skycoin/skycoin-exchange/src/coin"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -10,3 +18,194 @@ func TestGetBlkExplrUtxos(t *testing.T) {
 	_, err := GetUnspentOutputs([]string{"19EC57DDAtTCVcKENVcd5tbRXk7yKSKvGK"})
 	assert.Nil(t, err)
 }
+
+// newBlkExplrMock starts a server that mocks the balance and utxo api of blockexplorer.com,
+// each address owns one utxo of 100 satoshis, the batch api fails if batch is false.
+func newBlkExplrMock(batch bool, latency time.Duration) (*httptest.Server, *uint64) {
+	var requests uint64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint64(&requests, 1)
+		time.Sleep(latency)
+		ss := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case len(ss) == 4 && ss[1] == "addr" && ss[3] == "balance":
+			fmt.Fprint(w, "100")
+		case len(ss) == 4 && ss[1] == "addrs" && ss[3] == "utxo" && batch:
+			us := []BlkExplrUtxo{}
+			for _, a := range strings.Split(ss[2], ",") {
+				us = append(us, BlkExplrUtxo{Address: a, Amount: 100})
+			}
+			json.NewEncoder(w).Encode(us)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return srv, &requests
+}
+
+func withBlkExplrAPI(url string) func() {
+	api := BlkExplrAPI
+	BlkExplrAPI = url + "/api"
+	return func() { BlkExplrAPI = api }
+}
+
+func makeTestAddrs(n int) []string {
+	addrs := make([]string, n)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("addr%d", i)
+	}
+	return addrs
+}
+
+func TestGetBalanceBatch(t *testing.T) {
+	addrs := makeTestAddrs(120)
+
+	srv, requests := newBlkExplrMock(true, 0)
+	defer srv.Close()
+	defer withBlkExplrAPI(srv.URL)()
+
+	stats := GetBalanceStats()
+	bal, err := Bitcoin{}.GetBalance(addrs)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(12000), bal.GetAmount())
+
+	// the addresses are queried in 3 batches.
+	assert.Equal(t, uint64(3), atomic.LoadUint64(requests))
+	s := GetBalanceStats()
+	assert.Equal(t, stats.Queries+1, s.Queries)
+	assert.Equal(t, stats.Requests+3, s.Requests)
+	assert.Equal(t, stats.Fallbacks, s.Fallbacks)
+	assert.True(t, s.Duration > stats.Duration)
+}
+
+func TestGetBalanceBatchFallback(t *testing.T) {
+	addrs := makeTestAddrs(60)
+
+	srv, requests := newBlkExplrMock(false, 0)
+	defer srv.Close()
+	defer withBlkExplrAPI(srv.URL)()
+
+	stats := GetBalanceStats()
+	bal, err := Bitcoin{}.GetBalance(addrs)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(6000), bal.GetAmount())
+
+	// both batches fail, and each address is queried separately.
+	assert.Equal(t, uint64(62), atomic.LoadUint64(requests))
+	s := GetBalanceStats()
+	assert.Equal(t, stats.Requests+62, s.Requests)
+	assert.Equal(t, stats.Fallbacks+2, s.Fallbacks)
+}
+
+// BenchmarkGetBalance compares querying the balance of 100 addresses one by one
+// with querying them in batches, the server takes 1ms to serve each request.
+func BenchmarkGetBalance(b *testing.B) {
+	addrs := makeTestAddrs(100)
+	srv, _ := newBlkExplrMock(true, time.Millisecond)
+	defer srv.Close()
+	defer withBlkExplrAPI(srv.URL)()
+
+	b.Run("PerAddress", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := insightNet.GetBalance(addrs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := getBalanceBatchExplr(addrs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestGetFeeEstimates(t *testing.T) {
+	var requests uint64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint64(&requests, 1)
+		if r.URL.Path != "/api/utils/estimatefee" || r.URL.Query().Get("nbBlocks") != "1,3,6" {
+			http.NotFound(w, r)
+			return
+		}
+		// the node has no estimate for 6 blocks.
+		fmt.Fprint(w, `{"1":0.00045,"3":0.000295,"6":-1}`)
+	}))
+	defer srv.Close()
+	defer withBlkExplrAPI(srv.URL)()
+
+	want := []coin.FeeEstimate{{Blocks: 1, FeeRate: 45}, {Blocks: 3, FeeRate: 30}, {Blocks: 6, FeeRate: FeeRate}}
+	btc := Bitcoin{}
+	fes, err := btc.GetFeeEstimates()
+	assert.Nil(t, err)
+	assert.Equal(t, want, fes)
+
+	// cached, the node is not queried again.
+	fes[0].FeeRate = 0
+	fes, err = btc.GetFeeEstimates()
+	assert.Nil(t, err)
+	assert.Equal(t, want, fes)
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&requests))
+
+	// expired.
+	ttl := FeeEstimatesTTL
+	FeeEstimatesTTL = 0
+	defer func() { FeeEstimatesTTL = ttl }()
+	_, err = btc.GetFeeEstimates()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&requests))
+}
+
+func TestGetFeeEstimatesFailed(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	defer withBlkExplrAPI(srv.URL)()
+
+	_, err := Bitcoin{}.GetFeeEstimates()
+	assert.NotNil(t, err)
+}
+
+func TestGetDetailedBalance(t *testing.T) {
+	addrs := makeTestAddrs(60)
+	var requests uint64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint64(&requests, 1)
+		ss := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(ss) != 4 || ss[1] != "addrs" || ss[3] != "utxo" {
+			http.NotFound(w, r)
+			return
+		}
+
+		// addr0 has a pending deposit, addr1 has a pending change and a confirmed utxo.
+		us := []BlkExplrUtxo{}
+		for _, a := range strings.Split(ss[2], ",") {
+			switch a {
+			case "addr0":
+				us = append(us, BlkExplrUtxo{Address: a, Txid: "deposit", Amount: 5000})
+			case "addr1":
+				us = append(us, BlkExplrUtxo{Address: a, Txid: "change", Amount: 300})
+				us = append(us, BlkExplrUtxo{Address: a, Txid: "old", Amount: 1000, Confirms: 6})
+			default:
+				us = append(us, BlkExplrUtxo{Address: a, Txid: a, Amount: 100, Confirms: 1})
+			}
+		}
+		json.NewEncoder(w).Encode(us)
+	}))
+	defer srv.Close()
+	defer withBlkExplrAPI(srv.URL)()
+
+	bal, err := Bitcoin{}.GetDetailedBalance(addrs)
+	assert.Nil(t, err)
+	assert.Equal(t, coin.DetailedBalance{Confirmed: 6800, Unconfirmed: 5300, Total: 12100}, bal)
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&requests))
+
+	bal, err = Bitcoin{}.GetDetailedBalance([]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, coin.DetailedBalance{}, bal)
+
+	srv.Close()
+	_, err = Bitcoin{}.GetDetailedBalance(addrs)
+	assert.NotNil(t, err)
+}
@@ -5,10 +5,10 @@ import (
 	"encoding/hex"
 	"errors"
 	"reflect"
+	"strconv"
 
 	"fmt"
 
-	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
@@ -21,12 +21,12 @@ type Bitcoin struct{}
 
 // GetTx get bitcoin transaction of specific txid.
 func (btc Bitcoin) GetTx(txid string) (*pp.Tx, error) {
-	return getTxVerboseExplr(txid)
+	return insightNet.GetTxVerbose(txid)
 }
 
 // GetRawTx get bitcoin raw transaction of specific txid.
 func (btc Bitcoin) GetRawTx(txid string) (string, error) {
-	return getRawtxExplr(txid)
+	return insightNet.GetRawTx(txid)
 }
 
 // InjectTx inject bitcoin raw transaction.
@@ -34,15 +34,42 @@ func (btc Bitcoin) InjectTx(rawtx string) (string, error) {
 	return BroadcastTx(rawtx)
 }
 
-// GetBalance get balance of specific addresses.
+// GetBalance get balance of specific addresses, the addresses are queried in batches.
 func (btc Bitcoin) GetBalance(addrs []string) (pp.Balance, error) {
-	v, err := getBalanceExplr(addrs)
+	v, err := getBalanceBatchExplr(addrs)
 	if err != nil {
 		return pp.Balance{}, err
 	}
 	return pp.Balance{Amount: pp.PtrUint64(v)}, nil
 }
 
+// GetDetailedBalance get the balance of specific addresses split by the confirmation status
+// of their utxos, the addresses are queried in batches.
+func (btc Bitcoin) GetDetailedBalance(addrs []string) (coin.DetailedBalance, error) {
+	bal := coin.DetailedBalance{}
+	for i := 0; i < len(addrs); i += BalanceBatchSize {
+		end := i + BalanceBatchSize
+		if end > len(addrs) {
+			end = len(addrs)
+		}
+
+		utxos, err := insightNet.GetUnspentOutputs(addrs[i:end])
+		if err != nil {
+			return coin.DetailedBalance{}, err
+		}
+
+		for _, u := range utxos {
+			if u.GetConfirmations() > 0 {
+				bal.Confirmed += u.GetAmount()
+			} else {
+				bal.Unconfirmed += u.GetAmount()
+			}
+		}
+	}
+	bal.Total = bal.Confirmed + bal.Unconfirmed
+	return bal, nil
+}
+
 // CreateRawTx create bitcoin raw transaction.
 func (btc Bitcoin) CreateRawTx(txIns []coin.TxIn, txOuts interface{}) (string, error) {
 	tx := wire.NewMsgTx()
@@ -77,21 +104,20 @@ func (btc Bitcoin) CreateRawTx(txIns []coin.TxIn, txOuts interface{}) (string, e
 		outs[i] = s.Index(i).Interface()
 	}
 
-	if len(outs) > 2 {
-		return "", errors.New("out address more than 2")
+	if len(outs) == 0 {
+		return "", errors.New("no output address")
 	}
 
 	for _, o := range outs {
 		out := o.(TxOut)
-		addr, err := btcutil.DecodeAddress(out.Addr, &chaincfg.MainNetParams)
+		txout, err := createTxOut(out.Value, out.Addr)
 		if err != nil {
 			return "", err
 		}
-		txout := createTxOut(out.Value, addr)
 		tx.AddTxOut(txout)
 	}
 
-	t := Transaction{*tx}
+	t := Transaction{MsgTx: *tx}
 	d, err := t.Serialize()
 	if err != nil {
 		return "", err
@@ -113,11 +139,13 @@ func (btc Bitcoin) SignRawTx(rawtx string, getKey coin.GetPrivKey) (string, erro
 	}
 
 	// get scriptPubkey and addr of the inputs.
+	prevOuts := make([]*wire.TxOut, len(tx.TxIn))
+	addrs := make([]string, len(tx.TxIn))
 	for i, t := range tx.TxIn {
 		txid := t.PreviousOutPoint.Hash.String()
 		index := t.PreviousOutPoint.Index
 		// get the scriptPubkey and addr.
-		vt, err := getTxVerboseExplr(txid)
+		vt, err := insightNet.GetTxVerbose(txid)
 		if err != nil {
 			return "", err
 		}
@@ -132,19 +160,32 @@ func (btc Bitcoin) SignRawTx(rawtx string, getKey coin.GetPrivKey) (string, erro
 			return "", err
 		}
 
-		// get private key of specific address in wallet.
-		wltPrivKey, err := getKey(addr[0])
+		// the amount is only signed by the witness input.
+		v, err := strconv.ParseFloat(outs[index].GetValue(), 64)
 		if err != nil {
 			return "", err
 		}
-
-		sig, err := signRawTx(&tx, i, wltPrivKey, sp)
+		amt, err := btcutil.NewAmount(v)
 		if err != nil {
 			return "", err
 		}
 
-		tx.TxIn[i].SignatureScript = sig
+		prevOuts[i] = wire.NewTxOut(int64(amt), sp)
+		addrs[i] = addr[0]
 	}
+
+	if err := signTx(&tx, prevOuts, addrs, getKey); err != nil {
+		return "", err
+	}
+
+	// track the transaction, so that it can be replaced by BumpFee.
+	replaceableTxs.add(tx.TxHash().String(), &replaceableTx{
+		tx:       &tx,
+		prevOuts: prevOuts,
+		addrs:    addrs,
+		getKey:   getKey,
+	})
+
 	txb, err := tx.Serialize()
 	if err != nil {
 		return "", err
@@ -162,6 +203,111 @@ func (btc *Bitcoin) ValidateTxid(txid string) bool {
 	return err == nil
 }
 
+// EstimateFee estimates bitcoin transaction fee by the transaction size.
+func (btc Bitcoin) EstimateFee(nInputs, nOutputs int) (uint64, error) {
+	size, err := EstimateTxSize(nInputs, nOutputs)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(size) * FeeRate, nil
+}
+
+// GetFeeEstimates returns the fee rates estimated by the node behind blockexplorer.com,
+// the estimates are cached for FeeEstimatesTTL.
+func (btc Bitcoin) GetFeeEstimates() ([]coin.FeeEstimate, error) {
+	return getFeeEstimatesExplr()
+}
+
+// EstimateTxSize estimates the byte count of P2PKH transaction, each input with
+// compressed pubkey takes at most 148 bytes, each output takes 34 bytes, and the
+// version, locktime and counters take 10 bytes.
+func EstimateTxSize(nInputs, nOutputs int) (int, error) {
+	if nInputs <= 0 || nOutputs <= 0 {
+		return 0, fmt.Errorf("invalid inputs number %d or outputs number %d", nInputs, nOutputs)
+	}
+	ins := make([]ScriptType, nInputs)
+	outs := make([]ScriptType, nOutputs)
+	return EstimateVsize(ins, outs)
+}
+
+// ScriptType the type of scriptPubkey spent by the input or paid by the output.
+type ScriptType uint8
+
+const (
+	// P2PKH pay to legacy pubkey hash address.
+	P2PKH ScriptType = iota
+	// P2WPKH pay to segwit version 0 pubkey hash address.
+	P2WPKH
+)
+
+// AddrScriptType returns the script type of the address.
+func AddrScriptType(addr string) ScriptType {
+	if isSegwitAddress(addr) {
+		return P2WPKH
+	}
+	return P2PKH
+}
+
+// the sizes in bytes used to estimate the transaction size, the signature is at most 72 bytes,
+// and the pubkey is compressed.
+const (
+	txOverheadSize    = 10  // version, locktime, and the counters of inputs and outputs.
+	witnessHeaderSize = 2   // segwit marker and flag.
+	p2pkhInputSize    = 148 // outpoint, script length, signature script and sequence.
+	p2wpkhInputSize   = 41  // outpoint, empty script and sequence.
+	p2wpkhWitnessSize = 108 // stack items count, signature and pubkey.
+	p2pkhOutputSize   = 34
+	p2wpkhOutputSize  = 31
+)
+
+// EstimateVsize estimates the virtual size of the transaction in vbytes, the inputs and outputs
+// are given by their script types. The witness data are discounted as BIP141, each of the
+// witness bytes counts 1/4 vbyte.
+func EstimateVsize(ins, outs []ScriptType) (int, error) {
+	if len(ins) == 0 || len(outs) == 0 {
+		return 0, fmt.Errorf("invalid inputs number %d or outputs number %d", len(ins), len(outs))
+	}
+
+	base, witness := txOverheadSize, 0
+	for _, in := range ins {
+		switch in {
+		case P2PKH:
+			base += p2pkhInputSize
+		case P2WPKH:
+			base += p2wpkhInputSize
+			witness += p2wpkhWitnessSize
+		default:
+			return 0, fmt.Errorf("unknow input script type %d", in)
+		}
+	}
+
+	for _, out := range outs {
+		switch out {
+		case P2PKH:
+			base += p2pkhOutputSize
+		case P2WPKH:
+			base += p2wpkhOutputSize
+		default:
+			return 0, fmt.Errorf("unknow output script type %d", out)
+		}
+	}
+
+	if witness > 0 {
+		witness += witnessHeaderSize
+	}
+	weight := base*4 + witness
+	return (weight + 3) / 4, nil
+}
+
+// EstimateFeeWithRate estimates the fee of transaction in satoshis at rate satoshis per vbyte.
+func EstimateFeeWithRate(ins, outs []ScriptType, rate uint64) (uint64, error) {
+	size, err := EstimateVsize(ins, outs)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(size) * rate, nil
+}
+
 // GetUtxos gets bitcoin utxos of specific addresses.
 func (btc *Bitcoin) GetUtxos(addrs []string) (interface{}, error) {
 	utxos, err := GetUnspentOutputs(addrs)
@@ -186,6 +332,11 @@ func (btc *Bitcoin) GetUtxos(addrs []string) (interface{}, error) {
 	return res, nil
 }
 
+// GetAddressTxs gets bitcoin transactions of specific addresses.
+func (btc *Bitcoin) GetAddressTxs(addrs []string) ([]*pp.Tx, error) {
+	return insightNet.GetAddressTxs(addrs)
+}
+
 // GetOutput not implemented yet.
 func (btc *Bitcoin) GetOutput(hash string) (interface{}, error) {
 	return nil, fmt.Errorf("get output by has is not supported by bitcoin")
@@ -200,3 +351,16 @@ func (btc *Bitcoin) Symbol() string {
 func (btc *Bitcoin) Type() string {
 	return Type
 }
+
+// HealthCheck checks if the blockexplorer.com api is available.
+func (btc *Bitcoin) HealthCheck() (bool, error) {
+	if err := insightNet.HealthCheck(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Decimals returns the decimal places of bitcoin, the amounts are in satoshis.
+func (btc *Bitcoin) Decimals() int {
+	return 8
+}
@@ -0,0 +1,81 @@
+package bitcoin_interface
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateFee(t *testing.T) {
+	btc := Bitcoin{}
+	fee, err := btc.EstimateFee(1, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(226)*FeeRate, fee)
+
+	// the fee grows with the number of inputs and outputs.
+	var last uint64
+	for n := 1; n <= 5; n++ {
+		fee, err := btc.EstimateFee(n, 2)
+		assert.Nil(t, err)
+		assert.True(t, fee > last)
+		last = fee
+	}
+
+	for n := 1; n <= 5; n++ {
+		fee, err := btc.EstimateFee(1, n)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(158+n*34)*FeeRate, fee)
+	}
+
+	// each input costs more than each output.
+	inFee, _ := btc.EstimateFee(2, 1)
+	outFee, _ := btc.EstimateFee(1, 2)
+	assert.True(t, inFee > outFee)
+
+	for _, v := range [][2]int{{0, 1}, {1, 0}, {-1, 2}} {
+		_, err := btc.EstimateFee(v[0], v[1])
+		assert.NotNil(t, err)
+	}
+}
+
+func TestEstimateFeeWithRate(t *testing.T) {
+	testData := []struct {
+		ins   []ScriptType
+		outs  []ScriptType
+		vsize int
+	}{
+		{[]ScriptType{P2PKH}, []ScriptType{P2PKH, P2PKH}, 226},
+		{[]ScriptType{P2PKH, P2PKH}, []ScriptType{P2PKH}, 340},
+		{[]ScriptType{P2PKH}, []ScriptType{P2WPKH, P2PKH}, 223},
+		// 113 bytes base and 110 bytes witness, 562 weight units.
+		{[]ScriptType{P2WPKH}, []ScriptType{P2WPKH, P2WPKH}, 141},
+		// 381 bytes base and 110 bytes witness, 1634 weight units.
+		{[]ScriptType{P2PKH, P2PKH, P2WPKH}, []ScriptType{P2PKH}, 409},
+		{[]ScriptType{P2WPKH, P2WPKH}, []ScriptType{P2PKH, P2PKH}, 215},
+	}
+
+	for _, d := range testData {
+		size, err := EstimateVsize(d.ins, d.outs)
+		assert.Nil(t, err)
+		assert.Equal(t, d.vsize, size)
+
+		fee, err := EstimateFeeWithRate(d.ins, d.outs, 15)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(d.vsize)*15, fee)
+	}
+
+	// the segwit input is cheaper than the legacy one.
+	legacy, _ := EstimateFeeWithRate([]ScriptType{P2PKH}, []ScriptType{P2PKH}, 10)
+	segwit, _ := EstimateFeeWithRate([]ScriptType{P2WPKH}, []ScriptType{P2PKH}, 10)
+	assert.True(t, segwit < legacy)
+
+	_, err := EstimateVsize(nil, []ScriptType{P2PKH})
+	assert.NotNil(t, err)
+	_, err = EstimateVsize([]ScriptType{P2PKH}, nil)
+	assert.NotNil(t, err)
+	_, err = EstimateVsize([]ScriptType{ScriptType(9)}, []ScriptType{P2PKH})
+	assert.NotNil(t, err)
+
+	assert.Equal(t, P2PKH, AddrScriptType("1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"))
+	assert.Equal(t, P2WPKH, AddrScriptType("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"))
+}
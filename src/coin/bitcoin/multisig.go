@@ -0,0 +1,191 @@
+package bitcoin_interface
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// the M-of-N multisig is paid to P2SH address, the redeem script is
+// `M <pubkey>... N OP_CHECKMULTISIG`, each party signs the spending transaction
+// with its own key, and the signatures are combined into the signature script.
+
+// MaxMultisigKeys max pubkeys of the multisig, the redeem script of P2SH can't exceed 520 bytes.
+const MaxMultisigKeys = 15
+
+// NewMultisigAddress creates the P2SH address of nRequired-of-len(pubkeys) multisig, the pubkeys
+// are in hex, returns the address and the redeem script in hex which is required for signing.
+func NewMultisigAddress(nRequired int, pubkeys []string) (string, string, error) {
+	if len(pubkeys) == 0 || len(pubkeys) > MaxMultisigKeys {
+		return "", "", fmt.Errorf("invalid pubkeys number %d", len(pubkeys))
+	}
+
+	if nRequired <= 0 || nRequired > len(pubkeys) {
+		return "", "", fmt.Errorf("invalid required signatures number %d of %d pubkeys", nRequired, len(pubkeys))
+	}
+
+	keys := make([]*btcutil.AddressPubKey, len(pubkeys))
+	for i, pk := range pubkeys {
+		b, err := hex.DecodeString(pk)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid pubkey %s", pk)
+		}
+		keys[i], err = btcutil.NewAddressPubKey(b, NetParams())
+		if err != nil {
+			return "", "", fmt.Errorf("invalid pubkey %s, %v", pk, err)
+		}
+	}
+
+	script, err := txscript.MultiSigScript(keys, nRequired)
+	if err != nil {
+		return "", "", err
+	}
+
+	addr, err := btcutil.NewAddressScriptHash(script, NetParams())
+	if err != nil {
+		return "", "", err
+	}
+	return addr.EncodeAddress(), hex.EncodeToString(script), nil
+}
+
+// SignMultisig partially signs the input of index which spends the P2SH multisig output of
+// redeemScript, returns the signature of the private key, the signatures of all parties are
+// assembled by CombineSignatures. The transaction is not changed, so the parties can sign
+// their own copies.
+func SignMultisig(tx *Transaction, index int, wifPrivKey string, redeemScript []byte) ([]byte, error) {
+	pubkeys, _, err := parseMultisigScript(redeemScript)
+	if err != nil {
+		return nil, err
+	}
+
+	wif, err := btcutil.DecodeWIF(wifPrivKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var isSigner bool
+	for _, pk := range pubkeys {
+		if pk.IsEqual(wif.PrivKey.PubKey()) {
+			isSigner = true
+			break
+		}
+	}
+	if !isSigner {
+		return nil, errors.New("private key is not a signer of the multisig")
+	}
+
+	hash, err := legacySigHash(&tx.MsgTx, index, redeemScript)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := wif.PrivKey.Sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	return append(sig.Serialize(), byte(txscript.SigHashAll)), nil
+}
+
+// CombineSignatures assembles the signature script of the multisig input of index from the
+// signatures of the parties. The valid signatures are put in the order of the pubkeys in
+// redeemScript, the invalid and extra ones are dropped. Returns error if the valid signatures
+// are less than required.
+func CombineSignatures(tx *Transaction, index int, redeemScript []byte, sigs [][]byte) error {
+	pubkeys, nRequired, err := parseMultisigScript(redeemScript)
+	if err != nil {
+		return err
+	}
+
+	hash, err := legacySigHash(&tx.MsgTx, index, redeemScript)
+	if err != nil {
+		return err
+	}
+
+	valid := [][]byte{}
+	for _, pk := range pubkeys {
+		if len(valid) == nRequired {
+			break
+		}
+
+		for _, s := range sigs {
+			if len(s) == 0 || txscript.SigHashType(s[len(s)-1]) != txscript.SigHashAll {
+				continue
+			}
+			sig, err := btcec.ParseDERSignature(s[:len(s)-1], btcec.S256())
+			if err != nil {
+				continue
+			}
+			if sig.Verify(hash, pk) {
+				valid = append(valid, s)
+				break
+			}
+		}
+	}
+
+	if len(valid) < nRequired {
+		return fmt.Errorf("%d valid signatures, %d required", len(valid), nRequired)
+	}
+
+	// OP_CHECKMULTISIG pops an extra item, which must be OP_0.
+	b := txscript.NewScriptBuilder().AddOp(txscript.OP_0)
+	for _, s := range valid {
+		b.AddData(s)
+	}
+	script, err := b.AddData(redeemScript).Script()
+	if err != nil {
+		return err
+	}
+	tx.TxIn[index].SignatureScript = script
+	return nil
+}
+
+// parseMultisigScript returns the pubkeys and the required signatures number of the redeem script.
+func parseMultisigScript(redeemScript []byte) ([]*btcec.PublicKey, int, error) {
+	class, addrs, nRequired, err := txscript.ExtractPkScriptAddrs(redeemScript, NetParams())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if class != txscript.MultiSigTy {
+		return nil, 0, errors.New("not multisig redeem script")
+	}
+
+	pubkeys := make([]*btcec.PublicKey, len(addrs))
+	for i, a := range addrs {
+		pk, ok := a.(*btcutil.AddressPubKey)
+		if !ok {
+			return nil, 0, errors.New("not multisig redeem script")
+		}
+		pubkeys[i] = pk.PubKey()
+	}
+	return pubkeys, nRequired, nil
+}
+
+// legacySigHash computes the SIGHASH_ALL signature hash of the non-witness input of index,
+// subScript is the script being spent, which has no OP_CODESEPARATOR.
+func legacySigHash(tx *wire.MsgTx, index int, subScript []byte) ([]byte, error) {
+	if index < 0 || index >= len(tx.TxIn) {
+		return nil, fmt.Errorf("input index %d out of range", index)
+	}
+
+	txCopy := tx.Copy()
+	for i := range txCopy.TxIn {
+		txCopy.TxIn[i].SignatureScript = nil
+	}
+	txCopy.TxIn[index].SignatureScript = subScript
+
+	var buf bytes.Buffer
+	if err := txCopy.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	binary.Write(&buf, binary.LittleEndian, uint32(txscript.SigHashAll))
+	return chainhash.DoubleHashB(buf.Bytes()), nil
+}
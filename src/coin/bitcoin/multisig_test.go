@@ -0,0 +1,104 @@
+package bitcoin_interface
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMultisigKeys(t *testing.T, n int) ([]string, []string) {
+	wifs := make([]string, n)
+	pubkeys := make([]string, n)
+	for i := range wifs {
+		priv, err := btcec.NewPrivateKey(btcec.S256())
+		assert.Nil(t, err)
+		wif, err := btcutil.NewWIF(priv, &chaincfg.MainNetParams, true)
+		assert.Nil(t, err)
+		wifs[i] = wif.String()
+		pubkeys[i] = hex.EncodeToString(priv.PubKey().SerializeCompressed())
+	}
+	return wifs, pubkeys
+}
+
+func TestMultisigAddress(t *testing.T) {
+	_, pubkeys := newMultisigKeys(t, 3)
+	addr, script, err := NewMultisigAddress(2, pubkeys)
+	assert.Nil(t, err)
+	assert.Equal(t, byte('3'), addr[0])
+	assert.Nil(t, ValidateAddr(addr))
+
+	// the address pays to the hash of redeem script.
+	rs, err := hex.DecodeString(script)
+	assert.Nil(t, err)
+	pkScript, err := payToAddrScript(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, btcutil.Hash160(rs), pkScript[2:22])
+
+	for _, n := range []int{0, 4, -1} {
+		_, _, err := NewMultisigAddress(n, pubkeys)
+		assert.NotNil(t, err)
+	}
+	_, _, err = NewMultisigAddress(1, []string{})
+	assert.NotNil(t, err)
+	_, _, err = NewMultisigAddress(1, []string{"00"})
+	assert.NotNil(t, err)
+}
+
+func TestMultisigSpend(t *testing.T) {
+	wifs, pubkeys := newMultisigKeys(t, 3)
+	addr, script, err := NewMultisigAddress(2, pubkeys)
+	assert.Nil(t, err)
+	redeemScript, _ := hex.DecodeString(script)
+	pkScript, err := payToAddrScript(addr)
+	assert.Nil(t, err)
+
+	tx := Transaction{MsgTx: *wire.NewMsgTx()}
+	prevHash, _ := chainhash.NewHashFromStr("ef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a")
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(prevHash, 0), nil))
+	out, err := createTxOut(90000, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")
+	assert.Nil(t, err)
+	tx.AddTxOut(out)
+
+	// the parties sign in any order.
+	sig2, err := SignMultisig(&tx, 0, wifs[2], redeemScript)
+	assert.Nil(t, err)
+	sig0, err := SignMultisig(&tx, 0, wifs[0], redeemScript)
+	assert.Nil(t, err)
+	assert.Empty(t, tx.TxIn[0].SignatureScript)
+
+	// the key not in the multisig can't sign.
+	others, _ := newMultisigKeys(t, 1)
+	_, err = SignMultisig(&tx, 0, others[0], redeemScript)
+	assert.NotNil(t, err)
+	_, err = SignMultisig(&tx, 1, wifs[0], redeemScript)
+	assert.NotNil(t, err)
+
+	// one signature is not enough.
+	assert.NotNil(t, CombineSignatures(&tx, 0, redeemScript, [][]byte{sig2}))
+	// the invalid signatures are dropped.
+	assert.NotNil(t, CombineSignatures(&tx, 0, redeemScript, [][]byte{sig2, sig2, {0x01}}))
+
+	assert.Nil(t, CombineSignatures(&tx, 0, redeemScript, [][]byte{sig2, {0x01}, sig0}))
+	vm, err := txscript.NewEngine(pkScript, &tx.MsgTx, 0, txscript.StandardVerifyFlags, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, vm.Execute())
+
+	// the signed transaction survives serialization.
+	d, err := tx.Serialize()
+	assert.Nil(t, err)
+	tx1 := Transaction{}
+	assert.Nil(t, tx1.Deserialize(bytes.NewBuffer(d)))
+	assert.Equal(t, tx.TxIn[0].SignatureScript, tx1.TxIn[0].SignatureScript)
+
+	// the signatures commit to the outputs.
+	tx.TxOut[0].Value = 80000
+	assert.NotNil(t, CombineSignatures(&tx, 0, redeemScript, [][]byte{sig0, sig2}))
+}
@@ -0,0 +1,65 @@
+package bitcoin_interface
+
+import "github.com/btcsuite/btcd/chaincfg"
+
+// TestnetSegwitHRP the human readable part of bitcoin testnet segwit addresses.
+const TestnetSegwitHRP = "tb"
+
+// the apis of the mainnet and testnet3.
+const (
+	mainBlkExplrAPI = "https://blockexplorer.com/api"
+	testBlkExplrAPI = "https://testnet.blockexplorer.com/api"
+	mainBlkChnAPI   = "https://blockchain.info"
+	testBlkChnAPI   = "https://testnet.blockchain.info"
+	mainInsightAPI  = "https://insight.bitpay.com/api"
+	testInsightAPI  = "https://test-insight.bitpay.com/api"
+)
+
+var testnet bool
+
+// SetTestnet switches the package between bitcoin mainnet and testnet3, the address formats,
+// the private key format and the apis of the network are used afterwards. BlkExplrAPI is reset
+// to the api of the network. It should be called before any address is generated or validated.
+func SetTestnet(t bool) {
+	testnet = t
+	if t {
+		BlkExplrAPI = testBlkExplrAPI
+		return
+	}
+	BlkExplrAPI = mainBlkExplrAPI
+}
+
+// IsTestnet returns true if the package works on bitcoin testnet3.
+func IsTestnet() bool {
+	return testnet
+}
+
+// NetParams returns the chain params of the current network.
+func NetParams() *chaincfg.Params {
+	if testnet {
+		return &chaincfg.TestNet3Params
+	}
+	return &chaincfg.MainNetParams
+}
+
+// segwitHRP returns the human readable part of the segwit addresses of the current network.
+func segwitHRP() string {
+	if testnet {
+		return TestnetSegwitHRP
+	}
+	return SegwitHRP
+}
+
+func blkChnAPI() string {
+	if testnet {
+		return testBlkChnAPI
+	}
+	return mainBlkChnAPI
+}
+
+func insightAPI() string {
+	if testnet {
+		return testInsightAPI
+	}
+	return mainInsightAPI
+}
@@ -0,0 +1,72 @@
+package bitcoin_interface
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestnetValidateAddr(t *testing.T) {
+	defer SetTestnet(false)
+
+	testnetAddrs := []string{
+		"tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
+		"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7",
+		"mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn",
+		"2MzQwSSnBHWHqSAqtTVQ6v47XtaisrJa1Vc",
+	}
+	mainnetAddrs := []string{
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+		"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+		"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy",
+	}
+
+	// the testnet segwit addresses are rejected in mainnet.
+	for _, addr := range testnetAddrs[:2] {
+		assert.NotNil(t, ValidateAddr(addr), addr)
+	}
+
+	SetTestnet(true)
+	assert.True(t, IsTestnet())
+	assert.Equal(t, &chaincfg.TestNet3Params, NetParams())
+	assert.Equal(t, testBlkExplrAPI, BlkExplrAPI)
+	for _, addr := range testnetAddrs {
+		assert.Nil(t, ValidateAddr(addr), addr)
+	}
+	for _, addr := range mainnetAddrs {
+		assert.NotNil(t, ValidateAddr(addr), addr)
+	}
+
+	// the scripts pay to the testnet addresses.
+	for _, addr := range testnetAddrs {
+		_, err := payToAddrScript(addr)
+		assert.Nil(t, err, addr)
+	}
+
+	SetTestnet(false)
+	assert.False(t, IsTestnet())
+	assert.Equal(t, mainBlkExplrAPI, BlkExplrAPI)
+	assert.Nil(t, ValidateAddr(mainnetAddrs[0]))
+}
+
+func TestTestnetAddressEntry(t *testing.T) {
+	defer SetTestnet(false)
+	SetTestnet(true)
+
+	priv, err := btcec.NewPrivateKey(btcec.S256())
+	assert.Nil(t, err)
+	entry := testnetAddressEntry(priv.Serialize())
+	assert.Nil(t, ValidateAddr(entry.Address))
+
+	wif, err := btcutil.DecodeWIF(entry.Secret)
+	assert.Nil(t, err)
+	assert.True(t, wif.IsForNet(&chaincfg.TestNet3Params))
+
+	addr, err := SegwitAddressFromPubkey(wif.SerializePubKey())
+	assert.Nil(t, err)
+	assert.Equal(t, "tb1", addr[:3])
+	assert.Nil(t, ValidateAddr(addr))
+}
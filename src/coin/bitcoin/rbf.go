@@ -0,0 +1,184 @@
+package bitcoin_interface
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/skycoin/skycoin-exchange/src/coin"
+)
+
+// the inputs of the transactions created by the gateway signal opt-in replace-by-fee as BIP125,
+// the signed transactions are tracked, so that the stuck ones can be replaced by BumpFee with
+// the same inputs at a higher fee rate.
+
+// RBFSequence the sequence number of inputs, which is lower than 0xfffffffe, signals the
+// transaction is replaceable.
+const RBFSequence = wire.MaxTxInSequenceNum - 2
+
+// DustLimit the min value in satoshis of the change output left by BumpFee.
+const DustLimit = 546
+
+// MaxReplaceableTxs max signed transactions tracked for BumpFee, the oldest ones are dropped.
+var MaxReplaceableTxs = 1000
+
+// replaceableTx the signed transaction with everything required to sign its replacement.
+type replaceableTx struct {
+	tx       *Transaction
+	prevOuts []*wire.TxOut // the outputs spent by the inputs.
+	addrs    []string      // the addresses of prevOuts, whose private keys sign the inputs.
+	getKey   coin.GetPrivKey
+}
+
+// txTracker records the replaceable transactions by txid.
+type txTracker struct {
+	mtx   sync.Mutex
+	txs   map[string]*replaceableTx
+	order []string // txids in the order of being added.
+}
+
+var replaceableTxs = txTracker{txs: make(map[string]*replaceableTx)}
+
+func (tt *txTracker) add(txid string, rtx *replaceableTx) {
+	tt.mtx.Lock()
+	defer tt.mtx.Unlock()
+	if _, ok := tt.txs[txid]; !ok {
+		tt.order = append(tt.order, txid)
+	}
+	tt.txs[txid] = rtx
+	for len(tt.order) > MaxReplaceableTxs {
+		delete(tt.txs, tt.order[0])
+		tt.order = tt.order[1:]
+	}
+}
+
+func (tt *txTracker) get(txid string) (*replaceableTx, bool) {
+	tt.mtx.Lock()
+	defer tt.mtx.Unlock()
+	rtx, ok := tt.txs[txid]
+	return rtx, ok
+}
+
+func (tt *txTracker) remove(txid string) {
+	tt.mtx.Lock()
+	defer tt.mtx.Unlock()
+	if _, ok := tt.txs[txid]; !ok {
+		return
+	}
+	delete(tt.txs, txid)
+	for i, id := range tt.order {
+		if id == txid {
+			tt.order = append(tt.order[:i], tt.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// BumpFee replaces the stuck transaction of txid, which must be signed by the gateway, with the
+// transaction spending the same inputs at newFeeRate satoshis per vbyte. The fee increase is paid
+// by the last output, which is the change of withdrawal. Returns the txid of the replacement.
+func (btc *Bitcoin) BumpFee(txid string, newFeeRate uint64) (string, error) {
+	rtx, err := replaceTx(txid, newFeeRate)
+	if err != nil {
+		return "", err
+	}
+
+	d, err := rtx.tx.Serialize()
+	if err != nil {
+		return "", err
+	}
+
+	newTxid, err := btc.InjectTx(hex.EncodeToString(d))
+	if err != nil {
+		return "", err
+	}
+
+	logger.Info("bitcoin tx %s is replaced by %s at fee rate %d", txid, newTxid, newFeeRate)
+	replaceableTxs.remove(txid)
+	replaceableTxs.add(newTxid, rtx)
+	return newTxid, nil
+}
+
+// replaceTx creates and signs the replacement of the tracked transaction at rate.
+func replaceTx(txid string, rate uint64) (*replaceableTx, error) {
+	old, ok := replaceableTxs.get(txid)
+	if !ok {
+		return nil, fmt.Errorf("bitcoin tx %s is not tracked", txid)
+	}
+
+	if len(old.tx.TxOut) < 2 {
+		return nil, errors.New("no change output to pay the fee increase")
+	}
+
+	var inAmt, outAmt int64
+	for _, o := range old.prevOuts {
+		inAmt += o.Value
+	}
+	for _, o := range old.tx.TxOut {
+		outAmt += o.Value
+	}
+	oldFee := inAmt - outAmt
+
+	vsize, err := old.tx.vsize()
+	if err != nil {
+		return nil, err
+	}
+	newFee := int64(vsize) * int64(rate)
+	if newFee <= oldFee {
+		return nil, fmt.Errorf("fee %d at rate %d is not higher than the original fee %d", newFee, rate, oldFee)
+	}
+
+	tx := &Transaction{MsgTx: *old.tx.MsgTx.Copy()}
+	chg := tx.TxOut[len(tx.TxOut)-1]
+	if chg.Value-(newFee-oldFee) < DustLimit {
+		return nil, fmt.Errorf("change %d can't pay the fee increase %d", chg.Value, newFee-oldFee)
+	}
+	chg.Value -= newFee - oldFee
+
+	for _, in := range tx.TxIn {
+		in.SignatureScript = nil
+		in.Sequence = RBFSequence
+	}
+
+	if err := signTx(tx, old.prevOuts, old.addrs, old.getKey); err != nil {
+		return nil, err
+	}
+
+	return &replaceableTx{
+		tx:       tx,
+		prevOuts: old.prevOuts,
+		addrs:    old.addrs,
+		getKey:   old.getKey,
+	}, nil
+}
+
+// signTx signs each input of tx with the private key of the address of output it spends.
+func signTx(tx *Transaction, prevOuts []*wire.TxOut, addrs []string, getKey coin.GetPrivKey) error {
+	if len(prevOuts) != len(tx.TxIn) || len(addrs) != len(tx.TxIn) {
+		return errors.New("the spent outputs don't match the inputs")
+	}
+
+	for i := range tx.TxIn {
+		key, err := getKey(addrs[i])
+		if err != nil {
+			return err
+		}
+
+		if err := signInput(tx, i, key, prevOuts[i].PkScript, prevOuts[i].Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// vsize returns the virtual size of the transaction in vbytes, the witness data count 1/4.
+func (tx *Transaction) vsize() (int, error) {
+	d, err := tx.Serialize()
+	if err != nil {
+		return 0, err
+	}
+	weight := tx.MsgTx.SerializeSize()*3 + len(d)
+	return (weight + 3) / 4, nil
+}
@@ -0,0 +1,155 @@
+package bitcoin_interface
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateTxInRBF(t *testing.T) {
+	prevHash, _ := chainhash.NewHashFromStr("ef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a")
+	in := createTxIn(wire.NewOutPoint(prevHash, 0))
+	assert.Equal(t, uint32(RBFSequence), in.Sequence)
+	// BIP125 signals replaceability with sequence lower than 0xfffffffe.
+	assert.True(t, in.Sequence < wire.MaxTxInSequenceNum-1)
+}
+
+// makeReplaceableTx signs the transaction spending a legacy output of 60000 and a segwit output of
+// 40000 satoshis, paying 50000 to the receiver, and 40000 to the change, the fee is 10000.
+func makeReplaceableTx(t *testing.T) *replaceableTx {
+	keys := map[string]string{}
+	newKey := func(segwit bool) (string, []byte) {
+		priv, err := btcec.NewPrivateKey(btcec.S256())
+		assert.Nil(t, err)
+		wif, err := btcutil.NewWIF(priv, &chaincfg.MainNetParams, true)
+		assert.Nil(t, err)
+		pubkey := priv.PubKey().SerializeCompressed()
+
+		var addr string
+		if segwit {
+			addr, err = SegwitAddressFromPubkey(pubkey)
+		} else {
+			var a *btcutil.AddressPubKeyHash
+			a, err = btcutil.NewAddressPubKeyHash(btcutil.Hash160(pubkey), &chaincfg.MainNetParams)
+			addr = a.EncodeAddress()
+		}
+		assert.Nil(t, err)
+		keys[addr] = wif.String()
+
+		script, err := payToAddrScript(addr)
+		assert.Nil(t, err)
+		return addr, script
+	}
+
+	legacyAddr, legacyScript := newKey(false)
+	segwitAddr, segwitScript := newKey(true)
+	prevOuts := []*wire.TxOut{wire.NewTxOut(60000, legacyScript), wire.NewTxOut(40000, segwitScript)}
+
+	tx := &Transaction{MsgTx: *wire.NewMsgTx()}
+	prevHash, _ := chainhash.NewHashFromStr("ef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a")
+	for i := range prevOuts {
+		tx.AddTxIn(createTxIn(wire.NewOutPoint(prevHash, uint32(i))))
+	}
+	for _, o := range []TxOut{{Addr: "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", Value: 50000}, {Addr: legacyAddr, Value: 40000}} {
+		out, err := createTxOut(o.Value, o.Addr)
+		assert.Nil(t, err)
+		tx.AddTxOut(out)
+	}
+
+	getKey := func(addr string) (string, error) {
+		if k, ok := keys[addr]; ok {
+			return k, nil
+		}
+		return "", fmt.Errorf("no private key of %s", addr)
+	}
+	addrs := []string{legacyAddr, segwitAddr}
+	assert.Nil(t, signTx(tx, prevOuts, addrs, getKey))
+	return &replaceableTx{tx: tx, prevOuts: prevOuts, addrs: addrs, getKey: getKey}
+}
+
+func TestReplaceTx(t *testing.T) {
+	old := makeReplaceableTx(t)
+	txid := old.tx.TxHash().String()
+	replaceableTxs.add(txid, old)
+	defer replaceableTxs.remove(txid)
+
+	vsize, err := old.tx.vsize()
+	assert.Nil(t, err)
+
+	rtx, err := replaceTx(txid, 100)
+	assert.Nil(t, err)
+	tx := rtx.tx
+
+	// the replacement spends the same inputs, and still signals replaceability.
+	assert.Equal(t, len(old.tx.TxIn), len(tx.TxIn))
+	for i, in := range tx.TxIn {
+		assert.Equal(t, old.tx.TxIn[i].PreviousOutPoint, in.PreviousOutPoint)
+		assert.Equal(t, uint32(RBFSequence), in.Sequence)
+	}
+	assert.NotEqual(t, txid, tx.TxHash().String())
+
+	// the fee increase is paid by the change.
+	assert.Equal(t, int64(50000), tx.TxOut[0].Value)
+	assert.Equal(t, int64(100000-50000)-int64(vsize)*100, tx.TxOut[1].Value)
+	assert.Equal(t, int64(40000), old.tx.TxOut[1].Value)
+
+	// the legacy input is signed.
+	vm, err := txscript.NewEngine(old.prevOuts[0].PkScript, &tx.MsgTx, 0, txscript.StandardVerifyFlags, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, vm.Execute())
+
+	// the witness input is signed with the new outputs.
+	w := tx.Witness[1][0]
+	sig, err := btcec.ParseDERSignature(w[:len(w)-1], btcec.S256())
+	assert.Nil(t, err)
+	pubkey, err := btcec.ParsePubKey(tx.Witness[1][1], btcec.S256())
+	assert.Nil(t, err)
+	hash := witnessSigHash(&tx.MsgTx, 1, old.prevOuts[1].PkScript, old.prevOuts[1].Value, txscript.SigHashAll)
+	assert.True(t, sig.Verify(hash, pubkey))
+
+	// the fee must be increased.
+	_, err = replaceTx(txid, 10)
+	assert.NotNil(t, err)
+
+	// the change can't be left below dust.
+	_, err = replaceTx(txid, 50000/uint64(vsize)+1)
+	assert.NotNil(t, err)
+
+	_, err = replaceTx("unknown", 100)
+	assert.NotNil(t, err)
+
+	// the transaction without change can't be bumped.
+	noChg := makeReplaceableTx(t)
+	noChg.tx.TxOut = noChg.tx.TxOut[:1]
+	replaceableTxs.add("nochange", noChg)
+	defer replaceableTxs.remove("nochange")
+	_, err = replaceTx("nochange", 100)
+	assert.NotNil(t, err)
+}
+
+func TestTxTracker(t *testing.T) {
+	tt := txTracker{txs: make(map[string]*replaceableTx)}
+	defer func(n int) { MaxReplaceableTxs = n }(MaxReplaceableTxs)
+	MaxReplaceableTxs = 2
+
+	for _, id := range []string{"a", "b", "c"} {
+		tt.add(id, &replaceableTx{})
+	}
+	_, ok := tt.get("a")
+	assert.False(t, ok)
+	_, ok = tt.get("c")
+	assert.True(t, ok)
+
+	tt.remove("b")
+	tt.add("d", &replaceableTx{})
+	_, ok = tt.get("c")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"c", "d"}, tt.order)
+}
@@ -7,17 +7,15 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"reflect"
 
-	"strings"
 
-	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/skycoin/skycoin-exchange/src/coin/insight"
 )
 
 type BlockChainInfoTxOut struct {
@@ -31,12 +29,9 @@ type blockChainInfoTx struct {
 	Outputs []BlockChainInfoTxOut `json:"out"`
 }
 
-type sendTxJson struct {
-	RawTx string `json:"rawtx"`
-}
-
 type Transaction struct {
 	wire.MsgTx
+	Witness [][][]byte // witness stack of each input, empty if the tx spends no segwit output.
 }
 
 // NewTransaction create transaction,
@@ -77,29 +72,38 @@ func NewTransaction(utxos interface{}, outAddrs []TxOut) (*Transaction, error) {
 		tx.AddTxIn(txin)
 	}
 
-	if len(outAddrs) > 2 {
-		return nil, errors.New("out address more than 2")
+	if len(outAddrs) == 0 {
+		return nil, errors.New("no output address")
 	}
 
 	for _, out := range outAddrs {
-		addr, err := btcutil.DecodeAddress(out.Addr, &chaincfg.MainNetParams)
+		txout, err := createTxOut(out.Value, out.Addr)
 		if err != nil {
-			return nil, fmt.Errorf("decode address %s, faild, %s", out.Addr, err)
+			return nil, err
 		}
-		txout := createTxOut(out.Value, addr)
 		tx.AddTxOut(txout)
 	}
 
 	// sign the transaction
+	t := &Transaction{MsgTx: *tx}
 	for i, r := range ret {
 		utxo := r.(UtxoWithkey)
-		sig, err := signRawTx(&Transaction{*tx}, i, utxo.GetPrivKey(), oldTxOuts[i].PkScript)
-		if err != nil {
+		if err := signInput(t, i, utxo.GetPrivKey(), oldTxOuts[i].PkScript, oldTxOuts[i].Value); err != nil {
 			return nil, err
 		}
-		tx.TxIn[i].SignatureScript = sig
 	}
-	return &Transaction{*tx}, nil
+	return t, nil
+}
+
+// BroadcastAPIs the insight apis the transactions are broadcast through, tried in order until
+// one accepts the transaction, empty uses the insight api of the network.
+var BroadcastAPIs []string
+
+func broadcastAPIs() []string {
+	if len(BroadcastAPIs) > 0 {
+		return BroadcastAPIs
+	}
+	return []string{insightAPI()}
 }
 
 // BroadcastTx tries to send the transaction using an api that will broadcast
@@ -108,39 +112,10 @@ func NewTransaction(utxos interface{}, outAddrs []TxOut) (*Transaction, error) {
 // The transaction is broadcast to the bitcoin network using this API:
 //    https://github.com/bitpay/insight-api
 //
+// The apis in BroadcastAPIs are tried in order, the next one is used if the
+// api is down, the transaction rejected by the node is not sent again.
 func BroadcastTx(rawtx string) (string, error) {
-	url := "https://insight.bitpay.com/api/tx/send"
-	contentType := "application/json"
-
-	// fmt.Printf("Sending transaction to: %s\n", url)
-	sendTxJson := &sendTxJson{RawTx: rawtx}
-	j, err := json.Marshal(sendTxJson)
-	if err != nil {
-		return "", fmt.Errorf("Broadcasting the tx failed: %v", err)
-	}
-	buf := bytes.NewBuffer(j)
-	resp, err := http.Post(url, contentType, buf)
-	if err != nil {
-		return "", fmt.Errorf("Broadcasting the tx failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-	v := struct {
-		Txid string `json:"txid"`
-	}{}
-
-	if strings.Contains(string(b), "Code:") {
-		return "", fmt.Errorf("Broadcast tx failed, %v", string(b))
-	}
-
-	if err := json.Unmarshal(b, &v); err != nil {
-		return "", fmt.Errorf("Broadcasting tx failed, unmarshal result failed, err:%v", err)
-	}
-	return v.Txid, nil
+	return insight.BroadcastTx(broadcastAPIs(), rawtx)
 }
 
 // signRawTransaction requires a transaction, a private key, and the bytes of the raw
@@ -170,7 +145,7 @@ func signRawTx(tx *Transaction, index int, wifPrivKey string, scriptPubKey []byt
 // Uses the txid of the target funding transaction and asks blockchain.info's
 // api for information (in json) related to that transaction.
 func lookupTxid(hash *chainhash.Hash) (*blockChainInfoTx, error) {
-	url := "https://blockchain.info/rawtx/" + hash.String()
+	url := blkChnAPI() + "/rawtx/" + hash.String()
 	resp, err := http.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("Tx Lookup failed: %v", err)
@@ -222,27 +197,36 @@ func getFundingParams(rawtx *blockChainInfoTx, vout uint32) (*wire.TxOut, *wire.
 }
 
 // createTxIn pulls the outpoint out of the funding TxOut and uses it as a reference
-// for the txin that will be placed in a new transaction.
+// for the txin that will be placed in a new transaction, the txin signals replace-by-fee.
 func createTxIn(outpoint *wire.OutPoint) *wire.TxIn {
 	// The second arg is the txin's signature script, which we are leaving empty
 	// until the entire transaction is ready.
 	txin := wire.NewTxIn(outpoint, []byte{})
+	txin.Sequence = RBFSequence
 	return txin
 }
 
-// createTxOut generates a TxOut that can be added to a transaction.
-func createTxOut(outCoins uint64, addr btcutil.Address) *wire.TxOut {
+// createTxOut generates a TxOut that can be added to a transaction, the addr can be
+// legacy or segwit address.
+func createTxOut(outCoins uint64, addr string) (*wire.TxOut, error) {
 	// Take the address and generate a PubKeyScript out of it
-	script, err := txscript.PayToAddrScript(addr)
+	script, err := payToAddrScript(addr)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	txout := wire.NewTxOut(int64(outCoins), script)
-	return txout
+	return wire.NewTxOut(int64(outCoins), script), nil
 }
 
+// Serialize encodes the transaction, the witness format is used if any input has witness.
 func (tx *Transaction) Serialize() ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if tx.hasWitness() {
+		if err := tx.serializeWitness(buf); err != nil {
+			return []byte{}, err
+		}
+		return buf.Bytes(), nil
+	}
+
 	if err := tx.MsgTx.Serialize(buf); err != nil {
 		return []byte{}, err
 	}
@@ -2,9 +2,16 @@ package bitcoin_interface
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
+	"github.com/skycoin/skycoin-exchange/src/coin"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -69,9 +76,64 @@ type TData struct {
 // }
 
 func TestGetTxVerbose(t *testing.T) {
-	tx, err := getTxVerboseExplr("69be3a3b98541e609f5a4935f94c92012d2b3e3437e9508770ba2257f532142f")
+	tx, err := insightNet.GetTxVerbose("69be3a3b98541e609f5a4935f94c92012d2b3e3437e9508770ba2257f532142f")
 	assert.Nil(t, err)
 	v, err := json.MarshalIndent(tx, "", " ")
 	assert.Nil(t, err)
 	fmt.Println(string(v))
 }
+
+// newBroadcastMock mocks the insight api, which responds the status and body to /api/tx/send.
+func newBroadcastMock(status int, body string) (*httptest.Server, *uint64) {
+	var requests uint64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint64(&requests, 1)
+		d, _ := ioutil.ReadAll(r.Body)
+		if r.URL.Path != "/api/tx/send" || string(d) != `{"rawtx":"0100"}` {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	return srv, &requests
+}
+
+func TestBroadcastTxFailover(t *testing.T) {
+	down, _ := newBroadcastMock(http.StatusOK, "")
+	down.Close()
+	broken, brokenReqs := newBroadcastMock(http.StatusServiceUnavailable, "maintenance")
+	defer broken.Close()
+	good, goodReqs := newBroadcastMock(http.StatusOK, `{"txid":"abc"}`)
+	defer good.Close()
+	next, nextReqs := newBroadcastMock(http.StatusOK, `{"txid":"abc"}`)
+	defer next.Close()
+	defer func() { BroadcastAPIs = nil }()
+
+	// the first api is down and the second is unavailable, the third accepts it.
+	BroadcastAPIs = []string{down.URL + "/api", broken.URL + "/api", good.URL + "/api", next.URL + "/api"}
+	txid, err := BroadcastTx("0100")
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", txid)
+	assert.Equal(t, uint64(1), atomic.LoadUint64(brokenReqs))
+	assert.Equal(t, uint64(1), atomic.LoadUint64(goodReqs))
+	assert.Equal(t, uint64(0), atomic.LoadUint64(nextReqs))
+
+	// all fail, the error is retryable.
+	BroadcastAPIs = []string{down.URL + "/api", broken.URL + "/api"}
+	_, err = BroadcastTx("0100")
+	assert.NotNil(t, err)
+	assert.True(t, coin.IsRetryable(err))
+
+	// the rejected transaction is not sent to the next api.
+	rejecting, _ := newBroadcastMock(http.StatusBadRequest, "258: txn-mempool-conflict. Code:-26")
+	defer rejecting.Close()
+	BroadcastAPIs = []string{rejecting.URL + "/api", next.URL + "/api"}
+	_, err = BroadcastTx("0100")
+	assert.True(t, errors.Is(err, coin.ErrTxRejected))
+	assert.Equal(t, uint64(0), atomic.LoadUint64(nextReqs))
+
+	// the default api of the network is used if none is set.
+	BroadcastAPIs = nil
+	assert.Equal(t, []string{mainInsightAPI}, broadcastAPIs())
+}
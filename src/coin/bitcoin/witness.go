@@ -0,0 +1,249 @@
+package bitcoin_interface
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// the wire package predates segwit, the witness serialization and signature
+// hash of BIP141 and BIP143 are implemented here.
+
+const (
+	witnessMarker = 0x00
+	witnessFlag   = 0x01
+)
+
+// payToAddrScript creates the scriptPubkey that pays to the legacy or segwit address.
+func payToAddrScript(addr string) ([]byte, error) {
+	if isSegwitAddress(addr) {
+		version, program, err := DecodeSegwitAddress(segwitHRP(), addr)
+		if err != nil {
+			return nil, err
+		}
+		// only witness version 0 is supported, which is OP_0.
+		script := []byte{txscript.OP_0 + version, byte(len(program))}
+		return append(script, program...), nil
+	}
+
+	a, err := btcutil.DecodeAddress(addr, NetParams())
+	if err != nil {
+		return nil, fmt.Errorf("decode address %s failed, %v", addr, err)
+	}
+	return txscript.PayToAddrScript(a)
+}
+
+// isWitnessPubKeyHash checks if the scriptPubkey is P2WPKH.
+func isWitnessPubKeyHash(script []byte) bool {
+	return len(script) == 22 && script[0] == txscript.OP_0 && script[1] == txscript.OP_DATA_20
+}
+
+// hasWitness checks if any input of the transaction has witness.
+func (tx *Transaction) hasWitness() bool {
+	for _, w := range tx.Witness {
+		if len(w) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// signInput signs the input of index, the input spends the output of pkScript with amount
+// satoshis, the signature is put into witness if the output is P2WPKH.
+func signInput(tx *Transaction, index int, wifPrivKey string, pkScript []byte, amount int64) error {
+	if !isWitnessPubKeyHash(pkScript) {
+		sig, err := signRawTx(tx, index, wifPrivKey, pkScript)
+		if err != nil {
+			return err
+		}
+		tx.TxIn[index].SignatureScript = sig
+		return nil
+	}
+
+	wif, err := btcutil.DecodeWIF(wifPrivKey)
+	if err != nil {
+		return err
+	}
+
+	pubkey := wif.PrivKey.PubKey().SerializeCompressed()
+	if !bytes.Equal(btcutil.Hash160(pubkey), pkScript[2:]) {
+		return errors.New("private key does not match the witness program")
+	}
+
+	hash := witnessSigHash(&tx.MsgTx, index, pkScript, amount, txscript.SigHashAll)
+	sig, err := wif.PrivKey.Sign(hash)
+	if err != nil {
+		return err
+	}
+
+	if len(tx.Witness) != len(tx.TxIn) {
+		w := make([][][]byte, len(tx.TxIn))
+		copy(w, tx.Witness)
+		tx.Witness = w
+	}
+	tx.TxIn[index].SignatureScript = []byte{}
+	tx.Witness[index] = [][]byte{append(sig.Serialize(), byte(txscript.SigHashAll)), pubkey}
+	return nil
+}
+
+// witnessSigHash computes the BIP143 signature hash of the P2WPKH input.
+func witnessSigHash(tx *wire.MsgTx, index int, pkScript []byte, amount int64, hashType txscript.SigHashType) []byte {
+	var prevouts, sequences, outputs bytes.Buffer
+	for _, in := range tx.TxIn {
+		prevouts.Write(in.PreviousOutPoint.Hash[:])
+		binary.Write(&prevouts, binary.LittleEndian, in.PreviousOutPoint.Index)
+		binary.Write(&sequences, binary.LittleEndian, in.Sequence)
+	}
+	for _, out := range tx.TxOut {
+		binary.Write(&outputs, binary.LittleEndian, out.Value)
+		wire.WriteVarBytes(&outputs, 0, out.PkScript)
+	}
+
+	in := tx.TxIn[index]
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, tx.Version)
+	b.Write(chainhash.DoubleHashB(prevouts.Bytes()))
+	b.Write(chainhash.DoubleHashB(sequences.Bytes()))
+	b.Write(in.PreviousOutPoint.Hash[:])
+	binary.Write(&b, binary.LittleEndian, in.PreviousOutPoint.Index)
+
+	// the scriptCode of P2WPKH is the P2PKH script of the pubkey hash.
+	b.Write([]byte{0x19, txscript.OP_DUP, txscript.OP_HASH160, txscript.OP_DATA_20})
+	b.Write(pkScript[2:])
+	b.Write([]byte{txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG})
+
+	binary.Write(&b, binary.LittleEndian, amount)
+	binary.Write(&b, binary.LittleEndian, in.Sequence)
+	b.Write(chainhash.DoubleHashB(outputs.Bytes()))
+	binary.Write(&b, binary.LittleEndian, tx.LockTime)
+	binary.Write(&b, binary.LittleEndian, uint32(hashType))
+	return chainhash.DoubleHashB(b.Bytes())
+}
+
+// serializeWitness writes the transaction in BIP144 format.
+func (tx *Transaction) serializeWitness(w io.Writer) error {
+	if len(tx.Witness) != len(tx.TxIn) {
+		return errors.New("witness count does not match the inputs")
+	}
+
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, tx.Version)
+	b.Write([]byte{witnessMarker, witnessFlag})
+	wire.WriteVarInt(&b, 0, uint64(len(tx.TxIn)))
+	for _, in := range tx.TxIn {
+		b.Write(in.PreviousOutPoint.Hash[:])
+		binary.Write(&b, binary.LittleEndian, in.PreviousOutPoint.Index)
+		wire.WriteVarBytes(&b, 0, in.SignatureScript)
+		binary.Write(&b, binary.LittleEndian, in.Sequence)
+	}
+	wire.WriteVarInt(&b, 0, uint64(len(tx.TxOut)))
+	for _, out := range tx.TxOut {
+		binary.Write(&b, binary.LittleEndian, out.Value)
+		wire.WriteVarBytes(&b, 0, out.PkScript)
+	}
+	for _, items := range tx.Witness {
+		wire.WriteVarInt(&b, 0, uint64(len(items)))
+		for _, item := range items {
+			wire.WriteVarBytes(&b, 0, item)
+		}
+	}
+	binary.Write(&b, binary.LittleEndian, tx.LockTime)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// Deserialize decodes the transaction in legacy or BIP144 witness format.
+func (tx *Transaction) Deserialize(r io.Reader) error {
+	d, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	tx.Witness = nil
+	if len(d) < 6 || d[4] != witnessMarker || d[5] != witnessFlag {
+		return tx.MsgTx.Deserialize(bytes.NewReader(d))
+	}
+	return tx.deserializeWitness(bytes.NewReader(d))
+}
+
+func (tx *Transaction) deserializeWitness(r io.Reader) error {
+	msg := wire.NewMsgTx()
+	if err := binary.Read(r, binary.LittleEndian, &msg.Version); err != nil {
+		return err
+	}
+
+	// skip the marker and flag.
+	if _, err := io.ReadFull(r, make([]byte, 2)); err != nil {
+		return err
+	}
+
+	nIn, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < nIn; i++ {
+		var op wire.OutPoint
+		if _, err := io.ReadFull(r, op.Hash[:]); err != nil {
+			return err
+		}
+		if err := binary.Read(r, binary.LittleEndian, &op.Index); err != nil {
+			return err
+		}
+		script, err := wire.ReadVarBytes(r, 0, wire.MaxMessagePayload, "signature script")
+		if err != nil {
+			return err
+		}
+		in := wire.NewTxIn(&op, script)
+		if err := binary.Read(r, binary.LittleEndian, &in.Sequence); err != nil {
+			return err
+		}
+		msg.AddTxIn(in)
+	}
+
+	nOut, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < nOut; i++ {
+		var value int64
+		if err := binary.Read(r, binary.LittleEndian, &value); err != nil {
+			return err
+		}
+		script, err := wire.ReadVarBytes(r, 0, wire.MaxMessagePayload, "pk script")
+		if err != nil {
+			return err
+		}
+		msg.AddTxOut(wire.NewTxOut(value, script))
+	}
+
+	witness := make([][][]byte, nIn)
+	for i := range witness {
+		n, err := wire.ReadVarInt(r, 0)
+		if err != nil {
+			return err
+		}
+		for j := uint64(0); j < n; j++ {
+			item, err := wire.ReadVarBytes(r, 0, wire.MaxMessagePayload, "witness item")
+			if err != nil {
+				return err
+			}
+			witness[i] = append(witness[i], item)
+		}
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &msg.LockTime); err != nil {
+		return err
+	}
+
+	tx.MsgTx = *msg
+	tx.Witness = witness
+	return nil
+}
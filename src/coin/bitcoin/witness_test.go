@@ -0,0 +1,108 @@
+package bitcoin_interface
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayToSegwitAddr(t *testing.T) {
+	script, err := payToAddrScript("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+	assert.Nil(t, err)
+	assert.Equal(t, "0014751e76e8199196d454941c45d1b3a323f1433bd6", hex.EncodeToString(script))
+	assert.True(t, isWitnessPubKeyHash(script))
+
+	script, err = payToAddrScript("1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")
+	assert.Nil(t, err)
+	assert.False(t, isWitnessPubKeyHash(script))
+}
+
+func TestSignWitnessInput(t *testing.T) {
+	priv, err := btcec.NewPrivateKey(btcec.S256())
+	assert.Nil(t, err)
+	wif, err := btcutil.NewWIF(priv, &chaincfg.MainNetParams, true)
+	assert.Nil(t, err)
+
+	pubkey := priv.PubKey().SerializeCompressed()
+	addr, err := SegwitAddressFromPubkey(pubkey)
+	assert.Nil(t, err)
+	pkScript, err := payToAddrScript(addr)
+	assert.Nil(t, err)
+
+	tx := Transaction{MsgTx: *wire.NewMsgTx()}
+	prevHash, _ := chainhash.NewHashFromStr("ef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a")
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(prevHash, 1), nil))
+	out, err := createTxOut(90000, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")
+	assert.Nil(t, err)
+	tx.AddTxOut(out)
+
+	assert.Nil(t, signInput(&tx, 0, wif.String(), pkScript, 100000))
+	assert.Empty(t, tx.TxIn[0].SignatureScript)
+	assert.Equal(t, 2, len(tx.Witness[0]))
+	assert.Equal(t, pubkey, tx.Witness[0][1])
+
+	// verify the signature against the BIP143 signature hash.
+	w := tx.Witness[0][0]
+	assert.Equal(t, byte(txscript.SigHashAll), w[len(w)-1])
+	sig, err := btcec.ParseDERSignature(w[:len(w)-1], btcec.S256())
+	assert.Nil(t, err)
+	hash := witnessSigHash(&tx.MsgTx, 0, pkScript, 100000, txscript.SigHashAll)
+	assert.True(t, sig.Verify(hash, priv.PubKey()))
+
+	// the signature commits to the amount.
+	assert.False(t, sig.Verify(witnessSigHash(&tx.MsgTx, 0, pkScript, 100001, txscript.SigHashAll), priv.PubKey()))
+
+	// serialized in witness format, and can be decoded back.
+	d, err := tx.Serialize()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{witnessMarker, witnessFlag}, d[4:6])
+
+	var tx1 Transaction
+	assert.Nil(t, tx1.Deserialize(bytes.NewReader(d)))
+	assert.Equal(t, tx.Witness, tx1.Witness)
+	assert.Equal(t, tx.TxHash(), tx1.TxHash())
+
+	// the private key must match the witness program.
+	other, err := btcec.NewPrivateKey(btcec.S256())
+	assert.Nil(t, err)
+	otherWif, err := btcutil.NewWIF(other, &chaincfg.MainNetParams, true)
+	assert.Nil(t, err)
+	assert.NotNil(t, signInput(&tx, 0, otherWif.String(), pkScript, 100000))
+}
+
+// the native P2WPKH example of BIP143, the first input spends a P2PK output
+// and is signed in the legacy way, the second one is the P2WPKH input.
+func TestSignWitnessInputBIP143(t *testing.T) {
+	unsigned := "0100000002fff7f7881a8099afa6940d42d1e7f6362bec38171ea3edf433541db4e4ad969f0000000000eeffffffef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a0100000000ffffffff02202cb206000000001976a9148280b37df378db99f66f85c95a783a76ac7a6d5988ac9093510d000000001976a9143bde42dbee7e4dbe6a21b2d50ce2f0167faa815988ac11000000"
+	p2pkSig := "4830450221008b9d1dc26ba6a9cb62127b02742fa9d754cd3bebf337f7a55d114c8e5cdd30be022040529b194ba3f9281a99f2b1c0a19c0489bc22ede944ccf4ecbab4cc618ef3ed01"
+	signed := "01000000000102fff7f7881a8099afa6940d42d1e7f6362bec38171ea3edf433541db4e4ad969f00000000494830450221008b9d1dc26ba6a9cb62127b02742fa9d754cd3bebf337f7a55d114c8e5cdd30be022040529b194ba3f9281a99f2b1c0a19c0489bc22ede944ccf4ecbab4cc618ef3ed01eeffffffef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a0100000000ffffffff02202cb206000000001976a9148280b37df378db99f66f85c95a783a76ac7a6d5988ac9093510d000000001976a9143bde42dbee7e4dbe6a21b2d50ce2f0167faa815988ac000247304402203609e17b84f6a7d30c80bfa610b5b4542f32a8a0d5447a12fb1366d7f01cc44a0220573a954c4518331561406f90300e8f3358f51928d43c212a8caed02de67eebee0121025476c2e83188368da1ff3e292e7acafcdb3566bb0ad253f62fc70f07aeee635711000000"
+
+	d, _ := hex.DecodeString(unsigned)
+	var tx Transaction
+	assert.Nil(t, tx.Deserialize(bytes.NewReader(d)))
+	assert.False(t, tx.hasWitness())
+
+	pkScript, _ := hex.DecodeString("00141d0f172a0ecb48aee1be1f2687d2963ae33f71a1")
+	assert.True(t, isWitnessPubKeyHash(pkScript))
+	hash := witnessSigHash(&tx.MsgTx, 1, pkScript, 600000000, txscript.SigHashAll)
+	assert.Equal(t, "c37af31116d1b27caf68aae9e3ac82f1477929014d5b917657d0eb49478cb670", hex.EncodeToString(hash))
+
+	seckey, _ := hex.DecodeString("619c335025c7f4012e556c2a58b2506e30b8511b53ade95ea316fd8c3286feb9")
+	priv, _ := btcec.PrivKeyFromBytes(btcec.S256(), seckey)
+	wif, err := btcutil.NewWIF(priv, &chaincfg.MainNetParams, true)
+	assert.Nil(t, err)
+	assert.Nil(t, signInput(&tx, 1, wif.String(), pkScript, 600000000))
+
+	tx.TxIn[0].SignatureScript, _ = hex.DecodeString(p2pkSig)
+	d, err = tx.Serialize()
+	assert.Nil(t, err)
+	assert.Equal(t, signed, hex.EncodeToString(d))
+}
@@ -0,0 +1,62 @@
+package coin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrTxRejected the transaction is rejected by the node or blockchain api, like double
+// spending or invalid signature, broadcasting it again won't succeed.
+var ErrTxRejected = errors.New("transaction rejected")
+
+// IsRetryable checks if the broadcast failure is transient, like network errors or
+// unavailable nodes, the rejected transactions are not retryable.
+func IsRetryable(err error) bool {
+	return err != nil && !errors.Is(err, ErrTxRejected)
+}
+
+// BroadcastTx injects the rawtx through the tx handler, the transient failures are retried
+// up to maxRetries times, the wait before each retry starts from backoff and doubles every
+// time. The rejected transaction fails fast, and the last error is returned if all fail.
+func BroadcastTx(h TxHandler, rawtx string, maxRetries int, backoff time.Duration) (string, error) {
+	for i := 0; ; i++ {
+		txid, err := h.InjectTx(rawtx)
+		if err == nil {
+			return txid, nil
+		}
+
+		if i >= maxRetries || !IsRetryable(err) {
+			return "", err
+		}
+		time.Sleep(backoff << uint(i))
+	}
+}
+
+// Failover sends the transaction to the broadcast apis in order with send, until one of them
+// accepts it. The api down or responding garbage is skipped, the rejected transaction fails
+// fast since the other apis will reject it too. The errors of all the apis are returned if
+// none accepts it, the last one is wrapped, so IsRetryable still works on the result.
+func Failover(apis []string, send func(api string) (string, error)) (string, error) {
+	if len(apis) == 0 {
+		return "", errors.New("no broadcast api")
+	}
+
+	var errs []string
+	for i, api := range apis {
+		txid, err := send(api)
+		if err == nil {
+			return txid, nil
+		}
+
+		if !IsRetryable(err) || i == len(apis)-1 {
+			if len(errs) == 0 {
+				return "", err
+			}
+			return "", fmt.Errorf("%s; %s: %w", strings.Join(errs, "; "), api, err)
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", api, err))
+	}
+	panic("unreachable")
+}
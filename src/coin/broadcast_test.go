@@ -0,0 +1,94 @@
+package coin
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTxHandler fails the first failures broadcasts with err.
+type fakeTxHandler struct {
+	TxHandler
+	failures int
+	err      error
+	calls    int
+}
+
+func (h *fakeTxHandler) InjectTx(rawtx string) (string, error) {
+	h.calls++
+	if h.calls <= h.failures {
+		return "", h.err
+	}
+	return "txid", nil
+}
+
+func TestBroadcastTx(t *testing.T) {
+	// fails twice then succeeds.
+	h := &fakeTxHandler{failures: 2, err: errors.New("connection refused")}
+	start := time.Now()
+	txid, err := BroadcastTx(h, "rawtx", 3, 10*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, "txid", txid)
+	assert.Equal(t, 3, h.calls)
+	// waits 10ms and 20ms before the retries.
+	assert.True(t, time.Since(start) >= 30*time.Millisecond)
+
+	// gives up after max retries.
+	h = &fakeTxHandler{failures: 5, err: errors.New("connection refused")}
+	_, err = BroadcastTx(h, "rawtx", 2, time.Millisecond)
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, 3, h.calls)
+
+	// no retry.
+	h = &fakeTxHandler{failures: 1, err: errors.New("connection refused")}
+	_, err = BroadcastTx(h, "rawtx", 0, time.Millisecond)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, h.calls)
+}
+
+func TestBroadcastTxRejected(t *testing.T) {
+	h := &fakeTxHandler{failures: 1, err: fmt.Errorf("%w: double spend", ErrTxRejected)}
+	_, err := BroadcastTx(h, "rawtx", 3, time.Millisecond)
+	assert.True(t, errors.Is(err, ErrTxRejected))
+	assert.Equal(t, 1, h.calls)
+
+	assert.False(t, IsRetryable(nil))
+	assert.False(t, IsRetryable(err))
+	assert.True(t, IsRetryable(errors.New("timeout")))
+}
+
+func TestFailover(t *testing.T) {
+	// the first api is down, the second accepts it, the third is not tried.
+	var tried []string
+	send := func(errs map[string]error) func(api string) (string, error) {
+		tried = nil
+		return func(api string) (string, error) {
+			tried = append(tried, api)
+			if err := errs[api]; err != nil {
+				return "", err
+			}
+			return "txid@" + api, nil
+		}
+	}
+	txid, err := Failover([]string{"a", "b", "c"}, send(map[string]error{"a": errors.New("connection refused")}))
+	assert.Nil(t, err)
+	assert.Equal(t, "txid@b", txid)
+	assert.Equal(t, []string{"a", "b"}, tried)
+
+	// the rejection is not sent to the next api.
+	_, err = Failover([]string{"a", "b"}, send(map[string]error{"a": fmt.Errorf("%w: double spend", ErrTxRejected)}))
+	assert.True(t, errors.Is(err, ErrTxRejected))
+	assert.Equal(t, []string{"a"}, tried)
+
+	// all down, the errors of all apis are reported, and it's still retryable.
+	_, err = Failover([]string{"a", "b"}, send(map[string]error{"a": errors.New("timeout"), "b": errors.New("503")}))
+	assert.EqualError(t, err, "a: timeout; b: 503")
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, []string{"a", "b"}, tried)
+
+	_, err = Failover(nil, send(nil))
+	assert.NotNil(t, err)
+}
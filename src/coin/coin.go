@@ -1,6 +1,24 @@
 package coin
 
-import "github.com/skycoin/skycoin-exchange/src/pp"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+)
+
+var (
+	// ErrUtxoTimeout no utxo can be chosen in the specific time.
+	ErrUtxoTimeout = errors.New("choose utxos time out")
+	// ErrInsufficientUtxo the utxo pool is drained before sufficient utxos are chosen.
+	ErrInsufficientUtxo = errors.New("insufficient utxos")
+	// ErrUtxoReserved the utxo is chosen by another transaction and not yet put back or spent.
+	ErrUtxoReserved = errors.New("utxo is reserved")
+	// HealthCheckTimeout max time that will be allowed in checking the backend of coin.
+	HealthCheckTimeout = 5 * time.Second
+)
 
 // Gateway coin gateway, once a coin implemented this interface,
 // then this coin can be registered in this exchange system.
@@ -8,11 +26,29 @@ type Gateway interface {
 	TxHandler
 	Symbol() string // return the coin symbol, SKY, BTC, MZC, etc.
 	Type() string   // return the coin type, skycoin, bitcoin, etc.
+	Decimals() int  // return the number of decimal places of the coin, the amounts are in the smallest unit.
 	// GetBalance interface for getting balance, the return value is an interface{}, cause
 	// the balance struct of skycoin and bitcoin are not the same.
 	GetBalance(addrs []string) (pp.Balance, error)
 	GetOutput(hash string) (interface{}, error)
 	GetUtxos(addrs []string) (interface{}, error)
+	// GetAddressTxs returns the transactions relevant to the addresses.
+	GetAddressTxs(addrs []string) ([]*pp.Tx, error)
+	// HealthCheck checks if the node or blockchain api that the coin depends on is available.
+	HealthCheck() (bool, error)
+}
+
+// DetailedBalance the balance split by confirmation status, the unconfirmed coins are
+// the outputs of the pending transactions, like the incoming deposits and change.
+type DetailedBalance struct {
+	Confirmed   uint64 `json:"confirmed"`
+	Unconfirmed uint64 `json:"unconfirmed"`
+	Total       uint64 `json:"total"`
+}
+
+// DetailedBalancer is implemented by the gateways which can tell the unconfirmed balance.
+type DetailedBalancer interface {
+	GetDetailedBalance(addrs []string) (DetailedBalance, error)
 }
 
 // TxHandler transaction handler interface for gateway.
@@ -23,6 +59,30 @@ type TxHandler interface {
 	CreateRawTx(txIns []TxIn, txOuts interface{}) (string, error)
 	SignRawTx(rawtx string, getKey GetPrivKey) (string, error)
 	ValidateTxid(txid string) bool
+	// EstimateFee estimates the fee of transaction with nInputs inputs and nOutputs outputs.
+	EstimateFee(nInputs, nOutputs int) (uint64, error)
+	// GetFeeEstimates returns the fee rates for the transaction to be confirmed within each of FeeTargets.
+	GetFeeEstimates() ([]FeeEstimate, error)
+}
+
+// FeeTargets the confirmation windows, in blocks, that the fee rates are estimated for.
+var FeeTargets = []int{1, 3, 6}
+
+// FeeEstimate the fee rate for the transaction to be confirmed within Blocks blocks, the
+// rate is in the smallest unit of the coin per (v)byte.
+type FeeEstimate struct {
+	Blocks  int    `json:"blocks"`
+	FeeRate uint64 `json:"fee_rate"`
+}
+
+// StaticFeeEstimates returns the same fee rate for all of FeeTargets, for the coins
+// whose fee doesn't depend on the mempool.
+func StaticFeeEstimates(rate uint64) []FeeEstimate {
+	fes := make([]FeeEstimate, len(FeeTargets))
+	for i, n := range FeeTargets {
+		fes[i] = FeeEstimate{Blocks: n, FeeRate: rate}
+	}
+	return fes
 }
 
 // TxIn records the tx vin info, txid is the prevous txid, Index is the out index in previous tx.
@@ -30,11 +90,51 @@ type TxIn struct {
 	Txid    string
 	Address string
 	Vout    uint32
+	Hours   uint64 // coin hours of the skycoin utxo.
 }
 
 // GetPrivKey is a callback func used for SignTx func to get relevant private key of specific address.
 type GetPrivKey func(addr string) (string, error)
 
+// Info the metadata of supported coin, MinAmount is in the smallest unit of the coin.
+type Info struct {
+	Symbol        string `json:"symbol"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Decimals      int    `json:"decimals"`
+	MinAmount     uint64 `json:"min_amount"`
+	AddressFormat string `json:"address_format"`
+}
+
+// UtxoStats the state of the utxo pool of the gateway, for diagnosing why choosing utxos times out.
+type UtxoStats struct {
+	Available  int    `json:"available"`   // utxos in the pool, including the ones being chosen.
+	Borrowed   int    `json:"borrowed"`    // utxos chosen and not yet put back or spent.
+	TotalValue uint64 `json:"total_value"` // value of the available utxos, in the unit of choosing utxos.
+	LastRefill int64  `json:"last_refill"` // unix time of the last check of new utxos, zero before the first one.
+}
+
+// Health the health status of the coin's backend.
+type Health struct {
+	Type    string `json:"type"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// CheckURL checks if the url responds 200 OK in HealthCheckTimeout.
+func CheckURL(url string) error {
+	c := http.Client{Timeout: HealthCheckTimeout}
+	rsp, err := c.Get(url)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responds %s", url, rsp.Status)
+	}
+	return nil
+}
+
 // AddressEntry represents the wallet address
 type AddressEntry struct {
 	Address string `json:"address"`
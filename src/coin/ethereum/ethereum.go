@@ -0,0 +1,94 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+var (
+	HideSeckey = false
+	// Type represents ethereum coin type
+	Type = "ethereum"
+	// ChainID the EIP-155 chain id that the transactions are signed for, 1 is the main network.
+	ChainID uint64 = 1
+	// GasLimit gas of the plain ether transfer.
+	GasLimit uint64 = 21000
+)
+
+// ValidateAddr checks the hex address of 20 bytes with 0x prefix, the address of mixed case
+// must have the EIP-55 checksum, the all lower or upper case address has no checksum.
+func ValidateAddr(addr string) error {
+	if !strings.HasPrefix(addr, "0x") || len(addr) != 42 {
+		return fmt.Errorf("invalid ethereum address %s, must be 20 bytes in hex with 0x prefix", addr)
+	}
+
+	b, err := hex.DecodeString(addr[2:])
+	if err != nil {
+		return fmt.Errorf("invalid ethereum address %s: %v", addr, err)
+	}
+
+	h := addr[2:]
+	if h == strings.ToLower(h) || h == strings.ToUpper(h) {
+		return nil
+	}
+
+	if ChecksumAddr(b) != addr {
+		return fmt.Errorf("invalid ethereum address %s, checksum mismatch", addr)
+	}
+	return nil
+}
+
+// ChecksumAddr returns the EIP-55 mixed case hex address, the letter is upper case
+// if the matching nibble of the keccak256 of the lower case address is 8 or greater.
+func ChecksumAddr(addr []byte) string {
+	h := hex.EncodeToString(addr)
+	hash := Keccak256([]byte(h))
+	b := []byte(h)
+	for i, c := range b {
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0f
+		}
+		if c >= 'a' && nibble >= 8 {
+			b[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(b)
+}
+
+// AddressFromPubkey returns the checksummed address of the public key, which is the last
+// 20 bytes of the keccak256 of the uncompressed key without its 0x04 prefix.
+func AddressFromPubkey(pub *btcec.PublicKey) string {
+	return ChecksumAddr(Keccak256(pub.SerializeUncompressed()[1:])[12:])
+}
+
+// GenerateAddresses generates ethereum addresses, the private keys are in hex.
+func GenerateAddresses(seed []byte, num int) (string, []coin.AddressEntry) {
+	sd, seckeys := cipher.GenerateDeterministicKeyPairsSeed(seed, num)
+	entries := make([]coin.AddressEntry, num)
+	for i, sec := range seckeys {
+		_, pub := btcec.PrivKeyFromBytes(btcec.S256(), sec[:])
+		entries[i].Address = AddressFromPubkey(pub)
+		entries[i].Public = hex.EncodeToString(pub.SerializeCompressed())
+		if !HideSeckey {
+			entries[i].Secret = hex.EncodeToString(sec[:])
+		}
+	}
+	return fmt.Sprintf("%2x", sd), entries
+}
+
+// privKeyFromHex decodes the private key of 32 bytes in hex.
+func privKeyFromHex(s string) (*btcec.PrivateKey, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != 32 {
+		return nil, errors.New("invalid private key, must be 32 bytes in hex")
+	}
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), b)
+	return key, nil
+}
@@ -0,0 +1,101 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeccak256(t *testing.T) {
+	assert.Equal(t, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", hex.EncodeToString(Keccak256()))
+	assert.Equal(t, "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45", hex.EncodeToString(Keccak256([]byte("abc"))))
+
+	// the data longer than the rate is absorbed in blocks.
+	long := []byte(strings.Repeat("a", 200))
+	assert.Equal(t, Keccak256(long), Keccak256(long[:100], long[100:]))
+}
+
+func TestValidateAddr(t *testing.T) {
+	valid := []string{
+		// EIP-55 test vectors.
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+		// all upper or lower case addresses have no checksum.
+		"0x52908400098527886E0F7030069857D2E4169EE7",
+		"0xde709f2102306220921060314715629080e2fb77",
+	}
+	for _, a := range valid {
+		assert.Nil(t, ValidateAddr(a), a)
+	}
+
+	invalid := []string{
+		"",
+		"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",     // no 0x prefix.
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAe",    // too short.
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAedd",  // too long.
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeg",   // not hex.
+		"0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed",   // checksum mismatch.
+		"0x1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH1BgGZ9tc", // bitcoin like.
+	}
+	for _, a := range invalid {
+		assert.NotNil(t, ValidateAddr(a), a)
+	}
+}
+
+func TestAddressFromPubkey(t *testing.T) {
+	b, _ := hex.DecodeString("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	_, pub := btcec.PrivKeyFromBytes(btcec.S256(), b)
+	assert.Equal(t, "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", AddressFromPubkey(pub))
+}
+
+func TestSignTransaction(t *testing.T) {
+	// the example of EIP-155.
+	tx := Transaction{
+		Nonce:    9,
+		GasPrice: 20000000000,
+		Gas:      21000,
+		To:       "0x3535353535353535353535353535353535353535",
+		Value:    1000000000000000000,
+	}
+	h, err := tx.sigHash(1)
+	assert.Nil(t, err)
+	assert.Equal(t, "daf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53", hex.EncodeToString(h))
+
+	assert.Nil(t, tx.Sign(1, strings.Repeat("46", 32)))
+	assert.Equal(t, uint64(37), tx.V)
+	d, err := tx.Serialize()
+	assert.Nil(t, err)
+	assert.Equal(t, "f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83", hex.EncodeToString(d))
+
+	// the signed transaction is decoded back.
+	dtx, err := DecodeTx(d)
+	assert.Nil(t, err)
+	assert.Equal(t, tx.Nonce, dtx.Nonce)
+	assert.Equal(t, tx.Value, dtx.Value)
+	assert.Equal(t, tx.V, dtx.V)
+	assert.True(t, dtx.IsSigned())
+}
+
+func TestSignWrongKey(t *testing.T) {
+	tx := Transaction{
+		Gas:   21000,
+		To:    "0x3535353535353535353535353535353535353535",
+		Value: 1,
+		From:  "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+	}
+
+	// the unsigned transaction keeps the sender.
+	d, err := tx.Serialize()
+	assert.Nil(t, err)
+	dtx, err := DecodeTx(d)
+	assert.Nil(t, err)
+	assert.Equal(t, tx.From, dtx.From)
+	assert.False(t, dtx.IsSigned())
+
+	assert.NotNil(t, dtx.Sign(1, strings.Repeat("46", 32)))
+}
@@ -0,0 +1,302 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/pp"
+)
+
+var (
+	// ErrNoOutputs is returned by the utxo methods, ethereum is account based.
+	ErrNoOutputs = errors.New("ethereum is account based, it has no outputs")
+	// ErrTxNotFound is returned when the node doesn't know the transaction.
+	ErrTxNotFound = errors.New("ethereum transaction not found")
+)
+
+// Gateway implements the coin.Gateway interface with the JSON-RPC api of ethereum node.
+// The amounts are in wei, which fit in uint64 up to about 18.4 ether. The transactions
+// are plain ether transfers from one address, whose nonces are managed by the gateway.
+type Gateway struct {
+	NodeAddr string
+	nonces   *NonceManager
+}
+
+// New creates the gateway of the ethereum node, which listens on nodeAddr like 127.0.0.1:8545.
+func New(nodeAddr string) *Gateway {
+	return &Gateway{
+		NodeAddr: nodeAddr,
+		nonces: NewNonceManager(func(addr string) (uint64, error) {
+			return callUint(nodeAddr, "eth_getTransactionCount", addr, "pending")
+		}),
+	}
+}
+
+// Symbol returns the ethereum symbol.
+func (eth *Gateway) Symbol() string {
+	return "ETH"
+}
+
+// Type returns the ethereum type name.
+func (eth *Gateway) Type() string {
+	return Type
+}
+
+// Decimals returns the decimal places of ether, the amounts are in wei.
+func (eth *Gateway) Decimals() int {
+	return 18
+}
+
+// ValidateAddr checks the address, see ValidateAddr.
+func (eth *Gateway) ValidateAddr(addr string) error {
+	return ValidateAddr(addr)
+}
+
+// GetBalance returns the total balance of the addresses in wei.
+func (eth *Gateway) GetBalance(addrs []string) (pp.Balance, error) {
+	var total uint64
+	for _, a := range addrs {
+		if err := ValidateAddr(a); err != nil {
+			return pp.Balance{}, err
+		}
+		b, err := callUint(eth.NodeAddr, "eth_getBalance", a, "latest")
+		if err != nil {
+			return pp.Balance{}, err
+		}
+		if b > math.MaxUint64-total {
+			return pp.Balance{}, errors.New("balance overflows uint64 wei")
+		}
+		total += b
+	}
+	return pp.Balance{Amount: pp.PtrUint64(total)}, nil
+}
+
+// GetOutput is not supported, ethereum has no outputs.
+func (eth *Gateway) GetOutput(hash string) (interface{}, error) {
+	return nil, ErrNoOutputs
+}
+
+// GetUtxos is not supported, ethereum has no outputs.
+func (eth *Gateway) GetUtxos(addrs []string) (interface{}, error) {
+	return nil, ErrNoOutputs
+}
+
+// GetAddressTxs is not supported, the node has no index of the address transactions.
+func (eth *Gateway) GetAddressTxs(addrs []string) ([]*pp.Tx, error) {
+	return nil, errors.New("ethereum node can't list the transactions of address")
+}
+
+// HealthCheck checks if the ethereum node is available.
+func (eth *Gateway) HealthCheck() (bool, error) {
+	if _, err := callUint(eth.NodeAddr, "eth_blockNumber"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetTx returns the transaction of txid, returns ErrTxNotFound if the node doesn't know it.
+func (eth *Gateway) GetTx(txid string) (*pp.Tx, error) {
+	var rt *struct {
+		Hash        string  `json:"hash"`
+		From        string  `json:"from"`
+		To          string  `json:"to"`
+		Value       string  `json:"value"`
+		Nonce       string  `json:"nonce"`
+		Gas         string  `json:"gas"`
+		GasPrice    string  `json:"gasPrice"`
+		BlockNumber *string `json:"blockNumber"`
+	}
+	if err := call(eth.NodeAddr, "eth_getTransactionByHash", &rt, txid); err != nil {
+		return nil, err
+	}
+	if rt == nil {
+		return nil, ErrTxNotFound
+	}
+
+	tx := pp.EthTx{
+		Txid: pp.PtrString(rt.Hash),
+		From: pp.PtrString(rt.From),
+		To:   pp.PtrString(rt.To),
+	}
+	var err error
+	for _, f := range []struct {
+		s string
+		v **uint64
+	}{
+		{rt.Value, &tx.Value},
+		{rt.Nonce, &tx.Nonce},
+		{rt.Gas, &tx.Gas},
+		{rt.GasPrice, &tx.GasPrice},
+	} {
+		if *f.v, err = ptrQuantity(f.s); err != nil {
+			return nil, err
+		}
+	}
+
+	// the pending transaction has no block.
+	if rt.BlockNumber != nil {
+		if tx.BlockNumber, err = ptrQuantity(*rt.BlockNumber); err != nil {
+			return nil, err
+		}
+	}
+	return &pp.Tx{Eth: &tx}, nil
+}
+
+// GetRawTx returns the signed transaction of txid in hex.
+func (eth *Gateway) GetRawTx(txid string) (string, error) {
+	var raw *string
+	if err := call(eth.NodeAddr, "eth_getRawTransactionByHash", &raw, txid); err != nil {
+		return "", err
+	}
+	if raw == nil || *raw == "0x" {
+		return "", ErrTxNotFound
+	}
+	return strings.TrimPrefix(*raw, "0x"), nil
+}
+
+// InjectTx broadcasts the signed transaction in hex, returns the txid.
+func (eth *Gateway) InjectTx(rawtx string) (string, error) {
+	var txid string
+	if err := call(eth.NodeAddr, "eth_sendRawTransaction", &txid, "0x"+strings.TrimPrefix(rawtx, "0x")); err != nil {
+		return "", err
+	}
+	return txid, nil
+}
+
+// CreateRawTx creates the unsigned transaction in hex, which sends the value of the only TxOut
+// in txOuts from the address of the only txIn, the Txid and Vout of txIn are ignored. The nonce
+// is counted as used, ResetNonce must be called if the transaction is not broadcasted.
+func (eth *Gateway) CreateRawTx(txIns []coin.TxIn, txOuts interface{}) (string, error) {
+	outs, ok := txOuts.([]TxOut)
+	if !ok {
+		return "", fmt.Errorf("unknown tx out type %T", txOuts)
+	}
+	if len(txIns) != 1 || len(outs) != 1 {
+		return "", errors.New("ethereum transaction must have one sender and one recipient")
+	}
+
+	from := txIns[0].Address
+	if err := ValidateAddr(from); err != nil {
+		return "", err
+	}
+	if err := ValidateAddr(outs[0].Addr); err != nil {
+		return "", err
+	}
+
+	gasPrice, err := eth.gasPrice()
+	if err != nil {
+		return "", err
+	}
+
+	nonce, err := eth.nonces.Next(from)
+	if err != nil {
+		return "", err
+	}
+
+	tx := Transaction{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      GasLimit,
+		To:       outs[0].Addr,
+		Value:    outs[0].Value,
+		From:     from,
+	}
+	d, err := tx.Serialize()
+	if err != nil {
+		eth.nonces.Reset(from)
+		return "", err
+	}
+	return hex.EncodeToString(d), nil
+}
+
+// SignRawTx signs the unsigned transaction in hex with the key of its sender.
+func (eth *Gateway) SignRawTx(rawtx string, getKey coin.GetPrivKey) (string, error) {
+	b, err := hex.DecodeString(rawtx)
+	if err != nil {
+		return "", err
+	}
+	tx, err := DecodeTx(b)
+	if err != nil {
+		return "", err
+	}
+	if tx.IsSigned() {
+		return "", errors.New("transaction is already signed")
+	}
+
+	key, err := getKey(tx.From)
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Sign(ChainID, key); err != nil {
+		return "", err
+	}
+
+	d, err := tx.Serialize()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(d), nil
+}
+
+// ResetNonce drops the local nonce of the address, see NonceManager.Reset.
+func (eth *Gateway) ResetNonce(addr string) {
+	eth.nonces.Reset(addr)
+}
+
+// Send sends amount wei from the address to the recipient, returns the txid. The nonce
+// is reused by the next transaction if the broadcast fails.
+func (eth *Gateway) Send(from, to string, amount uint64, getKey coin.GetPrivKey) (string, error) {
+	rawtx, err := eth.CreateRawTx([]coin.TxIn{{Address: from}}, []TxOut{{Addr: to, Value: amount}})
+	if err != nil {
+		return "", err
+	}
+
+	signed, err := eth.SignRawTx(rawtx, getKey)
+	if err != nil {
+		eth.nonces.Reset(from)
+		return "", err
+	}
+
+	txid, err := eth.InjectTx(signed)
+	if err != nil {
+		eth.nonces.Reset(from)
+		return "", err
+	}
+	return txid, nil
+}
+
+// ValidateTxid checks the txid of 32 bytes in hex with 0x prefix.
+func (eth *Gateway) ValidateTxid(txid string) bool {
+	if !strings.HasPrefix(txid, "0x") || len(txid) != 66 {
+		return false
+	}
+	_, err := hex.DecodeString(txid[2:])
+	return err == nil
+}
+
+// EstimateFee returns the fee of the ether transfer in wei, the inputs and outputs are ignored.
+func (eth *Gateway) EstimateFee(nInputs, nOutputs int) (uint64, error) {
+	gasPrice, err := eth.gasPrice()
+	if err != nil {
+		return 0, err
+	}
+	return gasPrice * GasLimit, nil
+}
+
+// GetFeeEstimates returns the current gas price of the node in wei per gas for all of the
+// targets, the node has no estimation for confirmation windows.
+func (eth *Gateway) GetFeeEstimates() ([]coin.FeeEstimate, error) {
+	gasPrice, err := eth.gasPrice()
+	if err != nil {
+		return nil, err
+	}
+	return coin.StaticFeeEstimates(gasPrice), nil
+}
+
+func (eth *Gateway) gasPrice() (uint64, error) {
+	return callUint(eth.NodeAddr, "eth_gasPrice")
+}
@@ -0,0 +1,173 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/stretchr/testify/assert"
+)
+
+// nodeMock mocks the JSON-RPC api of ethereum node, the pending transaction count of
+// every address is base plus the accepted transactions.
+type nodeMock struct {
+	mtx      sync.Mutex
+	base     uint64
+	accepted []*Transaction
+	counts   int  // calls of eth_getTransactionCount.
+	reject   bool // rejects the next transaction.
+}
+
+func (m *nodeMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	var result interface{}
+	switch req.Method {
+	case "eth_getTransactionCount":
+		m.counts++
+		result = quantityOf(m.base + uint64(len(m.accepted)))
+	case "eth_gasPrice":
+		result = "0x4a817c800"
+	case "eth_getBalance":
+		result = "0xde0b6b3a7640000"
+	case "eth_blockNumber":
+		result = "0x10"
+	case "eth_sendRawTransaction":
+		if m.reject {
+			m.reject = false
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": rpcError{Code: -32000, Message: "rejected"}})
+			return
+		}
+		b, _ := hex.DecodeString(strings.TrimPrefix(req.Params[0].(string), "0x"))
+		tx, err := DecodeTx(b)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		m.accepted = append(m.accepted, tx)
+		result = "0x" + hex.EncodeToString(Keccak256(b))
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+}
+
+func (m *nodeMock) nonces() []uint64 {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	ns := make([]uint64, len(m.accepted))
+	for i, tx := range m.accepted {
+		ns[i] = tx.Nonce
+	}
+	return ns
+}
+
+func quantityOf(v uint64) string {
+	return "0x" + strconv.FormatUint(v, 16)
+}
+
+func newTestGateway(m *nodeMock) (*Gateway, string, func(string) (string, error), func()) {
+	srv := httptest.NewServer(m)
+	key := strings.Repeat("46", 32)
+	b, _ := hex.DecodeString(key)
+	_, pub := btcec.PrivKeyFromBytes(btcec.S256(), b)
+	getKey := func(string) (string, error) { return key, nil }
+	return New(strings.TrimPrefix(srv.URL, "http://")), AddressFromPubkey(pub), getKey, srv.Close
+}
+
+func TestSendNonces(t *testing.T) {
+	m := &nodeMock{base: 5}
+	eth, from, getKey, teardown := newTestGateway(m)
+	defer teardown()
+
+	to := "0x3535353535353535353535353535353535353535"
+	for i := 0; i < 2; i++ {
+		txid, err := eth.Send(from, to, 1000, getKey)
+		assert.Nil(t, err)
+		assert.True(t, eth.ValidateTxid(txid))
+	}
+
+	// the first nonce is fetched from node, the later ones are counted locally.
+	assert.Equal(t, []uint64{5, 6}, m.nonces())
+	assert.Equal(t, 1, m.counts)
+
+	// the nonce of the rejected transaction is reused.
+	m.reject = true
+	_, err := eth.Send(from, to, 1000, getKey)
+	assert.NotNil(t, err)
+	_, err = eth.Send(from, to, 1000, getKey)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{5, 6, 7}, m.nonces())
+	assert.Equal(t, 2, m.counts)
+
+	// the address is case insensitive.
+	_, err = eth.Send(strings.ToLower(from), to, 1000, getKey)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{5, 6, 7, 8}, m.nonces())
+	assert.Equal(t, 2, m.counts)
+}
+
+func TestSendConcurrent(t *testing.T) {
+	m := &nodeMock{}
+	eth, from, getKey, teardown := newTestGateway(m)
+	defer teardown()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := eth.Send(from, "0x3535353535353535353535353535353535353535", 1000, getKey)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// every transaction has its own nonce.
+	seen := make(map[uint64]bool)
+	for _, n := range m.nonces() {
+		assert.False(t, seen[n], "nonce %d reused", n)
+		seen[n] = true
+	}
+	assert.Equal(t, 10, len(seen))
+}
+
+func TestGatewayNode(t *testing.T) {
+	m := &nodeMock{}
+	eth, from, _, teardown := newTestGateway(m)
+	defer teardown()
+
+	bal, err := eth.GetBalance([]string{from, from})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2000000000000000000), bal.GetAmount())
+
+	_, err = eth.GetBalance([]string{"0xinvalid"})
+	assert.NotNil(t, err)
+
+	ok, err := eth.HealthCheck()
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	fee, err := eth.EstimateFee(1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(20000000000*21000), fee)
+
+	// the transaction must have one sender.
+	var gw coin.Gateway = eth
+	_, err = gw.CreateRawTx(nil, []TxOut{{Addr: from, Value: 1}})
+	assert.NotNil(t, err)
+}
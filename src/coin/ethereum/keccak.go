@@ -0,0 +1,95 @@
+package ethereum
+
+import "encoding/binary"
+
+// keccak256 rate in bytes, the capacity is 512 bits.
+const keccakRate = 136
+
+var keccakRC = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+	0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+	0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// rotation offsets and lane positions of the rho and pi steps.
+var (
+	keccakRotc = [24]uint{1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 2, 14, 27, 41, 56, 8, 25, 43, 62, 18, 39, 61, 20, 44}
+	keccakPiln = [24]int{10, 7, 11, 17, 18, 3, 5, 16, 8, 21, 24, 4, 15, 23, 19, 13, 12, 2, 20, 14, 22, 9, 6, 1}
+)
+
+// Keccak256 returns the Keccak-256 hash of the data, which is used by ethereum. It's the
+// original Keccak padding, not the SHA3-256 of FIPS 202.
+func Keccak256(data ...[]byte) []byte {
+	var msg []byte
+	for _, d := range data {
+		msg = append(msg, d...)
+	}
+
+	// pad with 0x01 ... 0x80 to a multiple of the rate.
+	n := keccakRate - len(msg)%keccakRate
+	pad := make([]byte, n)
+	pad[0] = 0x01
+	pad[n-1] |= 0x80
+	msg = append(msg, pad...)
+
+	var st [25]uint64
+	for len(msg) > 0 {
+		for i := 0; i < keccakRate/8; i++ {
+			st[i] ^= binary.LittleEndian.Uint64(msg[i*8:])
+		}
+		keccakF(&st)
+		msg = msg[keccakRate:]
+	}
+
+	out := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint64(out[i*8:], st[i])
+	}
+	return out
+}
+
+// keccakF is the Keccak-f[1600] permutation.
+func keccakF(st *[25]uint64) {
+	var bc [5]uint64
+	for r := 0; r < 24; r++ {
+		// theta
+		for i := 0; i < 5; i++ {
+			bc[i] = st[i] ^ st[i+5] ^ st[i+10] ^ st[i+15] ^ st[i+20]
+		}
+		for i := 0; i < 5; i++ {
+			t := bc[(i+4)%5] ^ rotl(bc[(i+1)%5], 1)
+			for j := 0; j < 25; j += 5 {
+				st[j+i] ^= t
+			}
+		}
+
+		// rho and pi
+		t := st[1]
+		for i := 0; i < 24; i++ {
+			j := keccakPiln[i]
+			bc[0] = st[j]
+			st[j] = rotl(t, keccakRotc[i])
+			t = bc[0]
+		}
+
+		// chi
+		for j := 0; j < 25; j += 5 {
+			for i := 0; i < 5; i++ {
+				bc[i] = st[j+i]
+			}
+			for i := 0; i < 5; i++ {
+				st[j+i] ^= ^bc[(i+1)%5] & bc[(i+2)%5]
+			}
+		}
+
+		// iota
+		st[0] ^= keccakRC[r]
+	}
+}
+
+func rotl(x uint64, n uint) uint64 {
+	return x<<n | x>>(64-n)
+}
@@ -0,0 +1,54 @@
+package ethereum
+
+import (
+	"strings"
+	"sync"
+)
+
+// NonceManager assigns the nonces of the transactions sent from each address. The first
+// nonce of the address is its pending transaction count from the node, the later ones are
+// counted locally, so that the transactions sent in a row don't reuse the nonce before
+// the node sees the previous ones.
+type NonceManager struct {
+	mtx    sync.Mutex
+	nonces map[string]uint64 // next nonce of each address, in lower case.
+	count  func(addr string) (uint64, error)
+}
+
+// NewNonceManager creates the nonce manager, count returns the pending transaction count of the address.
+func NewNonceManager(count func(addr string) (uint64, error)) *NonceManager {
+	return &NonceManager{
+		nonces: make(map[string]uint64),
+		count:  count,
+	}
+}
+
+// Next returns the next nonce of the address and counts it as used.
+func (nm *NonceManager) Next(addr string) (uint64, error) {
+	nm.mtx.Lock()
+	defer nm.mtx.Unlock()
+
+	a := normalizeAddr(addr)
+	n, ok := nm.nonces[a]
+	if !ok {
+		var err error
+		if n, err = nm.count(addr); err != nil {
+			return 0, err
+		}
+	}
+	nm.nonces[a] = n + 1
+	return n, nil
+}
+
+// Reset drops the local nonce of the address, the next one is fetched from the node again.
+// It's called when the transaction of the nonce failed to send, so that the nonce is reused
+// instead of leaving a gap that blocks the later transactions.
+func (nm *NonceManager) Reset(addr string) {
+	nm.mtx.Lock()
+	delete(nm.nonces, normalizeAddr(addr))
+	nm.mtx.Unlock()
+}
+
+func normalizeAddr(addr string) string {
+	return strings.ToLower(addr)
+}
@@ -0,0 +1,133 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+)
+
+// the transactions are flat RLP lists of byte strings, only the encoding needed by them is implemented.
+
+// rlpBytes encodes the byte string.
+func rlpBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return []byte{b[0]}
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+// rlpUint encodes the integer as the big-endian byte string without leading zeros.
+func rlpUint(v uint64) []byte {
+	return rlpBytes(trimZeros(uintBytes(v)))
+}
+
+// rlpList encodes the list of the encoded items.
+func rlpList(items ...[]byte) []byte {
+	var payload []byte
+	for _, it := range items {
+		payload = append(payload, it...)
+	}
+	return append(rlpHeader(0xc0, len(payload)), payload...)
+}
+
+func rlpHeader(offset byte, n int) []byte {
+	if n <= 55 {
+		return []byte{offset + byte(n)}
+	}
+	l := trimZeros(uintBytes(uint64(n)))
+	return append([]byte{offset + 55 + byte(len(l))}, l...)
+}
+
+// rlpDecodeList decodes the flat list of byte strings, the nested lists are not supported.
+func rlpDecodeList(b []byte) ([][]byte, error) {
+	payload, rest, isList, err := rlpSplit(b)
+	if err != nil {
+		return nil, err
+	}
+	if !isList || len(rest) > 0 {
+		return nil, errors.New("rlp: not a single list")
+	}
+
+	items := [][]byte{}
+	for len(payload) > 0 {
+		var it []byte
+		it, payload, isList, err = rlpSplit(payload)
+		if err != nil {
+			return nil, err
+		}
+		if isList {
+			return nil, errors.New("rlp: nested list")
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// rlpSplit splits the first item of b, returns its payload and the rest bytes.
+func rlpSplit(b []byte) ([]byte, []byte, bool, error) {
+	if len(b) == 0 {
+		return nil, nil, false, errors.New("rlp: unexpected end")
+	}
+
+	prefix := b[0]
+	switch {
+	case prefix < 0x80:
+		return b[:1], b[1:], false, nil
+	case prefix <= 0xb7:
+		return rlpTake(b[1:], int(prefix-0x80), false)
+	case prefix < 0xc0:
+		return rlpTakeLong(b[1:], int(prefix-0xb7), false)
+	case prefix <= 0xf7:
+		return rlpTake(b[1:], int(prefix-0xc0), true)
+	default:
+		return rlpTakeLong(b[1:], int(prefix-0xf7), true)
+	}
+}
+
+func rlpTakeLong(b []byte, lenOfLen int, isList bool) ([]byte, []byte, bool, error) {
+	if len(b) < lenOfLen || lenOfLen > 8 {
+		return nil, nil, false, errors.New("rlp: invalid length")
+	}
+	var n uint64
+	for _, c := range b[:lenOfLen] {
+		n = n<<8 | uint64(c)
+	}
+	if n > uint64(len(b)) {
+		return nil, nil, false, errors.New("rlp: unexpected end")
+	}
+	return rlpTake(b[lenOfLen:], int(n), isList)
+}
+
+func rlpTake(b []byte, n int, isList bool) ([]byte, []byte, bool, error) {
+	if n > len(b) {
+		return nil, nil, false, fmt.Errorf("rlp: %d bytes expected, %d left", n, len(b))
+	}
+	return b[:n], b[n:], isList, nil
+}
+
+func uintBytes(v uint64) []byte {
+	b := make([]byte, 8)
+	for i := 7; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return b
+}
+
+func trimZeros(b []byte) []byte {
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+	return b
+}
+
+// bytesToUint decodes the big-endian integer of at most 8 bytes.
+func bytesToUint(b []byte) (uint64, error) {
+	if len(b) > 8 {
+		return 0, errors.New("rlp: integer overflows uint64")
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
@@ -0,0 +1,110 @@
+package ethereum
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RPCTimeout max time of one JSON-RPC call to the node.
+var RPCTimeout = 10 * time.Second
+
+var rpcID uint64
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("ethereum node error %d: %s", e.Code, e.Message)
+}
+
+// call calls the JSON-RPC method of the node, which listens on nodeAddr like 127.0.0.1:8545,
+// the result is decoded into v.
+func call(nodeAddr, method string, v interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	d, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddUint64(&rpcID, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	c := http.Client{Timeout: RPCTimeout}
+	rsp, err := c.Post(fmt.Sprintf("http://%s", nodeAddr), "application/json", bytes.NewReader(d))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s responds %s", nodeAddr, method, rsp.Status)
+	}
+
+	var res rpcResponse
+	if err := json.NewDecoder(rsp.Body).Decode(&res); err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(res.Result, v)
+}
+
+// callUint calls the method whose result is a hex quantity that fits in uint64.
+func callUint(nodeAddr, method string, params ...interface{}) (uint64, error) {
+	var s string
+	if err := call(nodeAddr, method, &s, params...); err != nil {
+		return 0, err
+	}
+	return parseQuantity(s)
+}
+
+// parseQuantity parses the hex quantity like 0x1b4, returns error if it overflows uint64.
+func parseQuantity(s string) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	n, ok := new(big.Int).SetString(s[2:], 16)
+	if !ok {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	if !n.IsUint64() {
+		return 0, errors.New("quantity overflows uint64")
+	}
+	return n.Uint64(), nil
+}
+
+func ptrQuantity(s string) (*uint64, error) {
+	n, err := parseQuantity(s)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
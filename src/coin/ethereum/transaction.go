@@ -0,0 +1,179 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// TxOut the recipient of the ether transfer, Value is in wei.
+type TxOut struct {
+	Addr  string
+	Value uint64
+}
+
+// Transaction the legacy ether transfer transaction without data, the amounts are in wei.
+// The unsigned transaction carries the sender in place of the signature, so that it can
+// be signed with the sender's key, the signed one has the EIP-155 signature.
+type Transaction struct {
+	Nonce    uint64
+	GasPrice uint64
+	Gas      uint64
+	To       string
+	Value    uint64
+	From     string // sender of the unsigned transaction.
+	V        uint64
+	R        []byte
+	S        []byte
+}
+
+// IsSigned checks whether the transaction has signature.
+func (tx Transaction) IsSigned() bool {
+	return len(tx.R) > 0 && len(tx.S) > 0
+}
+
+// Serialize encodes the transaction in RLP, the signed transaction is in the format
+// accepted by eth_sendRawTransaction.
+func (tx Transaction) Serialize() ([]byte, error) {
+	to, err := addrBytes(tx.To)
+	if err != nil {
+		return nil, err
+	}
+
+	items := [][]byte{
+		rlpUint(tx.Nonce),
+		rlpUint(tx.GasPrice),
+		rlpUint(tx.Gas),
+		rlpBytes(to),
+		rlpUint(tx.Value),
+		rlpBytes(nil),
+	}
+	if tx.IsSigned() {
+		items = append(items, rlpUint(tx.V), rlpBytes(trimZeros(tx.R)), rlpBytes(trimZeros(tx.S)))
+	} else {
+		from, err := addrBytes(tx.From)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, rlpBytes(from))
+	}
+	return rlpList(items...), nil
+}
+
+// Hash returns the txid of the signed transaction.
+func (tx Transaction) Hash() (string, error) {
+	if !tx.IsSigned() {
+		return "", errors.New("transaction is not signed")
+	}
+	d, err := tx.Serialize()
+	if err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(Keccak256(d)), nil
+}
+
+// sigHash returns the EIP-155 signing hash of the transaction.
+func (tx Transaction) sigHash(chainID uint64) ([]byte, error) {
+	to, err := addrBytes(tx.To)
+	if err != nil {
+		return nil, err
+	}
+	return Keccak256(rlpList(
+		rlpUint(tx.Nonce),
+		rlpUint(tx.GasPrice),
+		rlpUint(tx.Gas),
+		rlpBytes(to),
+		rlpUint(tx.Value),
+		rlpBytes(nil),
+		rlpUint(chainID),
+		rlpUint(0),
+		rlpUint(0))), nil
+}
+
+// Sign signs the transaction for the chain with the private key in hex, the key must
+// be the sender's if the transaction has one.
+func (tx *Transaction) Sign(chainID uint64, seckey string) error {
+	key, err := privKeyFromHex(seckey)
+	if err != nil {
+		return err
+	}
+
+	from := AddressFromPubkey(key.PubKey())
+	if tx.From != "" && !sameAddr(tx.From, from) {
+		return fmt.Errorf("private key is not of the sender %s", tx.From)
+	}
+
+	h, err := tx.sigHash(chainID)
+	if err != nil {
+		return err
+	}
+
+	// the compact signature is [27 + recovery id] || R || S.
+	sig, err := btcec.SignCompact(btcec.S256(), key, h, false)
+	if err != nil {
+		return err
+	}
+	tx.V = uint64(sig[0]-27) + chainID*2 + 35
+	tx.R = sig[1:33]
+	tx.S = sig[33:]
+	tx.From = from
+	return nil
+}
+
+// DecodeTx decodes the unsigned or signed transaction serialized by Serialize, the sender
+// of the signed transaction is not recovered.
+func DecodeTx(b []byte) (*Transaction, error) {
+	items, err := rlpDecodeList(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != 7 && len(items) != 9 {
+		return nil, fmt.Errorf("invalid transaction of %d fields", len(items))
+	}
+	if len(items[5]) > 0 {
+		return nil, errors.New("transaction with data is not supported")
+	}
+
+	var tx Transaction
+	for i, v := range []*uint64{&tx.Nonce, &tx.GasPrice, &tx.Gas} {
+		if *v, err = bytesToUint(items[i]); err != nil {
+			return nil, err
+		}
+	}
+	if tx.Value, err = bytesToUint(items[4]); err != nil {
+		return nil, err
+	}
+	if len(items[3]) != 20 {
+		return nil, errors.New("invalid recipient address")
+	}
+	tx.To = ChecksumAddr(items[3])
+
+	if len(items) == 7 {
+		if len(items[6]) != 20 {
+			return nil, errors.New("invalid sender address")
+		}
+		tx.From = ChecksumAddr(items[6])
+		return &tx, nil
+	}
+
+	if tx.V, err = bytesToUint(items[6]); err != nil {
+		return nil, err
+	}
+	tx.R, tx.S = items[7], items[8]
+	return &tx, nil
+}
+
+// addrBytes decodes the address to 20 bytes.
+func addrBytes(addr string) ([]byte, error) {
+	if err := ValidateAddr(addr); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(addr[2:])
+}
+
+// sameAddr checks whether the two addresses are the same regardless of case.
+func sameAddr(a, b string) bool {
+	return len(a) == len(b) && normalizeAddr(a) == normalizeAddr(b)
+}
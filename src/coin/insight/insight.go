@@ -0,0 +1,299 @@
+// Package insight implements the blockchain queries and the utxo manager shared by the
+// coins of the bitcoin utxo model, whose blockchain is served by the insight api, the
+// coin packages only provide the Network of their params.
+package insight
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	logging "github.com/op/go-logging"
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/pp"
+)
+
+var logger = logging.MustGetLogger("exchange.insight")
+
+// Network the params of the coin network served by the insight api.
+type Network struct {
+	Type         string                  // coin type, used in the logs and errors.
+	API          func() string           // returns the base url of the insight api.
+	ValidateAddr func(addr string) error // checks if the address belongs to the network.
+}
+
+// Utxo unspent output
+type Utxo interface {
+	GetTxid() string
+	GetVout() uint32
+	GetAmount() uint64
+	GetAddress() string
+	GetConfirmations() uint64
+	GetBlockHash() string
+}
+
+// UnspentOutput the unspent output returned by the insight api.
+type UnspentOutput struct {
+	Address      string `json:"address"`
+	Txid         string `json:"txid"`
+	Vout         uint32 `json:"vout"`
+	ScriptPubkey string `json:"criptPubKey"`
+	Amount       uint64 `json:"satoshis"`
+	Confirms     uint64 `json:"confirmations"`
+	BlockHash    string `json:"blockhash,omitempty"` // hash of the block including the tx, empty if the api doesn't report it.
+}
+
+func (uo UnspentOutput) GetTxid() string {
+	return uo.Txid
+}
+
+func (uo UnspentOutput) GetVout() uint32 {
+	return uo.Vout
+}
+
+func (uo UnspentOutput) GetAmount() uint64 {
+	return uo.Amount
+}
+
+func (uo UnspentOutput) GetAddress() string {
+	return uo.Address
+}
+
+func (uo UnspentOutput) GetConfirmations() uint64 {
+	return uo.Confirms
+}
+
+func (uo UnspentOutput) GetBlockHash() string {
+	return uo.BlockHash
+}
+
+func (n *Network) validateAddrs(addrs []string) error {
+	for _, a := range addrs {
+		if err := n.ValidateAddr(a); err != nil {
+			return fmt.Errorf("invalid %s address %v", n.Type, a)
+		}
+	}
+	return nil
+}
+
+// GetUnspentOutputs return the unspent outputs of specific addresses.
+func (n *Network) GetUnspentOutputs(addrs []string) ([]Utxo, error) {
+	if len(addrs) == 0 {
+		return []Utxo{}, nil
+	}
+
+	if err := n.validateAddrs(addrs); err != nil {
+		return []Utxo{}, err
+	}
+
+	rsp, err := http.Get(fmt.Sprintf("%s/addrs/%s/utxo", n.API(), strings.Join(addrs, ",")))
+	if err != nil {
+		return []Utxo{}, fmt.Errorf("get %s utxo from insight api failed", n.Type)
+	}
+
+	defer rsp.Body.Close()
+	if rsp.StatusCode != 200 {
+		return []Utxo{}, fmt.Errorf("get %s unspent output from insight api failed", n.Type)
+	}
+	data, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return []Utxo{}, err
+	}
+	us := []UnspentOutput{}
+	if err := json.Unmarshal(data, &us); err != nil {
+		return []Utxo{}, err
+	}
+
+	utxos := make([]Utxo, len(us))
+	for i, u := range us {
+		utxos[i] = u
+	}
+	return utxos, nil
+}
+
+// GetTxVerbose get the verbose transaction of specific txid, the verbose transactions
+// of the insight coins have the same struct as bitcoin.
+func (n *Network) GetTxVerbose(txid string) (*pp.Tx, error) {
+	d, err := getDataOfUrl(fmt.Sprintf("%s/tx/%s", n.API(), txid))
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.ToLower(string(d)) == "not found" {
+		return nil, errors.New("not found")
+	}
+
+	tx := pp.Tx{}
+	if err := json.Unmarshal(d, &tx.Btc); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// GetRawTx get the hex encoded raw transaction of specific txid.
+func (n *Network) GetRawTx(txid string) (string, error) {
+	d, err := getDataOfUrl(fmt.Sprintf("%s/rawtx/%s", n.API(), txid))
+	if err != nil {
+		return "", err
+	}
+	v := struct {
+		Rawtx string `json:"rawtx"`
+	}{}
+	if err := json.Unmarshal(d, &v); err != nil {
+		return "", err
+	}
+	return v.Rawtx, nil
+}
+
+type balanceResult struct {
+	balance uint64
+	err     error
+}
+
+// GetBalance queries the balance of addresses concurrently, one request for each address.
+func (n *Network) GetBalance(addrs []string) (uint64, error) {
+	if err := n.validateAddrs(addrs); err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	valueChan := make(chan balanceResult, len(addrs))
+	for _, addr := range addrs {
+		wg.Add(1)
+		go func(addr string, wg *sync.WaitGroup, vc chan balanceResult) {
+			defer wg.Done()
+			d, err := getDataOfUrl(fmt.Sprintf("%s/addr/%s/balance", n.API(), addr))
+			if err != nil {
+				vc <- balanceResult{0, err}
+				return
+			}
+			v, err := strconv.ParseUint(string(d), 10, 64)
+			if err != nil {
+				vc <- balanceResult{0, err}
+				return
+			}
+			vc <- balanceResult{v, nil}
+		}(addr, &wg, valueChan)
+	}
+	wg.Wait()
+	close(valueChan)
+
+	var totalBal uint64
+	for v := range valueChan {
+		if v.err != nil {
+			return 0, v.err
+		}
+		totalBal += v.balance
+	}
+	return totalBal, nil
+}
+
+// GetAddressTxs get transactions of specific addresses, the history of each
+// address is fetched page by page, and the duplicated txs are removed.
+func (n *Network) GetAddressTxs(addrs []string) ([]*pp.Tx, error) {
+	if err := n.validateAddrs(addrs); err != nil {
+		return nil, err
+	}
+
+	txs := []*pp.Tx{}
+	txMap := make(map[string]bool)
+	for _, addr := range addrs {
+		for page, total := 0, 1; page < total; page++ {
+			d, err := getDataOfUrl(fmt.Sprintf("%s/txs?address=%s&pageNum=%d", n.API(), addr, page))
+			if err != nil {
+				return nil, err
+			}
+
+			v := struct {
+				PagesTotal int         `json:"pagesTotal"`
+				Txs        []*pp.BtcTx `json:"txs"`
+			}{}
+			if err := json.Unmarshal(d, &v); err != nil {
+				return nil, err
+			}
+
+			for _, tx := range v.Txs {
+				if txMap[tx.GetTxid()] {
+					continue
+				}
+				txMap[tx.GetTxid()] = true
+				txs = append(txs, &pp.Tx{Btc: tx})
+			}
+			total = v.PagesTotal
+		}
+	}
+	return txs, nil
+}
+
+// HealthCheck checks if the insight api is available.
+func (n *Network) HealthCheck() error {
+	return coin.CheckURL(n.API() + "/status?q=getInfo")
+}
+
+// BroadcastTx broadcast the raw transaction through the insight apis, tried in order until one
+// accepts the transaction, the next one is used if the api is down, the transaction rejected
+// by the node is not sent again.
+func BroadcastTx(apis []string, rawtx string) (string, error) {
+	return coin.Failover(apis, func(api string) (string, error) {
+		return broadcastTx(api, rawtx)
+	})
+}
+
+// broadcastTx sends the raw transaction to the insight api.
+func broadcastTx(api, rawtx string) (string, error) {
+	j, err := json.Marshal(struct {
+		RawTx string `json:"rawtx"`
+	}{rawtx})
+	if err != nil {
+		return "", fmt.Errorf("Broadcasting the tx failed: %v", err)
+	}
+
+	resp, err := http.Post(api+"/tx/send", "application/json", bytes.NewBuffer(j))
+	if err != nil {
+		return "", fmt.Errorf("Broadcasting the tx failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	// the insight api responds 400 with the reject code of the node if the transaction is rejected.
+	if resp.StatusCode == http.StatusBadRequest || strings.Contains(string(b), "Code:") {
+		return "", fmt.Errorf("Broadcast tx failed, %w: %v", coin.ErrTxRejected, string(b))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Broadcast tx failed, %s", resp.Status)
+	}
+
+	v := struct {
+		Txid string `json:"txid"`
+	}{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return "", fmt.Errorf("Broadcasting tx failed, unmarshal result failed, err:%v", err)
+	}
+	return v.Txid, nil
+}
+
+// getDataOfUrl, get data from specific URL.
+func getDataOfUrl(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return []byte{}, fmt.Errorf("access %v failed", url)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
@@ -0,0 +1,74 @@
+package insight
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAPI = "http://127.0.0.1:0/api"
+
+// testNet accepts the addresses starting with 1.
+var testNet = &Network{
+	Type: "testcoin",
+	API:  func() string { return testAPI },
+	ValidateAddr: func(addr string) error {
+		if !strings.HasPrefix(addr, "1") {
+			return errors.New("invalid address")
+		}
+		return nil
+	},
+}
+
+func withAPI(url string) func() {
+	api := testAPI
+	testAPI = url + "/api"
+	return func() { testAPI = api }
+}
+
+func TestGetUnspentOutputs(t *testing.T) {
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, fmt.Sprintf("/api/addrs/%s/utxo", addr), r.URL.Path)
+		json.NewEncoder(w).Encode([]UnspentOutput{{Address: addr, Txid: "t1", Vout: 1, Amount: 100, Confirms: 2, BlockHash: "b1"}})
+	}))
+	defer srv.Close()
+	defer withAPI(srv.URL)()
+
+	utxos, err := testNet.GetUnspentOutputs([]string{addr})
+	assert.Nil(t, err)
+	assert.Equal(t, []Utxo{UnspentOutput{Address: addr, Txid: "t1", Vout: 1, Amount: 100, Confirms: 2, BlockHash: "b1"}}, utxos)
+
+	_, err = testNet.GetUnspentOutputs([]string{addr, "mzBc4XEFSdzCDcTxAgf6EZXgsZWpztRhef"})
+	assert.EqualError(t, err, "invalid testcoin address mzBc4XEFSdzCDcTxAgf6EZXgsZWpztRhef")
+}
+
+func TestBroadcastTx(t *testing.T) {
+	newAPI := func(status int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		}))
+	}
+
+	down := newAPI(http.StatusInternalServerError, "down")
+	defer down.Close()
+	ok := newAPI(http.StatusOK, `{"txid":"abc"}`)
+	defer ok.Close()
+	txid, err := BroadcastTx([]string{down.URL, ok.URL}, "0100")
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", txid)
+
+	// the transaction rejected by the node is not sent to the next api.
+	rejecting := newAPI(http.StatusBadRequest, "258: txn-mempool-conflict. Code:-26")
+	defer rejecting.Close()
+	_, err = BroadcastTx([]string{rejecting.URL, ok.URL}, "0100")
+	assert.True(t, errors.Is(err, coin.ErrTxRejected))
+}
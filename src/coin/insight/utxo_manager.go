@@ -0,0 +1,450 @@
+package insight
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/sklog"
+)
+
+// CheckTick the interval of checking the new utxos of the watched addresses.
+var CheckTick = 5 * time.Second
+
+// UtxoManager manages the utxos of the watched addresses of an insight coin.
+type UtxoManager interface {
+	Start(closing chan bool)
+	ChooseUtxos(amt uint64, tm time.Duration) ([]Utxo, error)
+	TakeUtxos(ids []string) ([]Utxo, error) // take the specific utxos of txid:vout out of the pool.
+	PutUtxo(utxo Utxo)                      // put utxo into utxo pool
+	WatchAddresses(addrs []string)
+	SetPoolSize(n int) error         // resize the utxo pool
+	PoolSize() (int, int)            // capacity of the utxo pool and the available utxos.
+	SetMinConfirmations(n uint64)    // utxos with less confirmations are ignored.
+	PendingDeposits() int            // number of the utxos waiting for the min confirmations.
+	Stats() coin.UtxoStats           // state of the utxo pool.
+	SetDepositHandler(fn func(Utxo)) // fn is called for each new confirmed utxo.
+	SetReorgHandler(fn func(Utxo))   // fn is called for each confirmed utxo orphaned by reorg.
+}
+
+// ExUtxoManager implements the UtxoManager interface.
+type ExUtxoManager struct {
+	*coin.UtxoPool
+	net          *Network
+	WatchAddress []string
+	UtxoStateMap map[string]Utxo
+	minConfirms  uint64     // min confirmations of the utxos put into the pool.
+	onDeposit    func(Utxo) // called when new confirmed utxo is found.
+	onReorg      func(Utxo) // called when the confirmed utxo is orphaned by reorg.
+	pending      int        // number of the utxos with less than minConfirms confirmations.
+	confMtx      sync.RWMutex
+	statsMtx     sync.Mutex      // protects the following stats.
+	available    int             // utxos in the pool.
+	value        uint64          // total value of the available utxos.
+	borrowed     map[string]Utxo // utxos chosen and not yet put back or spent, key: txid:vout.
+	lastRefill   int64           // unix time of the last check of new utxos.
+}
+
+// NewUtxoManager creates the utxo manager of the coin network.
+func NewUtxoManager(net *Network, utxoPoolsize int, watchAddrs []string) UtxoManager {
+	eum := &ExUtxoManager{
+		net:          net,
+		UtxoPool:     coin.NewUtxoPool(utxoPoolsize),
+		UtxoStateMap: make(map[string]Utxo),
+		borrowed:     make(map[string]Utxo),
+		WatchAddress: watchAddrs,
+	}
+
+	return eum
+}
+
+func (eum *ExUtxoManager) Start(closing chan bool) {
+	logger.Info("start the %s utxo manager", eum.net.Type)
+	t := time.Tick(CheckTick)
+	for {
+		select {
+		case <-closing:
+			return
+		case <-t:
+			// check new utxos.
+			newUtxos, reorged, err := eum.checkNewUtxo()
+			if err != nil {
+				logger.Error(err.Error())
+				break
+			}
+
+			eum.confMtx.RLock()
+			onDeposit, onReorg := eum.onDeposit, eum.onReorg
+			eum.confMtx.RUnlock()
+			for _, utxo := range reorged {
+				sklog.Info(logger, "utxo orphaned by reorg", sklog.Fields{"coin": eum.net.Type, "address": utxo.GetAddress(), "txid": utxo.GetTxid(), "vout": utxo.GetVout(), "amount": utxo.GetAmount(), "block": utxo.GetBlockHash()})
+				if onReorg != nil {
+					onReorg(utxo)
+				}
+			}
+
+			for _, utxo := range newUtxos {
+				sklog.Debug(logger, "new utxo", sklog.Fields{"coin": eum.net.Type, "address": utxo.GetAddress(), "txid": utxo.GetTxid(), "vout": utxo.GetVout(), "amount": utxo.GetAmount()})
+				if onDeposit != nil {
+					onDeposit(utxo)
+				}
+				eum.putBack(utxo)
+				eum.Put(utxo)
+			}
+		}
+	}
+}
+
+func (eum *ExUtxoManager) PutUtxo(utxo Utxo) {
+	sklog.Debug(logger, "utxo put back", sklog.Fields{"coin": eum.net.Type, "address": utxo.GetAddress(), "txid": utxo.GetTxid(), "vout": utxo.GetVout()})
+	eum.putBack(utxo)
+	eum.Put(utxo)
+}
+
+func (eum *ExUtxoManager) WatchAddresses(addrs []string) {
+	eum.WatchAddress = append(eum.WatchAddress, addrs...)
+}
+
+// SetMinConfirmations sets the min confirmations of utxos, the utxos with less
+// confirmations are neither put into the pool nor reported as deposits, until
+// they are confirmed enough.
+func (eum *ExUtxoManager) SetMinConfirmations(n uint64) {
+	eum.confMtx.Lock()
+	eum.minConfirms = n
+	eum.confMtx.Unlock()
+}
+
+// SetDepositHandler sets the func which will be called with each new utxo that
+// reaches the min confirmations.
+func (eum *ExUtxoManager) SetDepositHandler(fn func(Utxo)) {
+	eum.confMtx.Lock()
+	eum.onDeposit = fn
+	eum.confMtx.Unlock()
+}
+
+// SetReorgHandler sets the func which will be called with each confirmed utxo that
+// disappears from the unspent outputs without being spent by the manager, which means
+// its transaction is orphaned by a chain reorg. The utxo is removed from the pool.
+func (eum *ExUtxoManager) SetReorgHandler(fn func(Utxo)) {
+	eum.confMtx.Lock()
+	eum.onReorg = fn
+	eum.confMtx.Unlock()
+}
+
+// PendingDeposits returns the number of utxos found in last check, which are
+// waiting for the min confirmations.
+func (eum *ExUtxoManager) PendingDeposits() int {
+	eum.confMtx.RLock()
+	defer eum.confMtx.RUnlock()
+	return eum.pending
+}
+
+// Stats returns the state of the utxo pool.
+func (eum *ExUtxoManager) Stats() coin.UtxoStats {
+	eum.statsMtx.Lock()
+	defer eum.statsMtx.Unlock()
+	return coin.UtxoStats{
+		Available:  eum.available,
+		Borrowed:   len(eum.borrowed),
+		TotalValue: eum.value,
+		LastRefill: eum.lastRefill,
+	}
+}
+
+// putBack counts the utxo as available, it's no longer borrowed if it was.
+func (eum *ExUtxoManager) putBack(utxo Utxo) {
+	eum.statsMtx.Lock()
+	eum.available++
+	eum.value += utxo.GetAmount()
+	delete(eum.borrowed, utxoID(utxo))
+	eum.statsMtx.Unlock()
+}
+
+// borrow counts the utxos chosen by ChooseUtxos as borrowed.
+func (eum *ExUtxoManager) borrow(utxos []Utxo) {
+	eum.statsMtx.Lock()
+	defer eum.statsMtx.Unlock()
+	for _, u := range utxos {
+		eum.available--
+		eum.value -= u.GetAmount()
+		eum.borrowed[utxoID(u)] = u
+	}
+}
+
+// refilled records the time of checking new utxos, the borrowed utxos which are not in
+// the unspent outputs any more are spent, and no longer counted.
+func (eum *ExUtxoManager) refilled(unspent map[string]bool) {
+	eum.statsMtx.Lock()
+	defer eum.statsMtx.Unlock()
+	eum.lastRefill = time.Now().Unix()
+	for id := range eum.borrowed {
+		if !unspent[id] {
+			delete(eum.borrowed, id)
+		}
+	}
+}
+
+func utxoID(utxo Utxo) string {
+	return fmt.Sprintf("%s:%d", utxo.GetTxid(), utxo.GetVout())
+}
+
+func (eum *ExUtxoManager) checkNewUtxo() ([]Utxo, []Utxo, error) {
+	latestUtxos, err := eum.net.GetUnspentOutputs(eum.WatchAddress)
+	if err != nil {
+		return []Utxo{}, []Utxo{}, err
+	}
+
+	eum.confMtx.RLock()
+	minConfirms := eum.minConfirms
+	eum.confMtx.RUnlock()
+
+	var pending int
+	latestUxMap := make(map[string]Utxo)
+	unspent := make(map[string]bool, len(latestUtxos))
+	// do diff
+	for _, utxo := range latestUtxos {
+		unspent[utxoID(utxo)] = true
+		// the utxo is reported as new once it's confirmed enough.
+		if utxo.GetConfirmations() < minConfirms {
+			pending++
+			continue
+		}
+		latestUxMap[utxoID(utxo)] = utxo
+	}
+
+	//get new
+	newUtxos := []Utxo{}
+	for id, utxo := range latestUxMap {
+		old, ok := eum.UtxoStateMap[id]
+		if !ok {
+			newUtxos = append(newUtxos, utxo)
+			continue
+		}
+
+		// the transaction is mined again in another block.
+		if old.GetBlockHash() != "" && utxo.GetBlockHash() != "" && old.GetBlockHash() != utxo.GetBlockHash() {
+			sklog.Info(logger, "utxo moved by reorg", sklog.Fields{"coin": eum.net.Type, "txid": utxo.GetTxid(), "vout": utxo.GetVout(), "from": old.GetBlockHash(), "to": utxo.GetBlockHash()})
+		}
+	}
+
+	reorged := []Utxo{}
+	for id, utxo := range eum.UtxoStateMap {
+		if !unspent[id] && !eum.isBorrowed(id) {
+			reorged = append(reorged, utxo)
+		}
+	}
+	eum.removeFromPool(reorged)
+
+	eum.UtxoStateMap = latestUxMap
+	eum.refilled(unspent)
+	eum.confMtx.Lock()
+	eum.pending = pending
+	eum.confMtx.Unlock()
+	return newUtxos, reorged, nil
+}
+
+// isBorrowed returns true if the utxo of id is chosen and not yet put back or spent.
+func (eum *ExUtxoManager) isBorrowed(id string) bool {
+	eum.statsMtx.Lock()
+	defer eum.statsMtx.Unlock()
+	_, ok := eum.borrowed[id]
+	return ok
+}
+
+// removeFromPool takes the orphaned utxos out of the pool, they're counted as borrowed
+// until refilled drops them, for they're not unspent.
+func (eum *ExUtxoManager) removeFromPool(utxos []Utxo) {
+	for _, u := range utxos {
+		if _, err := eum.TakeUtxos([]string{utxoID(u)}); err != nil {
+			logger.Error("remove orphaned utxo %s from pool failed: %v", utxoID(u), err)
+		}
+	}
+}
+
+// chooseUtxos choose appropriate utxos, if time out, and not found enough utxos,
+// the utxos got before will put back to the utxos pool, and return error.
+// insufficient will be set once the pool is drained before sufficient utxos are chosen.
+func (eum *ExUtxoManager) chooseUtxos(amount uint64, tm time.Duration, insufficient *int32) ([]Utxo, error) {
+	sklog.Debug(logger, "choose utxos", sklog.Fields{"coin": eum.net.Type, "amount": amount})
+	pool, resized, release := eum.AcquirePool()
+	defer release()
+
+	var totalAmount uint64
+	utxos := []Utxo{}
+	for {
+		var utxo Utxo
+		select {
+		case u := <-pool:
+			utxo = u.(Utxo)
+		default:
+			// the pool is drained, but the chosen utxos are not sufficient.
+			if len(utxos) > 0 {
+				atomic.StoreInt32(insufficient, 1)
+			}
+
+			select {
+			case u := <-pool:
+				utxo = u.(Utxo)
+			case <-time.After(tm):
+				// put utxos back
+				logger.Debug("choose time out, put back utxos")
+				for _, u := range utxos {
+					pool <- u
+				}
+				if atomic.LoadInt32(insufficient) == 1 {
+					return []Utxo{}, coin.ErrInsufficientUtxo
+				}
+				return []Utxo{}, coin.ErrUtxoTimeout
+
+			case <-resized:
+				// put utxos back, they will be moved into the new pool.
+				logger.Debug("utxo pool resized, put back utxos")
+				for _, u := range utxos {
+					pool <- u
+				}
+				return []Utxo{}, nil
+			}
+		}
+
+		sklog.Debug(logger, "get utxo", sklog.Fields{"coin": eum.net.Type, "address": utxo.GetAddress(), "txid": utxo.GetTxid(), "amount": utxo.GetAmount()})
+		utxos = append(utxos, utxo)
+		totalAmount += utxo.GetAmount()
+		if totalAmount >= amount {
+			return utxos, nil
+		}
+	}
+}
+
+// ChooseUtxos choose sufficient utxos in specific time, returns coin.ErrInsufficientUtxo
+// if the utxo pool is drained before sufficient utxos are chosen, or coin.ErrUtxoTimeout
+// if no utxo is available.
+func (eum *ExUtxoManager) ChooseUtxos(amt uint64, tm time.Duration) ([]Utxo, error) {
+	var (
+		insufficient int32
+		closing      = make(chan bool)
+		done         = make(chan []Utxo)
+	)
+
+	go func() {
+		for {
+			utxos, err := eum.chooseUtxos(amt, randExpireTm(), &insufficient)
+			if err == nil && len(utxos) > 0 {
+				select {
+				case done <- utxos:
+				case <-closing:
+					for _, u := range utxos {
+						eum.Put(u)
+					}
+				}
+				return
+			}
+
+			select {
+			case <-closing:
+				return
+			default:
+			}
+		}
+	}()
+
+	select {
+	case utxos := <-done:
+		eum.borrow(utxos)
+		return utxos, nil
+	case <-time.After(tm):
+		close(closing)
+		if atomic.LoadInt32(&insufficient) == 1 {
+			return []Utxo{}, coin.ErrInsufficientUtxo
+		}
+		return []Utxo{}, coin.ErrUtxoTimeout
+	}
+}
+
+// TakeUtxos takes the utxos of ids in txid:vout out of the pool, they're returned in the
+// order of ids and counted as borrowed like the chosen ones. Returns error wrapping
+// coin.ErrUtxoReserved if any of them is borrowed, or error if it's not in the pool,
+// no utxo is taken if any of them is unavailable.
+func (eum *ExUtxoManager) TakeUtxos(ids []string) ([]Utxo, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("no utxo is specified")
+	}
+
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if want[id] {
+			return nil, fmt.Errorf("utxo %s is specified twice", id)
+		}
+		want[id] = true
+	}
+
+	if err := eum.checkReserved(ids); err != nil {
+		return nil, err
+	}
+
+	pool, _, release := eum.AcquirePool()
+	defer release()
+
+	taken := make(map[string]Utxo, len(ids))
+	rest := []Utxo{}
+	for n := len(pool); n > 0; n-- {
+		select {
+		case v := <-pool:
+			u := v.(Utxo)
+			if want[utxoID(u)] {
+				taken[utxoID(u)] = u
+			} else {
+				rest = append(rest, u)
+			}
+		default:
+		}
+	}
+
+	for _, u := range rest {
+		pool <- u
+	}
+
+	if len(taken) < len(ids) {
+		for _, u := range taken {
+			pool <- u
+		}
+
+		// the missing utxo may be chosen while the pool is being searched.
+		if err := eum.checkReserved(ids); err != nil {
+			return nil, err
+		}
+
+		for _, id := range ids {
+			if _, ok := taken[id]; !ok {
+				return nil, fmt.Errorf("utxo %s is not available in the wallet", id)
+			}
+		}
+	}
+
+	utxos := make([]Utxo, len(ids))
+	for i, id := range ids {
+		utxos[i] = taken[id]
+	}
+	eum.borrow(utxos)
+	return utxos, nil
+}
+
+// checkReserved returns error wrapping coin.ErrUtxoReserved if any utxo of ids is borrowed.
+func (eum *ExUtxoManager) checkReserved(ids []string) error {
+	eum.statsMtx.Lock()
+	defer eum.statsMtx.Unlock()
+	for _, id := range ids {
+		if _, ok := eum.borrowed[id]; ok {
+			return fmt.Errorf("%w: %s", coin.ErrUtxoReserved, id)
+		}
+	}
+	return nil
+}
+
+func randExpireTm() time.Duration {
+	v := rand.Intn(5)
+	return time.Duration(3+v) * time.Second
+}
@@ -0,0 +1,465 @@
+package insight
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeUtxos(n int, amt uint64) []Utxo {
+	uxs := make([]Utxo, n)
+	for i := range uxs {
+		uxs[i] = UnspentOutput{
+			Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+			Txid:    fmt.Sprintf("%064x", i),
+			Vout:    uint32(i),
+			Amount:  amt,
+		}
+	}
+	return uxs
+}
+
+func TestSetPoolSizeGrow(t *testing.T) {
+	um := NewUtxoManager(testNet, 2, []string{})
+	uxs := makeUtxos(4, 100)
+	um.PutUtxo(uxs[0])
+	um.PutUtxo(uxs[1])
+
+	assert.Nil(t, um.SetPoolSize(4))
+	size, available := um.PoolSize()
+	assert.Equal(t, 4, size)
+	assert.Equal(t, 2, available)
+
+	// the pool can hold all the utxos now.
+	done := make(chan bool)
+	go func() {
+		um.PutUtxo(uxs[2])
+		um.PutUtxo(uxs[3])
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("put utxo blocked after growing the pool")
+	}
+
+	utxos, err := um.ChooseUtxos(400, 10*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(utxos))
+}
+
+func TestSetPoolSizeShrink(t *testing.T) {
+	um := NewUtxoManager(testNet, 4, []string{})
+	for _, u := range makeUtxos(4, 100) {
+		um.PutUtxo(u)
+	}
+
+	shrunk := make(chan error)
+	go func() {
+		shrunk <- um.SetPoolSize(2)
+	}()
+
+	// the new pool can't hold the 4 utxos.
+	select {
+	case <-shrunk:
+		t.Fatal("shrink should wait for utxos being chosen")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	utxos, err := um.ChooseUtxos(200, 10*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(utxos))
+
+	select {
+	case err := <-shrunk:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("shrink blocked")
+	}
+
+	utxos, err = um.ChooseUtxos(200, 10*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(utxos))
+
+	assert.NotNil(t, um.SetPoolSize(0))
+}
+
+func TestSetPoolSizeInflightChoose(t *testing.T) {
+	um := NewUtxoManager(testNet, 4, []string{})
+	uxs := makeUtxos(3, 100)
+	um.PutUtxo(uxs[0])
+
+	type result struct {
+		utxos []Utxo
+		err   error
+	}
+	rlt := make(chan result)
+	go func() {
+		utxos, err := um.ChooseUtxos(300, 10*time.Second)
+		rlt <- result{utxos, err}
+	}()
+
+	// wait until the choose goroutine takes the utxo.
+	time.Sleep(100 * time.Millisecond)
+	assert.Nil(t, um.SetPoolSize(2))
+
+	um.PutUtxo(uxs[1])
+	um.PutUtxo(uxs[2])
+
+	select {
+	case r := <-rlt:
+		assert.Nil(t, r.err)
+		assert.Equal(t, 3, len(r.utxos))
+	case <-time.After(2 * time.Second):
+		t.Fatal("choose utxos blocked after resizing")
+	}
+}
+
+func TestSetPoolSizeConcurrent(t *testing.T) {
+	n := 20
+	um := NewUtxoManager(testNet, 5, []string{})
+	uxs := makeUtxos(n, 1)
+
+	var wg sync.WaitGroup
+	for _, u := range uxs {
+		wg.Add(1)
+		go func(u Utxo) {
+			defer wg.Done()
+			um.PutUtxo(u)
+		}(u)
+	}
+
+	chosen := make(chan Utxo, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			utxos, err := um.ChooseUtxos(1, 30*time.Second)
+			if !assert.Nil(t, err) {
+				return
+			}
+			for _, u := range utxos {
+				chosen <- u
+			}
+		}()
+	}
+
+	for _, size := range []int{1, 10, 3, n} {
+		assert.Nil(t, um.SetPoolSize(size))
+	}
+
+	wg.Wait()
+	close(chosen)
+
+	ids := make(map[string]bool)
+	for u := range chosen {
+		id := fmt.Sprintf("%s:%d", u.GetTxid(), u.GetVout())
+		assert.False(t, ids[id], "utxo %s chosen twice", id)
+		ids[id] = true
+	}
+	assert.Equal(t, n, len(ids))
+}
+
+func TestChooseUtxosTimeout(t *testing.T) {
+	um := NewUtxoManager(testNet, 2, []string{})
+	for _, u := range makeUtxos(2, 100) {
+		um.PutUtxo(u)
+	}
+
+	// drain the pool.
+	utxos, err := um.ChooseUtxos(200, 10*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(utxos))
+
+	_, err = um.ChooseUtxos(100, 200*time.Millisecond)
+	assert.True(t, errors.Is(err, coin.ErrUtxoTimeout))
+}
+
+func TestChooseUtxosInsufficient(t *testing.T) {
+	um := NewUtxoManager(testNet, 2, []string{})
+	um.PutUtxo(makeUtxos(1, 100)[0])
+
+	_, err := um.ChooseUtxos(300, 200*time.Millisecond)
+	assert.True(t, errors.Is(err, coin.ErrInsufficientUtxo))
+}
+
+func TestTakeUtxos(t *testing.T) {
+	um := NewUtxoManager(testNet, 4, []string{})
+	uxs := makeUtxos(4, 100)
+	for _, u := range uxs {
+		um.PutUtxo(u)
+	}
+
+	// the utxos are returned in the order of ids.
+	utxos, err := um.TakeUtxos([]string{utxoID(uxs[2]), utxoID(uxs[0])})
+	assert.Nil(t, err)
+	assert.Equal(t, []Utxo{uxs[2], uxs[0]}, utxos)
+	assert.Equal(t, coin.UtxoStats{Available: 2, Borrowed: 2, TotalValue: 200}, um.Stats())
+
+	// the taken utxo is reserved, nothing else is taken.
+	_, err = um.TakeUtxos([]string{utxoID(uxs[1]), utxoID(uxs[2])})
+	assert.True(t, errors.Is(err, coin.ErrUtxoReserved))
+	_, err = um.TakeUtxos([]string{utxoID(uxs[1]), "unknown:0"})
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, coin.ErrUtxoReserved))
+	_, err = um.TakeUtxos([]string{utxoID(uxs[1]), utxoID(uxs[1])})
+	assert.NotNil(t, err)
+	_, err = um.TakeUtxos(nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, coin.UtxoStats{Available: 2, Borrowed: 2, TotalValue: 200}, um.Stats())
+
+	// the rest utxos can still be chosen.
+	rest, err := um.ChooseUtxos(200, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(rest))
+
+	// the utxo put back can be taken again.
+	um.PutUtxo(utxos[0])
+	_, err = um.TakeUtxos([]string{utxoID(uxs[2])})
+	assert.Nil(t, err)
+}
+
+func TestUtxoStats(t *testing.T) {
+	var mtx sync.Mutex
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	// only the utxo of txid 0 is unspent.
+	srv := newConfirmsMock(addr, []uint64{1}, &mtx)
+	defer srv.Close()
+	defer withAPI(srv.URL)()
+
+	um := NewUtxoManager(testNet, 4, []string{addr})
+	uxs := makeUtxos(4, 100)
+	for _, u := range uxs {
+		um.PutUtxo(u)
+	}
+	assert.Equal(t, coin.UtxoStats{Available: 4, TotalValue: 400}, um.Stats())
+
+	utxos, err := um.ChooseUtxos(200, 10*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, uxs[:2], utxos)
+	assert.Equal(t, coin.UtxoStats{Available: 2, Borrowed: 2, TotalValue: 200}, um.Stats())
+
+	// the borrowed utxo not in the unspent outputs is spent.
+	_, _, err = um.(*ExUtxoManager).checkNewUtxo()
+	assert.Nil(t, err)
+	stats := um.Stats()
+	assert.Equal(t, 1, stats.Borrowed)
+	assert.True(t, stats.LastRefill > 0)
+
+	um.PutUtxo(utxos[0])
+	stats = um.Stats()
+	assert.Equal(t, 3, stats.Available)
+	assert.Equal(t, 0, stats.Borrowed)
+	assert.Equal(t, uint64(300), stats.TotalValue)
+
+	// the failed choosing borrows nothing.
+	_, err = um.ChooseUtxos(1000, 200*time.Millisecond)
+	assert.NotNil(t, err)
+	stats = um.Stats()
+	assert.Equal(t, 3, stats.Available)
+	assert.Equal(t, 0, stats.Borrowed)
+}
+
+// newConfirmsMock mocks the utxo api of insight, the utxo i has confirms[i] confirmations.
+func newConfirmsMock(addr string, confirms []uint64, mtx *sync.Mutex) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		us := []UnspentOutput{}
+		for i, c := range confirms {
+			us = append(us, UnspentOutput{
+				Address:  addr,
+				Txid:     fmt.Sprintf("%064x", i),
+				Amount:   100,
+				Confirms: c,
+			})
+		}
+		json.NewEncoder(w).Encode(us)
+	}))
+}
+
+func TestCheckNewUtxoConfirmations(t *testing.T) {
+	var mtx sync.Mutex
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	confirms := []uint64{0, 2, 3, 6}
+	srv := newConfirmsMock(addr, confirms, &mtx)
+	defer srv.Close()
+	defer withAPI(srv.URL)()
+
+	um := NewUtxoManager(testNet, 10, []string{addr}).(*ExUtxoManager)
+	um.SetMinConfirmations(3)
+
+	uxs, _, err := um.checkNewUtxo()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(uxs))
+	for _, u := range uxs {
+		assert.True(t, u.GetConfirmations() >= 3)
+	}
+	assert.Equal(t, 2, um.PendingDeposits())
+
+	// the utxos are reported once they are confirmed enough.
+	mtx.Lock()
+	confirms[0], confirms[1] = 1, 3
+	mtx.Unlock()
+	uxs, _, err = um.checkNewUtxo()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(uxs))
+	assert.Equal(t, fmt.Sprintf("%064x", 1), uxs[0].GetTxid())
+	assert.Equal(t, 1, um.PendingDeposits())
+
+	// all utxos are accepted without threshold.
+	um = NewUtxoManager(testNet, 10, []string{addr}).(*ExUtxoManager)
+	uxs, _, err = um.checkNewUtxo()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(uxs))
+}
+
+func TestDepositConfirmations(t *testing.T) {
+	var mtx sync.Mutex
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	srv := newConfirmsMock(addr, []uint64{0, 1, 6}, &mtx)
+	defer srv.Close()
+	defer withAPI(srv.URL)()
+
+	tick := CheckTick
+	CheckTick = 10 * time.Millisecond
+	defer func() { CheckTick = tick }()
+
+	um := NewUtxoManager(testNet, 10, []string{addr})
+	um.SetMinConfirmations(1)
+	deposits := make(chan Utxo, 10)
+	um.SetDepositHandler(func(u Utxo) { deposits <- u })
+
+	closing := make(chan bool)
+	done := make(chan bool)
+	go func() {
+		um.Start(closing)
+		close(done)
+	}()
+	// the scanning uses the api, wait for it to stop before the api is restored.
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case u := <-deposits:
+			assert.True(t, u.GetConfirmations() >= 1)
+		case <-time.After(5 * time.Second):
+			t.Fatal("deposit is not reported")
+		}
+	}
+
+	// only the confirmed utxos are spendable.
+	utxos, err := um.ChooseUtxos(200, 5*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(utxos))
+	_, err = um.ChooseUtxos(100, time.Second)
+	assert.NotNil(t, err)
+
+	select {
+	case u := <-deposits:
+		t.Fatalf("unconfirmed utxo %s is reported", u.GetTxid())
+	default:
+	}
+}
+
+func TestReorgUtxo(t *testing.T) {
+	var mtx sync.Mutex
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	us := []UnspentOutput{
+		{Address: addr, Txid: fmt.Sprintf("%064x", 0), Amount: 100, Confirms: 1, BlockHash: "block1"},
+		{Address: addr, Txid: fmt.Sprintf("%064x", 1), Amount: 200, Confirms: 1, BlockHash: "block1"},
+		{Address: addr, Txid: fmt.Sprintf("%064x", 2), Amount: 300, Confirms: 1, BlockHash: "block1"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		json.NewEncoder(w).Encode(us)
+	}))
+	defer srv.Close()
+	defer withAPI(srv.URL)()
+
+	tick := CheckTick
+	CheckTick = 10 * time.Millisecond
+	defer func() { CheckTick = tick }()
+
+	um := NewUtxoManager(testNet, 10, []string{addr})
+	deposits := make(chan Utxo, 10)
+	reorged := make(chan Utxo, 10)
+	um.SetDepositHandler(func(u Utxo) { deposits <- u })
+	um.SetReorgHandler(func(u Utxo) { reorged <- u })
+
+	closing := make(chan bool)
+	done := make(chan bool)
+	go func() {
+		um.Start(closing)
+		close(done)
+	}()
+	// the scanning uses the api, wait for it to stop before the api is restored.
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-deposits:
+		case <-time.After(5 * time.Second):
+			t.Fatal("deposit is not reported")
+		}
+	}
+
+	// the utxo 2 is chosen, it's spent rather than orphaned once it disappears.
+	spent, err := um.TakeUtxos([]string{utxoID(us[2])})
+	assert.Nil(t, err)
+
+	// the utxo 1 is orphaned, and the utxo 0 is mined again in another block.
+	mtx.Lock()
+	us = []UnspentOutput{us[0]}
+	us[0].BlockHash = "block2"
+	mtx.Unlock()
+
+	select {
+	case u := <-reorged:
+		assert.Equal(t, fmt.Sprintf("%064x", 1), u.GetTxid())
+		assert.Equal(t, uint64(200), u.GetAmount())
+		assert.Equal(t, "block1", u.GetBlockHash())
+	case <-time.After(5 * time.Second):
+		t.Fatal("reorg is not reported")
+	}
+
+	// wait for another check, nothing else is reported.
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case u := <-reorged:
+		t.Fatalf("utxo %s is reported as orphaned", u.GetTxid())
+	case u := <-deposits:
+		t.Fatalf("utxo %s is reported as deposit again", u.GetTxid())
+	default:
+	}
+
+	// the orphaned utxo is removed from pool, and the spent one is dropped.
+	assert.Equal(t, coin.UtxoStats{Available: 1, TotalValue: 100}, withoutRefill(um.Stats()))
+	utxos, err := um.ChooseUtxos(100, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(utxos))
+	assert.Equal(t, us[0].Txid, utxos[0].GetTxid())
+	assert.Equal(t, 1, len(spent))
+}
+
+// withoutRefill clears the time of last refill in stats.
+func withoutRefill(s coin.UtxoStats) coin.UtxoStats {
+	s.LastRefill = 0
+	return s
+}
@@ -0,0 +1,36 @@
+// get litecoin blockchain info through the insight api.
+package litecoin
+
+import "github.com/skycoin/skycoin-exchange/src/coin/insight"
+
+// InsightURL the insight api of litecoin, which is the same api used by bitcoin blockexplorer.com.
+var InsightURL = mainInsightURL
+
+// insightNet the litecoin network served by InsightURL.
+var insightNet = &insight.Network{
+	Type:         Type,
+	API:          func() string { return InsightURL },
+	ValidateAddr: ValidateAddr,
+}
+
+// GetUnspentOutputs return the unspent outputs of specific addresses.
+func GetUnspentOutputs(addrs []string) ([]Utxo, error) {
+	return insightNet.GetUnspentOutputs(addrs)
+}
+
+// BroadcastAPIs the insight apis the transactions are broadcast through, tried in order until
+// one accepts the transaction, empty uses InsightURL.
+var BroadcastAPIs []string
+
+func broadcastAPIs() []string {
+	if len(BroadcastAPIs) > 0 {
+		return BroadcastAPIs
+	}
+	return []string{InsightURL}
+}
+
+// BroadcastTx broadcast the raw transaction through the insight apis in BroadcastAPIs, the next
+// one is used if the api is down, the transaction rejected by the node is not sent again.
+func BroadcastTx(rawtx string) (string, error) {
+	return insight.BroadcastTx(broadcastAPIs(), rawtx)
+}
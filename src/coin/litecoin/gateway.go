@@ -0,0 +1,232 @@
+package litecoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	"github.com/skycoin/skycoin-exchange/src/pp"
+)
+
+// Litecoin implements the interface of coin.Gateway.
+type Litecoin struct{}
+
+// New creates a litecoin instance.
+func New() *Litecoin {
+	return &Litecoin{}
+}
+
+// GetTx get litecoin transaction of specific txid.
+func (ltc Litecoin) GetTx(txid string) (*pp.Tx, error) {
+	return insightNet.GetTxVerbose(txid)
+}
+
+// GetRawTx get litecoin raw transaction of specific txid.
+func (ltc Litecoin) GetRawTx(txid string) (string, error) {
+	return insightNet.GetRawTx(txid)
+}
+
+// InjectTx inject litecoin raw transaction.
+func (ltc Litecoin) InjectTx(rawtx string) (string, error) {
+	return BroadcastTx(rawtx)
+}
+
+// GetBalance get balance of specific addresses.
+func (ltc Litecoin) GetBalance(addrs []string) (pp.Balance, error) {
+	v, err := insightNet.GetBalance(addrs)
+	if err != nil {
+		return pp.Balance{}, err
+	}
+	return pp.Balance{Amount: pp.PtrUint64(v)}, nil
+}
+
+// CreateRawTx create litecoin raw transaction, the txOuts must be []bitcoin.TxOut.
+func (ltc Litecoin) CreateRawTx(txIns []coin.TxIn, txOuts interface{}) (string, error) {
+	tx := wire.NewMsgTx()
+	for _, in := range txIns {
+		txid, err := chainhash.NewHashFromStr(in.Txid)
+		if err != nil {
+			return "", err
+		}
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(txid, in.Vout), []byte{}))
+	}
+
+	s := reflect.ValueOf(txOuts)
+	if s.Kind() != reflect.Slice {
+		return "", errors.New("error tx out type")
+	}
+
+	if s.Len() == 0 {
+		return "", errors.New("no output address")
+	}
+
+	for i := 0; i < s.Len(); i++ {
+		out, ok := s.Index(i).Interface().(bitcoin.TxOut)
+		if !ok {
+			return "", errors.New("error tx out type")
+		}
+		if err := ValidateAddr(out.Addr); err != nil {
+			return "", err
+		}
+		addr, err := btcutil.DecodeAddress(out.Addr, NetParams())
+		if err != nil {
+			return "", err
+		}
+		script, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return "", err
+		}
+		tx.AddTxOut(wire.NewTxOut(int64(out.Value), script))
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if err := tx.Serialize(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// SignRawTx sign litecoin transaction.
+func (ltc Litecoin) SignRawTx(rawtx string, getKey coin.GetPrivKey) (string, error) {
+	d, err := hex.DecodeString(rawtx)
+	if err != nil {
+		return "", err
+	}
+
+	tx := wire.MsgTx{}
+	if err := tx.Deserialize(bytes.NewBuffer(d)); err != nil {
+		return "", err
+	}
+
+	for i, in := range tx.TxIn {
+		// get scriptPubkey and addr of the previous output.
+		vt, err := insightNet.GetTxVerbose(in.PreviousOutPoint.Hash.String())
+		if err != nil {
+			return "", err
+		}
+		outs := vt.GetBtc().GetVout()
+		index := in.PreviousOutPoint.Index
+		if int(index) >= len(outs) {
+			return "", errors.New("error rawtx")
+		}
+		addrs := outs[index].GetScriptPubkey().GetAddresses()
+		if len(addrs) == 0 {
+			return "", fmt.Errorf("no address in output %s:%d", in.PreviousOutPoint.Hash, index)
+		}
+		sp, err := hex.DecodeString(outs[index].GetScriptPubkey().GetHex())
+		if err != nil {
+			return "", err
+		}
+
+		key, err := getKey(addrs[0])
+		if err != nil {
+			return "", err
+		}
+
+		wif, err := btcutil.DecodeWIF(key)
+		if err != nil {
+			return "", err
+		}
+
+		sig, err := txscript.SignatureScript(&tx, i, sp, txscript.SigHashAll, wif.PrivKey, true)
+		if err != nil {
+			return "", err
+		}
+		tx.TxIn[i].SignatureScript = sig
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if err := tx.Serialize(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// ValidateTxid check if the litecoin transaction id is validated.
+func (ltc Litecoin) ValidateTxid(txid string) bool {
+	if len(txid) != 64 {
+		return false
+	}
+
+	_, err := hex.DecodeString(txid)
+	return err == nil
+}
+
+// EstimateFee estimates litecoin transaction fee, the transaction size is the same as bitcoin.
+func (ltc Litecoin) EstimateFee(nInputs, nOutputs int) (uint64, error) {
+	size, err := bitcoin.EstimateTxSize(nInputs, nOutputs)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(size) * FeeRate, nil
+}
+
+// GetFeeEstimates returns the static FeeRate for all the confirmation targets.
+func (ltc Litecoin) GetFeeEstimates() ([]coin.FeeEstimate, error) {
+	return coin.StaticFeeEstimates(FeeRate), nil
+}
+
+// GetUtxos gets litecoin utxos of specific addresses.
+func (ltc Litecoin) GetUtxos(addrs []string) (interface{}, error) {
+	utxos, err := GetUnspentOutputs(addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	uxs := make([]*pp.BtcUtxo, len(utxos))
+	for i, u := range utxos {
+		uxs[i] = &pp.BtcUtxo{
+			Address: pp.PtrString(u.GetAddress()),
+			Txid:    pp.PtrString(u.GetTxid()),
+			Vout:    pp.PtrUint32(u.GetVout()),
+			Amount:  pp.PtrUint64(u.GetAmount()),
+		}
+	}
+
+	var res = pp.GetUtxoRes{
+		Result:   pp.MakeResultWithCode(pp.ErrCode_Success),
+		BtcUtxos: uxs,
+	}
+	return res, nil
+}
+
+// GetAddressTxs gets litecoin transactions of specific addresses.
+func (ltc Litecoin) GetAddressTxs(addrs []string) ([]*pp.Tx, error) {
+	return insightNet.GetAddressTxs(addrs)
+}
+
+// GetOutput not implemented yet.
+func (ltc Litecoin) GetOutput(hash string) (interface{}, error) {
+	return nil, errors.New("get output by hash is not supported by litecoin")
+}
+
+// Symbol returns litecoin symbol.
+func (ltc Litecoin) Symbol() string {
+	return "LTC"
+}
+
+// Type returns litecoin type.
+func (ltc Litecoin) Type() string {
+	return Type
+}
+
+// Decimals returns the decimal places of litecoin, the amounts are in litoshis.
+func (ltc Litecoin) Decimals() int {
+	return 8
+}
+
+// HealthCheck checks if the insight api of litecoin is available.
+func (ltc Litecoin) HealthCheck() (bool, error) {
+	if err := insightNet.HealthCheck(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,153 @@
+package litecoin
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	logging "github.com/op/go-logging"
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/coin/insight"
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+var (
+	HideSeckey = false
+	logger     = logging.MustGetLogger("exchange.litecoin")
+	// Type represents litecoin coin type
+	Type = "litecoin"
+	// FeeRate transaction fee rate in litoshis per byte, which is 0.001 LTC per kilobyte.
+	FeeRate uint64 = 100
+)
+
+// Utxo unspent output, litecoin shares the utxo model of bitcoin.
+type Utxo = insight.Utxo
+
+// MainNetParams litecoin main network params, the address and private key
+// version bytes are different from bitcoin.
+var MainNetParams = chaincfg.Params{
+	Name:             "litecoin-mainnet",
+	Net:              wire.BitcoinNet(0xdbb6c0fb),
+	DefaultPort:      "9333",
+	PubKeyHashAddrID: 0x30,                            // starts with L
+	ScriptHashAddrID: 0x32,                            // starts with M
+	PrivateKeyID:     0xb0,                            // starts with 6 (uncompressed) or T (compressed)
+	HDPrivateKeyID:   [4]byte{0x01, 0x9d, 0x9c, 0xfe}, // starts with Ltpv
+	HDPublicKeyID:    [4]byte{0x01, 0x9d, 0xa4, 0x62}, // starts with Ltub
+	HDCoinType:       2,
+}
+
+// TestNetParams litecoin testnet4 params, the pubkey hash addresses share the version
+// byte with bitcoin testnet.
+var TestNetParams = chaincfg.Params{
+	Name:             "litecoin-testnet4",
+	Net:              wire.BitcoinNet(0xf1c8d2fd),
+	DefaultPort:      "19335",
+	PubKeyHashAddrID: 0x6f,                            // starts with m or n
+	ScriptHashAddrID: 0x3a,                            // starts with Q
+	PrivateKeyID:     0xef,                            // starts with 9 (uncompressed) or c (compressed)
+	HDPrivateKeyID:   [4]byte{0x04, 0x35, 0x83, 0x94}, // starts with tprv
+	HDPublicKeyID:    [4]byte{0x04, 0x35, 0x87, 0xcf}, // starts with tpub
+	HDCoinType:       1,
+}
+
+// the insight apis of mainnet and testnet4.
+const (
+	mainInsightURL = "https://insight.litecore.io/api"
+	testInsightURL = "https://testnet.litecore.io/api"
+)
+
+var testnet bool
+
+func init() {
+	// register the params, so that btcutil can decode the litecoin addresses.
+	if err := chaincfg.Register(&MainNetParams); err != nil {
+		panic(err)
+	}
+
+	if err := chaincfg.Register(&TestNetParams); err != nil {
+		panic(err)
+	}
+}
+
+// SetTestnet switches the package between litecoin mainnet and testnet4, InsightURL is
+// reset to the api of the network. It should be called before any address is generated
+// or validated.
+func SetTestnet(t bool) {
+	testnet = t
+	if t {
+		InsightURL = testInsightURL
+		return
+	}
+	InsightURL = mainInsightURL
+}
+
+// IsTestnet returns true if the package works on litecoin testnet4.
+func IsTestnet() bool {
+	return testnet
+}
+
+// NetParams returns the chain params of the current network.
+func NetParams() *chaincfg.Params {
+	if testnet {
+		return &TestNetParams
+	}
+	return &MainNetParams
+}
+
+// GenerateAddresses generates litecoin addresses of the current network.
+func GenerateAddresses(seed []byte, num int) (string, []coin.AddressEntry) {
+	sd, seckeys := cipher.GenerateDeterministicKeyPairsSeed(seed, num)
+	entries := make([]coin.AddressEntry, num)
+	for i, sec := range seckeys {
+		addr, pub, wif, err := keysFromSeckey(sec[:])
+		if err != nil {
+			panic(err)
+		}
+		entries[i].Address = addr
+		entries[i].Public = pub
+		if !HideSeckey {
+			entries[i].Secret = wif
+		}
+	}
+	return fmt.Sprintf("%2x", sd), entries
+}
+
+// keysFromSeckey returns the address, hex encoded compressed pubkey and
+// wallet import format of the secret key.
+func keysFromSeckey(sec []byte) (string, string, string, error) {
+	privKey, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), sec)
+	pub := pubKey.SerializeCompressed()
+	addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(pub), NetParams())
+	if err != nil {
+		return "", "", "", err
+	}
+
+	wif, err := btcutil.NewWIF(privKey, NetParams(), true)
+	if err != nil {
+		return "", "", "", err
+	}
+	return addr.EncodeAddress(), fmt.Sprintf("%x", pub), wif.String(), nil
+}
+
+// ValidateAddr check if the litecoin address of the current network is valid,
+// bitcoin mainnet addresses are rejected.
+func ValidateAddr(addr string) error {
+	a, err := btcutil.DecodeAddress(addr, NetParams())
+	if err != nil {
+		return err
+	}
+
+	switch a.(type) {
+	case *btcutil.AddressPubKeyHash, *btcutil.AddressScriptHash:
+		if !a.IsForNet(NetParams()) {
+			return fmt.Errorf("%s is not litecoin address", addr)
+		}
+		return nil
+	default:
+		return errors.New("unknow litecoin address type")
+	}
+}
@@ -0,0 +1,173 @@
+package litecoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAddr(t *testing.T) {
+	testData := []struct {
+		Addr  string
+		Valid bool
+	}{
+		{"LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", true},
+		{"LW98ceYNxYki9e9QxDACLn82TtVEPm4qmy", true},
+		{"LUEweDxDA4WhvWiNXXSxjM9CYzHPJv4QQF", true},
+		{"MQMHBtvnBfxTzt3K2bdxgSE7qZPHSXWsGM", true},
+		{"MGv9cSYnaRSTZNzYaN7bhbgmozoGkKBvCn", true},
+		// invalid checksum.
+		{"LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnK", false},
+		// bitcoin addresses.
+		{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", false},
+		{"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", false},
+		// skycoin address.
+		{"fyqX5YuwXMUs4GEUE3LjLyhrqvNztFHQ4B", false},
+		// pubkey.
+		{"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", false},
+		{"", false},
+	}
+
+	for _, d := range testData {
+		err := ValidateAddr(d.Addr)
+		if d.Valid {
+			assert.Nil(t, err, d.Addr)
+		} else {
+			assert.NotNil(t, err, d.Addr)
+		}
+	}
+}
+
+func TestKeysFromSeckey(t *testing.T) {
+	sec, err := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000001")
+	assert.Nil(t, err)
+	addr, pub, wif, err := keysFromSeckey(sec)
+	assert.Nil(t, err)
+	assert.Equal(t, "LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", addr)
+	assert.Equal(t, "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", pub)
+	assert.Equal(t, "T33ydQRKp4FCW5LCLLUB7deioUMoveiwekdwUwyfRDeGZm76aUjV", wif)
+	assert.Nil(t, ValidateAddr(addr))
+}
+
+func TestTestnet(t *testing.T) {
+	defer SetTestnet(false)
+
+	// the keys of testnet4 share the formats of bitcoin testnet.
+	sec, err := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000001")
+	assert.Nil(t, err)
+	SetTestnet(true)
+	assert.True(t, IsTestnet())
+	assert.Equal(t, testInsightURL, InsightURL)
+	addr, _, wif, err := keysFromSeckey(sec)
+	assert.Nil(t, err)
+	assert.Equal(t, "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r", addr)
+	assert.Equal(t, "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA", wif)
+
+	testnetAddrs := []string{addr, "QXHFfTBKYXjaaTH1e7Rox8CcdNPGHVhM59"}
+	mainnetAddrs := []string{"LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", "MQMHBtvnBfxTzt3K2bdxgSE7qZPHSXWsGM"}
+	for _, a := range testnetAddrs {
+		assert.Nil(t, ValidateAddr(a), a)
+	}
+	for _, a := range mainnetAddrs {
+		assert.NotNil(t, ValidateAddr(a), a)
+	}
+
+	SetTestnet(false)
+	assert.Equal(t, mainInsightURL, InsightURL)
+	for _, a := range testnetAddrs {
+		assert.NotNil(t, ValidateAddr(a), a)
+	}
+	for _, a := range mainnetAddrs {
+		assert.Nil(t, ValidateAddr(a), a)
+	}
+}
+
+func TestValidateTxid(t *testing.T) {
+	ltc := Litecoin{}
+	assert.True(t, ltc.ValidateTxid("5b9e14fd7e5bb9a4b9a3ab40e3e2a0d9e3e5d2bfb9cba4c1c41d6a6ae2a0f0c1"))
+	assert.False(t, ltc.ValidateTxid("5b9e14fd7e5bb9a4b9a3ab40e3e2a0d9e3e5d2bfb9cba4c1c41d6a6ae2a0f0c"))
+	assert.False(t, ltc.ValidateTxid("zb9e14fd7e5bb9a4b9a3ab40e3e2a0d9e3e5d2bfb9cba4c1c41d6a6ae2a0f0c1"))
+}
+
+func TestCreateRawTx(t *testing.T) {
+	ltc := Litecoin{}
+	txIns := []coin.TxIn{
+		{Txid: "5b9e14fd7e5bb9a4b9a3ab40e3e2a0d9e3e5d2bfb9cba4c1c41d6a6ae2a0f0c1", Vout: 1},
+	}
+	txOuts := []bitcoin.TxOut{
+		{Addr: "LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", Value: 100000},
+		{Addr: "MQMHBtvnBfxTzt3K2bdxgSE7qZPHSXWsGM", Value: 2000},
+	}
+	rawtx, err := ltc.CreateRawTx(txIns, txOuts)
+	assert.Nil(t, err)
+
+	d, err := hex.DecodeString(rawtx)
+	assert.Nil(t, err)
+	tx := wire.MsgTx{}
+	assert.Nil(t, tx.Deserialize(bytes.NewBuffer(d)))
+	assert.Equal(t, 1, len(tx.TxIn))
+	assert.Equal(t, txIns[0].Txid, tx.TxIn[0].PreviousOutPoint.Hash.String())
+	assert.Equal(t, uint32(1), tx.TxIn[0].PreviousOutPoint.Index)
+	assert.Equal(t, 2, len(tx.TxOut))
+	assert.Equal(t, int64(100000), tx.TxOut[0].Value)
+	assert.Equal(t, int64(2000), tx.TxOut[1].Value)
+
+	// bitcoin address is not allowed.
+	_, err = ltc.CreateRawTx(txIns, []bitcoin.TxOut{{Addr: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", Value: 1}})
+	assert.NotNil(t, err)
+}
+
+func TestEstimateFee(t *testing.T) {
+	ltc := New()
+	fee, err := ltc.EstimateFee(2, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(374)*FeeRate, fee)
+
+	_, err = ltc.EstimateFee(0, 2)
+	assert.NotNil(t, err)
+}
+
+func TestBroadcastTxFailover(t *testing.T) {
+	var tried []string
+	newAPI := func(name string, status int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tried = append(tried, name)
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+		}))
+	}
+	broken := newAPI("broken", http.StatusBadGateway, "bad gateway")
+	defer broken.Close()
+	good := newAPI("good", http.StatusOK, `{"txid":"abc"}`)
+	defer good.Close()
+	rejecting := newAPI("rejecting", http.StatusBadRequest, "bad-txns-inputs-spent")
+	defer rejecting.Close()
+	defer func() { BroadcastAPIs = nil }()
+
+	// the first api fails, the second accepts it.
+	BroadcastAPIs = []string{broken.URL, good.URL}
+	txid, err := BroadcastTx("0100")
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", txid)
+	assert.Equal(t, []string{"broken", "good"}, tried)
+
+	// the rejected transaction is not sent to the next api.
+	tried = nil
+	BroadcastAPIs = []string{rejecting.URL, good.URL}
+	_, err = BroadcastTx("0100")
+	assert.True(t, errors.Is(err, coin.ErrTxRejected))
+	assert.Equal(t, []string{"rejecting"}, tried)
+
+	// InsightURL is used if none is set.
+	BroadcastAPIs = nil
+	assert.Equal(t, []string{InsightURL}, broadcastAPIs())
+}
@@ -0,0 +1,11 @@
+package litecoin
+
+import "github.com/skycoin/skycoin-exchange/src/coin/insight"
+
+// UtxoManager litecoin utxo manager, which is shared with bitcoin.
+type UtxoManager = insight.UtxoManager
+
+// NewUtxoManager creates litecoin utxo manager.
+func NewUtxoManager(utxoPoolsize int, watchAddrs []string) UtxoManager {
+	return insight.NewUtxoManager(insightNet, utxoPoolsize, watchAddrs)
+}
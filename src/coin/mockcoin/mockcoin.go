@@ -0,0 +1,352 @@
+// Package mockcoin provides an in-memory coin.Gateway for tests, the balances, transactions
+// and fees are set by the test, and the calls can be forced to fail, so the server and
+// clients can be tested without a node.
+package mockcoin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/pp"
+)
+
+// ErrNotFound the transaction or output is not known by the gateway.
+var ErrNotFound = errors.New("not found")
+
+var (
+	_ coin.Gateway          = (*Gateway)(nil)
+	_ coin.DetailedBalancer = (*Gateway)(nil)
+)
+
+// Gateway the in-memory coin gateway, it's safe for concurrent use.
+// The created raw transaction is `raw:` followed by the inputs, signing prefixes it with
+// `signed:`, and the txid of the injected transaction is the hex encoded sha256 of it.
+type Gateway struct {
+	mtx         sync.Mutex
+	tp          string
+	symbol      string
+	decimals    int
+	fee         uint64
+	feeRate     uint64
+	balances    map[string]uint64 // confirmed balance of each address.
+	unconfirmed map[string]uint64 // unconfirmed balance of each address.
+	outputs     map[string]interface{}
+	utxos       interface{}
+	txs         map[string]*pp.Tx
+	rawTxs      map[string]string
+	addrTxs     map[string][]string // txids of each address in the order of adding.
+	injected    []string            // raw transactions injected successfully.
+	calls       map[string]int      // number of calls of each method.
+	failures    map[string]*failure // forced failures of each method.
+}
+
+// failure the method fails with err for the next n calls, or every call if n < 0.
+type failure struct {
+	err error
+	n   int
+}
+
+// New creates the gateway of coin type tp, like bitcoin, with the symbol and decimals.
+func New(tp, symbol string, decimals int) *Gateway {
+	return &Gateway{
+		tp:          tp,
+		symbol:      symbol,
+		decimals:    decimals,
+		balances:    make(map[string]uint64),
+		unconfirmed: make(map[string]uint64),
+		outputs:     make(map[string]interface{}),
+		txs:         make(map[string]*pp.Tx),
+		rawTxs:      make(map[string]string),
+		addrTxs:     make(map[string][]string),
+		calls:       make(map[string]int),
+		failures:    make(map[string]*failure),
+	}
+}
+
+// SetDecimals sets the number of decimal places returned by Decimals.
+func (g *Gateway) SetDecimals(n int) {
+	g.mtx.Lock()
+	g.decimals = n
+	g.mtx.Unlock()
+}
+
+// SetBalance sets the confirmed and unconfirmed balance of the address.
+func (g *Gateway) SetBalance(addr string, confirmed, unconfirmed uint64) {
+	g.mtx.Lock()
+	g.balances[addr] = confirmed
+	g.unconfirmed[addr] = unconfirmed
+	g.mtx.Unlock()
+}
+
+// SetFee sets the fee returned by EstimateFee, and the rate returned by GetFeeEstimates.
+func (g *Gateway) SetFee(fee, rate uint64) {
+	g.mtx.Lock()
+	g.fee = fee
+	g.feeRate = rate
+	g.mtx.Unlock()
+}
+
+// SetOutput sets the output of hash returned by GetOutput.
+func (g *Gateway) SetOutput(hash string, out interface{}) {
+	g.mtx.Lock()
+	g.outputs[hash] = out
+	g.mtx.Unlock()
+}
+
+// SetUtxos sets the utxos returned by GetUtxos.
+func (g *Gateway) SetUtxos(utxos interface{}) {
+	g.mtx.Lock()
+	g.utxos = utxos
+	g.mtx.Unlock()
+}
+
+// AddTx adds the transaction and its raw transaction, it's relevant to addrs.
+func (g *Gateway) AddTx(txid string, tx *pp.Tx, rawtx string, addrs ...string) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	g.txs[txid] = tx
+	g.rawTxs[txid] = rawtx
+	for _, a := range addrs {
+		g.addrTxs[a] = append(g.addrTxs[a], txid)
+	}
+}
+
+// Fail makes the next n calls of method, like InjectTx, fail with err, every call fails if n < 0.
+// A nil err clears the failure.
+func (g *Gateway) Fail(method string, err error, n int) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err == nil || n == 0 {
+		delete(g.failures, method)
+		return
+	}
+	g.failures[method] = &failure{err: err, n: n}
+}
+
+// Calls returns the number of calls of method, including the failed ones.
+func (g *Gateway) Calls(method string) int {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	return g.calls[method]
+}
+
+// Injected returns the raw transactions injected successfully.
+func (g *Gateway) Injected() []string {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	return append([]string{}, g.injected...)
+}
+
+// call records the call of method, and returns its forced failure, g.mtx must be held.
+func (g *Gateway) call(method string) error {
+	g.calls[method]++
+	f, ok := g.failures[method]
+	if !ok {
+		return nil
+	}
+	if f.n > 0 {
+		f.n--
+		if f.n == 0 {
+			delete(g.failures, method)
+		}
+	}
+	return f.err
+}
+
+// Symbol returns the coin symbol.
+func (g *Gateway) Symbol() string {
+	return g.symbol
+}
+
+// Type returns the coin type.
+func (g *Gateway) Type() string {
+	return g.tp
+}
+
+// Decimals returns the number of decimal places of the coin.
+func (g *Gateway) Decimals() int {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	return g.decimals
+}
+
+// GetBalance returns the total confirmed balance of the addresses.
+func (g *Gateway) GetBalance(addrs []string) (pp.Balance, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("GetBalance"); err != nil {
+		return pp.Balance{}, err
+	}
+	var amt uint64
+	for _, a := range addrs {
+		amt += g.balances[a]
+	}
+	return pp.Balance{Amount: pp.PtrUint64(amt)}, nil
+}
+
+// GetDetailedBalance returns the total confirmed and unconfirmed balance of the addresses.
+func (g *Gateway) GetDetailedBalance(addrs []string) (coin.DetailedBalance, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("GetDetailedBalance"); err != nil {
+		return coin.DetailedBalance{}, err
+	}
+	var bal coin.DetailedBalance
+	for _, a := range addrs {
+		bal.Confirmed += g.balances[a]
+		bal.Unconfirmed += g.unconfirmed[a]
+	}
+	bal.Total = bal.Confirmed + bal.Unconfirmed
+	return bal, nil
+}
+
+// GetOutput returns the output set by SetOutput.
+func (g *Gateway) GetOutput(hash string) (interface{}, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("GetOutput"); err != nil {
+		return nil, err
+	}
+	out, ok := g.outputs[hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return out, nil
+}
+
+// GetUtxos returns the utxos set by SetUtxos.
+func (g *Gateway) GetUtxos(addrs []string) (interface{}, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("GetUtxos"); err != nil {
+		return nil, err
+	}
+	return g.utxos, nil
+}
+
+// GetAddressTxs returns the transactions relevant to the addresses, each transaction once.
+func (g *Gateway) GetAddressTxs(addrs []string) ([]*pp.Tx, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("GetAddressTxs"); err != nil {
+		return nil, err
+	}
+	txs := []*pp.Tx{}
+	seen := make(map[string]bool)
+	for _, a := range addrs {
+		for _, txid := range g.addrTxs[a] {
+			if !seen[txid] {
+				seen[txid] = true
+				txs = append(txs, g.txs[txid])
+			}
+		}
+	}
+	return txs, nil
+}
+
+// HealthCheck returns true unless it's forced to fail.
+func (g *Gateway) HealthCheck() (bool, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("HealthCheck"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetTx returns the transaction added by AddTx.
+func (g *Gateway) GetTx(txid string) (*pp.Tx, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("GetTx"); err != nil {
+		return nil, err
+	}
+	tx, ok := g.txs[txid]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return tx, nil
+}
+
+// GetRawTx returns the raw transaction added by AddTx or injected.
+func (g *Gateway) GetRawTx(txid string) (string, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("GetRawTx"); err != nil {
+		return "", err
+	}
+	rawtx, ok := g.rawTxs[txid]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return rawtx, nil
+}
+
+// InjectTx records the raw transaction, and returns its txid.
+func (g *Gateway) InjectTx(rawtx string) (string, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("InjectTx"); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(rawtx))
+	txid := hex.EncodeToString(sum[:])
+	g.rawTxs[txid] = rawtx
+	g.injected = append(g.injected, rawtx)
+	return txid, nil
+}
+
+// CreateRawTx returns the raw transaction of the inputs, the outputs are not encoded.
+func (g *Gateway) CreateRawTx(txIns []coin.TxIn, txOuts interface{}) (string, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("CreateRawTx"); err != nil {
+		return "", err
+	}
+	ins := make([]string, len(txIns))
+	for i, in := range txIns {
+		ins[i] = fmt.Sprintf("%s:%d", in.Txid, in.Vout)
+	}
+	return "raw:" + strings.Join(ins, ","), nil
+}
+
+// SignRawTx returns the signed transaction, the keys are not used.
+func (g *Gateway) SignRawTx(rawtx string, getKey coin.GetPrivKey) (string, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("SignRawTx"); err != nil {
+		return "", err
+	}
+	return "signed:" + rawtx, nil
+}
+
+// ValidateTxid checks if the txid is the hex encoded 32 bytes.
+func (g *Gateway) ValidateTxid(txid string) bool {
+	b, err := hex.DecodeString(txid)
+	return err == nil && len(b) == 32
+}
+
+// EstimateFee returns the fee set by SetFee.
+func (g *Gateway) EstimateFee(nInputs, nOutputs int) (uint64, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("EstimateFee"); err != nil {
+		return 0, err
+	}
+	return g.fee, nil
+}
+
+// GetFeeEstimates returns the rate set by SetFee for all of coin.FeeTargets.
+func (g *Gateway) GetFeeEstimates() ([]coin.FeeEstimate, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if err := g.call("GetFeeEstimates"); err != nil {
+		return nil, err
+	}
+	return coin.StaticFeeEstimates(g.feeRate), nil
+}
@@ -0,0 +1,112 @@
+package mockcoin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalance(t *testing.T) {
+	g := New("bitcoin", "BTC", 8)
+	assert.Equal(t, "bitcoin", g.Type())
+	assert.Equal(t, "BTC", g.Symbol())
+	assert.Equal(t, 8, g.Decimals())
+	g.SetDecimals(6)
+	assert.Equal(t, 6, g.Decimals())
+
+	g.SetBalance("a", 100, 10)
+	g.SetBalance("b", 50, 0)
+	bal, err := g.GetBalance([]string{"a", "b", "c"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(150), bal.GetAmount())
+
+	db, err := g.GetDetailedBalance([]string{"a", "b"})
+	assert.Nil(t, err)
+	assert.Equal(t, coin.DetailedBalance{Confirmed: 150, Unconfirmed: 10, Total: 160}, db)
+}
+
+func TestTxs(t *testing.T) {
+	g := New("skycoin", "SKY", 6)
+	tx := &pp.Tx{Sky: &pp.SkyTx{}}
+	g.AddTx("tx1", tx, "rawtx1", "a", "b")
+	g.AddTx("tx2", &pp.Tx{}, "rawtx2", "b")
+
+	got, err := g.GetTx("tx1")
+	assert.Nil(t, err)
+	assert.Equal(t, tx, got)
+	_, err = g.GetTx("unknown")
+	assert.Equal(t, ErrNotFound, err)
+
+	rawtx, err := g.GetRawTx("tx2")
+	assert.Nil(t, err)
+	assert.Equal(t, "rawtx2", rawtx)
+
+	// the transaction relevant to both addresses is returned once.
+	txs, err := g.GetAddressTxs([]string{"a", "b"})
+	assert.Nil(t, err)
+	assert.Len(t, txs, 2)
+}
+
+func TestSend(t *testing.T) {
+	g := New("bitcoin", "BTC", 8)
+	g.SetFee(1000, 20)
+	fee, err := g.EstimateFee(1, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1000), fee)
+	fes, err := g.GetFeeEstimates()
+	assert.Nil(t, err)
+	assert.Equal(t, coin.StaticFeeEstimates(20), fes)
+
+	rawtx, err := g.CreateRawTx([]coin.TxIn{{Txid: "t1", Vout: 0}, {Txid: "t2", Vout: 1}}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "raw:t1:0,t2:1", rawtx)
+	signed, err := g.SignRawTx(rawtx, nil)
+	assert.Nil(t, err)
+
+	txid, err := g.InjectTx(signed)
+	assert.Nil(t, err)
+	assert.True(t, g.ValidateTxid(txid))
+	assert.False(t, g.ValidateTxid("txid"))
+	assert.Equal(t, []string{signed}, g.Injected())
+
+	// the injected transaction can be queried.
+	got, err := g.GetRawTx(txid)
+	assert.Nil(t, err)
+	assert.Equal(t, signed, got)
+}
+
+func TestFail(t *testing.T) {
+	g := New("bitcoin", "BTC", 8)
+	errBroadcast := errors.New("broadcast failed")
+
+	// the next 2 calls fail.
+	g.Fail("InjectTx", errBroadcast, 2)
+	for i := 0; i < 2; i++ {
+		_, err := g.InjectTx("rawtx")
+		assert.Equal(t, errBroadcast, err)
+	}
+	_, err := g.InjectTx("rawtx")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, g.Calls("InjectTx"))
+	assert.Len(t, g.Injected(), 1)
+
+	// every call fails until it's cleared.
+	g.Fail("HealthCheck", errBroadcast, -1)
+	for i := 0; i < 3; i++ {
+		ok, err := g.HealthCheck()
+		assert.False(t, ok)
+		assert.NotNil(t, err)
+	}
+	g.Fail("HealthCheck", nil, 0)
+	ok, err := g.HealthCheck()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	g.Fail("GetBalance", errBroadcast, 1)
+	_, err = g.GetBalance([]string{"a"})
+	assert.Equal(t, errBroadcast, err)
+	assert.Equal(t, 0, g.Calls("GetTx"))
+}
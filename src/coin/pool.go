@@ -0,0 +1,92 @@
+package coin
+
+import (
+	"fmt"
+	"sync"
+)
+
+// UtxoPool the resizable pool of utxos embedded in the utxo managers, the utxos are
+// put as interface{}, and asserted back to the utxo type of the coin by the manager.
+type UtxoPool struct {
+	ch        chan interface{}
+	mtx       sync.RWMutex    // protects ch and refs while resizing.
+	refs      *sync.WaitGroup // counts the users of current ch.
+	resized   chan bool       // closed when current ch is replaced.
+	resizeMtx sync.Mutex      // serializes the SetPoolSize calls.
+}
+
+// NewUtxoPool creates the utxo pool which can hold n utxos.
+func NewUtxoPool(n int) *UtxoPool {
+	return &UtxoPool{
+		ch:      make(chan interface{}, n),
+		refs:    &sync.WaitGroup{},
+		resized: make(chan bool),
+	}
+}
+
+// SetPoolSize resizes the utxo pool while the manager is running, the utxos in the
+// old pool are moved into the new one. It returns once all the users of the old pool
+// have released it, so shrinking waits for the borrowed utxos to be put back, and
+// blocks until enough utxos are chosen if the new pool can't hold all of them.
+func (p *UtxoPool) SetPoolSize(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("invalid utxo pool size: %d", n)
+	}
+
+	p.resizeMtx.Lock()
+	defer p.resizeMtx.Unlock()
+
+	p.mtx.Lock()
+	oldPool, oldRefs := p.ch, p.refs
+	newPool := make(chan interface{}, n)
+	p.ch, p.refs = newPool, &sync.WaitGroup{}
+	close(p.resized)
+	p.resized = make(chan bool)
+	p.mtx.Unlock()
+
+	released := make(chan bool)
+	go func() {
+		oldRefs.Wait()
+		close(released)
+	}()
+
+	// keep moving utxos until no one holds the old pool.
+	for {
+		select {
+		case u := <-oldPool:
+			newPool <- u
+		case <-released:
+			for {
+				select {
+				case u := <-oldPool:
+					newPool <- u
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// PoolSize returns the capacity of the utxo pool and the number of utxos available in it.
+func (p *UtxoPool) PoolSize() (int, int) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return cap(p.ch), len(p.ch)
+}
+
+// AcquirePool returns the current utxo pool and the chan which will be closed once
+// the pool is resized, the release func must be called once the caller no longer uses the pool.
+func (p *UtxoPool) AcquirePool() (chan interface{}, chan bool, func()) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	p.refs.Add(1)
+	return p.ch, p.resized, p.refs.Done
+}
+
+// Put puts the utxo into current utxo pool.
+func (p *UtxoPool) Put(utxo interface{}) {
+	pool, _, release := p.AcquirePool()
+	defer release()
+	pool <- utxo
+}
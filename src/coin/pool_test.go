@@ -0,0 +1,58 @@
+package coin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUtxoPoolResize(t *testing.T) {
+	p := NewUtxoPool(2)
+	p.Put("a")
+	p.Put("b")
+
+	assert.NotNil(t, p.SetPoolSize(0))
+	assert.Nil(t, p.SetPoolSize(3))
+	size, available := p.PoolSize()
+	assert.Equal(t, 3, size)
+	assert.Equal(t, 2, available)
+
+	// the user of the old pool is told it's resized, and the resizing
+	// waits for the old pool to be released.
+	pool, resized, release := p.AcquirePool()
+	u := <-pool
+	done := make(chan error)
+	go func() { done <- p.SetPoolSize(1) }()
+
+	select {
+	case <-resized:
+	case <-time.After(time.Second):
+		t.Fatal("resize is not notified")
+	}
+
+	select {
+	case <-done:
+		t.Fatal("resize returned before the old pool is released")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("resize blocked after the old pool is released")
+	}
+
+	size, available = p.PoolSize()
+	assert.Equal(t, 1, size)
+	assert.Equal(t, 1, available)
+
+	// the utxo taken from the old pool is put into the new one.
+	go p.Put(u)
+	pool, _, release = p.AcquirePool()
+	defer release()
+	assert.Equal(t, "b", <-pool)
+	assert.Equal(t, u, <-pool)
+}
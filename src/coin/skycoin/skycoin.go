@@ -105,12 +105,63 @@ func (sky *Skycoin) GetBalance(addrs []string) (pp.Balance, error) {
 		Hours:  pp.PtrUint64(bal.Confirmed.Hours)}, nil
 }
 
+// GetDetailedBalance get skycoin balance of specific addresses split by confirmation status,
+// the confirmed coins exclude the outputs being spent by the pending transactions, and the
+// unconfirmed coins are the outputs the pending transactions send to the addresses.
+func (sky *Skycoin) GetDetailedBalance(addrs []string) (coin.DetailedBalance, error) {
+	if len(addrs) == 0 {
+		return coin.DetailedBalance{}, nil
+	}
+
+	url := fmt.Sprintf("http://%s/outputs?addrs=%s", sky.NodeAddress, strings.Join(addrs, ","))
+	rsp, err := http.Get(url)
+	if err != nil {
+		return coin.DetailedBalance{}, err
+	}
+	defer rsp.Body.Close()
+	outSet := visor.ReadableOutputSet{}
+	if err := json.NewDecoder(rsp.Body).Decode(&outSet); err != nil {
+		return coin.DetailedBalance{}, err
+	}
+
+	spending := make(map[string]bool)
+	for _, o := range outSet.OutgoingOutputs {
+		spending[o.Hash] = true
+	}
+
+	bal := coin.DetailedBalance{}
+	for _, o := range outSet.HeadOutputs {
+		if !spending[o.Hash] {
+			bal.Confirmed += SkyUtxo{o}.GetCoins()
+		}
+	}
+	for _, o := range outSet.IncommingOutputs {
+		bal.Unconfirmed += SkyUtxo{o}.GetCoins()
+	}
+	bal.Total = bal.Confirmed + bal.Unconfirmed
+	return bal, nil
+}
+
 // ValidateTxid verify the valiation of specific transaction id.
 func (sky *Skycoin) ValidateTxid(txid string) bool {
 	_, err := cipher.SHA256FromHex(txid)
 	return err == nil
 }
 
+// EstimateFee skycoin transaction fee is paid by burning coin hours, no coins
+// are charged, so the fee is always 0 regardless of the transaction size.
+func (sky *Skycoin) EstimateFee(nInputs, nOutputs int) (uint64, error) {
+	if nInputs <= 0 || nOutputs <= 0 {
+		return 0, fmt.Errorf("invalid inputs number %d or outputs number %d", nInputs, nOutputs)
+	}
+	return 0, nil
+}
+
+// GetFeeEstimates skycoin has no mempool fee market, the fee rates are always 0.
+func (sky *Skycoin) GetFeeEstimates() ([]coin.FeeEstimate, error) {
+	return coin.StaticFeeEstimates(0), nil
+}
+
 func newPPTx(tx *visor.TransactionResult) *pp.Tx {
 	return &pp.Tx{
 		Sky: &pp.SkyTx{
@@ -158,8 +209,8 @@ func (sky Skycoin) CreateRawTx(txIns []coin.TxIn, txOuts interface{}) (string, e
 		outs[i] = s.Index(i).Interface()
 	}
 
-	if len(outs) > 2 {
-		return "", errors.New("out address more than 2")
+	if len(outs) == 0 {
+		return "", errors.New("no output address")
 	}
 
 	for _, o := range outs {
@@ -258,6 +309,21 @@ func (sky *Skycoin) GetUtxos(addrs []string) (interface{}, error) {
 	return res, nil
 }
 
+// GetAddressTxs gets the transactions of specific addresses, including the ones
+// whose outputs are spent.
+func (sky *Skycoin) GetAddressTxs(addrs []string) ([]*pp.Tx, error) {
+	rs, err := getAddressTxs(sky.NodeAddress, addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*pp.Tx, len(rs))
+	for i := range rs {
+		txs[i] = newPPTx(&rs[i])
+	}
+	return txs, nil
+}
+
 // GetOutput gets output info of specific hash
 func (sky *Skycoin) GetOutput(hash string) (interface{}, error) {
 	out, err := GetOutput(sky.NodeAddress, hash)
@@ -329,6 +395,30 @@ func MakeUtxoOutput(addr string, amount uint64, hours uint64) TxOut {
 	return uo
 }
 
+// OutputHoursFactor the outputs share 1/OutputHoursFactor of the input coin hours, the rest
+// are burnt as the transaction fee.
+const OutputHoursFactor = 4
+
+// ErrInsufficientHours the inputs have no coin hours to burn, skycoin rejects the
+// transaction without fee.
+var ErrInsufficientHours = errors.New("insufficient coin hours to pay the transaction fee")
+
+// DistributeHours returns the coin hours of each of the nOuts outputs, and the hours burnt as fee by
+// the transaction spending inputHours. The outputs share 1/OutputHoursFactor of the input hours evenly,
+// the remainder of the division is burnt too.
+func DistributeHours(inputHours uint64, nOuts int) (uint64, uint64, error) {
+	if nOuts <= 0 {
+		return 0, 0, fmt.Errorf("invalid outputs number %d", nOuts)
+	}
+
+	if inputHours == 0 {
+		return 0, 0, ErrInsufficientHours
+	}
+
+	outHours := inputHours / OutputHoursFactor / uint64(nOuts)
+	return outHours, inputHours - outHours*uint64(nOuts), nil
+}
+
 // VerifyAmount check if the amout is validated.
 func VerifyAmount(amt uint64) error {
 	if (amt % 1e6) != 0 {
@@ -403,6 +493,47 @@ func getUnspentOutputsByHashes(nodeAddr string, hashes []string) ([]Utxo, error)
 	return ux, nil
 }
 
+// getAddressTxs returns the confirmed and unconfirmed transactions involving addrs from
+// the address transactions api of the node, which includes the transactions whose outputs
+// are spent already, the transaction of several addresses is returned once.
+func getAddressTxs(nodeAddr string, addrs []string) ([]visor.TransactionResult, error) {
+	if len(addrs) == 0 {
+		return []visor.TransactionResult{}, nil
+	}
+
+	url := fmt.Sprintf("http://%s/transactions?addrs=%s", nodeAddr, strings.Join(addrs, ","))
+	rsp, err := http.Get(url)
+	if err != nil {
+		return nil, errors.New("get address transactions failed")
+	}
+	defer rsp.Body.Close()
+
+	d, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsp.StatusCode != 200 {
+		return nil, errors.New(string(d))
+	}
+
+	txs := []visor.TransactionResult{}
+	if err := json.Unmarshal(d, &txs); err != nil {
+		return nil, err
+	}
+
+	res := []visor.TransactionResult{}
+	txMap := make(map[string]bool)
+	for _, tx := range txs {
+		if txMap[tx.Transaction.Hash] {
+			continue
+		}
+		txMap[tx.Transaction.Hash] = true
+		res = append(res, tx)
+	}
+	return res, nil
+}
+
 // GetOutput gets verbose info of tx output with specific hash.
 func GetOutput(nodeAddr string, hash string) (*pp.Output, error) {
 	_, err := cipher.SHA256FromHex(hash)
@@ -443,3 +574,21 @@ func (sky *Skycoin) Symbol() string {
 func (sky *Skycoin) Type() string {
 	return Type
 }
+
+// HealthCheck checks if the skycoin node is available.
+func (sky *Skycoin) HealthCheck() (bool, error) {
+	if err := CheckNode(sky.NodeAddress); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CheckNode checks if the skycoin node of nodeAddr responds.
+func CheckNode(nodeAddr string) error {
+	return coin.CheckURL(fmt.Sprintf("http://%s/blockchain/metadata", nodeAddr))
+}
+
+// Decimals returns the decimal places of skycoin, the amounts are in droplets.
+func (sky *Skycoin) Decimals() int {
+	return 6
+}
@@ -4,10 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 
+	"github.com/skycoin/skycoin-exchange/src/coin"
 	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin/src/visor"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -87,3 +93,132 @@ func TestGetOutput(t *testing.T) {
 		}
 	}
 }
+
+func TestHealthCheck(t *testing.T) {
+	var down int32
+	node := newTestNode(&down)
+	defer node.Close()
+
+	sky := New(strings.TrimPrefix(node.URL, "http://"))
+	ok, err := sky.HealthCheck()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	atomic.StoreInt32(&down, 1)
+	ok, err = sky.HealthCheck()
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+
+	// node is unreachable.
+	node.Close()
+	atomic.StoreInt32(&down, 0)
+	ok, err = sky.HealthCheck()
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+}
+
+func TestDistributeHours(t *testing.T) {
+	tests := []struct {
+		inputHours uint64
+		nOuts      int
+		outHours   uint64
+		fee        uint64
+	}{
+		{400, 1, 100, 300},
+		{400, 2, 50, 300},
+		{401, 3, 33, 302},
+		{3, 1, 0, 3},
+		{1, 2, 0, 1},
+	}
+	for _, tt := range tests {
+		outHours, fee, err := DistributeHours(tt.inputHours, tt.nOuts)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.outHours, outHours, "%d hours to %d outputs", tt.inputHours, tt.nOuts)
+		assert.Equal(t, tt.fee, fee, "%d hours to %d outputs", tt.inputHours, tt.nOuts)
+		assert.Equal(t, tt.inputHours, outHours*uint64(tt.nOuts)+fee)
+	}
+
+	_, _, err := DistributeHours(0, 2)
+	assert.Equal(t, ErrInsufficientHours, err)
+	_, _, err = DistributeHours(100, 0)
+	assert.NotNil(t, err)
+}
+
+func TestGetDetailedBalance(t *testing.T) {
+	// out2 is being spent by a pending transaction, which sends out3 back as change,
+	// out4 is a pending deposit.
+	outSet := visor.ReadableOutputSet{
+		HeadOutputs: []visor.ReadableOutput{
+			{Hash: "out1", Address: "addr1", Coins: "10"},
+			{Hash: "out2", Address: "addr2", Coins: "5"},
+		},
+		OutgoingOutputs: []visor.ReadableOutput{
+			{Hash: "out2", Address: "addr2", Coins: "5"},
+		},
+		IncommingOutputs: []visor.ReadableOutput{
+			{Hash: "out3", Address: "addr2", Coins: "2"},
+			{Hash: "out4", Address: "addr1", Coins: "3"},
+		},
+	}
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/outputs" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.Query().Get("addrs")
+		json.NewEncoder(w).Encode(outSet)
+	}))
+	defer srv.Close()
+
+	sky := New(strings.TrimPrefix(srv.URL, "http://"))
+	bal, err := sky.GetDetailedBalance([]string{"addr1", "addr2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "addr1,addr2", query)
+	assert.Equal(t, coin.DetailedBalance{Confirmed: 10e6, Unconfirmed: 5e6, Total: 15e6}, bal)
+
+	// no pending transaction.
+	outSet.OutgoingOutputs, outSet.IncommingOutputs = nil, nil
+	bal, err = sky.GetDetailedBalance([]string{"addr1", "addr2"})
+	assert.Nil(t, err)
+	assert.Equal(t, coin.DetailedBalance{Confirmed: 15e6, Unconfirmed: 0, Total: 15e6}, bal)
+
+	srv.Close()
+	_, err = sky.GetDetailedBalance([]string{"addr1"})
+	assert.NotNil(t, err)
+}
+
+func TestGetAddressTxs(t *testing.T) {
+	// tx1 funds addr1, and its output is spent by tx2 which pays addr2.
+	txs := []visor.TransactionResult{
+		{Status: visor.TransactionStatus{Confirmed: true, Height: 2}, Transaction: visor.ReadableTransaction{Hash: "tx1", Out: []visor.ReadableTransactionOutput{{Hash: "out1", Address: "addr1", Coins: "10"}}}},
+		{Status: visor.TransactionStatus{Confirmed: true, Height: 1}, Transaction: visor.ReadableTransaction{Hash: "tx2", In: []string{"out1"}, Out: []visor.ReadableTransactionOutput{{Hash: "out2", Address: "addr2", Coins: "10"}}}},
+		{Status: visor.TransactionStatus{Confirmed: true, Height: 1}, Transaction: visor.ReadableTransaction{Hash: "tx2", In: []string{"out1"}, Out: []visor.ReadableTransactionOutput{{Hash: "out2", Address: "addr2", Coins: "10"}}}},
+	}
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/transactions" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.Query().Get("addrs")
+		json.NewEncoder(w).Encode(txs)
+	}))
+	defer srv.Close()
+
+	sky := New(strings.TrimPrefix(srv.URL, "http://"))
+	res, err := sky.GetAddressTxs([]string{"addr1", "addr2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "addr1,addr2", query)
+	assert.Equal(t, 2, len(res))
+	assert.Equal(t, "tx1", res[0].GetSky().GetHash())
+	assert.Equal(t, "tx2", res[1].GetSky().GetHash())
+
+	res, err = sky.GetAddressTxs([]string{})
+	assert.Nil(t, err)
+	assert.Empty(t, res)
+
+	srv.Close()
+	_, err = sky.GetAddressTxs([]string{"addr1"})
+	assert.NotNil(t, err)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/skycoin/skycoin-exchange/src/coin"
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/encoder"
 	skycoin "github.com/skycoin/skycoin/src/coin"
@@ -58,6 +59,15 @@ func BroadcastTx(nodeAddr, rawtx string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+
+	// the node responds 400 if the transaction is invalid, like double spending.
+	if rsp.StatusCode == http.StatusBadRequest {
+		return "", fmt.Errorf("inject rawtx failed, %w: %s", coin.ErrTxRejected, strings.TrimSpace(string(s)))
+	}
+
+	if rsp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("inject rawtx failed, %s", rsp.Status)
+	}
 	return strings.Trim(string(s), "\""), nil
 }
 
@@ -1,36 +1,69 @@
 package skycoin_interface
 
 import (
-	"errors"
 	"fmt"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/sklog"
 )
 
-var CheckTick = 5 * time.Second
+var (
+	CheckTick = 5 * time.Second
+	// ReconnectMinBackoff the first interval of reconnecting the node after it went down.
+	ReconnectMinBackoff = 1 * time.Second
+	// ReconnectMaxBackoff the max interval of reconnecting the node.
+	ReconnectMaxBackoff = 1 * time.Minute
+)
+
+// NodeStatus records the connection status of the skycoin node.
+type NodeStatus struct {
+	Healthy bool      `json:"healthy"`
+	Error   string    `json:"error,omitempty"`
+	Retries int       `json:"retries"` // reconnection attempts since the node went down.
+	Since   time.Time `json:"since"`   // the time when current status started.
+}
 
 type UtxoManager interface {
 	Start(closing chan bool)
 	ChooseUtxos(amt uint64, tm time.Duration) ([]Utxo, error)
 	PutUtxo(utxo Utxo) // put utxo into utxo pool
 	WatchAddresses(addrs []string)
+	SetPoolSize(n int) error         // resize the utxo pool
+	PoolSize() (int, int)            // capacity of the utxo pool and the available utxos.
+	Status() NodeStatus              // connection status of the skycoin node.
+	Stats() coin.UtxoStats           // state of the utxo pool.
+	SetDepositHandler(fn func(Utxo)) // fn is called for each new utxo.
 }
 
 type ExUtxoManager struct {
+	*coin.UtxoPool
 	WatchAddress []string
-	UtxosCh      chan Utxo
 	UtxoStateMap map[string]Utxo
 	NodeAddr     string
 	mutx         sync.Mutex
+	status       NodeStatus
+	statusMtx    sync.RWMutex // protects status.
+	onDeposit    func(Utxo)   // called when new utxo is found.
+	depositMtx   sync.RWMutex
+	statsMtx     sync.Mutex      // protects the following stats.
+	available    int             // utxos in the pool.
+	value        uint64          // total value of the available utxos.
+	borrowed     map[string]Utxo // utxos chosen and not yet put back or spent, key: hash.
+	lastRefill   int64           // unix time of the last check of new utxos.
 }
 
 func NewUtxoManager(nodeAddr string, utxoPoolsize int, watchAddrs []string) UtxoManager {
 	eum := &ExUtxoManager{
-		UtxosCh:      make(chan Utxo, utxoPoolsize),
+		UtxoPool:     coin.NewUtxoPool(utxoPoolsize),
 		UtxoStateMap: make(map[string]Utxo),
+		borrowed:     make(map[string]Utxo),
 		WatchAddress: watchAddrs,
 		NodeAddr:     nodeAddr,
+		status:       NodeStatus{Healthy: true, Since: time.Now()},
 	}
 
 	return eum
@@ -48,21 +81,85 @@ func (eum *ExUtxoManager) Start(closing chan bool) {
 			newUtxos, err := eum.checkNewUtxo()
 			if err != nil {
 				logger.Error(err.Error())
+				if !eum.reconnect(err, closing) {
+					return
+				}
 				break
 			}
 
+			eum.depositMtx.RLock()
+			onDeposit := eum.onDeposit
+			eum.depositMtx.RUnlock()
 			for _, utxo := range newUtxos {
-				logger.Debug("new skycoin utxo: hash:%s coins:%d hours:%d",
-					utxo.GetHash(), utxo.GetCoins(), utxo.GetHours())
-				eum.UtxosCh <- utxo
+				sklog.Debug(logger, "new utxo", sklog.Fields{"coin": Type, "address": utxo.GetAddress(), "hash": utxo.GetHash(), "coins": utxo.GetCoins(), "hours": utxo.GetHours()})
+				if onDeposit != nil {
+					onDeposit(utxo)
+				}
+				eum.putBack(utxo)
+				eum.Put(utxo)
 			}
 		}
 	}
 }
 
+// SetDepositHandler sets the func which will be called with each new utxo, the
+// unspent outputs returned by the node are all confirmed.
+func (eum *ExUtxoManager) SetDepositHandler(fn func(Utxo)) {
+	eum.depositMtx.Lock()
+	eum.onDeposit = fn
+	eum.depositMtx.Unlock()
+}
+
+// Status returns the connection status of the skycoin node.
+func (eum *ExUtxoManager) Status() NodeStatus {
+	eum.statusMtx.RLock()
+	defer eum.statusMtx.RUnlock()
+	return eum.status
+}
+
+func (eum *ExUtxoManager) setStatus(s NodeStatus) {
+	eum.statusMtx.Lock()
+	eum.status = s
+	eum.statusMtx.Unlock()
+}
+
+// reconnect marks the node as down, and checks the node with exponential backoff
+// until it's available again. Returns false if closing is closed before that.
+func (eum *ExUtxoManager) reconnect(cause error, closing chan bool) bool {
+	status := NodeStatus{Error: cause.Error(), Since: time.Now()}
+	eum.setStatus(status)
+
+	backoff := ReconnectMinBackoff
+	for {
+		select {
+		case <-closing:
+			return false
+		case <-time.After(backoff):
+		}
+
+		status.Retries++
+		err := CheckNode(eum.NodeAddr)
+		if err == nil {
+			sklog.Info(logger, "node reconnected", sklog.Fields{"coin": Type, "node": eum.NodeAddr, "retries": status.Retries})
+			eum.setStatus(NodeStatus{Healthy: true, Since: time.Now()})
+			return true
+		}
+
+		sklog.Error(logger, "node reconnect failed", sklog.Fields{"coin": Type, "node": eum.NodeAddr, "error": err.Error()})
+		status.Error = err.Error()
+		eum.setStatus(status)
+
+		backoff *= 2
+		if backoff > ReconnectMaxBackoff {
+			backoff = ReconnectMaxBackoff
+		}
+	}
+}
+
 func (eum *ExUtxoManager) PutUtxo(utxo Utxo) {
-	logger.Debug("skycoin utxo put back: %s", utxo.GetHash())
-	eum.UtxosCh <- utxo
+	sklog.Debug(logger, "utxo put back", sklog.Fields{"coin": Type, "address": utxo.GetAddress(), "hash": utxo.GetHash()})
+	eum.putBack(utxo)
+	eum.Put(utxo)
 }
 
 func (eum *ExUtxoManager) WatchAddresses(addrs []string) {
@@ -72,6 +169,51 @@ func (eum *ExUtxoManager) WatchAddresses(addrs []string) {
 	eum.WatchAddress = append(eum.WatchAddress, addrs...)
 }
 
+// Stats returns the state of the utxo pool, the value is in the unit of ChooseUtxos.
+func (eum *ExUtxoManager) Stats() coin.UtxoStats {
+	eum.statsMtx.Lock()
+	defer eum.statsMtx.Unlock()
+	return coin.UtxoStats{
+		Available:  eum.available,
+		Borrowed:   len(eum.borrowed),
+		TotalValue: eum.value,
+		LastRefill: eum.lastRefill,
+	}
+}
+
+// putBack counts the utxo as available, it's no longer borrowed if it was.
+func (eum *ExUtxoManager) putBack(utxo Utxo) {
+	eum.statsMtx.Lock()
+	eum.available++
+	eum.value += utxo.GetCoins() * 1e6
+	delete(eum.borrowed, utxo.GetHash())
+	eum.statsMtx.Unlock()
+}
+
+// borrow counts the utxos chosen by ChooseUtxos as borrowed.
+func (eum *ExUtxoManager) borrow(utxos []Utxo) {
+	eum.statsMtx.Lock()
+	defer eum.statsMtx.Unlock()
+	for _, u := range utxos {
+		eum.available--
+		eum.value -= u.GetCoins() * 1e6
+		eum.borrowed[u.GetHash()] = u
+	}
+}
+
+// refilled records the time of checking new utxos, the borrowed utxos which are not in
+// the unspent outputs any more are spent, and no longer counted.
+func (eum *ExUtxoManager) refilled(unspent map[string]Utxo) {
+	eum.statsMtx.Lock()
+	defer eum.statsMtx.Unlock()
+	eum.lastRefill = time.Now().Unix()
+	for id := range eum.borrowed {
+		if _, ok := unspent[id]; !ok {
+			delete(eum.borrowed, id)
+		}
+	}
+}
+
 func (eum *ExUtxoManager) checkNewUtxo() ([]Utxo, error) {
 	latestUtxos, err := GetUnspentOutputs(eum.NodeAddr, eum.WatchAddress)
 	if err != nil {
@@ -96,6 +238,7 @@ func (eum *ExUtxoManager) checkNewUtxo() ([]Utxo, error) {
 
 	eum.UtxoStateMap = latestUxMap
 	eum.mutx.Unlock()
+	eum.refilled(latestUxMap)
 	return newUtxos, nil
 }
 
@@ -110,76 +253,104 @@ func (eum *ExUtxoManager) mustGetUtxos(hash string) Utxo {
 
 // chooseUtxos choose appropriate utxos, if time out, and not found enough utxos,
 // the utxos got before will put back to the utxos pool, and return error.
-// the tm is millisecond
-func (eum *ExUtxoManager) chooseUtxos(amount uint64, tm time.Duration) ([]Utxo, error) {
-	logger.Debug("skycoin choose utxos, amount:%d", amount)
+// insufficient will be set once the pool is drained before sufficient utxos are chosen.
+func (eum *ExUtxoManager) chooseUtxos(amount uint64, tm time.Duration, insufficient *int32) ([]Utxo, error) {
+	sklog.Debug(logger, "choose utxos", sklog.Fields{"coin": Type, "amount": amount})
+	pool, resized, release := eum.AcquirePool()
+	defer release()
+
 	var totalAmount uint64
 	utxos := []Utxo{}
 	for {
+		var utxo Utxo
 		select {
-		case utxo := <-eum.UtxosCh:
-			u := eum.mustGetUtxos(utxo.GetHash())
-			if u.GetCoins() != utxo.GetCoins() {
-				panic("utxo coins not equal")
-			}
-			logger.Debug("get utxo: hash:%s coins:%d hours:%d",
-				utxo.GetHash(), utxo.GetCoins(), utxo.GetHours())
-			utxos = append(utxos, u)
-			totalAmount += utxo.GetCoins() * 1e6
-			if totalAmount >= amount {
-				return utxos, nil
+		case u := <-pool:
+			utxo = u.(Utxo)
+		default:
+			// the pool is drained, but the chosen utxos are not sufficient.
+			if len(utxos) > 0 {
+				atomic.StoreInt32(insufficient, 1)
 			}
 
-		case <-time.After(tm):
-			// put utxos back
-			logger.Debug("choose time out, put back utxos")
-			for _, u := range utxos {
-				eum.UtxosCh <- u
+			select {
+			case u := <-pool:
+				utxo = u.(Utxo)
+			case <-time.After(tm):
+				// put utxos back
+				logger.Debug("choose time out, put back utxos")
+				for _, u := range utxos {
+					pool <- u
+				}
+				if atomic.LoadInt32(insufficient) == 1 {
+					return []Utxo{}, coin.ErrInsufficientUtxo
+				}
+				return []Utxo{}, coin.ErrUtxoTimeout
+
+			case <-resized:
+				// put utxos back, they will be moved into the new pool.
+				logger.Debug("utxo pool resized, put back utxos")
+				for _, u := range utxos {
+					pool <- u
+				}
+				return []Utxo{}, nil
 			}
-			return []Utxo{}, nil
+		}
+
+		u := eum.mustGetUtxos(utxo.GetHash())
+		if u.GetCoins() != utxo.GetCoins() {
+			panic("utxo coins not equal")
+		}
+		sklog.Debug(logger, "get utxo", sklog.Fields{"coin": Type, "address": utxo.GetAddress(), "hash": utxo.GetHash(), "coins": utxo.GetCoins(), "hours": utxo.GetHours()})
+		utxos = append(utxos, u)
+		totalAmount += utxo.GetCoins() * 1e6
+		if totalAmount >= amount {
+			return utxos, nil
 		}
 	}
 }
 
-// ChooseUtxos choose sufficient utxos in specific time.
+// ChooseUtxos choose sufficient utxos in specific time, returns coin.ErrInsufficientUtxo
+// if the utxo pool is drained before sufficient utxos are chosen, or coin.ErrUtxoTimeout
+// if no utxo is available.
 func (eum *ExUtxoManager) ChooseUtxos(amt uint64, tm time.Duration) ([]Utxo, error) {
 	var (
-		utxos []Utxo
-		err   error
-		ch    = make(chan bool)
-		ok    = make(chan bool)
+		insufficient int32
+		closing      = make(chan bool)
+		done         = make(chan []Utxo)
 	)
 
-	go func(closing chan bool, ok chan bool) {
+	go func() {
 		for {
+			utxos, err := eum.chooseUtxos(amt, randExpireTm(), &insufficient)
+			if err == nil && len(utxos) > 0 {
+				select {
+				case done <- utxos:
+				case <-closing:
+					for _, u := range utxos {
+						eum.Put(u)
+					}
+				}
+				return
+			}
+
 			select {
 			case <-closing:
-				for _, u := range utxos {
-					eum.UtxosCh <- u
-				}
 				return
 			default:
-				utxos, err = eum.chooseUtxos(amt, randExpireTm())
-				if err != nil {
-					return
-				}
-
-				if len(utxos) > 0 {
-					ok <- true
-					return
-				}
 			}
 		}
-	}(ch, ok)
+	}()
 
-	for {
-		select {
-		case <-time.After(tm):
-			ch <- true
-			return []Utxo{}, errors.New("time out")
-		case <-ok:
-			return utxos, nil
+	select {
+	case utxos := <-done:
+		eum.borrow(utxos)
+		return utxos, nil
+	case <-time.After(tm):
+		close(closing)
+		if atomic.LoadInt32(&insufficient) == 1 {
+			return []Utxo{}, coin.ErrInsufficientUtxo
 		}
+		return []Utxo{}, coin.ErrUtxoTimeout
 	}
 }
 
@@ -0,0 +1,332 @@
+package skycoin_interface
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/stretchr/testify/assert"
+)
+
+// uxAmt is the amount of one test utxo, which has 1 coin.
+const uxAmt = 1e12
+
+func makeUtxos(n int) []Utxo {
+	uxs := make([]Utxo, n)
+	for i := range uxs {
+		uxs[i] = SkyUtxo{visor.ReadableOutput{
+			Hash:    fmt.Sprintf("%064x", i),
+			Address: "fyqX5YuwXMUs4GEUE3LjLyhrqvNztFHQ4B",
+			Coins:   "1",
+		}}
+	}
+	return uxs
+}
+
+// newTestUtxoManager creates utxo manager which knows the state of uxs.
+func newTestUtxoManager(size int, uxs []Utxo) UtxoManager {
+	um := NewUtxoManager("", size, []string{})
+	eum := um.(*ExUtxoManager)
+	for _, u := range uxs {
+		eum.UtxoStateMap[u.GetHash()] = u
+	}
+	return um
+}
+
+func TestSetPoolSizeGrow(t *testing.T) {
+	uxs := makeUtxos(4)
+	um := newTestUtxoManager(2, uxs)
+	um.PutUtxo(uxs[0])
+	um.PutUtxo(uxs[1])
+
+	assert.Nil(t, um.SetPoolSize(4))
+
+	// the pool can hold all the utxos now.
+	done := make(chan bool)
+	go func() {
+		um.PutUtxo(uxs[2])
+		um.PutUtxo(uxs[3])
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("put utxo blocked after growing the pool")
+	}
+
+	utxos, err := um.ChooseUtxos(4*uxAmt, 10*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(utxos))
+}
+
+func TestSetPoolSizeShrink(t *testing.T) {
+	uxs := makeUtxos(4)
+	um := newTestUtxoManager(4, uxs)
+	for _, u := range uxs {
+		um.PutUtxo(u)
+	}
+
+	shrunk := make(chan error)
+	go func() {
+		shrunk <- um.SetPoolSize(2)
+	}()
+
+	// the new pool can't hold the 4 utxos.
+	select {
+	case <-shrunk:
+		t.Fatal("shrink should wait for utxos being chosen")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	utxos, err := um.ChooseUtxos(2*uxAmt, 10*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(utxos))
+
+	select {
+	case err := <-shrunk:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("shrink blocked")
+	}
+
+	utxos, err = um.ChooseUtxos(2*uxAmt, 10*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(utxos))
+
+	assert.NotNil(t, um.SetPoolSize(0))
+}
+
+func TestSetPoolSizeInflightChoose(t *testing.T) {
+	uxs := makeUtxos(3)
+	um := newTestUtxoManager(4, uxs)
+	um.PutUtxo(uxs[0])
+
+	type result struct {
+		utxos []Utxo
+		err   error
+	}
+	rlt := make(chan result)
+	go func() {
+		utxos, err := um.ChooseUtxos(3*uxAmt, 10*time.Second)
+		rlt <- result{utxos, err}
+	}()
+
+	// wait until the choose goroutine takes the utxo.
+	time.Sleep(100 * time.Millisecond)
+	assert.Nil(t, um.SetPoolSize(2))
+
+	um.PutUtxo(uxs[1])
+	um.PutUtxo(uxs[2])
+
+	select {
+	case r := <-rlt:
+		assert.Nil(t, r.err)
+		assert.Equal(t, 3, len(r.utxos))
+	case <-time.After(2 * time.Second):
+		t.Fatal("choose utxos blocked after resizing")
+	}
+}
+
+func TestSetPoolSizeConcurrent(t *testing.T) {
+	n := 20
+	uxs := makeUtxos(n)
+	um := newTestUtxoManager(5, uxs)
+
+	var wg sync.WaitGroup
+	for _, u := range uxs {
+		wg.Add(1)
+		go func(u Utxo) {
+			defer wg.Done()
+			um.PutUtxo(u)
+		}(u)
+	}
+
+	chosen := make(chan Utxo, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			utxos, err := um.ChooseUtxos(uxAmt, 30*time.Second)
+			if !assert.Nil(t, err) {
+				return
+			}
+			for _, u := range utxos {
+				chosen <- u
+			}
+		}()
+	}
+
+	for _, size := range []int{1, 10, 3, n} {
+		assert.Nil(t, um.SetPoolSize(size))
+	}
+
+	wg.Wait()
+	close(chosen)
+
+	ids := make(map[string]bool)
+	for u := range chosen {
+		id := u.GetHash()
+		assert.False(t, ids[id], "utxo %s chosen twice", id)
+		ids[id] = true
+	}
+	assert.Equal(t, n, len(ids))
+}
+
+func TestChooseUtxosTimeout(t *testing.T) {
+	uxs := makeUtxos(2)
+	um := newTestUtxoManager(2, uxs)
+	for _, u := range uxs {
+		um.PutUtxo(u)
+	}
+
+	// drain the pool.
+	utxos, err := um.ChooseUtxos(2*uxAmt, 10*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(utxos))
+
+	_, err = um.ChooseUtxos(uxAmt, 200*time.Millisecond)
+	assert.True(t, errors.Is(err, coin.ErrUtxoTimeout))
+}
+
+func TestChooseUtxosInsufficient(t *testing.T) {
+	uxs := makeUtxos(1)
+	um := newTestUtxoManager(2, uxs)
+	um.PutUtxo(uxs[0])
+
+	_, err := um.ChooseUtxos(3*uxAmt, 200*time.Millisecond)
+	assert.True(t, errors.Is(err, coin.ErrInsufficientUtxo))
+}
+
+func TestUtxoStats(t *testing.T) {
+	uxs := makeUtxos(4)
+	um := newTestUtxoManager(4, uxs)
+	for _, u := range uxs {
+		um.PutUtxo(u)
+	}
+	assert.Equal(t, coin.UtxoStats{Available: 4, TotalValue: 4 * uxAmt}, um.Stats())
+
+	utxos, err := um.ChooseUtxos(2*uxAmt, 10*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(utxos))
+	assert.Equal(t, coin.UtxoStats{Available: 2, Borrowed: 2, TotalValue: 2 * uxAmt}, um.Stats())
+
+	um.PutUtxo(utxos[0])
+	assert.Equal(t, coin.UtxoStats{Available: 3, Borrowed: 1, TotalValue: 3 * uxAmt}, um.Stats())
+
+	// the failed choosing borrows nothing.
+	_, err = um.ChooseUtxos(10*uxAmt, 200*time.Millisecond)
+	assert.NotNil(t, err)
+	assert.Equal(t, coin.UtxoStats{Available: 3, Borrowed: 1, TotalValue: 3 * uxAmt}, um.Stats())
+
+	// the node has no unspent outputs, the borrowed utxo is spent.
+	var down int32
+	srv := newTestNode(&down)
+	defer srv.Close()
+	eum := um.(*ExUtxoManager)
+	eum.NodeAddr = strings.TrimPrefix(srv.URL, "http://")
+	eum.WatchAddresses([]string{"fyqX5YuwXMUs4GEUE3LjLyhrqvNztFHQ4B"})
+	_, err = eum.checkNewUtxo()
+	assert.Nil(t, err)
+	stats := um.Stats()
+	assert.Equal(t, 0, stats.Borrowed)
+	assert.True(t, stats.LastRefill > 0)
+}
+
+// newTestNode creates a fake skycoin node, which responds 500 while down is set.
+func newTestNode(down *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(down) == 1 {
+			http.Error(w, "node is down", http.StatusInternalServerError)
+			return
+		}
+		switch r.URL.Path {
+		case "/outputs":
+			w.Write([]byte(`{"head_outputs":[],"outgoing_outputs":[],"incoming_outputs":[]}`))
+		case "/blockchain/metadata":
+			w.Write([]byte(`{"head":{"seq":1}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func waitStatus(um UtxoManager, healthy bool, tm time.Duration) (NodeStatus, bool) {
+	deadline := time.After(tm)
+	for {
+		s := um.Status()
+		if s.Healthy == healthy {
+			return s, true
+		}
+		select {
+		case <-deadline:
+			return s, false
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestNodeReconnect(t *testing.T) {
+	tick, minBackoff, maxBackoff := CheckTick, ReconnectMinBackoff, ReconnectMaxBackoff
+	CheckTick, ReconnectMinBackoff, ReconnectMaxBackoff = 10*time.Millisecond, 10*time.Millisecond, 40*time.Millisecond
+	defer func() {
+		CheckTick, ReconnectMinBackoff, ReconnectMaxBackoff = tick, minBackoff, maxBackoff
+	}()
+
+	var down int32
+	node := newTestNode(&down)
+	defer node.Close()
+
+	um := NewUtxoManager(strings.TrimPrefix(node.URL, "http://"), 10, []string{"fyqX5YuwXMUs4GEUE3LjLyhrqvNztFHQ4B"})
+	closing := make(chan bool)
+	done := make(chan bool)
+	go func() {
+		um.Start(closing)
+		close(done)
+	}()
+
+	assert.True(t, um.Status().Healthy)
+
+	// node goes down.
+	atomic.StoreInt32(&down, 1)
+	s, ok := waitStatus(um, false, time.Second)
+	if !ok {
+		t.Fatal("node outage is not detected")
+	}
+	assert.NotEmpty(t, s.Error)
+
+	// keeps retrying while the node is down.
+	time.Sleep(100 * time.Millisecond)
+	s = um.Status()
+	assert.False(t, s.Healthy)
+	assert.True(t, s.Retries > 0)
+
+	// node recovers.
+	atomic.StoreInt32(&down, 0)
+	s, ok = waitStatus(um, true, time.Second)
+	if !ok {
+		t.Fatal("node is not reconnected")
+	}
+	assert.Empty(t, s.Error)
+	assert.Equal(t, 0, s.Retries)
+
+	// can be stopped while reconnecting.
+	atomic.StoreInt32(&down, 1)
+	if _, ok := waitStatus(um, false, time.Second); !ok {
+		t.Fatal("node outage is not detected")
+	}
+	close(closing)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("utxo manager is not stopped while reconnecting")
+	}
+}
@@ -89,6 +89,24 @@ func Encrypt(r interface{}, pubkey string, seckey string) (data []byte, nonce []
 	return
 }
 
+// Sign signs the sha256 hash of the json encoded request with seckey, and returns
+// the signature in hex.
+func Sign(r interface{}, seckey string) (sig string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("sign failed")
+		}
+	}()
+	d, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+
+	s := cipher.MustSecKeyFromHex(seckey)
+	sig = cipher.SignHash(cipher.SumSHA256(d), s).Hex()
+	return
+}
+
 func Decrypt(in []byte, nonce []byte, pubkey string, seckey string) (data []byte, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -63,22 +63,209 @@ func (m *CreateAccountRes) GetCreatedAt() int64 {
 	return 0
 }
 
+type GetAccountNonceReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetAccountNonceReq) Reset()                    { *m = GetAccountNonceReq{} }
+func (m *GetAccountNonceReq) String() string            { return proto.CompactTextString(m) }
+func (*GetAccountNonceReq) ProtoMessage()               {}
+func (*GetAccountNonceReq) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
+
+func (m *GetAccountNonceReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+type GetAccountNonceRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Nonce            *uint64 `protobuf:"varint,11,opt,name=nonce" json:"nonce,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetAccountNonceRes) Reset()                    { *m = GetAccountNonceRes{} }
+func (m *GetAccountNonceRes) String() string            { return proto.CompactTextString(m) }
+func (*GetAccountNonceRes) ProtoMessage()               {}
+func (*GetAccountNonceRes) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{3} }
+
+func (m *GetAccountNonceRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetAccountNonceRes) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *GetAccountNonceRes) GetNonce() uint64 {
+	if m != nil && m.Nonce != nil {
+		return *m.Nonce
+	}
+	return 0
+}
+
+type EnrollTOTPReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Nonce            *uint64 `protobuf:"varint,9,opt,name=nonce" json:"nonce,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *EnrollTOTPReq) Reset()                    { *m = EnrollTOTPReq{} }
+func (m *EnrollTOTPReq) String() string            { return proto.CompactTextString(m) }
+func (*EnrollTOTPReq) ProtoMessage()               {}
+func (*EnrollTOTPReq) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{4} }
+
+func (m *EnrollTOTPReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *EnrollTOTPReq) GetNonce() uint64 {
+	if m != nil && m.Nonce != nil {
+		return *m.Nonce
+	}
+	return 0
+}
+
+type EnrollTOTPRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Secret           *string `protobuf:"bytes,11,opt,name=secret" json:"secret,omitempty"`
+	Uri              *string `protobuf:"bytes,12,opt,name=uri" json:"uri,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *EnrollTOTPRes) Reset()                    { *m = EnrollTOTPRes{} }
+func (m *EnrollTOTPRes) String() string            { return proto.CompactTextString(m) }
+func (*EnrollTOTPRes) ProtoMessage()               {}
+func (*EnrollTOTPRes) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{5} }
+
+func (m *EnrollTOTPRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *EnrollTOTPRes) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *EnrollTOTPRes) GetSecret() string {
+	if m != nil && m.Secret != nil {
+		return *m.Secret
+	}
+	return ""
+}
+
+func (m *EnrollTOTPRes) GetUri() string {
+	if m != nil && m.Uri != nil {
+		return *m.Uri
+	}
+	return ""
+}
+
+type ConfirmTOTPReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Nonce            *uint64 `protobuf:"varint,9,opt,name=nonce" json:"nonce,omitempty"`
+	Code             *string `protobuf:"bytes,11,opt,name=code" json:"code,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *ConfirmTOTPReq) Reset()                    { *m = ConfirmTOTPReq{} }
+func (m *ConfirmTOTPReq) String() string            { return proto.CompactTextString(m) }
+func (*ConfirmTOTPReq) ProtoMessage()               {}
+func (*ConfirmTOTPReq) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{6} }
+
+func (m *ConfirmTOTPReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *ConfirmTOTPReq) GetNonce() uint64 {
+	if m != nil && m.Nonce != nil {
+		return *m.Nonce
+	}
+	return 0
+}
+
+func (m *ConfirmTOTPReq) GetCode() string {
+	if m != nil && m.Code != nil {
+		return *m.Code
+	}
+	return ""
+}
+
+type ConfirmTOTPRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *ConfirmTOTPRes) Reset()                    { *m = ConfirmTOTPRes{} }
+func (m *ConfirmTOTPRes) String() string            { return proto.CompactTextString(m) }
+func (*ConfirmTOTPRes) ProtoMessage()               {}
+func (*ConfirmTOTPRes) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{7} }
+
+func (m *ConfirmTOTPRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *ConfirmTOTPRes) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*CreateAccountReq)(nil), "pp.CreateAccountReq")
 	proto.RegisterType((*CreateAccountRes)(nil), "pp.CreateAccountRes")
+	proto.RegisterType((*GetAccountNonceReq)(nil), "pp.GetAccountNonceReq")
+	proto.RegisterType((*GetAccountNonceRes)(nil), "pp.GetAccountNonceRes")
+	proto.RegisterType((*EnrollTOTPReq)(nil), "pp.EnrollTOTPReq")
+	proto.RegisterType((*EnrollTOTPRes)(nil), "pp.EnrollTOTPRes")
+	proto.RegisterType((*ConfirmTOTPReq)(nil), "pp.ConfirmTOTPReq")
+	proto.RegisterType((*ConfirmTOTPRes)(nil), "pp.ConfirmTOTPRes")
 }
 
 func init() { proto.RegisterFile("pp.account.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 140 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x12, 0x28, 0x28, 0xd0, 0x4b,
-	0x4c, 0x4e, 0xce, 0x2f, 0xcd, 0x2b, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2a, 0x28,
-	0x90, 0xe2, 0x2f, 0x28, 0xd0, 0x4b, 0xce, 0xcf, 0xcd, 0xcd, 0xcf, 0x83, 0x08, 0x2a, 0x29, 0x71,
-	0x09, 0x38, 0x17, 0xa5, 0x26, 0x96, 0xa4, 0x3a, 0x42, 0xd4, 0x06, 0xa5, 0x16, 0x0a, 0xf1, 0x71,
-	0xb1, 0x15, 0x94, 0x26, 0x65, 0xa7, 0x56, 0x4a, 0x70, 0x29, 0x30, 0x6a, 0x70, 0x2a, 0x05, 0x61,
-	0xa8, 0x29, 0x16, 0x92, 0xe2, 0x62, 0x2b, 0x4a, 0x2d, 0x2e, 0xcd, 0x29, 0x91, 0x60, 0x54, 0x60,
-	0xd2, 0xe0, 0x36, 0xe2, 0xd2, 0x2b, 0x28, 0xd0, 0x0b, 0x02, 0x8b, 0xa0, 0xeb, 0x17, 0x12, 0xe2,
-	0xe2, 0x4a, 0x06, 0xeb, 0x4f, 0x89, 0x4f, 0x2c, 0x91, 0x10, 0x51, 0x60, 0xd4, 0x60, 0x06, 0x04,
-	0x00, 0x00, 0xff, 0xff, 0xaf, 0xad, 0x53, 0x4e, 0x9f, 0x00, 0x00, 0x00,
+	// 242 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x8f, 0x31, 0x4b, 0xc3, 0x40,
+	0x14, 0x80, 0x49, 0x5b, 0x03, 0x79, 0x69, 0x63, 0x39, 0x1c, 0x8e, 0x4e, 0xe1, 0x70, 0xc8, 0x74,
+	0x83, 0xb3, 0x0e, 0x52, 0xc4, 0xcd, 0x4a, 0xe8, 0xe0, 0x26, 0xf1, 0xfa, 0x84, 0x62, 0x72, 0xef,
+	0x79, 0xb9, 0x1b, 0xfc, 0xf7, 0xe2, 0x45, 0x10, 0x6b, 0x06, 0xb3, 0x3e, 0xbe, 0xef, 0x7d, 0xef,
+	0xc1, 0x9a, 0x59, 0x37, 0xc6, 0x50, 0xb0, 0x5e, 0xb3, 0x23, 0x4f, 0x62, 0xc6, 0xbc, 0x39, 0x67,
+	0xd6, 0x86, 0xba, 0x8e, 0xec, 0x30, 0x54, 0x0a, 0xd6, 0x5b, 0x87, 0x8d, 0xc7, 0xdb, 0x81, 0xad,
+	0xf1, 0x5d, 0x14, 0x90, 0x72, 0x78, 0x79, 0xc3, 0x0f, 0x09, 0x65, 0x52, 0x65, 0xaa, 0xfe, 0xc3,
+	0xf4, 0x62, 0x03, 0xa9, 0xc3, 0x3e, 0xb4, 0x5e, 0x26, 0xe5, 0xac, 0xca, 0xaf, 0x40, 0x33, 0xeb,
+	0x3a, 0x4e, 0x4e, 0x7d, 0x21, 0x00, 0x4c, 0xf4, 0x0f, 0xcf, 0x8d, 0x97, 0x17, 0x65, 0x52, 0xcd,
+	0xd5, 0x25, 0x88, 0x7b, 0xf4, 0xdf, 0x0b, 0x1f, 0xc8, 0x1a, 0x1c, 0x2b, 0xef, 0x46, 0xa8, 0x69,
+	0xed, 0x15, 0x9c, 0xd9, 0x2f, 0x4f, 0xe6, 0x65, 0x52, 0x2d, 0x94, 0x86, 0xd5, 0x9d, 0x75, 0xd4,
+	0xb6, 0xfb, 0xdd, 0xfe, 0x71, 0xa4, 0xf8, 0xc3, 0x67, 0x91, 0x7f, 0xfa, 0xcd, 0x4f, 0x6b, 0x17,
+	0x90, 0xf6, 0x68, 0x1c, 0xfa, 0x18, 0xcf, 0x44, 0x0e, 0xf3, 0xe0, 0x8e, 0x72, 0x19, 0x5f, 0xbb,
+	0x81, 0x62, 0x4b, 0xf6, 0xf5, 0xe8, 0xba, 0xff, 0x9d, 0x22, 0x96, 0xb0, 0x30, 0x74, 0x18, 0x1e,
+	0xc9, 0xd4, 0xf5, 0x89, 0x3e, 0xe9, 0xb2, 0xcf, 0x01, 0x00, 0x85, 0x7c, 0x2b, 0x6b, 0x1d, 0x02,
+	0x00, 0x00,
 }
@@ -71,24 +71,501 @@ func (m *UpdateCreditRes) GetResult() *Result {
 	return nil
 }
 
+type AdminCreateAccountReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Dst              *string `protobuf:"bytes,20,opt,name=dst" json:"dst,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminCreateAccountReq) Reset()                    { *m = AdminCreateAccountReq{} }
+func (m *AdminCreateAccountReq) String() string            { return proto.CompactTextString(m) }
+func (*AdminCreateAccountReq) ProtoMessage()               {}
+func (*AdminCreateAccountReq) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{2} }
+
+func (m *AdminCreateAccountReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *AdminCreateAccountReq) GetDst() string {
+	if m != nil && m.Dst != nil {
+		return *m.Dst
+	}
+	return ""
+}
+
+type AdminCreateAccountRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	AccountId        *string `protobuf:"bytes,10,opt,name=account_id" json:"account_id,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminCreateAccountRes) Reset()                    { *m = AdminCreateAccountRes{} }
+func (m *AdminCreateAccountRes) String() string            { return proto.CompactTextString(m) }
+func (*AdminCreateAccountRes) ProtoMessage()               {}
+func (*AdminCreateAccountRes) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{3} }
+
+func (m *AdminCreateAccountRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *AdminCreateAccountRes) GetAccountId() string {
+	if m != nil && m.AccountId != nil {
+		return *m.AccountId
+	}
+	return ""
+}
+
+type AdminDeleteAccountReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	AccountId        *string `protobuf:"bytes,20,opt,name=account_id" json:"account_id,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminDeleteAccountReq) Reset()                    { *m = AdminDeleteAccountReq{} }
+func (m *AdminDeleteAccountReq) String() string            { return proto.CompactTextString(m) }
+func (*AdminDeleteAccountReq) ProtoMessage()               {}
+func (*AdminDeleteAccountReq) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{4} }
+
+func (m *AdminDeleteAccountReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *AdminDeleteAccountReq) GetAccountId() string {
+	if m != nil && m.AccountId != nil {
+		return *m.AccountId
+	}
+	return ""
+}
+
+type AdminDeleteAccountRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminDeleteAccountRes) Reset()                    { *m = AdminDeleteAccountRes{} }
+func (m *AdminDeleteAccountRes) String() string            { return proto.CompactTextString(m) }
+func (*AdminDeleteAccountRes) ProtoMessage()               {}
+func (*AdminDeleteAccountRes) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{5} }
+
+func (m *AdminDeleteAccountRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+type AdminAddCoinPairReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	CoinPair         *string `protobuf:"bytes,20,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminAddCoinPairReq) Reset()                    { *m = AdminAddCoinPairReq{} }
+func (m *AdminAddCoinPairReq) String() string            { return proto.CompactTextString(m) }
+func (*AdminAddCoinPairReq) ProtoMessage()               {}
+func (*AdminAddCoinPairReq) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{6} }
+
+func (m *AdminAddCoinPairReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *AdminAddCoinPairReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+type AdminAddCoinPairRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminAddCoinPairRes) Reset()                    { *m = AdminAddCoinPairRes{} }
+func (m *AdminAddCoinPairRes) String() string            { return proto.CompactTextString(m) }
+func (*AdminAddCoinPairRes) ProtoMessage()               {}
+func (*AdminAddCoinPairRes) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{7} }
+
+func (m *AdminAddCoinPairRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+type AdminHaltPairReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	CoinPair         *string `protobuf:"bytes,20,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminHaltPairReq) Reset()                    { *m = AdminHaltPairReq{} }
+func (m *AdminHaltPairReq) String() string            { return proto.CompactTextString(m) }
+func (*AdminHaltPairReq) ProtoMessage()               {}
+func (*AdminHaltPairReq) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{8} }
+
+func (m *AdminHaltPairReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *AdminHaltPairReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+type AdminHaltPairRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminHaltPairRes) Reset()                    { *m = AdminHaltPairRes{} }
+func (m *AdminHaltPairRes) String() string            { return proto.CompactTextString(m) }
+func (*AdminHaltPairRes) ProtoMessage()               {}
+func (*AdminHaltPairRes) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{9} }
+
+func (m *AdminHaltPairRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+type AdminSetPriceBandReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	CoinPair         *string `protobuf:"bytes,20,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	PriceBand        *uint64 `protobuf:"varint,30,opt,name=price_band" json:"price_band,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminSetPriceBandReq) Reset()                    { *m = AdminSetPriceBandReq{} }
+func (m *AdminSetPriceBandReq) String() string            { return proto.CompactTextString(m) }
+func (*AdminSetPriceBandReq) ProtoMessage()               {}
+func (*AdminSetPriceBandReq) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{10} }
+
+func (m *AdminSetPriceBandReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *AdminSetPriceBandReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *AdminSetPriceBandReq) GetPriceBand() uint64 {
+	if m != nil && m.PriceBand != nil {
+		return *m.PriceBand
+	}
+	return 0
+}
+
+type AdminSetPriceBandRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminSetPriceBandRes) Reset()                    { *m = AdminSetPriceBandRes{} }
+func (m *AdminSetPriceBandRes) String() string            { return proto.CompactTextString(m) }
+func (*AdminSetPriceBandRes) ProtoMessage()               {}
+func (*AdminSetPriceBandRes) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{11} }
+
+func (m *AdminSetPriceBandRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+type AdminResumePairReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	CoinPair         *string `protobuf:"bytes,20,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminResumePairReq) Reset()                    { *m = AdminResumePairReq{} }
+func (m *AdminResumePairReq) String() string            { return proto.CompactTextString(m) }
+func (*AdminResumePairReq) ProtoMessage()               {}
+func (*AdminResumePairReq) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{12} }
+
+func (m *AdminResumePairReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *AdminResumePairReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+type AdminResumePairRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminResumePairRes) Reset()                    { *m = AdminResumePairRes{} }
+func (m *AdminResumePairRes) String() string            { return proto.CompactTextString(m) }
+func (*AdminResumePairRes) ProtoMessage()               {}
+func (*AdminResumePairRes) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{13} }
+
+func (m *AdminResumePairRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+type AdminGetUtxoStatsReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	CoinType         *string `protobuf:"bytes,20,opt,name=coin_type" json:"coin_type,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminGetUtxoStatsReq) Reset()                    { *m = AdminGetUtxoStatsReq{} }
+func (m *AdminGetUtxoStatsReq) String() string            { return proto.CompactTextString(m) }
+func (*AdminGetUtxoStatsReq) ProtoMessage()               {}
+func (*AdminGetUtxoStatsReq) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{14} }
+
+func (m *AdminGetUtxoStatsReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *AdminGetUtxoStatsReq) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+type AdminGetUtxoStatsRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinType         *string `protobuf:"bytes,10,opt,name=coin_type" json:"coin_type,omitempty"`
+	Available        *int64  `protobuf:"varint,20,opt,name=available" json:"available,omitempty"`
+	Borrowed         *int64  `protobuf:"varint,30,opt,name=borrowed" json:"borrowed,omitempty"`
+	TotalValue       *uint64 `protobuf:"varint,40,opt,name=total_value" json:"total_value,omitempty"`
+	LastRefill       *int64  `protobuf:"varint,50,opt,name=last_refill" json:"last_refill,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminGetUtxoStatsRes) Reset()                    { *m = AdminGetUtxoStatsRes{} }
+func (m *AdminGetUtxoStatsRes) String() string            { return proto.CompactTextString(m) }
+func (*AdminGetUtxoStatsRes) ProtoMessage()               {}
+func (*AdminGetUtxoStatsRes) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{15} }
+
+func (m *AdminGetUtxoStatsRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *AdminGetUtxoStatsRes) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+func (m *AdminGetUtxoStatsRes) GetAvailable() int64 {
+	if m != nil && m.Available != nil {
+		return *m.Available
+	}
+	return 0
+}
+
+func (m *AdminGetUtxoStatsRes) GetBorrowed() int64 {
+	if m != nil && m.Borrowed != nil {
+		return *m.Borrowed
+	}
+	return 0
+}
+
+func (m *AdminGetUtxoStatsRes) GetTotalValue() uint64 {
+	if m != nil && m.TotalValue != nil {
+		return *m.TotalValue
+	}
+	return 0
+}
+
+func (m *AdminGetUtxoStatsRes) GetLastRefill() int64 {
+	if m != nil && m.LastRefill != nil {
+		return *m.LastRefill
+	}
+	return 0
+}
+
+type AdminFreezeAccountReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	AccountId        *string `protobuf:"bytes,20,opt,name=account_id" json:"account_id,omitempty"`
+	CancelOrders     *bool   `protobuf:"varint,30,opt,name=cancel_orders" json:"cancel_orders,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminFreezeAccountReq) Reset()                    { *m = AdminFreezeAccountReq{} }
+func (m *AdminFreezeAccountReq) String() string            { return proto.CompactTextString(m) }
+func (*AdminFreezeAccountReq) ProtoMessage()               {}
+func (*AdminFreezeAccountReq) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{16} }
+
+func (m *AdminFreezeAccountReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *AdminFreezeAccountReq) GetAccountId() string {
+	if m != nil && m.AccountId != nil {
+		return *m.AccountId
+	}
+	return ""
+}
+
+func (m *AdminFreezeAccountReq) GetCancelOrders() bool {
+	if m != nil && m.CancelOrders != nil {
+		return *m.CancelOrders
+	}
+	return false
+}
+
+type AdminFreezeAccountRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminFreezeAccountRes) Reset()                    { *m = AdminFreezeAccountRes{} }
+func (m *AdminFreezeAccountRes) String() string            { return proto.CompactTextString(m) }
+func (*AdminFreezeAccountRes) ProtoMessage()               {}
+func (*AdminFreezeAccountRes) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{17} }
+
+func (m *AdminFreezeAccountRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+type AdminUnfreezeAccountReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	AccountId        *string `protobuf:"bytes,20,opt,name=account_id" json:"account_id,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminUnfreezeAccountReq) Reset()                    { *m = AdminUnfreezeAccountReq{} }
+func (m *AdminUnfreezeAccountReq) String() string            { return proto.CompactTextString(m) }
+func (*AdminUnfreezeAccountReq) ProtoMessage()               {}
+func (*AdminUnfreezeAccountReq) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{18} }
+
+func (m *AdminUnfreezeAccountReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *AdminUnfreezeAccountReq) GetAccountId() string {
+	if m != nil && m.AccountId != nil {
+		return *m.AccountId
+	}
+	return ""
+}
+
+type AdminUnfreezeAccountRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AdminUnfreezeAccountRes) Reset()                    { *m = AdminUnfreezeAccountRes{} }
+func (m *AdminUnfreezeAccountRes) String() string            { return proto.CompactTextString(m) }
+func (*AdminUnfreezeAccountRes) ProtoMessage()               {}
+func (*AdminUnfreezeAccountRes) Descriptor() ([]byte, []int) { return fileDescriptor11, []int{19} }
+
+func (m *AdminUnfreezeAccountRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*UpdateCreditReq)(nil), "pp.UpdateCreditReq")
 	proto.RegisterType((*UpdateCreditRes)(nil), "pp.UpdateCreditRes")
+	proto.RegisterType((*AdminCreateAccountReq)(nil), "pp.AdminCreateAccountReq")
+	proto.RegisterType((*AdminCreateAccountRes)(nil), "pp.AdminCreateAccountRes")
+	proto.RegisterType((*AdminDeleteAccountReq)(nil), "pp.AdminDeleteAccountReq")
+	proto.RegisterType((*AdminDeleteAccountRes)(nil), "pp.AdminDeleteAccountRes")
+	proto.RegisterType((*AdminAddCoinPairReq)(nil), "pp.AdminAddCoinPairReq")
+	proto.RegisterType((*AdminAddCoinPairRes)(nil), "pp.AdminAddCoinPairRes")
+	proto.RegisterType((*AdminHaltPairReq)(nil), "pp.AdminHaltPairReq")
+	proto.RegisterType((*AdminHaltPairRes)(nil), "pp.AdminHaltPairRes")
+	proto.RegisterType((*AdminSetPriceBandReq)(nil), "pp.AdminSetPriceBandReq")
+	proto.RegisterType((*AdminSetPriceBandRes)(nil), "pp.AdminSetPriceBandRes")
+	proto.RegisterType((*AdminResumePairReq)(nil), "pp.AdminResumePairReq")
+	proto.RegisterType((*AdminResumePairRes)(nil), "pp.AdminResumePairRes")
+	proto.RegisterType((*AdminGetUtxoStatsReq)(nil), "pp.AdminGetUtxoStatsReq")
+	proto.RegisterType((*AdminGetUtxoStatsRes)(nil), "pp.AdminGetUtxoStatsRes")
+	proto.RegisterType((*AdminFreezeAccountReq)(nil), "pp.AdminFreezeAccountReq")
+	proto.RegisterType((*AdminFreezeAccountRes)(nil), "pp.AdminFreezeAccountRes")
+	proto.RegisterType((*AdminUnfreezeAccountReq)(nil), "pp.AdminUnfreezeAccountReq")
+	proto.RegisterType((*AdminUnfreezeAccountRes)(nil), "pp.AdminUnfreezeAccountRes")
 }
 
 func init() { proto.RegisterFile("pp.admin.proto", fileDescriptor11) }
 
 var fileDescriptor11 = []byte{
-	// 162 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x5c, 0xcc, 0xb1, 0xaa, 0x83, 0x30,
-	0x14, 0x87, 0x71, 0xf4, 0x5e, 0x04, 0x8f, 0xa0, 0x34, 0x74, 0x08, 0x0e, 0x45, 0x9c, 0xb2, 0x34,
-	0x43, 0x5f, 0xa1, 0x4f, 0x50, 0xa1, 0x73, 0xb1, 0x26, 0x83, 0xb4, 0xf1, 0xfc, 0xab, 0x27, 0x83,
-	0x6f, 0x5f, 0x2a, 0x9d, 0xba, 0xfe, 0x3e, 0xf8, 0xa8, 0x04, 0x6c, 0xef, 0xc2, 0x38, 0x59, 0xcc,
-	0x2c, 0xac, 0x52, 0xa0, 0xae, 0x00, 0x3b, 0x70, 0x08, 0xfc, 0xc5, 0xf6, 0x42, 0xd5, 0x15, 0xae,
-	0x17, 0x7f, 0x9e, 0xbd, 0x1b, 0xa5, 0xf3, 0x2f, 0x55, 0x52, 0x86, 0x78, 0x7f, 0xf8, 0x55, 0x53,
-	0x93, 0x98, 0x5c, 0xed, 0x28, 0x1f, 0x78, 0x9c, 0x6e, 0xb2, 0xc2, 0xeb, 0xfd, 0x46, 0x25, 0x65,
-	0x7d, 0xe0, 0x38, 0x89, 0x3e, 0x34, 0x89, 0xf9, 0x57, 0x05, 0xfd, 0xb9, 0x45, 0xb4, 0xf9, 0xc4,
-	0xf6, 0xf8, 0xbb, 0x5c, 0x54, 0x4d, 0xd9, 0xec, 0x97, 0xf8, 0x14, 0x9d, 0x34, 0xa9, 0x29, 0x4e,
-	0x64, 0x01, 0xdb, 0x6d, 0xf2, 0x0e, 0x00, 0x00, 0xff, 0xff, 0xb5, 0x78, 0x04, 0x24, 0xa7, 0x00,
-	0x00, 0x00,
+	// 436 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x93, 0x41, 0x6f, 0xd3, 0x40,
+	0x10, 0x85, 0x95, 0xa6, 0x8a, 0xda, 0x89, 0x48, 0xcb, 0xb6, 0x15, 0x56, 0x0f, 0x28, 0xda, 0x53,
+	0x2e, 0x58, 0x90, 0x52, 0x01, 0x42, 0x1c, 0x42, 0x10, 0xe5, 0x82, 0x54, 0x52, 0xe5, 0x6c, 0x4d,
+	0xbc, 0x53, 0x69, 0xc5, 0xda, 0xbb, 0xac, 0xc7, 0x85, 0xf2, 0x3b, 0xf8, 0xc1, 0xc8, 0x6b, 0x0b,
+	0xa9, 0x60, 0x56, 0x4d, 0x8f, 0x1e, 0xcf, 0xf7, 0xde, 0xdb, 0xd1, 0x0c, 0x4c, 0x9c, 0x4b, 0x51,
+	0x15, 0xba, 0x4c, 0x9d, 0xb7, 0x6c, 0xc5, 0x8e, 0x73, 0xa7, 0x07, 0xce, 0xa5, 0xb9, 0x2d, 0x0a,
+	0xdb, 0x15, 0xe5, 0x17, 0x38, 0x58, 0x3b, 0x85, 0x4c, 0x4b, 0x4f, 0x4a, 0xf3, 0x8a, 0xbe, 0x89,
+	0x09, 0x8c, 0x5c, 0xbd, 0xf9, 0x4a, 0xb7, 0x09, 0x4c, 0x07, 0xb3, 0x7d, 0xf1, 0x18, 0xf6, 0x73,
+	0xab, 0xcb, 0x8c, 0x6f, 0x1d, 0x25, 0xc7, 0xa1, 0x34, 0x81, 0x11, 0x16, 0xb6, 0x2e, 0x39, 0x79,
+	0x3a, 0x1d, 0xcc, 0x76, 0xc5, 0x18, 0x86, 0xaa, 0xe2, 0x64, 0xd6, 0xfc, 0x94, 0xcf, 0xfe, 0x96,
+	0xac, 0xc4, 0x29, 0x8c, 0x3c, 0x55, 0xb5, 0xe1, 0x64, 0x30, 0xdd, 0x99, 0x8d, 0xe7, 0x90, 0x3a,
+	0x97, 0xae, 0x42, 0x45, 0xbe, 0x84, 0x93, 0x45, 0x93, 0x72, 0xe9, 0x09, 0x99, 0x16, 0x79, 0xde,
+	0xe8, 0xf6, 0xe5, 0xe8, 0x4c, 0x42, 0x02, 0x79, 0xd1, 0x4f, 0x45, 0xad, 0x84, 0x00, 0xc0, 0xb6,
+	0x33, 0xd3, 0xaa, 0x55, 0x95, 0x6f, 0x3b, 0xa1, 0x0f, 0x64, 0x28, 0x6a, 0x7f, 0x17, 0x6e, 0x53,
+	0x9c, 0xf5, 0xc3, 0xf1, 0x07, 0xbf, 0x86, 0xa3, 0x00, 0x2d, 0x94, 0x5a, 0x5a, 0x5d, 0x5e, 0xa2,
+	0xf6, 0xb1, 0xb1, 0x3b, 0xd4, 0xbe, 0xb3, 0x7b, 0xd1, 0x47, 0xc6, 0xcd, 0xce, 0xe1, 0x30, 0x20,
+	0x9f, 0xd0, 0xf0, 0x16, 0x4e, 0xe9, 0x3f, 0x58, 0xdc, 0xe6, 0x33, 0x1c, 0x87, 0xfe, 0x2b, 0xe2,
+	0x4b, 0xaf, 0x73, 0x7a, 0x8f, 0xa5, 0xba, 0x9f, 0x55, 0x33, 0x57, 0xd7, 0x20, 0xd9, 0x06, 0x4b,
+	0xd5, 0xee, 0x93, 0x9c, 0xf7, 0xca, 0xc5, 0x23, 0xbc, 0x02, 0x11, 0x98, 0xe6, 0xb3, 0xa0, 0x2d,
+	0xde, 0xfa, 0xbc, 0x07, 0x8c, 0x5b, 0xbd, 0xe9, 0xe2, 0x5d, 0x10, 0xaf, 0xf9, 0x87, 0xbd, 0x62,
+	0xe4, 0xea, 0x7e, 0x97, 0x23, 0x7f, 0x0d, 0x7a, 0xd9, 0xf8, 0xde, 0xde, 0xd1, 0xf9, 0x23, 0x8d,
+	0x37, 0xa8, 0x0d, 0x6e, 0x4c, 0x2b, 0x3d, 0x14, 0x87, 0xb0, 0xb7, 0xb1, 0xde, 0xdb, 0xef, 0xd4,
+	0x8e, 0x71, 0x28, 0x8e, 0x60, 0xcc, 0x96, 0xd1, 0x64, 0x37, 0x68, 0x6a, 0x0a, 0xe7, 0xb9, 0xdb,
+	0x14, 0x0d, 0x56, 0x9c, 0x79, 0xba, 0xd6, 0xc6, 0x24, 0xf3, 0xa6, 0x53, 0xae, 0xba, 0x45, 0xfe,
+	0xe8, 0x89, 0x7e, 0x6e, 0x79, 0x05, 0xe2, 0x04, 0x1e, 0xe5, 0x58, 0xe6, 0x64, 0x32, 0xeb, 0x15,
+	0xf9, 0x2a, 0xb8, 0xef, 0xc9, 0xb3, 0x7e, 0xcd, 0xf8, 0x68, 0xdf, 0xc1, 0x93, 0x00, 0xad, 0xcb,
+	0xeb, 0x07, 0x44, 0x91, 0xe7, 0xff, 0xc3, 0xa3, 0xae, 0xbf, 0x07, 0x00, 0xc7, 0x24, 0x61, 0x7f,
+	0x2b, 0x05, 0x00, 0x00,
 }
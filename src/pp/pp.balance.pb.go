@@ -90,6 +90,82 @@ func (m *GetAccountBalanceRes) GetBalance() *Balance {
 	return nil
 }
 
+// CoinBalance the available and reserved balance of a coin in the account.
+type CoinBalance struct {
+	CoinType         *string `protobuf:"bytes,10,opt,name=coin_type" json:"coin_type,omitempty"`
+	Available        *uint64 `protobuf:"varint,11,opt,name=available" json:"available,omitempty"`
+	Reserved         *uint64 `protobuf:"varint,12,opt,name=reserved" json:"reserved,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *CoinBalance) Reset()                    { *m = CoinBalance{} }
+func (m *CoinBalance) String() string            { return proto.CompactTextString(m) }
+func (*CoinBalance) ProtoMessage()               {}
+func (*CoinBalance) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{3} }
+
+func (m *CoinBalance) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+func (m *CoinBalance) GetAvailable() uint64 {
+	if m != nil && m.Available != nil {
+		return *m.Available
+	}
+	return 0
+}
+
+func (m *CoinBalance) GetReserved() uint64 {
+	if m != nil && m.Reserved != nil {
+		return *m.Reserved
+	}
+	return 0
+}
+
+type GetAccountBalancesReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetAccountBalancesReq) Reset()                    { *m = GetAccountBalancesReq{} }
+func (m *GetAccountBalancesReq) String() string            { return proto.CompactTextString(m) }
+func (*GetAccountBalancesReq) ProtoMessage()               {}
+func (*GetAccountBalancesReq) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{4} }
+
+func (m *GetAccountBalancesReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+type GetAccountBalancesRes struct {
+	Result           *Result        `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Balances         []*CoinBalance `protobuf:"bytes,10,rep,name=balances" json:"balances,omitempty"`
+	XXX_unrecognized []byte         `json:"-"`
+}
+
+func (m *GetAccountBalancesRes) Reset()                    { *m = GetAccountBalancesRes{} }
+func (m *GetAccountBalancesRes) String() string            { return proto.CompactTextString(m) }
+func (*GetAccountBalancesRes) ProtoMessage()               {}
+func (*GetAccountBalancesRes) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{5} }
+
+func (m *GetAccountBalancesRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetAccountBalancesRes) GetBalances() []*CoinBalance {
+	if m != nil {
+		return m.Balances
+	}
+	return nil
+}
+
 type GetAddrBalanceReq struct {
 	CoinType         *string `protobuf:"bytes,10,opt,name=coin_type" json:"coin_type,omitempty"`
 	Addrs            *string `protobuf:"bytes,20,opt,name=addrs" json:"addrs,omitempty"`
@@ -99,7 +175,7 @@ type GetAddrBalanceReq struct {
 func (m *GetAddrBalanceReq) Reset()                    { *m = GetAddrBalanceReq{} }
 func (m *GetAddrBalanceReq) String() string            { return proto.CompactTextString(m) }
 func (*GetAddrBalanceReq) ProtoMessage()               {}
-func (*GetAddrBalanceReq) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{3} }
+func (*GetAddrBalanceReq) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{6} }
 
 func (m *GetAddrBalanceReq) GetCoinType() string {
 	if m != nil && m.CoinType != nil {
@@ -124,7 +200,7 @@ type GetAddrBalanceRes struct {
 func (m *GetAddrBalanceRes) Reset()                    { *m = GetAddrBalanceRes{} }
 func (m *GetAddrBalanceRes) String() string            { return proto.CompactTextString(m) }
 func (*GetAddrBalanceRes) ProtoMessage()               {}
-func (*GetAddrBalanceRes) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{4} }
+func (*GetAddrBalanceRes) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{7} }
 
 func (m *GetAddrBalanceRes) GetResult() *Result {
 	if m != nil {
@@ -140,30 +216,109 @@ func (m *GetAddrBalanceRes) GetBalance() *Balance {
 	return nil
 }
 
+type GetAddrDetailedBalanceReq struct {
+	CoinType         *string `protobuf:"bytes,10,opt,name=coin_type" json:"coin_type,omitempty"`
+	Addrs            *string `protobuf:"bytes,20,opt,name=addrs" json:"addrs,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetAddrDetailedBalanceReq) Reset()                    { *m = GetAddrDetailedBalanceReq{} }
+func (m *GetAddrDetailedBalanceReq) String() string            { return proto.CompactTextString(m) }
+func (*GetAddrDetailedBalanceReq) ProtoMessage()               {}
+func (*GetAddrDetailedBalanceReq) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{8} }
+
+func (m *GetAddrDetailedBalanceReq) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+func (m *GetAddrDetailedBalanceReq) GetAddrs() string {
+	if m != nil && m.Addrs != nil {
+		return *m.Addrs
+	}
+	return ""
+}
+
+// GetAddrDetailedBalanceRes the unconfirmed balance is the pending deposits and changes.
+type GetAddrDetailedBalanceRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Confirmed        *uint64 `protobuf:"varint,10,opt,name=confirmed" json:"confirmed,omitempty"`
+	Unconfirmed      *uint64 `protobuf:"varint,11,opt,name=unconfirmed" json:"unconfirmed,omitempty"`
+	Total            *uint64 `protobuf:"varint,12,opt,name=total" json:"total,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetAddrDetailedBalanceRes) Reset()                    { *m = GetAddrDetailedBalanceRes{} }
+func (m *GetAddrDetailedBalanceRes) String() string            { return proto.CompactTextString(m) }
+func (*GetAddrDetailedBalanceRes) ProtoMessage()               {}
+func (*GetAddrDetailedBalanceRes) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{9} }
+
+func (m *GetAddrDetailedBalanceRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetAddrDetailedBalanceRes) GetConfirmed() uint64 {
+	if m != nil && m.Confirmed != nil {
+		return *m.Confirmed
+	}
+	return 0
+}
+
+func (m *GetAddrDetailedBalanceRes) GetUnconfirmed() uint64 {
+	if m != nil && m.Unconfirmed != nil {
+		return *m.Unconfirmed
+	}
+	return 0
+}
+
+func (m *GetAddrDetailedBalanceRes) GetTotal() uint64 {
+	if m != nil && m.Total != nil {
+		return *m.Total
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Balance)(nil), "pp.Balance")
 	proto.RegisterType((*GetAccountBalanceReq)(nil), "pp.GetAccountBalanceReq")
 	proto.RegisterType((*GetAccountBalanceRes)(nil), "pp.GetAccountBalanceRes")
+	proto.RegisterType((*CoinBalance)(nil), "pp.CoinBalance")
+	proto.RegisterType((*GetAccountBalancesReq)(nil), "pp.GetAccountBalancesReq")
+	proto.RegisterType((*GetAccountBalancesRes)(nil), "pp.GetAccountBalancesRes")
 	proto.RegisterType((*GetAddrBalanceReq)(nil), "pp.GetAddrBalanceReq")
 	proto.RegisterType((*GetAddrBalanceRes)(nil), "pp.GetAddrBalanceRes")
+	proto.RegisterType((*GetAddrDetailedBalanceReq)(nil), "pp.GetAddrDetailedBalanceReq")
+	proto.RegisterType((*GetAddrDetailedBalanceRes)(nil), "pp.GetAddrDetailedBalanceRes")
 }
 
 func init() { proto.RegisterFile("pp.balance.proto", fileDescriptor5) }
 
 var fileDescriptor5 = []byte{
-	// 222 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x8c, 0x8e, 0x3d, 0x4f, 0xc3, 0x30,
-	0x10, 0x86, 0x95, 0x8a, 0xb6, 0xca, 0x99, 0x02, 0xb5, 0x3a, 0x58, 0x15, 0x43, 0xe4, 0xc9, 0x93,
-	0x87, 0x4a, 0x0c, 0x8c, 0xb0, 0x30, 0x21, 0xa1, 0xfe, 0x01, 0xe4, 0xda, 0x96, 0x40, 0x34, 0xf6,
-	0xe1, 0x8f, 0x21, 0xff, 0x1e, 0xd9, 0xc9, 0x00, 0x0a, 0x43, 0xc7, 0x7b, 0xef, 0x7d, 0xee, 0x1e,
-	0xb8, 0x43, 0x94, 0x27, 0x75, 0x56, 0x4e, 0x5b, 0x89, 0xc1, 0x27, 0x4f, 0x17, 0x88, 0xfb, 0x5b,
-	0x44, 0xa9, 0x7d, 0xdf, 0x7b, 0x37, 0x86, 0x5c, 0xc0, 0xfa, 0x79, 0x6c, 0xd1, 0x1b, 0x58, 0xa9,
-	0xde, 0x67, 0x97, 0x18, 0x74, 0x8d, 0xb8, 0xa2, 0x1b, 0x58, 0x7e, 0xf8, 0x1c, 0x22, 0x23, 0x65,
-	0xe4, 0x8f, 0xb0, 0x7b, 0xb1, 0xe9, 0x49, 0xeb, 0x52, 0x99, 0x98, 0xa3, 0xfd, 0x2e, 0x18, 0xe6,
-	0xd3, 0x97, 0x1d, 0x2a, 0xd6, 0xd2, 0x2d, 0xb4, 0xda, 0x7f, 0xba, 0xf7, 0x34, 0xa0, 0xad, 0x68,
-	0xcb, 0xdf, 0xfe, 0x45, 0x23, 0xdd, 0xc3, 0x2a, 0xd8, 0x98, 0xcf, 0x89, 0x35, 0xdd, 0x42, 0x90,
-	0x03, 0x48, 0x44, 0x79, 0xac, 0x09, 0xbd, 0x87, 0xf5, 0xa4, 0xcf, 0xae, 0xbb, 0x46, 0x90, 0x03,
-	0x29, 0xcb, 0x09, 0xe6, 0x0f, 0xb0, 0x2d, 0x17, 0x8d, 0x09, 0xbf, 0x4c, 0xfe, 0x7c, 0x1e, 0x65,
-	0x36, 0xb0, 0x54, 0xc6, 0x84, 0xc8, 0x76, 0x55, 0xe4, 0x75, 0x8e, 0x5d, 0x6c, 0x01, 0x33, 0x8b,
-	0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x45, 0xa9, 0x54, 0x71, 0x64, 0x01, 0x00, 0x00,
+	// 327 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x92, 0xbd, 0x4f, 0xf3, 0x30,
+	0x10, 0x87, 0xd5, 0xbe, 0xfd, 0x3c, 0xbf, 0xa5, 0xad, 0x29, 0x52, 0xa8, 0x18, 0x82, 0x17, 0x32,
+	0x65, 0xa8, 0xc4, 0xc0, 0xc0, 0xc0, 0x97, 0x98, 0x90, 0x50, 0x07, 0x56, 0xe4, 0xc6, 0x87, 0x88,
+	0x48, 0x6c, 0x63, 0x3b, 0x95, 0xfa, 0xdf, 0xa3, 0x7c, 0x14, 0x8a, 0x12, 0x2a, 0x18, 0x73, 0xbe,
+	0xe7, 0xf2, 0xfc, 0xce, 0x86, 0x89, 0xd6, 0xe1, 0x8a, 0x27, 0x5c, 0x46, 0x18, 0x6a, 0xa3, 0x9c,
+	0xa2, 0x6d, 0xad, 0xe7, 0x63, 0xad, 0xc3, 0x48, 0xa5, 0xa9, 0x92, 0x65, 0x91, 0x05, 0xd0, 0xbf,
+	0x2e, 0xbb, 0xe8, 0x01, 0xf4, 0x78, 0xaa, 0x32, 0xe9, 0x3c, 0xf0, 0x5b, 0x41, 0x87, 0x8e, 0xa0,
+	0xfb, 0xaa, 0x32, 0x63, 0x3d, 0x92, 0x7f, 0xb2, 0x0b, 0x98, 0xdd, 0xa3, 0xbb, 0x8a, 0xa2, 0xbc,
+	0xa5, 0x62, 0x96, 0xf8, 0x9e, 0x63, 0x3a, 0x5b, 0xbd, 0xe1, 0xa6, 0xc0, 0x86, 0x74, 0x0a, 0xc3,
+	0x48, 0xc5, 0xf2, 0xd9, 0x6d, 0x34, 0x16, 0xe8, 0x90, 0x3d, 0x36, 0xa2, 0x96, 0xce, 0xa1, 0x67,
+	0xd0, 0x66, 0x89, 0xf3, 0x5a, 0x7e, 0x3b, 0x20, 0x0b, 0x08, 0xb5, 0x0e, 0x97, 0x45, 0x85, 0x9e,
+	0x40, 0xbf, 0xd2, 0xf7, 0xfe, 0xfb, 0xad, 0x80, 0x2c, 0x48, 0x7e, 0x58, 0xc1, 0xec, 0x0e, 0xc8,
+	0x8d, 0x8a, 0xe5, 0x56, 0xfd, 0xdb, 0x3f, 0x3f, 0x35, 0xf8, 0x9a, 0xc7, 0x09, 0x5f, 0x25, 0xa5,
+	0x46, 0x87, 0x4e, 0x60, 0x60, 0xd0, 0xa2, 0x59, 0xa3, 0x28, 0x66, 0x76, 0xd8, 0x19, 0x1c, 0xd5,
+	0xc4, 0x6c, 0x43, 0x28, 0xf6, 0xd4, 0xdc, 0xb8, 0x3f, 0xc2, 0x29, 0x0c, 0xaa, 0x08, 0xd6, 0x03,
+	0xff, 0x5f, 0x40, 0x16, 0xe3, 0xfc, 0x74, 0x47, 0x9c, 0x9d, 0xc3, 0x34, 0x9f, 0x2b, 0x84, 0xd9,
+	0xd9, 0x68, 0x43, 0x9a, 0x11, 0x74, 0xb9, 0x10, 0xc6, 0x7a, 0xb3, 0x42, 0xe7, 0xa1, 0x8e, 0xfd,
+	0x7a, 0x9b, 0x50, 0xdf, 0xe6, 0x25, 0x1c, 0x57, 0xe3, 0x6e, 0xd1, 0xf1, 0x38, 0x41, 0xf1, 0x27,
+	0x1b, 0xf9, 0x33, 0xbe, 0xdf, 0xaa, 0x18, 0x2d, 0x5f, 0x62, 0x93, 0xa2, 0xa8, 0x1e, 0xdd, 0x21,
+	0x90, 0x4c, 0x7e, 0x15, 0xc9, 0xf6, 0x25, 0x3a, 0xe5, 0x78, 0x52, 0xde, 0xda, 0xc7, 0x00, 0xf3,
+	0x93, 0x9d, 0x2f, 0xdb, 0x02, 0x00, 0x00,
 }
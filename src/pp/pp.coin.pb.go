@@ -55,21 +55,183 @@ func (m *CoinsRes) GetCoins() []string {
 	return nil
 }
 
+type CoinInfo struct {
+	Symbol           *string `protobuf:"bytes,1,opt,name=symbol" json:"symbol,omitempty"`
+	Name             *string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Type             *string `protobuf:"bytes,3,opt,name=type" json:"type,omitempty"`
+	Decimals         *int32  `protobuf:"varint,4,opt,name=decimals" json:"decimals,omitempty"`
+	MinAmount        *uint64 `protobuf:"varint,5,opt,name=min_amount" json:"min_amount,omitempty"`
+	AddressFormat    *string `protobuf:"bytes,6,opt,name=address_format" json:"address_format,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *CoinInfo) Reset()                    { *m = CoinInfo{} }
+func (m *CoinInfo) String() string            { return proto.CompactTextString(m) }
+func (*CoinInfo) ProtoMessage()               {}
+func (*CoinInfo) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{2} }
+
+func (m *CoinInfo) GetSymbol() string {
+	if m != nil && m.Symbol != nil {
+		return *m.Symbol
+	}
+	return ""
+}
+
+func (m *CoinInfo) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *CoinInfo) GetType() string {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return ""
+}
+
+func (m *CoinInfo) GetDecimals() int32 {
+	if m != nil && m.Decimals != nil {
+		return *m.Decimals
+	}
+	return 0
+}
+
+func (m *CoinInfo) GetMinAmount() uint64 {
+	if m != nil && m.MinAmount != nil {
+		return *m.MinAmount
+	}
+	return 0
+}
+
+func (m *CoinInfo) GetAddressFormat() string {
+	if m != nil && m.AddressFormat != nil {
+		return *m.AddressFormat
+	}
+	return ""
+}
+
+type CoinsInfoRes struct {
+	Result           *Result     `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Coins            []*CoinInfo `protobuf:"bytes,10,rep,name=coins" json:"coins,omitempty"`
+	XXX_unrecognized []byte      `json:"-"`
+}
+
+func (m *CoinsInfoRes) Reset()                    { *m = CoinsInfoRes{} }
+func (m *CoinsInfoRes) String() string            { return proto.CompactTextString(m) }
+func (*CoinsInfoRes) ProtoMessage()               {}
+func (*CoinsInfoRes) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{3} }
+
+func (m *CoinsInfoRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *CoinsInfoRes) GetCoins() []*CoinInfo {
+	if m != nil {
+		return m.Coins
+	}
+	return nil
+}
+
+type CoinHealth struct {
+	Type             *string `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	Healthy          *bool   `protobuf:"varint,2,opt,name=healthy" json:"healthy,omitempty"`
+	Error            *string `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *CoinHealth) Reset()                    { *m = CoinHealth{} }
+func (m *CoinHealth) String() string            { return proto.CompactTextString(m) }
+func (*CoinHealth) ProtoMessage()               {}
+func (*CoinHealth) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{4} }
+
+func (m *CoinHealth) GetType() string {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return ""
+}
+
+func (m *CoinHealth) GetHealthy() bool {
+	if m != nil && m.Healthy != nil {
+		return *m.Healthy
+	}
+	return false
+}
+
+func (m *CoinHealth) GetError() string {
+	if m != nil && m.Error != nil {
+		return *m.Error
+	}
+	return ""
+}
+
+type HealthRes struct {
+	Result           *Result       `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Healthy          *bool         `protobuf:"varint,10,opt,name=healthy" json:"healthy,omitempty"`
+	Coins            []*CoinHealth `protobuf:"bytes,11,rep,name=coins" json:"coins,omitempty"`
+	XXX_unrecognized []byte        `json:"-"`
+}
+
+func (m *HealthRes) Reset()                    { *m = HealthRes{} }
+func (m *HealthRes) String() string            { return proto.CompactTextString(m) }
+func (*HealthRes) ProtoMessage()               {}
+func (*HealthRes) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{5} }
+
+func (m *HealthRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *HealthRes) GetHealthy() bool {
+	if m != nil && m.Healthy != nil {
+		return *m.Healthy
+	}
+	return false
+}
+
+func (m *HealthRes) GetCoins() []*CoinHealth {
+	if m != nil {
+		return m.Coins
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*GetCoinsReq)(nil), "pp.GetCoinsReq")
 	proto.RegisterType((*CoinsRes)(nil), "pp.CoinsRes")
+	proto.RegisterType((*CoinInfo)(nil), "pp.CoinInfo")
+	proto.RegisterType((*CoinsInfoRes)(nil), "pp.CoinsInfoRes")
+	proto.RegisterType((*CoinHealth)(nil), "pp.CoinHealth")
+	proto.RegisterType((*HealthRes)(nil), "pp.HealthRes")
 }
 
 func init() { proto.RegisterFile("pp.coin.proto", fileDescriptor7) }
 
 var fileDescriptor7 = []byte{
-	// 128 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0xe2, 0x2d, 0x28, 0xd0, 0x4b,
-	0xce, 0xcf, 0xcc, 0xd3, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2a, 0x28, 0x90, 0xe2, 0x07,
-	0x0b, 0xe5, 0xe6, 0xe6, 0x43, 0x05, 0x95, 0x64, 0xb9, 0xb8, 0xdd, 0x53, 0x4b, 0x9c, 0xf3, 0x33,
-	0xf3, 0x8a, 0x83, 0x52, 0x0b, 0x85, 0xf8, 0xb8, 0xd8, 0x0a, 0x4a, 0x93, 0xb2, 0x53, 0x2b, 0x25,
-	0x18, 0x15, 0x18, 0x35, 0x38, 0x95, 0x4c, 0xb9, 0x38, 0xa0, 0x72, 0xc5, 0x42, 0x52, 0x5c, 0x6c,
-	0x45, 0xa9, 0xc5, 0xa5, 0x39, 0x25, 0x12, 0x8c, 0x0a, 0x4c, 0x1a, 0xdc, 0x46, 0x5c, 0x7a, 0x05,
-	0x05, 0x7a, 0x41, 0x60, 0x11, 0x21, 0x5e, 0x2e, 0x56, 0x90, 0x4d, 0xc5, 0x12, 0x5c, 0x0a, 0xcc,
-	0x1a, 0x9c, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0x06, 0xd6, 0x8f, 0x33, 0x7a, 0x00, 0x00, 0x00,
+	// 279 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x8f, 0xbf, 0x6f, 0x83, 0x30,
+	0x10, 0x85, 0x45, 0x02, 0x14, 0x0e, 0x42, 0x2a, 0x0f, 0x95, 0x95, 0x2a, 0x12, 0x62, 0xf2, 0xc4,
+	0x10, 0xa9, 0x4b, 0xd7, 0x0e, 0x69, 0xd7, 0x2c, 0x1d, 0x23, 0x27, 0x38, 0x0a, 0x2a, 0xfe, 0x51,
+	0xdb, 0x0c, 0xfc, 0xf7, 0x95, 0xed, 0x50, 0x65, 0xcb, 0x66, 0xbf, 0xd3, 0x7d, 0xf7, 0x3d, 0x58,
+	0x29, 0xd5, 0x9e, 0x65, 0x2f, 0x5a, 0xa5, 0xa5, 0x95, 0x68, 0xa1, 0xd4, 0x66, 0xed, 0x23, 0xce,
+	0xe5, 0x2d, 0x6c, 0xb6, 0x50, 0xec, 0x99, 0xfd, 0x90, 0xbd, 0x30, 0x07, 0xf6, 0x8b, 0x2a, 0x48,
+	0xd5, 0x78, 0xfa, 0x61, 0x13, 0x8e, 0xea, 0x88, 0xe4, 0xcd, 0x1b, 0x64, 0xb7, 0x99, 0x41, 0x1b,
+	0x48, 0x35, 0x33, 0xe3, 0x60, 0x71, 0x54, 0x2f, 0x48, 0xb1, 0x83, 0x56, 0xa9, 0xf6, 0xe0, 0x13,
+	0xb4, 0x82, 0xc4, 0x5d, 0x32, 0x18, 0xea, 0x25, 0xc9, 0x1b, 0x1b, 0xd6, 0xbe, 0xc4, 0x45, 0x3a,
+	0xa4, 0x99, 0xf8, 0x49, 0x0e, 0x01, 0x89, 0x4a, 0x88, 0x05, 0xe5, 0x0c, 0x2f, 0xe6, 0x9f, 0x9d,
+	0x14, 0xc3, 0x4b, 0xff, 0x7b, 0x86, 0xac, 0x63, 0xe7, 0x9e, 0xd3, 0xc1, 0xe0, 0xb8, 0x8e, 0x48,
+	0x82, 0x10, 0x00, 0xef, 0xc5, 0x91, 0x72, 0x39, 0x0a, 0x8b, 0x93, 0x3a, 0x22, 0x31, 0x7a, 0x81,
+	0x8a, 0x76, 0x9d, 0x66, 0xc6, 0x1c, 0x2f, 0x52, 0x73, 0x6a, 0x71, 0xea, 0x65, 0xf7, 0x50, 0x7a,
+	0x59, 0x77, 0xf6, 0x91, 0xf0, 0xeb, 0xbd, 0x70, 0xb1, 0x2b, 0xdd, 0x68, 0x56, 0x6e, 0xde, 0x01,
+	0xdc, 0xfb, 0x93, 0xd1, 0xc1, 0x5e, 0xff, 0x15, 0x83, 0xfe, 0x1a, 0x9e, 0xae, 0x3e, 0x9f, 0x7c,
+	0x83, 0xcc, 0x55, 0x67, 0x5a, 0x4b, 0x1d, 0x2a, 0x34, 0xdf, 0x90, 0x87, 0xbd, 0x47, 0x06, 0x77,
+	0x20, 0xf0, 0xa0, 0xed, 0xac, 0x54, 0x78, 0xa5, 0x6a, 0x56, 0x0a, 0xb8, 0xbf, 0x01, 0x00, 0x85,
+	0xbe, 0x28, 0x90, 0xce, 0x01, 0x00, 0x00,
 }
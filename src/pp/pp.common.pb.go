@@ -27,22 +27,57 @@ It has these top-level messages:
 	EncryptRes
 	CreateAccountReq
 	CreateAccountRes
+	GetAccountNonceReq
+	GetAccountNonceRes
+	EnrollTOTPReq
+	EnrollTOTPRes
+	ConfirmTOTPReq
+	ConfirmTOTPRes
 	GetDepositAddrReq
 	GetDepositAddrRes
 	WithdrawalReq
 	WithdrawalRes
+	GetWithdrawalReq
+	GetWithdrawalRes
 	Balance
 	GetAccountBalanceReq
 	GetAccountBalanceRes
+	CoinBalance
+	GetAccountBalancesReq
+	GetAccountBalancesRes
 	GetAddrBalanceReq
 	GetAddrBalanceRes
+	GetAddrDetailedBalanceReq
+	GetAddrDetailedBalanceRes
 	OrderReq
 	OrderRes
+	BatchOrderReq
+	BatchOrderResult
+	BatchOrderRes
 	Order
 	GetOrderReq
 	GetOrderRes
+	GetOrderByIDReq
+	GetOrderByIDRes
+	GetAccountOrdersReq
+	PairOrders
+	GetAccountOrdersRes
+	CancelOrderReq
+	CancelOrderRes
+	DepthLevel
+	GetDepthReq
+	GetDepthRes
+	Candle
+	GetCandlesReq
+	GetCandlesRes
+	GetTickerReq
+	GetTickerRes
 	GetCoinsReq
 	CoinsRes
+	CoinInfo
+	CoinsInfoRes
+	CoinHealth
+	HealthRes
 	Request
 	GetUtxoReq
 	BtcUtxo
@@ -55,15 +90,36 @@ It has these top-level messages:
 	GetTxRes
 	GetRawTxReq
 	GetRawTxRes
+	GetAddrTxsReq
+	GetAddrTxsRes
 	BtcTx
 	BtcVin
 	BtcScriptSig
 	BtcVout
 	BtcScriptPubKeyResult
 	SkyTx
+	EthTx
 	SkyTxOutput
 	UpdateCreditReq
 	UpdateCreditRes
+	AdminCreateAccountReq
+	AdminCreateAccountRes
+	AdminDeleteAccountReq
+	AdminDeleteAccountRes
+	AdminAddCoinPairReq
+	AdminAddCoinPairRes
+	AdminHaltPairReq
+	AdminHaltPairRes
+	AdminSetPriceBandReq
+	AdminSetPriceBandRes
+	AdminResumePairReq
+	AdminResumePairRes
+	AdminGetUtxoStatsReq
+	AdminGetUtxoStatsRes
+	AdminFreezeAccountReq
+	AdminFreezeAccountRes
+	AdminUnfreezeAccountReq
+	AdminUnfreezeAccountRes
 	GetOutputReq
 	GetOutputRes
 	Output
@@ -95,6 +151,7 @@ const (
 	ErrCode_UnAuthorized    ErrCode = 31
 	ErrCode_NotExits        ErrCode = 32
 	ErrCode_AlreadyExits    ErrCode = 33
+	ErrCode_TooManyRequests ErrCode = 34
 	ErrCode_ServerError     ErrCode = 40
 	ErrCode_BroadcastTxFail ErrCode = 50
 )
@@ -107,6 +164,7 @@ var ErrCode_name = map[int32]string{
 	31: "UnAuthorized",
 	32: "NotExits",
 	33: "AlreadyExits",
+	34: "TooManyRequests",
 	40: "ServerError",
 	50: "BroadcastTxFail",
 }
@@ -118,6 +176,7 @@ var ErrCode_value = map[string]int32{
 	"UnAuthorized":    31,
 	"NotExits":        32,
 	"AlreadyExits":    33,
+	"TooManyRequests": 34,
 	"ServerError":     40,
 	"BroadcastTxFail": 50,
 }
@@ -199,21 +258,22 @@ func init() {
 func init() { proto.RegisterFile("pp.common.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 252 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x3c, 0xcd, 0x31, 0x4f, 0xb4, 0x40,
-	0x10, 0xc6, 0xf1, 0x17, 0xde, 0x78, 0xe0, 0x70, 0x91, 0xcd, 0xaa, 0x09, 0xb1, 0x11, 0xaf, 0x30,
-	0xc4, 0x82, 0xe2, 0x4a, 0xbb, 0xd3, 0x70, 0xa5, 0x31, 0x9c, 0xc6, 0x7a, 0x85, 0x89, 0x12, 0x81,
-	0x59, 0x67, 0x77, 0xcd, 0xe1, 0xc7, 0xf1, 0x93, 0x9a, 0x3d, 0x12, 0xdb, 0xdf, 0x93, 0xf9, 0x0f,
-	0xa4, 0x5a, 0x97, 0x0d, 0x0d, 0x03, 0x8d, 0xa5, 0x66, 0xb2, 0x24, 0x43, 0xad, 0x57, 0xb7, 0xb0,
-	0xa8, 0xd1, 0xb8, 0xde, 0xca, 0x14, 0x22, 0xe3, 0x9a, 0x06, 0x8d, 0xc9, 0x82, 0x3c, 0x2c, 0x62,
-	0x0f, 0xc8, 0xdc, 0x50, 0x8b, 0x59, 0x98, 0x07, 0xc5, 0x91, 0x3c, 0x81, 0x05, 0xa3, 0x32, 0x34,
-	0x66, 0xff, 0xf3, 0xa0, 0x38, 0x5e, 0x5d, 0x43, 0x5c, 0x0d, 0xda, 0x4e, 0x35, 0x1a, 0x79, 0xe1,
-	0x37, 0xdf, 0x39, 0x1c, 0x27, 0x6b, 0x28, 0xb5, 0x2e, 0xe7, 0xf2, 0xcd, 0x4f, 0x00, 0x51, 0xc5,
-	0x7c, 0x4f, 0x2d, 0xca, 0x04, 0xa2, 0xdd, 0xfc, 0x45, 0xfc, 0x93, 0x29, 0x24, 0x2f, 0x4c, 0xe3,
-	0xdb, 0x96, 0x78, 0x50, 0x56, 0xc0, 0x1f, 0x3c, 0xba, 0xd7, 0x0f, 0x9c, 0xc4, 0x99, 0x14, 0xb0,
-	0x3c, 0x40, 0x8d, 0x9f, 0x0e, 0x8d, 0x15, 0xe7, 0x5e, 0x9e, 0xc7, 0x8d, 0xb3, 0xef, 0xc4, 0xdd,
-	0x37, 0xb6, 0xe2, 0x52, 0x2e, 0x21, 0x7e, 0x20, 0x5b, 0xed, 0x3b, 0x6b, 0x44, 0xee, 0xf7, 0x4d,
-	0xcf, 0xa8, 0xda, 0x69, 0x96, 0x2b, 0x1f, 0xdd, 0x21, 0x7f, 0x21, 0x57, 0xcc, 0xc4, 0xa2, 0x90,
-	0xa7, 0x90, 0xde, 0x31, 0xa9, 0xb6, 0x51, 0xc6, 0x3e, 0xed, 0xb7, 0xaa, 0xeb, 0xc5, 0xfa, 0x37,
-	0x00, 0x00, 0xff, 0xff, 0x94, 0x6d, 0x77, 0xf5, 0x1e, 0x01, 0x00, 0x00,
+	// 259 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x3c, 0xcd, 0x41, 0x4f, 0x83, 0x40,
+	0x10, 0x05, 0x60, 0xa9, 0xb1, 0xe0, 0xd0, 0xc8, 0x66, 0xd5, 0x84, 0x78, 0x11, 0x39, 0x18, 0xe2,
+	0x81, 0x43, 0x8f, 0xde, 0xaa, 0xa1, 0x37, 0x8d, 0xa1, 0x35, 0x9e, 0x57, 0x98, 0x28, 0x11, 0x98,
+	0x75, 0x76, 0x31, 0xc5, 0x3f, 0xe6, 0xdf, 0x33, 0x5b, 0x8c, 0xd7, 0xef, 0xcd, 0xbc, 0x07, 0x91,
+	0xd6, 0x79, 0x45, 0x5d, 0x47, 0x7d, 0xae, 0x99, 0x2c, 0xc9, 0x99, 0xd6, 0xe9, 0x2d, 0xcc, 0x4b,
+	0x34, 0x43, 0x6b, 0x65, 0x04, 0xbe, 0x19, 0xaa, 0x0a, 0x8d, 0x89, 0xbd, 0x64, 0x96, 0x05, 0x0e,
+	0x90, 0xb9, 0xa2, 0x1a, 0xe3, 0x59, 0xe2, 0x65, 0x47, 0xf2, 0x04, 0xe6, 0x8c, 0xca, 0x50, 0x1f,
+	0x1f, 0x26, 0x5e, 0x76, 0x9c, 0x5e, 0x43, 0x50, 0x74, 0xda, 0x8e, 0x25, 0x1a, 0x79, 0xe1, 0x32,
+	0xd7, 0xb3, 0x7f, 0x0e, 0x97, 0x90, 0x6b, 0x9d, 0x4f, 0xcd, 0x37, 0x3f, 0x1e, 0xf8, 0x05, 0xf3,
+	0x3d, 0xd5, 0x28, 0x43, 0xf0, 0x37, 0xd3, 0x8a, 0x38, 0x90, 0x11, 0x84, 0x2f, 0x4c, 0xfd, 0xdb,
+	0x9a, 0xb8, 0x53, 0x56, 0xc0, 0x3f, 0x3c, 0x0d, 0xaf, 0x1f, 0x38, 0x8a, 0x33, 0x29, 0x60, 0xb1,
+	0x87, 0x12, 0x3f, 0x07, 0x34, 0x56, 0x9c, 0x3b, 0x79, 0xee, 0x57, 0x83, 0x7d, 0x27, 0x6e, 0xbe,
+	0xb1, 0x16, 0x97, 0x72, 0x01, 0xc1, 0x23, 0xd9, 0x62, 0xd7, 0x58, 0x23, 0x12, 0x97, 0xaf, 0x5a,
+	0x46, 0x55, 0x8f, 0x93, 0x5c, 0xc9, 0x53, 0x88, 0xb6, 0x44, 0x0f, 0xaa, 0x1f, 0xff, 0x5a, 0x8c,
+	0x48, 0xdd, 0xd2, 0x06, 0xf9, 0x0b, 0xb9, 0x60, 0x26, 0x16, 0x99, 0xbb, 0xba, 0x63, 0x52, 0x75,
+	0xa5, 0x8c, 0xdd, 0xee, 0xd6, 0xaa, 0x69, 0xc5, 0xf2, 0x77, 0x00, 0x95, 0x6c, 0xf1, 0xb2, 0x33,
+	0x01, 0x00, 0x00,
 }
@@ -15,6 +15,7 @@ var _ = math.Inf
 
 type GetDepositAddrReq struct {
 	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Nonce            *uint64 `protobuf:"varint,9,opt,name=nonce" json:"nonce,omitempty"`
 	CoinType         *string `protobuf:"bytes,11,opt,name=coin_type" json:"coin_type,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
@@ -31,6 +32,13 @@ func (m *GetDepositAddrReq) GetPubkey() string {
 	return ""
 }
 
+func (m *GetDepositAddrReq) GetNonce() uint64 {
+	if m != nil && m.Nonce != nil {
+		return *m.Nonce
+	}
+	return 0
+}
+
 func (m *GetDepositAddrReq) GetCoinType() string {
 	if m != nil && m.CoinType != nil {
 		return *m.CoinType
@@ -79,15 +87,16 @@ func init() {
 func init() { proto.RegisterFile("pp.deposit.proto", fileDescriptor3) }
 
 var fileDescriptor3 = []byte{
-	// 155 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x6c, 0xcc, 0xb1, 0x0e, 0x82, 0x30,
-	0x14, 0x85, 0xe1, 0xc0, 0x80, 0xe1, 0x62, 0x44, 0x3a, 0x35, 0x4c, 0x84, 0x89, 0xa9, 0x83, 0x83,
-	0xbb, 0x89, 0x89, 0x3b, 0x3e, 0x80, 0x51, 0x7a, 0x07, 0xa2, 0x70, 0x8f, 0x6d, 0x19, 0x78, 0x7b,
-	0x83, 0x8c, 0xba, 0x7e, 0x39, 0xe7, 0xa7, 0x3d, 0x60, 0x2c, 0x43, 0x7c, 0x1f, 0x0c, 0x9c, 0x04,
-	0x51, 0x31, 0x50, 0xe6, 0x80, 0xe9, 0x64, 0x18, 0x64, 0x5c, 0xb1, 0x3e, 0x52, 0x71, 0xe1, 0x70,
-	0x5e, 0x87, 0x27, 0x6b, 0x5d, 0xcb, 0x6f, 0xb5, 0xa3, 0x04, 0xd3, 0xe3, 0xc9, 0xb3, 0xa6, 0x2a,
-	0x6a, 0x52, 0x55, 0x50, 0xda, 0x49, 0x3f, 0xde, 0xc2, 0x0c, 0xd6, 0xd9, 0x42, 0xf5, 0xf5, 0xf7,
-	0xe7, 0x55, 0x49, 0x89, 0x63, 0x3f, 0xbd, 0x82, 0x8e, 0xaa, 0xb8, 0xc9, 0x0e, 0x64, 0x00, 0xd3,
-	0x7e, 0xe5, 0x4f, 0x43, 0xe5, 0xb4, 0xb9, 0x5b, 0xeb, 0xd8, 0x7b, 0xbd, 0x5d, 0xe0, 0x13, 0x00,
-	0x00, 0xff, 0xff, 0x16, 0x86, 0x8a, 0x61, 0xb4, 0x00, 0x00, 0x00,
+	// 164 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x6c, 0xcc, 0x31, 0xae, 0x82, 0x40,
+	0x10, 0x80, 0xe1, 0x40, 0xde, 0xc3, 0x30, 0xa8, 0xc8, 0x54, 0x1b, 0x2a, 0x42, 0x45, 0xb5, 0x85,
+	0x37, 0x30, 0xd1, 0xd8, 0xe3, 0x01, 0x8c, 0xb2, 0x53, 0x10, 0x65, 0x67, 0xdc, 0x5d, 0x0a, 0x6e,
+	0x6f, 0x90, 0x52, 0xdb, 0x2f, 0x7f, 0x7e, 0xd8, 0x89, 0x68, 0x43, 0xc2, 0xbe, 0x0f, 0x5a, 0x1c,
+	0x07, 0xc6, 0x58, 0xa4, 0xcc, 0x45, 0x74, 0xc7, 0xc3, 0xc0, 0x76, 0xc1, 0xfa, 0x04, 0xc5, 0x99,
+	0xc2, 0x71, 0x09, 0x0f, 0xc6, 0xb8, 0x96, 0x5e, 0xb8, 0x85, 0x44, 0xc6, 0xfb, 0x83, 0x26, 0x05,
+	0x55, 0xd4, 0xa4, 0xb8, 0x81, 0x7f, 0xcb, 0xb6, 0x23, 0x95, 0x56, 0x51, 0xf3, 0x87, 0x05, 0xa4,
+	0x1d, 0xf7, 0xf6, 0x1a, 0x26, 0x21, 0x95, 0xcd, 0x45, 0x7d, 0xf9, 0xde, 0x78, 0x2c, 0x21, 0x71,
+	0xe4, 0xc7, 0x67, 0x50, 0x51, 0x15, 0x37, 0xd9, 0x1e, 0xb4, 0x88, 0x6e, 0x3f, 0xf2, 0xe3, 0x81,
+	0x39, 0xac, 0x6e, 0xc6, 0x38, 0xf2, 0x5e, 0xad, 0x67, 0x78, 0x0f, 0x00, 0xfb, 0xb0, 0xd2, 0x7c,
+	0xc3, 0x00, 0x00, 0x00,
 }
@@ -17,6 +17,7 @@ type EncryptReq struct {
 	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
 	Nonce            []byte  `protobuf:"bytes,11,opt,name=nonce" json:"nonce,omitempty"`
 	Encryptdata      []byte  `protobuf:"bytes,12,opt,name=encryptdata" json:"encryptdata,omitempty"`
+	Signature        *string `protobuf:"bytes,13,opt,name=signature" json:"signature,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -46,6 +47,13 @@ func (m *EncryptReq) GetEncryptdata() []byte {
 	return nil
 }
 
+func (m *EncryptReq) GetSignature() string {
+	if m != nil && m.Signature != nil {
+		return *m.Signature
+	}
+	return ""
+}
+
 type EncryptRes struct {
 	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
 	Nonce            []byte  `protobuf:"bytes,10,opt,name=nonce" json:"nonce,omitempty"`
@@ -87,15 +95,16 @@ func init() {
 func init() { proto.RegisterFile("pp.encrypt.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 152 bytes of a gzipped FileDescriptorProto
+	// 165 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x12, 0x28, 0x28, 0xd0, 0x4b,
 	0xcd, 0x4b, 0x2e, 0xaa, 0x2c, 0x28, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2a, 0x28,
-	0x90, 0xe2, 0x2f, 0x28, 0xd0, 0x4b, 0xce, 0xcf, 0xcd, 0xcd, 0xcf, 0x83, 0x08, 0x2a, 0x39, 0x70,
+	0x90, 0xe2, 0x2f, 0x28, 0xd0, 0x4b, 0xce, 0xcf, 0xcd, 0xcd, 0xcf, 0x83, 0x08, 0x2a, 0x05, 0x73,
 	0x71, 0xb9, 0x42, 0x54, 0x05, 0xa5, 0x16, 0x0a, 0xf1, 0x71, 0xb1, 0x15, 0x94, 0x26, 0x65, 0xa7,
 	0x56, 0x4a, 0x70, 0x29, 0x30, 0x6a, 0x70, 0x0a, 0xf1, 0x72, 0xb1, 0xe6, 0xe5, 0xe7, 0x25, 0xa7,
 	0x4a, 0x70, 0x2b, 0x30, 0x6a, 0xf0, 0x08, 0x09, 0x73, 0x71, 0x43, 0x8d, 0x4c, 0x49, 0x2c, 0x49,
-	0x94, 0xe0, 0x01, 0x09, 0x2a, 0xf9, 0x20, 0x99, 0x50, 0x2c, 0x24, 0xc5, 0xc5, 0x56, 0x94, 0x5a,
+	0x94, 0xe0, 0x01, 0x0b, 0x0a, 0x72, 0x71, 0x16, 0x67, 0xa6, 0xe7, 0x25, 0x96, 0x94, 0x16, 0xa5,
+	0x4a, 0xf0, 0x82, 0xb4, 0x29, 0xf9, 0x20, 0x19, 0x5a, 0x2c, 0x24, 0xc5, 0xc5, 0x56, 0x94, 0x5a,
 	0x5c, 0x9a, 0x53, 0x22, 0xc1, 0xa8, 0xc0, 0xa4, 0xc1, 0x6d, 0xc4, 0xa5, 0x57, 0x50, 0xa0, 0x17,
-	0x04, 0x16, 0x41, 0x98, 0xc6, 0x85, 0xcd, 0x34, 0x11, 0x90, 0x20, 0x20, 0x00, 0x00, 0xff, 0xff,
-	0x5e, 0x0e, 0xd5, 0x3f, 0xb7, 0x00, 0x00, 0x00,
+	0x04, 0x16, 0x41, 0x58, 0xc0, 0x85, 0xcd, 0x02, 0x11, 0x90, 0x20, 0x60, 0x00, 0x84, 0xc3, 0xfb,
+	0xb9, 0xca, 0x00, 0x00, 0x00,
 }
@@ -15,10 +15,16 @@ var _ = math.Inf
 
 type OrderReq struct {
 	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Nonce            *uint64 `protobuf:"varint,9,opt,name=nonce" json:"nonce,omitempty"`
 	CoinPair         *string `protobuf:"bytes,11,opt,name=coin_pair" json:"coin_pair,omitempty"`
 	Type             *string `protobuf:"bytes,12,opt,name=type" json:"type,omitempty"`
 	Amount           *uint64 `protobuf:"varint,13,opt,name=amount" json:"amount,omitempty"`
 	Price            *uint64 `protobuf:"varint,14,opt,name=price" json:"price,omitempty"`
+	Kind             *string `protobuf:"bytes,15,opt,name=kind" json:"kind,omitempty"`
+	TimeInForce      *string `protobuf:"bytes,16,opt,name=time_in_force" json:"time_in_force,omitempty"`
+	ExpireAt         *int64  `protobuf:"varint,17,opt,name=expire_at" json:"expire_at,omitempty"`
+	StopPrice        *uint64 `protobuf:"varint,18,opt,name=stop_price" json:"stop_price,omitempty"`
+	AmountCurrency   *string `protobuf:"bytes,19,opt,name=amount_currency" json:"amount_currency,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -34,6 +40,13 @@ func (m *OrderReq) GetPubkey() string {
 	return ""
 }
 
+func (m *OrderReq) GetNonce() uint64 {
+	if m != nil && m.Nonce != nil {
+		return *m.Nonce
+	}
+	return 0
+}
+
 func (m *OrderReq) GetCoinPair() string {
 	if m != nil && m.CoinPair != nil {
 		return *m.CoinPair
@@ -62,6 +75,41 @@ func (m *OrderReq) GetPrice() uint64 {
 	return 0
 }
 
+func (m *OrderReq) GetKind() string {
+	if m != nil && m.Kind != nil {
+		return *m.Kind
+	}
+	return ""
+}
+
+func (m *OrderReq) GetTimeInForce() string {
+	if m != nil && m.TimeInForce != nil {
+		return *m.TimeInForce
+	}
+	return ""
+}
+
+func (m *OrderReq) GetExpireAt() int64 {
+	if m != nil && m.ExpireAt != nil {
+		return *m.ExpireAt
+	}
+	return 0
+}
+
+func (m *OrderReq) GetStopPrice() uint64 {
+	if m != nil && m.StopPrice != nil {
+		return *m.StopPrice
+	}
+	return 0
+}
+
+func (m *OrderReq) GetAmountCurrency() string {
+	if m != nil && m.AmountCurrency != nil {
+		return *m.AmountCurrency
+	}
+	return ""
+}
+
 type OrderRes struct {
 	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
 	OrderId          *uint64 `protobuf:"varint,11,opt,name=order_id" json:"order_id,omitempty"`
@@ -87,6 +135,97 @@ func (m *OrderRes) GetOrderId() uint64 {
 	return 0
 }
 
+type BatchOrderReq struct {
+	Pubkey           *string     `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Nonce            *uint64     `protobuf:"varint,9,opt,name=nonce" json:"nonce,omitempty"`
+	CoinPair         *string     `protobuf:"bytes,11,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Orders           []*OrderReq `protobuf:"bytes,12,rep,name=orders" json:"orders,omitempty"`
+	XXX_unrecognized []byte      `json:"-"`
+}
+
+func (m *BatchOrderReq) Reset()                    { *m = BatchOrderReq{} }
+func (m *BatchOrderReq) String() string            { return proto.CompactTextString(m) }
+func (*BatchOrderReq) ProtoMessage()               {}
+func (*BatchOrderReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{2} }
+
+func (m *BatchOrderReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *BatchOrderReq) GetNonce() uint64 {
+	if m != nil && m.Nonce != nil {
+		return *m.Nonce
+	}
+	return 0
+}
+
+func (m *BatchOrderReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *BatchOrderReq) GetOrders() []*OrderReq {
+	if m != nil {
+		return m.Orders
+	}
+	return nil
+}
+
+type BatchOrderResult struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	OrderId          *uint64 `protobuf:"varint,11,opt,name=order_id" json:"order_id,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *BatchOrderResult) Reset()                    { *m = BatchOrderResult{} }
+func (m *BatchOrderResult) String() string            { return proto.CompactTextString(m) }
+func (*BatchOrderResult) ProtoMessage()               {}
+func (*BatchOrderResult) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{3} }
+
+func (m *BatchOrderResult) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *BatchOrderResult) GetOrderId() uint64 {
+	if m != nil && m.OrderId != nil {
+		return *m.OrderId
+	}
+	return 0
+}
+
+type BatchOrderRes struct {
+	Result           *Result             `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Results          []*BatchOrderResult `protobuf:"bytes,11,rep,name=results" json:"results,omitempty"`
+	XXX_unrecognized []byte              `json:"-"`
+}
+
+func (m *BatchOrderRes) Reset()                    { *m = BatchOrderRes{} }
+func (m *BatchOrderRes) String() string            { return proto.CompactTextString(m) }
+func (*BatchOrderRes) ProtoMessage()               {}
+func (*BatchOrderRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{4} }
+
+func (m *BatchOrderRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *BatchOrderRes) GetResults() []*BatchOrderResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
 type Order struct {
 	Id               *uint64 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
 	Type             *string `protobuf:"bytes,3,opt,name=type" json:"type,omitempty"`
@@ -94,13 +233,14 @@ type Order struct {
 	Amount           *uint64 `protobuf:"varint,5,opt,name=amount" json:"amount,omitempty"`
 	RestAmt          *uint64 `protobuf:"varint,6,opt,name=rest_amt" json:"rest_amt,omitempty"`
 	CreatedAt        *int64  `protobuf:"varint,7,opt,name=created_at" json:"created_at,omitempty"`
+	Status           *string `protobuf:"bytes,8,opt,name=status" json:"status,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
 func (m *Order) Reset()                    { *m = Order{} }
 func (m *Order) String() string            { return proto.CompactTextString(m) }
 func (*Order) ProtoMessage()               {}
-func (*Order) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{2} }
+func (*Order) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{5} }
 
 func (m *Order) GetId() uint64 {
 	if m != nil && m.Id != nil {
@@ -144,19 +284,27 @@ func (m *Order) GetCreatedAt() int64 {
 	return 0
 }
 
+func (m *Order) GetStatus() string {
+	if m != nil && m.Status != nil {
+		return *m.Status
+	}
+	return ""
+}
+
 type GetOrderReq struct {
 	Router           *string `protobuf:"bytes,1,opt,name=router" json:"router,omitempty"`
 	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
 	Type             *string `protobuf:"bytes,11,opt,name=type" json:"type,omitempty"`
 	Start            *int64  `protobuf:"varint,12,opt,name=start" json:"start,omitempty"`
 	End              *int64  `protobuf:"varint,13,opt,name=end" json:"end,omitempty"`
+	ByTime           *bool   `protobuf:"varint,14,opt,name=by_time" json:"by_time,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
 func (m *GetOrderReq) Reset()                    { *m = GetOrderReq{} }
 func (m *GetOrderReq) String() string            { return proto.CompactTextString(m) }
 func (*GetOrderReq) ProtoMessage()               {}
-func (*GetOrderReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{3} }
+func (*GetOrderReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{6} }
 
 func (m *GetOrderReq) GetRouter() string {
 	if m != nil && m.Router != nil {
@@ -193,10 +341,18 @@ func (m *GetOrderReq) GetEnd() int64 {
 	return 0
 }
 
+func (m *GetOrderReq) GetByTime() bool {
+	if m != nil && m.ByTime != nil {
+		return *m.ByTime
+	}
+	return false
+}
+
 type GetOrderRes struct {
 	Result           *Result  `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
 	CoinPair         *string  `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
 	Type             *string  `protobuf:"bytes,11,opt,name=type" json:"type,omitempty"`
+	Total            *int64   `protobuf:"varint,12,opt,name=total" json:"total,omitempty"`
 	Orders           []*Order `protobuf:"bytes,21,rep,name=orders" json:"orders,omitempty"`
 	XXX_unrecognized []byte   `json:"-"`
 }
@@ -204,7 +360,7 @@ type GetOrderRes struct {
 func (m *GetOrderRes) Reset()                    { *m = GetOrderRes{} }
 func (m *GetOrderRes) String() string            { return proto.CompactTextString(m) }
 func (*GetOrderRes) ProtoMessage()               {}
-func (*GetOrderRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{4} }
+func (*GetOrderRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{7} }
 
 func (m *GetOrderRes) GetResult() *Result {
 	if m != nil {
@@ -227,6 +383,13 @@ func (m *GetOrderRes) GetType() string {
 	return ""
 }
 
+func (m *GetOrderRes) GetTotal() int64 {
+	if m != nil && m.Total != nil {
+		return *m.Total
+	}
+	return 0
+}
+
 func (m *GetOrderRes) GetOrders() []*Order {
 	if m != nil {
 		return m.Orders
@@ -234,34 +397,611 @@ func (m *GetOrderRes) GetOrders() []*Order {
 	return nil
 }
 
+type GetOrderByIDReq struct {
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	OrderId          *uint64 `protobuf:"varint,11,opt,name=order_id" json:"order_id,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetOrderByIDReq) Reset()                    { *m = GetOrderByIDReq{} }
+func (m *GetOrderByIDReq) String() string            { return proto.CompactTextString(m) }
+func (*GetOrderByIDReq) ProtoMessage()               {}
+func (*GetOrderByIDReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{8} }
+
+func (m *GetOrderByIDReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetOrderByIDReq) GetOrderId() uint64 {
+	if m != nil && m.OrderId != nil {
+		return *m.OrderId
+	}
+	return 0
+}
+
+type GetOrderByIDRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Order            *Order  `protobuf:"bytes,11,opt,name=order" json:"order,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetOrderByIDRes) Reset()                    { *m = GetOrderByIDRes{} }
+func (m *GetOrderByIDRes) String() string            { return proto.CompactTextString(m) }
+func (*GetOrderByIDRes) ProtoMessage()               {}
+func (*GetOrderByIDRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{9} }
+
+func (m *GetOrderByIDRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetOrderByIDRes) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetOrderByIDRes) GetOrder() *Order {
+	if m != nil {
+		return m.Order
+	}
+	return nil
+}
+
+type GetAccountOrdersReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	OpenOnly         *bool   `protobuf:"varint,11,opt,name=open_only" json:"open_only,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetAccountOrdersReq) Reset()                    { *m = GetAccountOrdersReq{} }
+func (m *GetAccountOrdersReq) String() string            { return proto.CompactTextString(m) }
+func (*GetAccountOrdersReq) ProtoMessage()               {}
+func (*GetAccountOrdersReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{10} }
+
+func (m *GetAccountOrdersReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *GetAccountOrdersReq) GetOpenOnly() bool {
+	if m != nil && m.OpenOnly != nil {
+		return *m.OpenOnly
+	}
+	return false
+}
+
+type PairOrders struct {
+	CoinPair         *string  `protobuf:"bytes,1,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Orders           []*Order `protobuf:"bytes,2,rep,name=orders" json:"orders,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *PairOrders) Reset()                    { *m = PairOrders{} }
+func (m *PairOrders) String() string            { return proto.CompactTextString(m) }
+func (*PairOrders) ProtoMessage()               {}
+func (*PairOrders) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{11} }
+
+func (m *PairOrders) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *PairOrders) GetOrders() []*Order {
+	if m != nil {
+		return m.Orders
+	}
+	return nil
+}
+
+type GetAccountOrdersRes struct {
+	Result           *Result       `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Pairs            []*PairOrders `protobuf:"bytes,10,rep,name=pairs" json:"pairs,omitempty"`
+	XXX_unrecognized []byte        `json:"-"`
+}
+
+func (m *GetAccountOrdersRes) Reset()                    { *m = GetAccountOrdersRes{} }
+func (m *GetAccountOrdersRes) String() string            { return proto.CompactTextString(m) }
+func (*GetAccountOrdersRes) ProtoMessage()               {}
+func (*GetAccountOrdersRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{12} }
+
+func (m *GetAccountOrdersRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetAccountOrdersRes) GetPairs() []*PairOrders {
+	if m != nil {
+		return m.Pairs
+	}
+	return nil
+}
+
+type CancelOrderReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Nonce            *uint64 `protobuf:"varint,9,opt,name=nonce" json:"nonce,omitempty"`
+	CoinPair         *string `protobuf:"bytes,11,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	OrderId          *uint64 `protobuf:"varint,12,opt,name=order_id" json:"order_id,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *CancelOrderReq) Reset()                    { *m = CancelOrderReq{} }
+func (m *CancelOrderReq) String() string            { return proto.CompactTextString(m) }
+func (*CancelOrderReq) ProtoMessage()               {}
+func (*CancelOrderReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{13} }
+
+func (m *CancelOrderReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *CancelOrderReq) GetNonce() uint64 {
+	if m != nil && m.Nonce != nil {
+		return *m.Nonce
+	}
+	return 0
+}
+
+func (m *CancelOrderReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *CancelOrderReq) GetOrderId() uint64 {
+	if m != nil && m.OrderId != nil {
+		return *m.OrderId
+	}
+	return 0
+}
+
+type CancelOrderRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	OrderId          *uint64 `protobuf:"varint,11,opt,name=order_id" json:"order_id,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *CancelOrderRes) Reset()                    { *m = CancelOrderRes{} }
+func (m *CancelOrderRes) String() string            { return proto.CompactTextString(m) }
+func (*CancelOrderRes) ProtoMessage()               {}
+func (*CancelOrderRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{14} }
+
+func (m *CancelOrderRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *CancelOrderRes) GetOrderId() uint64 {
+	if m != nil && m.OrderId != nil {
+		return *m.OrderId
+	}
+	return 0
+}
+
+type DepthLevel struct {
+	Price            *uint64 `protobuf:"varint,1,opt,name=price" json:"price,omitempty"`
+	TotalAmount      *uint64 `protobuf:"varint,2,opt,name=total_amount" json:"total_amount,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *DepthLevel) Reset()                    { *m = DepthLevel{} }
+func (m *DepthLevel) String() string            { return proto.CompactTextString(m) }
+func (*DepthLevel) ProtoMessage()               {}
+func (*DepthLevel) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{15} }
+
+func (m *DepthLevel) GetPrice() uint64 {
+	if m != nil && m.Price != nil {
+		return *m.Price
+	}
+	return 0
+}
+
+func (m *DepthLevel) GetTotalAmount() uint64 {
+	if m != nil && m.TotalAmount != nil {
+		return *m.TotalAmount
+	}
+	return 0
+}
+
+type GetDepthReq struct {
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Levels           *int64  `protobuf:"varint,11,opt,name=levels" json:"levels,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetDepthReq) Reset()                    { *m = GetDepthReq{} }
+func (m *GetDepthReq) String() string            { return proto.CompactTextString(m) }
+func (*GetDepthReq) ProtoMessage()               {}
+func (*GetDepthReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{16} }
+
+func (m *GetDepthReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetDepthReq) GetLevels() int64 {
+	if m != nil && m.Levels != nil {
+		return *m.Levels
+	}
+	return 0
+}
+
+type GetDepthRes struct {
+	Result           *Result       `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinPair         *string       `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Bids             []*DepthLevel `protobuf:"bytes,11,rep,name=bids" json:"bids,omitempty"`
+	Asks             []*DepthLevel `protobuf:"bytes,12,rep,name=asks" json:"asks,omitempty"`
+	XXX_unrecognized []byte        `json:"-"`
+}
+
+func (m *GetDepthRes) Reset()                    { *m = GetDepthRes{} }
+func (m *GetDepthRes) String() string            { return proto.CompactTextString(m) }
+func (*GetDepthRes) ProtoMessage()               {}
+func (*GetDepthRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{17} }
+
+func (m *GetDepthRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetDepthRes) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetDepthRes) GetBids() []*DepthLevel {
+	if m != nil {
+		return m.Bids
+	}
+	return nil
+}
+
+func (m *GetDepthRes) GetAsks() []*DepthLevel {
+	if m != nil {
+		return m.Asks
+	}
+	return nil
+}
+
+type Candle struct {
+	Time             *int64  `protobuf:"varint,1,opt,name=time" json:"time,omitempty"`
+	Open             *uint64 `protobuf:"varint,2,opt,name=open" json:"open,omitempty"`
+	High             *uint64 `protobuf:"varint,3,opt,name=high" json:"high,omitempty"`
+	Low              *uint64 `protobuf:"varint,4,opt,name=low" json:"low,omitempty"`
+	Close            *uint64 `protobuf:"varint,5,opt,name=close" json:"close,omitempty"`
+	Volume           *uint64 `protobuf:"varint,6,opt,name=volume" json:"volume,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *Candle) Reset()                    { *m = Candle{} }
+func (m *Candle) String() string            { return proto.CompactTextString(m) }
+func (*Candle) ProtoMessage()               {}
+func (*Candle) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{18} }
+
+func (m *Candle) GetTime() int64 {
+	if m != nil && m.Time != nil {
+		return *m.Time
+	}
+	return 0
+}
+
+func (m *Candle) GetOpen() uint64 {
+	if m != nil && m.Open != nil {
+		return *m.Open
+	}
+	return 0
+}
+
+func (m *Candle) GetHigh() uint64 {
+	if m != nil && m.High != nil {
+		return *m.High
+	}
+	return 0
+}
+
+func (m *Candle) GetLow() uint64 {
+	if m != nil && m.Low != nil {
+		return *m.Low
+	}
+	return 0
+}
+
+func (m *Candle) GetClose() uint64 {
+	if m != nil && m.Close != nil {
+		return *m.Close
+	}
+	return 0
+}
+
+func (m *Candle) GetVolume() uint64 {
+	if m != nil && m.Volume != nil {
+		return *m.Volume
+	}
+	return 0
+}
+
+type GetCandlesReq struct {
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Interval         *string `protobuf:"bytes,11,opt,name=interval" json:"interval,omitempty"`
+	Start            *int64  `protobuf:"varint,12,opt,name=start" json:"start,omitempty"`
+	End              *int64  `protobuf:"varint,13,opt,name=end" json:"end,omitempty"`
+	Fill             *bool   `protobuf:"varint,14,opt,name=fill" json:"fill,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetCandlesReq) Reset()                    { *m = GetCandlesReq{} }
+func (m *GetCandlesReq) String() string            { return proto.CompactTextString(m) }
+func (*GetCandlesReq) ProtoMessage()               {}
+func (*GetCandlesReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{19} }
+
+func (m *GetCandlesReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetCandlesReq) GetInterval() string {
+	if m != nil && m.Interval != nil {
+		return *m.Interval
+	}
+	return ""
+}
+
+func (m *GetCandlesReq) GetStart() int64 {
+	if m != nil && m.Start != nil {
+		return *m.Start
+	}
+	return 0
+}
+
+func (m *GetCandlesReq) GetEnd() int64 {
+	if m != nil && m.End != nil {
+		return *m.End
+	}
+	return 0
+}
+
+func (m *GetCandlesReq) GetFill() bool {
+	if m != nil && m.Fill != nil {
+		return *m.Fill
+	}
+	return false
+}
+
+type GetCandlesRes struct {
+	Result           *Result   `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinPair         *string   `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	Interval         *string   `protobuf:"bytes,11,opt,name=interval" json:"interval,omitempty"`
+	Candles          []*Candle `protobuf:"bytes,12,rep,name=candles" json:"candles,omitempty"`
+	XXX_unrecognized []byte    `json:"-"`
+}
+
+func (m *GetCandlesRes) Reset()                    { *m = GetCandlesRes{} }
+func (m *GetCandlesRes) String() string            { return proto.CompactTextString(m) }
+func (*GetCandlesRes) ProtoMessage()               {}
+func (*GetCandlesRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{20} }
+
+func (m *GetCandlesRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetCandlesRes) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetCandlesRes) GetInterval() string {
+	if m != nil && m.Interval != nil {
+		return *m.Interval
+	}
+	return ""
+}
+
+func (m *GetCandlesRes) GetCandles() []*Candle {
+	if m != nil {
+		return m.Candles
+	}
+	return nil
+}
+
+type GetTickerReq struct {
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetTickerReq) Reset()                    { *m = GetTickerReq{} }
+func (m *GetTickerReq) String() string            { return proto.CompactTextString(m) }
+func (*GetTickerReq) ProtoMessage()               {}
+func (*GetTickerReq) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{21} }
+
+func (m *GetTickerReq) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+type GetTickerRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinPair         *string `protobuf:"bytes,10,opt,name=coin_pair" json:"coin_pair,omitempty"`
+	BestBid          *uint64 `protobuf:"varint,11,opt,name=best_bid" json:"best_bid,omitempty"`
+	BestAsk          *uint64 `protobuf:"varint,12,opt,name=best_ask" json:"best_ask,omitempty"`
+	Spread           *uint64 `protobuf:"varint,13,opt,name=spread" json:"spread,omitempty"`
+	LastPrice        *uint64 `protobuf:"varint,14,opt,name=last_price" json:"last_price,omitempty"`
+	Volume           *uint64 `protobuf:"varint,15,opt,name=volume" json:"volume,omitempty"`
+	Time             *int64  `protobuf:"varint,16,opt,name=time" json:"time,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetTickerRes) Reset()                    { *m = GetTickerRes{} }
+func (m *GetTickerRes) String() string            { return proto.CompactTextString(m) }
+func (*GetTickerRes) ProtoMessage()               {}
+func (*GetTickerRes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{22} }
+
+func (m *GetTickerRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetTickerRes) GetCoinPair() string {
+	if m != nil && m.CoinPair != nil {
+		return *m.CoinPair
+	}
+	return ""
+}
+
+func (m *GetTickerRes) GetBestBid() uint64 {
+	if m != nil && m.BestBid != nil {
+		return *m.BestBid
+	}
+	return 0
+}
+
+func (m *GetTickerRes) GetBestAsk() uint64 {
+	if m != nil && m.BestAsk != nil {
+		return *m.BestAsk
+	}
+	return 0
+}
+
+func (m *GetTickerRes) GetSpread() uint64 {
+	if m != nil && m.Spread != nil {
+		return *m.Spread
+	}
+	return 0
+}
+
+func (m *GetTickerRes) GetLastPrice() uint64 {
+	if m != nil && m.LastPrice != nil {
+		return *m.LastPrice
+	}
+	return 0
+}
+
+func (m *GetTickerRes) GetVolume() uint64 {
+	if m != nil && m.Volume != nil {
+		return *m.Volume
+	}
+	return 0
+}
+
+func (m *GetTickerRes) GetTime() int64 {
+	if m != nil && m.Time != nil {
+		return *m.Time
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*OrderReq)(nil), "pp.OrderReq")
 	proto.RegisterType((*OrderRes)(nil), "pp.OrderRes")
+	proto.RegisterType((*BatchOrderReq)(nil), "pp.BatchOrderReq")
+	proto.RegisterType((*BatchOrderResult)(nil), "pp.BatchOrderResult")
+	proto.RegisterType((*BatchOrderRes)(nil), "pp.BatchOrderRes")
 	proto.RegisterType((*Order)(nil), "pp.Order")
 	proto.RegisterType((*GetOrderReq)(nil), "pp.GetOrderReq")
 	proto.RegisterType((*GetOrderRes)(nil), "pp.GetOrderRes")
+	proto.RegisterType((*GetOrderByIDReq)(nil), "pp.GetOrderByIDReq")
+	proto.RegisterType((*GetOrderByIDRes)(nil), "pp.GetOrderByIDRes")
+	proto.RegisterType((*GetAccountOrdersReq)(nil), "pp.GetAccountOrdersReq")
+	proto.RegisterType((*PairOrders)(nil), "pp.PairOrders")
+	proto.RegisterType((*GetAccountOrdersRes)(nil), "pp.GetAccountOrdersRes")
+	proto.RegisterType((*CancelOrderReq)(nil), "pp.CancelOrderReq")
+	proto.RegisterType((*CancelOrderRes)(nil), "pp.CancelOrderRes")
+	proto.RegisterType((*DepthLevel)(nil), "pp.DepthLevel")
+	proto.RegisterType((*GetDepthReq)(nil), "pp.GetDepthReq")
+	proto.RegisterType((*GetDepthRes)(nil), "pp.GetDepthRes")
+	proto.RegisterType((*Candle)(nil), "pp.Candle")
+	proto.RegisterType((*GetCandlesReq)(nil), "pp.GetCandlesReq")
+	proto.RegisterType((*GetCandlesRes)(nil), "pp.GetCandlesRes")
+	proto.RegisterType((*GetTickerReq)(nil), "pp.GetTickerReq")
+	proto.RegisterType((*GetTickerRes)(nil), "pp.GetTickerRes")
 }
 
 func init() { proto.RegisterFile("pp.order.proto", fileDescriptor6) }
 
 var fileDescriptor6 = []byte{
-	// 285 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x90, 0xb1, 0x4e, 0xc3, 0x30,
-	0x10, 0x86, 0x95, 0x3a, 0x0d, 0xcd, 0xb9, 0x0d, 0xc5, 0x12, 0x92, 0xe9, 0x14, 0x65, 0xca, 0x94,
-	0xa1, 0x13, 0x6f, 0xc0, 0x88, 0xd4, 0xb1, 0x4b, 0x64, 0x92, 0x43, 0x8a, 0x20, 0xf1, 0xe1, 0x5c,
-	0x86, 0xbe, 0x3d, 0xca, 0xa1, 0x96, 0xc2, 0x00, 0xe3, 0xfd, 0xf6, 0xfd, 0xdf, 0xe9, 0x83, 0x8c,
-	0xa8, 0xf2, 0xa1, 0xc5, 0x50, 0x51, 0xf0, 0xec, 0xcd, 0x82, 0x68, 0x77, 0x4b, 0x54, 0x35, 0xbe,
-	0xef, 0xfd, 0xf0, 0x15, 0x16, 0x47, 0x58, 0x3d, 0xcf, 0x7f, 0x0e, 0xf8, 0x61, 0x32, 0x48, 0x68,
-	0x7a, 0x79, 0xc3, 0x93, 0x85, 0x3c, 0x2a, 0x53, 0x73, 0x07, 0x69, 0xe3, 0xbb, 0xa1, 0x26, 0xd7,
-	0x05, 0xab, 0x25, 0x5a, 0x43, 0xcc, 0x27, 0x42, 0xbb, 0x96, 0x29, 0x83, 0xc4, 0xf5, 0x7e, 0x1a,
-	0xd8, 0x6e, 0xf2, 0xa8, 0x8c, 0xcd, 0x06, 0x96, 0x14, 0xba, 0x06, 0x6d, 0x36, 0x8f, 0xc5, 0xe3,
-	0xa5, 0x7b, 0x34, 0x3b, 0x48, 0x02, 0x8e, 0xd3, 0x3b, 0xdb, 0x28, 0x5f, 0x94, 0x7a, 0x0f, 0x15,
-	0x51, 0x75, 0x90, 0xc4, 0x6c, 0x61, 0x25, 0x77, 0xd6, 0x5d, 0x2b, 0x98, 0xb8, 0x78, 0x85, 0xa5,
-	0x6c, 0x1a, 0x80, 0x45, 0xd7, 0xda, 0x48, 0xda, 0xcf, 0x6c, 0x25, 0xec, 0x0b, 0x2b, 0x96, 0xc7,
-	0xef, 0x53, 0x96, 0x32, 0x6f, 0x61, 0x15, 0x70, 0xe4, 0xda, 0xf5, 0x6c, 0x13, 0x49, 0x0c, 0x40,
-	0x13, 0xd0, 0x31, 0xb6, 0xb5, 0x63, 0x7b, 0x93, 0x47, 0xa5, 0x2a, 0x8e, 0xa0, 0x9f, 0x90, 0xaf,
-	0x05, 0x04, 0x3f, 0x31, 0x06, 0x21, 0xfe, 0x12, 0x00, 0x3f, 0x04, 0xe8, 0xf3, 0x11, 0x23, 0xbb,
-	0xc0, 0xe2, 0x43, 0x19, 0x0d, 0x0a, 0x87, 0x56, 0x64, 0xa8, 0x02, 0xaf, 0xbb, 0xff, 0x16, 0xf0,
-	0x2f, 0xe7, 0x01, 0x12, 0x31, 0x34, 0xda, 0xfb, 0x5c, 0x95, 0x7a, 0x9f, 0xce, 0xcb, 0x52, 0xfd,
-	0x19, 0x00, 0x00, 0xff, 0xff, 0xf7, 0xe4, 0xde, 0xc2, 0xe6, 0x01, 0x00, 0x00,
+	// 787 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xa4, 0x55, 0x5d, 0x8b, 0xe3, 0x36,
+	0x14, 0xc5, 0xb1, 0xe3, 0x24, 0x37, 0x8e, 0x93, 0x78, 0x77, 0xa9, 0xba, 0x9d, 0x42, 0x6a, 0x28,
+	0xe4, 0x29, 0xb4, 0xfb, 0x50, 0x96, 0x3e, 0x94, 0x76, 0x77, 0x21, 0x14, 0x0a, 0x5d, 0x86, 0x7d,
+	0xea, 0xc3, 0x1a, 0xc5, 0xd6, 0x34, 0x22, 0xb6, 0xa5, 0x4a, 0x4a, 0x3a, 0xf9, 0x3d, 0xfd, 0x3f,
+	0xfd, 0x4d, 0x45, 0x57, 0x71, 0xbe, 0xe6, 0x83, 0x49, 0xe7, 0x51, 0x5f, 0xe7, 0xdc, 0x7b, 0xee,
+	0xb9, 0x57, 0x10, 0x4b, 0x39, 0x13, 0xaa, 0x60, 0x6a, 0x26, 0x95, 0x30, 0x22, 0x69, 0x49, 0xf9,
+	0x7a, 0x28, 0xe5, 0x2c, 0x17, 0x55, 0x25, 0x6a, 0xb7, 0x99, 0xfe, 0xeb, 0x41, 0xf7, 0x77, 0x7b,
+	0xe9, 0x9a, 0xfd, 0x95, 0xc4, 0x10, 0xca, 0xf5, 0x62, 0xc5, 0xb6, 0x04, 0x26, 0xde, 0xb4, 0x97,
+	0x0c, 0xa0, 0x5d, 0x8b, 0x3a, 0x67, 0xa4, 0x37, 0xf1, 0xa6, 0x41, 0x32, 0x86, 0x5e, 0x2e, 0x78,
+	0x9d, 0x49, 0xca, 0x15, 0xe9, 0xe3, 0x8d, 0x08, 0x02, 0xb3, 0x95, 0x8c, 0x44, 0xb8, 0x8a, 0x21,
+	0xa4, 0x95, 0x58, 0xd7, 0x86, 0x0c, 0xf0, 0xc1, 0x00, 0xda, 0x52, 0xf1, 0x9c, 0x91, 0x18, 0x97,
+	0x11, 0x04, 0x2b, 0x5e, 0x17, 0x64, 0x88, 0x97, 0x5f, 0xc1, 0xc0, 0xf0, 0x8a, 0x65, 0xbc, 0xce,
+	0x6e, 0x84, 0xca, 0x19, 0x19, 0xe1, 0xf6, 0x18, 0x7a, 0xec, 0x56, 0x72, 0xc5, 0x32, 0x6a, 0xc8,
+	0x78, 0xe2, 0x4d, 0xfd, 0x24, 0x01, 0xd0, 0x46, 0xc8, 0xcc, 0x61, 0x25, 0x88, 0xf5, 0x05, 0x0c,
+	0x1d, 0x55, 0x96, 0xaf, 0x95, 0x62, 0x75, 0xbe, 0x25, 0x2f, 0xec, 0xfb, 0xf4, 0xed, 0x3e, 0x1f,
+	0x9d, 0xbc, 0x86, 0x50, 0x31, 0xbd, 0x2e, 0x0d, 0xf1, 0x26, 0xad, 0x69, 0xff, 0x0d, 0xcc, 0xa4,
+	0x9c, 0x5d, 0xe3, 0x4e, 0x32, 0x82, 0x2e, 0x8a, 0x93, 0xf1, 0x02, 0x73, 0x09, 0xd2, 0x0c, 0x06,
+	0xef, 0xa8, 0xc9, 0x97, 0xcf, 0x90, 0xe3, 0x0a, 0x42, 0x04, 0xd5, 0x24, 0x9a, 0xf8, 0xd3, 0xfe,
+	0x9b, 0xc8, 0x12, 0x36, 0x78, 0xe9, 0xcf, 0x30, 0x3a, 0x26, 0xc0, 0x30, 0x2e, 0x0b, 0xf1, 0xfa,
+	0x34, 0xc4, 0xc7, 0x33, 0xfc, 0x16, 0x3a, 0xee, 0x4c, 0x93, 0x3e, 0x46, 0xf3, 0xd2, 0x1e, 0x9e,
+	0x47, 0x90, 0x6e, 0xa0, 0x8d, 0xcb, 0x04, 0xa0, 0xc5, 0x0b, 0xe2, 0x35, 0xa5, 0xc2, 0xba, 0xfa,
+	0x4d, 0xe2, 0x4e, 0xfb, 0x00, 0x0f, 0x0f, 0x65, 0x6e, 0xe3, 0x7a, 0x04, 0x5d, 0xc5, 0xb4, 0xc9,
+	0x68, 0x65, 0x48, 0x88, 0x3b, 0x09, 0x40, 0xae, 0x18, 0x35, 0xac, 0xb0, 0x55, 0xec, 0x60, 0x15,
+	0x63, 0x08, 0xb5, 0xa1, 0x66, 0xad, 0x49, 0x17, 0x0b, 0xb5, 0x82, 0xfe, 0x9c, 0x99, 0x63, 0xb1,
+	0x95, 0x58, 0x1b, 0xa6, 0x88, 0xd7, 0xf8, 0xe0, 0xa0, 0x2e, 0x9c, 0x98, 0xad, 0xdf, 0x04, 0xa5,
+	0x0d, 0x55, 0x06, 0xbd, 0xe7, 0x27, 0x7d, 0xf0, 0x59, 0x5d, 0xa0, 0xf1, 0xfc, 0x64, 0x08, 0x9d,
+	0xc5, 0x36, 0xb3, 0xf6, 0x42, 0xeb, 0x75, 0x53, 0x73, 0x4c, 0xf6, 0xb8, 0x6c, 0x4f, 0x21, 0x36,
+	0xc2, 0xd0, 0x72, 0x47, 0xfc, 0xe5, 0xbe, 0xe6, 0xaf, 0x50, 0xe5, 0xde, 0xbe, 0xe6, 0xe9, 0x0f,
+	0x30, 0x6c, 0x58, 0xdf, 0x6d, 0x7f, 0xfd, 0x60, 0xd3, 0xbc, 0x07, 0xfd, 0x6e, 0x99, 0xff, 0x38,
+	0x7f, 0x77, 0x71, 0xc4, 0x04, 0xda, 0x88, 0x89, 0x80, 0x27, 0x31, 0xbd, 0x85, 0x17, 0x73, 0x66,
+	0x7e, 0xc9, 0x73, 0x5b, 0x40, 0xdc, 0xd2, 0xf7, 0x79, 0x7d, 0x0c, 0x3d, 0x21, 0x59, 0x9d, 0x89,
+	0xba, 0xdc, 0x22, 0x48, 0x37, 0xfd, 0x11, 0xe0, 0x23, 0xe5, 0xca, 0xbd, 0x39, 0x25, 0x75, 0x25,
+	0x3b, 0x28, 0xd1, 0x3a, 0x57, 0xe2, 0xe3, 0x7d, 0xac, 0x8f, 0x67, 0xf5, 0x35, 0xb4, 0x2d, 0xb6,
+	0x26, 0x80, 0x60, 0xb1, 0x3d, 0x3a, 0xf0, 0xa7, 0x9f, 0x20, 0x7e, 0x4f, 0xeb, 0x9c, 0x95, 0xcf,
+	0x68, 0xd7, 0x63, 0xe5, 0x23, 0x54, 0xfe, 0xa7, 0x33, 0xd4, 0x4b, 0x67, 0xc8, 0xf7, 0x00, 0x1f,
+	0x98, 0x34, 0xcb, 0xdf, 0xd8, 0x86, 0x95, 0x87, 0xbe, 0x71, 0x4d, 0xf5, 0x12, 0x22, 0x34, 0x4e,
+	0xb6, 0xeb, 0x9e, 0x16, 0x3e, 0xf9, 0x0e, 0xad, 0x89, 0xaf, 0x1e, 0x30, 0x48, 0x0c, 0x61, 0x69,
+	0xf1, 0x34, 0x92, 0xf8, 0xe9, 0xed, 0xf1, 0x8b, 0x8b, 0xad, 0x71, 0x05, 0xc1, 0x82, 0x17, 0xcd,
+	0x4c, 0x40, 0x59, 0x8f, 0x42, 0xbe, 0x82, 0x80, 0xea, 0x55, 0x33, 0xbf, 0xce, 0x4e, 0xd3, 0xcf,
+	0x10, 0xbe, 0xa7, 0x75, 0x51, 0x32, 0x6c, 0x09, 0x5e, 0xb9, 0xcc, 0x7c, 0xbb, 0xb2, 0x6e, 0x71,
+	0x19, 0xd9, 0xd5, 0x92, 0xff, 0xb9, 0xc4, 0xe1, 0x11, 0xd8, 0xc6, 0x2c, 0xc5, 0xdf, 0xbb, 0xd1,
+	0x31, 0x80, 0x76, 0x5e, 0x0a, 0xcd, 0x76, 0x93, 0x23, 0x86, 0x70, 0x23, 0xca, 0x75, 0xc5, 0xdc,
+	0xdc, 0x48, 0x3f, 0xc3, 0x60, 0xce, 0x8c, 0xa3, 0xd0, 0x0f, 0xb7, 0x0b, 0xaf, 0x0d, 0x53, 0x1b,
+	0x5a, 0x3e, 0x61, 0x12, 0x44, 0x10, 0xdc, 0xf0, 0xb2, 0xdc, 0x8d, 0x81, 0xea, 0x14, 0xff, 0x62,
+	0xed, 0xee, 0x72, 0x7f, 0x05, 0x9d, 0xdc, 0xc1, 0xed, 0x24, 0x43, 0x04, 0xc7, 0x90, 0x7e, 0x03,
+	0xd1, 0x9c, 0x99, 0x4f, 0x3c, 0x5f, 0x39, 0x87, 0xde, 0x45, 0x4c, 0xff, 0xf1, 0x4e, 0xee, 0xfc,
+	0x9f, 0x88, 0x16, 0x76, 0xf6, 0x2e, 0x1a, 0x0b, 0xee, 0x77, 0xa8, 0x5e, 0x91, 0xa8, 0x51, 0x59,
+	0x4b, 0xc5, 0x68, 0xb1, 0xfb, 0x96, 0x13, 0x80, 0x92, 0x6a, 0x93, 0x1d, 0xff, 0xcd, 0x87, 0x4a,
+	0x0c, 0xf7, 0x1f, 0x00, 0xaf, 0xdc, 0xa7, 0xec, 0xff, 0x37, 0x00, 0x4d, 0x68, 0x91, 0xe2, 0x4b,
+	0x08, 0x00, 0x00,
 }
@@ -91,6 +91,7 @@ func (m *GetTxReq) GetTxid() string {
 type Tx struct {
 	Btc              *BtcTx `protobuf:"bytes,10,opt,name=btc" json:"btc,omitempty"`
 	Sky              *SkyTx `protobuf:"bytes,20,opt,name=sky" json:"sky,omitempty"`
+	Eth              *EthTx `protobuf:"bytes,30,opt,name=eth" json:"eth,omitempty"`
 	XXX_unrecognized []byte `json:"-"`
 }
 
@@ -113,6 +114,13 @@ func (m *Tx) GetSky() *SkyTx {
 	return nil
 }
 
+func (m *Tx) GetEth() *EthTx {
+	if m != nil {
+		return m.Eth
+	}
+	return nil
+}
+
 type GetTxRes struct {
 	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
 	CoinType         *string `protobuf:"bytes,10,opt,name=coin_type" json:"coin_type,omitempty"`
@@ -204,6 +212,64 @@ func (m *GetRawTxRes) GetRawtx() string {
 	return ""
 }
 
+type GetAddrTxsReq struct {
+	CoinType         *string  `protobuf:"bytes,10,opt,name=coin_type" json:"coin_type,omitempty"`
+	Addresses        []string `protobuf:"bytes,20,rep,name=addresses" json:"addresses,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *GetAddrTxsReq) Reset()                    { *m = GetAddrTxsReq{} }
+func (m *GetAddrTxsReq) String() string            { return proto.CompactTextString(m) }
+func (*GetAddrTxsReq) ProtoMessage()               {}
+func (*GetAddrTxsReq) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{7} }
+
+func (m *GetAddrTxsReq) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+func (m *GetAddrTxsReq) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+type GetAddrTxsRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinType         *string `protobuf:"bytes,10,opt,name=coin_type" json:"coin_type,omitempty"`
+	Txs              []*Tx   `protobuf:"bytes,20,rep,name=txs" json:"txs,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetAddrTxsRes) Reset()                    { *m = GetAddrTxsRes{} }
+func (m *GetAddrTxsRes) String() string            { return proto.CompactTextString(m) }
+func (*GetAddrTxsRes) ProtoMessage()               {}
+func (*GetAddrTxsRes) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{8} }
+
+func (m *GetAddrTxsRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetAddrTxsRes) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+func (m *GetAddrTxsRes) GetTxs() []*Tx {
+	if m != nil {
+		return m.Txs
+	}
+	return nil
+}
+
 type BtcTx struct {
 	Txid             *string    `protobuf:"bytes,10,opt,name=txid" json:"txid,omitempty"`
 	Version          *uint32    `protobuf:"varint,11,opt,name=version" json:"version,omitempty"`
@@ -220,7 +286,7 @@ type BtcTx struct {
 func (m *BtcTx) Reset()                    { *m = BtcTx{} }
 func (m *BtcTx) String() string            { return proto.CompactTextString(m) }
 func (*BtcTx) ProtoMessage()               {}
-func (*BtcTx) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{7} }
+func (*BtcTx) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{9} }
 
 func (m *BtcTx) GetTxid() string {
 	if m != nil && m.Txid != nil {
@@ -297,7 +363,7 @@ type BtcVin struct {
 func (m *BtcVin) Reset()                    { *m = BtcVin{} }
 func (m *BtcVin) String() string            { return proto.CompactTextString(m) }
 func (*BtcVin) ProtoMessage()               {}
-func (*BtcVin) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{8} }
+func (*BtcVin) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{10} }
 
 func (m *BtcVin) GetCoinbase() string {
 	if m != nil && m.Coinbase != nil {
@@ -343,7 +409,7 @@ type BtcScriptSig struct {
 func (m *BtcScriptSig) Reset()                    { *m = BtcScriptSig{} }
 func (m *BtcScriptSig) String() string            { return proto.CompactTextString(m) }
 func (*BtcScriptSig) ProtoMessage()               {}
-func (*BtcScriptSig) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{9} }
+func (*BtcScriptSig) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{11} }
 
 func (m *BtcScriptSig) GetAsm() string {
 	if m != nil && m.Asm != nil {
@@ -369,7 +435,7 @@ type BtcVout struct {
 func (m *BtcVout) Reset()                    { *m = BtcVout{} }
 func (m *BtcVout) String() string            { return proto.CompactTextString(m) }
 func (*BtcVout) ProtoMessage()               {}
-func (*BtcVout) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{10} }
+func (*BtcVout) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{12} }
 
 func (m *BtcVout) GetValue() string {
 	if m != nil && m.Value != nil {
@@ -404,7 +470,7 @@ type BtcScriptPubKeyResult struct {
 func (m *BtcScriptPubKeyResult) Reset()                    { *m = BtcScriptPubKeyResult{} }
 func (m *BtcScriptPubKeyResult) String() string            { return proto.CompactTextString(m) }
 func (*BtcScriptPubKeyResult) ProtoMessage()               {}
-func (*BtcScriptPubKeyResult) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{11} }
+func (*BtcScriptPubKeyResult) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{13} }
 
 func (m *BtcScriptPubKeyResult) GetAsm() string {
 	if m != nil && m.Asm != nil {
@@ -458,7 +524,7 @@ type SkyTx struct {
 func (m *SkyTx) Reset()                    { *m = SkyTx{} }
 func (m *SkyTx) String() string            { return proto.CompactTextString(m) }
 func (*SkyTx) ProtoMessage()               {}
-func (*SkyTx) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{12} }
+func (*SkyTx) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{14} }
 
 func (m *SkyTx) GetLength() uint32 {
 	if m != nil && m.Length != nil {
@@ -530,6 +596,80 @@ func (m *SkyTx) GetHeight() uint64 {
 	return 0
 }
 
+// EthTx the ether transfer transaction, the amounts are in wei.
+type EthTx struct {
+	Txid             *string `protobuf:"bytes,10,opt,name=txid" json:"txid,omitempty"`
+	From             *string `protobuf:"bytes,11,opt,name=from" json:"from,omitempty"`
+	To               *string `protobuf:"bytes,12,opt,name=to" json:"to,omitempty"`
+	Value            *uint64 `protobuf:"varint,13,opt,name=value" json:"value,omitempty"`
+	Nonce            *uint64 `protobuf:"varint,14,opt,name=nonce" json:"nonce,omitempty"`
+	Gas              *uint64 `protobuf:"varint,15,opt,name=gas" json:"gas,omitempty"`
+	GasPrice         *uint64 `protobuf:"varint,16,opt,name=gas_price" json:"gas_price,omitempty"`
+	BlockNumber      *uint64 `protobuf:"varint,17,opt,name=block_number" json:"block_number,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *EthTx) Reset()                    { *m = EthTx{} }
+func (m *EthTx) String() string            { return proto.CompactTextString(m) }
+func (*EthTx) ProtoMessage()               {}
+func (*EthTx) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{15} }
+
+func (m *EthTx) GetTxid() string {
+	if m != nil && m.Txid != nil {
+		return *m.Txid
+	}
+	return ""
+}
+
+func (m *EthTx) GetFrom() string {
+	if m != nil && m.From != nil {
+		return *m.From
+	}
+	return ""
+}
+
+func (m *EthTx) GetTo() string {
+	if m != nil && m.To != nil {
+		return *m.To
+	}
+	return ""
+}
+
+func (m *EthTx) GetValue() uint64 {
+	if m != nil && m.Value != nil {
+		return *m.Value
+	}
+	return 0
+}
+
+func (m *EthTx) GetNonce() uint64 {
+	if m != nil && m.Nonce != nil {
+		return *m.Nonce
+	}
+	return 0
+}
+
+func (m *EthTx) GetGas() uint64 {
+	if m != nil && m.Gas != nil {
+		return *m.Gas
+	}
+	return 0
+}
+
+func (m *EthTx) GetGasPrice() uint64 {
+	if m != nil && m.GasPrice != nil {
+		return *m.GasPrice
+	}
+	return 0
+}
+
+func (m *EthTx) GetBlockNumber() uint64 {
+	if m != nil && m.BlockNumber != nil {
+		return *m.BlockNumber
+	}
+	return 0
+}
+
 type SkyTxOutput struct {
 	Hash             *string `protobuf:"bytes,10,opt,name=hash" json:"hash,omitempty"`
 	Address          *string `protobuf:"bytes,11,opt,name=address" json:"address,omitempty"`
@@ -541,7 +681,7 @@ type SkyTxOutput struct {
 func (m *SkyTxOutput) Reset()                    { *m = SkyTxOutput{} }
 func (m *SkyTxOutput) String() string            { return proto.CompactTextString(m) }
 func (*SkyTxOutput) ProtoMessage()               {}
-func (*SkyTxOutput) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{13} }
+func (*SkyTxOutput) Descriptor() ([]byte, []int) { return fileDescriptor10, []int{16} }
 
 func (m *SkyTxOutput) GetHash() string {
 	if m != nil && m.Hash != nil {
@@ -579,56 +719,65 @@ func init() {
 	proto.RegisterType((*GetTxRes)(nil), "pp.GetTxRes")
 	proto.RegisterType((*GetRawTxReq)(nil), "pp.GetRawTxReq")
 	proto.RegisterType((*GetRawTxRes)(nil), "pp.GetRawTxRes")
+	proto.RegisterType((*GetAddrTxsReq)(nil), "pp.GetAddrTxsReq")
+	proto.RegisterType((*GetAddrTxsRes)(nil), "pp.GetAddrTxsRes")
 	proto.RegisterType((*BtcTx)(nil), "pp.BtcTx")
 	proto.RegisterType((*BtcVin)(nil), "pp.BtcVin")
 	proto.RegisterType((*BtcScriptSig)(nil), "pp.BtcScriptSig")
 	proto.RegisterType((*BtcVout)(nil), "pp.BtcVout")
 	proto.RegisterType((*BtcScriptPubKeyResult)(nil), "pp.BtcScriptPubKeyResult")
 	proto.RegisterType((*SkyTx)(nil), "pp.SkyTx")
+	proto.RegisterType((*EthTx)(nil), "pp.EthTx")
 	proto.RegisterType((*SkyTxOutput)(nil), "pp.SkyTxOutput")
 }
 
 func init() { proto.RegisterFile("pp.transaction.proto", fileDescriptor10) }
 
 var fileDescriptor10 = []byte{
-	// 609 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x53, 0x41, 0x6f, 0xda, 0x4c,
-	0x10, 0x95, 0x01, 0x13, 0x3c, 0xc6, 0x40, 0xfc, 0x25, 0x5f, 0x37, 0x39, 0x59, 0xee, 0x05, 0xa9,
-	0x2a, 0x95, 0x50, 0x0f, 0x3d, 0xe7, 0x12, 0xb5, 0x51, 0xd5, 0x28, 0xa0, 0x5e, 0x23, 0x63, 0xb6,
-	0x78, 0x0b, 0xde, 0xdd, 0x78, 0xd7, 0xc4, 0xfc, 0xb2, 0x4a, 0xfd, 0x75, 0xd5, 0x8e, 0xbd, 0x56,
-	0xa8, 0x92, 0xaa, 0xbd, 0xe1, 0xb7, 0x33, 0xef, 0xbd, 0x99, 0x79, 0xc0, 0x99, 0x94, 0x33, 0x5d,
-	0x24, 0x5c, 0x25, 0xa9, 0x66, 0x82, 0xcf, 0x64, 0x21, 0xb4, 0x08, 0x3b, 0x52, 0x5e, 0x8e, 0xa5,
-	0x9c, 0xa5, 0x22, 0xcf, 0x2d, 0x18, 0xbf, 0x85, 0xe1, 0x47, 0xfe, 0x9d, 0xa6, 0x7a, 0x59, 0xf1,
-	0x3b, 0xfa, 0x10, 0x9e, 0x82, 0x97, 0x0a, 0xc6, 0xef, 0xf5, 0x41, 0x52, 0x02, 0x91, 0x33, 0xf5,
-	0x42, 0x80, 0x8e, 0xae, 0xc8, 0x99, 0xf9, 0x1d, 0x7f, 0x38, 0x2a, 0x57, 0xe1, 0x25, 0xf4, 0x0b,
-	0xaa, 0xca, 0x9d, 0x26, 0x4e, 0xd4, 0x99, 0xfa, 0x73, 0x98, 0x49, 0x39, 0xbb, 0x43, 0x24, 0x1c,
-	0x42, 0x4f, 0x57, 0x6c, 0x5d, 0xb3, 0xc4, 0x6f, 0x60, 0x70, 0x4d, 0xf5, 0xb2, 0x7a, 0x41, 0xc4,
-	0x16, 0xd7, 0x32, 0xef, 0xa1, 0xb3, 0xac, 0xc2, 0xff, 0xa1, 0xbb, 0xd2, 0x29, 0x16, 0xf8, 0x73,
-	0xcf, 0x30, 0x5f, 0xe9, 0xb4, 0xc6, 0xd5, 0xf6, 0x80, 0xa5, 0x0d, 0xbe, 0xd8, 0x1e, 0x96, 0x55,
-	0xfc, 0xb9, 0x95, 0xf8, 0xb3, 0xb1, 0x67, 0xe4, 0xc3, 0x76, 0x46, 0x7f, 0xde, 0x37, 0xa5, 0xcb,
-	0x2a, 0x9e, 0x81, 0x7f, 0x4d, 0xf5, 0x5d, 0xf2, 0xf8, 0x97, 0xa6, 0x6f, 0x9e, 0xd6, 0xff, 0xb3,
-	0x83, 0x00, 0xdc, 0x22, 0x79, 0x6c, 0x17, 0xfd, 0xc3, 0x01, 0xb7, 0x9e, 0xf6, 0x68, 0x8d, 0xe1,
-	0x18, 0x4e, 0xf6, 0xb4, 0x50, 0x4c, 0x70, 0xe2, 0x47, 0xce, 0x34, 0x08, 0x27, 0x30, 0xd8, 0x89,
-	0x74, 0xab, 0x59, 0x4e, 0xc9, 0x10, 0x91, 0x57, 0xd0, 0xdd, 0x33, 0x4e, 0x82, 0xa8, 0x6b, 0x55,
-	0xaf, 0x74, 0xfa, 0x95, 0xf1, 0xf0, 0x02, 0x7a, 0x7b, 0x51, 0x6a, 0x32, 0xc2, 0x17, 0xdf, 0xbe,
-	0x88, 0x12, 0x0d, 0xad, 0x0c, 0x4d, 0x96, 0xa8, 0x8c, 0x8c, 0x51, 0xe9, 0x1c, 0x82, 0x54, 0xf0,
-	0x6f, 0xac, 0xc8, 0x13, 0x13, 0x22, 0x45, 0x26, 0x91, 0x33, 0xed, 0xa1, 0x1d, 0xa3, 0x75, 0x1a,
-	0x39, 0xd3, 0x6e, 0xdb, 0x87, 0x50, 0x68, 0xa0, 0x38, 0x87, 0x7e, 0xa3, 0x37, 0x81, 0x81, 0x99,
-	0x72, 0x95, 0xa8, 0xdf, 0x17, 0xe6, 0xdb, 0x2f, 0xf4, 0x53, 0xdb, 0x7e, 0x0d, 0x9e, 0x4a, 0x0b,
-	0x26, 0xf5, 0x82, 0x6d, 0x48, 0x80, 0x97, 0x98, 0x34, 0x16, 0x17, 0x16, 0x37, 0x94, 0x8a, 0x3e,
-	0x94, 0x94, 0xa7, 0x94, 0x8c, 0x4c, 0x5b, 0x3c, 0x85, 0xe1, 0x51, 0x85, 0x0f, 0xdd, 0x44, 0xe5,
-	0x8d, 0x9e, 0x0f, 0xdd, 0x8c, 0x56, 0xb5, 0x5c, 0xbc, 0x84, 0x13, 0x3b, 0x6e, 0x00, 0xee, 0x3e,
-	0xd9, 0x95, 0xd6, 0x96, 0x07, 0x8e, 0x5d, 0xe7, 0x3b, 0x18, 0xd6, 0x2e, 0x6e, 0xcb, 0xd5, 0x96,
-	0x1e, 0xd0, 0x9b, 0x3f, 0xbf, 0x38, 0x32, 0x72, 0x5b, 0xae, 0x6e, 0xe8, 0xa1, 0x3e, 0x65, 0x9c,
-	0xc2, 0xf9, 0xb3, 0x0f, 0x2f, 0x1b, 0x31, 0x37, 0x2c, 0xe8, 0xc3, 0x82, 0x6d, 0x14, 0xd2, 0xbb,
-	0xb8, 0x16, 0x93, 0x84, 0x00, 0x9f, 0x4f, 0xc1, 0x4b, 0xd6, 0xeb, 0x82, 0x2a, 0x45, 0x15, 0xde,
-	0xca, 0x8b, 0x7f, 0x3a, 0xe0, 0x62, 0xc6, 0xc3, 0x11, 0xf4, 0x77, 0x94, 0x6f, 0x74, 0x86, 0xc4,
-	0x41, 0xdb, 0xea, 0x5b, 0x22, 0xbc, 0xe0, 0xb0, 0x09, 0x35, 0x30, 0xce, 0x69, 0x71, 0x8f, 0x58,
-	0x60, 0x77, 0xae, 0x8c, 0x30, 0xf2, 0x1a, 0x36, 0xc6, 0x65, 0xa9, 0x15, 0x19, 0xe3, 0x77, 0x04,
-	0x27, 0xa2, 0xd4, 0x08, 0x4c, 0x30, 0x24, 0xe3, 0xf6, 0xdf, 0xf5, 0x05, 0x71, 0xd3, 0x51, 0xf2,
-	0x2d, 0x17, 0x8f, 0x78, 0xed, 0x41, 0x9d, 0x64, 0x4c, 0x09, 0x5d, 0x93, 0xff, 0x10, 0x1a, 0x41,
-	0x3f, 0xa3, 0x6c, 0x93, 0x69, 0x8c, 0x72, 0x2f, 0xfe, 0x04, 0xfe, 0x53, 0x06, 0xeb, 0xb1, 0xcd,
-	0x73, 0x33, 0x6c, 0xb3, 0x9c, 0x00, 0x5c, 0x13, 0x1a, 0xd5, 0xcc, 0x10, 0x80, 0x9b, 0x89, 0xb2,
-	0x50, 0x68, 0xbf, 0xf7, 0x2b, 0x00, 0x00, 0xff, 0xff, 0x13, 0x7d, 0x20, 0xd3, 0xda, 0x04, 0x00,
-	0x00,
+	// 715 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x54, 0xdf, 0x6b, 0xdb, 0x3a,
+	0x18, 0xc5, 0xb1, 0x9d, 0x26, 0x9f, 0xed, 0x24, 0x75, 0xd3, 0x7b, 0xd5, 0x3e, 0x5c, 0x8c, 0xef,
+	0x4b, 0xe0, 0x72, 0x33, 0x08, 0x0c, 0xf6, 0xba, 0xc2, 0x28, 0x5b, 0x37, 0x56, 0x9a, 0xb0, 0xd7,
+	0xe0, 0x38, 0x6a, 0xac, 0x25, 0x96, 0x5c, 0x49, 0x4e, 0x9d, 0xe7, 0xfd, 0x51, 0x83, 0xfd, 0x75,
+	0x43, 0xf2, 0x8f, 0x34, 0x5d, 0x3b, 0xd6, 0x37, 0xfb, 0xe8, 0xd3, 0x77, 0x8e, 0xbe, 0x73, 0x24,
+	0x18, 0x66, 0xd9, 0x58, 0xf2, 0x88, 0x8a, 0x28, 0x96, 0x84, 0xd1, 0x71, 0xc6, 0x99, 0x64, 0x7e,
+	0x2b, 0xcb, 0xce, 0xfb, 0x59, 0x36, 0x8e, 0x59, 0x9a, 0xd6, 0x60, 0xf8, 0x3f, 0xb8, 0xef, 0xe9,
+	0x57, 0x1c, 0xcb, 0x59, 0x41, 0x6f, 0xf0, 0x9d, 0x7f, 0x0c, 0xdd, 0x98, 0x11, 0x3a, 0x97, 0xbb,
+	0x0c, 0x23, 0x08, 0x8c, 0x51, 0xd7, 0x07, 0x68, 0xc9, 0x02, 0x0d, 0xd5, 0x77, 0xf8, 0xe6, 0xa0,
+	0x5c, 0xf8, 0xe7, 0xd0, 0xe6, 0x58, 0xe4, 0x1b, 0x89, 0x8c, 0xa0, 0x35, 0x72, 0x26, 0x30, 0xce,
+	0xb2, 0xf1, 0x8d, 0x46, 0x7c, 0x17, 0x2c, 0x59, 0x90, 0x65, 0xd9, 0x25, 0xfc, 0x0f, 0x3a, 0x97,
+	0x58, 0xce, 0x8a, 0x67, 0x48, 0xea, 0xe2, 0x92, 0xe6, 0x23, 0xb4, 0x66, 0x85, 0xff, 0x17, 0x98,
+	0x0b, 0x19, 0xeb, 0x02, 0x67, 0xd2, 0x55, 0x9d, 0x2f, 0x64, 0x5c, 0xe2, 0x62, 0xbd, 0x43, 0xc3,
+	0x3d, 0x3e, 0x5d, 0xef, 0x4a, 0x1c, 0xcb, 0x04, 0xfd, 0xb3, 0xc7, 0xdf, 0xc9, 0x64, 0x56, 0x84,
+	0x9f, 0x1a, 0xea, 0xdf, 0x0b, 0x7e, 0x42, 0x96, 0xdf, 0x9c, 0xdd, 0x99, 0xb4, 0x55, 0xe9, 0xac,
+	0x08, 0xc7, 0xe0, 0x5c, 0x62, 0x79, 0x13, 0xdd, 0xff, 0xe1, 0x61, 0xae, 0x1e, 0xd6, 0xbf, 0x58,
+	0x81, 0x07, 0x36, 0x8f, 0xee, 0x1b, 0x03, 0x5e, 0x83, 0x77, 0x89, 0xe5, 0xdb, 0xe5, 0x92, 0xcf,
+	0x0a, 0xf1, 0x0c, 0xfd, 0x31, 0x74, 0xa3, 0xe5, 0x92, 0x63, 0x21, 0xb0, 0x40, 0xc3, 0xc0, 0x1c,
+	0x75, 0xc3, 0xe9, 0xe1, 0xb6, 0x17, 0xab, 0x38, 0x01, 0x53, 0x16, 0x65, 0xb3, 0xfd, 0x20, 0xbe,
+	0x1b, 0x60, 0x97, 0x8e, 0x1c, 0x58, 0xed, 0xf7, 0xe1, 0x68, 0x8b, 0xb9, 0x20, 0x8c, 0x22, 0x27,
+	0x30, 0x46, 0x9e, 0x3f, 0x80, 0xce, 0x86, 0xc5, 0x6b, 0x49, 0x52, 0x8c, 0x5c, 0x8d, 0xfc, 0x0d,
+	0xe6, 0x96, 0x50, 0xe4, 0x05, 0x66, 0xcd, 0x7d, 0x21, 0xe3, 0x2f, 0x84, 0xfa, 0x67, 0x60, 0x6d,
+	0x59, 0x2e, 0x51, 0x4f, 0xaf, 0x38, 0xf5, 0x0a, 0xcb, 0xb5, 0xac, 0x85, 0x6a, 0x93, 0x44, 0x22,
+	0x41, 0x7d, 0xcd, 0x74, 0x0a, 0x5e, 0xcc, 0xe8, 0x2d, 0xe1, 0x69, 0xa4, 0x82, 0x2e, 0xd0, 0x20,
+	0x30, 0x46, 0x96, 0x96, 0xa3, 0xb8, 0x8e, 0x03, 0x63, 0x64, 0x36, 0xfb, 0x34, 0xe4, 0x2b, 0x28,
+	0x4c, 0xa1, 0x5d, 0xf1, 0x0d, 0xa0, 0xa3, 0xce, 0xba, 0x88, 0xc4, 0x63, 0xf3, 0x9c, 0xfa, 0x4f,
+	0xeb, 0x29, 0x65, 0xff, 0x0b, 0x5d, 0x11, 0x73, 0x92, 0xc9, 0x29, 0x59, 0x21, 0x4f, 0xa7, 0x62,
+	0x50, 0x49, 0x9c, 0xd6, 0xb8, 0x6a, 0x29, 0xf0, 0x5d, 0x8e, 0x69, 0x8c, 0x51, 0x4f, 0x6d, 0x0b,
+	0x47, 0xe0, 0x1e, 0x54, 0x38, 0x60, 0x46, 0x22, 0xad, 0xf8, 0x1c, 0x30, 0x13, 0x5c, 0x94, 0x74,
+	0xe1, 0x0c, 0x8e, 0xea, 0xe3, 0x7a, 0x60, 0x6f, 0xa3, 0x4d, 0x5e, 0xcb, 0xea, 0x82, 0x51, 0x8f,
+	0xf3, 0x15, 0xb8, 0xa5, 0x8a, 0xeb, 0x7c, 0xb1, 0xc6, 0x3b, 0xad, 0xcd, 0x99, 0x9c, 0x1d, 0x08,
+	0xb9, 0xce, 0x17, 0x57, 0x78, 0x57, 0x1a, 0x1a, 0xc6, 0x70, 0xfa, 0xe4, 0xc2, 0xf3, 0x42, 0x94,
+	0x87, 0x1c, 0xdf, 0x4d, 0xc9, 0x4a, 0xe8, 0xf6, 0xb6, 0x1e, 0x8b, 0xca, 0x83, 0xf7, 0x6b, 0xc4,
+	0x7a, 0x3a, 0x62, 0x3f, 0x0c, 0xb0, 0xcb, 0x7b, 0xd8, 0x83, 0xf6, 0x06, 0xd3, 0x95, 0x4c, 0x74,
+	0x63, 0xaf, 0xd9, 0xea, 0xd4, 0x8d, 0xb4, 0x83, 0x6e, 0x75, 0xc1, 0x80, 0x50, 0x8a, 0xf9, 0x5c,
+	0x63, 0x5e, 0x3d, 0x73, 0x41, 0x56, 0x55, 0x5f, 0xd5, 0x8d, 0xd0, 0x2c, 0x97, 0x02, 0xf5, 0xf5,
+	0x7f, 0x00, 0x47, 0x2c, 0x97, 0x1a, 0x18, 0xe8, 0x90, 0xf4, 0x9b, 0x17, 0xe0, 0xb3, 0xc6, 0xd5,
+	0x8e, 0x9c, 0xae, 0x29, 0xbb, 0xd7, 0x6e, 0x77, 0xca, 0x3c, 0xeb, 0x94, 0xe0, 0x25, 0x3a, 0xd1,
+	0x50, 0x0f, 0xda, 0x09, 0x26, 0xab, 0x44, 0xea, 0x6b, 0x65, 0x85, 0xdf, 0x0c, 0xb0, 0xf5, 0x63,
+	0xf1, 0x28, 0xca, 0x2e, 0x58, 0xb7, 0x9c, 0xa5, 0xc8, 0x69, 0x5e, 0x42, 0x56, 0x09, 0x6f, 0xec,
+	0xf1, 0x74, 0xe4, 0x3c, 0xb0, 0x29, 0xab, 0x1d, 0xb7, 0xd4, 0x2c, 0x57, 0x91, 0xd0, 0x29, 0xb5,
+	0x14, 0xff, 0x2a, 0x12, 0xf3, 0x8c, 0x93, 0x18, 0x57, 0x09, 0x1d, 0x82, 0xab, 0x33, 0x39, 0xa7,
+	0x79, 0xba, 0xc0, 0x5c, 0x27, 0xd5, 0x0a, 0x3f, 0x80, 0xf3, 0xf0, 0x1c, 0xf5, 0xa4, 0x9a, 0x5b,
+	0x55, 0x8d, 0xbc, 0x52, 0xe3, 0x81, 0xad, 0xa2, 0x2b, 0xf6, 0x82, 0x12, 0x96, 0x73, 0x51, 0x0a,
+	0xfa, 0x39, 0x00, 0x63, 0x90, 0x8c, 0xdf, 0x04, 0x06, 0x00, 0x00,
 }
@@ -14,11 +14,17 @@ var _ = fmt.Errorf
 var _ = math.Inf
 
 type WithdrawalReq struct {
-	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
-	CoinType         *string `protobuf:"bytes,11,opt,name=coin_type" json:"coin_type,omitempty"`
-	Coins            *uint64 `protobuf:"varint,12,opt,name=coins" json:"coins,omitempty"`
-	OutputAddress    *string `protobuf:"bytes,13,opt,name=output_address" json:"output_address,omitempty"`
-	XXX_unrecognized []byte  `json:"-"`
+	Pubkey           *string  `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Nonce            *uint64  `protobuf:"varint,9,opt,name=nonce" json:"nonce,omitempty"`
+	CoinType         *string  `protobuf:"bytes,11,opt,name=coin_type" json:"coin_type,omitempty"`
+	Coins            *uint64  `protobuf:"varint,12,opt,name=coins" json:"coins,omitempty"`
+	OutputAddress    *string  `protobuf:"bytes,13,opt,name=output_address" json:"output_address,omitempty"`
+	IdempotencyKey   *string  `protobuf:"bytes,14,opt,name=idempotency_key" json:"idempotency_key,omitempty"`
+	FeeRate          *uint64  `protobuf:"varint,15,opt,name=fee_rate" json:"fee_rate,omitempty"`
+	Memo             *string  `protobuf:"bytes,16,opt,name=memo" json:"memo,omitempty"`
+	Utxos            []string `protobuf:"bytes,17,rep,name=utxos" json:"utxos,omitempty"`
+	TotpCode         *string  `protobuf:"bytes,18,opt,name=totp_code" json:"totp_code,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
 }
 
 func (m *WithdrawalReq) Reset()                    { *m = WithdrawalReq{} }
@@ -33,6 +39,13 @@ func (m *WithdrawalReq) GetPubkey() string {
 	return ""
 }
 
+func (m *WithdrawalReq) GetNonce() uint64 {
+	if m != nil && m.Nonce != nil {
+		return *m.Nonce
+	}
+	return 0
+}
+
 func (m *WithdrawalReq) GetCoinType() string {
 	if m != nil && m.CoinType != nil {
 		return *m.CoinType
@@ -54,6 +67,41 @@ func (m *WithdrawalReq) GetOutputAddress() string {
 	return ""
 }
 
+func (m *WithdrawalReq) GetIdempotencyKey() string {
+	if m != nil && m.IdempotencyKey != nil {
+		return *m.IdempotencyKey
+	}
+	return ""
+}
+
+func (m *WithdrawalReq) GetFeeRate() uint64 {
+	if m != nil && m.FeeRate != nil {
+		return *m.FeeRate
+	}
+	return 0
+}
+
+func (m *WithdrawalReq) GetMemo() string {
+	if m != nil && m.Memo != nil {
+		return *m.Memo
+	}
+	return ""
+}
+
+func (m *WithdrawalReq) GetUtxos() []string {
+	if m != nil {
+		return m.Utxos
+	}
+	return nil
+}
+
+func (m *WithdrawalReq) GetTotpCode() string {
+	if m != nil && m.TotpCode != nil {
+		return *m.TotpCode
+	}
+	return ""
+}
+
 type WithdrawalRes struct {
 	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
 	NewTxid          *string `protobuf:"bytes,20,opt,name=new_txid" json:"new_txid,omitempty"`
@@ -79,25 +127,124 @@ func (m *WithdrawalRes) GetNewTxid() string {
 	return ""
 }
 
+type GetWithdrawalReq struct {
+	Pubkey           *string `protobuf:"bytes,10,opt,name=pubkey" json:"pubkey,omitempty"`
+	Txid             *string `protobuf:"bytes,11,opt,name=txid" json:"txid,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetWithdrawalReq) Reset()                    { *m = GetWithdrawalReq{} }
+func (m *GetWithdrawalReq) String() string            { return proto.CompactTextString(m) }
+func (*GetWithdrawalReq) ProtoMessage()               {}
+func (*GetWithdrawalReq) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{2} }
+
+func (m *GetWithdrawalReq) GetPubkey() string {
+	if m != nil && m.Pubkey != nil {
+		return *m.Pubkey
+	}
+	return ""
+}
+
+func (m *GetWithdrawalReq) GetTxid() string {
+	if m != nil && m.Txid != nil {
+		return *m.Txid
+	}
+	return ""
+}
+
+type GetWithdrawalRes struct {
+	Result           *Result `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	CoinType         *string `protobuf:"bytes,10,opt,name=coin_type" json:"coin_type,omitempty"`
+	Address          *string `protobuf:"bytes,11,opt,name=address" json:"address,omitempty"`
+	Amount           *uint64 `protobuf:"varint,12,opt,name=amount" json:"amount,omitempty"`
+	Txid             *string `protobuf:"bytes,13,opt,name=txid" json:"txid,omitempty"`
+	Memo             *string `protobuf:"bytes,14,opt,name=memo" json:"memo,omitempty"`
+	Time             *int64  `protobuf:"varint,15,opt,name=time" json:"time,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *GetWithdrawalRes) Reset()                    { *m = GetWithdrawalRes{} }
+func (m *GetWithdrawalRes) String() string            { return proto.CompactTextString(m) }
+func (*GetWithdrawalRes) ProtoMessage()               {}
+func (*GetWithdrawalRes) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{3} }
+
+func (m *GetWithdrawalRes) GetResult() *Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *GetWithdrawalRes) GetCoinType() string {
+	if m != nil && m.CoinType != nil {
+		return *m.CoinType
+	}
+	return ""
+}
+
+func (m *GetWithdrawalRes) GetAddress() string {
+	if m != nil && m.Address != nil {
+		return *m.Address
+	}
+	return ""
+}
+
+func (m *GetWithdrawalRes) GetAmount() uint64 {
+	if m != nil && m.Amount != nil {
+		return *m.Amount
+	}
+	return 0
+}
+
+func (m *GetWithdrawalRes) GetTxid() string {
+	if m != nil && m.Txid != nil {
+		return *m.Txid
+	}
+	return ""
+}
+
+func (m *GetWithdrawalRes) GetMemo() string {
+	if m != nil && m.Memo != nil {
+		return *m.Memo
+	}
+	return ""
+}
+
+func (m *GetWithdrawalRes) GetTime() int64 {
+	if m != nil && m.Time != nil {
+		return *m.Time
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*WithdrawalReq)(nil), "pp.WithdrawalReq")
 	proto.RegisterType((*WithdrawalRes)(nil), "pp.WithdrawalRes")
+	proto.RegisterType((*GetWithdrawalReq)(nil), "pp.GetWithdrawalReq")
+	proto.RegisterType((*GetWithdrawalRes)(nil), "pp.GetWithdrawalRes")
 }
 
 func init() { proto.RegisterFile("pp.withdrawal.proto", fileDescriptor4) }
 
 var fileDescriptor4 = []byte{
-	// 179 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x54, 0xcc, 0xc1, 0xaa, 0x82, 0x40,
-	0x14, 0xc6, 0x71, 0x94, 0x7b, 0x25, 0x8f, 0x69, 0x35, 0x45, 0x0c, 0xae, 0xc4, 0x95, 0xab, 0x59,
-	0xb4, 0xef, 0x25, 0xdc, 0x44, 0x2b, 0x31, 0x67, 0x20, 0x49, 0x9d, 0xd3, 0xcc, 0x19, 0xcc, 0xb7,
-	0x0f, 0x0d, 0x82, 0xb6, 0x3f, 0xfe, 0xdf, 0x07, 0x7b, 0x44, 0x31, 0xb6, 0x74, 0x97, 0xa6, 0x1e,
-	0xeb, 0x4e, 0xa0, 0xd1, 0xa4, 0x99, 0x8f, 0x98, 0x6e, 0x10, 0x45, 0xa3, 0xfb, 0x5e, 0x0f, 0x1f,
-	0xcc, 0xaf, 0x10, 0x5f, 0xbe, 0x61, 0xa9, 0x9e, 0x2c, 0x81, 0x00, 0xdd, 0xed, 0xa1, 0x26, 0x0e,
-	0x99, 0x57, 0x84, 0x6c, 0x07, 0x61, 0xa3, 0xdb, 0xa1, 0xa2, 0x09, 0x15, 0x8f, 0x16, 0x8a, 0xe1,
-	0x7f, 0x26, 0xcb, 0xd7, 0x99, 0x57, 0xfc, 0xb1, 0x23, 0x24, 0xda, 0x11, 0x3a, 0xaa, 0x6a, 0x29,
-	0x8d, 0xb2, 0x96, 0xc7, 0x73, 0x96, 0x9f, 0x7f, 0xaf, 0x2d, 0x4b, 0x21, 0x30, 0xca, 0xba, 0x8e,
-	0xb8, 0x97, 0xf9, 0x45, 0x74, 0x02, 0x81, 0x28, 0xca, 0x45, 0xd8, 0x16, 0x56, 0x83, 0x1a, 0x2b,
-	0x7a, 0xb5, 0x92, 0x1f, 0xe6, 0xf9, 0x3b, 0x00, 0x00, 0xff, 0xff, 0x04, 0xf5, 0x53, 0xc7, 0xc4,
-	0x00, 0x00, 0x00,
+	// 298 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x90, 0xcf, 0x4e, 0xbb, 0x40,
+	0x10, 0xc7, 0x43, 0xdb, 0x5f, 0x7f, 0x32, 0x2d, 0xd0, 0xa2, 0xd1, 0x4d, 0x4f, 0xa4, 0x27, 0x4e,
+	0xc4, 0x78, 0xf7, 0xec, 0xbd, 0x17, 0x8f, 0x04, 0x61, 0x8c, 0xc4, 0xee, 0xce, 0x08, 0xb3, 0x69,
+	0xfb, 0x0e, 0xbe, 0x95, 0x2f, 0x66, 0x58, 0x5c, 0x93, 0xc6, 0xa4, 0xc7, 0x9d, 0xfd, 0xfe, 0x99,
+	0xf9, 0xc0, 0x35, 0x73, 0x71, 0x68, 0xe5, 0xad, 0xe9, 0xaa, 0x43, 0xb5, 0x2f, 0xb8, 0x23, 0xa1,
+	0x74, 0xc2, 0xbc, 0x49, 0x98, 0x8b, 0x9a, 0xb4, 0x26, 0x33, 0x0e, 0xb7, 0x5f, 0x01, 0x44, 0xcf,
+	0xbf, 0xca, 0x1d, 0x7e, 0xa4, 0x31, 0xcc, 0xd9, 0xbe, 0xbc, 0xe3, 0x49, 0x41, 0x16, 0xe4, 0x61,
+	0x1a, 0xc1, 0x3f, 0x43, 0xa6, 0x46, 0x15, 0x66, 0x41, 0x3e, 0x4b, 0xd7, 0x10, 0xd6, 0xd4, 0x9a,
+	0x52, 0x4e, 0x8c, 0x6a, 0xe1, 0x15, 0xc3, 0xa8, 0x57, 0x4b, 0xa7, 0xb8, 0x85, 0x98, 0xac, 0xb0,
+	0x95, 0xb2, 0x6a, 0x9a, 0x0e, 0xfb, 0x5e, 0x45, 0x4e, 0x76, 0x07, 0x49, 0xdb, 0xa0, 0x66, 0x12,
+	0x34, 0xf5, 0xa9, 0x1c, 0x1a, 0x62, 0xf7, 0xb1, 0x82, 0xab, 0x57, 0xc4, 0xb2, 0xab, 0x04, 0x55,
+	0xe2, 0x22, 0x96, 0x30, 0xd3, 0xa8, 0x49, 0xad, 0x7c, 0xbe, 0x95, 0x23, 0xf5, 0x6a, 0x9d, 0x4d,
+	0xf3, 0x70, 0xd8, 0x40, 0x48, 0xb8, 0xac, 0xa9, 0x41, 0x95, 0x0e, 0x8a, 0xed, 0xe3, 0xf9, 0x11,
+	0x7d, 0xba, 0x81, 0x79, 0x87, 0xbd, 0xdd, 0x8b, 0x0a, 0xb2, 0x49, 0xbe, 0x78, 0x80, 0x82, 0xb9,
+	0xd8, 0xb9, 0xc9, 0x50, 0x67, 0xf0, 0x50, 0xca, 0xb1, 0x6d, 0xd4, 0x8d, 0xb3, 0xdf, 0xc3, 0xea,
+	0x09, 0xe5, 0x32, 0x86, 0x25, 0xcc, 0x9c, 0xc3, 0x9d, 0xbc, 0xfd, 0x0c, 0xfe, 0x58, 0x2e, 0x97,
+	0x9e, 0x61, 0x1b, 0x13, 0x13, 0xf8, 0xef, 0x01, 0x8d, 0x1c, 0x63, 0x98, 0x57, 0x9a, 0xac, 0x91,
+	0x1f, 0x90, 0xbe, 0x32, 0xf2, 0x0b, 0x38, 0x26, 0xb1, 0x7f, 0x49, 0xab, 0x47, 0x5e, 0xd3, 0xef,
+	0x01, 0x00, 0x45, 0x71, 0xc3, 0x4b, 0xf0, 0x01, 0x00, 0x00,
 }
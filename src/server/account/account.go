@@ -2,10 +2,11 @@ package account
 
 import (
 	"errors"
-	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 
 	logging "github.com/op/go-logging"
 	"github.com/skycoin/skycoin/src/util"
@@ -14,31 +15,83 @@ import (
 var (
 	acntDir  = filepath.Join(util.UserHome(), ".skycoin-exchange/account")
 	acntName = "account.data"
-	logger   = logging.MustGetLogger("exchange.account")
+	// the nonces are saved on every signed request, apart from the accounts to keep the writes small.
+	nonceName = "nonce.data"
+	logger    = logging.MustGetLogger("exchange.account")
 )
 
 type Accounter interface {
 	GetID() string                            // return the account id.
 	GetBalance(ct string) uint64              // return the account's Balance.
 	AddDepositAddress(ct string, addr string) // add the deposit address to the account.
-	DecreaseBalance(ct string, amt uint64) error
-	IncreaseBalance(ct string, amt uint64) error
+	DecreaseBalance(ct string, amt uint64, reason Reason) error
+	IncreaseBalance(ct string, amt uint64, reason Reason) error
 	SetBalance(cp string, amt uint64) error
+	GetReservedBalance(ct string) uint64                       // return the balance locked by open orders.
+	GetBalances() map[string]Balance                           // return the available and reserved balances of all coins.
+	ReserveBalance(ct string, amt uint64, reason Reason) error // move the balance to reserved balance.
+	ReleaseBalance(ct string, amt uint64, reason Reason) error // move the reserved balance back to balance.
+	DecreaseReservedBalance(ct string, amt uint64, reason Reason) error
+	GetLedger(ct string, start, end int64) []LedgerEntry // return the balance changes in the time range.
+	IsEmpty() bool                                       // return true if all the balances and reserved balances are zero.
+	HasDepositAddress(ct string, addr string) bool
+	CreditDeposit(ct string, id string, amt uint64) error            // credit the deposit of utxo id, each deposit is credited only once.
+	ReverseDeposit(ct string, id string, amt uint64) (uint64, error) // reverse the credited deposit of utxo id orphaned by reorg.
+	GetWithdrawal(key string) (WithdrawalRecord, bool)               // return the recent withdrawal of idempotency key.
+	GetWithdrawalByTxid(txid string) (WithdrawalRecord, bool)
+	AddWithdrawal(r WithdrawalRecord)
+	SetWhitelistEnabled(enabled bool) // restrict the withdrawals to the whitelisted addresses.
+	WhitelistEnabled() bool
+	AddWithdrawalAddress(ct string, addr string) error
+	RemoveWithdrawalAddress(ct string, addr string) error
+	ListWithdrawalAddresses() []WithdrawalAddress
+	CheckWithdrawalAddress(ct string, addr string, cooldown time.Duration) error // check if the withdrawal to the address is allowed.
+	EnrollTOTP(key []byte) (string, error)                                       // generate the totp secret encrypted by key.
+	ConfirmTOTP(key []byte, code string, skew int) error
+	TOTPEnabled() bool
+	VerifyTOTP(key []byte, code string, skew int) error // check the totp code of the withdrawal if it's enabled.
 }
 
+// Balance the available and reserved balance of a coin.
+type Balance struct {
+	Available uint64 `json:"available"`
+	Reserved  uint64 `json:"reserved"`
+}
+
+// ErrDepositCredited the deposit has already been credited.
+var ErrDepositCredited = errors.New("deposit already credited")
+
+// ErrDepositNotCredited the deposit to reverse has not been credited.
+var ErrDepositNotCredited = errors.New("deposit not credited")
+
 // ExchangeAccount maintains the account state
 type ExchangeAccount struct {
-	ID          string              // account id
-	Balance     map[string]uint64   // the Balance should not be accessed directly.
-	Addresses   map[string][]string // deposit addresses
-	addr_mtx    sync.Mutex
-	balance_mtx sync.RWMutex // mutex used to protect the Balance's concurrent read and write.
+	ID              string              // account id
+	Balance         map[string]uint64   // the Balance should not be accessed directly.
+	Reserved        map[string]uint64   // balance locked by open orders, not included in Balance.
+	Addresses       map[string][]string // deposit addresses
+	Deposits        map[string]bool     // credited deposits, key: coin type and utxo id joined with `:`.
+	Ledger          []LedgerEntry       // append-only balance changes.
+	Withdrawals     []WithdrawalRecord  // recent withdrawals.
+	Whitelist       bool                // withdrawals are restricted to WithdrawalAddrs.
+	WithdrawalAddrs []WithdrawalAddress // whitelisted withdrawal addresses.
+	TOTP            *TOTPSecret         // second factor of the withdrawals, nil if not enrolled.
+	addr_mtx        sync.Mutex
+	balance_mtx     sync.RWMutex // mutex used to protect the Balance's concurrent read and write.
+	withdrawal_mtx  sync.Mutex   // mutex used to protect the Withdrawals, the whitelist and the TOTP.
 }
 
 type exchgAcntJson struct {
-	ID        string              `json:"id"`
-	Balance   map[string]uint64   `json:"balance"`
-	Addresses map[string][]string `json:"addresses"`
+	ID              string              `json:"id"`
+	Balance         map[string]uint64   `json:"balance"`
+	Reserved        map[string]uint64   `json:"reserved"`
+	Addresses       map[string][]string `json:"addresses"`
+	Deposits        []string            `json:"deposits,omitempty"`
+	Ledger          []LedgerEntry       `json:"ledger,omitempty"`
+	Withdrawals     []WithdrawalRecord  `json:"withdrawals,omitempty"`
+	Whitelist       bool                `json:"whitelist,omitempty"`
+	WithdrawalAddrs []WithdrawalAddress `json:"withdrawal_addresses,omitempty"`
+	TOTP            *TOTPSecret         `json:"totp,omitempty"`
 }
 
 // InitDir init the account storage file path.
@@ -64,11 +117,13 @@ func newExchangeAccount(id string) ExchangeAccount {
 			"skycoin": 0,
 			"bitcoin": 0,
 		},
+		Reserved:  make(map[string]uint64),
 		Addresses: make(map[string][]string),
+		Deposits:  make(map[string]bool),
 	}
 }
 
-func (self ExchangeAccount) GetID() string {
+func (self *ExchangeAccount) GetID() string {
 	return self.ID
 }
 
@@ -79,28 +134,131 @@ func (self *ExchangeAccount) GetBalance(coinType string) uint64 {
 	return self.Balance[coinType]
 }
 
+// IsEmpty returns true if the account holds no balance and no reserved balance of any coin.
+func (self *ExchangeAccount) IsEmpty() bool {
+	self.balance_mtx.RLock()
+	defer self.balance_mtx.RUnlock()
+	for _, bals := range []map[string]uint64{self.Balance, self.Reserved} {
+		for _, v := range bals {
+			if v > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func (self *ExchangeAccount) AddDepositAddress(coinType string, addr string) {
 	self.addr_mtx.Lock()
 	self.Addresses[coinType] = append(self.Addresses[coinType], addr)
 	self.addr_mtx.Unlock()
 }
 
+// removeDepositAddress removes the deposit address from the account.
+func (self *ExchangeAccount) removeDepositAddress(coinType string, addr string) {
+	self.addr_mtx.Lock()
+	defer self.addr_mtx.Unlock()
+	addrs := self.Addresses[coinType]
+	for i, a := range addrs {
+		if a == addr {
+			self.Addresses[coinType] = append(addrs[:i:i], addrs[i+1:]...)
+			return
+		}
+	}
+}
+
+// HasDepositAddress checks if the address is the deposit address of the account.
+func (self *ExchangeAccount) HasDepositAddress(coinType string, addr string) bool {
+	self.addr_mtx.Lock()
+	defer self.addr_mtx.Unlock()
+	for _, a := range self.Addresses[coinType] {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// CreditDeposit increases the balance with the deposit of utxo id, returns
+// ErrDepositCredited if the deposit has already been credited. The balance of
+// the coin is created by its first credit.
+func (self *ExchangeAccount) CreditDeposit(ct string, id string, amt uint64) error {
+	self.balance_mtx.Lock()
+	defer self.balance_mtx.Unlock()
+	if err := self.ensureBalance(ct); err != nil {
+		return err
+	}
+
+	key := ct + ":" + id
+	if self.Deposits[key] {
+		return ErrDepositCredited
+	}
+
+	if self.Deposits == nil {
+		self.Deposits = make(map[string]bool)
+	}
+	self.Deposits[key] = true
+	self.Balance[ct] += amt
+	self.record(ct, int64(amt), ReasonDeposit)
+	return nil
+}
+
+// ReverseDeposit reverses the credited deposit of utxo id which is orphaned by a chain reorg,
+// the available balance is debited by amt at most, and the debited coins are returned, the
+// rest has been spent or reserved. The deposit can be credited again if it's mined later.
+// Returns ErrDepositNotCredited if the deposit has not been credited.
+func (self *ExchangeAccount) ReverseDeposit(ct string, id string, amt uint64) (uint64, error) {
+	self.balance_mtx.Lock()
+	defer self.balance_mtx.Unlock()
+	key := ct + ":" + id
+	if !self.Deposits[key] {
+		return 0, ErrDepositNotCredited
+	}
+
+	delete(self.Deposits, key)
+	debit := amt
+	if self.Balance[ct] < debit {
+		debit = self.Balance[ct]
+	}
+	self.Balance[ct] -= debit
+	self.record(ct, -int64(debit), ReasonDepositReversal)
+	return debit, nil
+}
+
+// ensureBalance creates the balance entry of coin type ct if it's missing, the accounts
+// only start with the skycoin and bitcoin entries, the other coins are added by their
+// first credit. The caller must hold the balance_mtx.
+func (self *ExchangeAccount) ensureBalance(ct string) error {
+	if ct == "" {
+		return errors.New("unknow coin type")
+	}
+	if self.Balance == nil {
+		self.Balance = make(map[string]uint64)
+	}
+	if _, ok := self.Balance[ct]; !ok {
+		self.Balance[ct] = 0
+	}
+	return nil
+}
+
 // SetBalance update the balanace of specific coin.
 func (self *ExchangeAccount) SetBalance(cp string, amt uint64) error {
 	self.balance_mtx.Lock()
 	defer self.balance_mtx.Unlock()
-	if _, ok := self.Balance[cp]; !ok {
-		return fmt.Errorf("the account does not have %s", cp)
+	if err := self.ensureBalance(cp); err != nil {
+		return err
 	}
+	delta := int64(amt) - int64(self.Balance[cp])
 	self.Balance[cp] = amt
+	self.record(cp, delta, ReasonAdmin)
 	return nil
 }
 
-func (self *ExchangeAccount) DecreaseBalance(ct string, amt uint64) error {
+func (self *ExchangeAccount) DecreaseBalance(ct string, amt uint64, reason Reason) error {
 	self.balance_mtx.Lock()
 	defer self.balance_mtx.Unlock()
-	if _, ok := self.Balance[ct]; !ok {
-		return errors.New("unknow coin type")
+	if err := self.ensureBalance(ct); err != nil {
+		return err
 	}
 	if self.Balance[ct] < amt {
 		logger.Debug("balance:%d require:%d", self.Balance[ct], amt)
@@ -108,24 +266,130 @@ func (self *ExchangeAccount) DecreaseBalance(ct string, amt uint64) error {
 	}
 
 	self.Balance[ct] -= amt
+	self.record(ct, -int64(amt), reason)
 	return nil
 }
 
-func (self *ExchangeAccount) IncreaseBalance(ct string, amt uint64) error {
+func (self *ExchangeAccount) IncreaseBalance(ct string, amt uint64, reason Reason) error {
 	self.balance_mtx.Lock()
 	defer self.balance_mtx.Unlock()
-	if _, ok := self.Balance[ct]; !ok {
-		return errors.New("unknow coin type")
+	if err := self.ensureBalance(ct); err != nil {
+		return err
 	}
 
 	self.Balance[ct] += amt
+	self.record(ct, int64(amt), reason)
 	return nil
 }
 
-func (self ExchangeAccount) ToMarshalable() exchgAcntJson {
+// transfer moves amt ct coins from the balance of account from to account to, the balance
+// locks are acquired in the order of account ids, so that the opposite transfers won't deadlock.
+func transfer(from, to *ExchangeAccount, ct string, amt uint64) error {
+	first, second := from, to
+	if to.ID < from.ID {
+		first, second = to, from
+	}
+	first.balance_mtx.Lock()
+	defer first.balance_mtx.Unlock()
+	second.balance_mtx.Lock()
+	defer second.balance_mtx.Unlock()
+
+	if err := from.ensureBalance(ct); err != nil {
+		return err
+	}
+	if err := to.ensureBalance(ct); err != nil {
+		return err
+	}
+	if from.Balance[ct] < amt {
+		logger.Debug("balance:%d require:%d", from.Balance[ct], amt)
+		return errors.New("account balance is not sufficient")
+	}
+
+	from.Balance[ct] -= amt
+	from.record(ct, -int64(amt), ReasonTransfer)
+	to.Balance[ct] += amt
+	to.record(ct, int64(amt), ReasonTransfer)
+	return nil
+}
+
+// GetReservedBalance returns the balance reserved by open orders.
+func (self *ExchangeAccount) GetReservedBalance(ct string) uint64 {
+	self.balance_mtx.RLock()
+	defer self.balance_mtx.RUnlock()
+	return self.Reserved[ct]
+}
+
+// GetBalances returns the available and reserved balances of all coins in the account.
+func (self *ExchangeAccount) GetBalances() map[string]Balance {
+	self.balance_mtx.RLock()
+	defer self.balance_mtx.RUnlock()
+	bals := make(map[string]Balance, len(self.Balance))
+	for ct, bal := range self.Balance {
+		bals[ct] = Balance{Available: bal, Reserved: self.Reserved[ct]}
+	}
+	return bals
+}
+
+// ReserveBalance moves amt from balance to reserved balance, returns error if
+// the balance is not sufficient, so that the coins can't be committed twice.
+func (self *ExchangeAccount) ReserveBalance(ct string, amt uint64, reason Reason) error {
+	self.balance_mtx.Lock()
+	defer self.balance_mtx.Unlock()
+	if err := self.ensureBalance(ct); err != nil {
+		return err
+	}
+	if self.Balance[ct] < amt {
+		logger.Debug("balance:%d require:%d", self.Balance[ct], amt)
+		return errors.New("account balance is not sufficient")
+	}
+
+	if self.Reserved == nil {
+		self.Reserved = make(map[string]uint64)
+	}
+	self.Balance[ct] -= amt
+	self.Reserved[ct] += amt
+	self.recordReserved(ct, -int64(amt), int64(amt), reason)
+	return nil
+}
+
+// ReleaseBalance moves amt from reserved balance back to balance.
+func (self *ExchangeAccount) ReleaseBalance(ct string, amt uint64, reason Reason) error {
+	self.balance_mtx.Lock()
+	defer self.balance_mtx.Unlock()
+	if err := self.ensureBalance(ct); err != nil {
+		return err
+	}
+	if self.Reserved[ct] < amt {
+		logger.Debug("reserved:%d release:%d", self.Reserved[ct], amt)
+		return errors.New("account reserved balance is not sufficient")
+	}
+
+	self.Reserved[ct] -= amt
+	self.Balance[ct] += amt
+	self.recordReserved(ct, int64(amt), -int64(amt), reason)
+	return nil
+}
+
+// DecreaseReservedBalance debits the reserved balance when the order is settled or the withdrawal
+// is sent, the spend is recorded in the ledger with the change of reserved balance.
+func (self *ExchangeAccount) DecreaseReservedBalance(ct string, amt uint64, reason Reason) error {
+	self.balance_mtx.Lock()
+	defer self.balance_mtx.Unlock()
+	if self.Reserved[ct] < amt {
+		logger.Debug("reserved:%d require:%d", self.Reserved[ct], amt)
+		return errors.New("account reserved balance is not sufficient")
+	}
+
+	self.Reserved[ct] -= amt
+	self.recordReserved(ct, 0, -int64(amt), reason)
+	return nil
+}
+
+func (self *ExchangeAccount) ToMarshalable() exchgAcntJson {
 	eaj := exchgAcntJson{
 		ID:        self.ID,
 		Balance:   make(map[string]uint64),
+		Reserved:  make(map[string]uint64),
 		Addresses: make(map[string][]string),
 	}
 
@@ -133,9 +397,27 @@ func (self ExchangeAccount) ToMarshalable() exchgAcntJson {
 		eaj.Balance[ct] = bal
 	}
 
+	for ct, bal := range self.Reserved {
+		eaj.Reserved[ct] = bal
+	}
+
 	for ct, addrs := range self.Addresses {
 		eaj.Addresses[ct] = append(eaj.Addresses[ct], addrs...)
 	}
+
+	for d := range self.Deposits {
+		eaj.Deposits = append(eaj.Deposits, d)
+	}
+	sort.Strings(eaj.Deposits)
+
+	eaj.Ledger = append(eaj.Ledger, self.Ledger...)
+	eaj.Withdrawals = append(eaj.Withdrawals, self.Withdrawals...)
+	eaj.Whitelist = self.Whitelist
+	eaj.WithdrawalAddrs = append(eaj.WithdrawalAddrs, self.WithdrawalAddrs...)
+	if self.TOTP != nil {
+		t := *self.TOTP
+		eaj.TOTP = &t
+	}
 	return eaj
 }
 
@@ -145,7 +427,9 @@ func (self exchgAcntJson) ToExchgAcnt() *ExchangeAccount {
 	at := ExchangeAccount{
 		ID:        self.ID,
 		Balance:   make(map[string]uint64),
+		Reserved:  make(map[string]uint64),
 		Addresses: make(map[string][]string),
+		Deposits:  make(map[string]bool),
 	}
 
 	// convert balance.
@@ -153,9 +437,26 @@ func (self exchgAcntJson) ToExchgAcnt() *ExchangeAccount {
 		at.Balance[ct] = bal
 	}
 
+	for ct, bal := range self.Reserved {
+		at.Reserved[ct] = bal
+	}
+
 	// convert address
 	for ct, addrs := range self.Addresses {
 		at.Addresses[ct] = append(at.Addresses[ct], addrs...)
 	}
+
+	for _, d := range self.Deposits {
+		at.Deposits[d] = true
+	}
+
+	at.Ledger = append(at.Ledger, self.Ledger...)
+	at.Withdrawals = append(at.Withdrawals, self.Withdrawals...)
+	at.Whitelist = self.Whitelist
+	at.WithdrawalAddrs = append(at.WithdrawalAddrs, self.WithdrawalAddrs...)
+	if self.TOTP != nil {
+		t := *self.TOTP
+		at.TOTP = &t
+	}
 	return &at
 }
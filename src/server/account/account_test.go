@@ -1,11 +1,16 @@
 package account_test
 
 import (
+	"bytes"
+	"errors"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
-	"github.com/skycoin/skycoin-exchange/src/coin"
 	"github.com/skycoin/skycoin-exchange/src/server/account"
 )
 
@@ -35,18 +40,18 @@ func TestGetID(t *testing.T) {
 
 func TestGetBalance(t *testing.T) {
 	a := account.ExchangeAccount{
-		Balance: map[coin.Type]uint64{
-			coin.Bitcoin: 90000,
-			coin.Skycoin: 450000,
+		Balance: map[string]uint64{
+			"bitcoin": 90000,
+			"skycoin": 450000,
 		},
 	}
 
-	if a.GetBalance(coin.Bitcoin) != 90000 {
+	if a.GetBalance("bitcoin") != 90000 {
 		t.Error("get bitcoin balance failed")
 		return
 	}
 
-	if a.GetBalance(coin.Skycoin) != 450000 {
+	if a.GetBalance("skycoin") != 450000 {
 		t.Error("get skycoin balance failed")
 		return
 	}
@@ -55,17 +60,17 @@ func TestGetBalance(t *testing.T) {
 func TestIncreaseBalance(t *testing.T) {
 	var btcInit uint64 = 90000
 	var skyInit uint64 = 450000
-	testData := map[coin.Type][]struct {
+	testData := map[string][]struct {
 		V      uint64
 		Expect uint64
 	}{
-		coin.Bitcoin: {
+		"bitcoin": {
 			{10000, 100000},
 			{20000, 110000},
 			{1000, 91000},
 			{100, 90100},
 		},
-		coin.Skycoin: {
+		"skycoin": {
 			{10000, 460000},
 			{30000, 480000},
 			{50000, 500000},
@@ -75,12 +80,12 @@ func TestIncreaseBalance(t *testing.T) {
 	for cp, tds := range testData {
 		for _, d := range tds {
 			a := account.ExchangeAccount{
-				Balance: map[coin.Type]uint64{
-					coin.Bitcoin: btcInit,
-					coin.Skycoin: skyInit,
+				Balance: map[string]uint64{
+					"bitcoin": btcInit,
+					"skycoin": skyInit,
 				},
 			}
-			if err := a.IncreaseBalance(cp, d.V); err != nil {
+			if err := a.IncreaseBalance(cp, d.V, account.ReasonAdmin); err != nil {
 				t.Error(err)
 				return
 			}
@@ -96,17 +101,17 @@ func TestIncreaseBalance(t *testing.T) {
 func TestDecreaseBalance(t *testing.T) {
 	var btcInit uint64 = 90000
 	var skyInit uint64 = 450000
-	testData := map[coin.Type][]struct {
+	testData := map[string][]struct {
 		V      uint64
 		Expect uint64
 	}{
-		coin.Bitcoin: {
+		"bitcoin": {
 			{10000, 80000},
 			{20000, 70000},
 			{1000, 89000},
 			{100, 89900},
 		},
-		coin.Skycoin: {
+		"skycoin": {
 			{10000, 440000},
 			{30000, 420000},
 			{50000, 400000},
@@ -116,12 +121,12 @@ func TestDecreaseBalance(t *testing.T) {
 	for cp, tds := range testData {
 		for _, d := range tds {
 			a := account.ExchangeAccount{
-				Balance: map[coin.Type]uint64{
-					coin.Bitcoin: btcInit,
-					coin.Skycoin: skyInit,
+				Balance: map[string]uint64{
+					"bitcoin": btcInit,
+					"skycoin": skyInit,
 				},
 			}
-			if err := a.DecreaseBalance(cp, d.V); err != nil {
+			if err := a.DecreaseBalance(cp, d.V, account.ReasonAdmin); err != nil {
 				t.Error(err)
 				return
 			}
@@ -134,3 +139,935 @@ func TestDecreaseBalance(t *testing.T) {
 	}
 
 }
+
+func TestReserveBalance(t *testing.T) {
+	a := account.ExchangeAccount{
+		Balance: map[string]uint64{
+			"bitcoin": 100,
+			"skycoin": 0,
+		},
+	}
+
+	// two asks over-commit the balance, the second one must be rejected.
+	if err := a.ReserveBalance("bitcoin", 60, account.ReasonOrder); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := a.ReserveBalance("bitcoin", 60, account.ReasonOrder); err == nil {
+		t.Error("over-commit balance should be rejected")
+		return
+	}
+
+	if a.GetBalance("bitcoin") != 40 || a.GetReservedBalance("bitcoin") != 60 {
+		t.Errorf("reserve balance failed, balance:%d, reserved:%d", a.GetBalance("bitcoin"), a.GetReservedBalance("bitcoin"))
+		return
+	}
+
+	// settle part of the order, and release the rest.
+	if err := a.DecreaseReservedBalance("bitcoin", 20, account.ReasonTrade); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := a.ReleaseBalance("bitcoin", 50, account.ReasonOrderCancel); err == nil {
+		t.Error("release more than reserved should fail")
+		return
+	}
+
+	if err := a.ReleaseBalance("bitcoin", 40, account.ReasonOrderCancel); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if a.GetBalance("bitcoin") != 80 || a.GetReservedBalance("bitcoin") != 0 {
+		t.Errorf("release balance failed, balance:%d, reserved:%d", a.GetBalance("bitcoin"), a.GetReservedBalance("bitcoin"))
+		return
+	}
+
+	if err := a.ReserveBalance("unknow", 1, account.ReasonOrder); err == nil {
+		t.Error("reserve unknow coin should fail")
+	}
+}
+
+func TestReserveBalanceConcurrent(t *testing.T) {
+	a := account.ExchangeAccount{
+		Balance: map[string]uint64{
+			"bitcoin": 100,
+		},
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- a.ReserveBalance("bitcoin", 60, account.ReasonOrder)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var failed int
+	for err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+
+	if failed != 1 {
+		t.Errorf("expect one of the asks rejected, got %d", failed)
+		return
+	}
+
+	if a.GetReservedBalance("bitcoin") != 60 || a.GetBalance("bitcoin") != 40 {
+		t.Errorf("reserved:%d, balance:%d", a.GetReservedBalance("bitcoin"), a.GetBalance("bitcoin"))
+	}
+}
+
+func TestLedger(t *testing.T) {
+	a := account.ExchangeAccount{
+		Balance: map[string]uint64{
+			"bitcoin": 0,
+			"skycoin": 0,
+		},
+	}
+
+	a.SetBalance("bitcoin", 100)
+	a.IncreaseBalance("skycoin", 10, account.ReasonAdmin)
+	a.ReserveBalance("bitcoin", 60, account.ReasonOrder)
+	a.ReleaseBalance("bitcoin", 20, account.ReasonOrderCancel)
+	a.IncreaseBalance("bitcoin", 5, account.ReasonTrade)
+	a.DecreaseBalance("bitcoin", 15, account.ReasonWithdraw)
+
+	// failed changes are not recorded.
+	a.DecreaseBalance("bitcoin", 1000, account.ReasonWithdraw)
+
+	expect := []account.LedgerEntry{
+		{CoinType: "bitcoin", Delta: 100, Balance: 100, Reason: account.ReasonAdmin},
+		{CoinType: "bitcoin", Delta: -60, Balance: 40, Reason: account.ReasonOrder},
+		{CoinType: "bitcoin", Delta: 20, Balance: 60, Reason: account.ReasonOrderCancel},
+		{CoinType: "bitcoin", Delta: 5, Balance: 65, Reason: account.ReasonTrade},
+		{CoinType: "bitcoin", Delta: -15, Balance: 50, Reason: account.ReasonWithdraw},
+	}
+
+	now := time.Now().Unix()
+	entries := a.GetLedger("bitcoin", 0, now)
+	if len(entries) != len(expect) {
+		t.Errorf("expect %d ledger entries, got %d", len(expect), len(entries))
+		return
+	}
+
+	var bal uint64
+	for i, e := range entries {
+		if e.CoinType != expect[i].CoinType || e.Delta != expect[i].Delta ||
+			e.Balance != expect[i].Balance || e.Reason != expect[i].Reason {
+			t.Errorf("entry %d: expect %+v, got %+v", i, expect[i], e)
+			return
+		}
+
+		// the running balance must match the sum of deltas.
+		bal = uint64(int64(bal) + e.Delta)
+		if e.Balance != bal {
+			t.Errorf("entry %d: running balance %d, recorded %d", i, bal, e.Balance)
+			return
+		}
+
+		if i > 0 && e.Time < entries[i-1].Time {
+			t.Errorf("entry %d is out of order", i)
+			return
+		}
+	}
+
+	if sky := a.GetLedger("skycoin", 0, now); len(sky) != 1 || sky[0].Balance != 10 {
+		t.Errorf("skycoin ledger: %+v", sky)
+		return
+	}
+
+	if entries := a.GetLedger("bitcoin", now+1, now+10); len(entries) != 0 {
+		t.Errorf("expect no entries out of time range, got %d", len(entries))
+		return
+	}
+
+	// the ledger is persisted with the account.
+	b := a.ToMarshalable().ToExchgAcnt()
+	if len(b.GetLedger("bitcoin", 0, now)) != len(expect) {
+		t.Error("ledger lost after marshal")
+	}
+}
+
+func TestLedgerReserved(t *testing.T) {
+	a := account.ExchangeAccount{
+		Balance: map[string]uint64{
+			"bitcoin": 100,
+		},
+	}
+
+	a.ReserveBalance("bitcoin", 60, account.ReasonWithdraw)
+	a.ReleaseBalance("bitcoin", 10, account.ReasonWithdrawRollback)
+	a.DecreaseReservedBalance("bitcoin", 50, account.ReasonWithdraw)
+
+	// the spend of reserved balance is recorded without changing the balance.
+	expect := []account.LedgerEntry{
+		{CoinType: "bitcoin", Delta: -60, Balance: 40, Reserved: 60, Reason: account.ReasonWithdraw},
+		{CoinType: "bitcoin", Delta: 10, Balance: 50, Reserved: -10, Reason: account.ReasonWithdrawRollback},
+		{CoinType: "bitcoin", Delta: 0, Balance: 50, Reserved: -50, Reason: account.ReasonWithdraw},
+	}
+	entries := a.GetLedger("bitcoin", 0, time.Now().Unix())
+	if len(entries) != len(expect) {
+		t.Fatalf("expect %d ledger entries, got %d", len(expect), len(entries))
+	}
+	for i, e := range entries {
+		e.Time = 0
+		if e != expect[i] {
+			t.Errorf("entry %d: expect %+v, got %+v", i, expect[i], e)
+		}
+	}
+}
+
+func TestLedgerCap(t *testing.T) {
+	max := account.MaxLedgerEntries
+	account.MaxLedgerEntries = 8
+	defer func() { account.MaxLedgerEntries = max }()
+
+	a := account.ExchangeAccount{
+		Balance: map[string]uint64{
+			"bitcoin": 0,
+		},
+	}
+	for i := 1; i <= 9; i++ {
+		a.IncreaseBalance("bitcoin", uint64(i), account.ReasonAdmin)
+	}
+
+	// the oldest quarter is dropped once the cap is exceeded.
+	entries := a.GetLedger("bitcoin", 0, time.Now().Unix())
+	if len(entries) != 6 || entries[0].Delta != 4 || entries[5].Delta != 9 {
+		t.Errorf("ledger after the cap: %+v", entries)
+	}
+}
+
+func TestCreditDeposit(t *testing.T) {
+	a := account.ExchangeAccount{
+		Balance: map[string]uint64{
+			"bitcoin": 0,
+		},
+	}
+
+	if err := a.CreditDeposit("bitcoin", "txid:0", 100); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the same deposit is credited only once.
+	if err := a.CreditDeposit("bitcoin", "txid:0", 100); err != account.ErrDepositCredited {
+		t.Errorf("expect ErrDepositCredited, got %v", err)
+		return
+	}
+
+	// the balance of the coin is created by its first credit.
+	if err := a.CreditDeposit("litecoin", "txid:1", 100); err != nil {
+		t.Error(err)
+		return
+	}
+	if a.GetBalance("litecoin") != 100 {
+		t.Errorf("expect litecoin balance 100, got %d", a.GetBalance("litecoin"))
+		return
+	}
+
+	if err := a.CreditDeposit("", "txid:2", 100); err == nil {
+		t.Error("expect error of unknow coin type")
+		return
+	}
+
+	if a.GetBalance("bitcoin") != 100 {
+		t.Errorf("expect balance 100, got %d", a.GetBalance("bitcoin"))
+		return
+	}
+
+	entries := a.GetLedger("bitcoin", 0, time.Now().Unix())
+	if len(entries) != 1 || entries[0].Reason != account.ReasonDeposit {
+		t.Errorf("deposit ledger: %+v", entries)
+		return
+	}
+
+	// the credited deposits are persisted with the account.
+	b := a.ToMarshalable().ToExchgAcnt()
+	if err := b.CreditDeposit("bitcoin", "txid:0", 100); err != account.ErrDepositCredited {
+		t.Errorf("credited deposit lost after marshal, got %v", err)
+	}
+}
+
+func TestReverseDeposit(t *testing.T) {
+	a := account.ExchangeAccount{
+		Balance: map[string]uint64{
+			"bitcoin": 0,
+		},
+	}
+
+	if _, err := a.ReverseDeposit("bitcoin", "txid:0", 100); err != account.ErrDepositNotCredited {
+		t.Errorf("expect ErrDepositNotCredited, got %v", err)
+		return
+	}
+
+	if err := a.CreditDeposit("bitcoin", "txid:0", 100); err != nil {
+		t.Error(err)
+		return
+	}
+
+	n, err := a.ReverseDeposit("bitcoin", "txid:0", 100)
+	if err != nil || n != 100 {
+		t.Errorf("reverse deposit: %d %v", n, err)
+		return
+	}
+
+	if a.GetBalance("bitcoin") != 0 {
+		t.Errorf("expect balance 0, got %d", a.GetBalance("bitcoin"))
+		return
+	}
+
+	entries := a.GetLedger("bitcoin", 0, time.Now().Unix())
+	if len(entries) != 2 || entries[1].Reason != account.ReasonDepositReversal || entries[1].Delta != -100 {
+		t.Errorf("deposit reversal ledger: %+v", entries)
+		return
+	}
+
+	// the deposit mined again is credited again, the spent part can't be debited.
+	if err := a.CreditDeposit("bitcoin", "txid:0", 100); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := a.DecreaseBalance("bitcoin", 70, account.ReasonWithdraw); err != nil {
+		t.Error(err)
+		return
+	}
+
+	n, err = a.ReverseDeposit("bitcoin", "txid:0", 100)
+	if err != nil || n != 30 {
+		t.Errorf("expect 30 debited, got %d %v", n, err)
+		return
+	}
+
+	if a.GetBalance("bitcoin") != 0 {
+		t.Errorf("expect balance 0, got %d", a.GetBalance("bitcoin"))
+	}
+}
+
+func TestWithdrawalRecords(t *testing.T) {
+	n := account.MaxWithdrawalKeys
+	account.MaxWithdrawalKeys = 2
+	defer func() { account.MaxWithdrawalKeys = n }()
+
+	a := account.ExchangeAccount{}
+	for _, key := range []string{"k1", "k2", "k3"} {
+		a.AddWithdrawal(account.WithdrawalRecord{Key: key, CoinType: "bitcoin", Txid: "tx" + key})
+	}
+
+	// only the recent records are kept.
+	if _, ok := a.GetWithdrawal("k1"); ok {
+		t.Error("the oldest withdrawal record is not dropped")
+		return
+	}
+
+	r, ok := a.GetWithdrawal("k3")
+	if !ok || r.Txid != "txk3" || r.Time == 0 {
+		t.Errorf("withdrawal record k3: %+v", r)
+		return
+	}
+
+	// the records are persisted with the account.
+	b := a.ToMarshalable().ToExchgAcnt()
+	if _, ok := b.GetWithdrawal("k2"); !ok {
+		t.Error("withdrawal record lost after marshal")
+	}
+
+	// the withdrawal without key is looked up by txid only.
+	a.AddWithdrawal(account.WithdrawalRecord{CoinType: "bitcoin", Txid: "tx4", Memo: "invoice 4"})
+	if _, ok := a.GetWithdrawal(""); ok {
+		t.Error("empty key matches the withdrawal record")
+		return
+	}
+
+	b = a.ToMarshalable().ToExchgAcnt()
+	r, ok = b.GetWithdrawalByTxid("tx4")
+	if !ok || r.Memo != "invoice 4" {
+		t.Errorf("withdrawal record tx4: %+v", r)
+	}
+}
+
+func TestWithdrawalWhitelist(t *testing.T) {
+	a := account.ExchangeAccount{}
+
+	// any address is allowed if the whitelist is disabled.
+	if err := a.CheckWithdrawalAddress("bitcoin", "addr1", time.Hour); err != nil {
+		t.Errorf("whitelist disabled: %v", err)
+		return
+	}
+
+	a.SetWhitelistEnabled(true)
+	if err := a.CheckWithdrawalAddress("bitcoin", "addr1", 0); err != account.ErrAddressNotWhitelisted {
+		t.Errorf("expect ErrAddressNotWhitelisted, got %v", err)
+		return
+	}
+
+	if err := a.AddWithdrawalAddress("bitcoin", "addr1"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := a.AddWithdrawalAddress("bitcoin", "addr1"); err == nil {
+		t.Error("duplicate whitelisted address is added")
+		return
+	}
+	if err := a.AddWithdrawalAddress("skycoin", "addr2"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the address is usable once the cooldown has passed.
+	if err := a.CheckWithdrawalAddress("bitcoin", "addr1", time.Hour); err != account.ErrAddressCoolingDown {
+		t.Errorf("expect ErrAddressCoolingDown, got %v", err)
+		return
+	}
+	if err := a.CheckWithdrawalAddress("bitcoin", "addr1", 0); err != nil {
+		t.Errorf("whitelisted address: %v", err)
+		return
+	}
+
+	// the address is whitelisted for one coin type.
+	if err := a.CheckWithdrawalAddress("skycoin", "addr1", 0); err != account.ErrAddressNotWhitelisted {
+		t.Errorf("expect ErrAddressNotWhitelisted, got %v", err)
+		return
+	}
+
+	// the whitelist is persisted with the account.
+	b := a.ToMarshalable().ToExchgAcnt()
+	if !b.WhitelistEnabled() || len(b.ListWithdrawalAddresses()) != 2 {
+		t.Errorf("whitelist lost after marshal: %v, %+v", b.WhitelistEnabled(), b.ListWithdrawalAddresses())
+		return
+	}
+
+	if err := a.RemoveWithdrawalAddress("bitcoin", "addr1"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := a.RemoveWithdrawalAddress("bitcoin", "addr1"); err != account.ErrAddressNotWhitelisted {
+		t.Errorf("expect ErrAddressNotWhitelisted, got %v", err)
+		return
+	}
+	addrs := a.ListWithdrawalAddresses()
+	if len(addrs) != 1 || addrs[0].CoinType != "skycoin" || addrs[0].Address != "addr2" || addrs[0].AddedAt == 0 {
+		t.Errorf("whitelisted addresses: %+v", addrs)
+		return
+	}
+	if err := a.CheckWithdrawalAddress("bitcoin", "addr1", 0); err != account.ErrAddressNotWhitelisted {
+		t.Errorf("expect ErrAddressNotWhitelisted, got %v", err)
+	}
+}
+
+func TestBindDepositAddress(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".skycoin-exchange-bind")
+	account.InitDir(dir)
+	defer os.RemoveAll(dir)
+
+	m := account.NewManager()
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.CreateAccountWithPubkey(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := m.BindDepositAddress("bitcoin", "addr1", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.BindDepositAddress("skycoin", "addr1", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	// binding again is allowed, but the address can't be taken by another account.
+	if err := m.BindDepositAddress("bitcoin", "addr1", "a"); err != nil {
+		t.Error(err)
+	}
+	if err := m.BindDepositAddress("bitcoin", "addr1", "b"); err == nil {
+		t.Error("expect error of binding the address of another account")
+	}
+	if err := m.BindDepositAddress("bitcoin", "addr2", "c"); err == nil {
+		t.Error("expect error of unknown account")
+	}
+
+	// the mapping is persisted.
+	lm, err := account.LoadManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []struct{ ct, addr, id string }{
+		{"bitcoin", "addr1", "a"},
+		{"skycoin", "addr1", "b"},
+	} {
+		a, err := lm.GetAccountByAddress(d.ct, d.addr)
+		if err != nil {
+			t.Errorf("%s address %s: %v", d.ct, d.addr, err)
+			continue
+		}
+		if a.GetID() != d.id {
+			t.Errorf("%s address %s belongs to %s, got %s", d.ct, d.addr, d.id, a.GetID())
+		}
+		if !a.HasDepositAddress(d.ct, d.addr) {
+			t.Errorf("%s address %s is not in account %s", d.ct, d.addr, d.id)
+		}
+	}
+	if _, err := lm.GetAccountByAddress("bitcoin", "addr2"); err == nil {
+		t.Error("expect error of unknown address")
+	}
+
+	// the mapping is removed with the account.
+	if err := lm.DeleteAccount("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lm.GetAccountByAddress("bitcoin", "addr1"); err == nil {
+		t.Error("expect error of the address of deleted account")
+	}
+
+	// the unbound address can be bound to another account.
+	if err := lm.UnbindDepositAddress("skycoin", "addr1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := lm.UnbindDepositAddress("skycoin", "addr1"); err == nil {
+		t.Error("expect error of unbound address")
+	}
+	b, _ := lm.GetAccount("b")
+	if b.HasDepositAddress("skycoin", "addr1") {
+		t.Error("unbound address is still in account b")
+	}
+	if _, err := lm.CreateAccountWithPubkey("c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := lm.BindDepositAddress("skycoin", "addr1", "c"); err != nil {
+		t.Error(err)
+	}
+
+	lm, err = account.LoadManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, err := lm.GetAccountByAddress("skycoin", "addr1"); err != nil || a.GetID() != "c" {
+		t.Errorf("owner of rebound address = %v, %v, want c", a, err)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".skycoin-exchange-transfer")
+	account.InitDir(dir)
+	defer os.RemoveAll(dir)
+
+	m := account.NewManager()
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.CreateAccountWithPubkey(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, _ := m.GetAccount("a")
+	b, _ := m.GetAccount("b")
+	a.IncreaseBalance("bitcoin", 100, account.ReasonAdmin)
+
+	if err := m.Transfer("a", "b", "bitcoin", 60); err != nil {
+		t.Fatal(err)
+	}
+	if a.GetBalance("bitcoin") != 40 || b.GetBalance("bitcoin") != 60 {
+		t.Errorf("balance a:%d, b:%d", a.GetBalance("bitcoin"), b.GetBalance("bitcoin"))
+	}
+
+	// both sides are recorded in the ledger.
+	now := time.Now().Unix()
+	ea := a.GetLedger("bitcoin", 0, now)
+	eb := b.GetLedger("bitcoin", 0, now)
+	if len(ea) != 2 || ea[1].Delta != -60 || ea[1].Balance != 40 || ea[1].Reason != account.ReasonTransfer {
+		t.Errorf("ledger of a: %+v", ea)
+	}
+	if len(eb) != 1 || eb[0].Delta != 60 || eb[0].Balance != 60 || eb[0].Reason != account.ReasonTransfer {
+		t.Errorf("ledger of b: %+v", eb)
+	}
+
+	for _, d := range []struct {
+		from, to, ct string
+		amt          uint64
+	}{
+		{"a", "b", "bitcoin", 41}, // insufficient balance.
+		{"a", "a", "bitcoin", 10}, // self transfer.
+		{"a", "c", "bitcoin", 10}, // unknown account.
+		{"c", "a", "bitcoin", 10},
+		{"a", "b", "unknown", 10}, // unknown coin.
+		{"a", "b", "bitcoin", 0},
+	} {
+		if err := m.Transfer(d.from, d.to, d.ct, d.amt); err == nil {
+			t.Errorf("expect error of transferring %d %s from %s to %s", d.amt, d.ct, d.from, d.to)
+		}
+	}
+	if a.GetBalance("bitcoin") != 40 || b.GetBalance("bitcoin") != 60 {
+		t.Errorf("balance changed by failed transfers, a:%d, b:%d", a.GetBalance("bitcoin"), b.GetBalance("bitcoin"))
+	}
+}
+
+// TestTransferConcurrent transfers among the accounts in both directions concurrently,
+// run it with -race.
+func TestTransferConcurrent(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".skycoin-exchange-transfer-concurrent")
+	account.InitDir(dir)
+	defer os.RemoveAll(dir)
+
+	m := account.NewManager()
+	ids := []string{"a", "b", "c"}
+	for _, id := range ids {
+		a, err := m.CreateAccountWithPubkey(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.IncreaseBalance("skycoin", 1000, account.ReasonAdmin)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			from, to := ids[i%3], ids[(i+1+i/3%2)%3]
+			for j := 0; j < 100; j++ {
+				// the transfers may fail of insufficient balance, but never break the total.
+				m.Transfer(from, to, "skycoin", uint64(j%7+1))
+				m.Transfer(to, from, "skycoin", uint64(j%5+1))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var total uint64
+	for _, id := range ids {
+		a, _ := m.GetAccount(id)
+		total += a.GetBalance("skycoin")
+
+		// the ledger is consistent with the balance.
+		var sum int64
+		for _, e := range a.GetLedger("skycoin", 0, time.Now().Unix()) {
+			sum += e.Delta
+		}
+		if uint64(sum) != a.GetBalance("skycoin") {
+			t.Errorf("account %s balance %d, ledger sum %d", id, a.GetBalance("skycoin"), sum)
+		}
+	}
+	if total != 3000 {
+		t.Errorf("total balance %d, expect 3000", total)
+	}
+}
+
+func TestGetBalances(t *testing.T) {
+	a := account.ExchangeAccount{
+		Balance: map[string]uint64{
+			"bitcoin": 100,
+			"skycoin": 50,
+		},
+	}
+	if err := a.ReserveBalance("bitcoin", 40, account.ReasonOrder); err != nil {
+		t.Fatal(err)
+	}
+
+	bals := a.GetBalances()
+	if len(bals) != 2 {
+		t.Fatalf("expect 2 balances, got %d", len(bals))
+	}
+	if bals["bitcoin"] != (account.Balance{Available: 60, Reserved: 40}) {
+		t.Errorf("bitcoin balance %+v", bals["bitcoin"])
+	}
+	if bals["skycoin"] != (account.Balance{Available: 50}) {
+		t.Errorf("skycoin balance %+v", bals["skycoin"])
+	}
+}
+
+func TestAccountNonce(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".skycoin-exchange-nonce")
+	account.InitDir(dir)
+	defer os.RemoveAll(dir)
+
+	m := account.NewManager()
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.CreateAccountWithPubkey(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := m.GetAccountNonce("a"); err != nil || n != 0 {
+		t.Errorf("nonce of new account = %d, %v, want 0", n, err)
+	}
+
+	// the replayed request reuses the nonce, gaps are allowed, but the nonce can't go back.
+	for _, d := range []struct {
+		id    string
+		nonce uint64
+		err   error
+	}{
+		{"a", 1, nil},
+		{"a", 1, account.ErrStaleNonce},
+		{"a", 10, nil},
+		{"a", 5, account.ErrStaleNonce},
+		{"a", 11, nil},
+		{"b", 1, nil}, // the nonces are per account.
+	} {
+		if err := m.UseAccountNonce(d.id, d.nonce); err != d.err {
+			t.Errorf("UseAccountNonce(%s, %d) = %v, want %v", d.id, d.nonce, err, d.err)
+		}
+	}
+
+	if err := m.UseAccountNonce("c", 1); err == nil {
+		t.Error("expect error of unknown account")
+	}
+	if _, err := m.GetAccountNonce("c"); err == nil {
+		t.Error("expect error of unknown account")
+	}
+
+	// the nonces are persisted, so the requests can't be replayed after restart.
+	lm, err := account.LoadManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := lm.GetAccountNonce("a"); err != nil || n != 11 {
+		t.Errorf("loaded nonce = %d, %v, want 11", n, err)
+	}
+	if err := lm.UseAccountNonce("a", 11); err != account.ErrStaleNonce {
+		t.Errorf("expect ErrStaleNonce, got %v", err)
+	}
+
+	// recording the nonce only writes the nonce file, the accounts are not saved.
+	d, err := ioutil.ReadFile(filepath.Join(dir, "account.data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(d), "nonces") {
+		t.Errorf("nonces are saved with the accounts: %s", d)
+	}
+
+	// the nonces saved with the accounts are still loaded without the nonce file.
+	if err := lm.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "nonce.data")); err != nil {
+		t.Fatal(err)
+	}
+	if lm, err = account.LoadManager(); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := lm.GetAccountNonce("a"); err != nil || n != 11 {
+		t.Errorf("loaded nonce = %d, %v, want 11", n, err)
+	}
+
+	// the nonce is removed with the account.
+	if err := lm.DeleteAccount("b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lm.CreateAccountWithPubkey("b"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := lm.GetAccountNonce("b"); err != nil || n != 0 {
+		t.Errorf("nonce of recreated account = %d, %v, want 0", n, err)
+	}
+}
+
+func TestFrozenAccount(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".skycoin-exchange-frozen")
+	account.InitDir(dir)
+	defer os.RemoveAll(dir)
+
+	m := account.NewManager()
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.CreateAccountWithPubkey(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, _ := m.GetAccount("a")
+	a.IncreaseBalance("bitcoin", 100, account.ReasonAdmin)
+
+	if err := m.SetFrozen("c", true); err == nil {
+		t.Error("expect error of unknown account")
+	}
+	if err := m.SetFrozen("a", true); err != nil {
+		t.Fatal(err)
+	}
+	if !m.IsFrozen("a") || m.IsFrozen("b") {
+		t.Errorf("frozen a:%v, b:%v, want true, false", m.IsFrozen("a"), m.IsFrozen("b"))
+	}
+
+	// the balance can't be transferred in or out.
+	if err := m.Transfer("a", "b", "bitcoin", 10); err != account.ErrAccountFrozen {
+		t.Errorf("expect ErrAccountFrozen, got %v", err)
+	}
+	if err := m.Transfer("b", "a", "bitcoin", 10); err != account.ErrAccountFrozen {
+		t.Errorf("expect ErrAccountFrozen, got %v", err)
+	}
+	if a.GetBalance("bitcoin") != 100 {
+		t.Errorf("balance of a = %d, want 100", a.GetBalance("bitcoin"))
+	}
+
+	// the flag is persisted.
+	lm, err := account.LoadManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lm.IsFrozen("a") || lm.IsFrozen("b") {
+		t.Errorf("loaded frozen a:%v, b:%v, want true, false", lm.IsFrozen("a"), lm.IsFrozen("b"))
+	}
+
+	if err := lm.SetFrozen("a", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := lm.Transfer("a", "b", "bitcoin", 10); err != nil {
+		t.Errorf("transfer of unfrozen account failed: %v", err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".skycoin-exchange-snapshot")
+	account.InitDir(dir)
+	defer os.RemoveAll(dir)
+
+	m := account.NewManager()
+	for _, id := range []string{"b", "a", "c"} {
+		if _, err := m.CreateAccountWithPubkey(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, _ := m.GetAccount("a")
+	a.IncreaseBalance("bitcoin", 100, account.ReasonAdmin)
+	a.ReserveBalance("bitcoin", 30, account.ReasonOrder)
+	a.AddWithdrawal(account.WithdrawalRecord{Key: "k1", Txid: "tx1", Time: 1})
+	if err := a.CreditDeposit("skycoin", "ux1", 7); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.BindDepositAddress("skycoin", "addr1", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.UseAccountNonce("b", 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetFrozen("c", true); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := m.ExportSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// the snapshot is restored into an empty manager.
+	rm := account.NewManager()
+	if err := rm.ImportSnapshot(bytes.NewReader(data), false); err != nil {
+		t.Fatal(err)
+	}
+
+	ra, err := rm.GetAccount("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra.GetBalance("bitcoin") != 70 || ra.GetReservedBalance("bitcoin") != 30 || ra.GetBalance("skycoin") != 7 {
+		t.Errorf("restored balances = %v", ra.GetBalances())
+	}
+	if len(ra.GetLedger("bitcoin", 0, 1<<62)) != 2 || len(ra.GetLedger("skycoin", 0, 1<<62)) != 1 {
+		t.Error("ledger is not restored")
+	}
+	if r, ok := ra.GetWithdrawal("k1"); !ok || r.Txid != "tx1" {
+		t.Errorf("restored withdrawal = %+v, %v", r, ok)
+	}
+	if err := ra.CreditDeposit("skycoin", "ux1", 7); err != account.ErrDepositCredited {
+		t.Errorf("expect ErrDepositCredited, got %v", err)
+	}
+	if o, err := rm.GetAccountByAddress("skycoin", "addr1"); err != nil || o.GetID() != "a" {
+		t.Errorf("owner of deposit address = %v, %v", o, err)
+	}
+	if n, _ := rm.GetAccountNonce("b"); n != 5 {
+		t.Errorf("restored nonce = %d, want 5", n)
+	}
+	if !rm.IsFrozen("c") || rm.IsFrozen("a") {
+		t.Error("frozen flags are not restored")
+	}
+
+	// the round trip gives the same snapshot.
+	var buf2 bytes.Buffer
+	if err := rm.ExportSnapshot(&buf2); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, buf2.Bytes()) {
+		t.Errorf("round trip snapshot differs:\n%s\n%s", data, buf2.Bytes())
+	}
+
+	// the imported accounts are saved.
+	lm, err := account.LoadManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if la, err := lm.GetAccount("a"); err != nil || la.GetBalance("bitcoin") != 70 {
+		t.Errorf("loaded account a = %v, %v", la, err)
+	}
+
+	// the non-empty manager is not overwritten unless forced.
+	om := account.NewManager()
+	if _, err := om.CreateAccountWithPubkey("d"); err != nil {
+		t.Fatal(err)
+	}
+	if err := om.ImportSnapshot(bytes.NewReader(data), false); err != account.ErrManagerNotEmpty {
+		t.Errorf("expect ErrManagerNotEmpty, got %v", err)
+	}
+	if _, err := om.GetAccount("a"); err == nil {
+		t.Error("account a is imported into non-empty manager")
+	}
+	if err := om.ImportSnapshot(bytes.NewReader(data), true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := om.GetAccount("d"); err == nil {
+		t.Error("account d is not replaced by the forced import")
+	}
+	if _, err := om.GetAccount("a"); err != nil {
+		t.Errorf("account a is not imported: %v", err)
+	}
+}
+
+func TestSnapshotCorrupted(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".skycoin-exchange-snapshot-corrupted")
+	account.InitDir(dir)
+	defer os.RemoveAll(dir)
+
+	m := account.NewManager()
+	if _, err := m.CreateAccountWithPubkey("a"); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := m.GetAccount("a")
+	a.IncreaseBalance("bitcoin", 100, account.ReasonAdmin)
+
+	var buf bytes.Buffer
+	if err := m.ExportSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+
+	for name, s := range map[string]string{
+		"balance changed":  strings.Replace(data, `"bitcoin":100`, `"bitcoin":900`, 1),
+		"checksum changed": strings.Replace(data, `"checksum":"`, `"checksum":"00`, 1),
+		"truncated":        data[:len(data)/2],
+		"not json":         "snapshot",
+	} {
+		if s == data {
+			t.Fatalf("%s: snapshot is not changed", name)
+		}
+		rm := account.NewManager()
+		if err := rm.ImportSnapshot(strings.NewReader(s), false); !errors.Is(err, account.ErrSnapshotCorrupted) {
+			t.Errorf("%s: expect ErrSnapshotCorrupted, got %v", name, err)
+		}
+		if _, err := rm.GetAccount("a"); err == nil {
+			t.Errorf("%s: corrupted snapshot is imported", name)
+		}
+	}
+
+	// unknown version.
+	rm := account.NewManager()
+	s := strings.Replace(data, `"version":1`, `"version":2`, 1)
+	if err := rm.ImportSnapshot(strings.NewReader(s), false); err == nil {
+		t.Error("expect error of unknown version")
+	}
+}
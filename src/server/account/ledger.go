@@ -0,0 +1,99 @@
+package account
+
+import "time"
+
+// MaxLedgerEntries max number of recent balance changes kept in the account, once exceeded,
+// the oldest quarter is dropped, so that the account file doesn't grow without bound.
+var MaxLedgerEntries = 10000
+
+// Reason the reason code of balance change.
+type Reason uint8
+
+const (
+	// ReasonAdmin balance is set by admin.
+	ReasonAdmin Reason = iota + 1
+	// ReasonOrder balance is locked by creating order.
+	ReasonOrder
+	// ReasonOrderCancel balance is given back as the order is canceled, closed or failed to create.
+	ReasonOrderCancel
+	// ReasonTrade balance is changed by the order settlement.
+	ReasonTrade
+	// ReasonTradeFee balance is credited with the trade fee.
+	ReasonTradeFee
+	// ReasonWithdraw balance is withdrawn.
+	ReasonWithdraw
+	// ReasonWithdrawRollback balance is given back as the withdrawal failed.
+	ReasonWithdrawRollback
+	// ReasonDeposit balance is credited with the confirmed deposit.
+	ReasonDeposit
+	// ReasonTransfer balance is transferred between accounts.
+	ReasonTransfer
+	// ReasonDepositReversal balance is debited as the credited deposit is orphaned by a chain reorg.
+	ReasonDepositReversal
+)
+
+var reasonStrings = map[Reason]string{
+	ReasonAdmin:            "admin",
+	ReasonOrder:            "order",
+	ReasonOrderCancel:      "order_cancel",
+	ReasonTrade:            "trade",
+	ReasonTradeFee:         "trade_fee",
+	ReasonWithdraw:         "withdraw",
+	ReasonWithdrawRollback: "withdraw_rollback",
+	ReasonDeposit:          "deposit",
+	ReasonTransfer:         "transfer",
+	ReasonDepositReversal:  "deposit_reversal",
+}
+
+func (r Reason) String() string {
+	if s, ok := reasonStrings[r]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// LedgerEntry records one change of the account balance.
+type LedgerEntry struct {
+	CoinType string `json:"coin_type"`
+	Delta    int64  `json:"delta"`                    // positive for increase, negative for decrease.
+	Balance  uint64 `json:"balance"`                  // balance after the change.
+	Reserved int64  `json:"reserved_delta,omitempty"` // change of the reserved balance.
+	Reason   Reason `json:"reason"`
+	Time     int64  `json:"time"` // unix time in seconds.
+}
+
+// record appends the balance change of ct to the ledger, must be called with balance_mtx held.
+func (self *ExchangeAccount) record(ct string, delta int64, reason Reason) {
+	self.recordReserved(ct, delta, 0, reason)
+}
+
+// recordReserved appends the change of balance and reserved balance of ct to the ledger,
+// the oldest entries are dropped if it exceeds MaxLedgerEntries, must be called with balance_mtx held.
+func (self *ExchangeAccount) recordReserved(ct string, delta, reserved int64, reason Reason) {
+	self.Ledger = append(self.Ledger, LedgerEntry{
+		CoinType: ct,
+		Delta:    delta,
+		Balance:  self.Balance[ct],
+		Reserved: reserved,
+		Reason:   reason,
+		Time:     time.Now().Unix(),
+	})
+	if len(self.Ledger) > MaxLedgerEntries {
+		n := len(self.Ledger) - MaxLedgerEntries + MaxLedgerEntries/4
+		self.Ledger = append([]LedgerEntry{}, self.Ledger[n:]...)
+	}
+}
+
+// GetLedger returns the balance changes of ct in the time range [start, end], in the order they happened,
+// the changes dropped for MaxLedgerEntries are not returned.
+func (self *ExchangeAccount) GetLedger(ct string, start, end int64) []LedgerEntry {
+	self.balance_mtx.RLock()
+	defer self.balance_mtx.RUnlock()
+	entries := []LedgerEntry{}
+	for _, e := range self.Ledger {
+		if e.CoinType == ct && e.Time >= start && e.Time <= end {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
@@ -3,34 +3,76 @@ package account
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 
-	"github.com/skycoin/skycoin/src/util"
+	"github.com/skycoin/skycoin-exchange/src/server/persist"
 )
 
 type Manager interface {
 	CreateAccountWithPubkey(pk string) (Accounter, error)
 	GetAccount(id string) (Accounter, error)
+	GetAccountByAddress(ct string, addr string) (Accounter, error) // return the account owning the deposit address.
+	BindDepositAddress(ct, addr, id string) error                  // bind the deposit address to the account, and save it.
+	UnbindDepositAddress(ct, addr string) error                    // remove the deposit address from its account, and save it.
+	DeleteAccount(id string) error
+	Transfer(fromID, toID, ct string, amt uint64) error // move the balance between accounts atomically.
+	GetAccountNonce(id string) (uint64, error)          // return the last nonce used by the account's signed requests.
+	UseAccountNonce(id string, nonce uint64) error      // record the nonce if it's greater than the last one, and save it.
+	SetFrozen(id string, frozen bool) error             // freeze or unfreeze the account, and save it.
+	IsFrozen(id string) bool                            // return true if the account is frozen.
+	ExportSnapshot(w io.Writer) error                   // write the backup of all accounts.
+	ImportSnapshot(r io.Reader, force bool) error       // restore the accounts from the backup, and save them.
 	Save() error
 }
 
+// ErrStaleNonce the nonce of the request is not greater than the last nonce of the account,
+// the request may be replayed.
+var ErrStaleNonce = errors.New("nonce must be greater than the last nonce of the account")
+
+// ErrAccountFrozen the account is frozen, it can't place orders, withdraw or transfer balance.
+var ErrAccountFrozen = errors.New("account is frozen")
+
 // AccountManager manage all the accounts in the server.
 type ExchangeAccountManager struct {
-	Accounts map[string]*ExchangeAccount `json:"accounts"`
-	mtx      sync.RWMutex
+	Accounts     map[string]*ExchangeAccount `json:"accounts"`
+	depositAddrs map[depositAddr]string      // the account id of deposit addresses.
+	nonces       map[string]uint64           // the last nonce of each account's signed requests.
+	frozen       map[string]bool             // the frozen accounts.
+	mtx          sync.RWMutex
+	nonceMtx     sync.Mutex // protects nonces, so that recording nonce only needs the read lock of mtx.
 }
 
 type exchgAcntMgrJson struct {
-	Accounts []exchgAcntJson `json:"accounts"`
+	Accounts     []exchgAcntJson   `json:"accounts"`
+	DepositAddrs []depositAddrJson `json:"deposit_addresses"`
+	Nonces       map[string]uint64 `json:"nonces,omitempty"`
+	Frozen       []string          `json:"frozen,omitempty"`
+}
+
+type depositAddr struct {
+	coinType string
+	address  string
+}
+
+type depositAddrJson struct {
+	CoinType  string `json:"coin_type"`
+	Address   string `json:"address"`
+	AccountID string `json:"account_id"`
 }
 
 // NewAccountManager
 func NewManager() Manager {
 	return &ExchangeAccountManager{
-		Accounts: make(map[string]*ExchangeAccount),
+		Accounts:     make(map[string]*ExchangeAccount),
+		depositAddrs: make(map[depositAddr]string),
+		nonces:       make(map[string]uint64),
+		frozen:       make(map[string]bool),
 		// AcntMgrFileName: fileName,
 	}
 }
@@ -50,9 +92,40 @@ func LoadManager() (Manager, error) {
 	if err := json.Unmarshal(d, &a); err != nil {
 		return nil, err
 	}
+
+	// the nonces saved with the accounts, including the ones saved before the nonce file is
+	// used, are replaced by the greater ones of the nonce file.
+	nonces, err := loadNonces()
+	if err != nil {
+		return nil, err
+	}
+	for id, n := range nonces {
+		if n > a.Nonces[id] {
+			if a.Nonces == nil {
+				a.Nonces = make(map[string]uint64)
+			}
+			a.Nonces[id] = n
+		}
+	}
 	return a.ToExchgAcntMgr(), nil
 }
 
+// loadNonces loads the nonces saved by saveNonces, returns empty map if the file doesn't exist.
+func loadNonces() (map[string]uint64, error) {
+	nonces := make(map[string]uint64)
+	d, err := ioutil.ReadFile(filepath.Join(acntDir, nonceName))
+	if os.IsNotExist(err) {
+		return nonces, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(d, &nonces); err != nil {
+		return nil, err
+	}
+	return nonces, nil
+}
+
 // CreateAccountWithPubkey create an accounter with specific pubkey, this pubkey is generated by client.
 func (self *ExchangeAccountManager) CreateAccountWithPubkey(pubkey string) (Accounter, error) {
 	self.mtx.Lock()
@@ -83,12 +156,199 @@ func (self *ExchangeAccountManager) GetAccount(id string) (Accounter, error) {
 	}
 }
 
-func (self ExchangeAccountManager) ToMarshalable() exchgAcntMgrJson {
+// GetAccountByAddress returns the account which the deposit address belongs to.
+func (self *ExchangeAccountManager) GetAccountByAddress(ct string, addr string) (Accounter, error) {
+	self.mtx.RLock()
+	defer self.mtx.RUnlock()
+	if id, ok := self.depositAddrs[depositAddr{ct, addr}]; ok {
+		if a, ok := self.Accounts[id]; ok {
+			return a, nil
+		}
+	}
+	return nil, errors.New("account does not exist")
+}
+
+// BindDepositAddress adds the deposit address to the account, and records the mapping of the
+// address to the account, so that the deposits to it can be credited. The accounts are saved
+// once bound, binding the address of another account is not allowed.
+func (self *ExchangeAccountManager) BindDepositAddress(ct, addr, id string) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	a, ok := self.Accounts[id]
+	if !ok {
+		return errors.New("account does not exist")
+	}
+
+	key := depositAddr{ct, addr}
+	if owner, ok := self.depositAddrs[key]; ok {
+		if owner == id {
+			return nil
+		}
+		return fmt.Errorf("%s address %s belongs to another account", ct, addr)
+	}
+
+	a.AddDepositAddress(ct, addr)
+	self.depositAddrs[key] = id
+	return self.save()
+}
+
+// UnbindDepositAddress removes the deposit address from the account owning it, the deposits
+// to the address are not credited to the account any more. The accounts are saved once unbound.
+func (self *ExchangeAccountManager) UnbindDepositAddress(ct, addr string) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	key := depositAddr{ct, addr}
+	id, ok := self.depositAddrs[key]
+	if !ok {
+		return fmt.Errorf("%s address %s is not bound", ct, addr)
+	}
+
+	if a, ok := self.Accounts[id]; ok {
+		a.removeDepositAddress(ct, addr)
+	}
+	delete(self.depositAddrs, key)
+	return self.save()
+}
+
+// DeleteAccount removes the account of specific id, and saves the accounts into disk.
+func (self *ExchangeAccountManager) DeleteAccount(id string) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if _, ok := self.Accounts[id]; !ok {
+		return errors.New("account does not exist")
+	}
+	delete(self.Accounts, id)
+	delete(self.frozen, id)
+	for key, owner := range self.depositAddrs {
+		if owner == id {
+			delete(self.depositAddrs, key)
+		}
+	}
+
+	self.nonceMtx.Lock()
+	delete(self.nonces, id)
+	err := self.saveNonces()
+	self.nonceMtx.Unlock()
+	if err != nil {
+		return err
+	}
+	return self.save()
+}
+
+// Transfer moves amt ct coins from the balance of account fromID to account toID, both
+// balances are changed at once, and the changes are recorded in the ledgers of the two accounts.
+func (self *ExchangeAccountManager) Transfer(fromID, toID, ct string, amt uint64) error {
+	if fromID == toID {
+		return errors.New("can't transfer to the same account")
+	}
+
+	if amt == 0 {
+		return errors.New("transfer amount must be greater than 0")
+	}
+
+	self.mtx.RLock()
+	from, ok := self.Accounts[fromID]
+	to, ok1 := self.Accounts[toID]
+	frozen := self.frozen[fromID] || self.frozen[toID]
+	self.mtx.RUnlock()
+	if !ok || !ok1 {
+		return errors.New("account does not exist")
+	}
+
+	if frozen {
+		return ErrAccountFrozen
+	}
+	return transfer(from, to, ct, amt)
+}
+
+// GetAccountNonce returns the last nonce used by the signed requests of the account, 0 if none is used.
+func (self *ExchangeAccountManager) GetAccountNonce(id string) (uint64, error) {
+	self.mtx.RLock()
+	defer self.mtx.RUnlock()
+	if _, ok := self.Accounts[id]; !ok {
+		return 0, errors.New("account does not exist")
+	}
+
+	self.nonceMtx.Lock()
+	defer self.nonceMtx.Unlock()
+	return self.nonces[id], nil
+}
+
+// UseAccountNonce records the nonce of the account's signed request, the nonce must be greater than
+// the last one, or ErrStaleNonce is returned, gaps are allowed. The nonce is saved at once, so that
+// the requests can't be replayed after restart, only the nonces are written, not the accounts.
+func (self *ExchangeAccountManager) UseAccountNonce(id string, nonce uint64) error {
+	self.mtx.RLock()
+	defer self.mtx.RUnlock()
+	if _, ok := self.Accounts[id]; !ok {
+		return errors.New("account does not exist")
+	}
+
+	self.nonceMtx.Lock()
+	defer self.nonceMtx.Unlock()
+	if nonce <= self.nonces[id] {
+		return ErrStaleNonce
+	}
+	self.nonces[id] = nonce
+	return self.saveNonces()
+}
+
+// SetFrozen freezes or unfreezes the account, the frozen account can't place orders, withdraw
+// or transfer balance in and out, its balances can still be queried. The flag is saved at once.
+func (self *ExchangeAccountManager) SetFrozen(id string, frozen bool) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if _, ok := self.Accounts[id]; !ok {
+		return errors.New("account does not exist")
+	}
+
+	if frozen {
+		self.frozen[id] = true
+	} else {
+		delete(self.frozen, id)
+	}
+	return self.save()
+}
+
+// IsFrozen returns true if the account is frozen.
+func (self *ExchangeAccountManager) IsFrozen(id string) bool {
+	self.mtx.RLock()
+	defer self.mtx.RUnlock()
+	return self.frozen[id]
+}
+
+func (self *ExchangeAccountManager) ToMarshalable() exchgAcntMgrJson {
 	amj := exchgAcntMgrJson{}
 
 	for _, acnt := range self.Accounts {
 		amj.Accounts = append(amj.Accounts, acnt.ToMarshalable())
 	}
+	sortAccounts(amj.Accounts)
+
+	for da, id := range self.depositAddrs {
+		amj.DepositAddrs = append(amj.DepositAddrs, depositAddrJson{da.coinType, da.address, id})
+	}
+	sort.Slice(amj.DepositAddrs, func(i, j int) bool {
+		a, b := amj.DepositAddrs[i], amj.DepositAddrs[j]
+		if a.CoinType != b.CoinType {
+			return a.CoinType < b.CoinType
+		}
+		return a.Address < b.Address
+	})
+
+	self.nonceMtx.Lock()
+	if len(self.nonces) > 0 {
+		amj.Nonces = make(map[string]uint64, len(self.nonces))
+		for id, n := range self.nonces {
+			amj.Nonces[id] = n
+		}
+	}
+	self.nonceMtx.Unlock()
+
+	for id := range self.frozen {
+		amj.Frozen = append(amj.Frozen, id)
+	}
+	sort.Strings(amj.Frozen)
 	return amj
 }
 
@@ -98,21 +358,56 @@ func (self *ExchangeAccountManager) Save() error {
 	return self.save()
 }
 
-// persistance to disc. Save as JSON
+// persistance to disc. Save as JSON, the nonces saved with the accounts may lag behind the
+// ones saved by saveNonces, the greater one is taken when loading.
 func (self *ExchangeAccountManager) save() error {
 	logger.Debug("save accounts")
 	a := self.ToMarshalable()
-	// for self.Accounts
-	return util.SaveJSON(filepath.Join(acntDir, acntName), a, 0600)
+	return persist.SaveJSON(filepath.Join(acntDir, acntName), a, 0600)
+}
+
+// saveNonces saves the nonces into their own file, nonceMtx must be held.
+func (self *ExchangeAccountManager) saveNonces() error {
+	return persist.SaveJSON(filepath.Join(acntDir, nonceName), self.nonces, 0600)
 }
 
 func (self exchgAcntMgrJson) ToExchgAcntMgr() *ExchangeAccountManager {
 	acntMap := make(map[string]*ExchangeAccount, len(self.Accounts))
+	depositAddrs := make(map[depositAddr]string)
 	for _, acnt := range self.Accounts {
 		at := acnt.ToExchgAcnt()
 		acntMap[at.ID] = at
+		// the accounts saved before the deposit addresses are recorded.
+		for ct, addrs := range at.Addresses {
+			for _, addr := range addrs {
+				depositAddrs[depositAddr{ct, addr}] = at.ID
+			}
+		}
+	}
+
+	for _, da := range self.DepositAddrs {
+		if _, ok := acntMap[da.AccountID]; ok {
+			depositAddrs[depositAddr{da.CoinType, da.Address}] = da.AccountID
+		}
+	}
+
+	nonces := make(map[string]uint64)
+	for id, n := range self.Nonces {
+		if _, ok := acntMap[id]; ok {
+			nonces[id] = n
+		}
+	}
+
+	frozen := make(map[string]bool)
+	for _, id := range self.Frozen {
+		if _, ok := acntMap[id]; ok {
+			frozen[id] = true
+		}
 	}
 	return &ExchangeAccountManager{
-		Accounts: acntMap,
+		Accounts:     acntMap,
+		depositAddrs: depositAddrs,
+		nonces:       nonces,
+		frozen:       frozen,
 	}
 }
@@ -0,0 +1,102 @@
+package account
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// SnapshotVersion the version of the account snapshot format written by ExportSnapshot.
+const SnapshotVersion = 1
+
+// ErrSnapshotCorrupted the checksum of the snapshot doesn't match its content.
+var ErrSnapshotCorrupted = errors.New("account snapshot is corrupted")
+
+// ErrManagerNotEmpty the manager holds accounts, the snapshot is not imported unless forced.
+var ErrManagerNotEmpty = errors.New("account manager is not empty")
+
+// snapshot the backup of all the accounts, the checksum is the hex encoded sha256 of the
+// raw accounts json, so that the content can be verified before it's decoded.
+type snapshot struct {
+	Version  int             `json:"version"`
+	Checksum string          `json:"checksum"`
+	Accounts json.RawMessage `json:"accounts"`
+}
+
+// ExportSnapshot writes all the accounts, including the balances, ledgers, withdrawals,
+// deposit addresses, nonces and frozen flags to w, the accounts are in the order of ids.
+func (self *ExchangeAccountManager) ExportSnapshot(w io.Writer) error {
+	self.mtx.RLock()
+	amj := self.ToMarshalable()
+	self.mtx.RUnlock()
+
+	d, err := json.Marshal(amj)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(d)
+	return json.NewEncoder(w).Encode(snapshot{
+		Version:  SnapshotVersion,
+		Checksum: hex.EncodeToString(sum[:]),
+		Accounts: d,
+	})
+}
+
+// ImportSnapshot restores the accounts from the snapshot written by ExportSnapshot, and saves
+// them to local disk. The snapshot is rejected if its version is unknown or its checksum doesn't
+// match. ErrManagerNotEmpty is returned if the manager holds any account and force is false,
+// otherwise all the accounts are replaced, the Accounter got before the import is stale.
+func (self *ExchangeAccountManager) ImportSnapshot(r io.Reader, force bool) error {
+	s := snapshot{}
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return fmt.Errorf("%w: %v", ErrSnapshotCorrupted, err)
+	}
+
+	if s.Version != SnapshotVersion {
+		return fmt.Errorf("unknown account snapshot version %d", s.Version)
+	}
+
+	sum := sha256.Sum256(s.Accounts)
+	if want, err := hex.DecodeString(s.Checksum); err != nil || !bytes.Equal(want, sum[:]) {
+		return ErrSnapshotCorrupted
+	}
+
+	amj := exchgAcntMgrJson{}
+	if err := json.Unmarshal(s.Accounts, &amj); err != nil {
+		return fmt.Errorf("%w: %v", ErrSnapshotCorrupted, err)
+	}
+	m := amj.ToExchgAcntMgr()
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if len(self.Accounts) > 0 && !force {
+		return ErrManagerNotEmpty
+	}
+
+	self.Accounts = m.Accounts
+	self.depositAddrs = m.depositAddrs
+	self.frozen = m.frozen
+	logger.Info("%d accounts imported from snapshot", len(self.Accounts))
+
+	self.nonceMtx.Lock()
+	self.nonces = m.nonces
+	err := self.saveNonces()
+	self.nonceMtx.Unlock()
+	if err != nil {
+		return err
+	}
+	return self.save()
+}
+
+// sortAccounts sorts the accounts by id.
+func sortAccounts(acnts []exchgAcntJson) {
+	sort.Slice(acnts, func(i, j int) bool {
+		return acnts[i].ID < acnts[j].ID
+	})
+}
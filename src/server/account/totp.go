@@ -0,0 +1,176 @@
+package account
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTPDigits number of digits of the totp code.
+	TOTPDigits = 6
+	// TOTPPeriod seconds of each time step of the totp code.
+	TOTPPeriod = 30
+	// totpSecretLen bytes of the generated totp secret.
+	totpSecretLen = 20
+)
+
+var (
+	// ErrTOTPEnrolled the totp of the account is already enrolled.
+	ErrTOTPEnrolled = errors.New("totp is already enrolled")
+	// ErrTOTPNotEnrolled the account has no pending totp enrollment.
+	ErrTOTPNotEnrolled = errors.New("totp is not enrolled")
+	// ErrTOTPRequired the totp code is required for the account that enrolled the totp.
+	ErrTOTPRequired = errors.New("totp code is required")
+	// ErrInvalidTOTP the totp code is wrong, expired or already used.
+	ErrInvalidTOTP = errors.New("invalid totp code")
+)
+
+// totpNow returns the current time of verifying the totp code, replaced in tests.
+var totpNow = time.Now
+
+// TOTPSecret the totp secret of the account, the secret is encrypted with AES-GCM by the key
+// of the server, the account id is the additional data, so it can't be moved to another account.
+type TOTPSecret struct {
+	Nonce     []byte `json:"nonce"`
+	Data      []byte `json:"data"`
+	Confirmed bool   `json:"confirmed"` // the code is required once the enrollment is confirmed.
+	LastStep  int64  `json:"last_step"` // time step of the last accepted code, which can't be used again.
+}
+
+// EnrollTOTP generates the totp secret of the account, and returns it in base32. The code is not
+// required until the enrollment is confirmed by ConfirmTOTP, the pending enrollment is replaced
+// by enrolling again, the confirmed one can't be replaced.
+func (self *ExchangeAccount) EnrollTOTP(key []byte) (string, error) {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	if self.TOTP != nil && self.TOTP.Confirmed {
+		return "", ErrTOTPEnrolled
+	}
+
+	secret := make([]byte, totpSecretLen)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return "", err
+	}
+
+	gcm, err := newTOTPGCM(key)
+	if err != nil {
+		return "", err
+	}
+	s := &TOTPSecret{Nonce: make([]byte, gcm.NonceSize())}
+	if _, err := io.ReadFull(rand.Reader, s.Nonce); err != nil {
+		return "", err
+	}
+	s.Data = gcm.Seal(nil, s.Nonce, secret, []byte(self.ID))
+	self.TOTP = s
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+// ConfirmTOTP confirms the pending totp enrollment with the code generated from the secret,
+// the withdrawals require the code since then.
+func (self *ExchangeAccount) ConfirmTOTP(key []byte, code string, skew int) error {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	if self.TOTP == nil {
+		return ErrTOTPNotEnrolled
+	}
+	if self.TOTP.Confirmed {
+		return ErrTOTPEnrolled
+	}
+	if err := self.verifyTOTP(key, code, skew); err != nil {
+		return err
+	}
+	self.TOTP.Confirmed = true
+	return nil
+}
+
+// TOTPEnabled returns true if the totp enrollment is confirmed.
+func (self *ExchangeAccount) TOTPEnabled() bool {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	return self.TOTP != nil && self.TOTP.Confirmed
+}
+
+// VerifyTOTP checks the code if the totp is enabled, the codes of skew time steps before and after
+// the current one are accepted for the clock drift, each code is accepted only once.
+func (self *ExchangeAccount) VerifyTOTP(key []byte, code string, skew int) error {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	if self.TOTP == nil || !self.TOTP.Confirmed {
+		return nil
+	}
+	if code == "" {
+		return ErrTOTPRequired
+	}
+	return self.verifyTOTP(key, code, skew)
+}
+
+// verifyTOTP checks the code and records its time step, withdrawal_mtx must be held.
+func (self *ExchangeAccount) verifyTOTP(key []byte, code string, skew int) error {
+	gcm, err := newTOTPGCM(key)
+	if err != nil {
+		return err
+	}
+	secret, err := gcm.Open(nil, self.TOTP.Nonce, self.TOTP.Data, []byte(self.ID))
+	if err != nil {
+		return fmt.Errorf("decrypt totp secret failed: %v", err)
+	}
+
+	step := totpNow().Unix() / TOTPPeriod
+	for i := -skew; i <= skew; i++ {
+		s := step + int64(i)
+		if s <= self.TOTP.LastStep {
+			continue
+		}
+		if hmac.Equal([]byte(totpCode(secret, s)), []byte(code)) {
+			self.TOTP.LastStep = s
+			return nil
+		}
+	}
+	return ErrInvalidTOTP
+}
+
+// TOTPCode returns the totp code of the base32 secret at time t.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	b, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	return totpCode(b, t.Unix()/TOTPPeriod), nil
+}
+
+// TOTPURI returns the otpauth uri of the secret, which is encoded in the QR code for the
+// authenticator apps.
+func TOTPURI(issuer, accountID, secret string) string {
+	return fmt.Sprintf("otpauth://totp/%s:%s?secret=%s&issuer=%s&algorithm=SHA1&digits=%d&period=%d",
+		issuer, accountID, secret, issuer, TOTPDigits, TOTPPeriod)
+}
+
+// totpCode returns the code of the time step, as RFC 6238 with HMAC-SHA1.
+func totpCode(secret []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	off := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[off:off+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", v%1000000)
+}
+
+func newTOTPGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
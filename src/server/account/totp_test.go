@@ -0,0 +1,166 @@
+package account
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// the test vectors of RFC 6238 with the SHA1 secret, truncated to 6 digits.
+	secret := []byte("12345678901234567890")
+	cases := []struct {
+		tm   int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, c := range cases {
+		if code := totpCode(secret, c.tm/TOTPPeriod); code != c.code {
+			t.Errorf("time %d: code %s, want %s", c.tm, code, c.code)
+		}
+	}
+
+	code, err := TOTPCode("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", time.Unix(59, 0))
+	if err != nil || code != "287082" {
+		t.Errorf("base32 secret: code %s, err %v", code, err)
+	}
+	if _, err := TOTPCode("not base32!", time.Unix(59, 0)); err == nil {
+		t.Error("invalid secret is accepted")
+	}
+}
+
+func TestTOTPEnroll(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	totpNow = func() time.Time { return now }
+	defer func() { totpNow = time.Now }()
+
+	key := sha256.Sum256([]byte("server seckey"))
+	a := &ExchangeAccount{ID: "a"}
+
+	// nothing is required before the enrollment is confirmed.
+	if err := a.ConfirmTOTP(key[:], "000000", 1); err != ErrTOTPNotEnrolled {
+		t.Fatalf("confirm before enroll: %v", err)
+	}
+	secret, err := a.EnrollTOTP(key[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.TOTPEnabled() {
+		t.Fatal("totp is enabled before confirmed")
+	}
+	if err := a.VerifyTOTP(key[:], "", 1); err != nil {
+		t.Fatalf("pending totp: %v", err)
+	}
+
+	// the secret is only stored encrypted.
+	d, err := json.Marshal(a.ToMarshalable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(d), secret) {
+		t.Fatal("the secret is stored in plaintext")
+	}
+	code, _ := TOTPCode(secret, now)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	if err := a.ConfirmTOTP(key[:], wrong, 1); err != ErrInvalidTOTP {
+		t.Fatalf("confirm with wrong code: %v", err)
+	}
+	other := sha256.Sum256([]byte("other seckey"))
+	if err := a.ConfirmTOTP(other[:], code, 1); err == nil {
+		t.Fatal("the secret is decrypted by another key")
+	}
+	if err := a.ConfirmTOTP(key[:], code, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !a.TOTPEnabled() {
+		t.Fatal("totp is not enabled after confirmed")
+	}
+	if _, err := a.EnrollTOTP(key[:]); err != ErrTOTPEnrolled {
+		t.Fatalf("enroll again: %v", err)
+	}
+
+	// the enabled totp survives the reloading.
+	b, err := json.Marshal(a.ToMarshalable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var eaj exchgAcntJson
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&eaj); err != nil {
+		t.Fatal(err)
+	}
+	if !eaj.ToExchgAcnt().TOTPEnabled() {
+		t.Fatal("totp is not loaded")
+	}
+}
+
+func TestTOTPVerify(t *testing.T) {
+	// the start of a time step.
+	now := time.Unix(1500000000-1500000000%TOTPPeriod, 0)
+	totpNow = func() time.Time { return now }
+	defer func() { totpNow = time.Now }()
+
+	key := sha256.Sum256([]byte("server seckey"))
+	a := &ExchangeAccount{ID: "a"}
+	secret, err := a.EnrollTOTP(key[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(d time.Duration) string {
+		code, err := TOTPCode(secret, now.Add(d))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+	if err := a.ConfirmTOTP(key[:], at(-2*TOTPPeriod*time.Second), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.VerifyTOTP(key[:], "", 1); err != ErrTOTPRequired {
+		t.Fatalf("empty code: %v", err)
+	}
+
+	// the codes out of the skew window are rejected, the first and the last second of the window
+	// are accepted.
+	cases := []struct {
+		d   time.Duration
+		err error
+	}{
+		{-TOTPPeriod*time.Second - time.Second, ErrInvalidTOTP},
+		{2 * TOTPPeriod * time.Second, ErrInvalidTOTP},
+		{-TOTPPeriod * time.Second, nil},
+		{TOTPPeriod*time.Second - time.Second, nil},
+		{2*TOTPPeriod*time.Second - time.Second, nil},
+	}
+	for _, c := range cases {
+		if err := a.VerifyTOTP(key[:], at(c.d), 1); err != c.err {
+			t.Errorf("code at %v: %v, want %v", c.d, err, c.err)
+		}
+	}
+
+	// each code is accepted once, and the older codes can't be used after a newer one.
+	if err := a.VerifyTOTP(key[:], at(2*TOTPPeriod*time.Second-time.Second), 1); err != ErrInvalidTOTP {
+		t.Errorf("reused code: %v", err)
+	}
+	if err := a.VerifyTOTP(key[:], at(0), 1); err != ErrInvalidTOTP {
+		t.Errorf("older code: %v", err)
+	}
+
+	// the window moves with the clock.
+	now = now.Add(3 * TOTPPeriod * time.Second)
+	if err := a.VerifyTOTP(key[:], at(0), 1); err != nil {
+		t.Errorf("code of the next window: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxWithdrawalKeys max number of recent withdrawals kept in the account for
+// replying the retried requests and looking up the memos, the oldest one is
+// dropped once exceeded.
+var MaxWithdrawalKeys = 100
+
+// MaxMemoLen max bytes of the withdrawal memo.
+const MaxMemoLen = 256
+
+// ErrAddressNotWhitelisted the withdrawal address is not in the whitelist of the account.
+var ErrAddressNotWhitelisted = errors.New("withdrawal address is not whitelisted")
+
+// ErrAddressCoolingDown the whitelisted address can't be used until its cooldown ends.
+var ErrAddressCoolingDown = errors.New("withdrawal address is in cooldown")
+
+// WithdrawalAddress the address the account can withdraw to when its whitelist is enabled.
+type WithdrawalAddress struct {
+	CoinType string `json:"coin_type"`
+	Address  string `json:"address"`
+	AddedAt  int64  `json:"added_at"` // unix time in seconds.
+}
+
+// WithdrawalRequest the withdrawal requested by the account.
+type WithdrawalRequest struct {
+	AccountID string
+	CoinType  string
+	Address   string
+	Amount    uint64
+	Key       string   // idempotency key, empty if not set.
+	FeeRate   uint64   // satoshis per vbyte, only used by bitcoin, 0 for the default rate.
+	Memo      string   // reference for reconciling, it's not in the transaction.
+	Utxos     []string // utxos of the server wallet in txid:vout funding the transaction, only used by bitcoin.
+	TOTPCode  string   // required if the totp of the account is enabled.
+}
+
+// WithdrawalRecord the outcome of the withdrawal.
+type WithdrawalRecord struct {
+	Key      string `json:"key,omitempty"` // idempotency key, empty if not set.
+	CoinType string `json:"coin_type"`
+	Address  string `json:"address"`
+	Amount   uint64 `json:"amount"`
+	Txid     string `json:"txid"`
+	Memo     string `json:"memo,omitempty"` // reference for reconciling, it's not in the transaction.
+	Time     int64  `json:"time"`           // unix time in seconds.
+}
+
+// GetWithdrawal returns the recent withdrawal made with the idempotency key.
+func (self *ExchangeAccount) GetWithdrawal(key string) (WithdrawalRecord, bool) {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	for _, r := range self.Withdrawals {
+		if key != "" && r.Key == key {
+			return r, true
+		}
+	}
+	return WithdrawalRecord{}, false
+}
+
+// GetWithdrawalByTxid returns the recent withdrawal of the transaction.
+func (self *ExchangeAccount) GetWithdrawalByTxid(txid string) (WithdrawalRecord, bool) {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	for _, r := range self.Withdrawals {
+		if r.Txid == txid {
+			return r, true
+		}
+	}
+	return WithdrawalRecord{}, false
+}
+
+// AddWithdrawal records the withdrawal, only the recent MaxWithdrawalKeys withdrawals are kept.
+func (self *ExchangeAccount) AddWithdrawal(r WithdrawalRecord) {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	if r.Time == 0 {
+		r.Time = time.Now().Unix()
+	}
+	self.Withdrawals = append(self.Withdrawals, r)
+	if n := len(self.Withdrawals) - MaxWithdrawalKeys; n > 0 {
+		self.Withdrawals = append([]WithdrawalRecord{}, self.Withdrawals[n:]...)
+	}
+}
+
+// SetWhitelistEnabled enables or disables the withdrawal whitelist, once it's enabled, the
+// account can only withdraw to the addresses added by AddWithdrawalAddress.
+func (self *ExchangeAccount) SetWhitelistEnabled(enabled bool) {
+	self.withdrawal_mtx.Lock()
+	self.Whitelist = enabled
+	self.withdrawal_mtx.Unlock()
+}
+
+// WhitelistEnabled returns true if the withdrawal whitelist is enabled.
+func (self *ExchangeAccount) WhitelistEnabled() bool {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	return self.Whitelist
+}
+
+// AddWithdrawalAddress adds the address of coin type ct to the withdrawal whitelist,
+// the time it's added is recorded for the cooldown.
+func (self *ExchangeAccount) AddWithdrawalAddress(ct string, addr string) error {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	for _, a := range self.WithdrawalAddrs {
+		if a.CoinType == ct && a.Address == addr {
+			return fmt.Errorf("%s address %s is already whitelisted", ct, addr)
+		}
+	}
+	self.WithdrawalAddrs = append(self.WithdrawalAddrs, WithdrawalAddress{
+		CoinType: ct,
+		Address:  addr,
+		AddedAt:  time.Now().Unix(),
+	})
+	return nil
+}
+
+// RemoveWithdrawalAddress removes the address of coin type ct from the withdrawal whitelist.
+func (self *ExchangeAccount) RemoveWithdrawalAddress(ct string, addr string) error {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	for i, a := range self.WithdrawalAddrs {
+		if a.CoinType == ct && a.Address == addr {
+			self.WithdrawalAddrs = append(self.WithdrawalAddrs[:i:i], self.WithdrawalAddrs[i+1:]...)
+			return nil
+		}
+	}
+	return ErrAddressNotWhitelisted
+}
+
+// ListWithdrawalAddresses returns the whitelisted addresses in the order of adding.
+func (self *ExchangeAccount) ListWithdrawalAddresses() []WithdrawalAddress {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	return append([]WithdrawalAddress{}, self.WithdrawalAddrs...)
+}
+
+// CheckWithdrawalAddress checks if the account can withdraw to the address of coin type ct,
+// any address is allowed if the whitelist is disabled. The whitelisted address can be used
+// once cooldown has passed since it's added.
+func (self *ExchangeAccount) CheckWithdrawalAddress(ct string, addr string, cooldown time.Duration) error {
+	self.withdrawal_mtx.Lock()
+	defer self.withdrawal_mtx.Unlock()
+	if !self.Whitelist {
+		return nil
+	}
+
+	for _, a := range self.WithdrawalAddrs {
+		if a.CoinType != ct || a.Address != addr {
+			continue
+		}
+		if time.Now().Before(time.Unix(a.AddedAt, 0).Add(cooldown)) {
+			return ErrAddressCoolingDown
+		}
+		return nil
+	}
+	return ErrAddressNotWhitelisted
+}
@@ -46,3 +46,113 @@ func CreateAccount(ee engine.Exchange) sknet.HandlerFunc {
 		return c.Error(errRlt)
 	}
 }
+
+// GetAccountNonce returns the nonce of the account's last signed request, the next
+// signed request must carry a greater one.
+func GetAccountNonce(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetAccountNonceReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			nonce, err := ee.GetAccountNonce(pubkey)
+			if err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_NotExits)
+				break
+			}
+
+			res := pp.GetAccountNonceRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+				Pubkey: pp.PtrString(pubkey),
+				Nonce:  pp.PtrUint64(nonce),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// EnrollTOTP generates the totp secret of the account, the secret and its otpauth uri are returned.
+func EnrollTOTP(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.EnrollTOTPReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			secret, uri, err := ee.EnrollTOTP(pubkey)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.EnrollTOTPRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+				Pubkey: pp.PtrString(pubkey),
+				Secret: pp.PtrString(secret),
+				Uri:    pp.PtrString(uri),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// ConfirmTOTP confirms the totp enrollment of the account, the withdrawals require the code since then.
+func ConfirmTOTP(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.ConfirmTOTPReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			if err := ee.ConfirmTOTP(pubkey, req.GetCode()); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.ConfirmTOTPRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+				Pubkey: pp.PtrString(pubkey),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
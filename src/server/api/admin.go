@@ -73,3 +73,264 @@ func UpdateCredit(ee engine.Exchange) sknet.HandlerFunc {
 		return c.Error(rlt)
 	}
 }
+
+// AdminCreateAccount creates account of the dst pubkey, must be called by admin.
+func AdminCreateAccount(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.AdminCreateAccountReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			// validate the dst pubkey.
+			dstPubkey := req.GetDst()
+			if err := validatePubkey(dstPubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			id, err := ee.CreateAccount(dstPubkey)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.AdminCreateAccountRes{
+				Result:    pp.MakeResultWithCode(pp.ErrCode_Success),
+				AccountId: pp.PtrString(id),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// AdminDeleteAccount deletes the account, must be called by admin, the account
+// with balance or open orders can't be deleted.
+func AdminDeleteAccount(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.AdminDeleteAccountReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			if err := ee.DeleteAccount(req.GetAccountId()); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.AdminDeleteAccountRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// AdminFreezeAccount freezes the account, must be called by admin, the open orders
+// of the account are cancelled if cancel_orders is true.
+func AdminFreezeAccount(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.AdminFreezeAccountReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			if err := ee.FreezeAccount(req.GetAccountId(), req.GetCancelOrders()); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.AdminFreezeAccountRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// AdminUnfreezeAccount unfreezes the account, must be called by admin.
+func AdminUnfreezeAccount(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.AdminUnfreezeAccountReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			if err := ee.UnfreezeAccount(req.GetAccountId()); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.AdminUnfreezeAccountRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// AdminAddCoinPair adds the order book of new coin pair, must be called by admin.
+func AdminAddCoinPair(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.AdminAddCoinPairReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			if err := ee.AddCoinPair(req.GetCoinPair()); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.AdminAddCoinPairRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// AdminHaltPair halts the trading of the coin pair, must be called by admin.
+func AdminHaltPair(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.AdminHaltPairReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			if err := ee.HaltPair(req.GetCoinPair()); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.AdminHaltPairRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// AdminResumePair resumes the trading of the halted coin pair, must be called by admin.
+func AdminResumePair(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.AdminResumePairReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			if err := ee.ResumePair(req.GetCoinPair()); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.AdminResumePairRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// AdminSetPriceBand sets the price band of the coin pair, must be called by admin.
+func AdminSetPriceBand(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.AdminSetPriceBandReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			if err := ee.SetPriceBand(req.GetCoinPair(), req.GetPriceBand()); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.AdminSetPriceBandRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// AdminGetUtxoStats gets the state of the utxo pool of specific coin type, must be called by admin.
+func AdminGetUtxoStats(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.AdminGetUtxoStatsReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			stats, err := ee.GetUtxoStats(req.GetCoinType())
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.AdminGetUtxoStatsRes{
+				Result:     pp.MakeResultWithCode(pp.ErrCode_Success),
+				CoinType:   req.CoinType,
+				Available:  pp.PtrInt64(int64(stats.Available)),
+				Borrowed:   pp.PtrInt64(int64(stats.Borrowed)),
+				TotalValue: pp.PtrUint64(stats.TotalValue),
+				LastRefill: pp.PtrInt64(stats.LastRefill),
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
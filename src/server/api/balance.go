@@ -1,8 +1,11 @@
 package api
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
+	cn "github.com/skycoin/skycoin-exchange/src/coin"
 	"github.com/skycoin/skycoin-exchange/src/pp"
 	"github.com/skycoin/skycoin-exchange/src/server/engine"
 	"github.com/skycoin/skycoin-exchange/src/sknet"
@@ -45,6 +48,56 @@ func GetAccountBalance(ee engine.Exchange) sknet.HandlerFunc {
 	}
 }
 
+// GetAccountBalances returns the available and reserved balances of all coins in the account,
+// sorted by coin type.
+func GetAccountBalances(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetAccountBalancesReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			bals, err := ee.GetAccountBalances(pubkey)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_NotExits)
+				break
+			}
+
+			cts := make([]string, 0, len(bals))
+			for ct := range bals {
+				cts = append(cts, ct)
+			}
+			sort.Strings(cts)
+
+			res := pp.GetAccountBalancesRes{
+				Result:   pp.MakeResultWithCode(pp.ErrCode_Success),
+				Balances: make([]*pp.CoinBalance, len(cts)),
+			}
+			for i, ct := range cts {
+				res.Balances[i] = &pp.CoinBalance{
+					CoinType:  pp.PtrString(ct),
+					Available: pp.PtrUint64(bals[ct].Available),
+					Reserved:  pp.PtrUint64(bals[ct].Reserved),
+				}
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
 // GetAddrBalance get balance of specific address.
 func GetAddrBalance(ee engine.Exchange) sknet.HandlerFunc {
 	return func(c *sknet.Context) error {
@@ -81,3 +134,48 @@ func GetAddrBalance(ee engine.Exchange) sknet.HandlerFunc {
 		return c.Error(rlt)
 	}
 }
+
+// GetAddrDetailedBalance get the confirmed and unconfirmed balance of specific addresses.
+func GetAddrDetailedBalance(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.GetAddrDetailedBalanceReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			coin, err := ee.GetCoin(req.GetCoinType())
+			if err != nil {
+				rlt = pp.MakeErrRes(err)
+				logger.Error(err.Error())
+				break
+			}
+
+			db, ok := coin.(cn.DetailedBalancer)
+			if !ok {
+				rlt = pp.MakeErrRes(fmt.Errorf("detailed balance of %s is not supported", req.GetCoinType()))
+				break
+			}
+
+			addrs := strings.Split(req.GetAddrs(), ",")
+			b, err := db.GetDetailedBalance(addrs)
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+			res := pp.GetAddrDetailedBalanceRes{
+				Result:      pp.MakeResultWithCode(pp.ErrCode_Success),
+				Confirmed:   pp.PtrUint64(b.Confirmed),
+				Unconfirmed: pp.PtrUint64(b.Unconfirmed),
+				Total:       pp.PtrUint64(b.Total),
+			}
+
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
@@ -16,3 +16,48 @@ func GetCoins(egn engine.Exchange) sknet.HandlerFunc {
 		return c.SendJSON(&coins)
 	}
 }
+
+// GetCoinsInfo get the metadata of supported coins.
+func GetCoinsInfo(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		infos := egn.GetSupportedCoinsInfo()
+		res := pp.CoinsInfoRes{
+			Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+			Coins:  make([]*pp.CoinInfo, len(infos)),
+		}
+		for i, info := range infos {
+			res.Coins[i] = &pp.CoinInfo{
+				Symbol:        pp.PtrString(info.Symbol),
+				Name:          pp.PtrString(info.Name),
+				Type:          pp.PtrString(info.Type),
+				Decimals:      pp.PtrInt32(int32(info.Decimals)),
+				MinAmount:     pp.PtrUint64(info.MinAmount),
+				AddressFormat: pp.PtrString(info.AddressFormat),
+			}
+		}
+		return c.SendJSON(&res)
+	}
+}
+
+// Health get the health status of the coins' nodes, healthy is true only if all nodes are healthy.
+func Health(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		hs := egn.HealthCheck()
+		res := pp.HealthRes{
+			Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
+			Healthy: pp.PtrBool(true),
+			Coins:   make([]*pp.CoinHealth, len(hs)),
+		}
+		for i, h := range hs {
+			if !h.Healthy {
+				res.Healthy = pp.PtrBool(false)
+			}
+			res.Coins[i] = &pp.CoinHealth{
+				Type:    pp.PtrString(h.Type),
+				Healthy: pp.PtrBool(h.Healthy),
+				Error:   pp.PtrString(h.Error),
+			}
+		}
+		return c.SendJSON(&res)
+	}
+}
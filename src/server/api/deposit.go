@@ -32,13 +32,13 @@ func GetNewAddress(ee engine.Exchange) sknet.HandlerFunc {
 				break
 			}
 
-			ct := req.GetCoinType()
-			// get the new address for depositing
-			addr := ee.GetNewAddress(ct)
-
-			// add the new address to engin for watching it's utxos.
-			at.AddDepositAddress(ct, addr)
-			ee.WatchAddress(ct, addr)
+			// get the address for depositing, it's bound to the account and watched.
+			addr, err := ee.GetDepositAddress(req.GetCoinType(), at.GetID())
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_ServerError)
+				break
+			}
 
 			ds := pp.GetDepositAddrRes{
 				Result:   pp.MakeResultWithCode(pp.ErrCode_Success),
@@ -3,11 +3,13 @@ package api
 import (
 	"errors"
 	"fmt"
-	"strings"
+	"sort"
 
 	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
 	"github.com/skycoin/skycoin-exchange/src/server/engine"
 	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/skycoin/skycoin-exchange/src/server/trade"
 	"github.com/skycoin/skycoin-exchange/src/sknet"
 )
 
@@ -31,8 +33,7 @@ func CreateOrder(egn engine.Exchange) sknet.HandlerFunc {
 				break
 			}
 
-			// get order type
-			op, err := order.TypeFromStr(req.GetType())
+			odr, err := newOrder(pubkey, req)
 			if err != nil {
 				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
 				logger.Error(err.Error())
@@ -40,57 +41,105 @@ func CreateOrder(egn engine.Exchange) sknet.HandlerFunc {
 			}
 
 			// find the account
-			acnt, err := egn.GetAccount(pubkey)
-			if err != nil {
+			if _, err := egn.GetAccount(pubkey); err != nil {
 				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
 				logger.Error(err.Error())
 				break
 			}
 
-			cp, bal, err := needBalance(op, req)
+			if err := egn.ValidateOrder(req.GetCoinPair(), *odr); err != nil {
+				rlt = pp.MakeErrRes(err)
+				logger.Debug(err.Error())
+				break
+			}
+
+			// the balance is reserved by AddOrder, and given back if the order is not added.
+			oid, err := egn.AddOrder(req.GetCoinPair(), *odr)
 			if err != nil {
-				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
 				logger.Error(err.Error())
+				rlt = orderErrRes(err)
 				break
 			}
+			egn.SaveAccount()
+			logger.Info(fmt.Sprintf("new %s %s order:%d", odr.Kind, odr.Type, oid))
+			res := pp.OrderRes{
+				Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
+				OrderId: &oid,
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
 
-			if acnt.GetBalance(cp) < bal {
-				err := fmt.Errorf("%s balance is not sufficient", cp)
-				rlt = pp.MakeErrRes(err)
-				logger.Debug(err.Error())
+// MaxBatchOrders max number of orders in the batch order request.
+var MaxBatchOrders = 100
+
+// CreateOrders creates the batch of orders of specifc coin pair, the orders are placed in the
+// order of the request and the book is matched once after all are placed. The pubkey of the
+// batch is used for all the orders, the result of each order is returned at its index, the
+// failed orders don't stop the others.
+func CreateOrders(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		req := &pp.BatchOrderReq{}
+		for {
+			if err := c.BindJSON(req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
 				break
 			}
 
-			var success bool
-			if op == order.Bid {
-				defer func() {
-					if success {
-						egn.SaveAccount()
-					} else {
-						acnt.IncreaseBalance(cp, bal)
-					}
-				}()
-				// decrease the balance, in case of double use the coins.
-				logger.Info("account:%s decrease %s:%d", acnt.GetID(), cp, bal)
-				if err := acnt.DecreaseBalance(cp, bal); err != nil {
-					rlt = pp.MakeErrRes(err)
-					logger.Error(err.Error())
-					break
-				}
+			// validate pubkey
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
 			}
 
-			odr := order.New(pubkey, op, req.GetPrice(), req.GetAmount())
-			oid, err := egn.AddOrder(req.GetCoinPair(), *odr)
-			if err != nil {
+			if _, err := egn.GetAccount(pubkey); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
 				logger.Error(err.Error())
-				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
 				break
 			}
-			success = true
-			logger.Info(fmt.Sprintf("new %s order:%d", op, oid))
-			res := pp.OrderRes{
+
+			if len(req.Orders) == 0 || len(req.Orders) > MaxBatchOrders {
+				rlt = pp.MakeErrRes(fmt.Errorf("number of orders must be between 1 and %d", MaxBatchOrders))
+				break
+			}
+
+			// the malformed orders are not passed to the engine.
+			results := make([]*pp.BatchOrderResult, len(req.Orders))
+			idx := []int{}
+			odrs := []order.Order{}
+			for i, r := range req.Orders {
+				odr, err := newOrder(pubkey, r)
+				if err != nil {
+					results[i] = &pp.BatchOrderResult{Result: pp.MakeErrRes(err).Result}
+					continue
+				}
+				idx = append(idx, i)
+				odrs = append(odrs, *odr)
+			}
+
+			ids, errs := egn.AddOrders(req.GetCoinPair(), odrs)
+			for j, i := range idx {
+				if errs[j] != nil {
+					logger.Debug(errs[j].Error())
+					results[i] = &pp.BatchOrderResult{Result: orderErrRes(errs[j]).Result}
+					continue
+				}
+				logger.Info(fmt.Sprintf("new %s %s order:%d", odrs[j].Kind, odrs[j].Type, ids[j]))
+				results[i] = &pp.BatchOrderResult{
+					Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
+					OrderId: pp.PtrUint64(ids[j]),
+				}
+			}
+
+			res := pp.BatchOrderRes{
 				Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
-				OrderId: &oid,
+				Results: results,
 			}
 			return c.SendJSON(&res)
 		}
@@ -98,6 +147,50 @@ func CreateOrder(egn engine.Exchange) sknet.HandlerFunc {
 	}
 }
 
+// orderErrRes makes the response of the order rejected with err, only the errors caused by
+// the request are shown to the client.
+func orderErrRes(err error) *pp.EmptyRes {
+	if order.IsUserError(err) || errors.Is(err, account.ErrAccountFrozen) {
+		return pp.MakeErrRes(err)
+	}
+	return pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+}
+
+// newOrder makes the order of the account from the order request.
+func newOrder(pubkey string, req *pp.OrderReq) (*order.Order, error) {
+	// get order type
+	op, err := order.TypeFromStr(req.GetType())
+	if err != nil {
+		return nil, err
+	}
+
+	// get order kind, limit or market.
+	kind, err := order.KindFromStr(req.GetKind())
+	if err != nil {
+		return nil, err
+	}
+
+	// get time in force of limit order.
+	tif, err := order.TimeInForceFromStr(req.GetTimeInForce())
+	if err != nil {
+		return nil, err
+	}
+
+	// get the coin of the amount, base or quote.
+	cur, err := order.CurrencyFromStr(req.GetAmountCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	odr := order.New(pubkey, op, req.GetPrice(), req.GetAmount())
+	odr.AmountCurrency = cur
+	odr.Kind = kind
+	odr.TimeInForce = tif
+	odr.ExpireAt = req.GetExpireAt()
+	odr.StopPrice = req.GetStopPrice()
+	return odr, nil
+}
+
 // GetOrders get order list.
 func GetOrders(egn engine.Exchange) sknet.HandlerFunc {
 	return func(c *sknet.Context) error {
@@ -114,7 +207,15 @@ func GetOrders(egn engine.Exchange) sknet.HandlerFunc {
 				logger.Error(err.Error())
 				break
 			}
-			ords, err := egn.GetOrders(req.GetCoinPair(), op, req.GetStart(), req.GetEnd())
+			var (
+				ords  []order.Order
+				total int
+			)
+			if req.GetByTime() {
+				ords, err = egn.GetOrdersByTime(req.GetCoinPair(), op, req.GetStart(), req.GetEnd())
+			} else {
+				ords, total, err = egn.GetOrders(req.GetCoinPair(), op, req.GetStart(), req.GetEnd())
+			}
 			if err != nil {
 				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
 				logger.Error(err.Error())
@@ -123,6 +224,7 @@ func GetOrders(egn engine.Exchange) sknet.HandlerFunc {
 			res := pp.GetOrderRes{
 				CoinPair: req.CoinPair,
 				Type:     req.Type,
+				Total:    pp.PtrInt64(int64(total)),
 				Orders:   make([]*pp.Order, len(ords)),
 			}
 
@@ -144,21 +246,267 @@ func GetOrders(egn engine.Exchange) sknet.HandlerFunc {
 	}
 }
 
-func needBalance(tp order.Type, req *pp.OrderReq) (string, uint64, error) {
-	pair := strings.Split(req.GetCoinPair(), "/")
-	if len(pair) != 2 {
-		return "", 0, errors.New("error coin pair")
+// GetOrder get the open or recently closed order by id.
+func GetOrder(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetOrderByIDReq{}
+			if err := c.BindJSON(&req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			od, err := egn.GetOrder(req.GetCoinPair(), req.GetOrderId())
+			if err != nil {
+				logger.Error(err.Error())
+				if errors.Is(err, order.ErrOrderNotFound) {
+					rlt = pp.MakeErrRes(err)
+					break
+				}
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			res := pp.GetOrderByIDRes{
+				Result:   pp.MakeResultWithCode(pp.ErrCode_Success),
+				CoinPair: req.CoinPair,
+				Order: &pp.Order{
+					Id:        &od.ID,
+					Type:      pp.PtrString(od.Type.String()),
+					Price:     &od.Price,
+					Amount:    &od.Amount,
+					RestAmt:   &od.RestAmt,
+					CreatedAt: &od.CreatedAt,
+					Status:    pp.PtrString(od.Status().String()),
+				},
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// GetAccountOrders get the open and recently closed orders of the account in every coin pair.
+func GetAccountOrders(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetAccountOrdersReq{}
+			if err := c.BindJSON(&req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
+
+			ods, err := egn.GetAccountOrders(pubkey, req.GetOpenOnly())
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_NotExits)
+				break
+			}
+
+			cps := make([]string, 0, len(ods))
+			for cp := range ods {
+				cps = append(cps, cp)
+			}
+			sort.Strings(cps)
+
+			res := pp.GetAccountOrdersRes{
+				Result: pp.MakeResultWithCode(pp.ErrCode_Success),
+				Pairs:  make([]*pp.PairOrders, len(cps)),
+			}
+			for i, cp := range cps {
+				po := &pp.PairOrders{
+					CoinPair: pp.PtrString(cp),
+					Orders:   make([]*pp.Order, len(ods[cp])),
+				}
+				for j, od := range ods[cp] {
+					po.Orders[j] = &pp.Order{
+						Id:        pp.PtrUint64(od.ID),
+						Type:      pp.PtrString(od.Type.String()),
+						Price:     pp.PtrUint64(od.Price),
+						Amount:    pp.PtrUint64(od.Amount),
+						RestAmt:   pp.PtrUint64(od.RestAmt),
+						CreatedAt: pp.PtrInt64(od.CreatedAt),
+						Status:    pp.PtrString(od.Status().String()),
+					}
+				}
+				res.Pairs[i] = po
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// GetDepth get the aggregated depth of order book.
+func GetDepth(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetDepthReq{}
+			if err := c.BindJSON(&req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			bids, asks, err := egn.GetDepth(req.GetCoinPair(), int(req.GetLevels()))
+			if err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			res := pp.GetDepthRes{
+				CoinPair: req.CoinPair,
+				Bids:     makeDepthLevels(bids),
+				Asks:     makeDepthLevels(asks),
+			}
+			res.Result = pp.MakeResultWithCode(pp.ErrCode_Success)
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+func makeDepthLevels(depth []order.DepthLevel) []*pp.DepthLevel {
+	levels := make([]*pp.DepthLevel, len(depth))
+	for i := range depth {
+		levels[i] = &pp.DepthLevel{
+			Price:       &depth[i].Price,
+			TotalAmount: &depth[i].TotalAmount,
+		}
+	}
+	return levels
+}
+
+// GetTicker get the top of book and 24h volume of coin pair, the empty fields are omitted.
+func GetTicker(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetTickerReq{}
+			if err := c.BindJSON(&req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			tk, err := egn.GetTicker(req.GetCoinPair())
+			if err != nil {
+				rlt = pp.MakeErrRes(err)
+				logger.Error(err.Error())
+				break
+			}
+
+			res := pp.GetTickerRes{
+				CoinPair:  req.CoinPair,
+				BestBid:   tk.BestBid,
+				BestAsk:   tk.BestAsk,
+				Spread:    tk.Spread,
+				LastPrice: tk.LastPrice,
+				Volume:    &tk.Volume,
+				Time:      &tk.Time,
+			}
+			res.Result = pp.MakeResultWithCode(pp.ErrCode_Success)
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
+
+// GetCandles get the OHLCV candles of the trades in time range.
+func GetCandles(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetCandlesReq{}
+			if err := c.BindJSON(&req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			interval, ok := trade.Intervals[req.GetInterval()]
+			if !ok {
+				rlt = pp.MakeErrRes(fmt.Errorf("unknow candle interval:%s", req.GetInterval()))
+				break
+			}
+
+			candles, err := egn.GetCandles(req.GetCoinPair(), interval, req.GetStart(), req.GetEnd(), req.GetFill())
+			if err != nil {
+				rlt = pp.MakeErrRes(err)
+				logger.Error(err.Error())
+				break
+			}
+
+			res := pp.GetCandlesRes{
+				CoinPair: req.CoinPair,
+				Interval: req.Interval,
+				Candles:  make([]*pp.Candle, len(candles)),
+			}
+			for i := range candles {
+				res.Candles[i] = &pp.Candle{
+					Time:   &candles[i].Time,
+					Open:   &candles[i].Open,
+					High:   &candles[i].High,
+					Low:    &candles[i].Low,
+					Close:  &candles[i].Close,
+					Volume: &candles[i].Volume,
+				}
+			}
+			res.Result = pp.MakeResultWithCode(pp.ErrCode_Success)
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
 	}
+}
+
+// CancelOrder cancel the open order of the account.
+func CancelOrder(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.CancelOrderReq{}
+			if err := c.BindJSON(&req); err != nil {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				logger.Error(err.Error())
+				break
+			}
+
+			// validate pubkey
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
+				break
+			}
 
-	mainCt := pair[0]
-	subCt := pair[1]
+			if err := egn.CancelOrder(req.GetCoinPair(), req.GetOrderId(), pubkey); err != nil {
+				logger.Error(err.Error())
+				switch err {
+				case order.ErrOrderNotExist:
+					rlt = pp.MakeErrResWithCode(pp.ErrCode_NotExits)
+				case order.ErrNotOrderOwner:
+					rlt = pp.MakeErrResWithCode(pp.ErrCode_UnAuthorized)
+				default:
+					rlt = pp.MakeErrRes(err)
+				}
+				break
+			}
 
-	switch tp {
-	case order.Bid:
-		return subCt, req.GetPrice() * req.GetAmount(), nil
-	case order.Ask:
-		return mainCt, req.GetAmount(), nil
-	default:
-		return "", 0, errors.New("unknow order type")
+			logger.Info("cancel order:%d", req.GetOrderId())
+			res := pp.CancelOrderRes{
+				Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
+				OrderId: req.OrderId,
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
 	}
 }
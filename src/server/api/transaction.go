@@ -123,3 +123,40 @@ func GetRawTx(egn engine.Exchange) sknet.HandlerFunc {
 		return c.Error(rlt)
 	}
 }
+
+// GetAddrTxs return transactions of specific addresses.
+func GetAddrTxs(egn engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		var rlt *pp.EmptyRes
+		for {
+			req := pp.GetAddrTxsReq{}
+			if err := c.BindJSON(&req); err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
+				break
+			}
+
+			coin, err := egn.GetCoin(req.GetCoinType())
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			txs, err := coin.GetAddressTxs(req.GetAddresses())
+			if err != nil {
+				logger.Error(err.Error())
+				rlt = pp.MakeErrRes(err)
+				break
+			}
+
+			res := pp.GetAddrTxsRes{
+				Result:   pp.MakeResultWithCode(pp.ErrCode_Success),
+				CoinType: req.CoinType,
+				Txs:      txs,
+			}
+			return c.SendJSON(&res)
+		}
+		return c.Error(rlt)
+	}
+}
@@ -3,10 +3,8 @@ package api
 import (
 	"encoding/hex"
 	"errors"
-	"fmt"
 	"time"
 
-	"github.com/skycoin/skycoin-exchange/src/coin"
 	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
 	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
 	"github.com/skycoin/skycoin-exchange/src/pp"
@@ -55,6 +53,11 @@ func getWithdrawReqParams(c *sknet.Context, ee engine.Exchange) (*ReqParams, err
 	rp.Values["cointype"] = req.GetCoinType()
 	rp.Values["amt"] = req.GetCoins()
 	rp.Values["outAddr"] = req.GetOutputAddress()
+	rp.Values["key"] = req.GetIdempotencyKey()
+	rp.Values["feeRate"] = req.GetFeeRate()
+	rp.Values["memo"] = req.GetMemo()
+	rp.Values["utxos"] = req.GetUtxos()
+	rp.Values["totpCode"] = req.GetTotpCode()
 	return rp, nil
 }
 
@@ -70,167 +73,80 @@ func Withdraw(ee engine.Exchange) sknet.HandlerFunc {
 				break
 			}
 
-			cp := reqParam.Values["cointype"].(string)
 			a := reqParam.Values["account"].(account.Accounter)
-			amt := reqParam.Values["amt"].(uint64)
-			outAddr := reqParam.Values["outAddr"].(string)
-
-			// get handler for creating txIns and txOuts base on the coin type.
-			createTxInOut, err := getTxInOutHandler(cp)
+			txid, err := ee.Withdraw(account.WithdrawalRequest{
+				AccountID: a.GetID(),
+				CoinType:  reqParam.Values["cointype"].(string),
+				Address:   reqParam.Values["outAddr"].(string),
+				Amount:    reqParam.Values["amt"].(uint64),
+				Key:       reqParam.Values["key"].(string),
+				FeeRate:   reqParam.Values["feeRate"].(uint64),
+				Memo:      reqParam.Values["memo"].(string),
+				Utxos:     reqParam.Values["utxos"].([]string),
+				TOTPCode:  reqParam.Values["totpCode"].(string),
+			})
 			if err != nil {
 				logger.Error(err.Error())
 				rlt = pp.MakeErrRes(err)
 				break
 			}
 
-			// create txIns and txOuts.
-			inOutSet, err := createTxInOut(ee, a, amt, outAddr)
-			if err != nil {
-				logger.Error(err.Error())
-				rlt = pp.MakeErrRes(err)
-				break
+			resp := pp.WithdrawalRes{
+				Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
+				NewTxid: &txid,
 			}
+			return c.SendJSON(&resp)
+		}
+		return c.Error(rlt)
+	}
+}
 
-			var success bool
-			defer func() {
-				if !success {
-					// if not success, invoke the teardown, for putting back utxos, and reset balance.
-					inOutSet.Teardown()
-				}
-			}()
-
-			// get coin gateway.
-			coin, err := ee.GetCoin(cp)
-			if err != nil {
+// GetWithdrawal returns the recent withdrawal of the account by txid, including its memo.
+func GetWithdrawal(ee engine.Exchange) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		rlt := &pp.EmptyRes{}
+		for {
+			req := pp.GetWithdrawalReq{}
+			if err := c.BindJSON(&req); err != nil {
 				logger.Error(err.Error())
-				rlt = pp.MakeErrRes(err)
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongRequest)
 				break
 			}
 
-			// create raw tx
-			rawtx, err := coin.CreateRawTx(inOutSet.TxIns, inOutSet.TxOuts)
-			if err != nil {
+			pubkey := req.GetPubkey()
+			if err := validatePubkey(pubkey); err != nil {
 				logger.Error(err.Error())
-				rlt = pp.MakeErrRes(err)
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_WrongPubkey)
 				break
 			}
 
-			// sign the tx
-			rawtx, err = coin.SignRawTx(rawtx, getAddrPrivKey(ee, cp))
+			a, err := ee.GetAccount(pubkey)
 			if err != nil {
-				logger.Error(err.Error())
-				rlt = pp.MakeErrRes(err)
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_NotExits)
 				break
 			}
 
-			// inject the transaction.
-			txid, err := coin.InjectTx(rawtx)
-			if err != nil {
-				logger.Error(err.Error())
-				rlt = pp.MakeErrRes(err)
+			r, ok := a.GetWithdrawalByTxid(req.GetTxid())
+			if !ok {
+				rlt = pp.MakeErrResWithCode(pp.ErrCode_NotExits)
 				break
 			}
 
-			success = true
-			resp := pp.WithdrawalRes{
-				Result:  pp.MakeResultWithCode(pp.ErrCode_Success),
-				NewTxid: &txid,
+			res := pp.GetWithdrawalRes{
+				Result:   pp.MakeResultWithCode(pp.ErrCode_Success),
+				CoinType: pp.PtrString(r.CoinType),
+				Address:  pp.PtrString(r.Address),
+				Amount:   pp.PtrUint64(r.Amount),
+				Txid:     pp.PtrString(r.Txid),
+				Memo:     pp.PtrString(r.Memo),
+				Time:     pp.PtrInt64(r.Time),
 			}
-			return c.SendJSON(&resp)
+			return c.SendJSON(&res)
 		}
 		return c.Error(rlt)
 	}
 }
 
-func getAddrPrivKey(ee engine.Exchange, cp string) coin.GetPrivKey {
-	return func(addr string) (string, error) {
-		return ee.GetAddrPrivKey(cp, addr)
-	}
-}
-
-// txInOutHandler used to generate TxIns and txOuts.
-type txInOutHandler func(ee engine.Exchange, a account.Accounter, amount uint64, outAddr string) (*txInOutResult, error)
-
-// global txInOut handlers, if new coin type need to be supported, register here.
-var txInOutHandlers = map[string]txInOutHandler{
-	bitcoin.Type: createBtcTxInOut,
-	skycoin.Type: createSkyTxInOut,
-}
-
-func getTxInOutHandler(cp string) (txInOutHandler, error) {
-	if hd, ok := txInOutHandlers[cp]; ok {
-		return hd, nil
-	}
-	return nil, fmt.Errorf("%s tx in handler not found", cp)
-}
-
-type txInOutResult struct {
-	TxIns    []coin.TxIn // transaction in values.
-	TxOuts   interface{} // transaction out values, must be a slice.
-	Teardown func()      // function for put back the choosen utxos, and reset balance,etc.
-}
-
-func createBtcTxInOut(ee engine.Exchange, a account.Accounter, amount uint64, outAddr string) (*txInOutResult, error) {
-	var rlt txInOutResult
-	// verify the outAddr
-	if _, err := cipher.BitcoinDecodeBase58Address(outAddr); err != nil {
-		return nil, errors.New("invalid bitcoin address")
-	}
-
-	var err error
-	// decrease balance and check if the balance is sufficient.
-	if err := a.DecreaseBalance("bitcoin", amount+ee.GetBtcFee()); err != nil {
-		return nil, err
-	}
-
-	var utxos []bitcoin.Utxo
-
-	// choose sufficient utxos.
-	uxs, err := ee.ChooseUtxos("bitcoin", amount+ee.GetBtcFee(), ChooseUtxoTm)
-	if err != nil {
-		return nil, err
-	}
-	utxos = uxs.([]bitcoin.Utxo)
-
-	for _, u := range utxos {
-		logger.Debug("using utxos: txid:%s vout:%d addr:%s", u.GetTxid(), u.GetVout(), u.GetAddress())
-		rlt.TxIns = append(rlt.TxIns, coin.TxIn{
-			Txid: u.GetTxid(),
-			Vout: u.GetVout(),
-		})
-	}
-
-	var totalAmounts uint64
-	for _, u := range utxos {
-		totalAmounts += u.GetAmount()
-	}
-	fee := ee.GetBtcFee()
-	txOuts := []bitcoin.TxOut{}
-	chgAmt := totalAmounts - fee - amount
-	chgAddr := ""
-	if chgAmt > 0 {
-		// generate a change address
-		chgAddr = ee.GetNewAddress(bitcoin.Type)
-		txOuts = append(txOuts,
-			bitcoin.TxOut{Addr: outAddr, Value: amount},
-			bitcoin.TxOut{Addr: chgAddr, Value: chgAmt})
-	} else {
-		txOuts = append(txOuts, bitcoin.TxOut{Addr: outAddr, Value: amount})
-	}
-
-	rlt.TxOuts = txOuts
-	rlt.Teardown = func() {
-		a.IncreaseBalance(bitcoin.Type, amount+ee.GetBtcFee())
-		ee.PutUtxos(bitcoin.Type, utxos)
-	}
-
-	return &rlt, nil
-}
-
-func createSkyTxInOut(ee engine.Exchange, a account.Accounter, amount uint64, outAddr string) (*txInOutResult, error) {
-	return nil, nil
-}
-
 func btcWithdraw(rp *ReqParams) (*pp.WithdrawalRes, *pp.EmptyRes) {
 	ee := rp.Values["engine"].(engine.Exchange)
 	acnt := rp.Values["account"].(account.Accounter)
@@ -238,14 +154,14 @@ func btcWithdraw(rp *ReqParams) (*pp.WithdrawalRes, *pp.EmptyRes) {
 	ct := rp.Values["cointype"].(string)
 	toAddr := rp.Values["toAddr"].(string)
 	// verify the toAddr
-	if _, err := cipher.BitcoinDecodeBase58Address(toAddr); err != nil {
+	if err := bitcoin.ValidateAddr(toAddr); err != nil {
 		return nil, pp.MakeErrRes(errors.New("invalid bitcoin address"))
 	}
 	var success bool
 	var btcTxRlt *BtcTxResult
 	var err error
 	// decrease balance and check if the balance is sufficient.
-	if err := acnt.DecreaseBalance(ct, amt+ee.GetBtcFee()); err != nil {
+	if err := acnt.DecreaseBalance(ct, amt+ee.GetBtcFee(), account.ReasonWithdraw); err != nil {
 		return nil, pp.MakeErrRes(err)
 	}
 	defer func() {
@@ -254,7 +170,7 @@ func btcWithdraw(rp *ReqParams) (*pp.WithdrawalRes, *pp.EmptyRes) {
 				if btcTxRlt != nil {
 					ee.PutUtxos(bitcoin.Type, btcTxRlt.UsingUtxos)
 				}
-				acnt.IncreaseBalance(ct, amt+ee.GetBtcFee())
+				acnt.IncreaseBalance(ct, amt+ee.GetBtcFee(), account.ReasonWithdrawRollback)
 			}()
 		} else {
 			//TODO: handle the saving failure.
@@ -310,7 +226,7 @@ func skyWithdrawl(nodeAddr string, rp *ReqParams) (*pp.WithdrawalRes, *pp.EmptyR
 	var success bool
 	var skyTxRlt *SkyTxResult
 	var err error
-	if err := acnt.DecreaseBalance(ct, amt); err != nil {
+	if err := acnt.DecreaseBalance(ct, amt, account.ReasonWithdraw); err != nil {
 		return nil, pp.MakeErrRes(err)
 	}
 	defer func() {
@@ -319,7 +235,7 @@ func skyWithdrawl(nodeAddr string, rp *ReqParams) (*pp.WithdrawalRes, *pp.EmptyR
 				if skyTxRlt != nil {
 					ee.PutUtxos(skycoin.Type, skyTxRlt.UsingUtxos)
 				}
-				acnt.IncreaseBalance(ct, amt)
+				acnt.IncreaseBalance(ct, amt, account.ReasonWithdrawRollback)
 			}()
 		} else {
 			//TODO: handle the saving failure.
@@ -387,7 +303,10 @@ func createBtcWithdrawTx(egn engine.Exchange, amount uint64, toAddr string) (*Bt
 	chgAddr := ""
 	if chgAmt > 0 {
 		// generate a change address
-		chgAddr = egn.GetNewAddress(bitcoin.Type)
+		chgAddr, err = egn.GetNewAddress(bitcoin.Type, "")
+		if err != nil {
+			return nil, err
+		}
 		outAddrs = append(outAddrs,
 			bitcoin.TxOut{Addr: toAddr, Value: amount},
 			bitcoin.TxOut{Addr: chgAddr, Value: chgAmt})
@@ -446,7 +365,10 @@ func createSkyWithdrawTx(egn engine.Exchange, amount uint64, toAddr string) (*Sk
 	chgAddr := ""
 	if chgAmt > 0 {
 		// generate a change address
-		chgAddr = egn.GetNewAddress(skycoin.Type)
+		chgAddr, err = egn.GetNewAddress(skycoin.Type, "")
+		if err != nil {
+			return nil, err
+		}
 		outAddrs = append(outAddrs,
 			skycoin.MakeUtxoOutput(toAddr, amount, chgHours/2),
 			skycoin.MakeUtxoOutput(chgAddr, chgAmt, chgHours/2))
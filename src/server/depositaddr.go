@@ -0,0 +1,129 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxDeriveAttempts max new addresses derived by GetDepositAddress to find one not assigned yet.
+const maxDeriveAttempts = 10
+
+// addrAssignment the deposit address handed out to an account.
+type addrAssignment struct {
+	accountID  string
+	assignedAt time.Time
+	funded     bool // set once a deposit to the address is credited.
+}
+
+// depositAddrBook records the deposit addresses handed out, so that the address awaiting deposit
+// is not handed out again, and the unfunded ones can be reclaimed once expired. It's kept in
+// memory, the addresses handed out before restart are never reclaimed. The zero value is ready to use.
+type depositAddrBook struct {
+	mtx   sync.Mutex
+	addrs map[string]map[string]*addrAssignment // key: coin type and address.
+}
+
+// assign records the address is handed out to the account at time now.
+func (b *depositAddrBook) assign(ct, addr, accountID string, now time.Time) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.addrs == nil {
+		b.addrs = make(map[string]map[string]*addrAssignment)
+	}
+	if _, ok := b.addrs[ct]; !ok {
+		b.addrs[ct] = make(map[string]*addrAssignment)
+	}
+	b.addrs[ct][addr] = &addrAssignment{accountID: accountID, assignedAt: now}
+}
+
+// isAssigned checks whether the address has been handed out.
+func (b *depositAddrBook) isAssigned(ct, addr string) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	_, ok := b.addrs[ct][addr]
+	return ok
+}
+
+// markFunded marks the address funded, it's never reclaimed.
+func (b *depositAddrBook) markFunded(ct, addr string) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if a, ok := b.addrs[ct][addr]; ok {
+		a.funded = true
+	}
+}
+
+// expired returns the unfunded address of coin type ct which is assigned longest ago,
+// and its account id, if it's assigned before now - ttl.
+func (b *depositAddrBook) expired(ct string, now time.Time, ttl time.Duration) (string, string, bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	var (
+		addr   string
+		oldest *addrAssignment
+	)
+	for ad, a := range b.addrs[ct] {
+		if a.funded || now.Sub(a.assignedAt) < ttl {
+			continue
+		}
+		if oldest == nil || a.assignedAt.Before(oldest.assignedAt) || (a.assignedAt.Equal(oldest.assignedAt) && ad < addr) {
+			addr, oldest = ad, a
+		}
+	}
+
+	if oldest == nil {
+		return "", "", false
+	}
+	return addr, oldest.accountID, true
+}
+
+// GetDepositAddress hands out the deposit address of coin type ct to the account, the address
+// is bound to the account and watched. The address handed out before or bound to any account
+// is skipped. If Config.DepositAddrTTL is set, the unfunded address handed out longer than it
+// is reclaimed from its account and handed out again, instead of deriving a new one.
+func (self *ExchangeServer) GetDepositAddress(ct, accountID string) (string, error) {
+	if _, err := self.GetAccount(accountID); err != nil {
+		return "", err
+	}
+
+	self.depositMtx.Lock()
+	defer self.depositMtx.Unlock()
+	now := time.Now()
+	if ttl := self.cfg.DepositAddrTTL; ttl > 0 {
+		if addr, owner, ok := self.depositAddrs.expired(ct, now, ttl); ok {
+			if err := self.UnbindDepositAddress(ct, addr); err != nil {
+				return "", err
+			}
+			if err := self.BindDepositAddress(ct, addr, accountID); err != nil {
+				return "", err
+			}
+			self.depositAddrs.assign(ct, addr, accountID, now)
+			logger.Info("%s deposit address %s reclaimed from account %s to %s", ct, addr, owner, accountID)
+			return addr, nil
+		}
+	}
+
+	for i := 0; i < maxDeriveAttempts; i++ {
+		addr, err := self.GetNewAddress(ct, "")
+		if err != nil {
+			return "", err
+		}
+
+		if self.depositAddrs.isAssigned(ct, addr) {
+			continue
+		}
+		if _, err := self.GetAccountByAddress(ct, addr); err == nil {
+			continue
+		}
+
+		// bind the address to the account, so that the deposits to it can be credited.
+		if err := self.BindDepositAddress(ct, addr, accountID); err != nil {
+			return "", err
+		}
+		self.depositAddrs.assign(ct, addr, accountID, now)
+		self.WatchAddress(ct, addr)
+		return addr, nil
+	}
+	return "", fmt.Errorf("no unassigned %s address after %d attempts", ct, maxDeriveAttempts)
+}
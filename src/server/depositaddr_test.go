@@ -0,0 +1,115 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepositAddrBook(t *testing.T) {
+	var b depositAddrBook
+	now := time.Now()
+	assert.False(t, b.isAssigned(bitcoin.Type, "addr1"))
+	_, _, ok := b.expired(bitcoin.Type, now, time.Hour)
+	assert.False(t, ok)
+
+	b.assign(bitcoin.Type, "addr1", "a", now.Add(-3*time.Hour))
+	b.assign(bitcoin.Type, "addr2", "b", now.Add(-2*time.Hour))
+	b.assign(bitcoin.Type, "addr3", "c", now.Add(-30*time.Minute))
+	assert.True(t, b.isAssigned(bitcoin.Type, "addr1"))
+	assert.False(t, b.isAssigned("skycoin", "addr1"))
+
+	// the oldest unfunded address is expired first.
+	addr, owner, ok := b.expired(bitcoin.Type, now, time.Hour)
+	assert.True(t, ok)
+	assert.Equal(t, "addr1", addr)
+	assert.Equal(t, "a", owner)
+
+	// the funded address is never expired.
+	b.markFunded(bitcoin.Type, "addr1")
+	addr, owner, ok = b.expired(bitcoin.Type, now, time.Hour)
+	assert.True(t, ok)
+	assert.Equal(t, "addr2", addr)
+	assert.Equal(t, "b", owner)
+
+	// the reassigned address starts a new ttl.
+	b.assign(bitcoin.Type, "addr2", "d", now)
+	_, _, ok = b.expired(bitcoin.Type, now, time.Hour)
+	assert.False(t, ok)
+
+	addr, owner, ok = b.expired(bitcoin.Type, now.Add(time.Hour), time.Hour)
+	assert.True(t, ok)
+	assert.Equal(t, "addr3", addr)
+	assert.Equal(t, "c", owner)
+	_, _, ok = b.expired("skycoin", now.Add(time.Hour), time.Hour)
+	assert.False(t, ok)
+}
+
+func TestGetDepositAddress(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_deposit_addr")
+	account.InitDir(filepath.Join(dir, "account"))
+	wallet.InitDir(filepath.Join(dir, "wallet"))
+	defer os.RemoveAll(dir)
+
+	wlts, err := makeWallets(filepath.Join(dir, "wallet"), []walletItem{{bitcoin.Type, DefaultWallet, "seed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &ExchangeServer{
+		Manager: account.NewManager(),
+		btcum:   bitcoin.NewUtxoManager(10, []string{}),
+		wallets: wlts,
+	}
+	for _, id := range []string{"a", "b"} {
+		_, err := s.CreateAccountWithPubkey(id)
+		assert.Nil(t, err)
+	}
+
+	_, err = s.GetDepositAddress(bitcoin.Type, "unknown")
+	assert.NotNil(t, err)
+
+	addr, err := s.GetDepositAddress(bitcoin.Type, "a")
+	assert.Nil(t, err)
+	a, err := s.GetAccountByAddress(bitcoin.Type, addr)
+	assert.Nil(t, err)
+	assert.Equal(t, "a", a.GetID())
+
+	// the address awaiting deposit is skipped, every new address of the test wallet
+	// is the same one, so no address is left.
+	_, err = s.GetDepositAddress(bitcoin.Type, "b")
+	assert.NotNil(t, err)
+
+	// the address not expired is not reclaimed.
+	s.cfg.DepositAddrTTL = time.Hour
+	_, err = s.GetDepositAddress(bitcoin.Type, "b")
+	assert.NotNil(t, err)
+
+	// the expired address is reclaimed from account a.
+	s.depositAddrs.assign(bitcoin.Type, addr, "a", time.Now().Add(-2*time.Hour))
+	raddr, err := s.GetDepositAddress(bitcoin.Type, "b")
+	assert.Nil(t, err)
+	assert.Equal(t, addr, raddr)
+	b, err := s.GetAccountByAddress(bitcoin.Type, addr)
+	assert.Nil(t, err)
+	assert.Equal(t, "b", b.GetID())
+	a, _ = s.GetAccount("a")
+	assert.False(t, a.HasDepositAddress(bitcoin.Type, addr))
+	assert.True(t, b.HasDepositAddress(bitcoin.Type, addr))
+
+	// the funded address is never reclaimed, the deposit is credited to account b.
+	s.depositAddrs.assign(bitcoin.Type, addr, "b", time.Now().Add(-2*time.Hour))
+	s.creditDeposit(bitcoin.Type, addr, "txid:0", 100)
+	assert.Equal(t, uint64(100), b.GetBalance(bitcoin.Type))
+	_, err = s.GetDepositAddress(bitcoin.Type, "a")
+	assert.NotNil(t, err)
+	b, err = s.GetAccountByAddress(bitcoin.Type, addr)
+	assert.Nil(t, err)
+	assert.Equal(t, "b", b.GetID())
+}
@@ -1,11 +1,13 @@
 package engine
 
 import (
+	"context"
 	"time"
 
 	"github.com/skycoin/skycoin-exchange/src/coin"
 	"github.com/skycoin/skycoin-exchange/src/server/account"
 	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/skycoin/skycoin-exchange/src/server/trade"
 )
 
 type Exchange interface {
@@ -19,31 +21,71 @@ type Exchange interface {
 type Accounter interface {
 	CreateAccountWithPubkey(pubkey string) (account.Accounter, error)
 	GetAccount(id string) (account.Accounter, error)
+	GetAccountBalances(accountID string) (map[string]account.Balance, error)
+	BindDepositAddress(ct, addr, accountID string) error
+	CreateAccount(pubkey string) (string, error)
+	DeleteAccount(accountID string) error
+	FreezeAccount(accountID string, cancelOrders bool) error
+	UnfreezeAccount(accountID string) error
+	TransferBalance(fromID, toID, ct string, amount uint64) error
+	GetAccountNonce(accountID string) (uint64, error)
+	UseAccountNonce(accountID string, nonce uint64) error
 	SaveAccount() error
 	IsAdmin(pubkey string) bool
 }
 
 type Addresser interface {
 	WatchAddress(ct, addr string)
-	GetNewAddress(coinType string) string
+	GetNewAddress(coinType, wltName string) (string, error)
+	GetDepositAddress(ct, accountID string) (string, error)
 	GetAddrPrivKey(ct, addr string) (string, error)
+	Withdraw(req account.WithdrawalRequest) (string, error)
+	EnrollTOTP(accountID string) (string, string, error)
+	ConfirmTOTP(accountID, code string) error
 }
 
 type Order interface {
 	AddOrder(cp string, odr order.Order) (uint64, error)
-	GetOrders(cp string, tp order.Type, start, end int64) ([]order.Order, error)
+	AddOrders(cp string, odrs []order.Order) ([]uint64, []error)
+	ValidateOrder(cp string, odr order.Order) error
+	AddOrderValidator(v order.Validator)
+	OrderCost(cp string, odr order.Order) (string, uint64, error)
+	ReserveOrder(cp string, odr *order.Order) (func(), error)
+	CancelOrder(cp string, id uint64, aid string) error
+	SetMinOrderAmount(cp string, amt uint64) error
+	SetTickSize(cp string, tick uint64) error
+	SetPriceDecimals(cp string, decimals uint8) error
+	SetSelfTradePrevention(cp string, mode order.STPMode) error
+	SetMaxOrders(cp string, max int, policy order.FullPolicy) error
+	SetPriceBand(cp string, bps uint64) error
+	AddCoinPair(cp string) error
+	HaltPair(cp string) error
+	ResumePair(cp string) error
+	GetOrders(cp string, tp order.Type, start, end int64) ([]order.Order, int, error)
+	GetOrdersByTime(cp string, tp order.Type, start, end int64) ([]order.Order, error)
+	GetOrder(cp string, id uint64) (order.Order, error)
+	GetAccountOrders(accountID string, openOnly bool) (map[string][]order.Order, error)
+	GetDepth(cp string, levels int) (bids []order.DepthLevel, asks []order.DepthLevel, err error)
+	OrderValue(cp string, price, amount uint64, r order.Rounding) (uint64, error)
+	MarketCost(cp string, amount uint64) (uint64, error)
+	GetCandles(cp string, interval time.Duration, start, end int64, fill bool) ([]trade.Candle, error)
+	GetTicker(cp string) (order.Ticker, error)
 }
 
 type Utxor interface {
 	ChooseUtxos(ct string, amount uint64, tm time.Duration) (interface{}, error)
 	PutUtxos(ct string, utxos interface{})
+	GetUtxoStats(ct string) (coin.UtxoStats, error)
 }
 
 type Server interface {
 	Run()
+	Shutdown(ctx context.Context) error
 	GetSecKey() string
 	GetBtcFee() uint64
 	GetSupportCoins() []string
+	GetSupportedCoinsInfo() []coin.Info
+	HealthCheck() []coin.Health
 	GetCoin(ct string) (coin.Gateway, error)
 	BindCoins(cs ...coin.Gateway) error
 }
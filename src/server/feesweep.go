@@ -0,0 +1,138 @@
+package server
+
+import (
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/sklog"
+)
+
+// FeeSweepMemo the memo of the withdrawals sweeping the fee account.
+const FeeSweepMemo = "fee sweep"
+
+// runFeeSweeper sweeps the fee account every Config.FeeSweepInterval until closing is closed.
+func (self *ExchangeServer) runFeeSweeper(closing chan bool) {
+	ticker := time.NewTicker(self.cfg.FeeSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closing:
+			return
+		case <-ticker.C:
+			self.sweepFees()
+		}
+	}
+}
+
+// sweepFees withdraws the balance of the fee account to the cold address in Config.FeeSweepAddrs
+// once it exceeds the threshold in Config.FeeSweepThresholds, the whole balance minus the withdrawal
+// fee is sent. A coin is not swept again until its last sweep is confirmed, the pending sweeps are
+// kept in memory, and the failed sweeps are retried in the next round.
+func (self *ExchangeServer) sweepFees() {
+	for ct, addr := range self.cfg.FeeSweepAddrs {
+		if self.feeSweepPending(ct) {
+			continue
+		}
+
+		if txid, err := self.sweepFeeAccount(ct, addr); err != nil {
+			logger.Error("sweep %s fees failed: %v", ct, err)
+		} else if txid != "" {
+			self.feeSweepMtx.Lock()
+			if self.feeSweeps == nil {
+				self.feeSweeps = make(map[string]string)
+			}
+			self.feeSweeps[ct] = txid
+			self.feeSweepMtx.Unlock()
+		}
+	}
+}
+
+// sweepFeeAccount withdraws the ct coins of the fee account to addr if the balance exceeds the threshold,
+// returns the txid, or empty string if nothing is swept.
+func (self *ExchangeServer) sweepFeeAccount(ct, addr string) (string, error) {
+	acnt, err := self.GetAccount(self.cfg.FeeAccount)
+	if err != nil {
+		return "", err
+	}
+
+	bal := acnt.GetBalance(ct)
+	if bal <= self.cfg.FeeSweepThresholds[ct] {
+		return "", nil
+	}
+
+	gateway, err := self.GetCoin(ct)
+	if err != nil {
+		return "", err
+	}
+
+	fee, err := self.withdrawFee(ct, gateway, addr, 0)
+	if err != nil {
+		return "", err
+	}
+
+	if bal <= fee {
+		return "", nil
+	}
+
+	amount := bal - fee
+	txid, err := self.withdraw(account.WithdrawalRequest{
+		AccountID: self.cfg.FeeAccount,
+		CoinType:  ct,
+		Address:   addr,
+		Amount:    amount,
+		Memo:      FeeSweepMemo,
+	}, false)
+	if err != nil {
+		return "", err
+	}
+
+	sklog.Info(logger, "fee sweep", sklog.Fields{
+		"accountID": self.cfg.FeeAccount,
+		"coin":      ct,
+		"address":   addr,
+		"amount":    amount,
+		"txid":      txid,
+	})
+	return txid, nil
+}
+
+// feeSweepPending checks whether the last fee sweep of coin type ct is not confirmed yet,
+// the sweep is forgotten once it's confirmed.
+func (self *ExchangeServer) feeSweepPending(ct string) bool {
+	self.feeSweepMtx.Lock()
+	txid, ok := self.feeSweeps[ct]
+	self.feeSweepMtx.Unlock()
+	if !ok {
+		return false
+	}
+
+	gateway, err := self.GetCoin(ct)
+	if err != nil {
+		return true
+	}
+
+	// the transaction may not be seen by the node yet.
+	tx, err := gateway.GetTx(txid)
+	if err != nil {
+		logger.Debug("get %s fee sweep tx %s failed: %v", ct, txid, err)
+		return true
+	}
+
+	if !txConfirmed(tx) {
+		return true
+	}
+
+	self.feeSweepMtx.Lock()
+	delete(self.feeSweeps, ct)
+	self.feeSweepMtx.Unlock()
+	return false
+}
+
+// txConfirmed checks whether the bitcoin like or skycoin transaction is in a block.
+func txConfirmed(tx *pp.Tx) bool {
+	if btx := tx.GetBtc(); btx != nil {
+		return btx.GetConfirmations() > 0
+	}
+	return tx.GetSky().GetConfirmed()
+}
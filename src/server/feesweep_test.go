@@ -0,0 +1,135 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const coldAddr = "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+
+// newFeeSweepTestServer makes the server whose fee account is "test", the cold bitcoin
+// address is coldAddr and the sweep threshold is 50000 satoshis.
+func newFeeSweepTestServer(t *testing.T, gw *gatewayMock) (*ExchangeServer, account.Accounter, func()) {
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	s.cfg.FeeAccount = acnt.GetID()
+	s.cfg.FeeSweepAddrs = map[string]string{bitcoin.Type: coldAddr}
+	s.cfg.FeeSweepThresholds = map[string]uint64{bitcoin.Type: 50000}
+
+	// the test account starts with 100000 satoshis.
+	acnt.DecreaseBalance(bitcoin.Type, 100000, account.ReasonAdmin)
+	return s, acnt, teardown
+}
+
+func confirmedTx(n uint64) *pp.Tx {
+	return &pp.Tx{Btc: &pp.BtcTx{Confirmations: pp.PtrUint64(n)}}
+}
+
+func TestSweepFees(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("sweeptxid", nil)
+
+	s, acnt, teardown := newFeeSweepTestServer(t, gw)
+	defer teardown()
+
+	// the balance doesn't exceed the threshold.
+	acnt.IncreaseBalance(bitcoin.Type, 50000, account.ReasonTradeFee)
+	s.sweepFees()
+	gw.AssertNotCalled(t, "InjectTx", mock.Anything)
+
+	// the whole balance minus the withdrawal fee is swept.
+	acnt.IncreaseBalance(bitcoin.Type, 20000, account.ReasonTradeFee)
+	s.sweepFees()
+	gw.AssertNumberOfCalls(t, "InjectTx", 1)
+	txOuts := gw.Calls[0].Arguments.Get(1).([]bitcoin.TxOut)
+	assert.Equal(t, coldAddr, txOuts[0].Addr)
+	assert.Equal(t, uint64(60000), txOuts[0].Value)
+	assert.Equal(t, uint64(0), acnt.GetBalance(bitcoin.Type))
+
+	r, ok := acnt.GetWithdrawalByTxid("sweeptxid")
+	assert.True(t, ok)
+	assert.Equal(t, FeeSweepMemo, r.Memo)
+	assert.Equal(t, uint64(60000), r.Amount)
+
+	// no sweep while the last one is unconfirmed.
+	s.btcum.PutUtxo(bitcoin.BlkExplrUtxo{Txid: "txid2", Vout: 0, Amount: 80000})
+	acnt.IncreaseBalance(bitcoin.Type, 80000, account.ReasonTradeFee)
+	gw.On("GetTx", "sweeptxid").Return(nil, errors.New("tx not found")).Once()
+	s.sweepFees()
+	gw.On("GetTx", "sweeptxid").Return(confirmedTx(0), nil).Once()
+	s.sweepFees()
+	gw.AssertNumberOfCalls(t, "InjectTx", 1)
+	assert.Equal(t, uint64(80000), acnt.GetBalance(bitcoin.Type))
+
+	// swept again after it's confirmed.
+	gw.On("GetTx", "sweeptxid").Return(confirmedTx(1), nil).Once()
+	s.sweepFees()
+	gw.AssertNumberOfCalls(t, "InjectTx", 2)
+	assert.Equal(t, uint64(0), acnt.GetBalance(bitcoin.Type))
+}
+
+func TestSweepFeesFailure(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("", errors.New("broadcast failed")).Once()
+	gw.On("InjectTx", "signedtx").Return("sweeptxid", nil)
+
+	s, acnt, teardown := newFeeSweepTestServer(t, gw)
+	defer teardown()
+
+	// the failed sweep is not pending, it's retried in the next round.
+	acnt.IncreaseBalance(bitcoin.Type, 80000, account.ReasonTradeFee)
+	s.sweepFees()
+	assert.Equal(t, uint64(80000), acnt.GetBalance(bitcoin.Type))
+	assert.Equal(t, uint64(0), acnt.GetReservedBalance(bitcoin.Type))
+
+	s.sweepFees()
+	gw.AssertNumberOfCalls(t, "InjectTx", 2)
+	assert.Equal(t, uint64(0), acnt.GetBalance(bitcoin.Type))
+	gw.AssertNotCalled(t, "GetTx", mock.Anything)
+}
+
+func TestRunFeeSweeper(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("sweeptxid", nil)
+	gw.On("GetTx", "sweeptxid").Return(confirmedTx(0), nil)
+
+	s, acnt, teardown := newFeeSweepTestServer(t, gw)
+	defer teardown()
+	s.cfg.FeeSweepInterval = 10 * time.Millisecond
+	s.btcum.PutUtxo(bitcoin.BlkExplrUtxo{Txid: "txid2", Vout: 0, Amount: 80000})
+
+	run := func(d time.Duration) {
+		closing := make(chan bool)
+		done := make(chan struct{})
+		go func() {
+			s.runFeeSweeper(closing)
+			close(done)
+		}()
+		time.Sleep(d)
+		close(closing)
+		<-done
+	}
+
+	acnt.IncreaseBalance(bitcoin.Type, 80000, account.ReasonTradeFee)
+	run(50 * time.Millisecond)
+	gw.AssertNumberOfCalls(t, "InjectTx", 1)
+	assert.Equal(t, uint64(0), acnt.GetBalance(bitcoin.Type))
+
+	// the fees keep coming, no sweep is made while the last one is unconfirmed.
+	acnt.IncreaseBalance(bitcoin.Type, 80000, account.ReasonTradeFee)
+	run(50 * time.Millisecond)
+	gw.AssertNumberOfCalls(t, "InjectTx", 1)
+	assert.Equal(t, uint64(80000), acnt.GetBalance(bitcoin.Type))
+}
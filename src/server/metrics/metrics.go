@@ -0,0 +1,124 @@
+// Package metrics exposes the prometheus metrics of the exchange server.
+package metrics
+
+import (
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "exchange"
+
+var (
+	// OrdersPlaced counts the orders added to the books, labeled by coin pair and order type.
+	OrdersPlaced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_placed_total",
+		Help:      "Number of the orders placed.",
+	}, []string{"pair", "type"})
+
+	// OrdersMatched counts the fills of the orders, labeled by coin pair and order type.
+	OrdersMatched = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_matched_total",
+		Help:      "Number of the order fills.",
+	}, []string{"pair", "type"})
+
+	// StreamClients the number of websocket clients connected to the order book stream.
+	StreamClients = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "stream_clients",
+		Help:      "Number of the active websocket clients of the order book stream.",
+	})
+
+	pools    = &poolCollector{pools: make(map[string]UtxoPool)}
+	registry = prometheus.NewRegistry()
+)
+
+func init() {
+	registry.MustRegister(
+		OrdersPlaced,
+		OrdersMatched,
+		StreamClients,
+		pools,
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(os.Getpid(), ""))
+}
+
+// UtxoPool the utxo pool whose size is collected, it's implemented by the utxo managers.
+type UtxoPool interface {
+	PoolSize() (size int, available int)
+}
+
+// depositWaiter is implemented by the utxo managers which hold the deposits
+// until they reach the min confirmations.
+type depositWaiter interface {
+	PendingDeposits() int
+}
+
+// SetUtxoPool sets the utxo pool of coin type ct to be collected, the previous one is replaced.
+func SetUtxoPool(ct string, p UtxoPool) {
+	pools.mtx.Lock()
+	pools.pools[ct] = p
+	pools.mtx.Unlock()
+}
+
+// Handler returns the http handler which serves the metrics in prometheus format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// poolCollector collects the utxo pool size, the available utxos and the pending
+// deposits of each coin when scraped.
+type poolCollector struct {
+	mtx   sync.Mutex
+	pools map[string]UtxoPool // key: coin type.
+}
+
+var (
+	poolSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "utxo", "pool_size"),
+		"Capacity of the utxo pool.",
+		[]string{"coin"}, nil)
+	poolAvailableDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "utxo", "pool_available"),
+		"Number of the utxos available in the pool.",
+		[]string{"coin"}, nil)
+	pendingDepositsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "pending_deposits"),
+		"Number of the deposits waiting for the min confirmations.",
+		[]string{"coin"}, nil)
+)
+
+// Describe implements prometheus.Collector.
+func (pc *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolSizeDesc
+	ch <- poolAvailableDesc
+	ch <- pendingDepositsDesc
+}
+
+// Collect implements prometheus.Collector.
+func (pc *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	pc.mtx.Lock()
+	defer pc.mtx.Unlock()
+
+	cts := make([]string, 0, len(pc.pools))
+	for ct := range pc.pools {
+		cts = append(cts, ct)
+	}
+	sort.Strings(cts)
+
+	for _, ct := range cts {
+		p := pc.pools[ct]
+		size, available := p.PoolSize()
+		ch <- prometheus.MustNewConstMetric(poolSizeDesc, prometheus.GaugeValue, float64(size), ct)
+		ch <- prometheus.MustNewConstMetric(poolAvailableDesc, prometheus.GaugeValue, float64(available), ct)
+		if w, ok := p.(depositWaiter); ok {
+			ch <- prometheus.MustNewConstMetric(pendingDepositsDesc, prometheus.GaugeValue, float64(w.PendingDeposits()), ct)
+		}
+	}
+}
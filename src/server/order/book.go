@@ -1,21 +1,63 @@
 package order
 
 import (
+	"errors"
+	"fmt"
 	"sort"
 	"sync"
 )
 
-// order book, which records the bid and ask order list.
+// MaxClosedOrders max number of the recent closed orders kept in each book for looking up,
+// the oldest one is dropped once exceeded.
+var MaxClosedOrders = 1000
+
+// Book records the bid and ask orders, and matches them in price-time priority:
+// bids match highest price first, asks match lowest price first, the orders at the
+// same price match oldest first, and the orders created at the same time match in
+// the order of their ids. The orders of each side are kept in sorted price levels,
+// each level is a FIFO queue of the orders at its price.
 type Book struct {
-	bidOrders []Order
-	askOrders []Order
+	bids      bookSide
+	asks      bookSide
+	minAmount uint64     // orders with amount less than this will be rejected.
+	tickSize  uint64     // prices must be multiples of it, 0 means any price.
+	decimals  uint8      // decimal places of the prices, the value of an order is price*amount/10^decimals.
+	stp       STPMode    // self-trade prevention mode.
+	maxOrders int        // max open orders including the stop orders, 0 means no limit.
+	full      FullPolicy // what happens to the new order when the book holds maxOrders.
+	halted    bool       // new orders are rejected and the matching is suspended.
+	priceBand uint64     // max distance of the limit price from the last trade price in basis points, 0 means no band.
+	stops     []Order    // inactive stop orders in the order of ids.
+	lastPrice uint64     // price of the last trade, for triggering the stop orders.
+	closed    []Order    // recent closed orders, oldest first.
 	bidMtx    sync.Mutex
 	askMtx    sync.Mutex
+	minMtx    sync.Mutex // protects minAmount, tickSize, decimals, stp, maxOrders, full, halted and priceBand.
+	stopMtx   sync.Mutex // protects stops and lastPrice.
+	closedMtx sync.Mutex // protects closed.
 }
 
 type BookJson struct {
-	BidOrders []Order `json:"bids"`
-	AskOrders []Order `json:"asks"`
+	Version    int        `json:"version"` // format version, see BookVersion.
+	BidOrders  []Order    `json:"bids"`
+	AskOrders  []Order    `json:"asks"`
+	StopOrders []Order    `json:"stops,omitempty"`
+	MinAmount  uint64     `json:"min_amount,omitempty"`
+	TickSize   uint64     `json:"tick_size,omitempty"`
+	Decimals   uint8      `json:"price_decimals,omitempty"`
+	LastPrice  uint64     `json:"last_price,omitempty"`
+	STP        STPMode    `json:"stp,omitempty"`
+	MaxOrders  int        `json:"max_orders,omitempty"`
+	FullPolicy FullPolicy `json:"full_policy,omitempty"`
+	Halted     bool       `json:"halted,omitempty"`
+	PriceBand  uint64     `json:"price_band,omitempty"`
+	Closed     []Order    `json:"closed,omitempty"`
+}
+
+// DepthLevel is the total rest amount of the orders at one price level.
+type DepthLevel struct {
+	Price       uint64 `json:"price"`
+	TotalAmount uint64 `json:"total_amount"`
 }
 
 type OrderPair struct {
@@ -25,73 +67,333 @@ type OrderPair struct {
 
 func (bk *Book) AddBid(bid Order) {
 	bk.bidMtx.Lock()
-	bk.bidOrders = append(bk.bidOrders, bid)
-	sort.Sort(byPriceThenTimeDesc(bk.bidOrders))
+	bk.bids.add(Bid, bid)
 	bk.bidMtx.Unlock()
 }
 
 func (bk *Book) AddAsk(ask Order) {
 	bk.askMtx.Lock()
-	bk.askOrders = append(bk.askOrders, ask)
-	sort.Sort(byPriceThenTimeAsc(bk.askOrders))
+	bk.asks.add(Ask, ask)
 	bk.askMtx.Unlock()
 }
 
-func (bk *Book) Copy() Book {
-	newBk := Book{}
+func (bk *Book) Copy() *Book {
+	newBk := &Book{}
 	bk.bidMtx.Lock()
-	newBk.bidOrders = make([]Order, len(bk.bidOrders))
-	copy(newBk.bidOrders, bk.bidOrders)
+	newBk.bids = bk.bids.clone()
 	bk.bidMtx.Unlock()
 
 	bk.askMtx.Lock()
-	newBk.askOrders = make([]Order, len(bk.askOrders))
-	copy(newBk.askOrders, bk.askOrders)
+	newBk.asks = bk.asks.clone()
 	bk.askMtx.Unlock()
+
+	bk.stopMtx.Lock()
+	newBk.stops = append([]Order(nil), bk.stops...)
+	newBk.lastPrice = bk.lastPrice
+	bk.stopMtx.Unlock()
+
+	newBk.minAmount = bk.MinAmount()
+	newBk.tickSize = bk.TickSize()
+	newBk.decimals = bk.PriceDecimals()
+	newBk.stp = bk.SelfTradePrevention()
+	newBk.maxOrders, newBk.full = bk.MaxOrders()
+	newBk.halted = bk.Halted()
+	newBk.priceBand = bk.PriceBand()
+
+	bk.closedMtx.Lock()
+	newBk.closed = append([]Order(nil), bk.closed...)
+	bk.closedMtx.Unlock()
 	return newBk
 }
 
-func (bk *Book) GetOrders(tp Type, start, end int64) []Order {
-	return bk.copyOrders(tp, start, end)
+// AddStop adds the inactive stop order, it's activated by TriggerStops.
+func (bk *Book) AddStop(od Order) {
+	bk.stopMtx.Lock()
+	bk.stops = append(bk.stops, od)
+	bk.stopMtx.Unlock()
 }
 
-// copy orders of specific type from start index to end.
-func (bk *Book) copyOrders(tp Type, start, end int64) []Order {
-	switch tp {
-	case Bid:
-		bk.bidMtx.Lock()
-		defer bk.bidMtx.Unlock()
-		bidLen := int64(len(bk.bidOrders))
-		if end > bidLen {
-			end = bidLen
-		}
+// Stops returns the inactive stop orders.
+func (bk *Book) Stops() []Order {
+	bk.stopMtx.Lock()
+	defer bk.stopMtx.Unlock()
+	return append([]Order{}, bk.stops...)
+}
 
-		var l int64 = 0
-		if start < end {
-			l = end - start
+// LastPrice returns the price of the last trade, zero if there's no trade yet.
+func (bk *Book) LastPrice() uint64 {
+	bk.stopMtx.Lock()
+	defer bk.stopMtx.Unlock()
+	return bk.lastPrice
+}
+
+// setLastPrice records the price of the latest trade.
+func (bk *Book) setLastPrice(price uint64) {
+	bk.stopMtx.Lock()
+	bk.lastPrice = price
+	bk.stopMtx.Unlock()
+}
+
+// TriggerStops removes the stop orders triggered by the last trade price, and returns
+// them in the order they were placed.
+func (bk *Book) TriggerStops() []Order {
+	bk.stopMtx.Lock()
+	defer bk.stopMtx.Unlock()
+
+	triggered := []Order{}
+	stops := bk.stops[:0]
+	for _, od := range bk.stops {
+		if od.isTriggered(bk.lastPrice) {
+			triggered = append(triggered, od)
+		} else {
+			stops = append(stops, od)
 		}
-		orders := make([]Order, 0, l)
-		for i := start; i < end; i++ {
-			orders = append(orders, bk.bidOrders[i])
+	}
+	bk.stops = stops
+	return triggered
+}
+
+// SetMinAmount sets the minimum amount of orders in this book.
+func (bk *Book) SetMinAmount(amt uint64) {
+	bk.minMtx.Lock()
+	bk.minAmount = amt
+	bk.minMtx.Unlock()
+}
+
+// MinAmount returns the minimum amount of orders in this book.
+func (bk *Book) MinAmount() uint64 {
+	bk.minMtx.Lock()
+	defer bk.minMtx.Unlock()
+	return bk.minAmount
+}
+
+// SetTickSize sets the tick size of this book, the prices of new orders must be multiples
+// of it, 0 means any price. The orders already in the book are kept.
+func (bk *Book) SetTickSize(tick uint64) {
+	bk.minMtx.Lock()
+	bk.tickSize = tick
+	bk.minMtx.Unlock()
+}
+
+// TickSize returns the tick size of this book.
+func (bk *Book) TickSize() uint64 {
+	bk.minMtx.Lock()
+	defer bk.minMtx.Unlock()
+	return bk.tickSize
+}
+
+// SetPriceDecimals sets the decimal places of the prices in this book, the value of an order
+// is price*amount/10^decimals. It can only be changed while the book holds no orders, since
+// the balances of the open orders were reserved with the old decimals.
+func (bk *Book) SetPriceDecimals(decimals uint8) error {
+	if decimals > MaxPriceDecimals {
+		return fmt.Errorf("price decimals %d exceeds %d", decimals, MaxPriceDecimals)
+	}
+
+	bk.bidMtx.Lock()
+	bk.askMtx.Lock()
+	bk.stopMtx.Lock()
+	defer func() {
+		bk.stopMtx.Unlock()
+		bk.askMtx.Unlock()
+		bk.bidMtx.Unlock()
+	}()
+
+	if bk.bids.len()+bk.asks.len()+len(bk.stops) > 0 {
+		return errors.New("price decimals can't be changed while the book holds orders")
+	}
+
+	bk.minMtx.Lock()
+	bk.decimals = decimals
+	bk.minMtx.Unlock()
+	return nil
+}
+
+// PriceDecimals returns the decimal places of the prices in this book.
+func (bk *Book) PriceDecimals() uint8 {
+	bk.minMtx.Lock()
+	defer bk.minMtx.Unlock()
+	return bk.decimals
+}
+
+// SetSelfTradePrevention sets the self-trade prevention mode of this book.
+func (bk *Book) SetSelfTradePrevention(mode STPMode) {
+	bk.minMtx.Lock()
+	bk.stp = mode
+	bk.minMtx.Unlock()
+}
+
+// SelfTradePrevention returns the self-trade prevention mode of this book.
+func (bk *Book) SelfTradePrevention() STPMode {
+	bk.minMtx.Lock()
+	defer bk.minMtx.Unlock()
+	return bk.stp
+}
+
+// SetMaxOrders sets the max open orders of this book and the policy for the new order
+// when it's full, 0 means no limit. The orders already in the book are kept.
+func (bk *Book) SetMaxOrders(max int, policy FullPolicy) {
+	bk.minMtx.Lock()
+	bk.maxOrders = max
+	bk.full = policy
+	bk.minMtx.Unlock()
+}
+
+// MaxOrders returns the max open orders of this book and the policy when it's full.
+func (bk *Book) MaxOrders() (int, FullPolicy) {
+	bk.minMtx.Lock()
+	defer bk.minMtx.Unlock()
+	return bk.maxOrders, bk.full
+}
+
+// SetHalted halts or resumes the trading of this book, the orders in the book are kept.
+func (bk *Book) SetHalted(halted bool) {
+	bk.minMtx.Lock()
+	bk.halted = halted
+	bk.minMtx.Unlock()
+}
+
+// Halted returns true if the trading of this book is halted.
+func (bk *Book) Halted() bool {
+	bk.minMtx.Lock()
+	defer bk.minMtx.Unlock()
+	return bk.halted
+}
+
+// SetPriceBand sets the price band of this book in basis points, the limit orders priced further
+// than it from the last trade price are rejected, 0 means no band.
+func (bk *Book) SetPriceBand(bps uint64) {
+	bk.minMtx.Lock()
+	bk.priceBand = bps
+	bk.minMtx.Unlock()
+}
+
+// PriceBand returns the price band of this book in basis points.
+func (bk *Book) PriceBand() uint64 {
+	bk.minMtx.Lock()
+	defer bk.minMtx.Unlock()
+	return bk.priceBand
+}
+
+// Len returns the number of open orders in this book, including the inactive stop orders.
+func (bk *Book) Len() int {
+	bk.bidMtx.Lock()
+	bk.askMtx.Lock()
+	bk.stopMtx.Lock()
+	defer func() {
+		bk.stopMtx.Unlock()
+		bk.askMtx.Unlock()
+		bk.bidMtx.Unlock()
+	}()
+	return bk.bids.len() + bk.asks.len() + len(bk.stops)
+}
+
+// addLimited adds the resting or stop order if the book holds less than its max orders,
+// otherwise the order evicts the worst-priced order by FullEvictWorst policy, or is rejected
+// with ErrBookFull. The evicted orders are returned for closing.
+func (bk *Book) addLimited(od Order) ([]Order, error) {
+	max, policy := bk.MaxOrders()
+	bk.bidMtx.Lock()
+	bk.askMtx.Lock()
+	bk.stopMtx.Lock()
+	defer func() {
+		bk.stopMtx.Unlock()
+		bk.askMtx.Unlock()
+		bk.bidMtx.Unlock()
+	}()
+
+	var evicted []Order
+	if max > 0 && bk.bids.len()+bk.asks.len()+len(bk.stops) >= max {
+		if policy != FullEvictWorst || od.IsStop() {
+			return nil, ErrBookFull
 		}
-		return orders
-	case Ask:
-		bk.askMtx.Lock()
-		defer bk.askMtx.Unlock()
-		bidLen := int64(len(bk.askOrders))
-		if end > bidLen {
-			end = bidLen
+
+		side, opposite := &bk.bids, &bk.asks
+		if od.Type == Ask {
+			side, opposite = opposite, side
 		}
 
-		var l int64 = 0
-		if start < end {
-			l = end - start
+		switch {
+		case side.len() > 0:
+			// the order at the back is the worst-priced and the newest.
+			i := len(side.levels) - 1
+			j := len(side.levels[i].orders) - 1
+			if !better(od.Type, od.Price, side.levels[i].price) {
+				return nil, ErrBookFull
+			}
+			evicted = append(evicted, side.levels[i].orders[j])
+			side.removeAt(i, j)
+		case opposite.len() > 0:
+			i := len(opposite.levels) - 1
+			j := len(opposite.levels[i].orders) - 1
+			evicted = append(evicted, opposite.levels[i].orders[j])
+			opposite.removeAt(i, j)
+		default:
+			// the book is full of stop orders.
+			return nil, ErrBookFull
 		}
-		orders := make([]Order, 0, l)
-		for i := start; i < end; i++ {
-			orders = append(orders, bk.askOrders[i])
+	}
+
+	switch {
+	case od.IsStop():
+		bk.stops = append(bk.stops, od)
+	case od.Type == Bid:
+		bk.bids.add(Bid, od)
+	default:
+		bk.asks.add(Ask, od)
+	}
+	return evicted, nil
+}
+
+// GetOrders returns the page of orders of specific type in priority order, start and end are
+// the indexes of the first and the last + 1 order, the out of range indexes are clamped.
+// The total number of orders is also returned for paging.
+func (bk *Book) GetOrders(tp Type, start, end int64) ([]Order, int, error) {
+	if start > end {
+		return []Order{}, 0, fmt.Errorf("start index %d is greater than end index %d", start, end)
+	}
+
+	orders := bk.copyOrders(tp)
+	total := int64(len(orders))
+	if start < 0 {
+		start = 0
+	}
+	if end > total {
+		end = total
+	}
+	if start >= end {
+		return []Order{}, len(orders), nil
+	}
+	return orders[start:end], len(orders), nil
+}
+
+// GetOrdersByTime returns the orders of specific type created in the time range [start, end)
+// in priority order, the time is unix seconds.
+func (bk *Book) GetOrdersByTime(tp Type, start, end int64) ([]Order, error) {
+	if start > end {
+		return []Order{}, fmt.Errorf("start time %d is greater than end time %d", start, end)
+	}
+
+	orders := []Order{}
+	for _, od := range bk.copyOrders(tp) {
+		if od.CreatedAt >= start && od.CreatedAt < end {
+			orders = append(orders, od)
 		}
-		return orders
+	}
+	return orders, nil
+}
+
+// copy all the orders of specific type.
+func (bk *Book) copyOrders(tp Type) []Order {
+	switch tp {
+	case Bid:
+		bk.bidMtx.Lock()
+		defer bk.bidMtx.Unlock()
+		return bk.bids.orders()
+	case Ask:
+		bk.askMtx.Lock()
+		defer bk.askMtx.Unlock()
+		return bk.asks.orders()
 	default:
 		return []Order{}
 	}
@@ -127,114 +429,469 @@ func (bk *Book) copyOrders(tp Type, start, end int64) []Order {
 // 	return newBk, nil
 // }
 
-func (bk Book) getMaxOrderID() uint64 {
+// Cancel removes the order of specific id from book, the order must belong to the account.
+// The inactive stop orders can be cancelled too.
+func (bk *Book) Cancel(id uint64, aid string) (Order, error) {
+	bk.bidMtx.Lock()
+	bk.askMtx.Lock()
+	bk.stopMtx.Lock()
+	defer func() {
+		bk.stopMtx.Unlock()
+		bk.askMtx.Unlock()
+		bk.bidMtx.Unlock()
+	}()
+
+	for i, od := range bk.stops {
+		if od.ID != id {
+			continue
+		}
+		if od.AccountID != aid {
+			return Order{}, ErrNotOrderOwner
+		}
+		bk.stops = append(bk.stops[:i], bk.stops[i+1:]...)
+		return od, nil
+	}
+
+	for _, side := range []*bookSide{&bk.bids, &bk.asks} {
+		i, j, ok := side.find(id)
+		if !ok {
+			continue
+		}
+		od := side.levels[i].orders[j]
+		if od.AccountID != aid {
+			return Order{}, ErrNotOrderOwner
+		}
+		side.removeAt(i, j)
+		return od, nil
+	}
+	return Order{}, ErrOrderNotExist
+}
+
+// GetOrder returns the open order of specific id, including the inactive stop order,
+// or the recent closed one. Returns ErrOrderNotFound if it's not found.
+func (bk *Book) GetOrder(id uint64) (Order, error) {
+	bk.bidMtx.Lock()
+	bk.askMtx.Lock()
+	bk.stopMtx.Lock()
+	od, ok := bk.findOpen(id)
+	bk.stopMtx.Unlock()
+	bk.askMtx.Unlock()
+	bk.bidMtx.Unlock()
+	if ok {
+		return od, nil
+	}
+
+	bk.closedMtx.Lock()
+	defer bk.closedMtx.Unlock()
+	for i := len(bk.closed) - 1; i >= 0; i-- {
+		if bk.closed[i].ID == id {
+			return bk.closed[i], nil
+		}
+	}
+	return Order{}, ErrOrderNotFound
+}
+
+// findOpen finds the open order of specific id, the bid, ask and stop mutexes must be held.
+func (bk *Book) findOpen(id uint64) (Order, bool) {
+	for _, od := range bk.stops {
+		if od.ID == id {
+			return od, true
+		}
+	}
+
+	for _, side := range []*bookSide{&bk.bids, &bk.asks} {
+		if i, j, ok := side.find(id); ok {
+			return side.levels[i].orders[j], true
+		}
+	}
+	return Order{}, false
+}
+
+// addClosed records the orders that left the book, only the recent MaxClosedOrders are kept.
+func (bk *Book) addClosed(ods ...Order) {
+	if len(ods) == 0 {
+		return
+	}
+
+	bk.closedMtx.Lock()
+	defer bk.closedMtx.Unlock()
+	for _, od := range ods {
+		od.Closed = true
+		bk.closed = append(bk.closed, od)
+	}
+	if n := len(bk.closed) - MaxClosedOrders; n > 0 {
+		bk.closed = append([]Order(nil), bk.closed[n:]...)
+	}
+}
+
+// closedOrders returns the orders closed by the fills, which are fully filled, or
+// closed by the fill of zero amount.
+func closedOrders(fills []Fill) []Order {
+	ods := []Order{}
+	for _, f := range fills {
+		if f.Amount == 0 || f.Order.RestAmt == 0 {
+			ods = append(ods, f.Order)
+		}
+	}
+	return ods
+}
+
+// HasOrders checks if the account has open orders in the book, including the stop orders.
+func (bk *Book) HasOrders(aid string) bool {
+	bk.bidMtx.Lock()
+	bk.askMtx.Lock()
+	bk.stopMtx.Lock()
+	defer func() {
+		bk.stopMtx.Unlock()
+		bk.askMtx.Unlock()
+		bk.bidMtx.Unlock()
+	}()
+
+	for _, od := range bk.stops {
+		if od.AccountID == aid {
+			return true
+		}
+	}
+	return bk.bids.hasAccount(aid) || bk.asks.hasAccount(aid)
+}
+
+// AccountOrders returns the open orders of the account, including the inactive stop orders,
+// the stop orders come first, then the bids and asks in priority order.
+func (bk *Book) AccountOrders(aid string) []Order {
+	bk.bidMtx.Lock()
+	bk.askMtx.Lock()
+	bk.stopMtx.Lock()
+	defer func() {
+		bk.stopMtx.Unlock()
+		bk.askMtx.Unlock()
+		bk.bidMtx.Unlock()
+	}()
+
+	ods := []Order{}
+	for _, od := range bk.stops {
+		if od.AccountID == aid {
+			ods = append(ods, od)
+		}
+	}
+	ods = append(ods, bk.bids.accountOrders(Bid, aid)...)
+	return append(ods, bk.asks.accountOrders(Ask, aid)...)
+}
+
+// AccountClosedOrders returns the recent closed orders of the account kept by the book, the
+// latest closed first.
+func (bk *Book) AccountClosedOrders(aid string) []Order {
+	bk.closedMtx.Lock()
+	defer bk.closedMtx.Unlock()
+	ods := []Order{}
+	for i := len(bk.closed) - 1; i >= 0; i-- {
+		if bk.closed[i].AccountID == aid {
+			ods = append(ods, bk.closed[i])
+		}
+	}
+	return ods
+}
+
+func (bk *Book) getMaxOrderID() uint64 {
 	// sort the book with priority of order id.
-	orders := append(bk.bidOrders, bk.askOrders...)
+	orders := append(bk.bids.orders(), bk.asks.orders()...)
 	sort.Sort(byOrderID(orders))
 	return orders[0].ID
 }
 
-// Match check if there're bids and asks are matched,
-// if matched, remove from the order book, and return the orders for
-// further use.
-func (bk *Book) Match() []Order {
+// Match matches the bids against the asks in price-time priority, each match
+// produces a fill for both sides with the executed amount at the maker's price.
+// Orders that are partially filled stay in the book with their RestAmt decreased,
+// fully filled orders are removed. The crossing orders of the same account are
+// handled by the self-trade prevention mode, the cancelled one is closed.
+func (bk *Book) Match() []Fill {
+	stp := bk.SelfTradePrevention()
 	bk.bidMtx.Lock()
 	bk.askMtx.Lock()
-	if len(bk.bidOrders) == 0 || len(bk.askOrders) == 0 {
+	defer func() {
 		bk.askMtx.Unlock()
 		bk.bidMtx.Unlock()
-		return []Order{}
+	}()
+
+	fills := []Fill{}
+	for bk.bids.len() > 0 && bk.asks.len() > 0 {
+		bid := bk.bids.front()
+		ask := bk.asks.front()
+		// the highest buy price < the lowest sell price, no order match.
+		if bid.Price < ask.Price {
+			break
+		}
+
+		if stp != STPNone && bid.AccountID == ask.AccountID {
+			cancelBid := isLater(*bid, *ask)
+			if stp == STPCancelOldest {
+				cancelBid = !cancelBid
+			}
+			if cancelBid {
+				fills = append(fills, Fill{Order: *bid})
+				bk.bids.popFront()
+			} else {
+				fills = append(fills, Fill{Order: *ask})
+				bk.asks.popFront()
+			}
+			continue
+		}
+
+		amt := bid.RestAmt
+		if ask.RestAmt < amt {
+			amt = ask.RestAmt
+		}
+		bid.RestAmt -= amt
+		ask.RestAmt -= amt
+		// the later one of the two orders is the taker, the trade is executed at the maker's
+		// price for both sides.
+		bidTaker := isLater(*bid, *ask)
+		price := bid.Price
+		if bidTaker {
+			price = ask.Price
+		}
+		fills = append(fills,
+			Fill{Order: *bid, Amount: amt, Price: price, Counter: *ask, Taker: bidTaker},
+			Fill{Order: *ask, Amount: amt, Price: price, Counter: *bid, Taker: !bidTaker})
+		bk.setLastPrice(price)
+
+		// remove fullfilled orders from book.
+		if bid.RestAmt == 0 {
+			bk.bids.popFront()
+		}
+		if ask.RestAmt == 0 {
+			bk.asks.popFront()
+		}
+	}
+	return fills
+}
+
+// MatchMarket executes the market order against the best opposite orders
+// until its amount is satisfied or the opposite side is empty, the price of
+// market order is ignored, each fill is executed at the resting order's price.
+// Returns error if there's no opposite order in the book.
+func (bk *Book) MatchMarket(od Order) ([]Fill, error) {
+	orders, unlock, err := bk.lockOpposite(od.Type)
+	if err != nil {
+		return []Fill{}, err
+	}
+	defer unlock()
+
+	if orders.len() == 0 {
+		return []Fill{}, fmt.Errorf("no %s orders for market %s order", oppositeType(od.Type), od.Type)
+	}
+	fills := matchImmediate(orders, od, false, bk.SelfTradePrevention(), bk.PriceDecimals())
+	bk.updateLastPrice(fills)
+	return fills, nil
+}
+
+// MatchIOC executes the IOC limit order against the opposite orders of acceptable
+// price, each fill is executed at the resting order's price, and the unfilled amount is closed.
+func (bk *Book) MatchIOC(od Order) ([]Fill, error) {
+	orders, unlock, err := bk.lockOpposite(od.Type)
+	if err != nil {
+		return []Fill{}, err
+	}
+	defer unlock()
+	fills := matchImmediate(orders, od, true, bk.SelfTradePrevention(), bk.PriceDecimals())
+	bk.updateLastPrice(fills)
+	return fills, nil
+}
+
+// updateLastPrice records the price of the last trade in the fills of immediate order,
+// which is executed at the maker's price.
+func (bk *Book) updateLastPrice(fills []Fill) {
+	for i := len(fills) - 1; i >= 0; i-- {
+		if fills[i].Taker && fills[i].Amount > 0 {
+			bk.setLastPrice(fills[i].Counter.Price)
+			return
+		}
 	}
+}
 
-	// the highest buy price < the lowest sell price, no order match.
-	if bk.bidOrders[0].Price < bk.askOrders[0].Price {
+// RemoveExpired removes the GTD orders that are expired at unix time now, and returns them.
+func (bk *Book) RemoveExpired(now int64) []Order {
+	bk.bidMtx.Lock()
+	bk.askMtx.Lock()
+	defer func() {
 		bk.askMtx.Unlock()
 		bk.bidMtx.Unlock()
-		return []Order{}
+	}()
+
+	expired := []Order{}
+	for _, side := range []*bookSide{&bk.bids, &bk.asks} {
+		expired = append(expired, side.removeIf(func(od Order) bool {
+			return od.isExpired(now)
+		})...)
 	}
+	return expired
+}
 
-	// var bidIndex, askIndex int = 0, 0
-	var bidOrders []Order
-	var askOrders []Order
+// lockOpposite locks and returns the orders that can match the specific type.
+func (bk *Book) lockOpposite(tp Type) (*bookSide, func(), error) {
+	switch tp {
+	case Bid:
+		bk.askMtx.Lock()
+		return &bk.asks, bk.askMtx.Unlock, nil
+	case Ask:
+		bk.bidMtx.Lock()
+		return &bk.bids, bk.bidMtx.Unlock, nil
+	default:
+		return nil, nil, errors.New("unknow order type")
+	}
+}
 
-	for i, bid := range bk.bidOrders {
-		restAmt, askOrderNum := checkAskOrders(bid, &bk.askOrders)
-		if restAmt == bid.Amount {
-			// no ask was matched
+// matchImmediate matches the order against the opposite orders in priority, the order
+// never rests in the book, its unfilled amount is closed. If limit is true, only
+// the orders of acceptable price are matched. Both sides are executed at the resting
+// order's price, as the resting one is the maker. The order is always the newest one
+// when it meets the resting order of the same account. The market bid of Cost is only
+// filled as much as the Cost can pay at the prices of decimals places.
+func matchImmediate(orders *bookSide, od Order, limit bool, stp STPMode, decimals uint8) []Fill {
+	fills := []Fill{}
+	for od.RestAmt > 0 && orders.len() > 0 {
+		rest := orders.front()
+		if limit && !priceAcceptable(od, *rest) {
 			break
 		}
 
-		bk.bidOrders[i].RestAmt = restAmt
+		if stp != STPNone && rest.AccountID == od.AccountID {
+			if stp != STPCancelOldest {
+				break
+			}
+			fills = append(fills, Fill{Order: *rest})
+			orders.popFront()
+			continue
+		}
 
-		// append fullfilled ask orders
-		askOrders = append(askOrders, bk.askOrders[:askOrderNum]...)
-		// remove fullfilled ask orders from book.
-		bk.askOrders = bk.askOrders[askOrderNum:]
+		amt := od.RestAmt
+		if rest.RestAmt < amt {
+			amt = rest.RestAmt
+		}
+		if od.Kind == Market && od.Type == Bid && od.Cost > 0 {
+			if n := MaxAmount(od.Cost, rest.Price, decimals); n < amt {
+				amt = n
+			}
+			if amt == 0 {
+				break
+			}
+			// within the cost, so it can't overflow.
+			v, _ := Value(rest.Price, amt, decimals, RoundUp)
+			od.Cost -= v
+		}
+		od.RestAmt -= amt
+		rest.RestAmt -= amt
 
-		if restAmt == 0 {
-			bidOrders = append(bidOrders, bk.bidOrders[i])
-		} else if restAmt > 0 {
-			break
+		fills = append(fills,
+			Fill{Order: od, Amount: amt, Price: rest.Price, Counter: *rest, Taker: true},
+			Fill{Order: *rest, Amount: amt, Price: rest.Price, Counter: od})
+		if rest.RestAmt == 0 {
+			orders.popFront()
 		}
 	}
 
-	bk.bidOrders = bk.bidOrders[len(bidOrders):]
-	bk.askMtx.Unlock()
-	bk.bidMtx.Unlock()
+	// close the order, the rest amount is dropped.
+	if od.RestAmt > 0 {
+		fills = append(fills, Fill{Order: od})
+	}
+	return fills
+}
 
-	return append(bidOrders, askOrders...)
+// crossesOwn checks whether the order would match the order of its own account, the
+// opposite orders are walked in priority until the amount of the order is satisfied.
+func (bk *Book) crossesOwn(od Order) bool {
+	orders, unlock, err := bk.lockOpposite(od.Type)
+	if err != nil {
+		return false
+	}
+	defer unlock()
+
+	amt := od.RestAmt
+	for _, lv := range orders.levels {
+		for _, rest := range lv.orders {
+			if amt == 0 || (od.Kind == Limit && !priceAcceptable(od, rest)) {
+				return false
+			}
+			if rest.AccountID == od.AccountID {
+				return true
+			}
+			if rest.RestAmt >= amt {
+				amt = 0
+			} else {
+				amt -= rest.RestAmt
+			}
+		}
+	}
+	return false
 }
 
-func (bk Book) ToMarshalable() BookJson {
-	bj := BookJson{
-		BidOrders: make([]Order, len(bk.bidOrders)),
-		AskOrders: make([]Order, len(bk.askOrders)),
+// priceAcceptable checks whether the limit order can be matched with the resting order.
+func priceAcceptable(od, rest Order) bool {
+	if od.Type == Bid {
+		return od.Price >= rest.Price
 	}
+	return od.Price <= rest.Price
+}
 
-	copy(bj.BidOrders, bk.bidOrders)
-	copy(bj.AskOrders, bk.askOrders)
-	return bj
+func (bk *Book) ToMarshalable() BookJson {
+	return BookJson{
+		Version:    BookVersion,
+		BidOrders:  bk.bids.orders(),
+		AskOrders:  bk.asks.orders(),
+		StopOrders: bk.stops,
+		MinAmount:  bk.minAmount,
+		TickSize:   bk.tickSize,
+		Decimals:   bk.decimals,
+		LastPrice:  bk.lastPrice,
+		STP:        bk.stp,
+		MaxOrders:  bk.maxOrders,
+		FullPolicy: bk.full,
+		Halted:     bk.halted,
+		PriceBand:  bk.priceBand,
+		Closed:     bk.closed,
+	}
 }
 
+// NewBookFromJson creates the book of the saved orders, they are sorted in priority again.
 func NewBookFromJson(bj BookJson) *Book {
 	bk := &Book{
-		bidOrders: make([]Order, len(bj.BidOrders)),
-		askOrders: make([]Order, len(bj.AskOrders)),
+		minAmount: bj.MinAmount,
+		tickSize:  bj.TickSize,
+		decimals:  bj.Decimals,
+		stops:     bj.StopOrders,
+		lastPrice: bj.LastPrice,
+		stp:       bj.STP,
+		maxOrders: bj.MaxOrders,
+		full:      bj.FullPolicy,
+		halted:    bj.Halted,
+		priceBand: bj.PriceBand,
+		closed:    bj.Closed,
+	}
+	for _, od := range bj.BidOrders {
+		bk.bids.add(Bid, od)
+	}
+	for _, od := range bj.AskOrders {
+		bk.asks.add(Ask, od)
 	}
-
-	copy(bk.bidOrders, bj.BidOrders)
-	copy(bk.askOrders, bj.AskOrders)
 	return bk
 }
 
-// checkAskOrders check if there're asks that can match the bid order,
-// return value:
-// 				first: the reset bid amount that not matched.
-// 				second: ask orders number that has been used.
-func checkAskOrders(bid Order, askOrders *[]Order) (uint64, uint64) {
-	if bid.RestAmt == 0 {
-		panic("the bid amount already fullfilled")
-	}
+// Depth aggregates the rest amount of orders by price level, bids are in descending
+// price order, asks are in ascending price order, each side has at most levels entries.
+func (bk *Book) Depth(levels int) (bids []DepthLevel, asks []DepthLevel) {
+	bk.bidMtx.Lock()
+	bids = bk.bids.depth(levels)
+	bk.bidMtx.Unlock()
 
-	var askNum uint64
-	for i, ask := range *askOrders {
-		// if ask.RestAmt > 0 {
-		if bid.Price < ask.Price {
-			return bid.RestAmt, askNum
-		}
+	bk.askMtx.Lock()
+	asks = bk.asks.depth(levels)
+	bk.askMtx.Unlock()
+	return
+}
 
-		if bid.RestAmt < ask.RestAmt {
-			(*askOrders)[i].RestAmt -= bid.RestAmt
-			return 0, 0
-		} else if bid.RestAmt == ask.RestAmt {
-			(*askOrders)[i].RestAmt = 0
-			askNum++
-			return 0, askNum
-		} else if bid.RestAmt > ask.RestAmt {
-			bid.RestAmt -= ask.RestAmt
-			(*askOrders)[i].RestAmt = 0
-			askNum++
-		}
+// isLater checks whether order a is placed after order b.
+func isLater(a, b Order) bool {
+	if a.CreatedAt != b.CreatedAt {
+		return a.CreatedAt > b.CreatedAt
 	}
-	return bid.RestAmt, askNum
+	return a.ID > b.ID
 }
@@ -33,15 +33,17 @@ func TestBook(t *testing.T) {
 		bk.AddAsk(ask)
 	}
 
-	if bk.bidOrders[0].Price < bk.bidOrders[1].Price {
+	bids := bk.bids.orders()
+	asks := bk.asks.orders()
+	if bids[0].Price < bids[1].Price {
 		t.Fatal("bid price not sorted")
 	}
 
-	if bk.askOrders[0].Price > bk.askOrders[1].Price {
+	if asks[0].Price > asks[1].Price {
 		t.Fatal("ask price not sorted")
 	}
 
-	if bk.askOrders[3].CreatedAt < bk.askOrders[4].CreatedAt {
+	if asks[3].CreatedAt > asks[4].CreatedAt {
 		t.Fatal("ask create time not sorted")
 	}
 }
@@ -80,6 +82,30 @@ func TestMatch(t *testing.T) {
 	assert.Equal(t, len(ods), 6)
 }
 
+func TestMatchMakerPrice(t *testing.T) {
+	// the later bid takes the resting ask, both sides are filled at the ask price.
+	bk := Book{}
+	bk.AddAsk(Order{ID: 1, Type: Ask, Price: 100, CreatedAt: 1, Amount: 2, RestAmt: 2})
+	bk.AddBid(Order{ID: 2, Type: Bid, Price: 105, CreatedAt: 2, Amount: 2, RestAmt: 2})
+	fills := bk.Match()
+	assert.Equal(t, 2, len(fills))
+	for _, f := range fills {
+		assert.Equal(t, uint64(100), f.Price)
+	}
+	assert.Equal(t, uint64(100), bk.LastPrice())
+
+	// the later ask takes the resting bid, both sides are filled at the bid price.
+	bk = Book{}
+	bk.AddBid(Order{ID: 1, Type: Bid, Price: 105, CreatedAt: 1, Amount: 2, RestAmt: 2})
+	bk.AddAsk(Order{ID: 2, Type: Ask, Price: 100, CreatedAt: 2, Amount: 2, RestAmt: 2})
+	fills = bk.Match()
+	assert.Equal(t, 2, len(fills))
+	for _, f := range fills {
+		assert.Equal(t, uint64(105), f.Price)
+	}
+	assert.Equal(t, uint64(105), bk.LastPrice())
+}
+
 // none match
 func TestNoneMatch(t *testing.T) {
 	var BidOrderList = []Order{
@@ -135,7 +161,7 @@ func TestMatchZero2N(t *testing.T) {
 	// for _, od := range ods {
 	// 	fmt.Printf("type:%v, price:%d, amount:%d\n", od.Type, od.Price, od.Amount)
 	// }
-	assert.Equal(t, len(ods), 5)
+	assert.Equal(t, len(ods), 10)
 }
 
 // one bid match n asks.
@@ -170,7 +196,7 @@ func TestMatchOne2N(t *testing.T) {
 	// 	fmt.Printf("type:%v, price:%d, amount:%d\n", od.Type, od.Price, od.Amount)
 	// }
 	// fmt.Println("len(ods):", len(ods))
-	assert.Equal(t, len(ods), 6)
+	assert.Equal(t, len(ods), 8)
 }
 
 // n bid match one asks.
@@ -205,7 +231,7 @@ func TestMatchN2One(t *testing.T) {
 	// 	fmt.Printf("type:%v, price:%d, amount:%d\n", od.Type, od.Price, od.Amount)
 	// }
 	// fmt.Println("len(ods):", len(ods))
-	assert.Equal(t, len(ods), 5)
+	assert.Equal(t, len(ods), 8)
 }
 
 // n bid match n asks.
@@ -241,7 +267,7 @@ func TestMatchN2N(t *testing.T) {
 	// 	fmt.Printf("type:%v, price:%d, amount:%d\n", od.Type, od.Price, od.Amount)
 	// }
 	// fmt.Println("len(ods):", len(ods))
-	assert.Equal(t, len(ods), 6)
+	assert.Equal(t, len(ods), 8)
 }
 
 // zero bid and ask
@@ -267,6 +293,202 @@ func TestMatchZero(t *testing.T) {
 	assert.Equal(t, len(ods), 0)
 }
 
+// one large bid partially filled by several small asks.
+func TestMatchPartialFill(t *testing.T) {
+	var AskOrderList = []Order{
+		Order{ID: 1, Type: Ask, Price: 100, CreatedAt: 132424, Amount: 2, RestAmt: 2},
+		Order{ID: 2, Type: Ask, Price: 101, CreatedAt: 132425, Amount: 3, RestAmt: 3},
+		Order{ID: 3, Type: Ask, Price: 102, CreatedAt: 132426, Amount: 1, RestAmt: 1},
+		Order{ID: 4, Type: Ask, Price: 105, CreatedAt: 132427, Amount: 1, RestAmt: 1},
+	}
+
+	bk := Book{}
+	for _, ask := range AskOrderList {
+		bk.AddAsk(ask)
+	}
+	bk.AddBid(Order{ID: 5, Type: Bid, Price: 102, CreatedAt: 132428, Amount: 10, RestAmt: 10})
+
+	fills := bk.Match()
+	expect := []struct {
+		id     uint64
+		amount uint64
+		rest   uint64
+	}{
+		{5, 2, 8},
+		{1, 2, 0},
+		{5, 3, 5},
+		{2, 3, 0},
+		{5, 1, 4},
+		{3, 1, 0},
+	}
+	assert.Equal(t, len(expect), len(fills))
+	for i, e := range expect {
+		assert.Equal(t, e.id, fills[i].Order.ID)
+		assert.Equal(t, e.amount, fills[i].Amount)
+		assert.Equal(t, e.rest, fills[i].Order.RestAmt)
+	}
+
+	// the rest of bid stays in book.
+	bids := ordersOf(&bk, Bid)
+	assert.Equal(t, 1, len(bids))
+	assert.Equal(t, uint64(4), bids[0].RestAmt)
+	assert.Equal(t, uint64(10), bids[0].Amount)
+
+	asks := ordersOf(&bk, Ask)
+	assert.Equal(t, 1, len(asks))
+	assert.Equal(t, uint64(4), asks[0].ID)
+
+	// new ask fills the rest of bid.
+	bk.AddAsk(Order{ID: 6, Type: Ask, Price: 100, CreatedAt: 132429, Amount: 6, RestAmt: 6})
+	fills = bk.Match()
+	assert.Equal(t, 2, len(fills))
+	assert.Equal(t, uint64(4), fills[0].Amount)
+	assert.Equal(t, uint64(0), fills[0].Order.RestAmt)
+	assert.Equal(t, uint64(2), fills[1].Order.RestAmt)
+	assert.Equal(t, 0, len(ordersOf(&bk, Bid)))
+	assert.Equal(t, 2, len(ordersOf(&bk, Ask)))
+}
+
+// market buy sweeps multiple price levels.
+func TestMatchMarketBid(t *testing.T) {
+	var AskOrderList = []Order{
+		Order{ID: 1, Type: Ask, Price: 100, CreatedAt: 132424, Amount: 2, RestAmt: 2},
+		Order{ID: 2, Type: Ask, Price: 101, CreatedAt: 132425, Amount: 3, RestAmt: 3},
+		Order{ID: 3, Type: Ask, Price: 105, CreatedAt: 132426, Amount: 4, RestAmt: 4},
+	}
+
+	bk := Book{}
+	for _, ask := range AskOrderList {
+		bk.AddAsk(ask)
+	}
+
+	fills, err := bk.MatchMarket(Order{ID: 4, Type: Bid, Kind: Market, Amount: 7, RestAmt: 7})
+	assert.Nil(t, err)
+	expect := []struct {
+		id     uint64
+		amount uint64
+		price  uint64
+	}{
+		{4, 2, 100},
+		{1, 2, 100},
+		{4, 3, 101},
+		{2, 3, 101},
+		{4, 2, 105},
+		{3, 2, 105},
+	}
+	assert.Equal(t, len(expect), len(fills))
+	var cost uint64
+	for i, e := range expect {
+		assert.Equal(t, e.id, fills[i].Order.ID)
+		assert.Equal(t, e.amount, fills[i].Amount)
+		assert.Equal(t, e.price, fills[i].Price)
+		if fills[i].Order.ID == 4 {
+			cost += fills[i].Price * fills[i].Amount
+		}
+	}
+	assert.Equal(t, uint64(2*100+3*101+2*105), cost)
+
+	asks := ordersOf(&bk, Ask)
+	assert.Equal(t, 1, len(asks))
+	assert.Equal(t, uint64(2), asks[0].RestAmt)
+
+	// sweep the whole book, the rest amount is dropped.
+	fills, err = bk.MatchMarket(Order{ID: 5, Type: Bid, Kind: Market, Amount: 10, RestAmt: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(fills))
+	assert.Equal(t, uint64(8), fills[0].Order.RestAmt)
+	assert.Equal(t, uint64(0), fills[2].Amount)
+	assert.Equal(t, uint64(8), fills[2].Order.RestAmt)
+	assert.Equal(t, 0, len(ordersOf(&bk, Ask)))
+
+	// no liquidity.
+	_, err = bk.MatchMarket(Order{ID: 6, Type: Bid, Kind: Market, Amount: 1, RestAmt: 1})
+	assert.NotNil(t, err)
+}
+
+func TestMatchMarketBidCost(t *testing.T) {
+	bk := Book{}
+	bk.AddAsk(Order{ID: 1, Type: Ask, Price: 100, CreatedAt: 132424, Amount: 2, RestAmt: 2})
+	bk.AddAsk(Order{ID: 2, Type: Ask, Price: 101, CreatedAt: 132425, Amount: 3, RestAmt: 3})
+
+	// the cost of 350 pays 2 at 100 and 1 at 101, the rest is closed with the unspent 49.
+	fills, err := bk.MatchMarket(Order{ID: 3, Type: Bid, Kind: Market, Amount: 5, RestAmt: 5, Cost: 350})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(fills))
+	assert.Equal(t, uint64(2), fills[0].Amount)
+	assert.Equal(t, uint64(150), fills[0].Order.Cost)
+	assert.Equal(t, uint64(1), fills[2].Amount)
+	assert.Equal(t, uint64(49), fills[2].Order.Cost)
+	assert.Equal(t, uint64(0), fills[4].Amount)
+	assert.Equal(t, uint64(2), fills[4].Order.RestAmt)
+	assert.Equal(t, uint64(49), fills[4].Order.Cost)
+
+	asks := ordersOf(&bk, Ask)
+	assert.Equal(t, 1, len(asks))
+	assert.Equal(t, uint64(2), asks[0].RestAmt)
+}
+
+func TestMatchIOC(t *testing.T) {
+	bk := Book{}
+	bk.AddAsk(Order{ID: 1, Type: Ask, Price: 100, CreatedAt: 132424, Amount: 2, RestAmt: 2})
+	bk.AddAsk(Order{ID: 2, Type: Ask, Price: 101, CreatedAt: 132425, Amount: 3, RestAmt: 3})
+	bk.AddAsk(Order{ID: 3, Type: Ask, Price: 105, CreatedAt: 132426, Amount: 4, RestAmt: 4})
+
+	// the asks above the bid price are not matched, the rest amount is closed.
+	fills, err := bk.MatchIOC(Order{ID: 4, Type: Bid, TimeInForce: IOC, Price: 102, Amount: 7, RestAmt: 7})
+	assert.Nil(t, err)
+	expect := []struct {
+		id     uint64
+		amount uint64
+		price  uint64
+	}{
+		{4, 2, 100},
+		{1, 2, 100},
+		{4, 3, 101},
+		{2, 3, 101},
+		{4, 0, 0},
+	}
+	assert.Equal(t, len(expect), len(fills))
+	for i, e := range expect {
+		assert.Equal(t, e.id, fills[i].Order.ID)
+		assert.Equal(t, e.amount, fills[i].Amount)
+		assert.Equal(t, e.price, fills[i].Price)
+	}
+	assert.Equal(t, uint64(2), fills[4].Order.RestAmt)
+
+	asks := ordersOf(&bk, Ask)
+	assert.Equal(t, 1, len(asks))
+	assert.Equal(t, uint64(3), asks[0].ID)
+	assert.Equal(t, 0, len(ordersOf(&bk, Bid)))
+
+	// no order is matched.
+	fills, err = bk.MatchIOC(Order{ID: 5, Type: Bid, TimeInForce: IOC, Price: 100, Amount: 1, RestAmt: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, []Fill{{Order: Order{ID: 5, Type: Bid, TimeInForce: IOC, Price: 100, Amount: 1, RestAmt: 1}}}, fills)
+}
+
+func TestRemoveExpired(t *testing.T) {
+	bk := Book{}
+	bk.AddBid(Order{ID: 1, Type: Bid, Price: 100, CreatedAt: 132424, Amount: 1, RestAmt: 1})
+	bk.AddBid(Order{ID: 2, Type: Bid, Price: 101, CreatedAt: 132425, Amount: 1, RestAmt: 1, TimeInForce: GTD, ExpireAt: 200})
+	bk.AddAsk(Order{ID: 3, Type: Ask, Price: 105, CreatedAt: 132426, Amount: 1, RestAmt: 1, TimeInForce: GTD, ExpireAt: 100})
+	bk.AddAsk(Order{ID: 4, Type: Ask, Price: 106, CreatedAt: 132427, Amount: 1, RestAmt: 1, TimeInForce: GTD, ExpireAt: 300})
+
+	expired := bk.RemoveExpired(200)
+	assert.Equal(t, 2, len(expired))
+	assert.Equal(t, uint64(2), expired[0].ID)
+	assert.Equal(t, uint64(3), expired[1].ID)
+
+	bids := ordersOf(&bk, Bid)
+	assert.Equal(t, 1, len(bids))
+	assert.Equal(t, uint64(1), bids[0].ID)
+	asks := ordersOf(&bk, Ask)
+	assert.Equal(t, 1, len(asks))
+	assert.Equal(t, uint64(4), asks[0].ID)
+
+	assert.Equal(t, 0, len(bk.RemoveExpired(200)))
+}
+
 func TestCopy(t *testing.T) {
 	var BidOrderList = []Order{
 		Order{Price: 100, CreatedAt: 132424, Amount: 1},
@@ -294,13 +516,276 @@ func TestCopy(t *testing.T) {
 	}
 
 	copyBk := bk.Copy()
-	assert.Equal(t, len(bk.bidOrders), len(copyBk.bidOrders))
-	for i := 0; i < len(bk.bidOrders); i++ {
-		assert.NotEqual(t, fmt.Sprintf("%p", &bk.bidOrders[i]), fmt.Sprintf("%p", &copyBk.bidOrders[i]))
-	}
+	assert.Equal(t, bk.bids.orders(), copyBk.bids.orders())
+	assert.NotEqual(t, fmt.Sprintf("%p", bk.bids.front()), fmt.Sprintf("%p", copyBk.bids.front()))
 
-	assert.Equal(t, len(bk.askOrders), len(copyBk.askOrders))
-	for i := 0; i < len(bk.askOrders); i++ {
-		assert.NotEqual(t, fmt.Sprintf("%p", &bk.askOrders[i]), fmt.Sprintf("%p", &copyBk.askOrders[i]))
-	}
+	assert.Equal(t, bk.asks.orders(), copyBk.asks.orders())
+	assert.NotEqual(t, fmt.Sprintf("%p", bk.asks.front()), fmt.Sprintf("%p", copyBk.asks.front()))
+
+	// updating the copy leaves the origin book unchanged.
+	copyBk.bids.front().RestAmt = 100
+	assert.NotEqual(t, uint64(100), bk.bids.front().RestAmt)
+}
+
+func TestDepth(t *testing.T) {
+	bk := Book{}
+	bids, asks := bk.Depth(5)
+	assert.NotNil(t, bids)
+	assert.NotNil(t, asks)
+	assert.Equal(t, 0, len(bids))
+	assert.Equal(t, 0, len(asks))
+
+	for _, od := range []Order{
+		Order{Price: 100, CreatedAt: 132424, Amount: 3, RestAmt: 3},
+		Order{Price: 102, CreatedAt: 132425, Amount: 1, RestAmt: 1},
+		Order{Price: 100, CreatedAt: 132426, Amount: 5, RestAmt: 2},
+		Order{Price: 101, CreatedAt: 132427, Amount: 4, RestAmt: 4},
+		Order{Price: 102, CreatedAt: 132428, Amount: 2, RestAmt: 2},
+		Order{Price: 99, CreatedAt: 132429, Amount: 7, RestAmt: 7},
+	} {
+		bk.AddBid(od)
+	}
+
+	for _, od := range []Order{
+		Order{Price: 110, CreatedAt: 132424, Amount: 1, RestAmt: 1},
+		Order{Price: 105, CreatedAt: 132425, Amount: 2, RestAmt: 2},
+		Order{Price: 110, CreatedAt: 132426, Amount: 3, RestAmt: 3},
+		Order{Price: 105, CreatedAt: 132427, Amount: 4, RestAmt: 4},
+	} {
+		bk.AddAsk(od)
+	}
+
+	bids, asks = bk.Depth(3)
+	assert.Equal(t, []DepthLevel{
+		{Price: 102, TotalAmount: 3},
+		{Price: 101, TotalAmount: 4},
+		{Price: 100, TotalAmount: 5},
+	}, bids)
+	assert.Equal(t, []DepthLevel{
+		{Price: 105, TotalAmount: 6},
+		{Price: 110, TotalAmount: 4},
+	}, asks)
+
+	bids, asks = bk.Depth(1)
+	assert.Equal(t, []DepthLevel{{Price: 102, TotalAmount: 3}}, bids)
+	assert.Equal(t, []DepthLevel{{Price: 105, TotalAmount: 6}}, asks)
+}
+
+func TestMatchTaker(t *testing.T) {
+	bk := Book{}
+	bk.AddAsk(Order{ID: 1, AccountID: "a", Price: 100, CreatedAt: 132424, Amount: 1, RestAmt: 1})
+	bk.AddBid(Order{ID: 2, AccountID: "b", Price: 101, CreatedAt: 132425, Amount: 1, RestAmt: 1})
+	fills := bk.Match()
+	assert.Equal(t, 2, len(fills))
+	// the bid is placed later, it's the taker.
+	assert.True(t, fills[0].Taker)
+	assert.Equal(t, "a", fills[0].Counter.AccountID)
+	assert.False(t, fills[1].Taker)
+	assert.Equal(t, "b", fills[1].Counter.AccountID)
+
+	bk.AddBid(Order{ID: 3, AccountID: "c", Price: 100, CreatedAt: 132426, Amount: 2, RestAmt: 2})
+	fills, err := bk.MatchMarket(Order{ID: 4, AccountID: "d", Type: Ask, Kind: Market, Amount: 1, RestAmt: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(fills))
+	assert.True(t, fills[0].Taker)
+	assert.Equal(t, uint64(3), fills[0].Counter.ID)
+	assert.False(t, fills[1].Taker)
+	assert.Equal(t, uint64(4), fills[1].Counter.ID)
+}
+
+func TestMatchPriceTimePriority(t *testing.T) {
+	bk := Book{}
+	// bids are inserted out of price and time order.
+	bk.AddBid(Order{ID: 1, Type: Bid, Price: 101, CreatedAt: 132430, Amount: 1, RestAmt: 1})
+	bk.AddBid(Order{ID: 2, Type: Bid, Price: 103, CreatedAt: 132432, Amount: 1, RestAmt: 1})
+	bk.AddBid(Order{ID: 3, Type: Bid, Price: 103, CreatedAt: 132428, Amount: 1, RestAmt: 1})
+	bk.AddBid(Order{ID: 5, Type: Bid, Price: 102, CreatedAt: 132429, Amount: 1, RestAmt: 1})
+	bk.AddBid(Order{ID: 4, Type: Bid, Price: 102, CreatedAt: 132429, Amount: 1, RestAmt: 1})
+	bk.AddBid(Order{ID: 6, Type: Bid, Price: 103, CreatedAt: 132431, Amount: 1, RestAmt: 1})
+
+	// a single big ask consumes the bids one by one in priority.
+	bk.AddAsk(Order{ID: 7, Type: Ask, Price: 100, CreatedAt: 132440, Amount: 6, RestAmt: 6})
+	fills := bk.Match()
+	assert.Equal(t, 12, len(fills))
+	ids := []uint64{}
+	for i := 0; i < len(fills); i += 2 {
+		ids = append(ids, fills[i].Order.ID)
+		assert.Equal(t, uint64(7), fills[i+1].Order.ID)
+	}
+	assert.Equal(t, []uint64{3, 6, 2, 4, 5, 1}, ids)
+	assert.Equal(t, 0, bk.bids.len())
+	assert.Equal(t, 0, bk.asks.len())
+
+	// asks are inserted out of price and time order.
+	bk.AddAsk(Order{ID: 8, Type: Ask, Price: 105, CreatedAt: 132450, Amount: 1, RestAmt: 1})
+	bk.AddAsk(Order{ID: 9, Type: Ask, Price: 104, CreatedAt: 132452, Amount: 1, RestAmt: 1})
+	bk.AddAsk(Order{ID: 11, Type: Ask, Price: 104, CreatedAt: 132451, Amount: 1, RestAmt: 1})
+	bk.AddAsk(Order{ID: 10, Type: Ask, Price: 104, CreatedAt: 132451, Amount: 1, RestAmt: 1})
+	bk.AddAsk(Order{ID: 12, Type: Ask, Price: 106, CreatedAt: 132449, Amount: 1, RestAmt: 1})
+
+	fills, err := bk.MatchMarket(Order{ID: 13, Type: Bid, Kind: Market, Amount: 5, RestAmt: 5})
+	assert.Nil(t, err)
+	ids = []uint64{}
+	for i := 0; i < len(fills); i += 2 {
+		ids = append(ids, fills[i].Counter.ID)
+	}
+	assert.Equal(t, []uint64{10, 11, 9, 8, 12}, ids)
+	assert.Equal(t, 0, bk.asks.len())
+}
+
+func TestBookPriceLevels(t *testing.T) {
+	bk := Book{}
+	bk.AddAsk(Order{ID: 1, Price: 102, CreatedAt: 132424, Amount: 1, RestAmt: 1})
+	bk.AddAsk(Order{ID: 2, Price: 100, CreatedAt: 132425, Amount: 2, RestAmt: 2})
+	bk.AddAsk(Order{ID: 3, Price: 102, CreatedAt: 132423, Amount: 3, RestAmt: 3})
+	bk.AddAsk(Order{ID: 4, Price: 101, CreatedAt: 132426, Amount: 4, RestAmt: 4})
+	assert.Equal(t, 3, len(bk.asks.levels))
+	assert.Equal(t, 4, bk.asks.len())
+
+	ids := []uint64{}
+	for _, od := range bk.asks.orders() {
+		ids = append(ids, od.ID)
+	}
+	assert.Equal(t, []uint64{2, 4, 3, 1}, ids)
+
+	// cancel removes the order from its level, and the empty level is dropped.
+	_, err := bk.Cancel(4, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(bk.asks.levels))
+	assert.Equal(t, 3, bk.asks.len())
+
+	// the restored book keeps the priority.
+	nbk := NewBookFromJson(bk.ToMarshalable())
+	assert.Equal(t, bk.asks.orders(), nbk.asks.orders())
+	assert.Equal(t, bk.asks.len(), nbk.asks.len())
+}
+
+// ordersOf returns the first 10 orders of specific type in the book.
+func ordersOf(bk *Book, tp Type) []Order {
+	ods, _, _ := bk.GetOrders(tp, 0, 10)
+	return ods
+}
+
+func TestGetOrders(t *testing.T) {
+	bk := Book{}
+	ods, total, err := bk.GetOrders(Bid, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, total)
+	assert.Equal(t, []Order{}, ods)
+
+	for i := 1; i <= 5; i++ {
+		bk.AddBid(Order{ID: uint64(i), Type: Bid, Price: uint64(100 + i), CreatedAt: int64(132420 + i), Amount: 1, RestAmt: 1})
+	}
+
+	ids := func(ods []Order) []uint64 {
+		ids := []uint64{}
+		for _, od := range ods {
+			ids = append(ids, od.ID)
+		}
+		return ids
+	}
+
+	testData := []struct {
+		Start int64
+		End   int64
+		IDs   []uint64
+	}{
+		{0, 2, []uint64{5, 4}},
+		{2, 4, []uint64{3, 2}},
+		{4, 6, []uint64{1}},  // partial page.
+		{5, 10, []uint64{}},  // out of range.
+		{3, 3, []uint64{}},   // empty range.
+		{-2, 1, []uint64{5}}, // negative start is clamped.
+		{0, 100, []uint64{5, 4, 3, 2, 1}},
+	}
+
+	for _, d := range testData {
+		ods, total, err := bk.GetOrders(Bid, d.Start, d.End)
+		assert.Nil(t, err)
+		assert.Equal(t, 5, total)
+		assert.Equal(t, d.IDs, ids(ods), "%d-%d", d.Start, d.End)
+	}
+
+	// start must not be greater than end.
+	_, _, err = bk.GetOrders(Bid, 3, 2)
+	assert.NotNil(t, err)
+
+	// no ask order.
+	ods, total, err = bk.GetOrders(Ask, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, total)
+	assert.Equal(t, 0, len(ods))
+
+	// orders created in [132422, 132424).
+	ods, err = bk.GetOrdersByTime(Bid, 132422, 132424)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{3, 2}, ids(ods))
+
+	ods, err = bk.GetOrdersByTime(Bid, 132430, 132440)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ods))
+
+	_, err = bk.GetOrdersByTime(Bid, 132424, 132422)
+	assert.NotNil(t, err)
+}
+
+func TestStops(t *testing.T) {
+	bk := Book{}
+	bk.AddStop(Order{ID: 1, AccountID: "a", Type: Ask, Price: 85, StopPrice: 90, Amount: 1, RestAmt: 1})
+	bk.AddStop(Order{ID: 2, AccountID: "b", Type: Bid, Price: 115, StopPrice: 110, Amount: 1, RestAmt: 1})
+	bk.AddStop(Order{ID: 3, AccountID: "a", Type: Ask, Price: 90, StopPrice: 95, Amount: 1, RestAmt: 1})
+	assert.Equal(t, 3, len(bk.Stops()))
+	assert.True(t, bk.HasOrders("b"))
+
+	// nothing is triggered before the first trade.
+	assert.Equal(t, 0, len(bk.TriggerStops()))
+
+	// the trade is executed at the maker's price.
+	bk.AddBid(Order{ID: 4, Type: Bid, Price: 100, CreatedAt: 1, Amount: 1, RestAmt: 1})
+	bk.AddAsk(Order{ID: 5, Type: Ask, Price: 98, CreatedAt: 2, Amount: 1, RestAmt: 1})
+	assert.Equal(t, 2, len(bk.Match()))
+	assert.Equal(t, uint64(100), bk.LastPrice())
+	assert.Equal(t, 0, len(bk.TriggerStops()))
+
+	// the immediate orders update the last price too.
+	bk.AddBid(Order{ID: 6, Type: Bid, Price: 95, CreatedAt: 3, Amount: 2, RestAmt: 2})
+	fills, err := bk.MatchIOC(Order{ID: 7, Type: Ask, Kind: Limit, TimeInForce: IOC, Price: 90, Amount: 1, RestAmt: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), fills[0].Amount)
+	assert.Equal(t, uint64(95), bk.LastPrice())
+
+	stops := bk.TriggerStops()
+	assert.Equal(t, 1, len(stops))
+	assert.Equal(t, uint64(3), stops[0].ID)
+	assert.Equal(t, 2, len(bk.Stops()))
+
+	_, err = bk.MatchMarket(Order{ID: 8, Type: Ask, Kind: Market, Amount: 1, RestAmt: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(95), bk.LastPrice())
+
+	// copies and the marshaled book keep the stops and the last price.
+	copyBk := bk.Copy()
+	assert.Equal(t, bk.Stops(), copyBk.Stops())
+	assert.Equal(t, bk.LastPrice(), copyBk.LastPrice())
+	jsonBk := NewBookFromJson(bk.ToMarshalable())
+	assert.Equal(t, bk.Stops(), jsonBk.Stops())
+	assert.Equal(t, bk.LastPrice(), jsonBk.LastPrice())
+
+	// the stop orders can be cancelled by the owner only.
+	_, err = bk.Cancel(2, "a")
+	assert.Equal(t, ErrNotOrderOwner, err)
+	od, err := bk.Cancel(2, "b")
+	assert.Nil(t, err)
+	assert.True(t, od.IsStop())
+	assert.False(t, bk.HasOrders("b"))
+	assert.Equal(t, 1, len(bk.Stops()))
+
+	bk.AddAsk(Order{ID: 9, Type: Ask, Price: 90, CreatedAt: 4, Amount: 1, RestAmt: 1})
+	bk.AddBid(Order{ID: 10, Type: Bid, Price: 90, CreatedAt: 5, Amount: 1, RestAmt: 1})
+	bk.Match()
+	assert.Equal(t, uint64(90), bk.LastPrice())
+	stops = bk.TriggerStops()
+	assert.Equal(t, 1, len(stops))
+	assert.Equal(t, uint64(1), stops[0].ID)
+	assert.Equal(t, 0, len(bk.Stops()))
 }
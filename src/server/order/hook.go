@@ -0,0 +1,95 @@
+package order
+
+import (
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/server/trade"
+)
+
+// TradeHookQueueSize max trades waiting for the hooks, the trades are dropped when the
+// queue is full, so the slow hooks never block the matching.
+var TradeHookQueueSize = 1024
+
+// TradeHook is called with every trade executed by the books of the manager, the hooks
+// are called one by one on a worker goroutine in the order of the trades.
+type TradeHook func(trade.Trade)
+
+// tradeHooks dispatches the trades to the registered hooks, the zero value is usable,
+// the worker is started by the first hook.
+type tradeHooks struct {
+	mtx   sync.RWMutex
+	fns   []TradeHook
+	queue chan trade.Trade // nil until the first hook is registered.
+}
+
+// RegisterTradeHook registers the hook which is called with every trade executed after,
+// multiple hooks can be registered, they're called in the order of registration.
+func (m *Manager) RegisterTradeHook(fn TradeHook) {
+	h := &m.hooks
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.fns = append(h.fns, fn)
+	if h.queue == nil {
+		h.queue = make(chan trade.Trade, TradeHookQueueSize)
+		go h.run(h.queue)
+	}
+}
+
+// run calls the hooks with the queued trades, a panic of the hook is logged.
+func (h *tradeHooks) run(queue chan trade.Trade) {
+	for t := range queue {
+		h.mtx.RLock()
+		fns := h.fns
+		h.mtx.RUnlock()
+		for _, fn := range fns {
+			callHook(fn, t)
+		}
+	}
+}
+
+func callHook(fn TradeHook, t trade.Trade) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("trade hook panic: %v", r)
+		}
+	}()
+	fn(t)
+}
+
+// emitTrades queues the trades of the taker fills for the hooks without blocking.
+func (m *Manager) emitTrades(cp string, fills []Fill) {
+	h := &m.hooks
+	h.mtx.RLock()
+	queue := h.queue
+	h.mtx.RUnlock()
+	if queue == nil {
+		return
+	}
+
+	for _, f := range fills {
+		if !f.Taker || f.Amount == 0 {
+			continue
+		}
+		t := FillTrade(cp, f)
+		select {
+		case queue <- t:
+		default:
+			logger.Error("trade hook queue is full, %s trade of order %d and %d is dropped", cp, t.MakerOrderID, t.TakerOrderID)
+		}
+	}
+}
+
+// FillTrade makes the trade of the taker fill, the trade is executed at the maker's price.
+func FillTrade(cp string, f Fill) trade.Trade {
+	return trade.Trade{
+		Pair:         cp,
+		Price:        f.Counter.Price,
+		Amount:       f.Amount,
+		Maker:        f.Counter.AccountID,
+		Taker:        f.Order.AccountID,
+		MakerOrderID: f.Counter.ID,
+		TakerOrderID: f.Order.ID,
+		Time:         time.Now().Unix(),
+	}
+}
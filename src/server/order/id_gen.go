@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/skycoin/skycoin-exchange/src/server/persist"
 	"github.com/skycoin/skycoin/src/util"
 )
 
@@ -32,14 +33,12 @@ func (ig IDGenerator) Run(closing chan bool) {
 		}
 	}
 	for {
+		id.ID += 1
 		select {
 		case <-closing:
 			return
-		default:
-			id.ID += 1
-			ig.IDC <- id.ID
-
-			if err := util.SaveJSON(ig.Path, id, 0600); err != nil {
+		case ig.IDC <- id.ID:
+			if err := persist.SaveJSON(ig.Path, id, 0600); err != nil {
 				panic(err)
 			}
 		}
@@ -5,26 +5,75 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/skycoin/skycoin/src/util"
+	logging "github.com/op/go-logging"
+	"github.com/skycoin/skycoin-exchange/src/server/persist"
+	"github.com/skycoin/skycoin-exchange/src/server/trade"
 )
 
+// MaxFeeRate the max fee rate in basis points, which is 100%.
+const MaxFeeRate = 10000
+
+// DefaultSaveInterval the default interval of saving the changed books to local disk.
+const DefaultSaveInterval = time.Second
+
+// ErrManagerStopped is returned when the order is added or cancelled after the manager is stopped.
+var ErrManagerStopped = errors.New("order manager is stopped")
+
+var (
+	logger = logging.MustGetLogger("exchange.order")
+	// bookWrites number of the book writes to local disk, accessed atomically.
+	bookWrites uint64
+)
+
+// Manager manages the order books of all coin pairs, the books can be added while
+// the manager is running.
 type Manager struct {
-	books map[string]*Book
-	chans map[string]chan Order
-	idg   map[string]*IDGenerator
+	feeRate uint64       // taker fee rate in basis points, accessed atomically, keep it first for 64-bit alignment.
+	mtx     sync.RWMutex // mutex for protecting the maps and the running state.
+	books   map[string]*Book
+	chans   map[string]chan Fill
+	idg     map[string]*IDGenerator
+	cmds    map[string]chan func() // command queues of the match goroutines, set by startBook.
+	onStop  StopHandler            // called when the stop orders are triggered.
+	tick    time.Duration          // match tick time, set by Start.
+	closing chan bool              // set by Start, nil if the manager is not started.
+	wg      sync.WaitGroup         // waits the match goroutines.
+
+	saveInterval time.Duration   // interval of saving the changed books.
+	dirtyMtx     sync.Mutex      // protects dirty.
+	dirty        map[string]bool // coin pairs of the books changed since the last save.
+
+	snapshotInterval time.Duration // interval of the book snapshots, 0 disables them.
+	snapshotKeep     int           // number of snapshots kept for each book.
+
+	hooks    tradeHooks      // hooks called with the executed trades.
+	tradeLog *trade.TradeLog // source of the ticker's last price and volume, set by SetTradeLog.
 }
 
+// StopHandler is called with the coin pair and the triggered stop order before it
+// enters matching, the order is dropped if error is returned, for example, the balance
+// of the account is not sufficient. The StopPrice of the order is already cleared, the
+// handler sets the Cost of the market bid.
+type StopHandler func(cp string, od *Order) error
+
 func NewManager() *Manager {
 	return &Manager{
-		books: make(map[string]*Book),
-		chans: make(map[string]chan Order),
-		idg:   make(map[string]*IDGenerator),
+		books:        make(map[string]*Book),
+		chans:        make(map[string]chan Fill),
+		idg:          make(map[string]*IDGenerator),
+		cmds:         make(map[string]chan func()),
+		saveInterval: DefaultSaveInterval,
+		dirty:        make(map[string]bool),
+		snapshotKeep: DefaultSnapshotKeep,
 	}
 }
 
@@ -52,127 +101,867 @@ func LoadManager() (*Manager, error) {
 		if err != nil {
 			return nil, err
 		}
-		bj := BookJson{}
-		if err := json.Unmarshal(d, &bj); err != nil {
-			return nil, err
-		}
 		p := strings.Split(f.Name(), ".")
 		pair := strings.Split(p[0], "_")
 		if len(pair) != 2 {
 			panic("error order book file name")
 		}
 		cp := strings.Join(pair, "/")
+
+		bj := BookJson{}
+		if err := json.Unmarshal(d, &bj); err != nil {
+			// the corrupted book is restored from the newest snapshot, and rewritten by the next flush.
+			logger.Error("order book %s is corrupted: %v", f.Name(), err)
+			bk, e := RestoreFromSnapshot(cp)
+			if e != nil {
+				return nil, fmt.Errorf("order book %s is corrupted: %v, %v", f.Name(), err, e)
+			}
+			m.books[cp] = bk
+			m.idg[cp] = newIDGenerator(cp)
+			m.markDirty(cp)
+			continue
+		}
+		// the book of older version is upgraded, and rewritten by the next flush.
+		version := bj.Version
+		if err := migrateBook(&bj); err != nil {
+			return nil, fmt.Errorf("order book %s: %w", f.Name(), err)
+		}
 		m.books[cp] = NewBookFromJson(bj)
+		if version != bj.Version {
+			logger.Info("order book %s upgraded to version %d", f.Name(), bj.Version)
+			m.markDirty(cp)
+		}
 
 		// init order id generator.
 		m.idg[cp] = newIDGenerator(cp)
 	}
 
+	// other files like the trade log may share the dir.
+	if len(m.books) == 0 {
+		return nil, os.ErrNotExist
+	}
+
 	return m, nil
 }
 
 // AddBook add the order book of specific coin pair to manager,
-// the stored book is an copy book, for thread safe.
+// the stored book is an copy book, for thread safe. If the manager is running,
+// the book starts matching immediately, so the order channel of the coin pair
+// should be registered before.
 func (m *Manager) AddBook(coinPair string, book *Book) error {
 	if coinPair == "" {
 		return errors.New("coin pair is empty")
 	}
 
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
 	if _, ok := m.books[coinPair]; ok {
 		return fmt.Errorf("book of coin pair: %s already exists", coinPair)
 	}
-	bk := book.Copy()
-	m.books[coinPair] = &bk
+	m.books[coinPair] = book.Copy()
 
 	m.idg[coinPair] = newIDGenerator(coinPair)
+	if m.closing != nil {
+		select {
+		case <-m.closing:
+		default:
+			m.startBook(coinPair)
+		}
+	}
 	return nil
 }
 
 func (m *Manager) IsExist(coinPair string) bool {
-	if _, ok := m.books[coinPair]; ok {
-		return true
+	_, ok := m.getBook(coinPair)
+	return ok
+}
+
+// Pairs returns the sorted coin pairs of all the books.
+func (m *Manager) Pairs() []string {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	cps := make([]string, 0, len(m.books))
+	for cp := range m.books {
+		cps = append(cps, cp)
 	}
-	return false
+	sort.Strings(cps)
+	return cps
+}
+
+// getBook returns the book of specific coin pair.
+func (m *Manager) getBook(coinPair string) (*Book, bool) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	bk, ok := m.books[coinPair]
+	return bk, ok
 }
 
-// AddOrder add bid or ask order to order book.
+// AddOrder add bid or ask order to order book, the order will be matched
+// incrementally, and rest in the book until its RestAmt reaches zero.
+// The stop order stays inactive until it's triggered by the last trade price.
+// If the book holds its max orders, the order is rejected with ErrBookFull, or
+// evicts the worst-priced order, whose closing fill is sent to the order channel.
+// While the manager is running, the order is placed by the match goroutine of the
+// book, so the concurrent orders are sequenced and matched one by one.
+// The order is rejected with ErrPairHalted if the trading of the coin pair is halted.
 func (m *Manager) AddOrder(coinPair string, order Order) (uint64, error) {
+	if order.Amount == 0 {
+		return 0, ErrZeroAmount
+	}
+
+	bk, idg, err := m.getBookAndIDGen(coinPair)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := checkOrder(coinPair, bk, &order); err != nil {
+		return 0, err
+	}
+
+	var id uint64
+	if e := m.exec(coinPair, func() { id, err = m.placeOrder(coinPair, bk, idg, order) }); e != nil {
+		return 0, e
+	}
+	return id, err
+}
+
+// AddOrders adds the batch of orders to the book of specific coin pair, each order is checked
+// as AddOrder does, then the valid ones are placed in the order of the batch, with no other
+// order placed between them, and the book is matched once after all are placed. The id or the
+// error of each order is returned at its index, the failed orders don't stop the others.
+func (m *Manager) AddOrders(coinPair string, orders []Order) ([]uint64, []error) {
+	ids := make([]uint64, len(orders))
+	errs := make([]error, len(orders))
+	bk, idg, err := m.getBookAndIDGen(coinPair)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return ids, errs
+	}
+
+	ods := make([]Order, len(orders))
+	for i, od := range orders {
+		if od.Amount == 0 {
+			errs[i] = ErrZeroAmount
+			continue
+		}
+		errs[i] = checkOrder(coinPair, bk, &od)
+		ods[i] = od
+	}
+
+	if e := m.exec(coinPair, func() {
+		for i, od := range ods {
+			if errs[i] == nil {
+				ids[i], errs[i] = m.placeOrder(coinPair, bk, idg, od)
+			}
+		}
+		if !bk.Halted() && m.matchBook(coinPair, bk) {
+			m.markDirty(coinPair)
+		}
+	}); e != nil {
+		// none of the orders is placed.
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = e
+			}
+		}
+	}
+	return ids, errs
+}
+
+// getBookAndIDGen returns the book and the order id generator of specific coin pair.
+func (m *Manager) getBookAndIDGen(coinPair string) (*Book, *IDGenerator, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
 	bk, ok := m.books[coinPair]
 	if !ok {
-		return 0, fmt.Errorf("coin pair:%s not supported", coinPair)
+		return nil, nil, fmt.Errorf("coin pair:%s not supported", coinPair)
 	}
-
 	idg, ok := m.idg[coinPair]
 	if !ok {
-		return 0, fmt.Errorf("coin pair:%s's id generator not supported", coinPair)
+		return nil, nil, fmt.Errorf("coin pair:%s's id generator not supported", coinPair)
+	}
+	return bk, idg, nil
+}
+
+// checkOrder converts the amount of the new order to the base coin, validates it with the book,
+// and sets its RestAmt to the amount if it's not a valid one.
+func checkOrder(coinPair string, bk *Book, order *Order) error {
+	od, err := order.ToBase(bk.PriceDecimals())
+	if err != nil {
+		return err
+	}
+	*order = od
+	if order.RestAmt == 0 || order.RestAmt > order.Amount {
+		order.RestAmt = order.Amount
+	}
+
+	// the price of market order is ignored, the stop price must be on the tick grid too.
+	if err := (Pipeline{ValidatePrice, ValidateMinAmount, ValidateTick}).Validate(coinPair, bk, *order); err != nil {
+		return err
+	}
+
+	// the value must fit in uint64 at the price and the stop price, so that settling
+	// any fill of the order can't overflow.
+	dec := bk.PriceDecimals()
+	if order.Kind == Limit {
+		if _, err := Value(order.Price, order.Amount, dec, RoundUp); err != nil {
+			return err
+		}
+	}
+	if _, err := Value(order.StopPrice, order.Amount, dec, RoundUp); err != nil {
+		return err
+	}
+
+	if order.Kind == Limit {
+		switch order.TimeInForce {
+		case GTC, IOC:
+		case GTD:
+			if order.ExpireAt <= time.Now().Unix() {
+				return ErrInvalidExpiry
+			}
+		default:
+			return fmt.Errorf("unknow time in force:%d", order.TimeInForce)
+		}
+	}
+	return nil
+}
+
+// placeOrder adds the validated order to the book, it's run by exec.
+func (m *Manager) placeOrder(coinPair string, bk *Book, idg *IDGenerator, order Order) (uint64, error) {
+	// checked in sequence, so no order is placed once HaltPair returns.
+	if bk.Halted() {
+		return 0, ErrPairHalted
+	}
+
+	if order.IsStop() {
+		if order.Type != Bid && order.Type != Ask {
+			return 0, errors.New("unknow order type")
+		}
+		return m.addLimitedOrder(coinPair, bk, idg, order)
+	}
+
+	if bk.SelfTradePrevention() == STPReject && bk.crossesOwn(order) {
+		return 0, ErrSelfTrade
+	}
+
+	if order.Kind == Market || order.TimeInForce == IOC {
+		return m.addImmediateOrder(coinPair, bk, idg, order)
+	}
+
+	if order.Type != Bid && order.Type != Ask {
+		return 0, errors.New("unknow order type")
+	}
+	return m.addLimitedOrder(coinPair, bk, idg, order)
+}
+
+// ValidateOrder runs the validators of pipeline p with the order and the book of the coin pair,
+// returns the error of the first failing validator.
+func (m *Manager) ValidateOrder(coinPair string, order Order, p Pipeline) error {
+	bk, ok := m.getBook(coinPair)
+	if !ok {
+		return fmt.Errorf("coin pair:%s not supported", coinPair)
+	}
+	order, err := order.ToBase(bk.PriceDecimals())
+	if err != nil {
+		return err
 	}
+	return p.Validate(coinPair, bk, order)
+}
 
-	switch order.Type {
-	case Bid:
-		order.ID = idg.GetID()
-		bk.AddBid(order)
-		return order.ID, nil
-	case Ask:
-		order.ID = idg.GetID()
-		bk.AddAsk(order)
-		return order.ID, nil
-	default:
+// addLimitedOrder adds the resting or stop order under the max orders limit of the book.
+func (m *Manager) addLimitedOrder(coinPair string, bk *Book, idg *IDGenerator, order Order) (uint64, error) {
+	order.ID = idg.GetID()
+	evicted, err := bk.addLimited(order)
+	if err != nil {
+		return 0, err
+	}
+	m.markDirty(coinPair)
+
+	fills := make([]Fill, len(evicted))
+	for i, od := range evicted {
+		fills[i] = Fill{Order: od}
+	}
+	m.sendFills(coinPair, fills)
+	return order.ID, nil
+}
+
+// addImmediateOrder executes the market or IOC order immediately, the order never rests in the book,
+// fills are sent to the registered order channel.
+func (m *Manager) addImmediateOrder(coinPair string, bk *Book, idg *IDGenerator, order Order) (uint64, error) {
+	if order.Type != Bid && order.Type != Ask {
 		return 0, errors.New("unknow order type")
 	}
+
+	match := bk.MatchMarket
+	if order.Kind == Limit {
+		match = bk.MatchIOC
+	}
+
+	order.ID = idg.GetID()
+	fills, err := match(order)
+	if err != nil {
+		return 0, err
+	}
+	m.markDirty(coinPair)
+	m.sendFills(coinPair, fills)
+	return order.ID, nil
+}
+
+// sendFills sends the fills to the registered order channel of the coin pair,
+// the orders closed by the fills are recorded in the book.
+func (m *Manager) sendFills(coinPair string, fills []Fill) {
+	m.mtx.RLock()
+	c, ok := m.chans[coinPair]
+	bk, exist := m.books[coinPair]
+	m.mtx.RUnlock()
+	if exist {
+		bk.addClosed(closedOrders(fills)...)
+	}
+	m.emitTrades(coinPair, fills)
+	if ok {
+		for _, f := range fills {
+			c <- f
+		}
+	}
+}
+
+// CancelOrder removes the open order from the book of specific coin pair, the accountID
+// must be the owner of the order. The cancelled order is returned, its RestAmt is the
+// amount that was not filled, and it's still a stop order if it was not triggered.
+// The order is returned because the rest amount is only known in the match goroutine at the
+// time of removal, the caller releases the balance reserved for it, reading the order
+// afterwards could race with the fills matched in between.
+func (m *Manager) CancelOrder(cp string, orderID uint64, accountID string) (Order, error) {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return Order{}, fmt.Errorf("coin pair:%s not supported", cp)
+	}
+
+	var od Order
+	var err error
+	if e := m.exec(cp, func() {
+		if od, err = bk.Cancel(orderID, accountID); err == nil {
+			bk.addClosed(od)
+			m.markDirty(cp)
+		}
+	}); e != nil {
+		return Order{}, e
+	}
+	return od, err
+}
+
+// exec runs fn in the match goroutine of the book, so the orders of the book are added,
+// cancelled and matched in one sequence, it returns after fn is done. fn is run directly
+// if the book is not started, ErrManagerStopped is returned if the manager is stopped.
+func (m *Manager) exec(cp string, fn func()) error {
+	m.mtx.RLock()
+	q, ok := m.cmds[cp]
+	closing := m.closing
+	m.mtx.RUnlock()
+	if !ok {
+		fn()
+		return nil
+	}
+
+	done := make(chan struct{})
+	select {
+	case <-closing:
+		return ErrManagerStopped
+	case q <- func() { fn(); close(done) }:
+	}
+	<-done
+	return nil
+}
+
+// GetOrder returns the order of specific coin pair and id, whether it's open or recently
+// closed, its Status tells the fill status. Returns ErrOrderNotFound if the order is unknown,
+// or it's closed long ago.
+func (m *Manager) GetOrder(cp string, orderID uint64) (Order, error) {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return Order{}, fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	return bk.GetOrder(orderID)
+}
+
+// HasOpenOrders checks if the account has open orders in any book.
+func (m *Manager) HasOpenOrders(accountID string) bool {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	for _, bk := range m.books {
+		if bk.HasOrders(accountID) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAccountOrders returns the orders of the account in every book, key coin pair. The open
+// orders come first, then the recent closed ones kept by the book unless openOnly is true,
+// the orders closed long ago are not returned.
+func (m *Manager) GetAccountOrders(accountID string, openOnly bool) map[string][]Order {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	ods := make(map[string][]Order)
+	for cp, bk := range m.books {
+		o := bk.AccountOrders(accountID)
+		if !openOnly {
+			o = append(o, bk.AccountClosedOrders(accountID)...)
+		}
+		if len(o) > 0 {
+			ods[cp] = o
+		}
+	}
+	return ods
+}
+
+// SetMinAmount sets the minimum order amount of specific coin pair, the
+// book is saved to local disk immediately.
+func (m *Manager) SetMinAmount(cp string, amt uint64) error {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	bk.SetMinAmount(amt)
+	return saveBook(cp, bk)
+}
+
+// SetTickSize sets the tick size of specific coin pair, the prices of new orders must be
+// multiples of it, 0 means any price. The book is saved to local disk immediately.
+func (m *Manager) SetTickSize(cp string, tick uint64) error {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	bk.SetTickSize(tick)
+	return saveBook(cp, bk)
+}
+
+// SetPriceBand sets the price band of specific coin pair in basis points, the limit orders
+// priced further than it from the last trade price are rejected by ValidatePriceBand, 0 means no band.
+func (m *Manager) SetPriceBand(cp string, bps uint64) error {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	bk.SetPriceBand(bps)
+	return saveBook(cp, bk)
+}
+
+// HaltPair halts the trading of specific coin pair, the new orders are rejected with ErrPairHalted,
+// and the matching is suspended, the orders in the book are kept and can still be cancelled.
+// The book is saved to local disk immediately, so it stays halted after restart.
+func (m *Manager) HaltPair(cp string) error {
+	return m.setHalted(cp, true)
+}
+
+// ResumePair resumes the trading of the halted coin pair, the book is matched by the next tick.
+func (m *Manager) ResumePair(cp string) error {
+	return m.setHalted(cp, false)
+}
+
+// IsHalted returns true if the trading of specific coin pair is halted.
+func (m *Manager) IsHalted(cp string) bool {
+	bk, ok := m.getBook(cp)
+	return ok && bk.Halted()
+}
+
+func (m *Manager) setHalted(cp string, halted bool) error {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return fmt.Errorf("coin pair:%s not supported", cp)
+	}
+
+	// the flag is set in sequence with the orders, so the order placed before returning is the last one.
+	if err := m.exec(cp, func() { bk.SetHalted(halted) }); err != nil {
+		return err
+	}
+	if halted {
+		logger.Info("trading of %s is halted", cp)
+	} else {
+		logger.Info("trading of %s is resumed", cp)
+	}
+	return saveBook(cp, bk)
+}
+
+// SetPriceDecimals sets the decimal places of the prices of specific coin pair, the book must
+// hold no orders. The book is saved to local disk immediately.
+func (m *Manager) SetPriceDecimals(cp string, decimals uint8) error {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	if err := bk.SetPriceDecimals(decimals); err != nil {
+		return err
+	}
+	return saveBook(cp, bk)
+}
+
+// Value returns the value price*amount of specific coin pair with the price decimals
+// of its book, rounded by r.
+func (m *Manager) Value(cp string, price, amount uint64, r Rounding) (uint64, error) {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return 0, fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	return Value(price, amount, bk.PriceDecimals(), r)
+}
+
+// ToBase returns the order with its amount in the base coin of specific coin pair, converted
+// with the price decimals of its book.
+func (m *Manager) ToBase(cp string, od Order) (Order, error) {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return od, fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	return od.ToBase(bk.PriceDecimals())
+}
+
+// MarketCost estimates the cost of the market bid of amount in specific coin pair
+// with the current asks.
+func (m *Manager) MarketCost(cp string, amount uint64) (uint64, error) {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return 0, fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	asks, _, err := bk.GetOrders(Ask, 0, math.MaxInt64)
+	if err != nil {
+		return 0, err
+	}
+	return MarketCost(asks, amount, bk.PriceDecimals())
+}
+
+// SetSelfTradePrevention sets the self-trade prevention mode of specific coin pair, the
+// book is saved to local disk immediately.
+func (m *Manager) SetSelfTradePrevention(cp string, mode STPMode) error {
+	if mode > STPReject {
+		return fmt.Errorf("unknow self-trade prevention mode:%d", mode)
+	}
+
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	bk.SetSelfTradePrevention(mode)
+	return saveBook(cp, bk)
+}
+
+// SetMaxOrders sets the max open orders of specific coin pair, and the policy for the
+// new order when the book is full, 0 means no limit. The book is saved to local disk immediately.
+func (m *Manager) SetMaxOrders(cp string, max int, policy FullPolicy) error {
+	if max < 0 {
+		return fmt.Errorf("invalid max orders:%d", max)
+	}
+
+	if policy > FullEvictWorst {
+		return fmt.Errorf("unknow full book policy:%d", policy)
+	}
+
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	bk.SetMaxOrders(max, policy)
+	return saveBook(cp, bk)
+}
+
+// SetFeeRate sets the fee rate in basis points charged to the taker of every trade,
+// the maker pays no fee.
+func (m *Manager) SetFeeRate(bps uint64) error {
+	if bps > MaxFeeRate {
+		return fmt.Errorf("fee rate:%d exceeds max fee rate:%d", bps, MaxFeeRate)
+	}
+	atomic.StoreUint64(&m.feeRate, bps)
+	return nil
+}
+
+// GetFeeRate returns the taker fee rate in basis points.
+func (m *Manager) GetFeeRate() uint64 {
+	return atomic.LoadUint64(&m.feeRate)
+}
+
+// TakerFee returns the fee charged to the taker who receives amt coins.
+func (m *Manager) TakerFee(amt uint64) uint64 {
+	return amt * m.GetFeeRate() / MaxFeeRate
 }
 
 // GetBook get specific coin pair's order book.
 // the return book is an copy of internal book, for thread safe.
-func (m *Manager) GetBook(coinPair string) Book {
-	return m.books[coinPair].Copy()
+func (m *Manager) GetBook(coinPair string) *Book {
+	bk, _ := m.getBook(coinPair)
+	return bk.Copy()
 }
 
-func (m *Manager) GetOrders(cp string, tp Type, start, end int64) ([]Order, error) {
-	if _, ok := m.books[cp]; !ok {
+// GetOrders returns the orders of specific coin pair and type in index range [start, end),
+// and the total number of the orders.
+func (m *Manager) GetOrders(cp string, tp Type, start, end int64) ([]Order, int, error) {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return []Order{}, 0, errors.New("get orders faile, err: unknow coin pair")
+	}
+	return bk.GetOrders(tp, start, end)
+}
+
+// GetOrdersByTime returns the orders of specific coin pair and type created in time range [start, end).
+func (m *Manager) GetOrdersByTime(cp string, tp Type, start, end int64) ([]Order, error) {
+	bk, ok := m.getBook(cp)
+	if !ok {
 		return []Order{}, errors.New("get orders faile, err: unknow coin pair")
 	}
-	return m.books[cp].GetOrders(tp, start, end), nil
+	return bk.GetOrdersByTime(tp, start, end)
 }
 
-func (m *Manager) RegisterOrderChan(coinPair string, c chan Order) {
+// GetDepth returns the aggregated bid and ask depth of specific coin pair,
+// each side has at most levels price levels.
+func (m *Manager) GetDepth(cp string, levels int) ([]DepthLevel, []DepthLevel, error) {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return []DepthLevel{}, []DepthLevel{}, fmt.Errorf("coin pair:%s not supported", cp)
+	}
+
+	if levels <= 0 {
+		return []DepthLevel{}, []DepthLevel{}, fmt.Errorf("invalid depth levels:%d", levels)
+	}
+
+	bids, asks := bk.Depth(levels)
+	return bids, asks, nil
+}
+
+// SetStopHandler sets the func which is called when the stop orders are triggered.
+func (m *Manager) SetStopHandler(fn StopHandler) {
+	m.mtx.Lock()
+	m.onStop = fn
+	m.mtx.Unlock()
+}
+
+// RegisterOrderChan register the channel which will receive the fills of specific coin pair.
+func (m *Manager) RegisterOrderChan(coinPair string, c chan Fill) {
+	m.mtx.Lock()
 	m.chans[coinPair] = c
+	m.mtx.Unlock()
+}
+
+// SetSaveInterval sets the interval of saving the changed books to local disk, the changes
+// in one interval are coalesced into one write of each book, so a crash loses at most the
+// changes of one interval. It must be called before Start.
+func (m *Manager) SetSaveInterval(d time.Duration) {
+	m.mtx.Lock()
+	m.saveInterval = d
+	m.mtx.Unlock()
 }
 
 // Run start the manager, tm is the match tick time, closing is used for stopping the manager from running.
+// It blocks until closing is closed and all the match goroutines are stopped, the changed books are
+// saved before it returns.
 func (m *Manager) Start(tm time.Duration, closing chan bool) {
-	// start the id generators
-	for _, g := range m.idg {
-		go g.Run(closing)
-	}
-
-	// start the match timer.
-	wg := sync.WaitGroup{}
-	for p, bk := range m.books {
-		wg.Add(1)
-		go func(cp string, b *Book, orderChan chan Order, c chan bool, w *sync.WaitGroup) {
-			orders := []Order{}
-			for {
-				select {
-				case <-c:
-					w.Done()
-					return
-				case <-time.After(tm):
-					orders = b.Match()
-					for _, o := range orders {
-						orderChan <- o
-					}
-					// update order book in local disk.
-					pairs := strings.Split(cp, "/")
-					if len(pairs) != 2 {
-						panic("error coin pair name")
-					}
-					filename := strings.Join(pairs, "_")
-					if err := util.SaveJSON(filepath.Join(orderDir, filename+"."+orderExt), b.Copy().ToMarshalable(), 0600); err != nil {
-						panic(err)
-					}
+	m.mtx.Lock()
+	m.tick = tm
+	m.closing = closing
+	for cp := range m.books {
+		m.startBook(cp)
+	}
+
+	m.wg.Add(1)
+	go func(d time.Duration) {
+		defer m.wg.Done()
+		for {
+			select {
+			case <-closing:
+				return
+			case <-time.After(d):
+				if err := m.Flush(); err != nil {
+					logger.Error("save order books failed: %v", err)
+				}
+			}
+		}
+	}(m.saveInterval)
+
+	if m.snapshotInterval > 0 {
+		m.wg.Add(1)
+		go func(d time.Duration) {
+			defer m.wg.Done()
+			m.runSnapshots(d, closing)
+		}(m.snapshotInterval)
+	}
+	m.mtx.Unlock()
+
+	<-closing
+	m.wg.Wait()
+	if err := m.Flush(); err != nil {
+		logger.Error("save order books failed: %v", err)
+	}
+}
+
+// startBook starts the id generator and the match goroutine of the book, m.mtx must be held.
+// The match goroutine runs the queued commands of AddOrder and CancelOrder between the
+// match ticks, it's the only one changing the orders of the book.
+func (m *Manager) startBook(cp string) {
+	m.wg.Add(1)
+	go func(idg *IDGenerator, c chan bool) {
+		defer m.wg.Done()
+		idg.Run(c)
+	}(m.idg[cp], m.closing)
+
+	q := make(chan func())
+	m.cmds[cp] = q
+	m.wg.Add(1)
+	go func(cp string, b *Book, tm time.Duration, c chan bool) {
+		defer m.wg.Done()
+		// the ticker is not reset by the commands, so the busy book is still matched.
+		ticker := time.NewTicker(tm)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c:
+				return
+			case fn := <-q:
+				fn()
+			case <-ticker.C:
+				// the halted book is neither expired nor matched.
+				if b.Halted() {
+					continue
+				}
+
+				// close the expired orders before matching.
+				expired := b.RemoveExpired(time.Now().Unix())
+				fills := make([]Fill, len(expired))
+				for i, od := range expired {
+					fills[i] = Fill{Order: od}
+				}
+				m.sendFills(cp, fills)
+
+				// the book is saved by the next flush if changed.
+				if m.matchBook(cp, b) || len(expired) > 0 {
+					m.markDirty(cp)
+				}
+			}
+		}
+	}(cp, m.books[cp], m.tick, m.closing)
+}
+
+// matchBook matches the crossed orders of the book, and activates the stop orders triggered
+// by the trades, the fills are sent to the order channel. Returns true if the book is changed.
+func (m *Manager) matchBook(cp string, b *Book) bool {
+	fills := b.Match()
+	m.sendFills(cp, fills)
+	return m.activateStops(cp, b) || len(fills) > 0
+}
+
+// activateStops converts the stop orders triggered by the last trade price into the
+// limit or market orders, and matches them immediately, the stop orders triggered by
+// the new trades are activated in turn. The triggered order is queued in the book as
+// created at the trigger time. The closing fill of the order which is dropped by the
+// StopHandler, or the market order without opposite orders, is sent to the order channel.
+// Returns true if any stop order is triggered.
+func (m *Manager) activateStops(cp string, b *Book) bool {
+	m.mtx.RLock()
+	onStop := m.onStop
+	m.mtx.RUnlock()
+
+	var triggered bool
+	for {
+		stops := b.TriggerStops()
+		if len(stops) == 0 {
+			return triggered
+		}
+		triggered = true
+
+		for _, od := range stops {
+			stop := od
+			od.StopPrice = 0
+			od.CreatedAt = time.Now().Unix()
+			if onStop != nil {
+				if err := onStop(cp, &od); err != nil {
+					// the stop order is closed without any balance reserved.
+					m.sendFills(cp, []Fill{{Order: stop}})
+					continue
 				}
 			}
-		}(p, bk, m.chans[p], closing, &wg)
+
+			switch {
+			case od.Kind == Market || od.TimeInForce == IOC:
+				match := b.MatchMarket
+				if od.Kind == Limit {
+					match = b.MatchIOC
+				}
+				fills, err := match(od)
+				if err != nil {
+					fills = []Fill{{Order: od}}
+				}
+				m.sendFills(cp, fills)
+			case od.Type == Bid:
+				b.AddBid(od)
+			default:
+				b.AddAsk(od)
+			}
+		}
+		m.sendFills(cp, b.Match())
+	}
+}
+
+// Save saves all the order books to local disk.
+func (m *Manager) Save() error {
+	m.dirtyMtx.Lock()
+	m.dirty = make(map[string]bool)
+	m.dirtyMtx.Unlock()
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	for cp, bk := range m.books {
+		if err := saveBook(cp, bk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveBook saves the order book of specific coin pair to local disk.
+func (m *Manager) SaveBook(cp string) error {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	return saveBook(cp, bk)
+}
+
+// markDirty marks the book of coin pair as changed, it's saved by the next flush.
+func (m *Manager) markDirty(cp string) {
+	m.dirtyMtx.Lock()
+	m.dirty[cp] = true
+	m.dirtyMtx.Unlock()
+}
+
+// Flush saves the books changed since the last flush to local disk, the books failed
+// to save are kept changed, and retried by the next flush.
+func (m *Manager) Flush() error {
+	m.dirtyMtx.Lock()
+	cps := m.dirty
+	m.dirty = make(map[string]bool)
+	m.dirtyMtx.Unlock()
+
+	var err error
+	for cp := range cps {
+		bk, ok := m.getBook(cp)
+		if !ok {
+			continue
+		}
+		if e := saveBook(cp, bk); e != nil {
+			m.markDirty(cp)
+			err = e
+		}
+	}
+	return err
+}
+
+// saveBook saves the order book of specific coin pair to local disk.
+func saveBook(cp string, bk *Book) error {
+	pairs := strings.Split(cp, "/")
+	if len(pairs) != 2 {
+		panic("error coin pair name")
 	}
-	wg.Wait()
+	filename := strings.Join(pairs, "_")
+	atomic.AddUint64(&bookWrites, 1)
+	return persist.SaveJSON(filepath.Join(orderDir, filename+"."+orderExt), bk.Copy().ToMarshalable(), 0600)
 }
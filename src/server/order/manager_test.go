@@ -1,11 +1,18 @@
 package order
 
 import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/skycoin/skycoin-exchange/src/server/trade"
 	"github.com/skycoin/skycoin/src/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -14,7 +21,7 @@ func TestManager(t *testing.T) {
 	m := NewManager()
 	coinPair := "btc/sky"
 	m.AddBook(coinPair, &Book{})
-	btcSkyChan := make(chan Order, 100)
+	btcSkyChan := make(chan Fill, 100)
 	m.RegisterOrderChan(coinPair, btcSkyChan)
 	closing := make(chan bool)
 	go m.Start(time.Duration(1)*time.Second, closing)
@@ -44,13 +51,13 @@ func TestManager(t *testing.T) {
 	}
 
 	totalMath := 0
-	go func(orders chan Order, c chan bool) {
+	go func(fills chan Fill, c chan bool) {
 		for {
 			select {
-			case od := <-orders:
+			case f := <-fills:
 				// assert.Equal(t, od.RestAmt, 0)
-				if od.RestAmt != 0 {
-					t.Fatal("match order's reset amt is not zero")
+				if f.Order.RestAmt != 0 {
+					t.Error("match order's reset amt is not zero")
 				}
 				totalMath += 1
 				// fmt.Printf("match order: type:%v, price:%d, amount:%d, restamt:%d\n", od.Type, od.Price, od.Amount, od.RestAmt)
@@ -67,6 +74,978 @@ func TestManager(t *testing.T) {
 	assert.Equal(t, totalMath, 8)
 }
 
+func TestManagerPartialFill(t *testing.T) {
+	m := NewManager()
+	coinPair := "btc/sky"
+	m.AddBook(coinPair, &Book{})
+	fillChan := make(chan Fill, 100)
+	m.RegisterOrderChan(coinPair, fillChan)
+	closing := make(chan bool)
+	go m.Start(time.Duration(100)*time.Millisecond, closing)
+	defer close(closing)
+
+	// RestAmt is initialized by AddOrder.
+	m.AddOrder(coinPair, Order{Type: Ask, Price: 100, CreatedAt: 132424, Amount: 1})
+	m.AddOrder(coinPair, Order{Type: Ask, Price: 101, CreatedAt: 132425, Amount: 2})
+	m.AddOrder(coinPair, Order{Type: Ask, Price: 102, CreatedAt: 132426, Amount: 3})
+	bid, err := m.AddOrder(coinPair, Order{Type: Bid, Price: 102, CreatedAt: 132427, Amount: 10})
+	assert.Nil(t, err)
+
+	var bidFills []uint64
+	for i := 0; i < 6; i++ {
+		select {
+		case f := <-fillChan:
+			if f.Order.ID == bid {
+				bidFills = append(bidFills, f.Amount)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("wait fills timeout")
+		}
+	}
+	assert.Equal(t, []uint64{1, 2, 3}, bidFills)
+
+	bids, _, err := m.GetOrders(coinPair, Bid, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(bids))
+	assert.Equal(t, uint64(4), bids[0].RestAmt)
+
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 102, Amount: 0})
+	assert.NotNil(t, err)
+}
+
+func TestManagerMarketOrder(t *testing.T) {
+	m := NewManager()
+	coinPair := "btc/sky"
+	m.AddBook(coinPair, &Book{})
+	fillChan := make(chan Fill, 100)
+	m.RegisterOrderChan(coinPair, fillChan)
+	closing := make(chan bool)
+	go m.Start(time.Duration(1)*time.Second, closing)
+	defer close(closing)
+
+	// reject market order when no liquidity.
+	_, err := m.AddOrder(coinPair, Order{Type: Ask, Kind: Market, Amount: 1})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, m.GetBook(coinPair).bids.len())
+
+	m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 132424, Amount: 1})
+	m.AddOrder(coinPair, Order{Type: Bid, Price: 98, CreatedAt: 132425, Amount: 2})
+	_, err = m.AddOrder(coinPair, Order{Type: Ask, Kind: Market, Price: 200, Amount: 2})
+	assert.Nil(t, err)
+
+	// market order is executed immediately.
+	assert.Equal(t, 4, len(fillChan))
+	prices := []uint64{}
+	for i := 0; i < 4; i++ {
+		f := <-fillChan
+		prices = append(prices, f.Price)
+	}
+	assert.Equal(t, []uint64{100, 100, 98, 98}, prices)
+	bids, _, err := m.GetOrders(coinPair, Bid, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(bids))
+	assert.Equal(t, uint64(1), bids[0].RestAmt)
+	assert.Equal(t, 0, m.GetBook(coinPair).asks.len())
+}
+
+func TestTradeHook(t *testing.T) {
+	m := NewManager()
+	coinPair := "btc/sky"
+	m.AddBook(coinPair, &Book{})
+	fillChan := make(chan Fill, 100)
+	m.RegisterOrderChan(coinPair, fillChan)
+
+	// the hooks are called in the order of registration.
+	trades := make(chan trade.Trade, 10)
+	var calls int32
+	m.RegisterTradeHook(func(t trade.Trade) {
+		atomic.AddInt32(&calls, 1)
+	})
+	m.RegisterTradeHook(func(t trade.Trade) {
+		trades <- t
+	})
+
+	closing := make(chan bool)
+	go m.Start(100*time.Millisecond, closing)
+	defer close(closing)
+
+	ask1, err := m.AddOrder(coinPair, Order{AccountID: "a", Type: Ask, Price: 100, CreatedAt: 132424, Amount: 1})
+	assert.Nil(t, err)
+	ask2, err := m.AddOrder(coinPair, Order{AccountID: "b", Type: Ask, Price: 101, CreatedAt: 132425, Amount: 3})
+	assert.Nil(t, err)
+	bid, err := m.AddOrder(coinPair, Order{AccountID: "c", Type: Bid, Price: 101, CreatedAt: 132426, Amount: 2})
+	assert.Nil(t, err)
+
+	wait := func() trade.Trade {
+		select {
+		case tr := <-trades:
+			return tr
+		case <-time.After(2 * time.Second):
+			t.Fatal("wait trade timeout")
+		}
+		return trade.Trade{}
+	}
+
+	// the trades of the matching loop are at the maker's price.
+	tr := wait()
+	assert.True(t, tr.Time > 0)
+	tr.Time = 0
+	assert.Equal(t, trade.Trade{Pair: coinPair, Price: 100, Amount: 1, Maker: "a", Taker: "c", MakerOrderID: ask1, TakerOrderID: bid}, tr)
+	tr = wait()
+	tr.Time = 0
+	assert.Equal(t, trade.Trade{Pair: coinPair, Price: 101, Amount: 1, Maker: "b", Taker: "c", MakerOrderID: ask2, TakerOrderID: bid}, tr)
+
+	// the market order trades are emitted immediately.
+	mkt, err := m.AddOrder(coinPair, Order{AccountID: "d", Type: Bid, Kind: Market, Amount: 2})
+	assert.Nil(t, err)
+	tr = wait()
+	tr.Time = 0
+	assert.Equal(t, trade.Trade{Pair: coinPair, Price: 101, Amount: 2, Maker: "b", Taker: "d", MakerOrderID: ask2, TakerOrderID: mkt}, tr)
+
+	select {
+	case tr := <-trades:
+		t.Fatalf("unexpected trade: %+v", tr)
+	case <-time.After(300 * time.Millisecond):
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestTradeHookNotBlocking(t *testing.T) {
+	m := NewManager()
+	coinPair := "btc/sky"
+	m.AddBook(coinPair, &Book{})
+	fillChan := make(chan Fill, 100)
+	m.RegisterOrderChan(coinPair, fillChan)
+
+	// the hook blocks until the test is done, the full queue drops the trades.
+	defer func(n int) { TradeHookQueueSize = n }(TradeHookQueueSize)
+	TradeHookQueueSize = 4
+	block := make(chan bool)
+	defer close(block)
+	m.RegisterTradeHook(func(t trade.Trade) {
+		<-block
+	})
+
+	closing := make(chan bool)
+	go m.Start(time.Second, closing)
+	defer close(closing)
+
+	n := 20
+	for i := 0; i < n; i++ {
+		_, err := m.AddOrder(coinPair, Order{Type: Ask, Price: 100, Amount: 1})
+		assert.Nil(t, err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := m.AddOrder(coinPair, Order{Type: Bid, Kind: Market, Amount: uint64(n)})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("matching is blocked by the trade hook")
+	}
+	assert.Equal(t, 2*n, len(fillChan))
+}
+
+func TestTimeInForce(t *testing.T) {
+	m := NewManager()
+	coinPair := "tif/sky"
+	m.AddBook(coinPair, &Book{})
+	fillChan := make(chan Fill, 100)
+	m.RegisterOrderChan(coinPair, fillChan)
+	closing := make(chan bool)
+	go m.Start(100*time.Millisecond, closing)
+	defer close(closing)
+
+	// GTC order rests in the book.
+	_, err := m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 132424, Amount: 2})
+	assert.Nil(t, err)
+	bids, _, _ := m.GetOrders(coinPair, Bid, 0, 10)
+	assert.Equal(t, 1, len(bids))
+
+	// IOC order is matched immediately, the unfilled amount never rests in the book.
+	id, err := m.AddOrder(coinPair, Order{Type: Ask, Price: 90, CreatedAt: 132425, Amount: 5, TimeInForce: IOC})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(fillChan))
+	f := <-fillChan
+	assert.Equal(t, id, f.Order.ID)
+	assert.Equal(t, uint64(2), f.Amount)
+	// executed at the price of the resting bid.
+	assert.Equal(t, uint64(100), f.Price)
+	<-fillChan
+	f = <-fillChan
+	assert.Equal(t, id, f.Order.ID)
+	assert.Equal(t, uint64(0), f.Amount)
+	assert.Equal(t, uint64(3), f.Order.RestAmt)
+	asks, _, _ := m.GetOrders(coinPair, Ask, 0, 10)
+	assert.Equal(t, 0, len(asks))
+	bids, _, _ = m.GetOrders(coinPair, Bid, 0, 10)
+	assert.Equal(t, 0, len(bids))
+
+	// IOC order without matched orders is closed.
+	_, err = m.AddOrder(coinPair, Order{Type: Ask, Price: 90, CreatedAt: 132426, Amount: 1, TimeInForce: IOC})
+	assert.Nil(t, err)
+	f = <-fillChan
+	assert.Equal(t, uint64(0), f.Amount)
+	assert.Equal(t, uint64(1), f.Order.RestAmt)
+
+	// GTD order must expire in the future.
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 132427, Amount: 1, TimeInForce: GTD})
+	assert.True(t, errors.Is(err, ErrInvalidExpiry))
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 132427, Amount: 1, TimeInForce: 10})
+	assert.NotNil(t, err)
+
+	// the expired GTD order is swept from the book.
+	id, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 132428, Amount: 1, TimeInForce: GTD, ExpireAt: time.Now().Unix() + 1})
+	assert.Nil(t, err)
+	bids, _, _ = m.GetOrders(coinPair, Bid, 0, 10)
+	assert.Equal(t, 1, len(bids))
+
+	select {
+	case f = <-fillChan:
+		assert.Equal(t, id, f.Order.ID)
+		assert.Equal(t, uint64(0), f.Amount)
+		assert.Equal(t, uint64(1), f.Order.RestAmt)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expired order is not swept")
+	}
+	bids, _, _ = m.GetOrders(coinPair, Bid, 0, 10)
+	assert.Equal(t, 0, len(bids))
+}
+
+func TestExpireWithoutOrderChan(t *testing.T) {
+	m := NewManager()
+	coinPair := "btc/sky"
+	m.AddBook(coinPair, &Book{})
+	closing := make(chan bool)
+	go m.Start(time.Duration(100)*time.Millisecond, closing)
+	defer close(closing)
+
+	// the expired order is closed, though no order channel is registered.
+	id, err := m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 132424, Amount: 1, TimeInForce: GTD, ExpireAt: time.Now().Unix() + 1})
+	assert.Nil(t, err)
+	for i := 0; i < 50; i++ {
+		if od, err := m.GetOrder(coinPair, id); err == nil && od.Closed {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	od, err := m.GetOrder(coinPair, id)
+	assert.Nil(t, err)
+	assert.True(t, od.Closed)
+
+	// the match goroutine is not blocked.
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 132425, Amount: 1})
+	assert.Nil(t, err)
+}
+
+func TestCancelOrder(t *testing.T) {
+	m := NewManager()
+	coinPair := "btc/sky"
+	m.AddBook(coinPair, &Book{})
+	fillChan := make(chan Fill, 100)
+	m.RegisterOrderChan(coinPair, fillChan)
+	closing := make(chan bool)
+	go m.Start(time.Duration(100)*time.Millisecond, closing)
+	defer close(closing)
+
+	bid, err := m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Price: 100, CreatedAt: 132424, Amount: 3})
+	assert.Nil(t, err)
+	ask, err := m.AddOrder(coinPair, Order{AccountID: "b", Type: Ask, Price: 200, CreatedAt: 132425, Amount: 1})
+	assert.Nil(t, err)
+
+	// cancel non-existent order.
+	_, err = m.CancelOrder(coinPair, 10000, "a")
+	assert.Equal(t, ErrOrderNotExist, err)
+
+	// cancel another account's order.
+	_, err = m.CancelOrder(coinPair, ask, "a")
+	assert.Equal(t, ErrNotOrderOwner, err)
+
+	// unknown coin pair.
+	_, err = m.CancelOrder("unknow/sky", bid, "a")
+	assert.NotNil(t, err)
+
+	// cancel partially filled order, the rest amount is returned.
+	_, err = m.AddOrder(coinPair, Order{AccountID: "b", Type: Ask, Price: 100, CreatedAt: 132426, Amount: 1})
+	assert.Nil(t, err)
+	<-fillChan
+	<-fillChan
+	od, err := m.CancelOrder(coinPair, bid, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), od.RestAmt)
+	bids, _, _ := m.GetOrders(coinPair, Bid, 0, 10)
+	assert.Equal(t, 0, len(bids))
+
+	// cancel already filled order.
+	bid, err = m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Price: 200, CreatedAt: 132427, Amount: 1})
+	assert.Nil(t, err)
+	<-fillChan
+	<-fillChan
+	_, err = m.CancelOrder(coinPair, bid, "a")
+	assert.Equal(t, ErrOrderNotExist, err)
+
+	// cancelled order can't be cancelled twice.
+	asks, _, _ := m.GetOrders(coinPair, Ask, 0, 10)
+	assert.Equal(t, 0, len(asks))
+	_, err = m.CancelOrder(coinPair, ask, "b")
+	assert.Equal(t, ErrOrderNotExist, err)
+}
+
+func TestGetOrder(t *testing.T) {
+	m := NewManager()
+	coinPair := "btc/sky"
+	m.AddBook(coinPair, &Book{})
+	fillChan := make(chan Fill, 100)
+	m.RegisterOrderChan(coinPair, fillChan)
+	closing := make(chan bool)
+	go m.Start(time.Duration(100)*time.Millisecond, closing)
+	defer close(closing)
+
+	// open order.
+	bid, err := m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Price: 100, CreatedAt: 132424, Amount: 3})
+	assert.Nil(t, err)
+	od, err := m.GetOrder(coinPair, bid)
+	assert.Nil(t, err)
+	assert.Equal(t, bid, od.ID)
+	assert.Equal(t, StatusOpen, od.Status())
+
+	// partially filled order.
+	ask, err := m.AddOrder(coinPair, Order{AccountID: "b", Type: Ask, Price: 100, CreatedAt: 132425, Amount: 1})
+	assert.Nil(t, err)
+	<-fillChan
+	<-fillChan
+	od, err = m.GetOrder(coinPair, bid)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), od.RestAmt)
+	assert.Equal(t, StatusPartial, od.Status())
+
+	// fully filled order is found in the closed orders.
+	od, err = m.GetOrder(coinPair, ask)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), od.RestAmt)
+	assert.Equal(t, StatusFilled, od.Status())
+
+	// cancelled order keeps its rest amount.
+	_, err = m.CancelOrder(coinPair, bid, "a")
+	assert.Nil(t, err)
+	od, err = m.GetOrder(coinPair, bid)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), od.RestAmt)
+	assert.Equal(t, StatusClosed, od.Status())
+
+	// missing order and unknown coin pair.
+	_, err = m.GetOrder(coinPair, 10000)
+	assert.Equal(t, ErrOrderNotFound, err)
+	_, err = m.GetOrder("unknow/sky", bid)
+	assert.NotNil(t, err)
+
+	// the closed orders are saved with the book.
+	bk := m.GetBook(coinPair)
+	od, err = NewBookFromJson(bk.ToMarshalable()).GetOrder(ask)
+	assert.Nil(t, err)
+	assert.Equal(t, StatusFilled, od.Status())
+}
+
+func TestGetAccountOrders(t *testing.T) {
+	m := NewManager()
+	btc, ltc := "btc/sky", "ltc/sky"
+	m.AddBook(btc, &Book{})
+	m.AddBook(ltc, &Book{})
+	fillChan := make(chan Fill, 100)
+	m.RegisterOrderChan(btc, fillChan)
+	closing := make(chan bool)
+	go m.Start(time.Duration(100)*time.Millisecond, closing)
+	defer close(closing)
+
+	add := func(cp string, od Order) uint64 {
+		id, err := m.AddOrder(cp, od)
+		assert.Nil(t, err)
+		return id
+	}
+	a1 := add(btc, Order{AccountID: "a", Type: Bid, Price: 100, CreatedAt: 1, Amount: 3})
+	a2 := add(btc, Order{AccountID: "a", Type: Bid, Price: 101, CreatedAt: 2, Amount: 1})
+	a3 := add(btc, Order{AccountID: "a", Type: Ask, Price: 200, CreatedAt: 3, Amount: 2})
+	a4 := add(btc, Order{AccountID: "a", Type: Bid, Price: 150, StopPrice: 150, CreatedAt: 4, Amount: 2})
+	a5 := add(ltc, Order{AccountID: "a", Type: Ask, Price: 50, CreatedAt: 5, Amount: 7})
+	b1 := add(btc, Order{AccountID: "b", Type: Bid, Price: 100, CreatedAt: 6, Amount: 4})
+	b2 := add(ltc, Order{AccountID: "b", Type: Bid, Price: 40, CreatedAt: 7, Amount: 1})
+
+	ids := func(ods []Order) []uint64 {
+		ids := []uint64{}
+		for _, od := range ods {
+			ids = append(ids, od.ID)
+		}
+		return ids
+	}
+
+	// the stop orders come first, then the bids and asks in priority order.
+	ods := m.GetAccountOrders("a", true)
+	assert.Equal(t, 2, len(ods))
+	assert.Equal(t, []uint64{a4, a2, a1, a3}, ids(ods[btc]))
+	assert.Equal(t, []uint64{a5}, ids(ods[ltc]))
+	ods = m.GetAccountOrders("b", true)
+	assert.Equal(t, []uint64{b1}, ids(ods[btc]))
+	assert.Equal(t, []uint64{b2}, ids(ods[ltc]))
+	assert.Equal(t, 0, len(m.GetAccountOrders("c", false)))
+
+	// the bid of a at 101 is filled, and a partially fills its ask at 200 by the IOC bid of b.
+	add(btc, Order{AccountID: "c", Type: Ask, Price: 101, CreatedAt: 8, Amount: 1})
+	<-fillChan
+	<-fillChan
+	b3 := add(btc, Order{AccountID: "b", Type: Bid, Price: 200, CreatedAt: 9, Amount: 1, TimeInForce: IOC})
+	<-fillChan
+	<-fillChan
+	_, err := m.CancelOrder(ltc, a5, "a")
+	assert.Nil(t, err)
+
+	ods = m.GetAccountOrders("a", true)
+	assert.Equal(t, []uint64{a4, a1, a3}, ids(ods[btc]))
+	assert.Equal(t, uint64(1), ods[btc][2].RestAmt)
+	_, ok := ods[ltc]
+	assert.False(t, ok)
+
+	// the closed orders follow the open ones, the latest closed first.
+	ods = m.GetAccountOrders("a", false)
+	assert.Equal(t, []uint64{a4, a1, a3, a2}, ids(ods[btc]))
+	assert.Equal(t, StatusFilled, ods[btc][3].Status())
+	assert.Equal(t, []uint64{a5}, ids(ods[ltc]))
+	assert.Equal(t, StatusClosed, ods[ltc][0].Status())
+
+	// the other accounts are not affected.
+	ods = m.GetAccountOrders("b", false)
+	assert.Equal(t, []uint64{b1, b3}, ids(ods[btc]))
+	assert.Equal(t, []uint64{b2}, ids(ods[ltc]))
+	assert.False(t, m.HasOpenOrders("c"))
+	assert.Equal(t, 1, len(m.GetAccountOrders("c", false)[btc]))
+}
+
+func TestMaxClosedOrders(t *testing.T) {
+	n := MaxClosedOrders
+	MaxClosedOrders = 2
+	defer func() { MaxClosedOrders = n }()
+
+	bk := &Book{}
+	bk.addClosed(Order{ID: 1, Amount: 1, RestAmt: 1}, Order{ID: 2, Amount: 1}, Order{ID: 3, Amount: 1})
+
+	// only the recent closed orders are kept.
+	_, err := bk.GetOrder(1)
+	assert.Equal(t, ErrOrderNotFound, err)
+	od, err := bk.GetOrder(3)
+	assert.Nil(t, err)
+	assert.True(t, od.Closed)
+}
+
+func TestMinOrderAmount(t *testing.T) {
+	m := NewManager()
+	coinPair := "min/sky"
+	m.AddBook(coinPair, &Book{})
+	closing := make(chan bool)
+	go m.Start(time.Duration(100)*time.Millisecond, closing)
+	defer close(closing)
+
+	assert.Nil(t, m.SetMinAmount(coinPair, 10))
+	assert.NotNil(t, m.SetMinAmount("unknow/sky", 10))
+
+	// rejected.
+	_, err := m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 132424, Amount: 9})
+	assert.True(t, errors.Is(err, ErrBelowMinAmount))
+	_, err = m.AddOrder(coinPair, Order{Type: Ask, Kind: Market, Amount: 1})
+	assert.True(t, errors.Is(err, ErrBelowMinAmount))
+
+	// accepted.
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 132425, Amount: 10})
+	assert.Nil(t, err)
+	_, err = m.AddOrder(coinPair, Order{Type: Ask, Price: 200, CreatedAt: 132426, Amount: 11})
+	assert.Nil(t, err)
+	bids, _, _ := m.GetOrders(coinPair, Bid, 0, 10)
+	assert.Equal(t, 1, len(bids))
+
+	// the min amount is persisted with the book.
+	lm, err := LoadManager()
+	assert.Nil(t, err)
+	bk := lm.GetBook(coinPair)
+	assert.Equal(t, uint64(10), bk.MinAmount())
+}
+
+func TestTickSize(t *testing.T) {
+	m := NewManager()
+	coinPair := "tick/sky"
+	m.AddBook(coinPair, &Book{})
+	closing := make(chan bool)
+	go m.Start(time.Duration(100)*time.Millisecond, closing)
+	defer close(closing)
+
+	assert.NotNil(t, m.SetTickSize("unknow/sky", 5))
+	assert.Nil(t, m.SetTickSize(coinPair, 5))
+
+	// off the tick grid.
+	_, err := m.AddOrder(coinPair, Order{Type: Bid, Price: 101, CreatedAt: 1, Amount: 1})
+	assert.True(t, errors.Is(err, ErrOffTick))
+	_, err = m.AddOrder(coinPair, Order{Type: Ask, Price: 204, CreatedAt: 2, Amount: 1})
+	assert.True(t, errors.Is(err, ErrOffTick))
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 150, StopPrice: 161, Amount: 1})
+	assert.True(t, errors.Is(err, ErrOffTick))
+
+	// on the tick grid, the price of market order is ignored.
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 3, Amount: 1})
+	assert.Nil(t, err)
+	_, err = m.AddOrder(coinPair, Order{Type: Ask, Price: 205, CreatedAt: 4, Amount: 1})
+	assert.Nil(t, err)
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 150, StopPrice: 160, Amount: 1})
+	assert.Nil(t, err)
+	_, err = m.AddOrder(coinPair, Order{Type: Ask, Kind: Market, Price: 3, Amount: 1})
+	assert.Nil(t, err)
+
+	bids, _, _ := m.GetOrders(coinPair, Bid, 0, 10)
+	asks, _, _ := m.GetOrders(coinPair, Ask, 0, 10)
+	assert.Equal(t, 0, len(bids))
+	assert.Equal(t, 1, len(asks))
+
+	// the tick size is persisted with the book.
+	lm, err := LoadManager()
+	assert.Nil(t, err)
+	bk := lm.GetBook(coinPair)
+	assert.Equal(t, uint64(5), bk.TickSize())
+
+	// 0 accepts any price.
+	assert.Nil(t, m.SetTickSize(coinPair, 0))
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 101, CreatedAt: 5, Amount: 1})
+	assert.Nil(t, err)
+}
+
+func TestPriceDecimals(t *testing.T) {
+	m := NewManager()
+	coinPair := "decimals/sky"
+	m.AddBook(coinPair, &Book{})
+	closing := make(chan bool)
+	go m.Start(time.Duration(100)*time.Millisecond, closing)
+	defer close(closing)
+
+	assert.NotNil(t, m.SetPriceDecimals("unknow/sky", 2))
+	assert.NotNil(t, m.SetPriceDecimals(coinPair, MaxPriceDecimals+1))
+	assert.Nil(t, m.SetPriceDecimals(coinPair, 2))
+
+	// 1.50 * 3 = 4.5, the paid value is rounded up, and the received one is rounded down.
+	v, err := m.Value(coinPair, 150, 3, RoundUp)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), v)
+	v, err = m.Value(coinPair, 150, 3, RoundDown)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(4), v)
+	_, err = m.Value("unknow/sky", 150, 3, RoundUp)
+	assert.NotNil(t, err)
+
+	// the value overflows at the price or the stop price.
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: math.MaxUint64, CreatedAt: 1, Amount: 101})
+	assert.True(t, errors.Is(err, ErrValueOverflow))
+	_, err = m.AddOrder(coinPair, Order{Type: Ask, Price: 1 << 40, CreatedAt: 2, Amount: 1 << 40})
+	assert.True(t, errors.Is(err, ErrValueOverflow))
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 100, StopPrice: math.MaxUint64, Amount: 101})
+	assert.True(t, errors.Is(err, ErrValueOverflow))
+
+	// the product exceeds 64 bits, but the scaled value fits.
+	_, err = m.AddOrder(coinPair, Order{Type: Ask, Price: math.MaxUint64, CreatedAt: 3, Amount: 100})
+	assert.Nil(t, err)
+	_, err = m.AddOrder(coinPair, Order{Type: Bid, Price: 150, CreatedAt: 4, Amount: 3})
+	assert.Nil(t, err)
+	cost, err := m.MarketCost(coinPair, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(math.MaxUint64)/10+1, cost)
+
+	// the decimals can't be changed while the book holds orders.
+	assert.NotNil(t, m.SetPriceDecimals(coinPair, 4))
+	bk := m.GetBook(coinPair)
+	assert.Equal(t, uint8(2), bk.PriceDecimals())
+
+	// the decimals are persisted with the book.
+	lm, err := LoadManager()
+	assert.Nil(t, err)
+	bk = lm.GetBook(coinPair)
+	assert.Equal(t, uint8(2), bk.PriceDecimals())
+}
+
+func TestMaxOrders(t *testing.T) {
+	m := NewManager()
+	coinPair := "max/sky"
+	m.AddBook(coinPair, &Book{})
+	fillChan := make(chan Fill, 100)
+	m.RegisterOrderChan(coinPair, fillChan)
+	closing := make(chan bool)
+	go m.Start(time.Hour, closing)
+	defer close(closing)
+
+	assert.NotNil(t, m.SetMaxOrders("unknow/sky", 3, FullReject))
+	assert.NotNil(t, m.SetMaxOrders(coinPair, -1, FullReject))
+	assert.NotNil(t, m.SetMaxOrders(coinPair, 3, FullEvictWorst+1))
+	assert.Nil(t, m.SetMaxOrders(coinPair, 3, FullReject))
+
+	// fill the book to the limit, the stop order counts.
+	worst, err := m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Price: 100, CreatedAt: 1, Amount: 1})
+	assert.Nil(t, err)
+	_, err = m.AddOrder(coinPair, Order{AccountID: "a", Type: Ask, Price: 200, CreatedAt: 2, Amount: 1})
+	assert.Nil(t, err)
+	_, err = m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Price: 150, StopPrice: 160, Amount: 1})
+	assert.Nil(t, err)
+
+	// rejected, even if it's better priced.
+	_, err = m.AddOrder(coinPair, Order{AccountID: "b", Type: Bid, Price: 120, CreatedAt: 3, Amount: 1})
+	assert.Equal(t, ErrBookFull, err)
+	// the orders never resting in the book are not limited.
+	_, err = m.AddOrder(coinPair, Order{AccountID: "b", Type: Bid, Price: 120, TimeInForce: IOC, Amount: 1})
+	assert.Nil(t, err)
+	<-fillChan
+
+	// evict the worst-priced order of the same side.
+	assert.Nil(t, m.SetMaxOrders(coinPair, 3, FullEvictWorst))
+	_, err = m.AddOrder(coinPair, Order{AccountID: "b", Type: Bid, Price: 90, CreatedAt: 4, Amount: 1})
+	assert.Equal(t, ErrBookFull, err)
+	_, err = m.AddOrder(coinPair, Order{AccountID: "b", Type: Bid, Price: 100, CreatedAt: 4, Amount: 1})
+	assert.Equal(t, ErrBookFull, err)
+	_, err = m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Price: 150, StopPrice: 160, Amount: 1})
+	assert.Equal(t, ErrBookFull, err)
+
+	id, err := m.AddOrder(coinPair, Order{AccountID: "b", Type: Bid, Price: 120, CreatedAt: 5, Amount: 2})
+	assert.Nil(t, err)
+	f := <-fillChan
+	assert.Equal(t, worst, f.Order.ID)
+	assert.Equal(t, uint64(1), f.Order.RestAmt)
+	assert.Equal(t, uint64(0), f.Amount)
+	bids, _, _ := m.GetOrders(coinPair, Bid, 0, 10)
+	assert.Equal(t, 1, len(bids))
+	assert.Equal(t, id, bids[0].ID)
+
+	// the opposite side is evicted if the order's side is empty.
+	_, err = m.CancelOrder(coinPair, id, "b")
+	assert.Nil(t, err)
+	_, err = m.AddOrder(coinPair, Order{AccountID: "b", Type: Ask, Price: 300, CreatedAt: 6, Amount: 1})
+	assert.Nil(t, err)
+	_, err = m.AddOrder(coinPair, Order{AccountID: "b", Type: Bid, Price: 50, CreatedAt: 7, Amount: 1})
+	assert.Nil(t, err)
+	f = <-fillChan
+	assert.Equal(t, Ask, f.Order.Type)
+	assert.Equal(t, uint64(300), f.Order.Price)
+
+	// the limit is persisted with the book.
+	lm, err := LoadManager()
+	assert.Nil(t, err)
+	bk := lm.GetBook(coinPair)
+	max, policy := bk.MaxOrders()
+	assert.Equal(t, 3, max)
+	assert.Equal(t, FullEvictWorst, policy)
+}
+
+func TestSelfTradePrevention(t *testing.T) {
+	// the bid of account a crosses its own ask, and the ask of b behind it.
+	newBook := func(mode STPMode) *Book {
+		bk := &Book{}
+		bk.SetSelfTradePrevention(mode)
+		bk.AddAsk(Order{ID: 1, AccountID: "a", Type: Ask, Price: 100, CreatedAt: 1, Amount: 2, RestAmt: 2})
+		bk.AddAsk(Order{ID: 3, AccountID: "b", Type: Ask, Price: 101, CreatedAt: 3, Amount: 1, RestAmt: 1})
+		return bk
+	}
+	bid := Order{ID: 2, AccountID: "a", Type: Bid, Price: 101, CreatedAt: 2, Amount: 1, RestAmt: 1}
+
+	type fill struct {
+		id      uint64
+		amount  uint64
+		restAmt uint64
+	}
+	testData := []struct {
+		mode      STPMode
+		fills     []fill
+		askIDs    []uint64
+		mktFills  []fill
+		mktAskIDs []uint64
+	}{
+		{
+			mode:      STPNone,
+			fills:     []fill{{2, 1, 0}, {1, 1, 1}},
+			askIDs:    []uint64{1, 3},
+			mktFills:  []fill{{4, 1, 0}, {1, 1, 1}},
+			mktAskIDs: []uint64{1, 3},
+		},
+		{
+			mode:      STPCancelNewest,
+			fills:     []fill{{2, 0, 1}},
+			askIDs:    []uint64{1, 3},
+			mktFills:  []fill{{4, 0, 1}},
+			mktAskIDs: []uint64{1, 3},
+		},
+		{
+			mode:      STPCancelOldest,
+			fills:     []fill{{1, 0, 2}, {2, 1, 0}, {3, 1, 0}},
+			askIDs:    []uint64{},
+			mktFills:  []fill{{1, 0, 2}, {4, 1, 0}, {3, 1, 0}},
+			mktAskIDs: []uint64{},
+		},
+		{
+			// the orders that still meet are handled as cancel newest.
+			mode:      STPReject,
+			fills:     []fill{{2, 0, 1}},
+			askIDs:    []uint64{1, 3},
+			mktFills:  []fill{{4, 0, 1}},
+			mktAskIDs: []uint64{1, 3},
+		},
+	}
+
+	toFills := func(fs []Fill) []fill {
+		r := []fill{}
+		for _, f := range fs {
+			r = append(r, fill{f.Order.ID, f.Amount, f.Order.RestAmt})
+		}
+		return r
+	}
+	askIDs := func(bk *Book) []uint64 {
+		ids := []uint64{}
+		for _, od := range bk.asks.orders() {
+			ids = append(ids, od.ID)
+		}
+		return ids
+	}
+
+	for _, d := range testData {
+		bk := newBook(d.mode)
+		bk.AddBid(bid)
+		assert.Equal(t, d.fills, toFills(bk.Match()), d.mode.String())
+		assert.Equal(t, d.askIDs, askIDs(bk), d.mode.String())
+		assert.Equal(t, 0, bk.bids.len(), d.mode.String())
+
+		// the market order is always the newest one.
+		bk = newBook(d.mode)
+		fills, err := bk.MatchMarket(Order{ID: 4, AccountID: "a", Type: Bid, Kind: Market, Amount: 1, RestAmt: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, d.mktFills, toFills(fills), d.mode.String())
+		assert.Equal(t, d.mktAskIDs, askIDs(bk), d.mode.String())
+	}
+}
+
+func TestSelfTradeReject(t *testing.T) {
+	m := NewManager()
+	coinPair := "stp/sky"
+	m.AddBook(coinPair, &Book{})
+	closing := make(chan bool)
+	go m.Start(time.Hour, closing)
+	defer close(closing)
+
+	assert.NotNil(t, m.SetSelfTradePrevention("unknow/sky", STPReject))
+	assert.NotNil(t, m.SetSelfTradePrevention(coinPair, STPReject+1))
+	assert.Nil(t, m.SetSelfTradePrevention(coinPair, STPReject))
+
+	_, err := m.AddOrder(coinPair, Order{AccountID: "a", Type: Ask, Price: 100, CreatedAt: 1, Amount: 2})
+	assert.Nil(t, err)
+
+	// crossing the own ask is rejected.
+	_, err = m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Price: 100, CreatedAt: 2, Amount: 1})
+	assert.Equal(t, ErrSelfTrade, err)
+	_, err = m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Kind: Market, Amount: 1})
+	assert.Equal(t, ErrSelfTrade, err)
+	_, err = m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Price: 100, TimeInForce: IOC, Amount: 1})
+	assert.Equal(t, ErrSelfTrade, err)
+
+	// the orders not crossing, or of other account, are accepted.
+	_, err = m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Price: 99, CreatedAt: 3, Amount: 1})
+	assert.Nil(t, err)
+	_, err = m.AddOrder(coinPair, Order{AccountID: "b", Type: Bid, Price: 100, CreatedAt: 4, Amount: 1})
+	assert.Nil(t, err)
+
+	// the own ask is not reached if the order is satisfied by the better ones.
+	_, err = m.AddOrder(coinPair, Order{AccountID: "c", Type: Ask, Price: 98, CreatedAt: 5, Amount: 1})
+	assert.Nil(t, err)
+	_, err = m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Price: 100, CreatedAt: 6, Amount: 2})
+	assert.Equal(t, ErrSelfTrade, err)
+	_, err = m.AddOrder(coinPair, Order{AccountID: "a", Type: Bid, Price: 100, CreatedAt: 6, Amount: 1})
+	assert.Nil(t, err)
+
+	// the mode is persisted with the book.
+	lm, err := LoadManager()
+	assert.Nil(t, err)
+	bk := lm.GetBook(coinPair)
+	assert.Equal(t, STPReject, bk.SelfTradePrevention())
+}
+
+func TestFeeRate(t *testing.T) {
+	m := NewManager()
+	assert.Equal(t, uint64(0), m.GetFeeRate())
+	assert.Equal(t, uint64(0), m.TakerFee(10000))
+
+	// 0.25%
+	assert.Nil(t, m.SetFeeRate(25))
+	assert.Equal(t, uint64(25), m.GetFeeRate())
+	assert.Equal(t, uint64(25), m.TakerFee(10000))
+	assert.Equal(t, uint64(2), m.TakerFee(999))
+	assert.Equal(t, uint64(0), m.TakerFee(10))
+
+	assert.NotNil(t, m.SetFeeRate(MaxFeeRate+1))
+	assert.Equal(t, uint64(25), m.GetFeeRate())
+}
+
+func TestGetDepth(t *testing.T) {
+	m := NewManager()
+	coinPair := "btc/sky"
+	m.AddBook(coinPair, &Book{})
+	closing := make(chan bool)
+	go m.Start(time.Duration(1)*time.Second, closing)
+	defer close(closing)
+
+	// empty book.
+	bids, asks, err := m.GetDepth(coinPair, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, []DepthLevel{}, bids)
+	assert.Equal(t, []DepthLevel{}, asks)
+
+	m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 132424, Amount: 1})
+	m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 132425, Amount: 2})
+	m.AddOrder(coinPair, Order{Type: Ask, Price: 200, CreatedAt: 132426, Amount: 5})
+	bids, asks, err = m.GetDepth(coinPair, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, []DepthLevel{{Price: 100, TotalAmount: 3}}, bids)
+	assert.Equal(t, []DepthLevel{{Price: 200, TotalAmount: 5}}, asks)
+
+	_, _, err = m.GetDepth(coinPair, 0)
+	assert.NotNil(t, err)
+	_, _, err = m.GetDepth("unknow/sky", 10)
+	assert.NotNil(t, err)
+}
+
+func TestGetTicker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ticker")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	m := NewManager()
+	m.AddBook("eth/sky", &Book{})
+
+	// the books are copied when added.
+	bidBk := &Book{}
+	bidBk.AddBid(Order{ID: 1, Type: Bid, Price: 100, CreatedAt: 1, Amount: 5, RestAmt: 5})
+	m.AddBook("ltc/sky", bidBk)
+
+	coinPair := "btc/sky"
+	bk := &Book{}
+	bk.AddBid(Order{ID: 1, Type: Bid, Price: 100, CreatedAt: 1, Amount: 5, RestAmt: 5})
+	bk.AddBid(Order{ID: 2, Type: Bid, Price: 98, CreatedAt: 2, Amount: 5, RestAmt: 5})
+	bk.AddAsk(Order{ID: 3, Type: Ask, Price: 105, CreatedAt: 3, Amount: 5, RestAmt: 5})
+	bk.AddAsk(Order{ID: 4, Type: Ask, Price: 103, CreatedAt: 4, Amount: 5, RestAmt: 5})
+	m.AddBook(coinPair, bk)
+
+	// empty book without trade log.
+	tk, err := m.GetTicker("eth/sky")
+	assert.Nil(t, err)
+	assert.Equal(t, "eth/sky", tk.Pair)
+	assert.Nil(t, tk.BestBid)
+	assert.Nil(t, tk.BestAsk)
+	assert.Nil(t, tk.Spread)
+	assert.Nil(t, tk.LastPrice)
+	assert.Equal(t, uint64(0), tk.Volume)
+
+	// one side only.
+	tk, err = m.GetTicker("ltc/sky")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), *tk.BestBid)
+	assert.Nil(t, tk.BestAsk)
+	assert.Nil(t, tk.Spread)
+
+	tl, err := trade.NewTradeLog(filepath.Join(dir, "trades.log"))
+	assert.Nil(t, err)
+	defer tl.Close()
+	m.SetTradeLog(tl)
+
+	// no trade yet.
+	tk, err = m.GetTicker(coinPair)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), *tk.BestBid)
+	assert.Equal(t, uint64(103), *tk.BestAsk)
+	assert.Equal(t, uint64(3), *tk.Spread)
+	assert.Nil(t, tk.LastPrice)
+	assert.Equal(t, uint64(0), tk.Volume)
+
+	// the trade older than 24 hours is not in the volume.
+	now := time.Now().Unix()
+	for _, td := range []trade.Trade{
+		{Pair: coinPair, Price: 90, Amount: 7, Time: now - 25*3600},
+		{Pair: coinPair, Price: 99, Amount: 2, Time: now - 3600},
+		{Pair: "ltc/sky", Price: 10, Amount: 100, Time: now - 48*3600},
+		{Pair: coinPair, Price: 101, Amount: 3, Time: now - 60},
+	} {
+		assert.Nil(t, tl.Append(td))
+	}
+
+	tk, err = m.GetTicker(coinPair)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(101), *tk.LastPrice)
+	assert.Equal(t, uint64(5), tk.Volume)
+	assert.True(t, tk.Time >= now)
+
+	// the last price is kept after the window.
+	tk, err = m.GetTicker("ltc/sky")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), *tk.LastPrice)
+	assert.Equal(t, uint64(0), tk.Volume)
+
+	_, err = m.GetTicker("unknow/sky")
+	assert.NotNil(t, err)
+}
+
+func TestFindRoute(t *testing.T) {
+	m := NewManager()
+	assert.Nil(t, m.SetFeeRate(100))
+
+	ab := &Book{}
+	ab.AddBid(Order{ID: 1, Type: Bid, Price: 100, CreatedAt: 1, Amount: 5, RestAmt: 5})
+	ab.AddBid(Order{ID: 2, Type: Bid, Price: 90, CreatedAt: 2, Amount: 10, RestAmt: 10})
+	m.AddBook("aaa/bbb", ab)
+
+	cb := &Book{}
+	cb.AddAsk(Order{ID: 1, Type: Ask, Price: 20, CreatedAt: 1, Amount: 10, RestAmt: 10})
+	cb.AddAsk(Order{ID: 2, Type: Ask, Price: 25, CreatedAt: 2, Amount: 100, RestAmt: 100})
+	m.AddBook("ccc/bbb", cb)
+
+	// the direct book gives 8 * 3 = 24 ccc only.
+	ac := &Book{}
+	ac.AddBid(Order{ID: 1, Type: Bid, Price: 3, CreatedAt: 1, Amount: 100, RestAmt: 100})
+	m.AddBook("aaa/ccc", ac)
+
+	// sell 8 aaa: 5 * 100 + 3 * 90 = 770 bbb, fee 7.
+	// buy ccc with 763 bbb: 10 * 20 + 22 * 25 = 750 bbb spent, 32 ccc bought, fee 0.
+	r, err := m.FindRoute("aaa", "ccc", 8)
+	assert.Nil(t, err)
+	assert.Equal(t, Route{
+		From:      "aaa",
+		To:        "ccc",
+		AmountIn:  8,
+		AmountOut: 32,
+		Hops: []Hop{
+			{Pair: "aaa/bbb", Type: Ask, From: "aaa", To: "bbb", AmountIn: 8, AmountOut: 763, Fee: 7},
+			{Pair: "ccc/bbb", Type: Bid, From: "bbb", To: "ccc", AmountIn: 750, AmountOut: 32, Fee: 0},
+		},
+	}, r)
+	assert.Equal(t, float64(4), r.Rate())
+
+	// the route is read only.
+	bids, _, err := m.GetDepth("aaa/bbb", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, []DepthLevel{{Price: 100, TotalAmount: 5}, {Price: 90, TotalAmount: 10}}, bids)
+
+	// the two hop route can't take 1000 aaa, the direct book can.
+	r, err = m.FindRoute("aaa", "ccc", 100)
+	assert.Nil(t, err)
+	assert.Equal(t, []Hop{{Pair: "aaa/ccc", Type: Ask, From: "aaa", To: "ccc", AmountIn: 100, AmountOut: 297, Fee: 3}}, r.Hops)
+
+	_, err = m.FindRoute("aaa", "ccc", 1000)
+	assert.True(t, errors.Is(err, ErrNoRoute))
+	_, err = m.FindRoute("aaa", "ddd", 8)
+	assert.True(t, errors.Is(err, ErrNoRoute))
+	_, err = m.FindRoute("aaa", "aaa", 8)
+	assert.NotNil(t, err)
+	_, err = m.FindRoute("aaa", "ccc", 0)
+	assert.NotNil(t, err)
+}
+
 func TestLoadManager(t *testing.T) {
 	// prepare data
 	coinPair := []string{"test", "sky"}
@@ -101,5 +1080,420 @@ func TestLoadManager(t *testing.T) {
 	m, err := LoadManager()
 	assert.Nil(t, err)
 	bk1 := m.GetBook(strings.Join(coinPair, "/"))
-	assert.Equal(t, bk, bk1)
+	assert.Equal(t, bk.ToMarshalable(), bk1.ToMarshalable())
+}
+
+func TestStopOrder(t *testing.T) {
+	m := NewManager()
+	coinPair := "stop/sky"
+	m.AddBook(coinPair, &Book{})
+	fillChan := make(chan Fill, 100)
+	m.RegisterOrderChan(coinPair, fillChan)
+
+	var mtx sync.Mutex
+	triggered := []Order{}
+	m.SetStopHandler(func(cp string, od *Order) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		triggered = append(triggered, *od)
+		if od.AccountID == "poor" {
+			return errors.New("balance is not sufficient")
+		}
+		return nil
+	})
+	closing := make(chan bool)
+	go m.Start(50*time.Millisecond, closing)
+	defer close(closing)
+
+	waitFill := func(id uint64) Fill {
+		for {
+			select {
+			case f := <-fillChan:
+				if f.Order.ID == id {
+					return f
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("fill of order %d not received", id)
+			}
+		}
+	}
+
+	stopAsk, err := m.AddOrder(coinPair, Order{AccountID: "a", Type: Ask, Price: 85, StopPrice: 90, CreatedAt: 1, Amount: 1})
+	assert.Nil(t, err)
+	poorAsk, err := m.AddOrder(coinPair, Order{AccountID: "poor", Type: Ask, Price: 80, StopPrice: 95, CreatedAt: 1, Amount: 1})
+	assert.Nil(t, err)
+	stopBid, err := m.AddOrder(coinPair, Order{AccountID: "b", Type: Bid, Kind: Market, StopPrice: 110, CreatedAt: 1, Amount: 1})
+	assert.Nil(t, err)
+
+	// the stop orders are not in the book until triggered.
+	asks, _, err := m.GetOrders(coinPair, Ask, 0, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(asks))
+	assert.Equal(t, 3, len(stopsOf(m, coinPair)))
+
+	// trade at 100 triggers nothing.
+	m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 2, Amount: 1})
+	id, _ := m.AddOrder(coinPair, Order{Type: Ask, Price: 100, CreatedAt: 3, Amount: 1})
+	waitFill(id)
+	mtx.Lock()
+	assert.Equal(t, 0, len(triggered))
+	mtx.Unlock()
+
+	// the price falls to 90, the stop asks are triggered, the poor one is closed,
+	// the other one is converted to limit order and matches the resting bid.
+	m.AddOrder(coinPair, Order{Type: Bid, Price: 90, CreatedAt: 4, Amount: 2})
+	m.AddOrder(coinPair, Order{Type: Ask, Price: 90, CreatedAt: 5, Amount: 1})
+	f := waitFill(poorAsk)
+	assert.Equal(t, uint64(0), f.Amount)
+	assert.True(t, f.Order.IsStop())
+	f = waitFill(stopAsk)
+	assert.Equal(t, uint64(1), f.Amount)
+	assert.Equal(t, uint64(90), f.Counter.Price)
+	assert.False(t, f.Order.IsStop())
+
+	mtx.Lock()
+	assert.Equal(t, 2, len(triggered))
+	for _, od := range triggered {
+		assert.False(t, od.IsStop())
+	}
+	mtx.Unlock()
+	assert.Equal(t, 1, len(stopsOf(m, coinPair)))
+
+	// the price rises to 110, the market stop bid is executed against the rest ask.
+	m.AddOrder(coinPair, Order{Type: Ask, Price: 110, CreatedAt: 6, Amount: 2})
+	m.AddOrder(coinPair, Order{Type: Bid, Price: 110, CreatedAt: 7, Amount: 1})
+	f = waitFill(stopBid)
+	assert.Equal(t, uint64(1), f.Amount)
+	assert.Equal(t, uint64(110), f.Counter.Price)
+	assert.Equal(t, 0, len(stopsOf(m, coinPair)))
+}
+
+func stopsOf(m *Manager, cp string) []Order {
+	bk := m.GetBook(cp)
+	return bk.Stops()
+}
+
+// useTempOrderDir sets the order dir to a temp dir, and returns the func restores it.
+func useTempOrderDir(tb testing.TB) func() {
+	dir, err := ioutil.TempDir("", "orderbook")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	od := orderDir
+	InitDir(dir)
+	return func() {
+		orderDir = od
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSaveCoalescing(t *testing.T) {
+	defer useTempOrderDir(t)()
+
+	m := NewManager()
+	coinPair := "save/sky"
+	m.AddBook(coinPair, &Book{})
+	m.RegisterOrderChan(coinPair, make(chan Fill, 100))
+	m.SetSaveInterval(100 * time.Millisecond)
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		m.Start(10*time.Millisecond, closing)
+		close(done)
+	}()
+
+	// the changes in one interval are written once.
+	writes := atomic.LoadUint64(&bookWrites)
+	for i := 0; i < 50; i++ {
+		_, err := m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: int64(i), Amount: 1})
+		assert.Nil(t, err)
+	}
+	time.Sleep(250 * time.Millisecond)
+	n := atomic.LoadUint64(&bookWrites) - writes
+	assert.True(t, n >= 1 && n <= 2, "%d writes", n)
+
+	m1, err := LoadManager()
+	assert.Nil(t, err)
+	_, total, err := m1.GetOrders(coinPair, Bid, 0, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 50, total)
+
+	// the unchanged book is not written.
+	writes = atomic.LoadUint64(&bookWrites)
+	time.Sleep(250 * time.Millisecond)
+	assert.Equal(t, writes, atomic.LoadUint64(&bookWrites))
+
+	// the changes are flushed on shutdown.
+	id, err := m.AddOrder(coinPair, Order{Type: Bid, Price: 100, CreatedAt: 50, Amount: 1})
+	assert.Nil(t, err)
+	_, err = m.CancelOrder(coinPair, 1, "")
+	assert.Nil(t, err)
+	close(closing)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("manager is not stopped")
+	}
+
+	m1, err = LoadManager()
+	assert.Nil(t, err)
+	bids, total, err := m1.GetOrders(coinPair, Bid, 0, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, 50, total)
+	assert.Equal(t, id, bids[len(bids)-1].ID)
+}
+
+// BenchmarkBookWrites compares the book writes of saving on every change to the coalesced saves.
+func BenchmarkBookWrites(b *testing.B) {
+	run := func(b *testing.B, interval time.Duration, saveEach bool) {
+		defer useTempOrderDir(b)()
+		m := NewManager()
+		m.AddBook("bench/sky", &Book{})
+		m.RegisterOrderChan("bench/sky", make(chan Fill, 100))
+		m.SetSaveInterval(interval)
+		closing := make(chan bool)
+		done := make(chan struct{})
+		go func() {
+			m.Start(time.Hour, closing)
+			close(done)
+		}()
+
+		writes := atomic.LoadUint64(&bookWrites)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// the orders are spread over price levels, so that the book size is bounded.
+			m.AddOrder("bench/sky", Order{Type: Bid, Price: uint64(i % 100), CreatedAt: int64(i), Amount: 1})
+			if saveEach {
+				if err := m.SaveBook("bench/sky"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+		close(closing)
+		<-done
+		b.StopTimer()
+		b.ReportMetric(float64(atomic.LoadUint64(&bookWrites)-writes)/float64(b.N), "writes/op")
+	}
+
+	b.Run("every-change", func(b *testing.B) { run(b, time.Hour, true) })
+	b.Run("coalesced", func(b *testing.B) { run(b, 10*time.Millisecond, false) })
+}
+
+// TestConcurrentAddOrder submits the orders from many goroutines, the orders are sequenced by
+// the match goroutine, so replaying them in the order of ids gives the same book.
+func TestConcurrentAddOrder(t *testing.T) {
+	defer useTempOrderDir(t)()
+
+	cp := "bitcoin/skycoin"
+	m := NewManager()
+	assert.Nil(t, m.AddBook(cp, &Book{}))
+	fillChan := make(chan Fill, 100)
+	m.RegisterOrderChan(cp, fillChan)
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		m.Start(time.Hour, closing)
+		close(done)
+	}()
+
+	var taken uint64
+	fillsDone := make(chan struct{})
+	go func() {
+		defer close(fillsDone)
+		for f := range fillChan {
+			if f.Taker {
+				taken += f.Amount
+			}
+		}
+	}()
+
+	const workers, perWorker = 8, 50
+	var mtx sync.Mutex
+	placed := make(map[uint64]Order)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				// the resting asks and bids, and the IOC bids taking the asks.
+				od := Order{
+					AccountID: string(rune('a' + w)),
+					Type:      Ask,
+					Price:     uint64(100 + (w*perWorker+i)%10),
+					CreatedAt: int64(w*perWorker + i),
+					Amount:    uint64(1 + i%3),
+				}
+				switch i % 3 {
+				case 1:
+					od.Type, od.Price = Bid, uint64(90+i%5)
+				case 2:
+					od.Type, od.TimeInForce = Bid, IOC
+				}
+				id, err := m.AddOrder(cp, od)
+				if !assert.Nil(t, err) {
+					return
+				}
+				od.ID = id
+				mtx.Lock()
+				placed[id] = od
+				mtx.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(closing)
+	<-done
+	close(fillChan)
+	<-fillsDone
+	assert.Len(t, placed, workers*perWorker)
+
+	// replay the orders in the order of ids.
+	bk := &Book{}
+	var replayTaken uint64
+	for id := uint64(1); id <= workers*perWorker; id++ {
+		od, ok := placed[id]
+		if !assert.True(t, ok, "order %d is missing", id) {
+			return
+		}
+		od.RestAmt = od.Amount
+		if od.TimeInForce == IOC {
+			fills, err := bk.MatchIOC(od)
+			assert.Nil(t, err)
+			for _, f := range fills {
+				if f.Taker {
+					replayTaken += f.Amount
+				}
+			}
+			continue
+		}
+		_, err := bk.addLimited(od)
+		assert.Nil(t, err)
+	}
+	assert.NotZero(t, taken)
+	assert.Equal(t, replayTaken, taken)
+
+	got := m.GetBook(cp)
+	for _, tp := range []Type{Bid, Ask} {
+		want, _, err := bk.GetOrders(tp, 0, math.MaxInt64)
+		assert.Nil(t, err)
+		ods, _, err := got.GetOrders(tp, 0, math.MaxInt64)
+		assert.Nil(t, err)
+		assert.Equal(t, want, ods)
+	}
+}
+
+func TestHaltPair(t *testing.T) {
+	defer useTempOrderDir(t)()
+
+	cp := "bitcoin/skycoin"
+	bk := &Book{}
+	bk.AddBid(Order{ID: 1, Type: Bid, Price: 100, Amount: 1, RestAmt: 1, AccountID: "a", CreatedAt: 1})
+	bk.AddAsk(Order{ID: 2, Type: Ask, Price: 100, Amount: 1, RestAmt: 1, AccountID: "b", CreatedAt: 2})
+	m := NewManager()
+	assert.Nil(t, m.AddBook(cp, bk))
+	fillChan := make(chan Fill, 10)
+	m.RegisterOrderChan(cp, fillChan)
+	assert.NotNil(t, m.HaltPair("unknown/skycoin"))
+	assert.Nil(t, m.HaltPair(cp))
+	assert.True(t, m.IsHalted(cp))
+
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		m.Start(10*time.Millisecond, closing)
+		close(done)
+	}()
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	// the new orders are rejected, and the crossed orders are not matched.
+	_, err := m.AddOrder(cp, Order{Type: Bid, Price: 100, Amount: 1, AccountID: "c"})
+	assert.Equal(t, ErrPairHalted, err)
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, fillChan, 0)
+	got := m.GetBook(cp)
+	assert.Equal(t, 2, got.Len())
+
+	// the resting orders can still be cancelled.
+	od, err := m.CancelOrder(cp, 1, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), od.ID)
+
+	// the halt is saved with the book.
+	m1, err := LoadManager()
+	assert.Nil(t, err)
+	assert.True(t, m1.IsHalted(cp))
+
+	// the matching resumes.
+	assert.Nil(t, m.ResumePair(cp))
+	assert.False(t, m.IsHalted(cp))
+	_, err = m.AddOrder(cp, Order{Type: Bid, Price: 100, Amount: 1, AccountID: "c"})
+	assert.Nil(t, err)
+	select {
+	case f := <-fillChan:
+		assert.Equal(t, uint64(1), f.Amount)
+	case <-time.After(time.Second):
+		t.Fatal("the orders are not matched after resume")
+	}
+}
+
+func TestAddOrders(t *testing.T) {
+	defer useTempOrderDir(t)()
+
+	cp := "bitcoin/skycoin"
+	m := NewManager()
+	assert.Nil(t, m.AddBook(cp, &Book{}))
+	fillChan := make(chan Fill, 10)
+	m.RegisterOrderChan(cp, fillChan)
+
+	// the ticker never fires, the orders are matched by the batch.
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		m.Start(time.Hour, closing)
+		close(done)
+	}()
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	ids, errs := m.AddOrders(cp, []Order{
+		{Type: Ask, Price: 100, Amount: 2, AccountID: "a"},
+		{Type: Bid, Price: 100, Amount: 0, AccountID: "b"},
+		{Type: Bid, Price: 100, Amount: 1, AccountID: "b"},
+		{Type: Bid, Price: 100, Amount: 1, AccountID: "b", TimeInForce: GTD, ExpireAt: 1},
+		{Type: Bid, Price: 0, Amount: 1, AccountID: "b"},
+		{Type: Bid, Price: 90, Amount: 1, AccountID: "c"},
+	})
+	assert.Len(t, ids, 6)
+	assert.Nil(t, errs[0])
+	assert.Equal(t, ErrZeroAmount, errs[1])
+	assert.Nil(t, errs[2])
+	assert.Equal(t, ErrInvalidExpiry, errs[3])
+	assert.NotNil(t, errs[4])
+	assert.Nil(t, errs[5])
+	for _, i := range []int{1, 3, 4} {
+		assert.Equal(t, uint64(0), ids[i])
+	}
+	assert.True(t, ids[0] < ids[2] && ids[2] < ids[5])
+
+	select {
+	case f := <-fillChan:
+		assert.Equal(t, ids[0]+ids[2], f.Order.ID+f.Counter.ID)
+		assert.Equal(t, uint64(1), f.Amount)
+	case <-time.After(time.Second):
+		t.Fatal("the batch is not matched")
+	}
+
+	// the rest of the ask and the bid of c are resting.
+	bk := m.GetBook(cp)
+	assert.Equal(t, 2, bk.Len())
+
+	ids, errs = m.AddOrders("unknown/skycoin", []Order{{Type: Bid, Price: 100, Amount: 1}})
+	assert.Equal(t, []uint64{0}, ids)
+	assert.NotNil(t, errs[0])
 }
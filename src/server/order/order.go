@@ -1,6 +1,7 @@
 package order
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,63 +17,199 @@ const (
 	Ask
 )
 
+// Kind decides how the order is executed.
+type Kind uint8
+
+const (
+	// Limit order rests in the book at fixed price until it's filled.
+	Limit Kind = iota
+	// Market order ignores the price, and executes against the best opposite
+	// orders immediately, the unfilled amount is dropped.
+	Market
+)
+
+// TimeInForce decides how long the limit order stays in the book.
+type TimeInForce uint8
+
+const (
+	// GTC good till cancelled, the order rests in the book until it's filled or cancelled.
+	GTC TimeInForce = iota
+	// IOC immediate or cancel, the order is matched immediately, the unfilled amount is cancelled.
+	IOC
+	// GTD good till date, the order is removed from the book after its ExpireAt.
+	GTD
+)
+
+// STPMode self-trade prevention mode, decides what happens when the bid and ask of the
+// same account match each other.
+type STPMode uint8
+
+const (
+	// STPNone the orders of the same account match like any other orders.
+	STPNone STPMode = iota
+	// STPCancelNewest the newer one of the two orders is cancelled, the older one stays in the book.
+	STPCancelNewest
+	// STPCancelOldest the older one of the two orders is cancelled, the newer one continues matching.
+	STPCancelOldest
+	// STPReject the new order that would match the account's own order is rejected when it's placed,
+	// the own orders that still meet in matching, like the triggered stop orders, are handled as
+	// STPCancelNewest.
+	STPReject
+)
+
+// FullPolicy decides what happens to the new order when the book holds its max orders.
+type FullPolicy uint8
+
+const (
+	// FullReject the new order is rejected with ErrBookFull.
+	FullReject FullPolicy = iota
+	// FullEvictWorst the worst-priced resting order of the new order's side is closed to make
+	// room, if the new order is better priced than it, or the opposite side's worst order if
+	// the new order's side is empty. The new stop order is rejected, it has no price to compare.
+	FullEvictWorst
+)
+
+// Currency the coin of the pair the order amount is denominated in.
+type Currency uint8
+
+const (
+	// Base the amount is in the base coin, the first coin of the pair, like "buy 100 SKY".
+	Base Currency = iota
+	// Quote the amount is in the quote coin, the second coin of the pair, like "spend 100 BTC".
+	// It's converted to the base amount at the limit price before the order is placed, so the
+	// book only holds base amounts.
+	Quote
+)
+
+// Status the fill status of the order.
+type Status uint8
+
+const (
+	// StatusOpen the order rests in the book without any fill, or it's the inactive stop order.
+	StatusOpen Status = iota
+	// StatusPartial the order rests in the book with part of its amount filled.
+	StatusPartial
+	// StatusFilled the order is fully filled.
+	StatusFilled
+	// StatusClosed the order left the book with its rest amount unfilled, it was cancelled,
+	// expired, evicted, or it's the market or IOC order.
+	StatusClosed
+)
+
 var (
 	orderDir string = filepath.Join(util.UserHome(), ".skycoin-exchange/orderbook")
 	orderExt string = "ods"
 	idExt    string = "id"
 )
 
+var (
+	// ErrOrderNotExist is returned when the order is not in the book, it may be filled or cancelled.
+	ErrOrderNotExist = errors.New("order not exist")
+	// ErrNotOrderOwner is returned when the account is not the owner of the order.
+	ErrNotOrderOwner = errors.New("account is not the owner of the order")
+	// ErrZeroAmount is returned when the order amount is zero.
+	ErrZeroAmount = errors.New("order amount is zero")
+	// ErrZeroPrice is returned when the limit order has no price.
+	ErrZeroPrice = errors.New("order price is zero")
+	// ErrBelowMinAmount is returned when the order amount is less than the minimum amount of the book.
+	ErrBelowMinAmount = errors.New("order amount is below the minimum")
+	// ErrOffTick is returned when the order price is not a multiple of the tick size of the book.
+	ErrOffTick = errors.New("order price is not a multiple of the tick size")
+	// ErrInvalidExpiry is returned when the GTD order has no expiry time in the future.
+	ErrInvalidExpiry = errors.New("invalid order expiry time")
+	// ErrSelfTrade is returned when the order would match the account's own order, and the book rejects it.
+	ErrSelfTrade = errors.New("order would match the account's own order")
+	// ErrOrderNotFound is returned when the order is neither open nor in the recent closed orders of the book.
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrBookFull is returned when the book holds its max open orders, and the new order can't evict any.
+	ErrBookFull = errors.New("order book is full")
+	// ErrNoRoute is returned when no path of books converts the source coin into the target coin,
+	// or the books of every path are not deep enough for the amount.
+	ErrNoRoute = errors.New("no route between the coins")
+	// ErrValueOverflow is returned when the value price*amount of the order doesn't fit in uint64.
+	ErrValueOverflow = errors.New("order value overflows")
+	// ErrPairHalted is returned when the trading of the coin pair is halted by the admin.
+	ErrPairHalted = errors.New("trading of the coin pair is halted")
+	// ErrQuoteAmount is returned when the amount in the quote coin can't be converted, the order
+	// is not a limit order with price.
+	ErrQuoteAmount = errors.New("quote amount requires limit order with price")
+	// ErrOutsidePriceBand is returned when the price of the limit order is too far from the last trade price.
+	ErrOutsidePriceBand = errors.New("price is outside the price band")
+	// ErrInsufficientBalance the account can't pay for the order.
+	ErrInsufficientBalance = errors.New("balance is not sufficient")
+)
+
+// userErrors the errors caused by the order request, their messages are shown to the client.
+var userErrors = []error{
+	ErrZeroAmount,
+	ErrZeroPrice,
+	ErrBelowMinAmount,
+	ErrOffTick,
+	ErrInvalidExpiry,
+	ErrSelfTrade,
+	ErrBookFull,
+	ErrValueOverflow,
+	ErrPairHalted,
+	ErrQuoteAmount,
+	ErrOutsidePriceBand,
+	ErrInsufficientBalance,
+}
+
+// IsUserError returns true if the order is rejected for the request itself, the error can be
+// shown to the client, the other errors are internal.
+func IsUserError(err error) bool {
+	for _, e := range userErrors {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+	return false
+}
+
 type Order struct {
 	ID        uint64 `json:"id"` // order id.
 	AccountID string `json:"account_id"`
 	Type      Type   `json:"type"`       // order type.
+	Kind      Kind   `json:"kind"`       // limit or market.
 	Price     uint64 `json:"price"`      // price of this order.
 	Amount    uint64 `json:"amount"`     // total amount of this order.
-	RestAmt   uint64 `json:"reset_amt"`  // rest amount.
+	RestAmt   uint64 `json:"reset_amt"`  // remaining amount, the order stays in book until it reaches zero.
 	CreatedAt int64  `json:"created_at"` // created time of the order.
-}
 
-type byPriceThenTimeDesc []Order
-type byPriceThenTimeAsc []Order
-type byOrderID []Order
+	TimeInForce TimeInForce `json:"time_in_force"`       // GTC, IOC or GTD, ignored by market order.
+	ExpireAt    int64       `json:"expire_at,omitempty"` // expiry unix time of GTD order.
 
-func (bp byPriceThenTimeDesc) Len() int {
-	return len(bp)
-}
+	// AmountCurrency the coin Amount is denominated in, the quote amount is converted to the
+	// base amount by ToBase when the order is added, so the orders in the book are always Base.
+	AmountCurrency Currency `json:"amount_currency,omitempty"`
 
-func (bp byPriceThenTimeDesc) Less(i, j int) bool {
-	a := bp[i]
-	b := bp[j]
-	if a.Price > b.Price {
-		return true
-	} else if a.Price == b.Price {
-		return a.CreatedAt > b.CreatedAt
-	}
-	return false
-}
+	// StopPrice trigger price of the stop order, the order is inactive until the last
+	// trade price reaches it, then it's converted to the limit or market order of its Kind.
+	// Zero for the normal orders and the triggered stop orders.
+	StopPrice uint64 `json:"stop_price,omitempty"`
 
-func (bp byPriceThenTimeDesc) Swap(i, j int) {
-	bp[i], bp[j] = bp[j], bp[i]
-}
+	// Closed whether the order has left the book, only set in the closed orders kept by the book.
+	Closed bool `json:"closed,omitempty"`
 
-func (bp byPriceThenTimeAsc) Len() int {
-	return len(bp)
+	// Cost the sub coins reserved for the market bid, the bid is only filled within it, and each
+	// fill spends its value rounded up, what's left is given back when the bid is closed.
+	// Zero means no limit, it's never zero for the market bids placed by the server.
+	Cost uint64 `json:"cost,omitempty"`
 }
 
-func (bp byPriceThenTimeAsc) Less(i, j int) bool {
-	a := bp[i]
-	b := bp[j]
-	if a.Price < b.Price {
-		return true
-	} else if a.Price == b.Price {
-		return a.CreatedAt > b.CreatedAt
-	}
-	return false
+// Fill records one execution of an order, an order can be filled
+// several times before its RestAmt reaches zero. A fill of zero Amount
+// means the order is closed, its RestAmt will never be filled, which happens
+// to the market and IOC orders after matching, and the expired GTD orders.
+type Fill struct {
+	Order   Order  // snapshot of the order after this fill.
+	Amount  uint64 // filled amount of this execution.
+	Price   uint64 // execution price, the price of the resting maker order for both sides.
+	Counter Order  // snapshot of the counterparty order, empty in the closing fill.
+	Taker   bool   // whether the Order is the taker of this execution.
 }
 
-func (bp byPriceThenTimeAsc) Swap(i, j int) {
-	bp[i], bp[j] = bp[j], bp[i]
-}
+type byOrderID []Order
 
 func (bo byOrderID) Len() int {
 	return len(bo)
@@ -122,6 +259,14 @@ func (tp Type) String() string {
 	}
 }
 
+// oppositeType returns the type of orders that can match the specific type.
+func oppositeType(tp Type) Type {
+	if tp == Bid {
+		return Ask
+	}
+	return Bid
+}
+
 func TypeFromStr(tp string) (Type, error) {
 	switch tp {
 	case "bid":
@@ -132,3 +277,207 @@ func TypeFromStr(tp string) (Type, error) {
 		return 0, fmt.Errorf("unknow order type:%s", tp)
 	}
 }
+
+func (k Kind) String() string {
+	switch k {
+	case Limit:
+		return "limit"
+	case Market:
+		return "market"
+	default:
+		return ""
+	}
+}
+
+func (c Currency) String() string {
+	switch c {
+	case Base:
+		return "base"
+	case Quote:
+		return "quote"
+	default:
+		return ""
+	}
+}
+
+func (tif TimeInForce) String() string {
+	switch tif {
+	case GTC:
+		return "gtc"
+	case IOC:
+		return "ioc"
+	case GTD:
+		return "gtd"
+	default:
+		return ""
+	}
+}
+
+func (mode STPMode) String() string {
+	switch mode {
+	case STPNone:
+		return "none"
+	case STPCancelNewest:
+		return "cancel_newest"
+	case STPCancelOldest:
+		return "cancel_oldest"
+	case STPReject:
+		return "reject"
+	default:
+		return ""
+	}
+}
+
+// STPModeFromStr returns the self-trade prevention mode, empty string means STPNone.
+func STPModeFromStr(mode string) (STPMode, error) {
+	switch mode {
+	case "", "none":
+		return STPNone, nil
+	case "cancel_newest":
+		return STPCancelNewest, nil
+	case "cancel_oldest":
+		return STPCancelOldest, nil
+	case "reject":
+		return STPReject, nil
+	default:
+		return 0, fmt.Errorf("unknow self-trade prevention mode:%s", mode)
+	}
+}
+
+func (p FullPolicy) String() string {
+	switch p {
+	case FullReject:
+		return "reject"
+	case FullEvictWorst:
+		return "evict_worst"
+	default:
+		return ""
+	}
+}
+
+func (st Status) String() string {
+	switch st {
+	case StatusOpen:
+		return "open"
+	case StatusPartial:
+		return "partially_filled"
+	case StatusFilled:
+		return "filled"
+	case StatusClosed:
+		return "closed"
+	default:
+		return ""
+	}
+}
+
+// FullPolicyFromStr returns the full book policy, empty string means FullReject.
+func FullPolicyFromStr(p string) (FullPolicy, error) {
+	switch p {
+	case "", "reject":
+		return FullReject, nil
+	case "evict_worst":
+		return FullEvictWorst, nil
+	default:
+		return 0, fmt.Errorf("unknow full book policy:%s", p)
+	}
+}
+
+// TimeInForceFromStr returns the time in force, empty string means GTC.
+func TimeInForceFromStr(tif string) (TimeInForce, error) {
+	switch tif {
+	case "", "gtc":
+		return GTC, nil
+	case "ioc":
+		return IOC, nil
+	case "gtd":
+		return GTD, nil
+	default:
+		return 0, fmt.Errorf("unknow time in force:%s", tif)
+	}
+}
+
+// isExpired checks whether the GTD order is expired at unix time now.
+func (od Order) isExpired(now int64) bool {
+	return od.TimeInForce == GTD && od.ExpireAt <= now
+}
+
+// IsStop checks whether the order is the inactive stop order.
+func (od Order) IsStop() bool {
+	return od.StopPrice > 0
+}
+
+// Status returns the fill status of the order.
+func (od Order) Status() Status {
+	switch {
+	case od.RestAmt == 0:
+		return StatusFilled
+	case od.Closed:
+		return StatusClosed
+	case od.RestAmt < od.Amount:
+		return StatusPartial
+	default:
+		return StatusOpen
+	}
+}
+
+// isTriggered checks whether the stop order is triggered by the last trade price,
+// the bid is triggered when the price rises to the stop price, and the ask is triggered
+// when the price falls to it. Nothing is triggered before the first trade.
+func (od Order) isTriggered(lastPrice uint64) bool {
+	if lastPrice == 0 {
+		return false
+	}
+	if od.Type == Bid {
+		return lastPrice >= od.StopPrice
+	}
+	return lastPrice <= od.StopPrice
+}
+
+// MarketCost calculates the cost of buying amount from the ask orders in priority order,
+// the prices have decimals places, and the cost of each ask is rounded up as it's paid.
+func MarketCost(asks []Order, amount uint64, decimals uint8) (uint64, error) {
+	var cost uint64
+	for _, od := range asks {
+		if amount == 0 {
+			break
+		}
+		amt := od.RestAmt
+		if amount < amt {
+			amt = amount
+		}
+		v, err := Value(od.Price, amt, decimals, RoundUp)
+		if err != nil {
+			return 0, err
+		}
+		if cost+v < cost {
+			return 0, fmt.Errorf("%w: market cost of amount %d", ErrValueOverflow, amount)
+		}
+		cost += v
+		amount -= amt
+	}
+	return cost, nil
+}
+
+// KindFromStr returns the order kind, empty string means limit order.
+func KindFromStr(k string) (Kind, error) {
+	switch k {
+	case "", "limit":
+		return Limit, nil
+	case "market":
+		return Market, nil
+	default:
+		return 0, fmt.Errorf("unknow order kind:%s", k)
+	}
+}
+
+// CurrencyFromStr returns the amount currency, empty string means the base coin.
+func CurrencyFromStr(c string) (Currency, error) {
+	switch c {
+	case "", "base":
+		return Base, nil
+	case "quote":
+		return Quote, nil
+	default:
+		return 0, fmt.Errorf("unknow amount currency:%s", c)
+	}
+}
@@ -0,0 +1,217 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// MaxRouteHops the max number of books a route walks through.
+var MaxRouteHops = 3
+
+// Hop one conversion of a route, the main coin of the pair is sold to its bids if Type is Ask,
+// or bought from its asks if Type is Bid.
+type Hop struct {
+	Pair      string `json:"pair"`
+	Type      Type   `json:"type"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	AmountIn  uint64 `json:"amount_in"`  // coins spent, the rest too little to buy one coin is not spent.
+	AmountOut uint64 `json:"amount_out"` // coins received after the taker fee.
+	Fee       uint64 `json:"fee"`        // taker fee deducted from the received coins.
+}
+
+// Route the path of books converting AmountIn source coins into AmountOut target coins,
+// the output of each hop is the input of the next one.
+type Route struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	AmountIn  uint64 `json:"amount_in"`
+	AmountOut uint64 `json:"amount_out"`
+	Hops      []Hop  `json:"hops"`
+}
+
+// Rate returns the effective rate of the route, target coins received per source coin.
+func (r Route) Rate() float64 {
+	if r.AmountIn == 0 {
+		return 0
+	}
+	return float64(r.AmountOut) / float64(r.AmountIn)
+}
+
+// FindRoute finds the path of at most MaxRouteHops books converting amount from coins into to coins,
+// and reports the coins received by walking the resting orders of each book as a taker, the taker
+// fee is deducted in every hop. The path of the most output is chosen, the shorter one wins the tie.
+// Nothing is executed, and the stop orders are ignored.
+func (m *Manager) FindRoute(from, to string, amount uint64) (Route, error) {
+	if from == to {
+		return Route{}, fmt.Errorf("source and target coin are both %s", from)
+	}
+
+	if amount == 0 {
+		return Route{}, errors.New("route amount is zero")
+	}
+
+	best := Route{}
+	for _, path := range routePaths(m.Pairs(), from, to, MaxRouteHops) {
+		r, err := m.walkRoute(from, to, amount, path)
+		if err != nil {
+			continue
+		}
+		if r.AmountOut > best.AmountOut || (r.AmountOut == best.AmountOut && len(r.Hops) < len(best.Hops)) {
+			best = r
+		}
+	}
+
+	if best.AmountOut == 0 {
+		return Route{}, fmt.Errorf("%w: %d %s to %s", ErrNoRoute, amount, from, to)
+	}
+	return best, nil
+}
+
+// routePaths returns the paths of the pairs from coin from to coin to, no coin is visited twice.
+func routePaths(pairs []string, from, to string, maxHops int) [][]string {
+	paths := [][]string{}
+	visited := map[string]bool{from: true}
+	var walk func(coin string, path []string)
+	walk = func(coin string, path []string) {
+		if coin == to {
+			paths = append(paths, append([]string(nil), path...))
+			return
+		}
+
+		if len(path) == maxHops {
+			return
+		}
+
+		for _, cp := range pairs {
+			next, ok := counterCoin(cp, coin)
+			if !ok || visited[next] {
+				continue
+			}
+			visited[next] = true
+			walk(next, append(path, cp))
+			visited[next] = false
+		}
+	}
+	walk(from, nil)
+	return paths
+}
+
+// counterCoin returns the other coin of the pair if coin is in it.
+func counterCoin(cp, coin string) (string, bool) {
+	pair := strings.Split(cp, "/")
+	if len(pair) != 2 {
+		return "", false
+	}
+
+	switch coin {
+	case pair[0]:
+		return pair[1], true
+	case pair[1]:
+		return pair[0], true
+	}
+	return "", false
+}
+
+// walkRoute walks the books of the path, returns error if any book is not deep enough.
+func (m *Manager) walkRoute(from, to string, amount uint64, path []string) (Route, error) {
+	r := Route{From: from, To: to, AmountIn: amount}
+	coin, amt := from, amount
+	for _, cp := range path {
+		bk, ok := m.getBook(cp)
+		if !ok {
+			return Route{}, fmt.Errorf("coin pair:%s not supported", cp)
+		}
+
+		pair := strings.Split(cp, "/")
+		h := Hop{Pair: cp, From: coin}
+		var err error
+		dec := bk.PriceDecimals()
+		if coin == pair[0] {
+			h.Type, h.To = Ask, pair[1]
+			bids, _, _ := bk.GetOrders(Bid, 0, math.MaxInt64)
+			h.AmountIn, h.AmountOut, err = sellTo(bids, amt, dec)
+		} else {
+			h.Type, h.To = Bid, pair[0]
+			asks, _, _ := bk.GetOrders(Ask, 0, math.MaxInt64)
+			h.AmountIn, h.AmountOut, err = buyFrom(asks, amt, dec)
+		}
+		if err != nil {
+			return Route{}, fmt.Errorf("%s: %v", cp, err)
+		}
+
+		h.Fee = m.TakerFee(h.AmountOut)
+		h.AmountOut -= h.Fee
+		if h.AmountOut == 0 {
+			return Route{}, fmt.Errorf("%s: nothing received", cp)
+		}
+		r.Hops = append(r.Hops, h)
+		coin, amt = h.To, h.AmountOut
+	}
+	r.AmountOut = amt
+	return r, nil
+}
+
+// sellTo sells amount main coins to the bids in priority order, returns the main coins sold
+// and the price coins received, which are rounded down.
+func sellTo(bids []Order, amount uint64, decimals uint8) (uint64, uint64, error) {
+	var out uint64
+	rest := amount
+	for _, od := range bids {
+		if rest == 0 {
+			break
+		}
+		amt := od.RestAmt
+		if rest < amt {
+			amt = rest
+		}
+		v, err := Value(od.Price, amt, decimals, RoundDown)
+		if err != nil {
+			return 0, 0, err
+		}
+		if out+v < out {
+			return 0, 0, ErrValueOverflow
+		}
+		out += v
+		rest -= amt
+	}
+
+	if rest > 0 {
+		return 0, 0, errors.New("bids are not sufficient")
+	}
+	return amount, out, nil
+}
+
+// buyFrom buys the main coins from the asks in priority order with budget price coins, returns
+// the price coins spent, which are rounded up, and the main coins bought. The budget left can't
+// buy one more coin of the next ask.
+func buyFrom(asks []Order, budget uint64, decimals uint8) (uint64, uint64, error) {
+	var out uint64
+	rest := budget
+	for _, od := range asks {
+		n := MaxAmount(rest, od.Price, decimals)
+		if n == 0 {
+			return budget - rest, out, nil
+		}
+		if n > od.RestAmt {
+			n = od.RestAmt
+		}
+		cost, err := Value(od.Price, n, decimals, RoundUp)
+		if err != nil {
+			return 0, 0, err
+		}
+		out += n
+		rest -= cost
+		if n < od.RestAmt {
+			// the ask is partially bought, the rest can't buy one more coin.
+			return budget - rest, out, nil
+		}
+	}
+
+	if rest > 0 {
+		return 0, 0, errors.New("asks are not sufficient")
+	}
+	return budget, out, nil
+}
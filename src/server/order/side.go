@@ -0,0 +1,222 @@
+package order
+
+import "sort"
+
+// priceLevel is the FIFO queue of the orders at the same price, the older orders
+// come first, and the orders created at the same time are ordered by id.
+type priceLevel struct {
+	price  uint64
+	orders []Order
+}
+
+// bookSide is the bids or asks of the book, the price levels are sorted by price
+// priority, that's descending price for bids and ascending price for asks, so the
+// first order of the first level is always the best one to match.
+type bookSide struct {
+	levels   []*priceLevel
+	size     int                          // number of orders in all the levels.
+	accounts map[string]map[uint64]uint64 // price of each order id of the accounts, for listing the orders of an account.
+}
+
+// better checks whether price a has higher priority than price b on the tp side.
+func better(tp Type, a, b uint64) bool {
+	if tp == Bid {
+		return a > b
+	}
+	return a < b
+}
+
+// add inserts the order into the tp side, the price level is located by binary
+// search, and the order is queued behind the orders created before it.
+func (s *bookSide) add(tp Type, od Order) {
+	i := sort.Search(len(s.levels), func(i int) bool {
+		return !better(tp, s.levels[i].price, od.Price)
+	})
+	if i == len(s.levels) || s.levels[i].price != od.Price {
+		s.levels = append(s.levels, nil)
+		copy(s.levels[i+1:], s.levels[i:])
+		s.levels[i] = &priceLevel{price: od.Price}
+	}
+
+	// the order is usually the latest one, and appended to the queue.
+	lv := s.levels[i]
+	j := sort.Search(len(lv.orders), func(j int) bool {
+		return isLater(lv.orders[j], od)
+	})
+	lv.orders = append(lv.orders, Order{})
+	copy(lv.orders[j+1:], lv.orders[j:])
+	lv.orders[j] = od
+	s.size++
+	s.index(od)
+}
+
+// index adds the order to the orders of its account.
+func (s *bookSide) index(od Order) {
+	if s.accounts == nil {
+		s.accounts = make(map[string]map[uint64]uint64)
+	}
+	ids, ok := s.accounts[od.AccountID]
+	if !ok {
+		ids = make(map[uint64]uint64)
+		s.accounts[od.AccountID] = ids
+	}
+	ids[od.ID] = od.Price
+}
+
+// unindex removes the order from the orders of its account.
+func (s *bookSide) unindex(od Order) {
+	ids := s.accounts[od.AccountID]
+	delete(ids, od.ID)
+	if len(ids) == 0 {
+		delete(s.accounts, od.AccountID)
+	}
+}
+
+// len returns the number of orders.
+func (s bookSide) len() int {
+	return s.size
+}
+
+// front returns the best order, which can be updated in place, nil if the side is empty.
+func (s *bookSide) front() *Order {
+	if len(s.levels) == 0 {
+		return nil
+	}
+	return &s.levels[0].orders[0]
+}
+
+// popFront removes the best order.
+func (s *bookSide) popFront() {
+	s.removeAt(0, 0)
+}
+
+// removeAt removes the jth order of the ith level, the level is removed once it's empty.
+func (s *bookSide) removeAt(i, j int) {
+	lv := s.levels[i]
+	s.unindex(lv.orders[j])
+	lv.orders = append(lv.orders[:j], lv.orders[j+1:]...)
+	if len(lv.orders) == 0 {
+		s.levels = append(s.levels[:i], s.levels[i+1:]...)
+	}
+	s.size--
+}
+
+// find returns the position of the order of specific id.
+func (s *bookSide) find(id uint64) (int, int, bool) {
+	for i, lv := range s.levels {
+		for j, od := range lv.orders {
+			if od.ID == id {
+				return i, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// removeIf removes the orders that fn returns true, and returns them in priority order.
+func (s *bookSide) removeIf(fn func(Order) bool) []Order {
+	removed := []Order{}
+	levels := s.levels[:0]
+	for _, lv := range s.levels {
+		rest := lv.orders[:0]
+		for _, od := range lv.orders {
+			if fn(od) {
+				removed = append(removed, od)
+				s.unindex(od)
+				continue
+			}
+			rest = append(rest, od)
+		}
+		lv.orders = rest
+		if len(rest) > 0 {
+			levels = append(levels, lv)
+		}
+	}
+	for i := len(levels); i < len(s.levels); i++ {
+		s.levels[i] = nil
+	}
+	s.levels = levels
+	s.size -= len(removed)
+	return removed
+}
+
+// hasAccount checks if the account has orders in the side.
+func (s *bookSide) hasAccount(aid string) bool {
+	return len(s.accounts[aid]) > 0
+}
+
+// accountOrders returns the copy of the orders of the account on the tp side in priority order,
+// the orders are located by the index, only the price levels of them are searched.
+func (s *bookSide) accountOrders(tp Type, aid string) []Order {
+	type pos struct{ i, j int }
+	ps := []pos{}
+	for id, price := range s.accounts[aid] {
+		i := sort.Search(len(s.levels), func(i int) bool {
+			return !better(tp, s.levels[i].price, price)
+		})
+		if i == len(s.levels) || s.levels[i].price != price {
+			continue
+		}
+		for j, od := range s.levels[i].orders {
+			if od.ID == id {
+				ps = append(ps, pos{i, j})
+				break
+			}
+		}
+	}
+	sort.Slice(ps, func(a, b int) bool {
+		if ps[a].i != ps[b].i {
+			return ps[a].i < ps[b].i
+		}
+		return ps[a].j < ps[b].j
+	})
+
+	ods := make([]Order, len(ps))
+	for k, p := range ps {
+		ods[k] = s.levels[p.i].orders[p.j]
+	}
+	return ods
+}
+
+// orders returns the copy of all the orders in priority order.
+func (s *bookSide) orders() []Order {
+	orders := make([]Order, 0, s.size)
+	for _, lv := range s.levels {
+		orders = append(orders, lv.orders...)
+	}
+	return orders
+}
+
+// clone returns the deep copy of the side.
+func (s *bookSide) clone() bookSide {
+	c := bookSide{levels: make([]*priceLevel, len(s.levels)), size: s.size}
+	for i, lv := range s.levels {
+		c.levels[i] = &priceLevel{price: lv.price, orders: append([]Order(nil), lv.orders...)}
+	}
+	if s.accounts != nil {
+		c.accounts = make(map[string]map[uint64]uint64, len(s.accounts))
+		for aid, ids := range s.accounts {
+			c.accounts[aid] = make(map[uint64]uint64, len(ids))
+			for id, price := range ids {
+				c.accounts[aid][id] = price
+			}
+		}
+	}
+	return c
+}
+
+// depth returns the total rest amount of the best levels, at most levels entries.
+func (s *bookSide) depth(levels int) []DepthLevel {
+	depth := []DepthLevel{}
+	for _, lv := range s.levels {
+		if len(depth) == levels {
+			break
+		}
+		dl := DepthLevel{Price: lv.price}
+		for _, od := range lv.orders {
+			dl.TotalAmount += od.RestAmt
+		}
+		depth = append(depth, dl)
+	}
+	return depth
+}
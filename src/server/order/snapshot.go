@@ -0,0 +1,207 @@
+package order
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/server/persist"
+	"github.com/skycoin/skycoin/src/util"
+)
+
+const (
+	// DefaultSnapshotInterval the default interval of the book snapshots.
+	DefaultSnapshotInterval = 10 * time.Minute
+	// DefaultSnapshotKeep the default number of snapshots kept for each book.
+	DefaultSnapshotKeep = 6
+
+	snapshotDir    = "snapshot"
+	snapshotLayout = "20060102T150405.000000000Z"
+)
+
+// ErrNoSnapshot is returned when the book has no valid snapshot to restore from.
+var ErrNoSnapshot = errors.New("no valid order book snapshot")
+
+// bookSnapshot the full copy of a book, the checksum is the hex encoded sha256 of the
+// compact book json, so that the truncated or corrupted snapshot is detected.
+type bookSnapshot struct {
+	Checksum string          `json:"checksum"`
+	Book     json.RawMessage `json:"book"`
+}
+
+// SetSnapshotInterval sets the interval of the full snapshots of the books, which are written
+// to timestamped files besides the book files, only the newest keep ones of each book are kept.
+// The books are restored from the snapshots by LoadManager if their files are corrupted.
+// 0 interval disables the snapshots. It must be called before Start.
+func (m *Manager) SetSnapshotInterval(d time.Duration, keep int) {
+	if keep <= 0 {
+		keep = DefaultSnapshotKeep
+	}
+	m.mtx.Lock()
+	m.snapshotInterval = d
+	m.snapshotKeep = keep
+	m.mtx.Unlock()
+}
+
+// runSnapshots snapshots the books every d until closing is closed.
+func (m *Manager) runSnapshots(d time.Duration, closing chan bool) {
+	for {
+		select {
+		case <-closing:
+			return
+		case <-time.After(d):
+			if err := m.Snapshot(); err != nil {
+				logger.Error("snapshot order books failed: %v", err)
+			}
+		}
+	}
+}
+
+// Snapshot writes the snapshots of all the books, and removes the old ones beyond the
+// number to keep. The books failed to snapshot are skipped, the last error is returned.
+func (m *Manager) Snapshot() error {
+	m.mtx.RLock()
+	keep := m.snapshotKeep
+	books := make(map[string]*Book, len(m.books))
+	for cp, bk := range m.books {
+		books[cp] = bk
+	}
+	m.mtx.RUnlock()
+	if keep <= 0 {
+		keep = DefaultSnapshotKeep
+	}
+
+	now := time.Now()
+	var err error
+	for cp, bk := range books {
+		if e := saveSnapshot(cp, bk, now); e != nil {
+			err = e
+			continue
+		}
+		if e := pruneSnapshots(cp, keep); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// RestoreFromSnapshot loads the book of coin pair cp from its newest valid snapshot,
+// the invalid snapshots are skipped, ErrNoSnapshot is returned if none is valid.
+func RestoreFromSnapshot(cp string) (*Book, error) {
+	files, err := snapshotFiles(cp)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(files) - 1; i >= 0; i-- {
+		bj, err := loadSnapshot(files[i])
+		if err != nil {
+			logger.Error("order book snapshot %s is invalid: %v", filepath.Base(files[i]), err)
+			continue
+		}
+		logger.Info("order book %s restored from snapshot %s", cp, filepath.Base(files[i]))
+		return NewBookFromJson(bj), nil
+	}
+	return nil, fmt.Errorf("%w of %s", ErrNoSnapshot, cp)
+}
+
+// saveSnapshot writes the snapshot of the book taken at time t.
+func saveSnapshot(cp string, bk *Book, t time.Time) error {
+	d, err := json.Marshal(bk.Copy().ToMarshalable())
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(orderDir, snapshotDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(d)
+	name := snapshotPrefix(cp) + t.UTC().Format(snapshotLayout) + "." + orderExt
+	return persist.SaveJSON(filepath.Join(dir, name), bookSnapshot{
+		Checksum: hex.EncodeToString(sum[:]),
+		Book:     d,
+	}, 0600)
+}
+
+// loadSnapshot loads the book json from the snapshot file, and verifies its checksum.
+func loadSnapshot(path string) (BookJson, error) {
+	s := bookSnapshot{}
+	if err := util.LoadJSON(path, &s); err != nil {
+		return BookJson{}, err
+	}
+
+	// the book json is indented by the saving, the checksum is of the compact one.
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, s.Book); err != nil {
+		return BookJson{}, err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	if want, err := hex.DecodeString(s.Checksum); err != nil || !bytes.Equal(want, sum[:]) {
+		return BookJson{}, errors.New("checksum mismatch")
+	}
+
+	bj := BookJson{}
+	if err := json.Unmarshal(s.Book, &bj); err != nil {
+		return BookJson{}, err
+	}
+	if err := migrateBook(&bj); err != nil {
+		return BookJson{}, err
+	}
+	return bj, nil
+}
+
+// pruneSnapshots removes the oldest snapshots of the book beyond the number to keep.
+func pruneSnapshots(cp string, keep int) error {
+	files, err := snapshotFiles(cp)
+	if err != nil {
+		return err
+	}
+
+	for len(files) > keep {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// snapshotFiles returns the snapshot files of the book from the oldest to the newest.
+func snapshotFiles(cp string) ([]string, error) {
+	dir := filepath.Join(orderDir, snapshotDir)
+	fs, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	prefix := snapshotPrefix(cp)
+	var files []string
+	for _, f := range fs {
+		if f.IsDir() || !strings.HasPrefix(f.Name(), prefix) || !strings.HasSuffix(f.Name(), "."+orderExt) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, f.Name()))
+	}
+	// the timestamps have fixed width, so the names sort by time.
+	sort.Strings(files)
+	return files, nil
+}
+
+// snapshotPrefix returns the file name prefix of the book's snapshots, like bitcoin_skycoin-.
+func snapshotPrefix(cp string) string {
+	return strings.Replace(cp, "/", "_", -1) + "-"
+}
@@ -0,0 +1,160 @@
+package order
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func snapshotBook(amounts ...uint64) *Book {
+	bk := &Book{}
+	for i, amt := range amounts {
+		bk.AddBid(Order{ID: uint64(i + 1), Type: Bid, Price: 100, Amount: amt, RestAmt: amt, AccountID: "a"})
+	}
+	return bk
+}
+
+func bidAmounts(t *testing.T, bk *Book) []uint64 {
+	bids, _, err := bk.GetOrders(Bid, 0, 100)
+	assert.Nil(t, err)
+	var amts []uint64
+	for _, od := range bids {
+		amts = append(amts, od.Amount)
+	}
+	return amts
+}
+
+func TestSnapshotRetention(t *testing.T) {
+	defer useTempOrderDir(t)()
+
+	cp := "bitcoin/skycoin"
+	m := NewManager()
+	assert.Nil(t, m.AddBook(cp, &Book{}))
+	assert.Nil(t, m.AddBook("litecoin/skycoin", &Book{}))
+	m.SetSnapshotInterval(time.Minute, 3)
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, m.Snapshot())
+	}
+	for _, pair := range []string{cp, "litecoin/skycoin"} {
+		files, err := snapshotFiles(pair)
+		assert.Nil(t, err)
+		assert.Len(t, files, 3)
+	}
+
+	// the newest snapshot is kept.
+	bk, _ := m.getBook(cp)
+	bk.AddBid(Order{ID: 1, Type: Bid, Price: 100, Amount: 7, RestAmt: 7, AccountID: "a"})
+	assert.Nil(t, m.Snapshot())
+	restored, err := RestoreFromSnapshot(cp)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{7}, bidAmounts(t, restored))
+}
+
+func TestRestoreFromSnapshot(t *testing.T) {
+	defer useTempOrderDir(t)()
+
+	cp := "bitcoin/skycoin"
+	_, err := RestoreFromSnapshot(cp)
+	assert.True(t, errors.Is(err, ErrNoSnapshot))
+
+	now := time.Now()
+	assert.Nil(t, saveSnapshot(cp, snapshotBook(1), now))
+	assert.Nil(t, saveSnapshot(cp, snapshotBook(1, 2), now.Add(time.Second)))
+	assert.Nil(t, saveSnapshot(cp, snapshotBook(1, 2, 3), now.Add(2*time.Second)))
+
+	bk, err := RestoreFromSnapshot(cp)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1, 2, 3}, bidAmounts(t, bk))
+
+	// the truncated and the tampered snapshots are skipped.
+	files, err := snapshotFiles(cp)
+	assert.Nil(t, err)
+	d, err := ioutil.ReadFile(files[2])
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(files[2], d[:len(d)/2], 0600))
+	assert.Nil(t, ioutil.WriteFile(files[1], []byte(`{"checksum":"00","book":{"bids":[]}}`), 0600))
+
+	bk, err = RestoreFromSnapshot(cp)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1}, bidAmounts(t, bk))
+
+	assert.Nil(t, ioutil.WriteFile(files[0], nil, 0600))
+	_, err = RestoreFromSnapshot(cp)
+	assert.True(t, errors.Is(err, ErrNoSnapshot))
+}
+
+func TestLoadManagerFromSnapshot(t *testing.T) {
+	defer useTempOrderDir(t)()
+
+	cp := "bitcoin/skycoin"
+	m := NewManager()
+	assert.Nil(t, m.AddBook(cp, snapshotBook(1, 2)))
+	assert.Nil(t, m.AddBook("litecoin/skycoin", snapshotBook(5)))
+	assert.Nil(t, m.Save())
+	assert.Nil(t, m.Snapshot())
+
+	// the orders placed after the snapshot are lost with the corrupted file.
+	bk, _ := m.getBook(cp)
+	bk.AddBid(Order{ID: 3, Type: Bid, Price: 100, Amount: 3, RestAmt: 3, AccountID: "a"})
+	assert.Nil(t, m.Save())
+	primary := filepath.Join(orderDir, "bitcoin_skycoin."+orderExt)
+	assert.Nil(t, ioutil.WriteFile(primary, []byte(`{"bids":[{"id":1,`), 0600))
+
+	m1, err := LoadManager()
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1, 2}, bidAmounts(t, m1.GetBook(cp)))
+	assert.Equal(t, []uint64{5}, bidAmounts(t, m1.GetBook("litecoin/skycoin")))
+
+	// the restored book is rewritten by the next flush.
+	assert.Nil(t, m1.Flush())
+	m2, err := LoadManager()
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1, 2}, bidAmounts(t, m2.GetBook(cp)))
+
+	// the corrupted book without snapshot fails the loading.
+	assert.Nil(t, ioutil.WriteFile(primary, []byte(`corrupted`), 0600))
+	files, err := snapshotFiles(cp)
+	assert.Nil(t, err)
+	for _, f := range files {
+		assert.Nil(t, ioutil.WriteFile(f, []byte(`corrupted`), 0600))
+	}
+	_, err = LoadManager()
+	assert.NotNil(t, err)
+}
+
+func TestRunSnapshots(t *testing.T) {
+	defer useTempOrderDir(t)()
+
+	cp := "bitcoin/skycoin"
+	m := NewManager()
+	assert.Nil(t, m.AddBook(cp, snapshotBook(1)))
+	m.RegisterOrderChan(cp, make(chan Fill, 100))
+	m.SetSnapshotInterval(20*time.Millisecond, 2)
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		m.Start(10*time.Millisecond, closing)
+		close(done)
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	close(closing)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("manager is not stopped")
+	}
+
+	files, err := snapshotFiles(cp)
+	assert.Nil(t, err)
+	assert.Len(t, files, 2)
+
+	// the snapshots are disabled by 0 interval.
+	m.SetSnapshotInterval(0, 0)
+	assert.Equal(t, DefaultSnapshotKeep, m.snapshotKeep)
+}
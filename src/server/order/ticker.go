@@ -0,0 +1,78 @@
+package order
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/server/trade"
+)
+
+// TickerWindow the time window of the ticker volume.
+const TickerWindow = 24 * time.Hour
+
+// Ticker the top of book and the recent trading of a coin pair, the fields are nil
+// if the book side is empty or there's no trade.
+type Ticker struct {
+	Pair      string  `json:"pair"`
+	BestBid   *uint64 `json:"best_bid"`
+	BestAsk   *uint64 `json:"best_ask"`
+	Spread    *uint64 `json:"spread"`     // best ask minus best bid, nil if either side is empty.
+	LastPrice *uint64 `json:"last_price"` // price of the last trade.
+	Volume    uint64  `json:"volume"`     // main coins traded in the last TickerWindow.
+	Time      int64   `json:"time"`       // unix time of the ticker.
+}
+
+// SetTradeLog sets the trade log which the ticker's last price and volume come from.
+func (m *Manager) SetTradeLog(tl *trade.TradeLog) {
+	m.mtx.Lock()
+	m.tradeLog = tl
+	m.mtx.Unlock()
+}
+
+// GetTicker returns the ticker of specific coin pair, the best bid and ask come from the book,
+// and the last price and volume come from the trade log, they're nil and zero if the trade log
+// is not set.
+func (m *Manager) GetTicker(cp string) (Ticker, error) {
+	bk, ok := m.getBook(cp)
+	if !ok {
+		return Ticker{}, fmt.Errorf("coin pair:%s not supported", cp)
+	}
+
+	now := time.Now().Unix()
+	tk := Ticker{Pair: cp, Time: now}
+	bids, asks := bk.Depth(1)
+	if len(bids) > 0 {
+		tk.BestBid = &bids[0].Price
+	}
+	if len(asks) > 0 {
+		tk.BestAsk = &asks[0].Price
+	}
+	if tk.BestBid != nil && tk.BestAsk != nil && *tk.BestAsk >= *tk.BestBid {
+		spread := *tk.BestAsk - *tk.BestBid
+		tk.Spread = &spread
+	}
+
+	m.mtx.RLock()
+	tl := m.tradeLog
+	m.mtx.RUnlock()
+	if tl == nil {
+		return tk, nil
+	}
+
+	trades, err := tl.Query(cp, 0, now)
+	if err != nil {
+		return Ticker{}, err
+	}
+
+	if len(trades) > 0 {
+		tk.LastPrice = &trades[len(trades)-1].Price
+	}
+
+	since := now - int64(TickerWindow/time.Second)
+	for _, t := range trades {
+		if t.Time > since {
+			tk.Volume += t.Amount
+		}
+	}
+	return tk, nil
+}
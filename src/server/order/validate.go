@@ -0,0 +1,107 @@
+package order
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Validator checks the order before it enters the book of coin pair cp, the order is
+// rejected with the returned error. The book must not be modified by the validator.
+type Validator func(cp string, bk *Book, od Order) error
+
+// Pipeline is a list of validators, which are run in order.
+type Pipeline []Validator
+
+// Validate runs the validators in order, and returns the error of the first failing one.
+func (p Pipeline) Validate(cp string, bk *Book, od Order) error {
+	for _, v := range p {
+		if err := v(cp, bk, od); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateNotHalted rejects the order if the trading of the book is halted.
+func ValidateNotHalted(cp string, bk *Book, od Order) error {
+	if bk.Halted() {
+		return ErrPairHalted
+	}
+	return nil
+}
+
+// ValidateAmount rejects the order of zero amount.
+func ValidateAmount(cp string, bk *Book, od Order) error {
+	if od.Amount == 0 {
+		return ErrZeroAmount
+	}
+	return nil
+}
+
+// ValidatePrice rejects the limit order of zero price, the price of market order is ignored.
+func ValidatePrice(cp string, bk *Book, od Order) error {
+	if od.Kind == Limit && od.Price == 0 {
+		return ErrZeroPrice
+	}
+	return nil
+}
+
+// ValidateTick rejects the order whose price or stop price is not on the tick grid of the book.
+func ValidateTick(cp string, bk *Book, od Order) error {
+	tick := bk.TickSize()
+	if tick == 0 {
+		return nil
+	}
+	if od.Kind == Limit && od.Price%tick != 0 {
+		return fmt.Errorf("%w: price %d, tick size %d", ErrOffTick, od.Price, tick)
+	}
+	if od.StopPrice%tick != 0 {
+		return fmt.Errorf("%w: stop price %d, tick size %d", ErrOffTick, od.StopPrice, tick)
+	}
+	return nil
+}
+
+// ValidatePriceBand rejects the limit order whose price is further than the price band of the
+// book from the last trade price. The book without trade has no reference price, so any price is
+// allowed, the market orders and the stop orders are not checked.
+func ValidatePriceBand(cp string, bk *Book, od Order) error {
+	band, last := bk.PriceBand(), bk.LastPrice()
+	if band == 0 || last == 0 || od.Kind != Limit || od.IsStop() {
+		return nil
+	}
+
+	diff := od.Price - last
+	if od.Price < last {
+		diff = last - od.Price
+	}
+	// diff/last > band/10000, compared in 128 bits so that it can't overflow.
+	dh, dl := bits.Mul64(diff, 10000)
+	bh, bl := bits.Mul64(last, band)
+	if dh > bh || (dh == bh && dl > bl) {
+		return fmt.Errorf("%w: price %d, last price %d, band %d bps", ErrOutsidePriceBand, od.Price, last, band)
+	}
+	return nil
+}
+
+// ValidateMinAmount rejects the order whose amount is below the minimum amount of the book.
+func ValidateMinAmount(cp string, bk *Book, od Order) error {
+	if min := bk.MinAmount(); od.Amount < min {
+		return fmt.Errorf("%w: amount %d, min amount %d", ErrBelowMinAmount, od.Amount, min)
+	}
+	return nil
+}
+
+// ValidateBookSize rejects the resting or stop order when the book holds its max orders,
+// and the order can't evict any by the policy of the book. It's checked again when the
+// order is added, since the book may be filled in the meantime.
+func ValidateBookSize(cp string, bk *Book, od Order) error {
+	if !od.IsStop() && (od.Kind == Market || od.TimeInForce == IOC) {
+		return nil
+	}
+
+	max, policy := bk.MaxOrders()
+	if max > 0 && bk.Len() >= max && (policy != FullEvictWorst || od.IsStop()) {
+		return ErrBookFull
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const validateCp = "bitcoin/skycoin"
+
+func TestPipeline(t *testing.T) {
+	var called []string
+	rule := func(name string, err error) Validator {
+		return func(cp string, bk *Book, od Order) error {
+			assert.Equal(t, validateCp, cp)
+			called = append(called, name)
+			return err
+		}
+	}
+
+	bk := &Book{}
+	assert.Nil(t, Pipeline{}.Validate(validateCp, bk, Order{}))
+	assert.Nil(t, Pipeline{rule("a", nil), rule("b", nil)}.Validate(validateCp, bk, Order{}))
+	assert.Equal(t, []string{"a", "b"}, called)
+
+	// the first failing rule stops the pipeline.
+	called = nil
+	errB := errors.New("b failed")
+	p := Pipeline{rule("a", nil), rule("b", errB), rule("c", errors.New("c failed"))}
+	assert.Equal(t, errB, p.Validate(validateCp, bk, Order{}))
+	assert.Equal(t, []string{"a", "b"}, called)
+}
+
+func TestValidateAmount(t *testing.T) {
+	bk := &Book{}
+	assert.Equal(t, ErrZeroAmount, ValidateAmount(validateCp, bk, Order{Type: Bid, Price: 100}))
+	assert.Nil(t, ValidateAmount(validateCp, bk, Order{Type: Bid, Price: 100, Amount: 1}))
+}
+
+func TestValidatePrice(t *testing.T) {
+	bk := &Book{}
+	assert.Equal(t, ErrZeroPrice, ValidatePrice(validateCp, bk, Order{Type: Bid, Kind: Limit, Amount: 1}))
+	assert.Nil(t, ValidatePrice(validateCp, bk, Order{Type: Bid, Kind: Limit, Price: 1, Amount: 1}))
+
+	// the price of market order is ignored.
+	assert.Nil(t, ValidatePrice(validateCp, bk, Order{Type: Bid, Kind: Market, Amount: 1}))
+}
+
+func TestValidateTick(t *testing.T) {
+	bk := &Book{}
+	assert.Nil(t, ValidateTick(validateCp, bk, Order{Kind: Limit, Price: 101}))
+
+	bk.SetTickSize(5)
+	assert.True(t, errors.Is(ValidateTick(validateCp, bk, Order{Kind: Limit, Price: 101}), ErrOffTick))
+	assert.Nil(t, ValidateTick(validateCp, bk, Order{Kind: Limit, Price: 100}))
+	assert.True(t, errors.Is(ValidateTick(validateCp, bk, Order{Kind: Limit, Price: 100, StopPrice: 92}), ErrOffTick))
+	assert.Nil(t, ValidateTick(validateCp, bk, Order{Kind: Limit, Price: 100, StopPrice: 95}))
+	assert.Nil(t, ValidateTick(validateCp, bk, Order{Kind: Market, Price: 101}))
+}
+
+func TestValidateMinAmount(t *testing.T) {
+	bk := &Book{}
+	assert.Nil(t, ValidateMinAmount(validateCp, bk, Order{Amount: 1}))
+
+	bk.SetMinAmount(10)
+	assert.True(t, errors.Is(ValidateMinAmount(validateCp, bk, Order{Amount: 9}), ErrBelowMinAmount))
+	assert.Nil(t, ValidateMinAmount(validateCp, bk, Order{Amount: 10}))
+}
+
+func TestValidatePriceBand(t *testing.T) {
+	bk := &Book{}
+	bk.SetPriceBand(1000)
+
+	// no reference price before the first trade.
+	assert.Nil(t, ValidatePriceBand(validateCp, bk, Order{Type: Bid, Price: 1, Amount: 1}))
+	assert.Nil(t, ValidatePriceBand(validateCp, bk, Order{Type: Ask, Price: 1e9, Amount: 1}))
+
+	bk.AddAsk(Order{ID: 1, Type: Ask, Price: 1000, Amount: 1, RestAmt: 1, AccountID: "a", CreatedAt: 1})
+	bk.AddBid(Order{ID: 2, Type: Bid, Price: 1000, Amount: 1, RestAmt: 1, AccountID: "b", CreatedAt: 2})
+	bk.Match()
+	assert.Equal(t, uint64(1000), bk.LastPrice())
+
+	for _, tc := range []struct {
+		od  Order
+		err error
+	}{
+		{Order{Type: Bid, Price: 1000, Amount: 1}, nil},
+		{Order{Type: Bid, Price: 1100, Amount: 1}, nil},
+		{Order{Type: Bid, Price: 1101, Amount: 1}, ErrOutsidePriceBand},
+		{Order{Type: Ask, Price: 900, Amount: 1}, nil},
+		{Order{Type: Ask, Price: 899, Amount: 1}, ErrOutsidePriceBand},
+		{Order{Type: Ask, Price: math.MaxUint64, Amount: 1}, ErrOutsidePriceBand},
+		// the market and stop orders are not checked.
+		{Order{Type: Bid, Kind: Market, Amount: 1}, nil},
+		{Order{Type: Bid, Price: 2000, StopPrice: 1500, Amount: 1}, nil},
+	} {
+		err := ValidatePriceBand(validateCp, bk, tc.od)
+		if tc.err == nil {
+			assert.Nil(t, err, "price %d", tc.od.Price)
+		} else {
+			assert.True(t, errors.Is(err, tc.err), "price %d", tc.od.Price)
+		}
+	}
+
+	// the band is saved with the book, and 0 disables it.
+	assert.Equal(t, uint64(1000), NewBookFromJson(bk.ToMarshalable()).PriceBand())
+	bk.SetPriceBand(0)
+	assert.Nil(t, ValidatePriceBand(validateCp, bk, Order{Type: Bid, Price: 5000, Amount: 1}))
+}
+
+func TestValidateBookSize(t *testing.T) {
+	bk := &Book{}
+	bk.AddAsk(Order{ID: 1, Type: Ask, Price: 200, Amount: 1, RestAmt: 1, AccountID: "a"})
+	bid := Order{Type: Bid, Kind: Limit, Price: 100, Amount: 1}
+	assert.Nil(t, ValidateBookSize(validateCp, bk, bid))
+
+	bk.SetMaxOrders(1, FullReject)
+	assert.Equal(t, 1, bk.Len())
+	assert.Equal(t, ErrBookFull, ValidateBookSize(validateCp, bk, bid))
+
+	// the market and IOC orders never rest in the book.
+	assert.Nil(t, ValidateBookSize(validateCp, bk, Order{Type: Bid, Kind: Market, Amount: 1}))
+	assert.Nil(t, ValidateBookSize(validateCp, bk, Order{Type: Bid, Kind: Limit, TimeInForce: IOC, Price: 100, Amount: 1}))
+
+	// the resting order may evict one, but the stop order can't.
+	bk.SetMaxOrders(1, FullEvictWorst)
+	assert.Nil(t, ValidateBookSize(validateCp, bk, bid))
+	assert.Equal(t, ErrBookFull, ValidateBookSize(validateCp, bk, Order{Type: Bid, Kind: Market, StopPrice: 150, Amount: 1}))
+
+	bk.SetMaxOrders(2, FullReject)
+	assert.Nil(t, ValidateBookSize(validateCp, bk, bid))
+}
+
+func TestAddOrderZeroPrice(t *testing.T) {
+	m := NewManager()
+	assert.Nil(t, m.AddBook(validateCp, &Book{}))
+	_, err := m.AddOrder(validateCp, Order{Type: Bid, Kind: Limit, Amount: 1, AccountID: "a"})
+	assert.Equal(t, ErrZeroPrice, err)
+}
+
+func TestManagerValidateOrder(t *testing.T) {
+	m := NewManager()
+	p := Pipeline{ValidateAmount, ValidatePrice}
+	assert.NotNil(t, m.ValidateOrder(validateCp, Order{Amount: 1, Price: 1}, p))
+
+	assert.Nil(t, m.AddBook(validateCp, &Book{}))
+	assert.Nil(t, m.ValidateOrder(validateCp, Order{Amount: 1, Price: 1}, p))
+	assert.Equal(t, ErrZeroPrice, m.ValidateOrder(validateCp, Order{Amount: 1}, p))
+}
+
+func TestIsUserError(t *testing.T) {
+	assert.True(t, IsUserError(ErrOffTick))
+	assert.True(t, IsUserError(fmt.Errorf("bitcoin %w", ErrInsufficientBalance)))
+	assert.False(t, IsUserError(ErrManagerStopped))
+	assert.False(t, IsUserError(errors.New("disk full")))
+}
@@ -0,0 +1,132 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// MaxPriceDecimals the max decimal places of the prices in a book.
+const MaxPriceDecimals = 18
+
+// Rounding the rounding policy of the settlement value price*amount when the
+// prices have decimal places.
+type Rounding uint8
+
+const (
+	// RoundDown rounds the value toward zero, it's used for the coins an account receives.
+	RoundDown Rounding = iota
+	// RoundUp rounds the value away from zero, it's used for the coins an account pays,
+	// so that the exchange never pays out more than it takes in.
+	RoundUp
+)
+
+// Value returns the settlement value price*amount in the price coin. The price is a fixed-point
+// number of decimals places, so the product is divided by 10^decimals and rounded by r. The product
+// is computed in 128 bits, ErrValueOverflow is returned if the value doesn't fit in uint64.
+func Value(price, amount uint64, decimals uint8, r Rounding) (uint64, error) {
+	if decimals > MaxPriceDecimals {
+		return 0, fmt.Errorf("price decimals %d exceeds %d", decimals, MaxPriceDecimals)
+	}
+
+	hi, lo := bits.Mul64(price, amount)
+	scale := pow10(decimals)
+	if hi >= scale {
+		return 0, fmt.Errorf("%w: price %d, amount %d, decimals %d", ErrValueOverflow, price, amount, decimals)
+	}
+
+	v, rem := bits.Div64(hi, lo, scale)
+	if r == RoundUp && rem > 0 {
+		if v == math.MaxUint64 {
+			return 0, fmt.Errorf("%w: price %d, amount %d, decimals %d", ErrValueOverflow, price, amount, decimals)
+		}
+		v++
+	}
+	return v, nil
+}
+
+// MaxAmount returns the max amount whose value at price, rounded up, is within budget.
+func MaxAmount(budget, price uint64, decimals uint8) uint64 {
+	if price == 0 || decimals > MaxPriceDecimals {
+		return 0
+	}
+
+	// the value is within budget as long as price*amount <= budget*10^decimals.
+	hi, lo := bits.Mul64(budget, pow10(decimals))
+	if hi >= price {
+		return math.MaxUint64
+	}
+	n, _ := bits.Div64(hi, lo, price)
+	return n
+}
+
+// AmountForValue returns the min amount whose value at price, rounded down, is at least value.
+func AmountForValue(value, price uint64, decimals uint8) (uint64, error) {
+	if decimals > MaxPriceDecimals {
+		return 0, fmt.Errorf("price decimals %d exceeds %d", decimals, MaxPriceDecimals)
+	}
+	if price == 0 {
+		return 0, ErrZeroPrice
+	}
+
+	// the value is reached as long as price*amount >= value*10^decimals.
+	hi, lo := bits.Mul64(value, pow10(decimals))
+	if hi >= price {
+		return 0, fmt.Errorf("%w: value %d, price %d, decimals %d", ErrValueOverflow, value, price, decimals)
+	}
+	n, rem := bits.Div64(hi, lo, price)
+	if rem > 0 {
+		if n == math.MaxUint64 {
+			return 0, fmt.Errorf("%w: value %d, price %d, decimals %d", ErrValueOverflow, value, price, decimals)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// ToBase returns the order with its amount in the base coin, the prices have decimals places.
+// The quote amount of a bid is the most it spends, so the base amount is rounded down, the
+// quote amount of an ask is the least it receives, so the base amount is rounded up. Only the
+// limit orders can have the quote amount, the market orders have no price to convert at.
+func (od Order) ToBase(decimals uint8) (Order, error) {
+	switch od.AmountCurrency {
+	case Base:
+		return od, nil
+	case Quote:
+	default:
+		return od, fmt.Errorf("unknow amount currency:%d", od.AmountCurrency)
+	}
+	if od.Kind != Limit || od.Price == 0 {
+		return od, ErrQuoteAmount
+	}
+
+	var amt uint64
+	switch od.Type {
+	case Bid:
+		amt = MaxAmount(od.Amount, od.Price, decimals)
+	case Ask:
+		var err error
+		if amt, err = AmountForValue(od.Amount, od.Price, decimals); err != nil {
+			return od, err
+		}
+	default:
+		return od, errors.New("unknow order type")
+	}
+	if amt == 0 {
+		return od, ErrZeroAmount
+	}
+
+	od.Amount = amt
+	od.RestAmt = amt
+	od.AmountCurrency = Base
+	return od, nil
+}
+
+func pow10(n uint8) uint64 {
+	v := uint64(1)
+	for i := uint8(0); i < n; i++ {
+		v *= 10
+	}
+	return v
+}
@@ -0,0 +1,176 @@
+package order
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue(t *testing.T) {
+	testData := []struct {
+		price    uint64
+		amount   uint64
+		decimals uint8
+		down     uint64
+		up       uint64
+		overflow bool
+	}{
+		{100, 1000, 0, 100000, 100000, false},
+		{0, 1000, 0, 0, 0, false},
+		// 1.5 * 3 = 4.5
+		{150, 3, 2, 4, 5, false},
+		// 0.00000001 * 1 = 0.00000001
+		{1, 1, 8, 0, 1, false},
+		// 12.345 * 200 = 2469
+		{12345, 200, 3, 2469, 2469, false},
+		{math.MaxUint64, 1, 0, math.MaxUint64, math.MaxUint64, false},
+		{1, math.MaxUint64, 0, math.MaxUint64, math.MaxUint64, false},
+		{math.MaxUint64 / 2, 2, 0, math.MaxUint64 - 1, math.MaxUint64 - 1, false},
+		{math.MaxUint64/2 + 1, 2, 0, 0, 0, true},
+		{1 << 32, 1 << 32, 0, 0, 0, true},
+		// the product exceeds 64 bits, but the scaled value fits.
+		{math.MaxUint64, 100, 2, math.MaxUint64, math.MaxUint64, false},
+		{math.MaxUint64, 1000, 2, 0, 0, true},
+		// max * 0.9 = 16602069666338596453.5
+		{math.MaxUint64, 9, 1, 16602069666338596453, 16602069666338596454, false},
+		{math.MaxUint64, 1 << 60, 18, 0, 0, true},
+	}
+
+	for _, d := range testData {
+		down, err := Value(d.price, d.amount, d.decimals, RoundDown)
+		if d.overflow {
+			assert.True(t, errors.Is(err, ErrValueOverflow), "%+v", d)
+		} else {
+			assert.Nil(t, err, "%+v", d)
+			assert.Equal(t, d.down, down, "%+v", d)
+		}
+
+		up, err := Value(d.price, d.amount, d.decimals, RoundUp)
+		if d.overflow {
+			assert.True(t, errors.Is(err, ErrValueOverflow), "%+v", d)
+		} else {
+			assert.Nil(t, err, "%+v", d)
+			assert.Equal(t, d.up, up, "%+v", d)
+		}
+	}
+
+	// 1676976733973595601.4 * 11 = max + 0.4, rounding up past the max overflows.
+	v, err := Value(16769767339735956014, 11, 1, RoundDown)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), v)
+	_, err = Value(16769767339735956014, 11, 1, RoundUp)
+	assert.True(t, errors.Is(err, ErrValueOverflow))
+
+	_, err = Value(1, 1, MaxPriceDecimals+1, RoundDown)
+	assert.NotNil(t, err)
+}
+
+func TestMaxAmount(t *testing.T) {
+	// 1.5 per coin, 5 can buy 3, 4 can only buy 2, which costs 3 rounded up.
+	assert.Equal(t, uint64(3), MaxAmount(5, 150, 2))
+	assert.Equal(t, uint64(2), MaxAmount(4, 150, 2))
+	v, _ := Value(150, 2, 2, RoundUp)
+	assert.Equal(t, uint64(3), v)
+
+	assert.Equal(t, uint64(7), MaxAmount(770, 100, 0))
+	assert.Equal(t, uint64(0), MaxAmount(99, 100, 0))
+	assert.Equal(t, uint64(0), MaxAmount(100, 0, 0))
+	assert.Equal(t, uint64(math.MaxUint64), MaxAmount(math.MaxUint64, 1, 0))
+	assert.Equal(t, uint64(math.MaxUint64), MaxAmount(math.MaxUint64, 1, 8))
+}
+
+func TestAmountForValue(t *testing.T) {
+	// 1.5 per coin, 4 is received by selling 3, which is 4.5 rounded down.
+	n, err := AmountForValue(4, 150, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), n)
+	v, _ := Value(150, 2, 2, RoundDown)
+	assert.Equal(t, uint64(3), v)
+	n, err = AmountForValue(6, 150, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(4), n)
+
+	n, err = AmountForValue(0, 100, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), n)
+	_, err = AmountForValue(100, 0, 0)
+	assert.Equal(t, ErrZeroPrice, err)
+	_, err = AmountForValue(math.MaxUint64, 1, 8)
+	assert.True(t, errors.Is(err, ErrValueOverflow))
+}
+
+func TestToBase(t *testing.T) {
+	testData := []struct {
+		od     Order
+		amount uint64
+		err    error
+	}{
+		// the base amount is kept.
+		{Order{Type: Bid, Price: 150, Amount: 5}, 5, nil},
+		{Order{Type: Bid, Kind: Market, Amount: 5}, 5, nil},
+		// spend 5 at 1.5 buys 3, receive 5 at 1.5 sells 4.
+		{Order{Type: Bid, Price: 150, Amount: 5, AmountCurrency: Quote}, 3, nil},
+		{Order{Type: Ask, Price: 150, Amount: 5, AmountCurrency: Quote}, 4, nil},
+		{Order{Type: Bid, Price: 150, Amount: 6, AmountCurrency: Quote}, 4, nil},
+		{Order{Type: Ask, Price: 150, Amount: 6, AmountCurrency: Quote}, 4, nil},
+		// the stop limit order is converted at its limit price.
+		{Order{Type: Bid, Price: 150, StopPrice: 200, Amount: 6, AmountCurrency: Quote}, 4, nil},
+		// 1 can't buy any at 1.5.
+		{Order{Type: Bid, Price: 150, Amount: 1, AmountCurrency: Quote}, 0, ErrZeroAmount},
+		{Order{Type: Bid, Kind: Market, Amount: 5, AmountCurrency: Quote}, 0, ErrQuoteAmount},
+		{Order{Type: Ask, Amount: 5, AmountCurrency: Quote}, 0, ErrQuoteAmount},
+	}
+	for _, d := range testData {
+		od, err := d.od.ToBase(2)
+		assert.Equal(t, d.err, err, "%+v", d.od)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, d.amount, od.Amount, "%+v", d.od)
+		assert.Equal(t, Base, od.AmountCurrency, "%+v", d.od)
+		if d.od.AmountCurrency == Quote {
+			assert.Equal(t, d.amount, od.RestAmt, "%+v", d.od)
+		}
+
+		// the converted order is kept as it is.
+		again, err := od.ToBase(2)
+		assert.Nil(t, err)
+		assert.Equal(t, od, again)
+	}
+
+	_, err := Order{Type: Bid, Price: 150, Amount: 5, AmountCurrency: 9}.ToBase(2)
+	assert.NotNil(t, err)
+}
+
+func TestMarketCost(t *testing.T) {
+	asks := []Order{
+		{ID: 1, Type: Ask, Price: 150, RestAmt: 3},
+		{ID: 2, Type: Ask, Price: 175, RestAmt: 5},
+	}
+
+	// 1.5 * 3 + 1.75 * 1 = 4.5 + 1.75, each rounded up.
+	cost, err := MarketCost(asks, 4, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), cost)
+
+	cost, err = MarketCost(asks, 4, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(625), cost)
+
+	// the asks are not deep enough, only the rest amounts are counted.
+	cost, err = MarketCost(asks, 100, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1325), cost)
+
+	asks = []Order{
+		{ID: 1, Type: Ask, Price: math.MaxUint64 / 2, RestAmt: 1},
+		{ID: 2, Type: Ask, Price: math.MaxUint64 / 2, RestAmt: 1},
+		{ID: 3, Type: Ask, Price: math.MaxUint64 / 2, RestAmt: 1},
+	}
+	_, err = MarketCost(asks, 2, 0)
+	assert.Nil(t, err)
+	_, err = MarketCost(asks, 3, 0)
+	assert.True(t, errors.Is(err, ErrValueOverflow))
+}
@@ -0,0 +1,155 @@
+package order
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/util"
+)
+
+// VerifyError describes the corruptions found in the order books.
+type VerifyError struct {
+	Problems []string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("order books are corrupted: %s", strings.Join(e.Problems, "; "))
+}
+
+// ReservedFunc returns the reserved balance of coin type ct in the account,
+// returns error if the account does not exist.
+type ReservedFunc func(accountID, ct string) (uint64, error)
+
+// Verify checks the integrity of the order books, which are usually loaded from disk:
+// the order ids must be unique and not exceed the last id issued by the id generator,
+// the amounts and prices must be valid, and if reserved is not nil, the reserved
+// balance of each account must match the rest amount of its asks. All the problems
+// found are returned in *VerifyError.
+func (m *Manager) Verify(reserved ReservedFunc) error {
+	var problems []string
+	// rest amounts of asks, key: account id, value: coin type to amount.
+	asks := make(map[string]map[string]uint64)
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	cps := make([]string, 0, len(m.books))
+	for cp := range m.books {
+		cps = append(cps, cp)
+	}
+	sort.Strings(cps)
+
+	for _, cp := range cps {
+		bk := m.books[cp].Copy()
+		pair := strings.Split(cp, "/")
+		if len(pair) != 2 {
+			problems = append(problems, fmt.Sprintf("%s: invalid coin pair", cp))
+			continue
+		}
+
+		var lastID uint64
+		if idg, ok := m.idg[cp]; ok {
+			id, err := idg.lastID()
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: load order id failed: %v", cp, err))
+			}
+			lastID = id
+		}
+
+		ids := make(map[uint64]bool)
+		for _, side := range []struct {
+			tp     Type
+			orders []Order
+		}{{Bid, bk.bids.orders()}, {Ask, bk.asks.orders()}} {
+			for _, od := range side.orders {
+				for _, p := range verifyOrder(od, side.tp) {
+					problems = append(problems, fmt.Sprintf("%s: %s order %d %s", cp, side.tp, od.ID, p))
+				}
+
+				if ids[od.ID] {
+					problems = append(problems, fmt.Sprintf("%s: duplicate order id %d", cp, od.ID))
+				}
+				ids[od.ID] = true
+
+				if od.ID > lastID {
+					problems = append(problems, fmt.Sprintf("%s: order id %d exceeds the last issued id %d", cp, od.ID, lastID))
+				}
+
+				if side.tp == Ask {
+					if asks[od.AccountID] == nil {
+						asks[od.AccountID] = make(map[string]uint64)
+					}
+					asks[od.AccountID][pair[0]] += od.RestAmt
+				}
+			}
+		}
+	}
+
+	if reserved != nil {
+		aids := make([]string, 0, len(asks))
+		for aid := range asks {
+			aids = append(aids, aid)
+		}
+		sort.Strings(aids)
+
+		for _, aid := range aids {
+			for ct, amt := range asks[aid] {
+				r, err := reserved(aid, ct)
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("account %s of open orders: %v", aid, err))
+					continue
+				}
+				if r != amt {
+					problems = append(problems, fmt.Sprintf("account %s reserved %s %d, open asks require %d", aid, ct, r, amt))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &VerifyError{Problems: problems}
+	}
+	return nil
+}
+
+// verifyOrder checks the fields of the order resting in the book of tp side.
+func verifyOrder(od Order, tp Type) []string {
+	var problems []string
+	if od.ID == 0 {
+		problems = append(problems, "has zero id")
+	}
+	if od.Type != tp {
+		problems = append(problems, fmt.Sprintf("has wrong type %s", od.Type))
+	}
+	if od.Kind != Limit {
+		problems = append(problems, fmt.Sprintf("has wrong kind %s", od.Kind))
+	}
+	if od.Price == 0 {
+		problems = append(problems, "has zero price")
+	}
+	if od.Amount == 0 {
+		problems = append(problems, "has zero amount")
+	}
+	if od.RestAmt == 0 || od.RestAmt > od.Amount {
+		problems = append(problems, fmt.Sprintf("has invalid rest amount %d of amount %d", od.RestAmt, od.Amount))
+	}
+	if od.AccountID == "" {
+		problems = append(problems, "has no account")
+	}
+	return problems
+}
+
+// lastID returns the last id issued by the generator, 0 if no id is issued.
+func (ig IDGenerator) lastID() (uint64, error) {
+	id := struct {
+		ID uint64 `json:"id"`
+	}{}
+	if _, err := os.Stat(ig.Path); os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err := util.LoadJSON(ig.Path, &id); err != nil {
+		return 0, err
+	}
+	return id.ID, nil
+}
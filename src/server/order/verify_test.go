@@ -0,0 +1,110 @@
+package order
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// loadBookFiles writes the book files into a temp order dir, and loads the manager from it.
+func loadBookFiles(t *testing.T, files map[string]string) (*Manager, func()) {
+	dir, err := ioutil.TempDir("", "orderbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	od := orderDir
+	InitDir(dir)
+	teardown := func() {
+		orderDir = od
+		os.RemoveAll(dir)
+	}
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			teardown()
+			t.Fatal(err)
+		}
+	}
+
+	m, err := LoadManager()
+	if err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+	return m, teardown
+}
+
+func reservedMap(rs map[string]uint64) ReservedFunc {
+	return func(aid, ct string) (uint64, error) {
+		r, ok := rs[aid+":"+ct]
+		if !ok {
+			return 0, errors.New("account does not exist")
+		}
+		return r, nil
+	}
+}
+
+func TestVerify(t *testing.T) {
+	m, teardown := loadBookFiles(t, map[string]string{
+		"bitcoin_skycoin.ods": `{"bids":[{"id":1,"account_id":"a","type":0,"price":100,"amount":10,"reset_amt":5}],
+			"asks":[{"id":2,"account_id":"b","type":1,"price":110,"amount":10,"reset_amt":10},
+			{"id":3,"account_id":"b","type":1,"price":120,"amount":5,"reset_amt":5}]}`,
+		"bitcoin_skycoin.id": `{"id":3}`,
+	})
+	defer teardown()
+
+	assert.Nil(t, m.Verify(nil))
+	assert.Nil(t, m.Verify(reservedMap(map[string]uint64{"b:bitcoin": 15})))
+}
+
+func TestVerifyCorrupted(t *testing.T) {
+	m, teardown := loadBookFiles(t, map[string]string{
+		"bitcoin_skycoin.ods": `{"bids":[{"id":1,"account_id":"a","type":0,"price":0,"amount":10,"reset_amt":5},
+			{"id":2,"account_id":"a","type":1,"price":100,"amount":10,"reset_amt":10}],
+			"asks":[{"id":2,"account_id":"b","type":1,"price":110,"amount":10,"reset_amt":20},
+			{"id":9,"account_id":"c","type":1,"price":120,"amount":0,"reset_amt":0}]}`,
+		"bitcoin_skycoin.id": `{"id":5}`,
+	})
+	defer teardown()
+
+	err := m.Verify(reservedMap(map[string]uint64{"b:bitcoin": 10}))
+	verr, ok := err.(*VerifyError)
+	if !ok {
+		t.Fatalf("expect VerifyError, got %v", err)
+	}
+
+	// the loaded orders are checked in priority order.
+	expect := []string{
+		"bitcoin/skycoin: bid order 2 has wrong type ask",
+		"bitcoin/skycoin: bid order 1 has zero price",
+		"bitcoin/skycoin: ask order 2 has invalid rest amount 20 of amount 10",
+		"bitcoin/skycoin: duplicate order id 2",
+		"bitcoin/skycoin: ask order 9 has zero amount",
+		"bitcoin/skycoin: ask order 9 has invalid rest amount 0 of amount 0",
+		"bitcoin/skycoin: order id 9 exceeds the last issued id 5",
+		"account b reserved bitcoin 10, open asks require 20",
+		"account c of open orders: account does not exist",
+	}
+	assert.Equal(t, expect, verr.Problems)
+	assert.True(t, strings.Contains(err.Error(), "duplicate order id 2"))
+}
+
+func TestVerifyMissingID(t *testing.T) {
+	// the id file is lost, so the order ids will be issued again.
+	m, teardown := loadBookFiles(t, map[string]string{
+		"bitcoin_skycoin.ods": `{"bids":[{"id":1,"account_id":"a","type":0,"price":100,"amount":10,"reset_amt":10}],"asks":[]}`,
+	})
+	defer teardown()
+
+	err := m.Verify(nil)
+	verr, ok := err.(*VerifyError)
+	if !ok {
+		t.Fatalf("expect VerifyError, got %v", err)
+	}
+	assert.Equal(t, []string{"bitcoin/skycoin: order id 1 exceeds the last issued id 0"}, verr.Problems)
+}
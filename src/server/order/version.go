@@ -0,0 +1,51 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BookVersion the version of the order book format written by this binary, the books of the
+// older versions are upgraded on load, and the newer ones are refused.
+//
+// 1: the books saved before the versioning, the orders have no kind, time in force, stop price
+// and amount currency, and the rest amount may be missing.
+// 2: the current format.
+const BookVersion = 2
+
+// ErrBookVersion is returned when the book is saved by a newer binary, loading it would drop
+// the fields this binary doesn't know.
+var ErrBookVersion = errors.New("order book version is not supported")
+
+// bookMigrations upgrades the book of version i+1 to version i+2.
+var bookMigrations = []func(bj *BookJson){
+	migrateBookV1,
+}
+
+// migrateBook upgrades the loaded book json to BookVersion, the book without version is version 1.
+func migrateBook(bj *BookJson) error {
+	if bj.Version == 0 {
+		bj.Version = 1
+	}
+	if bj.Version > BookVersion {
+		return fmt.Errorf("%w: version %d, max %d", ErrBookVersion, bj.Version, BookVersion)
+	}
+	for bj.Version < BookVersion {
+		bookMigrations[bj.Version-1](bj)
+		bj.Version++
+	}
+	return nil
+}
+
+// migrateBookV1 sets the rest amount of the open orders to their amount if it's missing, the
+// other new fields default to zero, which makes the orders the limit GTC orders in the base coin.
+// The invalid rest amounts are kept, so that Verify reports them.
+func migrateBookV1(bj *BookJson) {
+	for _, ods := range [][]Order{bj.BidOrders, bj.AskOrders, bj.StopOrders} {
+		for i := range ods {
+			if ods[i].RestAmt == 0 {
+				ods[i].RestAmt = ods[i].Amount
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package order
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bookV1 the book saved before the versioning, the ask has no rest amount.
+const bookV1 = `{
+    "bids": [
+        {"id": 1, "account_id": "a", "type": 0, "price": 100, "amount": 5, "reset_amt": 3, "created_at": 1},
+        {"id": 2, "account_id": "b", "type": 0, "price": 101, "amount": 2, "reset_amt": 2, "created_at": 2}
+    ],
+    "asks": [
+        {"id": 3, "account_id": "c", "type": 1, "price": 110, "amount": 4, "created_at": 3}
+    ]
+}`
+
+func TestLoadManagerV1(t *testing.T) {
+	defer useTempOrderDir(t)()
+
+	cp := "bitcoin/skycoin"
+	path := filepath.Join(orderDir, "bitcoin_skycoin."+orderExt)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(bookV1), 0600))
+
+	m, err := LoadManager()
+	assert.Nil(t, err)
+	bk := m.GetBook(cp)
+	bids, _, err := bk.GetOrders(Bid, 0, 10)
+	assert.Nil(t, err)
+	asks, _, err := bk.GetOrders(Ask, 0, 10)
+	assert.Nil(t, err)
+
+	// the new fields default to the limit GTC order in the base coin, the missing rest amount is the amount.
+	rest := make(map[uint64]uint64)
+	for _, od := range append(bids, asks...) {
+		assert.Equal(t, Limit, od.Kind)
+		assert.Equal(t, GTC, od.TimeInForce)
+		assert.Equal(t, Base, od.AmountCurrency)
+		assert.Equal(t, uint64(0), od.StopPrice)
+		rest[od.ID] = od.RestAmt
+	}
+	assert.Equal(t, map[uint64]uint64{1: 3, 2: 2, 3: 4}, rest)
+
+	// the upgraded book is rewritten in the current version by the next flush.
+	assert.Nil(t, m.Flush())
+	d, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	bj := BookJson{}
+	assert.Nil(t, json.Unmarshal(d, &bj))
+	assert.Equal(t, BookVersion, bj.Version)
+
+	m1, err := LoadManager()
+	assert.Nil(t, err)
+	assert.Equal(t, m.GetBook(cp), m1.GetBook(cp))
+}
+
+func TestLoadManagerNewerVersion(t *testing.T) {
+	defer useTempOrderDir(t)()
+
+	path := filepath.Join(orderDir, "bitcoin_skycoin."+orderExt)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"version":3,"bids":[],"asks":[],"unknown":1}`), 0600))
+
+	// the book of newer version is refused, rather than restored from the snapshot.
+	_, err := LoadManager()
+	assert.True(t, errors.Is(err, ErrBookVersion), "%v", err)
+
+	// the snapshot of newer version is skipped.
+	bj := snapshotBook(1).ToMarshalable()
+	bj.Version = BookVersion + 1
+	assert.True(t, errors.Is(migrateBook(&bj), ErrBookVersion))
+}
+
+func TestMigrateBook(t *testing.T) {
+	bj := BookJson{
+		BidOrders:  []Order{{ID: 1, Amount: 5}, {ID: 2, Amount: 5, RestAmt: 9}},
+		StopOrders: []Order{{ID: 3, Amount: 2, StopPrice: 100}},
+	}
+	assert.Nil(t, migrateBook(&bj))
+	assert.Equal(t, BookVersion, bj.Version)
+	assert.Equal(t, uint64(5), bj.BidOrders[0].RestAmt)
+	// the invalid rest amount is left to Verify.
+	assert.Equal(t, uint64(9), bj.BidOrders[1].RestAmt)
+	assert.Equal(t, uint64(2), bj.StopOrders[0].RestAmt)
+
+	// the current version is kept as it is.
+	cur := BookJson{Version: BookVersion, BidOrders: []Order{{ID: 1, Amount: 5}}}
+	assert.Nil(t, migrateBook(&cur))
+	assert.Equal(t, uint64(0), cur.BidOrders[0].RestAmt)
+}
@@ -0,0 +1,71 @@
+// Package persist saves the state files of the server atomically, the data is written to a
+// temporary file in the same directory, synced to disk, then renamed over the target, so an
+// interrupted save leaves either the old file or the new one, never a partial one.
+package persist
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// writeData writes the data to the temporary file, replaced in tests to simulate the interruption.
+var writeData = func(f *os.File, d []byte) error {
+	_, err := f.Write(d)
+	return err
+}
+
+// SaveJSON saves obj to path as indented json, the file is replaced atomically.
+func SaveJSON(path string, obj interface{}, mode os.FileMode) error {
+	d, err := json.MarshalIndent(obj, "", "    ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, d, mode)
+}
+
+// WriteFile writes the data to path atomically. The temporary file is removed if any step
+// fails, and the existing file is left untouched.
+func WriteFile(path string, data []byte, mode os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	if err = f.Chmod(mode); err != nil {
+		return err
+	}
+	if err = writeData(f, data); err != nil {
+		return err
+	}
+	// the data must be on disk before the rename, or a crash may leave the renamed file empty.
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir syncs the directory, so the rename survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
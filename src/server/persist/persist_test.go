@@ -0,0 +1,82 @@
+package persist
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data.json")
+	assert.Nil(t, SaveJSON(path, map[string]int{"a": 1}, 0600))
+	assert.Nil(t, SaveJSON(path, map[string]int{"a": 2}, 0600))
+
+	d, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "{\n    \"a\": 2\n}", string(d))
+	fi, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// no temporary file is left.
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestWriteFileInterrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "accounts.json")
+	assert.Nil(t, WriteFile(path, []byte(`{"good":true}`), 0600))
+
+	// the write stops halfway, like the disk is full or the process is killed.
+	errWrite := errors.New("write interrupted")
+	writeData = func(f *os.File, d []byte) error {
+		f.Write(d[:len(d)/2])
+		return errWrite
+	}
+	defer func() {
+		writeData = func(f *os.File, d []byte) error {
+			_, err := f.Write(d)
+			return err
+		}
+	}()
+	assert.Equal(t, errWrite, WriteFile(path, []byte(`{"good":false,"more":"data"}`), 0600))
+
+	// the prior good file is intact, and the partial temporary file is removed.
+	d, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"good":true}`, string(d))
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+
+	// the temporary file left by a crash doesn't affect the next save.
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, ".accounts.json.tmp123"), []byte(`{"go`), 0600))
+	writeData = func(f *os.File, d []byte) error {
+		_, err := f.Write(d)
+		return err
+	}
+	assert.Nil(t, WriteFile(path, []byte(`{"good":"again"}`), 0600))
+	d, err = ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"good":"again"}`, string(d))
+
+	// the missing directory fails without touching anything.
+	assert.NotNil(t, WriteFile(filepath.Join(dir, "missing", "a.json"), []byte("{}"), 0600))
+}
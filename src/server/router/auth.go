@@ -0,0 +1,113 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/server/engine"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// signed wraps the handler of mutating request, the request is rejected
+// if it's not signed by the account it claims to be from, or its nonce is
+// not greater than the last one of the account.
+func signed(ee engine.Exchange, handler sknet.HandlerFunc) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		if err := verifyRequest(ee, c); err != nil {
+			logger.Error("verify request failed: %v", err)
+			return c.Error(pp.MakeErrResWithCode(pp.ErrCode_UnAuthorized))
+		}
+
+		if err := checkNonce(ee, c); err != nil {
+			logger.Error("check nonce failed: %v", err)
+			res := pp.EmptyRes{Result: pp.MakeResult(pp.ErrCode_UnAuthorized, err.Error())}
+			return c.Error(&res)
+		}
+		return handler(c)
+	}
+}
+
+// signature is the middleware that rejects the request which is not signed
+// by the pubkey in request body, the pubkey needs not to be an account.
+func signature() sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		if _, err := verifySignature(c); err != nil {
+			logger.Error("verify request failed: %v", err)
+			return c.Error(pp.MakeErrResWithCode(pp.ErrCode_UnAuthorized))
+		}
+		return c.Next()
+	}
+}
+
+// verifyRequest checks the pubkey in request body belongs to a registered account,
+// and the signature of the raw request data is signed by the seckey of the pubkey.
+func verifyRequest(ee engine.Exchange, c *sknet.Context) error {
+	pubkey, err := verifySignature(c)
+	if err != nil {
+		return err
+	}
+
+	_, err = ee.GetAccount(pubkey)
+	return err
+}
+
+// checkNonce records the nonce in the request body as the last nonce of the account,
+// the request is replayed if the nonce has been used.
+func checkNonce(ee engine.Exchange, c *sknet.Context) error {
+	req := struct {
+		Pubkey string `json:"pubkey"`
+		Nonce  uint64 `json:"nonce"`
+	}{}
+	if err := json.Unmarshal(c.Raw, &req); err != nil {
+		return err
+	}
+
+	if req.Nonce == 0 {
+		return errors.New("nonce is required")
+	}
+	return ee.UseAccountNonce(req.Pubkey, req.Nonce)
+}
+
+// verifySignature checks the signature of the raw request data is signed by
+// the seckey of the pubkey in request body, and returns the pubkey.
+func verifySignature(c *sknet.Context) (pk string, err error) {
+	defer func() {
+		// the PubKeyFromHex may panic if the key is invalid.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	req := struct {
+		Pubkey string `json:"pubkey"`
+	}{}
+	if err := json.Unmarshal(c.Raw, &req); err != nil {
+		return "", err
+	}
+
+	if req.Pubkey == "" {
+		return "", errors.New("pubkey is required")
+	}
+
+	if c.Sig == "" {
+		return "", errors.New("signature is required")
+	}
+
+	pubkey, err := cipher.PubKeyFromHex(req.Pubkey)
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := cipher.SigFromHex(c.Sig)
+	if err != nil {
+		return "", err
+	}
+
+	if err := cipher.VerifySignature(pubkey, sig, cipher.SumSHA256(c.Raw)); err != nil {
+		return "", err
+	}
+	return req.Pubkey, nil
+}
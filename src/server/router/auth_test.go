@@ -0,0 +1,187 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/server/engine"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/stretchr/testify/assert"
+)
+
+// exchangeMock mocks the account lookup and nonces of exchange, other methods are not implemented.
+type exchangeMock struct {
+	engine.Exchange
+	accounts map[string]bool
+	nonces   map[string]uint64
+}
+
+func (m exchangeMock) GetAccount(id string) (account.Accounter, error) {
+	if !m.accounts[id] {
+		return nil, errors.New("account not found")
+	}
+	return nil, nil
+}
+
+func (m exchangeMock) UseAccountNonce(id string, nonce uint64) error {
+	if !m.accounts[id] {
+		return errors.New("account not found")
+	}
+	if nonce <= m.nonces[id] {
+		return account.ErrStaleNonce
+	}
+	m.nonces[id] = nonce
+	return nil
+}
+
+type responseMock struct {
+	res interface{}
+}
+
+func (r *responseMock) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (r *responseMock) SendJSON(data interface{}) error {
+	r.res = data
+	return nil
+}
+
+func makeSignedContext(t *testing.T, req interface{}, seckey cipher.SecKey) *sknet.Context {
+	d, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := pp.Sign(req, seckey.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &sknet.Context{Raw: d, Sig: sig, Resp: &responseMock{}}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	_, otherSeckey := cipher.GenerateKeyPair()
+	ee := exchangeMock{accounts: map[string]bool{pubkey.Hex(): true}}
+
+	req := pp.OrderReq{
+		Pubkey:   pp.PtrString(pubkey.Hex()),
+		CoinPair: pp.PtrString("bitcoin/skycoin"),
+		Type:     pp.PtrString("bid"),
+		Price:    pp.PtrUint64(100),
+		Amount:   pp.PtrUint64(10),
+	}
+
+	// valid signature.
+	assert.Nil(t, verifyRequest(ee, makeSignedContext(t, req, seckey)))
+
+	// forged signature, signed by other key.
+	assert.NotNil(t, verifyRequest(ee, makeSignedContext(t, req, otherSeckey)))
+
+	// the request is tampered after signed.
+	c := makeSignedContext(t, req, seckey)
+	req.Amount = pp.PtrUint64(1000)
+	c.Raw, _ = json.Marshal(req)
+	assert.NotNil(t, verifyRequest(ee, c))
+
+	// missing signature.
+	c = makeSignedContext(t, req, seckey)
+	c.Sig = ""
+	assert.NotNil(t, verifyRequest(ee, c))
+
+	// unregistered account.
+	otherPubkey := cipher.PubKeyFromSecKey(otherSeckey)
+	req.Pubkey = pp.PtrString(otherPubkey.Hex())
+	assert.NotNil(t, verifyRequest(ee, makeSignedContext(t, req, otherSeckey)))
+}
+
+func TestSigned(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	_, otherSeckey := cipher.GenerateKeyPair()
+	ee := exchangeMock{accounts: map[string]bool{pubkey.Hex(): true}, nonces: map[string]uint64{}}
+
+	var called bool
+	h := signed(ee, func(c *sknet.Context) error {
+		called = true
+		return nil
+	})
+
+	req := pp.CancelOrderReq{
+		Pubkey:   pp.PtrString(pubkey.Hex()),
+		CoinPair: pp.PtrString("bitcoin/skycoin"),
+		OrderId:  pp.PtrUint64(1),
+		Nonce:    pp.PtrUint64(1),
+	}
+
+	signedReq := makeSignedContext(t, req, seckey)
+	assert.Nil(t, h(signedReq))
+	assert.True(t, called)
+
+	// the replayed request is rejected.
+	called = false
+	c := &sknet.Context{Raw: signedReq.Raw, Sig: signedReq.Sig, Resp: &responseMock{}}
+	assert.Nil(t, h(c))
+	assert.False(t, called)
+	res := c.Resp.(*responseMock).res.(*pp.EmptyRes)
+	assert.Equal(t, int32(pp.ErrCode_UnAuthorized), res.Result.GetErrcode())
+
+	// the forged request is rejected before reaching the handler.
+	called = false
+	req.Nonce = pp.PtrUint64(2)
+	c = makeSignedContext(t, req, otherSeckey)
+	assert.Nil(t, h(c))
+	assert.False(t, called)
+	res = c.Resp.(*responseMock).res.(*pp.EmptyRes)
+	assert.Equal(t, int32(pp.ErrCode_UnAuthorized), res.Result.GetErrcode())
+}
+
+func TestCheckNonce(t *testing.T) {
+	ee := exchangeMock{accounts: map[string]bool{"a": true}, nonces: map[string]uint64{}}
+	check := func(id string, nonce uint64) error {
+		req := pp.OrderReq{Pubkey: pp.PtrString(id), CoinPair: pp.PtrString("bitcoin/skycoin")}
+		if nonce > 0 {
+			req.Nonce = pp.PtrUint64(nonce)
+		}
+		d, err := json.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return checkNonce(ee, &sknet.Context{Raw: d})
+	}
+
+	assert.Nil(t, check("a", 1))
+	// replayed.
+	assert.Equal(t, account.ErrStaleNonce, check("a", 1))
+	// gaps are allowed, but the nonce can't go back.
+	assert.Nil(t, check("a", 5))
+	assert.Equal(t, account.ErrStaleNonce, check("a", 3))
+	assert.Nil(t, check("a", 6))
+	// missing nonce.
+	assert.NotNil(t, check("a", 0))
+	// unknown account.
+	assert.NotNil(t, check("b", 10))
+}
+
+func TestSignatureMiddleware(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	_, otherSeckey := cipher.GenerateKeyPair()
+
+	// the admin needs not to be an account.
+	req := pp.AdminDeleteAccountReq{
+		Pubkey:    pp.PtrString(pubkey.Hex()),
+		AccountId: pp.PtrString("user"),
+	}
+
+	c := makeSignedContext(t, req, seckey)
+	assert.Nil(t, signature()(c))
+	assert.Nil(t, c.Resp.(*responseMock).res)
+
+	c = makeSignedContext(t, req, otherSeckey)
+	assert.Nil(t, signature()(c))
+	res := c.Resp.(*responseMock).res.(*pp.EmptyRes)
+	assert.Equal(t, int32(pp.ErrCode_UnAuthorized), res.Result.GetErrcode())
+}
@@ -6,19 +6,35 @@ import (
 	"github.com/skycoin/skycoin-exchange/src/sknet"
 )
 
-// New create sknet engine and register handlers.
-func New(ee engine.Exchange, quit chan bool) *sknet.Engine {
+// New create sknet engine and register handlers, the signed requests of each account
+// are limited by rl, nil rl means no limit.
+func New(ee engine.Exchange, rl *RateLimiter, quit chan bool) *sknet.Engine {
 	engine := sknet.New(ee.GetSecKey(), quit)
 	engine.Use(sknet.Logger())
 
 	engine.Register("/create/account", api.CreateAccount(ee))
-	engine.Register("/create/deposit_address", api.GetNewAddress(ee))
+	engine.Register("/create/deposit_address", signed(ee, limited(rl, api.GetNewAddress(ee))))
 	engine.Register("/get/account/balance", api.GetAccountBalance(ee))
+	engine.Register("/get/account/balances", api.GetAccountBalances(ee))
+	engine.Register("/get/account/orders", api.GetAccountOrders(ee))
+	engine.Register("/get/account/nonce", api.GetAccountNonce(ee))
 	engine.Register("/get/address/balance", api.GetAddrBalance(ee))
-	engine.Register("/withdrawl", api.Withdraw(ee))
-	engine.Register("/create/order", api.CreateOrder(ee))
+	engine.Register("/get/address/balance/detail", api.GetAddrDetailedBalance(ee))
+	engine.Register("/withdrawl", signed(ee, limited(rl, api.Withdraw(ee))))
+	engine.Register("/get/withdrawal", api.GetWithdrawal(ee))
+	engine.Register("/totp/enroll", signed(ee, limited(rl, api.EnrollTOTP(ee))))
+	engine.Register("/totp/confirm", signed(ee, limited(rl, api.ConfirmTOTP(ee))))
+	engine.Register("/create/order", signed(ee, limited(rl, api.CreateOrder(ee))))
+	engine.Register("/create/orders", signed(ee, limited(rl, api.CreateOrders(ee))))
+	engine.Register("/cancel/order", signed(ee, limited(rl, api.CancelOrder(ee))))
 	engine.Register("/get/coins", api.GetCoins(ee))
+	engine.Register("/get/coins/info", api.GetCoinsInfo(ee))
+	engine.Register("/health", api.Health(ee))
 	engine.Register("/get/orders", api.GetOrders(ee))
+	engine.Register("/get/order", api.GetOrder(ee))
+	engine.Register("/get/depth", api.GetDepth(ee))
+	engine.Register("/get/candles", api.GetCandles(ee))
+	engine.Register("/get/ticker", api.GetTicker(ee))
 
 	// utxos handler
 	engine.Register("/get/utxos", api.GetUtxos(ee))
@@ -30,8 +46,21 @@ func New(ee engine.Exchange, quit chan bool) *sknet.Engine {
 	engine.Register("/inject/tx", api.InjectTx(ee))
 	engine.Register("/get/tx", api.GetTx(ee))
 	engine.Register("/get/rawtx", api.GetRawTx(ee))
+	engine.Register("/get/address/txs", api.GetAddrTxs(ee))
 
 	engine.Register("/admin/update/credit", api.UpdateCredit(ee))
 
+	// admin handlers, the requests must be signed by admin.
+	admin := engine.Group("/admin", signature(), api.IsAdmin(ee))
+	admin.Register("/account/create", api.AdminCreateAccount(ee))
+	admin.Register("/account/delete", api.AdminDeleteAccount(ee))
+	admin.Register("/account/freeze", api.AdminFreezeAccount(ee))
+	admin.Register("/account/unfreeze", api.AdminUnfreezeAccount(ee))
+	admin.Register("/coinpair/add", api.AdminAddCoinPair(ee))
+	admin.Register("/coinpair/halt", api.AdminHaltPair(ee))
+	admin.Register("/coinpair/resume", api.AdminResumePair(ee))
+	admin.Register("/coinpair/priceband", api.AdminSetPriceBand(ee))
+	admin.Register("/utxo/stats", api.AdminGetUtxoStats(ee))
+
 	return engine
 }
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+)
+
+// RateLimiter limits the requests of each account with token bucket, every account
+// has a bucket of burst tokens which is refilled with rate tokens per second, and
+// each request takes one token.
+type RateLimiter struct {
+	rate      float64 // tokens refilled per second.
+	burst     float64 // capacity of the bucket.
+	mtx       sync.Mutex
+	buckets   map[string]*bucket // key: account id.
+	lastSweep time.Time
+	now       func() time.Time // for mocking the clock in tests.
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time // last time the tokens are refilled.
+}
+
+// NewRateLimiter creates the limiter allows rate requests per second and bursts of
+// burst requests for each account, returns nil which disables the limit if rate is 0.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of the account, returns false if it's empty.
+func (rl *RateLimiter) Allow(id string) bool {
+	if rl == nil {
+		return true
+	}
+
+	rl.mtx.Lock()
+	defer rl.mtx.Unlock()
+	now := rl.now()
+	rl.sweep(now)
+
+	b, ok := rl.buckets[id]
+	if !ok {
+		b = &bucket{tokens: rl.burst, last: now}
+		rl.buckets[id] = b
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * rl.rate
+		if b.tokens > rl.burst {
+			b.tokens = rl.burst
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sweep removes the buckets which are refilled to full, they are the same as the
+// new ones, so the buckets of the idle accounts won't pile up.
+func (rl *RateLimiter) sweep(now time.Time) {
+	full := time.Duration(rl.burst / rl.rate * float64(time.Second))
+	if now.Sub(rl.lastSweep) < full {
+		return
+	}
+	for id, b := range rl.buckets {
+		if now.Sub(b.last) >= full {
+			delete(rl.buckets, id)
+		}
+	}
+	rl.lastSweep = now
+}
+
+// limited wraps the handler of signed request, the request is rejected if the
+// account of pubkey in request body exceeds the rate limit.
+func limited(rl *RateLimiter, handler sknet.HandlerFunc) sknet.HandlerFunc {
+	return func(c *sknet.Context) error {
+		req := struct {
+			Pubkey string `json:"pubkey"`
+		}{}
+		if err := json.Unmarshal(c.Raw, &req); err != nil {
+			return c.Error(pp.MakeErrRes(err))
+		}
+
+		if !rl.Allow(req.Pubkey) {
+			logger.Error("account %s exceeds the rate limit", req.Pubkey)
+			return c.Error(pp.MakeErrResWithCode(pp.ErrCode_TooManyRequests))
+		}
+		return handler(c)
+	}
+}
@@ -0,0 +1,85 @@
+package router
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/sknet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rl := NewRateLimiter(2, 3)
+	rl.now = func() time.Time { return now }
+
+	// the burst is allowed, and the following requests are rejected.
+	for i := 0; i < 3; i++ {
+		assert.True(t, rl.Allow("a"))
+	}
+	assert.False(t, rl.Allow("a"))
+	assert.False(t, rl.Allow("a"))
+
+	// other accounts are not affected.
+	assert.True(t, rl.Allow("b"))
+
+	// one token is refilled after half a second.
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, rl.Allow("a"))
+	assert.False(t, rl.Allow("a"))
+
+	// the bucket is refilled to the burst at most.
+	now = now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		assert.True(t, rl.Allow("a"))
+	}
+	assert.False(t, rl.Allow("a"))
+
+	// the full buckets of idle accounts are removed.
+	now = now.Add(time.Minute)
+	assert.True(t, rl.Allow("c"))
+	assert.Equal(t, 1, len(rl.buckets))
+
+	// nil limiter allows all.
+	rl = NewRateLimiter(0, 3)
+	assert.Nil(t, rl)
+	assert.True(t, rl.Allow("a"))
+}
+
+func TestLimited(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rl := NewRateLimiter(1, 2)
+	rl.now = func() time.Time { return now }
+
+	var calls int
+	h := limited(rl, func(c *sknet.Context) error {
+		calls++
+		return nil
+	})
+
+	d, err := json.Marshal(pp.OrderReq{Pubkey: pp.PtrString("a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newContext := func() *sknet.Context {
+		return &sknet.Context{Raw: d, Resp: &responseMock{}}
+	}
+
+	assert.Nil(t, h(newContext()))
+	assert.Nil(t, h(newContext()))
+	assert.Equal(t, 2, calls)
+
+	// the request past the burst is rejected before reaching the handler.
+	c := newContext()
+	assert.Nil(t, h(c))
+	assert.Equal(t, 2, calls)
+	res := c.Resp.(*responseMock).res.(*pp.EmptyRes)
+	assert.Equal(t, int32(pp.ErrCode_TooManyRequests), res.Result.GetErrcode())
+
+	// recovers after the window.
+	now = now.Add(time.Second)
+	assert.Nil(t, h(newContext()))
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,207 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/websocket"
+	logging "github.com/op/go-logging"
+	"github.com/skycoin/skycoin-exchange/src/server/metrics"
+	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/skycoin/skycoin-exchange/src/server/trade"
+)
+
+var logger = logging.MustGetLogger("exchange.router")
+
+// Types of the stream events.
+const (
+	EventAdd    = "add"    // the order is added to the book.
+	EventCancel = "cancel" // the order is removed from the book.
+	EventFill   = "fill"   // the order is filled, its RestAmt is updated.
+	EventTrade  = "trade"  // a trade is executed.
+)
+
+var (
+	// StreamBufSize the number of events buffered for each client, the stalest
+	// events are dropped when the client can't keep up.
+	StreamBufSize = 64
+	// StreamWriteTm max time that will be allowed in writing one event to client.
+	StreamWriteTm = 10 * time.Second
+)
+
+// StreamEvent the order book delta or trade event pushed to the clients.
+type StreamEvent struct {
+	Type  string       `json:"type"`
+	Pair  string       `json:"pair"`
+	Order *order.Order `json:"order,omitempty"`
+	Trade *trade.Trade `json:"trade,omitempty"`
+}
+
+// Stream pushes the order book events and the trade tape of coin pairs to the subscribed websocket clients.
+type Stream struct {
+	subs      map[string]map[*subscriber]bool     // key: coin pair.
+	tape      map[string]map[*tapeSubscriber]bool // key: coin pair.
+	mtx       sync.RWMutex
+	upgrader  websocket.Upgrader
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+type subscriber struct {
+	events chan []byte
+}
+
+// NewStream creates the stream, clients can subscribe the coin pair by
+// connecting to the stream with `pair` query, like /stream?pair=bitcoin/skycoin,
+// and the trade tape of coin pairs by connecting to /trades, see ServeTape.
+func NewStream() *Stream {
+	return &Stream{
+		subs: make(map[string]map[*subscriber]bool),
+		tape: make(map[string]map[*tapeSubscriber]bool),
+		quit: make(chan struct{}),
+		upgrader: websocket.Upgrader{
+			// the market data is public.
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// Run starts the websocket server, returns after the stream is closed.
+func (s *Stream) Run(ip string, port int) {
+	srv := &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", ip, port),
+		Handler: s.mux(),
+	}
+
+	go func() {
+		<-s.quit
+		srv.Close()
+	}()
+
+	logger.Info("stream listening on %s", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Error("stream server stopped: %v", err)
+	}
+}
+
+// mux routes the stream, and the metrics of the server for prometheus to scrape.
+func (s *Stream) mux() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/stream", s)
+	mux.HandleFunc("/trades", s.ServeTape)
+	mux.Handle("/metrics", metrics.Handler())
+	return mux
+}
+
+// Close stops the websocket server and disconnects all clients.
+func (s *Stream) Close() {
+	s.closeOnce.Do(func() { close(s.quit) })
+}
+
+// Publish sends the event to all clients of the coin pair, it never blocks,
+// the stalest buffered event of slow client is dropped instead.
+func (s *Stream) Publish(ev StreamEvent) {
+	d, err := json.Marshal(ev)
+	if err != nil {
+		logger.Error("marshal stream event failed: %v", err)
+		return
+	}
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	for sub := range s.subs[ev.Pair] {
+		sub.push(d)
+	}
+}
+
+func (sub *subscriber) push(d []byte) {
+	for {
+		select {
+		case sub.events <- d:
+			return
+		default:
+			select {
+			case <-sub.events:
+			default:
+			}
+		}
+	}
+}
+
+// Subscribers returns the number of clients subscribing the coin pair.
+func (s *Stream) Subscribers(cp string) int {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return len(s.subs[cp])
+}
+
+func (s *Stream) subscribe(cp string) *subscriber {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	sub := &subscriber{events: make(chan []byte, StreamBufSize)}
+	if _, ok := s.subs[cp]; !ok {
+		s.subs[cp] = make(map[*subscriber]bool)
+	}
+	s.subs[cp][sub] = true
+	return sub
+}
+
+func (s *Stream) unsubscribe(cp string, sub *subscriber) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.subs[cp], sub)
+	if len(s.subs[cp]) == 0 {
+		delete(s.subs, cp)
+	}
+}
+
+// ServeHTTP upgrades the connection to websocket, and writes the events of
+// subscribed coin pair until the client disconnects.
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cp := r.URL.Query().Get("pair")
+	if cp == "" {
+		http.Error(w, "pair is required", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Error("upgrade stream connection failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	sub := s.subscribe(cp)
+	defer s.unsubscribe(cp, sub)
+	metrics.StreamClients.Inc()
+	defer metrics.StreamClients.Dec()
+
+	// the client sends nothing, reading is only used for detecting the disconnection.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-s.quit:
+			return
+		case d := <-sub.events:
+			conn.SetWriteDeadline(time.Now().Add(StreamWriteTm))
+			if err := conn.WriteMessage(websocket.TextMessage, d); err != nil {
+				logger.Debug("write stream event failed: %v", err)
+				return
+			}
+		}
+	}
+}
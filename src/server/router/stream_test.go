@@ -0,0 +1,145 @@
+package router
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/websocket"
+	"github.com/skycoin/skycoin-exchange/src/server/metrics"
+	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/stretchr/testify/assert"
+)
+
+func dialStream(t *testing.T, srv *httptest.Server, cp string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/stream?pair=" + cp
+	conn, _, err := (&websocket.Dialer{}).Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func waitSubscribers(s *Stream, cp string, n int) bool {
+	for i := 0; i < 100; i++ {
+		if s.Subscribers(cp) == n {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestStream(t *testing.T) {
+	s := NewStream()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	conn := dialStream(t, srv, "bitcoin/skycoin")
+	assert.True(t, waitSubscribers(s, "bitcoin/skycoin", 1))
+
+	// events of other pairs are not received.
+	s.Publish(StreamEvent{Type: EventAdd, Pair: "bitcoin/litecoin", Order: &order.Order{ID: 1}})
+	s.Publish(StreamEvent{Type: EventAdd, Pair: "bitcoin/skycoin", Order: &order.Order{ID: 2}})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, d, err := conn.ReadMessage()
+	assert.Nil(t, err)
+	ev := StreamEvent{}
+	assert.Nil(t, json.Unmarshal(d, &ev))
+	assert.Equal(t, EventAdd, ev.Type)
+	assert.Equal(t, uint64(2), ev.Order.ID)
+
+	// the client is unsubscribed after disconnected.
+	conn.Close()
+	assert.True(t, waitSubscribers(s, "bitcoin/skycoin", 0))
+
+	// pair is required.
+	res, err := http.Get(srv.URL + "/stream")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestStreamSlowConsumer(t *testing.T) {
+	s := NewStream()
+	sub := s.subscribe("bitcoin/skycoin")
+
+	// nobody reads the events, publishing must not block.
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < StreamBufSize*2; i++ {
+			s.Publish(StreamEvent{Type: EventFill, Pair: "bitcoin/skycoin", Order: &order.Order{ID: uint64(i)}})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish is blocked by slow consumer")
+	}
+
+	// the stalest events are dropped.
+	assert.Equal(t, StreamBufSize, len(sub.events))
+	ev := StreamEvent{}
+	assert.Nil(t, json.Unmarshal(<-sub.events, &ev))
+	assert.Equal(t, uint64(StreamBufSize), ev.Order.ID)
+}
+
+// fakePool mocks the utxo pool of bitcoin utxo manager.
+type fakePool struct{}
+
+func (fakePool) PoolSize() (int, int) { return 10, 3 }
+func (fakePool) PendingDeposits() int { return 2 }
+
+func scrape(t *testing.T, url string) string {
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	d, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(d)
+}
+
+func TestMetrics(t *testing.T) {
+	s := NewStream()
+	srv := httptest.NewServer(s.mux())
+	defer srv.Close()
+
+	metrics.SetUtxoPool("bitcoin", fakePool{})
+	metrics.OrdersPlaced.WithLabelValues("bitcoin/skycoin", "bid").Inc()
+	metrics.OrdersMatched.WithLabelValues("bitcoin/skycoin", "bid").Inc()
+
+	conn := dialStream(t, srv, "bitcoin/skycoin")
+	defer conn.Close()
+	assert.True(t, waitSubscribers(s, "bitcoin/skycoin", 1))
+
+	var body string
+	for i := 0; i < 100; i++ {
+		body = scrape(t, srv.URL+"/metrics")
+		if strings.Contains(body, "exchange_stream_clients 1") {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, m := range []string{
+		`exchange_orders_placed_total{pair="bitcoin/skycoin",type="bid"}`,
+		`exchange_orders_matched_total{pair="bitcoin/skycoin",type="bid"}`,
+		`exchange_stream_clients 1`,
+		`exchange_utxo_pool_size{coin="bitcoin"} 10`,
+		`exchange_utxo_pool_available{coin="bitcoin"} 3`,
+		`exchange_pending_deposits{coin="bitcoin"} 2`,
+		`go_goroutines`,
+	} {
+		assert.True(t, strings.Contains(body, m), m)
+	}
+}
@@ -0,0 +1,184 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/websocket"
+	"github.com/skycoin/skycoin-exchange/src/server/metrics"
+)
+
+// EventDropped the notice sent to the trade tape client whose trades are dropped.
+const EventDropped = "dropped"
+
+// TapeTrade the executed trade pushed by the trade tape.
+type TapeTrade struct {
+	Type   string `json:"type"` // always EventTrade.
+	Pair   string `json:"pair"`
+	Price  uint64 `json:"price"`
+	Amount uint64 `json:"amount"`
+	Side   string `json:"side"` // type of the taker order, bid or ask.
+	Time   int64  `json:"time"` // unix time of the execution.
+}
+
+// TapeNotice tells the client the number of trades dropped since the last notice, because
+// it can't keep up with the tape.
+type TapeNotice struct {
+	Type    string `json:"type"` // always EventDropped.
+	Dropped uint64 `json:"dropped"`
+}
+
+// tapeSubscriber the trade tape client, the new trades are dropped once its buffer is full,
+// and it's notified of the number of the dropped ones after the buffered trades are written.
+type tapeSubscriber struct {
+	events  chan []byte
+	notify  chan struct{}
+	dropped uint64 // accessed atomically.
+}
+
+func (sub *tapeSubscriber) push(d []byte) {
+	select {
+	case sub.events <- d:
+	default:
+		atomic.AddUint64(&sub.dropped, 1)
+		select {
+		case sub.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// PublishTrade sends the trade to all trade tape clients of its coin pair, it never blocks.
+func (s *Stream) PublishTrade(t TapeTrade) {
+	t.Type = EventTrade
+	d, err := json.Marshal(t)
+	if err != nil {
+		logger.Error("marshal tape trade failed: %v", err)
+		return
+	}
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	for sub := range s.tape[t.Pair] {
+		sub.push(d)
+	}
+}
+
+// TapeSubscribers returns the number of trade tape clients subscribing the coin pair.
+func (s *Stream) TapeSubscribers(cp string) int {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return len(s.tape[cp])
+}
+
+func (s *Stream) subscribeTape(pairs []string) *tapeSubscriber {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	sub := &tapeSubscriber{
+		events: make(chan []byte, StreamBufSize),
+		notify: make(chan struct{}, 1),
+	}
+	for _, cp := range pairs {
+		if _, ok := s.tape[cp]; !ok {
+			s.tape[cp] = make(map[*tapeSubscriber]bool)
+		}
+		s.tape[cp][sub] = true
+	}
+	return sub
+}
+
+func (s *Stream) unsubscribeTape(pairs []string, sub *tapeSubscriber) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, cp := range pairs {
+		delete(s.tape[cp], sub)
+		if len(s.tape[cp]) == 0 {
+			delete(s.tape, cp)
+		}
+	}
+}
+
+// ServeTape upgrades the connection to websocket, and writes the trades of the subscribed
+// coin pairs until the client disconnects, the pairs are joined with `,` in the `pairs`
+// query, like /trades?pairs=bitcoin/skycoin,skycoin/mzcoin.
+func (s *Stream) ServeTape(w http.ResponseWriter, r *http.Request) {
+	pairs := []string{}
+	seen := make(map[string]bool)
+	for _, cp := range strings.Split(r.URL.Query().Get("pairs"), ",") {
+		if cp = strings.TrimSpace(cp); cp != "" && !seen[cp] {
+			seen[cp] = true
+			pairs = append(pairs, cp)
+		}
+	}
+	if len(pairs) == 0 {
+		http.Error(w, "pairs is required", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Error("upgrade tape connection failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	sub := s.subscribeTape(pairs)
+	defer s.unsubscribeTape(pairs, sub)
+	metrics.StreamClients.Inc()
+	defer metrics.StreamClients.Dec()
+
+	// the client sends nothing, reading is only used for detecting the disconnection.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(d []byte) bool {
+		conn.SetWriteDeadline(time.Now().Add(StreamWriteTm))
+		if err := conn.WriteMessage(websocket.TextMessage, d); err != nil {
+			logger.Debug("write tape trade failed: %v", err)
+			return false
+		}
+		return true
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-s.quit:
+			return
+		case d := <-sub.events:
+			if !write(d) {
+				return
+			}
+		case <-sub.notify:
+			// the dropped trades are newer than the buffered ones.
+			for n := len(sub.events); n > 0; n-- {
+				if !write(<-sub.events) {
+					return
+				}
+			}
+			n := atomic.SwapUint64(&sub.dropped, 0)
+			if n == 0 {
+				continue
+			}
+			d, err := json.Marshal(TapeNotice{Type: EventDropped, Dropped: n})
+			if err != nil {
+				logger.Error("marshal tape notice failed: %v", err)
+				return
+			}
+			if !write(d) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func dialTape(t *testing.T, srv *httptest.Server, pairs string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/trades?pairs=" + pairs
+	conn, _, err := (&websocket.Dialer{}).Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func readTape(t *testing.T, conn *websocket.Conn, v interface{}) {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, d, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(d, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTape(t *testing.T) {
+	s := NewStream()
+	srv := httptest.NewServer(s.mux())
+	defer srv.Close()
+
+	conn := dialTape(t, srv, "bitcoin/skycoin,skycoin/mzcoin")
+	for i := 0; i < 100 && s.TapeSubscribers("skycoin/mzcoin") == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 1, s.TapeSubscribers("bitcoin/skycoin"))
+
+	// the trades of the subscribed pairs are received in order.
+	s.PublishTrade(TapeTrade{Pair: "bitcoin/litecoin", Price: 1, Amount: 1, Side: "bid", Time: 1})
+	s.PublishTrade(TapeTrade{Pair: "bitcoin/skycoin", Price: 100, Amount: 2, Side: "ask", Time: 2})
+	s.PublishTrade(TapeTrade{Pair: "skycoin/mzcoin", Price: 3, Amount: 4, Side: "bid", Time: 3})
+
+	tr := TapeTrade{}
+	readTape(t, conn, &tr)
+	assert.Equal(t, TapeTrade{Type: EventTrade, Pair: "bitcoin/skycoin", Price: 100, Amount: 2, Side: "ask", Time: 2}, tr)
+	readTape(t, conn, &tr)
+	assert.Equal(t, "skycoin/mzcoin", tr.Pair)
+
+	// the client is unsubscribed from all pairs after disconnected.
+	conn.Close()
+	for i := 0; i < 100 && s.TapeSubscribers("bitcoin/skycoin") > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, s.TapeSubscribers("bitcoin/skycoin"))
+	assert.Equal(t, 0, s.TapeSubscribers("skycoin/mzcoin"))
+
+	// pairs is required.
+	res, err := http.Get(srv.URL + "/trades?pairs=,")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestTapeSlowConsumer(t *testing.T) {
+	s := NewStream()
+	sub := s.subscribeTape([]string{"bitcoin/skycoin"})
+
+	// nobody reads the trades, publishing must not block.
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < StreamBufSize+10; i++ {
+			s.PublishTrade(TapeTrade{Pair: "bitcoin/skycoin", Price: uint64(i), Amount: 1})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish is blocked by slow consumer")
+	}
+
+	// the newest trades are dropped and counted.
+	assert.Equal(t, StreamBufSize, len(sub.events))
+	assert.Equal(t, uint64(10), sub.dropped)
+	assert.Equal(t, 1, len(sub.notify))
+	tr := TapeTrade{}
+	assert.Nil(t, json.Unmarshal(<-sub.events, &tr))
+	assert.Equal(t, uint64(0), tr.Price)
+}
@@ -1,9 +1,11 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,11 +15,16 @@ import (
 	logging "github.com/op/go-logging"
 	"github.com/skycoin/skycoin-exchange/src/coin"
 	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	litecoin "github.com/skycoin/skycoin-exchange/src/coin/litecoin"
+	mzcoin "github.com/skycoin/skycoin-exchange/src/coin/mzcoin"
 	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
 	"github.com/skycoin/skycoin-exchange/src/server/account"
 	"github.com/skycoin/skycoin-exchange/src/server/engine"
+	"github.com/skycoin/skycoin-exchange/src/server/metrics"
 	"github.com/skycoin/skycoin-exchange/src/server/order"
 	"github.com/skycoin/skycoin-exchange/src/server/router"
+	"github.com/skycoin/skycoin-exchange/src/server/trade"
+	"github.com/skycoin/skycoin-exchange/src/sklog"
 	"github.com/skycoin/skycoin/src/util"
 )
 
@@ -27,19 +34,82 @@ var logger = logging.MustGetLogger("exchange.server")
 type Config struct {
 	Server        string            // api server ip
 	Port          int               // api port
-	BtcFee        int               // btc transaction fee
-	DataDir       string            // data directory
-	Seed          string            // seed
+	StreamPort    int               // websocket port of order book stream, 0 disables the stream
+	BtcFee        int               // btc transaction fee, used by withdrawal if BtcFeeRate is 0
+	BtcFeeRate    uint64            // default btc withdrawal fee rate in satoshis per vbyte
+	DataDir       string            // data directory, relative to the home directory if it's not absolute
+	Seed          string            // seed of the default wallet
+	Seeds         map[string]string // seeds of extra wallets, key wallet name, value seed
 	Seckey        string            // server's private key
 	UtxoPoolSize  int               // utxo pool size.
 	Admins        string            // admins joined with `,`
+	FeeRate       uint64            // taker fee rate in basis points
+	FeeAccount    string            // id of the account which receives the trade fees
 	NodeAddresses map[string]string // node address map
-	HttpProf      bool
+	// MinConfirmations min confirmations of deposits before they are credited
+	// and spendable, key coin type, only bitcoin is supported now.
+	MinConfirmations map[string]uint64
+	// MaxOrders max open orders of each coin pair, 0 means no limit, OrderFullPolicy
+	// decides what happens to the new order when the book is full, "reject" or "evict_worst".
+	MaxOrders       int
+	OrderFullPolicy string
+	// PriceBand max distance of the limit price from the last trade price of each coin pair
+	// in basis points, 0 keeps the band saved with the books.
+	PriceBand uint64
+	// OrderSaveInterval interval of saving the changed order books, the changes in
+	// one interval are written once, 0 uses order.DefaultSaveInterval.
+	OrderSaveInterval time.Duration
+	// OrderSnapshotInterval interval of the full snapshots of the order books, which restore
+	// the corrupted book files on start, 0 disables them. OrderSnapshotKeep snapshots are kept
+	// for each book, 0 uses order.DefaultSnapshotKeep.
+	OrderSnapshotInterval time.Duration
+	OrderSnapshotKeep     int
+	// BroadcastRetries max retries of the withdrawal broadcast failed transiently, the
+	// wait before each retry starts from BroadcastBackoff and doubles every time.
+	BroadcastRetries int
+	BroadcastBackoff time.Duration
+	// BroadcastAPIs the insight apis the bitcoin and litecoin transactions are broadcast through,
+	// key coin type, tried in order until one accepts the transaction, the coin not set uses the
+	// default api of its network.
+	BroadcastAPIs map[string][]string
+	// RateLimit requests per second of each account to the signed apis,
+	// RateBurst is the max requests of a burst, 0 RateLimit disables the limit.
+	RateLimit float64
+	RateBurst int
+	// LogFormat format of the logs, "text" or "json", the json logs are printed
+	// as one object per line with the structured fields.
+	LogFormat string
+	// DepositAddrTTL the unfunded deposit address handed out longer than it is reclaimed
+	// and handed out again, 0 disables reclaiming. It must be longer than the time the
+	// deposits take to be confirmed.
+	DepositAddrTTL time.Duration
+	// Testnet runs the bitcoin and litecoin gateways on their testnets, the skycoin
+	// addresses are the same on testnet, set its node address in NodeAddresses instead.
+	Testnet bool
+	// FeeSweepInterval interval of checking the fee account, its balance exceeding the threshold in
+	// FeeSweepThresholds is withdrawn to the cold address in FeeSweepAddrs, both are keyed by coin
+	// type, 0 disables the sweeping.
+	FeeSweepInterval   time.Duration
+	FeeSweepAddrs      map[string]string
+	FeeSweepThresholds map[string]uint64
+	// WithdrawalAddrCooldown the time a newly whitelisted withdrawal address must wait before it
+	// can be withdrawn to, it only applies to the accounts whose whitelist is enabled.
+	WithdrawalAddrCooldown time.Duration
+	// TOTPSkew number of time steps before and after the current one whose totp codes are accepted.
+	TOTPSkew int
+	HttpProf bool
 }
 
 // NewConfig creates config instance and init nodeaddresses map.
 func NewConfig() *Config {
-	return &Config{NodeAddresses: make(map[string]string)}
+	return &Config{
+		NodeAddresses:      make(map[string]string),
+		Seeds:              make(map[string]string),
+		MinConfirmations:   make(map[string]uint64),
+		FeeSweepAddrs:      make(map[string]string),
+		FeeSweepThresholds: make(map[string]uint64),
+		BroadcastAPIs:      make(map[string][]string),
+	}
 }
 
 // ExchangeServer provides services like account system, order book, api for differenct coins, etc.
@@ -47,16 +117,39 @@ type ExchangeServer struct {
 	account.Manager
 	btcum         bitcoin.UtxoManager
 	skyum         skycoin.UtxoManager
+	ltcum         litecoin.UtxoManager
 	orderManager  *order.Manager
+	tradeLog      *trade.TradeLog
+	stream        *router.Stream
 	cfg           Config
 	wallets       wallets
-	wltMtx        sync.RWMutex                // mutex for protecting the wallet.
-	orderHandlers map[string]chan order.Order // order handlers, for handleing bid and ask.
+	wltMtx        sync.RWMutex               // mutex for protecting the wallet.
+	orderHandlers map[string]chan order.Fill // order handlers, for handleing the fills of bid and ask.
+	depositAddrs  depositAddrBook            // deposit addresses handed out by GetDepositAddress.
+	depositMtx    sync.Mutex                 // mutex for handing out one deposit address at a time.
 	coins         map[string]coin.Gateway
+	coinsMtx      sync.RWMutex      // mutex for protecting the coins.
+	admins        map[string]bool   // admin pubkeys parsed from Config.Admins.
+	withdrawKeys  map[string]bool   // idempotency keys of the withdrawals in progress, key: account id and key joined with `:`.
+	withdrawMtx   sync.Mutex        // mutex for protecting the withdrawKeys.
+	closing       chan bool         // closed by Shutdown, for stopping the goroutines started by Run.
+	running       bool              // set by Run, the order handlers of the coin pairs added later are started immediately.
+	runMtx        sync.Mutex        // mutex for ordering the start and shutdown of the goroutines, and protecting the orderHandlers.
+	feeSweeps     map[string]string // txids of the unconfirmed fee sweeps, key: coin type.
+	feeSweepMtx   sync.Mutex        // mutex for protecting the feeSweeps.
+	validators    order.Pipeline    // custom order validators added by AddOrderValidator.
+	validatorMtx  sync.RWMutex      // mutex for protecting the validators.
+	wg            sync.WaitGroup    // waits the goroutines started by Run.
 }
 
 // New create new server
 func New(cfg *Config) engine.Exchange {
+	// switch the networks before any address is made.
+	bitcoin.SetTestnet(cfg.Testnet)
+	litecoin.SetTestnet(cfg.Testnet)
+	bitcoin.BroadcastAPIs = cfg.BroadcastAPIs[bitcoin.Type]
+	litecoin.BroadcastAPIs = cfg.BroadcastAPIs[litecoin.Type]
+
 	// init the data dir
 	path := initDataDir(cfg.DataDir)
 
@@ -82,9 +175,12 @@ func New(cfg *Config) engine.Exchange {
 		}
 	}
 
-	wltItems := []walletItem{
-		{bitcoin.Type, cfg.Seed},
-		{skycoin.Type, cfg.Seed},
+	var wltItems []walletItem
+	for _, tp := range []string{bitcoin.Type, skycoin.Type, litecoin.Type} {
+		wltItems = append(wltItems, walletItem{tp, DefaultWallet, cfg.Seed})
+		for name, seed := range cfg.Seeds {
+			wltItems = append(wltItems, walletItem{tp, name, seed})
+		}
 	}
 
 	// init wallets in server.
@@ -93,20 +189,35 @@ func New(cfg *Config) engine.Exchange {
 		panic(err)
 	}
 
-	// create bitcoin utxo manager
-	btcWatchAddrs, err := wlts.GetAddresses(bitcoin.Type)
+	// create bitcoin utxo manager, only the utxos of default wallet are spent by the server.
+	btcWatchAddrs, err := wlts.GetAddresses(bitcoin.Type, DefaultWallet)
 	if err != nil {
 		panic(err)
 	}
 	btcum := bitcoin.NewUtxoManager(cfg.UtxoPoolSize, btcWatchAddrs)
+	btcum.SetMinConfirmations(cfg.MinConfirmations[bitcoin.Type])
 
 	// create skycoin utxo manager
-	skyWatchAddrs, err := wlts.GetAddresses(skycoin.Type)
+	skyWatchAddrs, err := wlts.GetAddresses(skycoin.Type, DefaultWallet)
 	if err != nil {
 		panic(err)
 	}
 	skyum := skycoin.NewUtxoManager(cfg.NodeAddresses[skycoin.Type], cfg.UtxoPoolSize, skyWatchAddrs)
 
+	// create litecoin utxo manager
+	ltcWatchAddrs, err := wlts.GetAddresses(litecoin.Type, DefaultWallet)
+	if err != nil {
+		panic(err)
+	}
+	ltcum := litecoin.NewUtxoManager(cfg.UtxoPoolSize, ltcWatchAddrs)
+	ltcum.SetMinConfirmations(cfg.MinConfirmations[litecoin.Type])
+
+	// open the trade log.
+	tradeLog, err := trade.NewTradeLog(filepath.Join(path, "orderbook", "trades.log"))
+	if err != nil {
+		panic(err)
+	}
+
 	// load or create order books.
 	var orderManager *order.Manager
 	orderManager, err = order.LoadManager()
@@ -119,24 +230,190 @@ func New(cfg *Config) engine.Exchange {
 		}
 	}
 
+	// the loaded books may be corrupted, the problems are logged for the admin to fix.
+	if err := orderManager.Verify(func(aid, ct string) (uint64, error) {
+		a, err := acntMgr.GetAccount(aid)
+		if err != nil {
+			return 0, err
+		}
+		return a.GetReservedBalance(ct), nil
+	}); err != nil {
+		if verr, ok := err.(*order.VerifyError); ok {
+			for _, p := range verr.Problems {
+				logger.Warning("order book: %s", p)
+			}
+		} else {
+			logger.Warning(err.Error())
+		}
+	}
+
+	if err := orderManager.SetFeeRate(cfg.FeeRate); err != nil {
+		panic(err)
+	}
+
+	// the configured limit overrides the one saved with the books.
+	fullPolicy, err := order.FullPolicyFromStr(cfg.OrderFullPolicy)
+	if err != nil {
+		panic(err)
+	}
+	if cfg.MaxOrders > 0 {
+		for _, cp := range orderManager.Pairs() {
+			if err := orderManager.SetMaxOrders(cp, cfg.MaxOrders, fullPolicy); err != nil {
+				panic(err)
+			}
+		}
+	}
+	if cfg.PriceBand > 0 {
+		for _, cp := range orderManager.Pairs() {
+			if err := orderManager.SetPriceBand(cp, cfg.PriceBand); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	// the fee account is created if not exist.
+	if cfg.FeeRate > 0 {
+		if cfg.FeeAccount == "" {
+			panic("fee account is required when fee rate is set")
+		}
+		if _, err := acntMgr.GetAccount(cfg.FeeAccount); err != nil {
+			if _, err := acntMgr.CreateAccountWithPubkey(cfg.FeeAccount); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	if cfg.FeeSweepInterval > 0 {
+		if cfg.FeeAccount == "" {
+			panic("fee account is required when fee sweep is enabled")
+		}
+		for ct, addr := range cfg.FeeSweepAddrs {
+			if err := validateWithdrawAddr(ct, addr); err != nil {
+				panic(fmt.Sprintf("fee sweep address: %v", err))
+			}
+		}
+	}
+
 	s := &ExchangeServer{
-		cfg:          *cfg,
-		wallets:      wlts,
-		Manager:      acntMgr,
-		btcum:        btcum,
-		skyum:        skyum,
-		orderManager: orderManager,
-		coins:        make(map[string]coin.Gateway),
-		orderHandlers: map[string]chan order.Order{
-			"bitcoin/skycoin": make(chan order.Order, 100),
-		},
+		cfg:           *cfg,
+		wallets:       wlts,
+		Manager:       acntMgr,
+		btcum:         btcum,
+		skyum:         skyum,
+		ltcum:         ltcum,
+		orderManager:  orderManager,
+		tradeLog:      tradeLog,
+		stream:        router.NewStream(),
+		coins:         make(map[string]coin.Gateway),
+		admins:        parseAdmins(cfg.Admins),
+		closing:       make(chan bool),
+		orderHandlers: make(map[string]chan order.Fill),
+	}
+
+	// the coin pairs added at runtime are loaded with the books.
+	for _, cp := range orderManager.Pairs() {
+		s.orderHandlers[cp] = make(chan order.Fill, 100)
 	}
 
+	s.setDepositHandlers()
+	orderManager.SetStopHandler(s.activateStop)
+	orderManager.SetTradeLog(tradeLog)
+	if cfg.OrderSaveInterval > 0 {
+		orderManager.SetSaveInterval(cfg.OrderSaveInterval)
+	}
+	orderManager.SetSnapshotInterval(cfg.OrderSnapshotInterval, cfg.OrderSnapshotKeep)
+
+	// the utxo pools are collected when the metrics are scraped.
+	metrics.SetUtxoPool(bitcoin.Type, btcum)
+	metrics.SetUtxoPool(skycoin.Type, skyum)
+	metrics.SetUtxoPool(litecoin.Type, ltcum)
 	return s
 }
 
-// BindCoins registers coins
+// setDepositHandlers credits the new utxos found by the utxo managers to the accounts
+// owning the deposit addresses, the bitcoin and litecoin deposits orphaned by reorg are reversed.
+func (self *ExchangeServer) setDepositHandlers() {
+	self.btcum.SetDepositHandler(func(u bitcoin.Utxo) {
+		id := fmt.Sprintf("%s:%d", u.GetTxid(), u.GetVout())
+		self.creditDeposit(bitcoin.Type, u.GetAddress(), id, u.GetAmount())
+	})
+
+	self.btcum.SetReorgHandler(func(u bitcoin.Utxo) {
+		id := fmt.Sprintf("%s:%d", u.GetTxid(), u.GetVout())
+		self.reverseDeposit(bitcoin.Type, u.GetAddress(), id, u.GetAmount())
+	})
+
+	self.skyum.SetDepositHandler(func(u skycoin.Utxo) {
+		self.creditDeposit(skycoin.Type, u.GetAddress(), u.GetHash(), u.GetCoins())
+	})
+
+	self.ltcum.SetDepositHandler(func(u litecoin.Utxo) {
+		id := fmt.Sprintf("%s:%d", u.GetTxid(), u.GetVout())
+		self.creditDeposit(litecoin.Type, u.GetAddress(), id, u.GetAmount())
+	})
+
+	self.ltcum.SetReorgHandler(func(u litecoin.Utxo) {
+		id := fmt.Sprintf("%s:%d", u.GetTxid(), u.GetVout())
+		self.reverseDeposit(litecoin.Type, u.GetAddress(), id, u.GetAmount())
+	})
+}
+
+// creditDeposit credits the confirmed deposit of utxo id to the account owning the address,
+// the utxos of addresses not belonging to any account, like the change addresses, are ignored.
+func (self *ExchangeServer) creditDeposit(ct, addr, id string, amt uint64) {
+	a, err := self.GetAccountByAddress(ct, addr)
+	if err != nil {
+		return
+	}
+	self.depositAddrs.markFunded(ct, addr)
+
+	if err := a.CreditDeposit(ct, id, amt); err != nil {
+		if err != account.ErrDepositCredited {
+			logger.Error("credit %s deposit %s failed: %v", ct, id, err)
+		}
+		return
+	}
+
+	logger.Info("account %s credited %d %s by deposit %s", a.GetID(), amt, ct, id)
+	if err := self.SaveAccount(); err != nil {
+		logger.Error("save account failed: %v", err)
+	}
+}
+
+// reverseDeposit debits the credited deposit of utxo id orphaned by reorg from the account owning
+// the address. If the coins are already spent or reserved, the rest can't be debited, the account
+// is frozen for the admin to settle it.
+func (self *ExchangeServer) reverseDeposit(ct, addr, id string, amt uint64) {
+	a, err := self.GetAccountByAddress(ct, addr)
+	if err != nil {
+		return
+	}
+
+	debit, err := a.ReverseDeposit(ct, id, amt)
+	if err != nil {
+		if err != account.ErrDepositNotCredited {
+			logger.Error("reverse %s deposit %s failed: %v", ct, id, err)
+		}
+		return
+	}
+
+	logger.Info("account %s debited %d %s by orphaned deposit %s", a.GetID(), debit, ct, id)
+	if debit < amt {
+		logger.Error("account %s can't cover %d %s of orphaned deposit %s, freeze it", a.GetID(), amt-debit, ct, id)
+		if err := self.SetFrozen(a.GetID(), true); err != nil {
+			logger.Error("freeze account %s failed: %v", a.GetID(), err)
+		}
+	}
+
+	if err := self.SaveAccount(); err != nil {
+		logger.Error("save account failed: %v", err)
+	}
+}
+
+// BindCoins registers coins, it's safe to call while the coins are being read.
 func (serv *ExchangeServer) BindCoins(cs ...coin.Gateway) error {
+	serv.coinsMtx.Lock()
+	defer serv.coinsMtx.Unlock()
 	for _, c := range cs {
 		if _, exist := serv.coins[c.Type()]; exist {
 			return fmt.Errorf("%s coin already registered", c.Type())
@@ -147,8 +424,16 @@ func (serv *ExchangeServer) BindCoins(cs ...coin.Gateway) error {
 	return nil
 }
 
-// Run start the exchange server.
+// Run start the exchange server, it blocks until the server is shut down.
 func (self *ExchangeServer) Run() {
+	self.runMtx.Lock()
+	select {
+	case <-self.closing:
+		self.runMtx.Unlock()
+		return
+	default:
+	}
+
 	logger.Info("server started %s:%d", self.cfg.Server, self.cfg.Port)
 	// register coins
 	// coin.RegisterGateway(coin.Bitcoin, &bitcoin.GatewayIns)
@@ -162,31 +447,85 @@ func (self *ExchangeServer) Run() {
 	}
 
 	// start the utxo manager
-	c := make(chan bool)
-	go self.btcum.Start(c)
-	go self.skyum.Start(c)
+	c := self.closing
+	self.goWait(func() { self.btcum.Start(c) })
+	self.goWait(func() { self.skyum.Start(c) })
+	self.goWait(func() { self.ltcum.Start(c) })
 
-	go self.orderManager.Start(1*time.Second, c)
+	self.goWait(func() { self.orderManager.Start(1*time.Second, c) })
 	self.handleOrders(c)
+	self.running = true
+
+	if self.cfg.FeeSweepInterval > 0 {
+		self.goWait(func() { self.runFeeSweeper(c) })
+	}
+
+	// start the order book stream.
+	if self.cfg.StreamPort > 0 {
+		self.goWait(func() { self.stream.Run(self.cfg.Server, self.cfg.StreamPort) })
+	}
 
 	// start the api server.
 	// r := NewRouter(self)
-	r := router.New(self, c)
+	r := router.New(self, router.NewRateLimiter(self.cfg.RateLimit, self.cfg.RateBurst), c)
+	self.runMtx.Unlock()
 	r.Run(self.cfg.Server, self.cfg.Port)
 }
 
+// Shutdown stops the goroutines started by Run, and saves the accounts and order books,
+// it returns the error of ctx if the goroutines are not stopped before ctx is done.
+func (self *ExchangeServer) Shutdown(ctx context.Context) error {
+	self.runMtx.Lock()
+	select {
+	case <-self.closing:
+	default:
+		close(self.closing)
+	}
+	self.runMtx.Unlock()
+
+	if self.stream != nil {
+		self.stream.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		self.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if err := self.SaveAccount(); err != nil {
+		return err
+	}
+	return self.orderManager.Save()
+}
+
+// goWait runs fn in a new goroutine, which will be waited by Shutdown.
+func (self *ExchangeServer) goWait(fn func()) {
+	self.wg.Add(1)
+	go func() {
+		defer self.wg.Done()
+		fn()
+	}()
+}
+
 // GetBtcFee get transaction fee of bitcoin.
-func (self ExchangeServer) GetBtcFee() uint64 {
+func (self *ExchangeServer) GetBtcFee() uint64 {
 	return uint64(self.cfg.BtcFee)
 }
 
-func (self ExchangeServer) GetSecKey() string {
+func (self *ExchangeServer) GetSecKey() string {
 	return self.cfg.Seckey
 }
 
 // GetPrivKey get the private key of specific address.
-func (self ExchangeServer) GetAddrPrivKey(cp, addr string) (string, error) {
-	_, key, err := self.wallets.GetKeypair(cp, addr)
+func (self *ExchangeServer) GetAddrPrivKey(cp, addr string) (string, error) {
+	_, key, err := self.wallets.GetKeypair(cp, "", addr)
 	if err != nil {
 		return "", err
 	}
@@ -194,36 +533,67 @@ func (self ExchangeServer) GetAddrPrivKey(cp, addr string) (string, error) {
 	return key, nil
 }
 
-// GetNewAddress create new address of specific coin type.
-func (self *ExchangeServer) GetNewAddress(cp string) string {
+// GetNewAddress create new address of specific coin type in the named wallet,
+// the address is created in default wallet if wltName is empty.
+func (self *ExchangeServer) GetNewAddress(cp, wltName string) (string, error) {
 	self.wltMtx.Lock()
 	defer self.wltMtx.Unlock()
-	addrEntry, err := self.wallets.NewAddresses(cp, 1)
+	addrEntry, err := self.wallets.NewAddresses(cp, wltName, 1)
 	if err != nil {
-		panic("server get new address failed")
+		return "", fmt.Errorf("server get new address failed: %v", err)
 	}
-	return addrEntry[0].Address
+	return addrEntry[0].Address, nil
 }
 
 // GetCoin gets coin gateway of specific type.
 func (serv *ExchangeServer) GetCoin(ct string) (coin.Gateway, error) {
+	serv.coinsMtx.RLock()
 	c, ok := serv.coins[ct]
+	serv.coinsMtx.RUnlock()
 	if !ok {
 		return nil, fmt.Errorf("%s coin is not supported", ct)
 	}
 	return c, nil
 }
 
-// ChooseUtxos choose appropriate bitcoin utxos,
+// ChooseUtxos choose appropriate utxos of specific coin type, the returned error
+// wraps coin.ErrUtxoTimeout or coin.ErrInsufficientUtxo if choosing failed.
 func (self *ExchangeServer) ChooseUtxos(cp string, amount uint64, tm time.Duration) (interface{}, error) {
+	var (
+		utxos interface{}
+		err   error
+	)
+
 	switch cp {
 	case bitcoin.Type:
-		return self.btcum.ChooseUtxos(amount, tm)
+		utxos, err = self.btcum.ChooseUtxos(amount, tm)
 	case skycoin.Type:
-		return self.skyum.ChooseUtxos(amount, tm)
+		utxos, err = self.skyum.ChooseUtxos(amount, tm)
+	case litecoin.Type:
+		utxos, err = self.ltcum.ChooseUtxos(amount, tm)
 	default:
 		return nil, errors.New("unknow coin type")
 	}
+
+	if err != nil {
+		return nil, fmt.Errorf("choose %s utxos failed: %w", cp, err)
+	}
+	return utxos, nil
+}
+
+// TakeUtxos takes the specific utxos of the server wallet out of the pool, ids are in txid:vout,
+// only bitcoin is supported. The returned error wraps coin.ErrUtxoReserved if any of them is
+// chosen by another transaction.
+func (self *ExchangeServer) TakeUtxos(cp string, ids []string) (interface{}, error) {
+	if cp != bitcoin.Type {
+		return nil, fmt.Errorf("taking specific utxos is not supported by %s", cp)
+	}
+
+	utxos, err := self.btcum.TakeUtxos(ids)
+	if err != nil {
+		return nil, fmt.Errorf("take %s utxos failed: %w", cp, err)
+	}
+	return utxos, nil
 }
 
 // PutUtxos set back the utxos of specific coin type.
@@ -239,6 +609,25 @@ func (self *ExchangeServer) PutUtxos(cp string, utxos interface{}) {
 		for _, u := range skyUtxos {
 			self.skyum.PutUtxo(u)
 		}
+	case litecoin.Type:
+		ltcUtxos := utxos.([]litecoin.Utxo)
+		for _, u := range ltcUtxos {
+			self.ltcum.PutUtxo(u)
+		}
+	}
+}
+
+// GetUtxoStats returns the state of the utxo pool of specific coin type.
+func (self *ExchangeServer) GetUtxoStats(cp string) (coin.UtxoStats, error) {
+	switch cp {
+	case bitcoin.Type:
+		return self.btcum.Stats(), nil
+	case skycoin.Type:
+		return self.skyum.Stats(), nil
+	case litecoin.Type:
+		return self.ltcum.Stats(), nil
+	default:
+		return coin.UtxoStats{}, errors.New("unknow coin type")
 	}
 }
 
@@ -249,6 +638,8 @@ func (self *ExchangeServer) WatchAddress(cp, addr string) {
 		self.btcum.WatchAddresses([]string{addr})
 	case skycoin.Type:
 		self.skyum.WatchAddresses([]string{addr})
+	case litecoin.Type:
+		self.ltcum.WatchAddresses([]string{addr})
 	}
 }
 
@@ -256,27 +647,286 @@ func (self *ExchangeServer) SaveAccount() error {
 	return self.Save()
 }
 
+// CreateAccount creates the account of specific pubkey on behalf of the admin, returns the account id.
+func (self *ExchangeServer) CreateAccount(pubkey string) (string, error) {
+	a, err := self.CreateAccountWithPubkey(pubkey)
+	if err != nil {
+		return "", err
+	}
+	return a.GetID(), nil
+}
+
+// DeleteAccount removes the account on behalf of the admin, the account must
+// have no balance, no reserved balance and no open orders.
+func (self *ExchangeServer) DeleteAccount(accountID string) error {
+	a, err := self.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	if !a.IsEmpty() {
+		return fmt.Errorf("account %s has nonzero balance", accountID)
+	}
+
+	if self.orderManager.HasOpenOrders(accountID) {
+		return fmt.Errorf("account %s has open orders", accountID)
+	}
+
+	return self.Manager.DeleteAccount(accountID)
+}
+
+// FreezeAccount freezes the account on behalf of the admin, the account can't place orders,
+// withdraw or transfer balance until it's unfrozen, its balances can still be queried. If
+// cancelOrders is true, the open orders of the account are cancelled and their balances released.
+func (self *ExchangeServer) FreezeAccount(accountID string, cancelOrders bool) error {
+	if err := self.SetFrozen(accountID, true); err != nil {
+		return err
+	}
+	sklog.Info(logger, "account frozen", sklog.Fields{"accountID": accountID, "cancelOrders": cancelOrders})
+
+	if !cancelOrders {
+		return nil
+	}
+
+	for cp, ods := range self.orderManager.GetAccountOrders(accountID, true) {
+		for _, od := range ods {
+			// the order may be filled after it's listed.
+			if err := self.CancelOrder(cp, od.ID, accountID); err != nil && !errors.Is(err, order.ErrOrderNotExist) {
+				return fmt.Errorf("cancel %s order %d failed: %v", cp, od.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// UnfreezeAccount unfreezes the account on behalf of the admin, the cancelled orders are not restored.
+func (self *ExchangeServer) UnfreezeAccount(accountID string) error {
+	if err := self.SetFrozen(accountID, false); err != nil {
+		return err
+	}
+	sklog.Info(logger, "account unfrozen", sklog.Fields{"accountID": accountID})
+	return nil
+}
+
+// GetAccountBalances returns the available and reserved balances of all coins in the account.
+func (self *ExchangeServer) GetAccountBalances(accountID string) (map[string]account.Balance, error) {
+	a, err := self.GetAccount(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("get balances of account %s failed: %v", accountID, err)
+	}
+	return a.GetBalances(), nil
+}
+
+// TransferBalance moves amount ct coins from account fromID to account toID without
+// blockchain transaction, the accounts are saved once transferred.
+func (self *ExchangeServer) TransferBalance(fromID, toID, ct string, amount uint64) error {
+	if err := self.Manager.Transfer(fromID, toID, ct, amount); err != nil {
+		return err
+	}
+
+	sklog.Info(logger, "balance transferred", sklog.Fields{
+		"from":   fromID,
+		"to":     toID,
+		"coin":   ct,
+		"amount": amount,
+	})
+	if err := self.SaveAccount(); err != nil {
+		logger.Error("save account failed: %v", err)
+	}
+	return nil
+}
+
+// AddOrder adds the order to the book of specific coin pair, the order resting
+// in the book is published to the stream, market and IOC orders are published by their fills,
+// and the stop orders are published once they are triggered. The amount in the quote coin is
+// converted to the base coin first, so the published order carries the base amount.
+// The account must be able to pay for the order, its balance is reserved by ReserveOrder before
+// the book is touched, and given back if the order is not added, the under-funded order fails
+// with ErrInsufficientBalance and leaves the book unchanged.
 func (self *ExchangeServer) AddOrder(cp string, odr order.Order) (uint64, error) {
-	return self.orderManager.AddOrder(cp, odr)
+	if self.IsFrozen(odr.AccountID) {
+		return 0, account.ErrAccountFrozen
+	}
+
+	odr, err := self.orderManager.ToBase(cp, odr)
+	if err != nil {
+		return 0, err
+	}
+
+	// the stop order reserves nothing, but must be covered too.
+	if err := self.validateBalance(cp, nil, odr); err != nil {
+		return 0, err
+	}
+	release, err := self.ReserveOrder(cp, &odr)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := self.orderManager.AddOrder(cp, odr)
+	if err != nil {
+		release()
+		return 0, err
+	}
+	metrics.OrdersPlaced.WithLabelValues(cp, odr.Type.String()).Inc()
+
+	if odr.Kind == order.Limit && odr.TimeInForce != order.IOC && !odr.IsStop() {
+		odr.ID = id
+		if odr.RestAmt == 0 || odr.RestAmt > odr.Amount {
+			odr.RestAmt = odr.Amount
+		}
+		self.publish(router.StreamEvent{Type: router.EventAdd, Pair: cp, Order: &odr})
+	}
+	return id, nil
 }
 
-func (self ExchangeServer) IsAdmin(pubkey string) bool {
+// AddOrders adds the batch of orders to the book of specific coin pair, and the book is matched
+// once after all of them are placed. Each order is validated and its balance is reserved as the
+// single order does, the balance reserved for the order that's not placed is given back.
+// The id or the error of each order is returned at its index.
+func (self *ExchangeServer) AddOrders(cp string, odrs []order.Order) ([]uint64, []error) {
+	// the amounts are converted to the base coin in place.
+	odrs = append([]order.Order{}, odrs...)
+	ids := make([]uint64, len(odrs))
+	errs := make([]error, len(odrs))
+	releases := make([]func(), len(odrs))
+	idx := []int{} // index of the orders passed to the order manager.
+	batch := []order.Order{}
+	for i, odr := range odrs {
+		if self.IsFrozen(odr.AccountID) {
+			errs[i] = account.ErrAccountFrozen
+			continue
+		}
+
+		odr, err := self.orderManager.ToBase(cp, odr)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		odrs[i] = odr
+		if err := self.ValidateOrder(cp, odr); err != nil {
+			errs[i] = err
+			continue
+		}
+		release, err := self.ReserveOrder(cp, &odr)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		odrs[i] = odr
+		releases[i] = release
+		idx = append(idx, i)
+		batch = append(batch, odr)
+	}
+
+	if len(batch) > 0 {
+		bids, berrs := self.orderManager.AddOrders(cp, batch)
+		for j, i := range idx {
+			ids[i], errs[i] = bids[j], berrs[j]
+		}
+	}
+
+	placed := false
+	for i, odr := range odrs {
+		if errs[i] != nil {
+			if releases[i] != nil {
+				releases[i]()
+			}
+			continue
+		}
+		placed = true
+		metrics.OrdersPlaced.WithLabelValues(cp, odr.Type.String()).Inc()
+		if odr.Kind == order.Limit && odr.TimeInForce != order.IOC && !odr.IsStop() {
+			odr.ID = ids[i]
+			if odr.RestAmt == 0 || odr.RestAmt > odr.Amount {
+				odr.RestAmt = odr.Amount
+			}
+			self.publish(router.StreamEvent{Type: router.EventAdd, Pair: cp, Order: &odr})
+		}
+	}
+
+	if placed {
+		if err := self.SaveAccount(); err != nil {
+			logger.Error("save account failed: %v", err)
+		}
+	}
+	return ids, errs
+}
+
+// CancelOrder cancels the open order of the account, and releases
+// the balance that was reserved for the rest amount of the order.
+func (self *ExchangeServer) CancelOrder(cp string, id uint64, aid string) error {
+	acnt, err := self.GetAccount(aid)
+	if err != nil {
+		return err
+	}
+
+	od, err := self.orderManager.CancelOrder(cp, id, aid)
+	if err != nil {
+		return err
+	}
+
+	// no balance is reserved for the stop order before it's triggered.
+	if od.IsStop() {
+		return nil
+	}
+	self.publish(router.StreamEvent{Type: router.EventCancel, Pair: cp, Order: &od})
+
+	pair := strings.Split(cp, "/")
+	if len(pair) != 2 {
+		return errors.New("error coin pair")
+	}
+
+	switch od.Type {
+	case order.Bid:
+		// give back the same value as reserved, which was rounded up.
+		v, err := self.orderManager.Value(cp, od.Price, od.RestAmt, order.RoundUp)
+		if err != nil {
+			return err
+		}
+		logger.Info("account:%s increase %s:%d", aid, pair[1], v)
+		if err := acnt.IncreaseBalance(pair[1], v, account.ReasonOrderCancel); err != nil {
+			return err
+		}
+	case order.Ask:
+		logger.Info("account:%s release %s:%d", aid, pair[0], od.RestAmt)
+		if err := acnt.ReleaseBalance(pair[0], od.RestAmt, account.ReasonOrderCancel); err != nil {
+			return err
+		}
+	}
+	return self.SaveAccount()
+}
+
+// IsAdmin checks if the pubkey is exactly one of the admin pubkeys.
+func (self *ExchangeServer) IsAdmin(pubkey string) bool {
 	logger.Debug("admins:%s, pubkey:%s", self.cfg.Admins, pubkey)
-	return strings.Contains(self.cfg.Admins, pubkey)
+	return self.admins[pubkey]
+}
+
+// parseAdmins parses the admin pubkeys joined with `,` into a set.
+func parseAdmins(admins string) map[string]bool {
+	keys := make(map[string]bool)
+	for _, k := range strings.Split(admins, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys[k] = true
+		}
+	}
+	return keys
 }
 
-// initDataDir init the data dir of skycoin exchange.
+// initDataDir init the data dir of skycoin exchange, the relative dir is in the home directory.
 func initDataDir(dir string) string {
 	if dir == "" {
 		logger.Error("data directory is nil")
 	}
 
-	home := util.UserHome()
-	if home == "" {
-		logger.Warning("Failed to get home directory")
-		dir = filepath.Join("./", dir)
-	} else {
-		dir = filepath.Join(home, dir)
+	if !filepath.IsAbs(dir) {
+		home := util.UserHome()
+		if home == "" {
+			logger.Warning("Failed to get home directory")
+			dir = filepath.Join("./", dir)
+		} else {
+			dir = filepath.Join(home, dir)
+		}
 	}
 
 	if err := os.MkdirAll(dir, os.FileMode(0700)); err != nil {
@@ -287,69 +937,573 @@ func initDataDir(dir string) string {
 
 func (self *ExchangeServer) handleOrders(c chan bool) {
 	for cp, ch := range self.orderHandlers {
-		go func(cp string, ch chan order.Order, closing chan bool) {
-			for {
-				select {
-				case <-closing:
-					return
-				case order := <-ch:
-					// handle the order
-					self.settleOrder(cp, order)
+		self.handleOrder(cp, ch, c)
+	}
+}
+
+// handleOrder settles the fills of specific coin pair until c is closed.
+func (self *ExchangeServer) handleOrder(cp string, ch chan order.Fill, c chan bool) {
+	self.goWait(func() {
+		for {
+			select {
+			case <-c:
+				return
+			case f := <-ch:
+				// the fill failed to settle is skipped, the balances are left unchanged.
+				if err := self.settleOrder(cp, f); err != nil {
+					sklog.Error(logger, "settle order failed", sklog.Fields{
+						"pair":      cp,
+						"orderID":   f.Order.ID,
+						"accountID": f.Order.AccountID,
+						"filled":    f.Amount,
+						"error":     err.Error(),
+					})
 				}
 			}
-		}(cp, ch, c)
+		}
+	})
+}
+
+// AddCoinPair adds the order book of coin pair like bitcoin/skycoin, both coins must be
+// registered. The book is saved, and starts matching immediately if the server is running.
+func (self *ExchangeServer) AddCoinPair(cp string) error {
+	pair := strings.Split(cp, "/")
+	if len(pair) != 2 || pair[0] == "" || pair[1] == "" || pair[0] == pair[1] {
+		return fmt.Errorf("invalid coin pair: %s", cp)
+	}
+
+	for _, ct := range pair {
+		if _, err := self.GetCoin(ct); err != nil {
+			return err
+		}
 	}
+
+	self.runMtx.Lock()
+	defer self.runMtx.Unlock()
+	select {
+	case <-self.closing:
+		return errors.New("server is shut down")
+	default:
+	}
+
+	if self.orderManager.IsExist(cp) {
+		return fmt.Errorf("coin pair %s already exists", cp)
+	}
+
+	// the fills channel must be registered before the book starts matching.
+	ch := make(chan order.Fill, 100)
+	self.orderManager.RegisterOrderChan(cp, ch)
+	bk := &order.Book{}
+	if self.cfg.MaxOrders > 0 {
+		policy, err := order.FullPolicyFromStr(self.cfg.OrderFullPolicy)
+		if err != nil {
+			return err
+		}
+		bk.SetMaxOrders(self.cfg.MaxOrders, policy)
+	}
+	bk.SetPriceBand(self.cfg.PriceBand)
+	if err := self.orderManager.AddBook(cp, bk); err != nil {
+		return err
+	}
+	self.orderHandlers[cp] = ch
+	if self.running {
+		self.handleOrder(cp, ch, self.closing)
+	}
+
+	logger.Info("coin pair %s added", cp)
+	return self.orderManager.SaveBook(cp)
 }
 
-func (self *ExchangeServer) settleOrder(cp string, od order.Order) {
-	logger.Info("match order=== type:%s, price:%d, amount:%d", od.Type, od.Price, od.Amount)
+// settleOrder adjusts the account balances for one fill of the order,
+// the balance changes are computed with the filled amount and execution price.
+// The accounts are checked before any balance is changed, and the changes already
+// made are reverted if a later one fails, so a failed fill leaves the balances as they were.
+func (self *ExchangeServer) settleOrder(cp string, f order.Fill) error {
+	od := f.Order
+	sklog.Info(logger, "order matched", sklog.Fields{
+		"pair":      cp,
+		"orderID":   od.ID,
+		"accountID": od.AccountID,
+		"type":      od.Type.String(),
+		"kind":      od.Kind.String(),
+		"price":     f.Price,
+		"amount":    od.Amount,
+		"filled":    f.Amount,
+		"rest":      od.RestAmt,
+	})
 	acnt, err := self.GetAccount(od.AccountID)
 	if err != nil {
-		panic("error account id")
+		return fmt.Errorf("error account id %s: %v", od.AccountID, err)
 	}
 
 	pair := strings.Split(cp, "/")
 	if len(pair) != 2 {
-		panic("error coin pair")
+		return fmt.Errorf("error coin pair %s", cp)
 	}
 	mainCt := pair[0]
 	subCt := pair[1]
 
+	// the stop order is dropped when triggered, no balance was reserved for it.
+	if od.IsStop() {
+		return nil
+	}
+
+	// the order is closed, give back the balance of the rest amount.
+	if f.Amount == 0 {
+		switch {
+		case od.Type == order.Ask:
+			logBalance(cp, od.AccountID, "release", mainCt, od.RestAmt)
+			if err := acnt.ReleaseBalance(mainCt, od.RestAmt, account.ReasonOrderCancel); err != nil {
+				return err
+			}
+		case od.Kind == order.Limit:
+			// the sub coin of limit bid was decreased when creating the order.
+			v, err := self.orderManager.Value(cp, od.Price, od.RestAmt, order.RoundUp)
+			if err != nil {
+				return err
+			}
+			logBalance(cp, od.AccountID, "increase", subCt, v)
+			if err := acnt.IncreaseBalance(subCt, v, account.ReasonOrderCancel); err != nil {
+				return err
+			}
+		case od.Cost > 0:
+			// the market bid gives back the cost it hasn't spent.
+			logBalance(cp, od.AccountID, "increase", subCt, od.Cost)
+			if err := acnt.IncreaseBalance(subCt, od.Cost, account.ReasonOrderCancel); err != nil {
+				return err
+			}
+		}
+
+		// the expired order is removed from the book.
+		if od.Kind == order.Limit && od.TimeInForce == order.GTD {
+			self.publish(router.StreamEvent{Type: router.EventCancel, Pair: cp, Order: &od})
+		}
+		self.SaveAccount()
+		return nil
+	}
+
+	// the bid receives main coins, the ask receives sub coins, the taker fee is deducted.
+	// The value received is rounded down, and the value paid is rounded up.
+	recvCt, recvAmt := mainCt, f.Amount
+	if od.Type == order.Ask {
+		recvCt = subCt
+		if recvAmt, err = self.orderManager.Value(cp, f.Price, f.Amount, order.RoundDown); err != nil {
+			return err
+		}
+	}
+
+	var fee uint64
+	var feeAcnt account.Accounter
+	if f.Taker {
+		fee = self.orderManager.TakerFee(recvAmt)
+	}
+	if fee > 0 {
+		feeAcnt, err = self.GetAccount(self.cfg.FeeAccount)
+		if err != nil {
+			return fmt.Errorf("error fee account id %s: %v", self.cfg.FeeAccount, err)
+		}
+	}
+
 	switch od.Type {
 	case order.Bid:
-		// increase main coin balance
-		logger.Info("account:%s increase %s:%d", od.AccountID, mainCt, od.Amount)
-		if err := acnt.IncreaseBalance(mainCt, od.Amount); err != nil {
-			panic(err)
+		// the sub coin of bid was decreased when creating the order, the market bid
+		// spends its cost at the execution price.
+		logBalance(cp, od.AccountID, "increase", recvCt, recvAmt-fee)
+		if err := acnt.IncreaseBalance(recvCt, recvAmt-fee, account.ReasonTrade); err != nil {
+			return err
 		}
 
-		self.SaveAccount()
+		// the fully filled market bid has no closing fill, give back the cost it hasn't spent.
+		if od.Kind == order.Market && od.RestAmt == 0 && od.Cost > 0 {
+			logBalance(cp, od.AccountID, "increase", subCt, od.Cost)
+			if err := acnt.IncreaseBalance(subCt, od.Cost, account.ReasonOrderCancel); err != nil {
+				return err
+			}
+		}
+
+		// the limit bid paid its own price, refund the difference to the execution price.
+		if od.Kind == order.Limit && f.Price < od.Price {
+			refund, err := self.bidRefund(cp, od.Price, f.Price, f.Amount, od.RestAmt)
+			if err != nil {
+				return err
+			}
+			if refund > 0 {
+				logBalance(cp, od.AccountID, "increase", subCt, refund)
+				if err := acnt.IncreaseBalance(subCt, refund, account.ReasonTrade); err != nil {
+					return err
+				}
+			}
+		}
 	case order.Ask:
-		// increase sub coin balance.
-		logger.Info("account:%s increase %s:%d", od.AccountID, subCt, od.Price*od.Amount)
-		if err := acnt.IncreaseBalance(subCt, od.Price*od.Amount); err != nil {
-			panic(err)
+		logBalance(cp, od.AccountID, "increase", recvCt, recvAmt-fee)
+		if err := acnt.IncreaseBalance(recvCt, recvAmt-fee, account.ReasonTrade); err != nil {
+			return err
 		}
-		// decrease main coin balance.
-		logger.Info("account:%s decrease %s:%d", od.AccountID, mainCt, od.Amount)
-		if err := acnt.DecreaseBalance(mainCt, od.Amount); err != nil {
-			panic(err)
+
+		// decrease main coin balance reserved by the ask.
+		logBalance(cp, od.AccountID, "decrease reserved", mainCt, f.Amount)
+		if err := acnt.DecreaseReservedBalance(mainCt, f.Amount, account.ReasonTrade); err != nil {
+			acnt.DecreaseBalance(recvCt, recvAmt-fee, account.ReasonTrade)
+			return err
+		}
+	default:
+		return fmt.Errorf("unknow order type %d", od.Type)
+	}
+
+	if fee > 0 {
+		sklog.Info(logger, "fee charged", sklog.Fields{"accountID": self.cfg.FeeAccount, "coin": recvCt, "amount": fee})
+		if err := feeAcnt.IncreaseBalance(recvCt, fee, account.ReasonTradeFee); err != nil {
+			logger.Error("charge fee of order %d failed: %v", od.ID, err)
 		}
-		self.SaveAccount()
 	}
+	self.SaveAccount()
+
+	// record the trade once, on the taker side.
+	if f.Taker {
+		self.recordTrade(cp, f)
+	}
+	metrics.OrdersMatched.WithLabelValues(cp, od.Type.String()).Inc()
+	self.publish(router.StreamEvent{Type: router.EventFill, Pair: cp, Order: &od})
+	return nil
 }
 
-func (self *ExchangeServer) GetOrders(cp string, tp order.Type, start, end int64) ([]order.Order, error) {
+// bidRefund returns how much the limit bid paid for amt at its own price beyond the value at the
+// execution price, rest is the amount left after the fill. The part reserved for amt is taken
+// from the value of the rest before and after the fill, rather than the value of amt alone, so
+// that the refunds of all the fills and the closing refund of the rest never exceed the value
+// reserved for the whole order, the values are rounded up as the bid value is.
+func (self *ExchangeServer) bidRefund(cp string, paid, price, amt, rest uint64) (uint64, error) {
+	before, err := self.orderManager.Value(cp, paid, rest+amt, order.RoundUp)
+	if err != nil {
+		return 0, err
+	}
+	after, err := self.orderManager.Value(cp, paid, rest, order.RoundUp)
+	if err != nil {
+		return 0, err
+	}
+	v, err := self.orderManager.Value(cp, price, amt, order.RoundUp)
+	if err != nil {
+		return 0, err
+	}
+	if reserved := before - after; v < reserved {
+		return reserved - v, nil
+	}
+	return 0, nil
+}
+
+// activateStop reserves the balance for the triggered stop order of coin pair cp, the
+// same as creating the order, the market bid decreases the balance by the estimated cost,
+// which is set as its Cost. The order is dropped if the balance is not sufficient.
+func (self *ExchangeServer) activateStop(cp string, od *order.Order) error {
+	acnt, err := self.GetAccount(od.AccountID)
+	if err != nil {
+		return err
+	}
+
+	pair := strings.Split(cp, "/")
+	if len(pair) != 2 {
+		return errors.New("error coin pair")
+	}
+
+	switch {
+	case od.Type == order.Bid && od.Kind == order.Market:
+		var cost uint64
+		if cost, err = self.orderManager.MarketCost(cp, od.Amount); err == nil {
+			if cost == 0 {
+				err = errors.New("no ask orders for market bid")
+				break
+			}
+			logBalance(cp, od.AccountID, "decrease", pair[1], cost)
+			if err = acnt.DecreaseBalance(pair[1], cost, account.ReasonOrder); err == nil {
+				od.Cost = cost
+			}
+		}
+	case od.Type == order.Bid:
+		var v uint64
+		if v, err = self.orderManager.Value(cp, od.Price, od.Amount, order.RoundUp); err == nil {
+			logBalance(cp, od.AccountID, "decrease", pair[1], v)
+			err = acnt.DecreaseBalance(pair[1], v, account.ReasonOrder)
+		}
+	default:
+		logBalance(cp, od.AccountID, "reserve", pair[0], od.Amount)
+		err = acnt.ReserveBalance(pair[0], od.Amount, account.ReasonOrder)
+	}
+	if err != nil {
+		sklog.Error(logger, "stop order dropped", sklog.Fields{
+			"pair":      cp,
+			"orderID":   od.ID,
+			"accountID": od.AccountID,
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	sklog.Info(logger, "stop order triggered", sklog.Fields{
+		"pair":      cp,
+		"orderID":   od.ID,
+		"accountID": od.AccountID,
+		"type":      od.Type.String(),
+		"kind":      od.Kind.String(),
+		"price":     od.Price,
+		"amount":    od.Amount,
+	})
+	if od.Kind == order.Limit && od.TimeInForce != order.IOC {
+		ev := *od
+		self.publish(router.StreamEvent{Type: router.EventAdd, Pair: cp, Order: &ev})
+	}
+	if err := self.SaveAccount(); err != nil {
+		logger.Error("save account failed: %v", err)
+	}
+	return nil
+}
+
+// logBalance logs the balance change of the account when settling the order of coin pair cp.
+func logBalance(cp, accountID, op, ct string, amt uint64) {
+	sklog.Info(logger, "balance changed", sklog.Fields{
+		"pair":      cp,
+		"accountID": accountID,
+		"op":        op,
+		"coin":      ct,
+		"amount":    amt,
+	})
+}
+
+// recordTrade appends the trade of the taker fill to trade log,
+// the trade is executed at the maker's price.
+func (self *ExchangeServer) recordTrade(cp string, f order.Fill) {
+	t := order.FillTrade(cp, f)
+	if err := self.tradeLog.Append(t); err != nil {
+		logger.Error("record trade failed: %v", err)
+	}
+	sklog.Info(logger, "trade", sklog.Fields{
+		"pair":         cp,
+		"price":        t.Price,
+		"amount":       t.Amount,
+		"maker":        t.Maker,
+		"taker":        t.Taker,
+		"makerOrderID": t.MakerOrderID,
+		"takerOrderID": t.TakerOrderID,
+	})
+	self.publish(router.StreamEvent{Type: router.EventTrade, Pair: cp, Trade: &t})
+	if self.stream != nil {
+		self.stream.PublishTrade(router.TapeTrade{
+			Pair:   cp,
+			Price:  t.Price,
+			Amount: t.Amount,
+			Side:   f.Order.Type.String(),
+			Time:   t.Time,
+		})
+	}
+}
+
+// publish sends the event to the order book stream, if the stream is enabled.
+func (self *ExchangeServer) publish(ev router.StreamEvent) {
+	if self.stream != nil {
+		self.stream.Publish(ev)
+	}
+}
+
+// GetOrders returns the orders of specific coin pair and type in index range [start, end),
+// and the total number of the orders.
+func (self *ExchangeServer) GetOrders(cp string, tp order.Type, start, end int64) ([]order.Order, int, error) {
 	return self.orderManager.GetOrders(cp, tp, start, end)
 }
 
+// GetOrdersByTime returns the orders of specific coin pair and type created in time range [start, end).
+func (self *ExchangeServer) GetOrdersByTime(cp string, tp order.Type, start, end int64) ([]order.Order, error) {
+	return self.orderManager.GetOrdersByTime(cp, tp, start, end)
+}
+
+// GetOrder returns the open or recently closed order of specific coin pair and id,
+// order.ErrOrderNotFound is returned if it's unknown.
+func (self *ExchangeServer) GetOrder(cp string, id uint64) (order.Order, error) {
+	return self.orderManager.GetOrder(cp, id)
+}
+
+// GetAccountOrders returns the orders of the account in every coin pair, key coin pair, the open
+// orders come first, then the recent closed ones unless openOnly is true.
+func (self *ExchangeServer) GetAccountOrders(accountID string, openOnly bool) (map[string][]order.Order, error) {
+	if _, err := self.GetAccount(accountID); err != nil {
+		return nil, fmt.Errorf("get orders of account %s failed: %v", accountID, err)
+	}
+	return self.orderManager.GetAccountOrders(accountID, openOnly), nil
+}
+
+// GetDepth returns the aggregated order book depth of specific coin pair.
+func (self *ExchangeServer) GetDepth(cp string, levels int) ([]order.DepthLevel, []order.DepthLevel, error) {
+	return self.orderManager.GetDepth(cp, levels)
+}
+
+// GetCandles returns the OHLCV candles of the trades of specific coin pair in time range [start, end],
+// the empty intervals are carried forward if fill is true.
+func (self *ExchangeServer) GetCandles(cp string, interval time.Duration, start, end int64, fill bool) ([]trade.Candle, error) {
+	if !self.orderManager.IsExist(cp) {
+		return []trade.Candle{}, fmt.Errorf("coin pair:%s not supported", cp)
+	}
+	return self.tradeLog.GetCandles(cp, interval, start, end, fill)
+}
+
+// GetTicker returns the best bid and ask, the last trade price and 24h volume of specific coin pair.
+func (self *ExchangeServer) GetTicker(cp string) (order.Ticker, error) {
+	return self.orderManager.GetTicker(cp)
+}
+
+// HaltPair halts the trading of specific coin pair on behalf of the admin, the new orders are
+// rejected and the matching is suspended, the open orders are kept and can still be cancelled.
+func (self *ExchangeServer) HaltPair(cp string) error {
+	if err := self.orderManager.HaltPair(cp); err != nil {
+		return err
+	}
+	sklog.Info(logger, "trading halted", sklog.Fields{"pair": cp})
+	return nil
+}
+
+// ResumePair resumes the trading of the halted coin pair on behalf of the admin.
+func (self *ExchangeServer) ResumePair(cp string) error {
+	if err := self.orderManager.ResumePair(cp); err != nil {
+		return err
+	}
+	sklog.Info(logger, "trading resumed", sklog.Fields{"pair": cp})
+	return nil
+}
+
+// SetMinOrderAmount sets the minimum amount of the orders in specific coin pair,
+// orders below this amount will be rejected.
+func (self *ExchangeServer) SetMinOrderAmount(cp string, amt uint64) error {
+	return self.orderManager.SetMinAmount(cp, amt)
+}
+
+// SetTickSize sets the tick size of specific coin pair, orders with prices off the
+// tick grid will be rejected, 0 means any price.
+func (self *ExchangeServer) SetTickSize(cp string, tick uint64) error {
+	return self.orderManager.SetTickSize(cp, tick)
+}
+
+// SetPriceDecimals sets the decimal places of the prices of specific coin pair, the value of
+// an order is price*amount/10^decimals. The book must hold no orders.
+func (self *ExchangeServer) SetPriceDecimals(cp string, decimals uint8) error {
+	return self.orderManager.SetPriceDecimals(cp, decimals)
+}
+
+// OrderValue returns the value price*amount of specific coin pair in the price coin, the
+// value paid by the account is rounded up, and the value received is rounded down.
+func (self *ExchangeServer) OrderValue(cp string, price, amount uint64, r order.Rounding) (uint64, error) {
+	return self.orderManager.Value(cp, price, amount, r)
+}
+
+// MarketCost estimates the cost of the market bid of amount in specific coin pair with the current asks.
+func (self *ExchangeServer) MarketCost(cp string, amount uint64) (uint64, error) {
+	return self.orderManager.MarketCost(cp, amount)
+}
+
+// SetSelfTradePrevention sets the self-trade prevention mode of specific coin pair, which
+// decides what happens when the orders of the same account match each other.
+func (self *ExchangeServer) SetSelfTradePrevention(cp string, mode order.STPMode) error {
+	return self.orderManager.SetSelfTradePrevention(cp, mode)
+}
+
+// SetMaxOrders sets the max open orders of specific coin pair, and the policy for the new
+// order when the book is full, 0 means no limit.
+func (self *ExchangeServer) SetMaxOrders(cp string, max int, policy order.FullPolicy) error {
+	return self.orderManager.SetMaxOrders(cp, max, policy)
+}
+
+// SetPriceBand sets the price band of specific coin pair in basis points on behalf of the
+// admin, 0 means no band.
+func (self *ExchangeServer) SetPriceBand(cp string, bps uint64) error {
+	if err := self.orderManager.SetPriceBand(cp, bps); err != nil {
+		return err
+	}
+	sklog.Info(logger, "price band changed", sklog.Fields{"pair": cp, "band": bps})
+	return nil
+}
+
+// coinMeta the metadata of coins which are not provided by the gateway, MinAmount is the
+// smallest amount can be transferred, like the dust limit of bitcoin.
+var coinMeta = map[string]struct {
+	Name          string
+	MinAmount     uint64
+	AddressFormat string
+}{
+	bitcoin.Type:  {"Bitcoin", 546, "base58check, bech32"},
+	litecoin.Type: {"Litecoin", 5460, "base58check"},
+	skycoin.Type:  {"Skycoin", 1e6, "base58check"},
+	mzcoin.Type:   {"Mzcoin", 1e6, "base58check"},
+}
+
+// GetSupportedCoinsInfo returns the metadata of all supported coins, sorted by coin type.
+func (serv *ExchangeServer) GetSupportedCoinsInfo() []coin.Info {
+	cs := serv.coinList()
+	infos := make([]coin.Info, 0, len(cs))
+	for _, c := range cs {
+		meta := coinMeta[c.Type()]
+		infos = append(infos, coin.Info{
+			Symbol:        c.Symbol(),
+			Name:          meta.Name,
+			Type:          c.Type(),
+			Decimals:      c.Decimals(),
+			MinAmount:     meta.MinAmount,
+			AddressFormat: meta.AddressFormat,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Type < infos[j].Type })
+	return infos
+}
+
+// HealthCheck checks the backends of all supported coins concurrently, sorted by coin type.
+// The skycoin backend is also unhealthy while the utxo manager is reconnecting the node.
+func (serv *ExchangeServer) HealthCheck() []coin.Health {
+	cs := serv.coinList()
+	hs := make([]coin.Health, 0, len(cs))
+	var (
+		mtx sync.Mutex
+		wg  sync.WaitGroup
+	)
+	for _, c := range cs {
+		wg.Add(1)
+		go func(c coin.Gateway) {
+			defer wg.Done()
+			h := coin.Health{Type: c.Type()}
+			ok, err := c.HealthCheck()
+			h.Healthy = ok
+			if err != nil {
+				h.Error = err.Error()
+			}
+
+			if c.Type() == skycoin.Type && serv.skyum != nil {
+				if s := serv.skyum.Status(); !s.Healthy {
+					h.Healthy = false
+					h.Error = fmt.Sprintf("reconnecting node: %s", s.Error)
+				}
+			}
+
+			mtx.Lock()
+			hs = append(hs, h)
+			mtx.Unlock()
+		}(c)
+	}
+	wg.Wait()
+	sort.Slice(hs, func(i, j int) bool { return hs[i].Type < hs[j].Type })
+	return hs
+}
+
 // GetSupportCoins returns all supported coin's symbol
 func (serv *ExchangeServer) GetSupportCoins() []string {
-	symbols := make([]string, len(serv.coins))
-	i := 0
-	for _, coin := range serv.coins {
+	cs := serv.coinList()
+	symbols := make([]string, len(cs))
+	for i, coin := range cs {
 		symbols[i] = coin.Symbol()
-		i++
 	}
 	return symbols
 }
+
+// coinList returns the registered coin gateways, the coins can be registered after it returns.
+func (serv *ExchangeServer) coinList() []coin.Gateway {
+	serv.coinsMtx.RLock()
+	defer serv.coinsMtx.RUnlock()
+	cs := make([]coin.Gateway, 0, len(serv.coins))
+	for _, c := range serv.coins {
+		cs = append(cs, c)
+	}
+	return cs
+}
@@ -0,0 +1,1514 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/websocket"
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	"github.com/skycoin/skycoin-exchange/src/coin/insight"
+	litecoin "github.com/skycoin/skycoin-exchange/src/coin/litecoin"
+	mzcoin "github.com/skycoin/skycoin-exchange/src/coin/mzcoin"
+	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/skycoin/skycoin-exchange/src/server/router"
+	"github.com/skycoin/skycoin-exchange/src/server/trade"
+	"github.com/skycoin/skycoin-exchange/src/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettleOrderFee(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_settle")
+	account.InitDir(filepath.Join(dir, "account"))
+	defer os.RemoveAll(dir)
+
+	tl, err := trade.NewTradeLog(filepath.Join(dir, "trades.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	s := &ExchangeServer{
+		cfg:          Config{FeeAccount: "fee"},
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+		tradeLog:     tl,
+	}
+	cp := "bitcoin/skycoin"
+	s.orderManager.AddBook(cp, &order.Book{})
+	// 0.2%
+	assert.Nil(t, s.orderManager.SetFeeRate(20))
+
+	fee, err := s.CreateAccountWithPubkey("fee")
+	assert.Nil(t, err)
+	bidder, err := s.CreateAccountWithPubkey("bidder")
+	assert.Nil(t, err)
+	asker, err := s.CreateAccountWithPubkey("asker")
+	assert.Nil(t, err)
+	asker.IncreaseBalance("bitcoin", 2000, account.ReasonAdmin)
+	assert.Nil(t, asker.ReserveBalance("bitcoin", 2000, account.ReasonOrder))
+
+	bid := order.Order{ID: 1, AccountID: "bidder", Type: order.Bid, Price: 100, Amount: 1000}
+	ask := order.Order{ID: 2, AccountID: "asker", Type: order.Ask, Price: 100, Amount: 2000}
+
+	// the bidder is the taker, the fee is deducted from the received bitcoin.
+	s.settleOrder(cp, order.Fill{Order: bid, Amount: 1000, Price: 100, Counter: ask, Taker: true})
+	s.settleOrder(cp, order.Fill{Order: ask, Amount: 1000, Price: 100, Counter: bid})
+	assert.Equal(t, uint64(998), bidder.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(100000), asker.GetBalance("skycoin"))
+	assert.Equal(t, uint64(2), fee.GetBalance("bitcoin"))
+
+	// the asker is the taker, the fee is deducted from the received skycoin.
+	s.settleOrder(cp, order.Fill{Order: ask, Amount: 1000, Price: 100, Counter: bid, Taker: true})
+	s.settleOrder(cp, order.Fill{Order: bid, Amount: 1000, Price: 100, Counter: ask})
+	assert.Equal(t, uint64(1998), bidder.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(199800), asker.GetBalance("skycoin"))
+	assert.Equal(t, uint64(0), asker.GetReservedBalance("bitcoin"))
+
+	// the fee account accumulates the fees of both coins.
+	assert.Equal(t, uint64(2), fee.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(200), fee.GetBalance("skycoin"))
+	entries := fee.GetLedger("skycoin", 0, 1<<62)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, account.ReasonTradeFee, entries[0].Reason)
+
+	// no fee is charged when the rate is zero.
+	assert.Nil(t, s.orderManager.SetFeeRate(0))
+	s.settleOrder(cp, order.Fill{Order: bid, Amount: 1000, Price: 100, Counter: ask, Taker: true})
+	assert.Equal(t, uint64(2998), bidder.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(2), fee.GetBalance("bitcoin"))
+}
+
+func TestSettleClosedOrder(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_settle_closed")
+	account.InitDir(filepath.Join(dir, "account"))
+	defer os.RemoveAll(dir)
+
+	s := &ExchangeServer{
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+	}
+	cp := "bitcoin/skycoin"
+	s.orderManager.AddBook(cp, &order.Book{})
+
+	acnt, err := s.CreateAccountWithPubkey("test")
+	assert.Nil(t, err)
+	acnt.IncreaseBalance("bitcoin", 10, account.ReasonAdmin)
+	assert.Nil(t, acnt.ReserveBalance("bitcoin", 10, account.ReasonOrder))
+
+	// the rest amount of ask is released.
+	ask := order.Order{AccountID: "test", Type: order.Ask, TimeInForce: order.IOC, Price: 100, Amount: 10, RestAmt: 4}
+	s.settleOrder(cp, order.Fill{Order: ask})
+	assert.Equal(t, uint64(4), acnt.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(6), acnt.GetReservedBalance("bitcoin"))
+
+	// the sub coins paid for the rest amount of limit bid are given back.
+	bid := order.Order{AccountID: "test", Type: order.Bid, TimeInForce: order.GTD, Price: 100, Amount: 10, RestAmt: 3}
+	s.settleOrder(cp, order.Fill{Order: bid})
+	assert.Equal(t, uint64(300), acnt.GetBalance("skycoin"))
+
+	// the cost the market bid hasn't spent is given back.
+	bid = order.Order{AccountID: "test", Type: order.Bid, Kind: order.Market, Amount: 10, RestAmt: 3, Cost: 50}
+	s.settleOrder(cp, order.Fill{Order: bid})
+	assert.Equal(t, uint64(350), acnt.GetBalance("skycoin"))
+}
+
+func TestSettleOrderDecimals(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_settle_decimals")
+	account.InitDir(filepath.Join(dir, "account"))
+	defer os.RemoveAll(dir)
+
+	tl, err := trade.NewTradeLog(filepath.Join(dir, "trades.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	s := &ExchangeServer{
+		cfg:          Config{FeeAccount: "fee"},
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+		tradeLog:     tl,
+	}
+
+	// the prices have 2 decimal places.
+	cp := "bitcoin/skycoin"
+	bk := &order.Book{}
+	assert.Nil(t, bk.SetPriceDecimals(2))
+	s.orderManager.AddBook(cp, bk)
+	assert.Nil(t, s.orderManager.SetFeeRate(2500))
+
+	ids := []string{"fee", "bidder", "asker"}
+	for _, id := range ids {
+		_, err := s.CreateAccountWithPubkey(id)
+		assert.Nil(t, err)
+	}
+	total := func(ct string) uint64 {
+		var n uint64
+		for _, id := range ids {
+			a, _ := s.GetAccount(id)
+			n += a.GetBalance(ct) + a.GetReservedBalance(ct)
+		}
+		return n
+	}
+
+	bidder, _ := s.GetAccount("bidder")
+	bidder.IncreaseBalance("skycoin", 10, account.ReasonAdmin)
+	asker, _ := s.GetAccount("asker")
+	asker.IncreaseBalance("bitcoin", 4, account.ReasonAdmin)
+	sky, btc := total("skycoin"), total("bitcoin")
+
+	// the limit bid of 5 at 1.50 reserves 7.5 rounded up, the ask of 4 at 1.25 rests in the book.
+	bid := order.Order{ID: 1, AccountID: "bidder", Type: order.Bid, Price: 150, Amount: 5, RestAmt: 5}
+	ask := order.Order{ID: 2, AccountID: "asker", Type: order.Ask, Price: 125, Amount: 4, RestAmt: 4}
+	for _, od := range []order.Order{bid, ask} {
+		_, err := s.ReserveOrder(cp, &od)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, uint64(2), bidder.GetBalance("skycoin"))
+	assert.Equal(t, uint64(4), asker.GetReservedBalance("bitcoin"))
+
+	// both sides are filled at the maker price 1.25, the bidder pays 5 and gets back 1
+	// of the 6 reserved for the 4 filled, the taker fee is 25% of the 4 bought.
+	bid.RestAmt, ask.RestAmt = 1, 0
+	assert.Nil(t, s.settleOrder(cp, order.Fill{Order: bid, Amount: 4, Price: 125, Counter: ask, Taker: true}))
+	assert.Nil(t, s.settleOrder(cp, order.Fill{Order: ask, Amount: 4, Price: 125, Counter: bid}))
+	assert.Equal(t, uint64(3), bidder.GetBalance("skycoin"))
+	assert.Equal(t, uint64(3), bidder.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(5), asker.GetBalance("skycoin"))
+	assert.Equal(t, uint64(0), asker.GetReservedBalance("bitcoin"))
+
+	// the rest 1 of the bid gives back 1.5 rounded up.
+	assert.Nil(t, s.settleOrder(cp, order.Fill{Order: bid}))
+	assert.Equal(t, uint64(5), bidder.GetBalance("skycoin"))
+
+	fee, _ := s.GetAccount("fee")
+	assert.Equal(t, uint64(1), fee.GetBalance("bitcoin"))
+	assert.Equal(t, sky, total("skycoin"))
+	assert.Equal(t, btc, total("bitcoin"))
+}
+
+func TestSettleBidRefundConserved(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_settle_refund")
+	account.InitDir(filepath.Join(dir, "account"))
+	defer os.RemoveAll(dir)
+
+	tl, err := trade.NewTradeLog(filepath.Join(dir, "trades.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	s := &ExchangeServer{
+		cfg:          Config{FeeAccount: "fee"},
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+		tradeLog:     tl,
+	}
+
+	// the prices have 1 decimal place, the taker fee is 10%.
+	cp := "bitcoin/skycoin"
+	bk := &order.Book{}
+	assert.Nil(t, bk.SetPriceDecimals(1))
+	s.orderManager.AddBook(cp, bk)
+	assert.Nil(t, s.orderManager.SetFeeRate(1000))
+
+	ids := []string{"fee", "bidder", "asker1", "asker2", "asker3"}
+	for _, id := range ids {
+		_, err := s.CreateAccountWithPubkey(id)
+		assert.Nil(t, err)
+	}
+	total := func(ct string) uint64 {
+		var n uint64
+		for _, id := range ids {
+			a, err := s.GetAccount(id)
+			assert.Nil(t, err)
+			n += a.GetBalance(ct) + a.GetReservedBalance(ct)
+		}
+		return n
+	}
+
+	// the bid of 404 at 1.5 reserves 606, each ask of 101 at 1.0 is worth 101, while
+	// 101 at 1.5 is 151.5, whose rounding must not be refunded more than once.
+	bidder, _ := s.GetAccount("bidder")
+	bidder.IncreaseBalance("skycoin", 606, account.ReasonAdmin)
+	bid := order.Order{ID: 1, AccountID: "bidder", Type: order.Bid, Price: 15, Amount: 404, RestAmt: 404}
+	asks := make([]order.Order, 3)
+	for i := range asks {
+		asks[i] = order.Order{ID: uint64(i + 2), AccountID: ids[i+2], Type: order.Ask, Price: 10, Amount: 101, RestAmt: 101}
+		a, _ := s.GetAccount(asks[i].AccountID)
+		a.IncreaseBalance("bitcoin", 101, account.ReasonAdmin)
+	}
+	sky, btc := total("skycoin"), total("bitcoin")
+
+	// the limit bid decreases its value from the balance, the asks reserve their amount.
+	for _, od := range append([]order.Order{bid}, asks...) {
+		_, err = s.ReserveOrder(cp, &od)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, uint64(0), bidder.GetBalance("skycoin"))
+
+	// the bid takes the three asks at their price, and its rest is closed.
+	for _, ask := range asks {
+		bid.RestAmt -= 101
+		ask.RestAmt = 0
+		assert.Nil(t, s.settleOrder(cp, order.Fill{Order: bid, Amount: 101, Price: 10, Counter: ask, Taker: true}))
+		assert.Nil(t, s.settleOrder(cp, order.Fill{Order: ask, Amount: 101, Price: 10, Counter: bid}))
+	}
+	assert.Nil(t, s.settleOrder(cp, order.Fill{Order: bid}))
+
+	assert.Equal(t, sky, total("skycoin"))
+	assert.Equal(t, btc, total("bitcoin"))
+	assert.Equal(t, uint64(303), bidder.GetBalance("skycoin"))
+	assert.Equal(t, uint64(273), bidder.GetBalance("bitcoin"))
+	fee, _ := s.GetAccount("fee")
+	assert.Equal(t, uint64(30), fee.GetBalance("bitcoin"))
+	for _, id := range ids[2:] {
+		a, _ := s.GetAccount(id)
+		assert.Equal(t, uint64(101), a.GetBalance("skycoin"))
+		assert.Equal(t, uint64(0), a.GetReservedBalance("bitcoin"))
+	}
+}
+
+func TestReserveMarketBid(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_reserve_market")
+	account.InitDir(filepath.Join(dir, "account"))
+	defer os.RemoveAll(dir)
+
+	s := &ExchangeServer{
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+	}
+	cp := "bitcoin/skycoin"
+	bk := &order.Book{}
+	bk.AddAsk(order.Order{ID: 1, AccountID: "asker", Type: order.Ask, Price: 100, Amount: 1, RestAmt: 1})
+	bk.AddAsk(order.Order{ID: 2, AccountID: "asker", Type: order.Ask, Price: 100, Amount: 1, RestAmt: 1})
+	s.orderManager.AddBook(cp, bk)
+
+	bidder, err := s.CreateAccountWithPubkey("bidder")
+	assert.Nil(t, err)
+	bidder.IncreaseBalance("skycoin", 150, account.ReasonAdmin)
+
+	// the first market bid takes the cost of 100 from the balance, so the second can't pay for it.
+	bid := order.Order{AccountID: "bidder", Type: order.Bid, Kind: order.Market, Amount: 1, RestAmt: 1}
+	release, err := s.ReserveOrder(cp, &bid)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), bid.Cost)
+	assert.Equal(t, uint64(50), bidder.GetBalance("skycoin"))
+
+	bid2 := order.Order{AccountID: "bidder", Type: order.Bid, Kind: order.Market, Amount: 1, RestAmt: 1}
+	assert.NotNil(t, s.validateBalance(cp, nil, bid2))
+	_, err = s.ReserveOrder(cp, &bid2)
+	assert.NotNil(t, err)
+	assert.Equal(t, uint64(50), bidder.GetBalance("skycoin"))
+
+	// the bid not added gives back the cost.
+	release()
+	assert.Equal(t, uint64(150), bidder.GetBalance("skycoin"))
+
+	// the closing fill gives back the cost not spent, the fill spends it.
+	_, err = s.ReserveOrder(cp, &bid)
+	assert.Nil(t, err)
+	bid.Cost = 40
+	assert.Nil(t, s.settleOrder(cp, order.Fill{Order: bid}))
+	assert.Equal(t, uint64(90), bidder.GetBalance("skycoin"))
+}
+
+func TestEvictOrder(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_evict_order")
+	account.InitDir(filepath.Join(dir, "account"))
+	order.InitDir(filepath.Join(dir, "orderbook"))
+	defer os.RemoveAll(dir)
+
+	cp := "bitcoin/skycoin"
+	s := &ExchangeServer{
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+	}
+	ch := make(chan order.Fill, 10)
+	s.orderManager.RegisterOrderChan(cp, ch)
+	assert.Nil(t, s.orderManager.AddBook(cp, &order.Book{}))
+	assert.Nil(t, s.SetMaxOrders(cp, 1, order.FullEvictWorst))
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		s.orderManager.Start(time.Hour, closing)
+		close(done)
+	}()
+	// the id files are saved until the manager is stopped, wait before removing the dir.
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	acnt, err := s.CreateAccountWithPubkey("a")
+	assert.Nil(t, err)
+	acnt.IncreaseBalance("bitcoin", 10, account.ReasonAdmin)
+	b, err := s.CreateAccountWithPubkey("b")
+	assert.Nil(t, err)
+	b.IncreaseBalance("bitcoin", 10, account.ReasonAdmin)
+	_, err = s.AddOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 200, CreatedAt: 1, Amount: 10})
+	assert.Nil(t, err)
+
+	// the better priced ask evicts the ask of a, and the reserved balance is released.
+	_, err = s.AddOrder(cp, order.Order{AccountID: "b", Type: order.Ask, Price: 100, CreatedAt: 2, Amount: 10})
+	assert.Nil(t, err)
+	assert.Nil(t, s.settleOrder(cp, <-ch))
+	assert.Equal(t, uint64(10), acnt.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(0), acnt.GetReservedBalance("bitcoin"))
+
+	// the worse priced one is rejected.
+	_, err = s.AddOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 200, CreatedAt: 3, Amount: 10})
+	assert.Equal(t, order.ErrBookFull, err)
+}
+
+func TestFreezeAccount(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_freeze_account")
+	account.InitDir(filepath.Join(dir, "account"))
+	order.InitDir(filepath.Join(dir, "orderbook"))
+	defer os.RemoveAll(dir)
+
+	cp := "bitcoin/skycoin"
+	s := &ExchangeServer{
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+	}
+	assert.Nil(t, s.orderManager.AddBook(cp, &order.Book{}))
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		s.orderManager.Start(time.Hour, closing)
+		close(done)
+	}()
+	// the id files are saved until the manager is stopped, wait before removing the dir.
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	a, err := s.CreateAccountWithPubkey("a")
+	assert.Nil(t, err)
+	_, err = s.CreateAccountWithPubkey("b")
+	assert.Nil(t, err)
+	a.IncreaseBalance("bitcoin", 20, account.ReasonAdmin)
+	a.IncreaseBalance("skycoin", 100, account.ReasonAdmin)
+	_, err = s.AddOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 200, CreatedAt: 1, Amount: 10})
+	assert.Nil(t, err)
+	_, err = s.AddOrder(cp, order.Order{AccountID: "a", Type: order.Bid, Price: 100, StopPrice: 150, Amount: 1})
+	assert.Nil(t, err)
+
+	assert.NotNil(t, s.FreezeAccount("unknown", false))
+	assert.Nil(t, s.FreezeAccount("a", false))
+
+	// the orders, withdrawals and transfers are blocked.
+	_, err = s.AddOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 200, CreatedAt: 2, Amount: 1})
+	assert.Equal(t, account.ErrAccountFrozen, err)
+	_, errs := s.AddOrders(cp, []order.Order{{AccountID: "a", Type: order.Ask, Price: 200, CreatedAt: 2, Amount: 1}})
+	assert.Equal(t, []error{account.ErrAccountFrozen}, errs)
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: "a", CoinType: bitcoin.Type, Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", Amount: 1})
+	assert.Equal(t, account.ErrAccountFrozen, err)
+	assert.Equal(t, account.ErrAccountFrozen, s.TransferBalance("a", "b", "bitcoin", 1))
+	assert.Equal(t, account.ErrAccountFrozen, s.TransferBalance("b", "a", "bitcoin", 1))
+
+	// the balances can still be queried, the open orders are kept.
+	bals, err := s.GetAccountBalances("a")
+	assert.Nil(t, err)
+	assert.Equal(t, account.Balance{Available: 10, Reserved: 10}, bals["bitcoin"])
+	assert.True(t, s.orderManager.HasOpenOrders("a"))
+
+	// the open orders are cancelled, and the reserved balance is released.
+	assert.Nil(t, s.FreezeAccount("a", true))
+	assert.False(t, s.orderManager.HasOpenOrders("a"))
+	assert.Equal(t, uint64(20), a.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(0), a.GetReservedBalance("bitcoin"))
+
+	// unfreezing restores them.
+	assert.Nil(t, s.UnfreezeAccount("a"))
+	_, err = s.AddOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 200, CreatedAt: 3, Amount: 1})
+	assert.Nil(t, err)
+	assert.Nil(t, s.TransferBalance("a", "b", "bitcoin", 1))
+}
+
+func TestSettleOrderFailed(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_settle_failed")
+	account.InitDir(filepath.Join(dir, "account"))
+	defer os.RemoveAll(dir)
+
+	tl, err := trade.NewTradeLog(filepath.Join(dir, "trades.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	cp := "bitcoin/skycoin"
+	s := &ExchangeServer{
+		cfg:           Config{FeeAccount: "fee"},
+		Manager:       account.NewManager(),
+		orderManager:  order.NewManager(),
+		tradeLog:      tl,
+		orderHandlers: map[string]chan order.Fill{cp: make(chan order.Fill, 10)},
+	}
+	s.orderManager.AddBook(cp, &order.Book{})
+	asker, err := s.CreateAccountWithPubkey("asker")
+	assert.Nil(t, err)
+	asker.IncreaseBalance("bitcoin", 10, account.ReasonAdmin)
+	assert.Nil(t, asker.ReserveBalance("bitcoin", 10, account.ReasonOrder))
+
+	// unknown account.
+	ask := order.Order{ID: 1, AccountID: "unknown", Type: order.Ask, Price: 100, Amount: 10}
+	assert.NotNil(t, s.settleOrder(cp, order.Fill{Order: ask, Amount: 10, Price: 100}))
+
+	// the reserved balance is not sufficient, the received skycoins are reverted.
+	ask.AccountID = "asker"
+	assert.NotNil(t, s.settleOrder(cp, order.Fill{Order: ask, Amount: 20, Price: 100}))
+	assert.Equal(t, uint64(0), asker.GetBalance("skycoin"))
+	assert.Equal(t, uint64(10), asker.GetReservedBalance("bitcoin"))
+
+	// the fee account doesn't exist, nothing is changed.
+	assert.Nil(t, s.orderManager.SetFeeRate(20))
+	assert.NotNil(t, s.settleOrder(cp, order.Fill{Order: ask, Amount: 10, Price: 100, Taker: true}))
+	assert.Equal(t, uint64(0), asker.GetBalance("skycoin"))
+	assert.Equal(t, uint64(10), asker.GetReservedBalance("bitcoin"))
+	ts, err := tl.Query(cp, 0, math.MaxInt64)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ts))
+	assert.Nil(t, s.orderManager.SetFeeRate(0))
+
+	// the order handler skips the bad fills, and keeps settling the others.
+	closing := make(chan bool)
+	s.handleOrders(closing)
+	ch := s.orderHandlers[cp]
+	ch <- order.Fill{Order: order.Order{ID: 2, AccountID: "unknown", Type: order.Bid, Price: 100, Amount: 1}, Amount: 1, Price: 100}
+	ch <- order.Fill{Order: ask, Amount: 20, Price: 100}
+	ch <- order.Fill{Order: ask, Amount: 4, Price: 100}
+	for i := 0; i < 100 && asker.GetBalance("skycoin") == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(closing)
+	s.wg.Wait()
+	assert.Equal(t, uint64(400), asker.GetBalance("skycoin"))
+	assert.Equal(t, uint64(6), asker.GetReservedBalance("bitcoin"))
+}
+
+func TestOrderStream(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_stream")
+	account.InitDir(filepath.Join(dir, "account"))
+	order.InitDir(filepath.Join(dir, "orderbook"))
+	defer os.RemoveAll(dir)
+
+	tl, err := trade.NewTradeLog(filepath.Join(dir, "trades.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	cp := "bitcoin/skycoin"
+	s := &ExchangeServer{
+		Manager:       account.NewManager(),
+		orderManager:  order.NewManager(),
+		tradeLog:      tl,
+		stream:        router.NewStream(),
+		orderHandlers: map[string]chan order.Fill{cp: make(chan order.Fill, 100)},
+	}
+	s.orderManager.AddBook(cp, &order.Book{})
+	s.orderManager.RegisterOrderChan(cp, s.orderHandlers[cp])
+
+	bidder, err := s.CreateAccountWithPubkey("bidder")
+	assert.Nil(t, err)
+	bidder.IncreaseBalance("skycoin", 1000, account.ReasonAdmin)
+	asker, err := s.CreateAccountWithPubkey("asker")
+	assert.Nil(t, err)
+	asker.IncreaseBalance("bitcoin", 4, account.ReasonAdmin)
+
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		s.orderManager.Start(100*time.Millisecond, closing)
+		close(done)
+	}()
+	// the books are flushed and the fills settled until the server is stopped, wait before removing the dir.
+	defer func() {
+		close(closing)
+		<-done
+		s.wg.Wait()
+	}()
+	s.handleOrders(closing)
+
+	srv := httptest.NewServer(s.stream)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/stream?pair=" + cp
+	conn, _, err := (&websocket.Dialer{}).Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	readEvent := func() router.StreamEvent {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, d, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ev := router.StreamEvent{}
+		if err := json.Unmarshal(d, &ev); err != nil {
+			t.Fatal(err)
+		}
+		return ev
+	}
+
+	// the subscription is registered after the handshake.
+	for i := 0; i < 100 && s.stream.Subscribers(cp) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	bidID, err := s.AddOrder(cp, order.Order{AccountID: "bidder", Type: order.Bid, Price: 100, Amount: 10, CreatedAt: time.Now().Unix()})
+	assert.Nil(t, err)
+	ev := readEvent()
+	assert.Equal(t, router.EventAdd, ev.Type)
+	assert.Equal(t, bidID, ev.Order.ID)
+	assert.Equal(t, uint64(10), ev.Order.RestAmt)
+
+	// the ask is matched with the bid.
+	_, err = s.AddOrder(cp, order.Order{AccountID: "asker", Type: order.Ask, Price: 100, Amount: 4, CreatedAt: time.Now().Unix()})
+	assert.Nil(t, err)
+	assert.Equal(t, router.EventAdd, readEvent().Type)
+
+	events := map[string]int{}
+	for i := 0; i < 3; i++ {
+		ev := readEvent()
+		events[ev.Type]++
+		if ev.Type == router.EventFill && ev.Order.ID == bidID {
+			assert.Equal(t, uint64(6), ev.Order.RestAmt)
+		}
+	}
+	assert.Equal(t, map[string]int{router.EventFill: 2, router.EventTrade: 1}, events)
+
+	assert.Nil(t, s.CancelOrder(cp, bidID, "bidder"))
+	ev = readEvent()
+	assert.Equal(t, router.EventCancel, ev.Type)
+	assert.Equal(t, bidID, ev.Order.ID)
+}
+
+func TestTradeTape(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_tape")
+	account.InitDir(filepath.Join(dir, "account"))
+	order.InitDir(filepath.Join(dir, "orderbook"))
+	defer os.RemoveAll(dir)
+
+	tl, err := trade.NewTradeLog(filepath.Join(dir, "trades.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	cp := "bitcoin/skycoin"
+	s := &ExchangeServer{
+		Manager:       account.NewManager(),
+		orderManager:  order.NewManager(),
+		tradeLog:      tl,
+		stream:        router.NewStream(),
+		orderHandlers: map[string]chan order.Fill{cp: make(chan order.Fill, 100)},
+	}
+	s.orderManager.AddBook(cp, &order.Book{})
+	s.orderManager.RegisterOrderChan(cp, s.orderHandlers[cp])
+
+	bidder, err := s.CreateAccountWithPubkey("bidder")
+	assert.Nil(t, err)
+	bidder.IncreaseBalance("skycoin", 1000, account.ReasonAdmin)
+	asker, err := s.CreateAccountWithPubkey("asker")
+	assert.Nil(t, err)
+	asker.IncreaseBalance("bitcoin", 4, account.ReasonAdmin)
+
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		s.orderManager.Start(10*time.Millisecond, closing)
+		close(done)
+	}()
+	defer func() {
+		close(closing)
+		<-done
+		s.wg.Wait()
+	}()
+	s.handleOrders(closing)
+
+	srv := httptest.NewServer(http.HandlerFunc(s.stream.ServeTape))
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/trades?pairs=bitcoin/litecoin," + cp
+	conn, _, err := (&websocket.Dialer{}).Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for i := 0; i < 100 && s.stream.TapeSubscribers(cp) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the taker ask is matched with the resting bid.
+	_, err = s.AddOrder(cp, order.Order{AccountID: "bidder", Type: order.Bid, Price: 100, Amount: 10, CreatedAt: time.Now().Unix()})
+	assert.Nil(t, err)
+	_, err = s.AddOrder(cp, order.Order{AccountID: "asker", Type: order.Ask, Price: 90, Amount: 4, CreatedAt: time.Now().Unix()})
+	assert.Nil(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, d, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := router.TapeTrade{}
+	assert.Nil(t, json.Unmarshal(d, &tr))
+	assert.Equal(t, router.EventTrade, tr.Type)
+	assert.Equal(t, cp, tr.Pair)
+	assert.Equal(t, uint64(100), tr.Price)
+	assert.Equal(t, uint64(4), tr.Amount)
+	assert.Equal(t, "ask", tr.Side)
+	assert.True(t, tr.Time > 0)
+}
+
+func TestQuoteAmountOrder(t *testing.T) {
+	// places the bid and the ask of 4 bitcoin at 1.50 as the order api does, with the amounts in
+	// the coin of cur, and returns the balances after they are matched and settled.
+	place := func(cur order.Currency, bidAmt, askAmt uint64) map[string]uint64 {
+		dir := filepath.Join(os.TempDir(), ".server_quote_amount")
+		account.InitDir(filepath.Join(dir, "account"))
+		order.InitDir(filepath.Join(dir, "orderbook"))
+		defer os.RemoveAll(dir)
+
+		tl, err := trade.NewTradeLog(filepath.Join(dir, "trades.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tl.Close()
+
+		cp := "bitcoin/skycoin"
+		s := &ExchangeServer{
+			Manager:       account.NewManager(),
+			orderManager:  order.NewManager(),
+			tradeLog:      tl,
+			orderHandlers: map[string]chan order.Fill{cp: make(chan order.Fill, 100)},
+		}
+		bk := &order.Book{}
+		assert.Nil(t, bk.SetPriceDecimals(2))
+		s.orderManager.AddBook(cp, bk)
+		s.orderManager.RegisterOrderChan(cp, s.orderHandlers[cp])
+
+		bidder, err := s.CreateAccountWithPubkey("bidder")
+		assert.Nil(t, err)
+		bidder.IncreaseBalance("skycoin", 1000, account.ReasonAdmin)
+		asker, err := s.CreateAccountWithPubkey("asker")
+		assert.Nil(t, err)
+		asker.IncreaseBalance("bitcoin", 10, account.ReasonAdmin)
+
+		closing := make(chan bool)
+		done := make(chan struct{})
+		go func() {
+			s.orderManager.Start(10*time.Millisecond, closing)
+			close(done)
+		}()
+		defer func() {
+			close(closing)
+			<-done
+			s.wg.Wait()
+		}()
+		s.handleOrders(closing)
+
+		for _, od := range []order.Order{
+			{AccountID: "bidder", Type: order.Bid, Price: 150, Amount: bidAmt, AmountCurrency: cur, CreatedAt: 1},
+			{AccountID: "asker", Type: order.Ask, Price: 150, Amount: askAmt, AmountCurrency: cur, CreatedAt: 2},
+		} {
+			assert.Nil(t, s.ValidateOrder(cp, od))
+			_, err := s.AddOrder(cp, od)
+			assert.Nil(t, err)
+		}
+
+		for i := 0; i < 500 && (asker.GetReservedBalance("bitcoin") > 0 || bidder.GetBalance("bitcoin") == 0); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		return map[string]uint64{
+			"bidder bitcoin": bidder.GetBalance("bitcoin"),
+			"bidder skycoin": bidder.GetBalance("skycoin"),
+			"asker bitcoin":  asker.GetBalance("bitcoin"),
+			"asker skycoin":  asker.GetBalance("skycoin"),
+			"asker reserved": asker.GetReservedBalance("bitcoin"),
+			"open orders":    uint64(bk.Len()),
+		}
+	}
+
+	// buy and sell 4 bitcoin, or spend and receive 6 skycoin.
+	base := place(order.Base, 4, 4)
+	quote := place(order.Quote, 6, 6)
+	assert.Equal(t, base, quote)
+	assert.Equal(t, uint64(4), base["bidder bitcoin"])
+	assert.Equal(t, uint64(994), base["bidder skycoin"])
+	assert.Equal(t, uint64(6), base["asker bitcoin"])
+	assert.Equal(t, uint64(6), base["asker skycoin"])
+	assert.Equal(t, uint64(0), base["asker reserved"])
+	assert.Equal(t, uint64(0), base["open orders"])
+}
+
+func TestMakerPriceSettlement(t *testing.T) {
+	// places the first order then the second, and returns the skycoin balances of the bidder
+	// and the asker after they are matched and settled.
+	place := func(first, second order.Order) (uint64, uint64) {
+		dir := filepath.Join(os.TempDir(), ".server_maker_price")
+		account.InitDir(filepath.Join(dir, "account"))
+		order.InitDir(filepath.Join(dir, "orderbook"))
+		defer os.RemoveAll(dir)
+
+		tl, err := trade.NewTradeLog(filepath.Join(dir, "trades.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tl.Close()
+
+		cp := "bitcoin/skycoin"
+		s := &ExchangeServer{
+			Manager:       account.NewManager(),
+			orderManager:  order.NewManager(),
+			tradeLog:      tl,
+			orderHandlers: map[string]chan order.Fill{cp: make(chan order.Fill, 100)},
+		}
+		s.orderManager.AddBook(cp, &order.Book{})
+		s.orderManager.RegisterOrderChan(cp, s.orderHandlers[cp])
+
+		bidder, err := s.CreateAccountWithPubkey("bidder")
+		assert.Nil(t, err)
+		bidder.IncreaseBalance("skycoin", 1000, account.ReasonAdmin)
+		asker, err := s.CreateAccountWithPubkey("asker")
+		assert.Nil(t, err)
+		asker.IncreaseBalance("bitcoin", 4, account.ReasonAdmin)
+
+		closing := make(chan bool)
+		done := make(chan struct{})
+		go func() {
+			s.orderManager.Start(10*time.Millisecond, closing)
+			close(done)
+		}()
+		defer func() {
+			close(closing)
+			<-done
+			s.wg.Wait()
+		}()
+		s.handleOrders(closing)
+
+		for _, od := range []order.Order{first, second} {
+			_, err := s.AddOrder(cp, od)
+			assert.Nil(t, err)
+		}
+
+		// the skycoins are conserved once both sides are settled.
+		for i := 0; i < 500 && (bidder.GetBalance("skycoin")+asker.GetBalance("skycoin") != 1000 ||
+			bidder.GetBalance("bitcoin") != 4 || asker.GetReservedBalance("bitcoin") > 0); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(t, uint64(4), bidder.GetBalance("bitcoin"))
+		assert.Equal(t, uint64(0), asker.GetReservedBalance("bitcoin"))
+		return bidder.GetBalance("skycoin"), asker.GetBalance("skycoin")
+	}
+
+	bid := order.Order{AccountID: "bidder", Type: order.Bid, Price: 105, Amount: 4}
+	ask := order.Order{AccountID: "asker", Type: order.Ask, Price: 100, Amount: 4}
+
+	// the resting ask is the maker, the bid is refunded the 20 it reserved beyond the ask price.
+	bid.CreatedAt, ask.CreatedAt = 2, 1
+	bidderSky, askerSky := place(ask, bid)
+	assert.Equal(t, uint64(600), bidderSky)
+	assert.Equal(t, uint64(400), askerSky)
+
+	// the resting bid is the maker, the ask receives the bid price.
+	bid.CreatedAt, ask.CreatedAt = 1, 2
+	bidderSky, askerSky = place(bid, ask)
+	assert.Equal(t, uint64(580), bidderSky)
+	assert.Equal(t, uint64(420), askerSky)
+
+	// the IOC bid takes the resting ask at the ask price, and is refunded as the limit bid.
+	bid.CreatedAt, ask.CreatedAt = 2, 1
+	bid.TimeInForce = order.IOC
+	bidderSky, askerSky = place(ask, bid)
+	assert.Equal(t, uint64(600), bidderSky)
+	assert.Equal(t, uint64(400), askerSky)
+}
+
+func TestGetAccountOrders(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_account_orders")
+	account.InitDir(filepath.Join(dir, "account"))
+	order.InitDir(filepath.Join(dir, "orderbook"))
+	defer os.RemoveAll(dir)
+
+	s := &ExchangeServer{
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+	}
+	s.orderManager.AddBook("bitcoin/skycoin", &order.Book{})
+	s.orderManager.AddBook("litecoin/skycoin", &order.Book{})
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		s.orderManager.Start(time.Hour, closing)
+		close(done)
+	}()
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	for _, id := range []string{"a", "b"} {
+		acnt, err := s.CreateAccountWithPubkey(id)
+		assert.Nil(t, err)
+		acnt.IncreaseBalance("skycoin", 1000, account.ReasonAdmin)
+	}
+	placed := map[string][]uint64{}
+	for i, od := range []struct {
+		cp  string
+		aid string
+	}{
+		{"bitcoin/skycoin", "a"},
+		{"bitcoin/skycoin", "b"},
+		{"litecoin/skycoin", "a"},
+		{"bitcoin/skycoin", "a"},
+	} {
+		id, err := s.AddOrder(od.cp, order.Order{AccountID: od.aid, Type: order.Bid, Price: uint64(100 + i), Amount: 1, CreatedAt: int64(i)})
+		assert.Nil(t, err)
+		placed[od.aid+" "+od.cp] = append(placed[od.aid+" "+od.cp], id)
+	}
+
+	// each account only sees its own orders, the better priced bid first.
+	ods, err := s.GetAccountOrders("a", true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ods))
+	assert.Equal(t, 2, len(ods["bitcoin/skycoin"]))
+	assert.Equal(t, placed["a bitcoin/skycoin"][1], ods["bitcoin/skycoin"][0].ID)
+	assert.Equal(t, placed["a bitcoin/skycoin"][0], ods["bitcoin/skycoin"][1].ID)
+	assert.Equal(t, placed["a litecoin/skycoin"][0], ods["litecoin/skycoin"][0].ID)
+
+	ods, err = s.GetAccountOrders("b", false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ods))
+	assert.Equal(t, 1, len(ods["bitcoin/skycoin"]))
+	assert.Equal(t, "b", ods["bitcoin/skycoin"][0].AccountID)
+
+	// the cancelled order is only listed with the closed orders.
+	assert.Nil(t, s.CancelOrder("bitcoin/skycoin", placed["b bitcoin/skycoin"][0], "b"))
+	ods, err = s.GetAccountOrders("b", true)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ods))
+	ods, err = s.GetAccountOrders("b", false)
+	assert.Nil(t, err)
+	assert.Equal(t, order.StatusClosed, ods["bitcoin/skycoin"][0].Status())
+
+	_, err = s.GetAccountOrders("unknown", false)
+	assert.NotNil(t, err)
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestShutdown(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Server = "127.0.0.1"
+	cfg.Port = freePort(t)
+	cfg.StreamPort = freePort(t)
+	cfg.Seed = "shutdown"
+	cfg.UtxoPoolSize = 10
+	dir, err := ioutil.TempDir("", "skycoin-exchange-shutdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg.DataDir = dir
+	wallet.InitDir(filepath.Join(dir, "wallet"))
+
+	n := runtime.NumGoroutine()
+	s := New(cfg)
+	stopped := make(chan struct{})
+	go func() {
+		s.Run()
+		close(stopped)
+	}()
+
+	// wait until the api server is ready.
+	addr := net.JoinHostPort(cfg.Server, strconv.Itoa(cfg.Port))
+	for i := 0; i < 100; i++ {
+		if c, err := net.Dial("tcp", addr); err == nil {
+			c.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, s.Shutdown(ctx))
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run is not returned after shutdown")
+	}
+
+	// the order books are saved.
+	_, err = os.Stat(filepath.Join(dir, "orderbook", "bitcoin_skycoin.ods"))
+	assert.Nil(t, err)
+
+	// all goroutines are stopped.
+	for i := 0; i < 100 && runtime.NumGoroutine() > n; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, runtime.NumGoroutine() <= n, "goroutines leaked: %d > %d", runtime.NumGoroutine(), n)
+
+	// shutdown again is fine.
+	assert.Nil(t, s.Shutdown(ctx))
+}
+
+func TestIsAdmin(t *testing.T) {
+	admin1 := "02942e46684114b35fe15218dfdc6e0d74af0446a397b8fcbf8b46fb389f756eb8"
+	admin2 := "03a8a8d2c1f3a1b3c2e1d0f9e8d7c6b5a4938271605f4e3d2c1b0a9f8e7d6c5b4a"
+	s := &ExchangeServer{admins: parseAdmins(admin1 + ", " + admin2 + ",")}
+
+	assert.True(t, s.IsAdmin(admin1))
+	assert.True(t, s.IsAdmin(admin2))
+
+	// prefixes, suffixes and substrings of admin keys are rejected.
+	for _, k := range []string{
+		"",
+		",",
+		admin1[:10],
+		admin1[:len(admin1)-1],
+		admin1[1:],
+		admin1[20:40],
+		admin1 + "," + admin2,
+		admin1[60:] + "," + admin2[:5],
+	} {
+		assert.False(t, s.IsAdmin(k), "%q is not admin", k)
+	}
+
+	// no admin is configured.
+	s = &ExchangeServer{admins: parseAdmins("")}
+	assert.False(t, s.IsAdmin(""))
+	assert.False(t, s.IsAdmin(admin1))
+}
+
+func TestAdminAccount(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_admin_account")
+	account.InitDir(filepath.Join(dir, "account"))
+	order.InitDir(filepath.Join(dir, "orderbook"))
+	defer os.RemoveAll(dir)
+
+	cp := "bitcoin/skycoin"
+	s := &ExchangeServer{
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+	}
+	// the bid of user has paid its coins, only the order remains.
+	bk := &order.Book{}
+	bk.AddBid(order.Order{ID: 1, AccountID: "user", Type: order.Bid, Price: 100, Amount: 10, RestAmt: 10})
+	s.orderManager.AddBook(cp, bk)
+
+	id, err := s.CreateAccount("user")
+	assert.Nil(t, err)
+	assert.Equal(t, "user", id)
+	_, err = s.GetAccount("user")
+	assert.Nil(t, err)
+
+	// duplicate creation.
+	_, err = s.CreateAccount("user")
+	assert.NotNil(t, err)
+
+	// account with balance can't be deleted.
+	acnt, _ := s.GetAccount("user")
+	acnt.IncreaseBalance("bitcoin", 10, account.ReasonAdmin)
+	assert.NotNil(t, s.DeleteAccount("user"))
+
+	// account with reserved balance can't be deleted.
+	assert.Nil(t, acnt.ReserveBalance("bitcoin", 10, account.ReasonOrder))
+	assert.NotNil(t, s.DeleteAccount("user"))
+	assert.Nil(t, acnt.DecreaseReservedBalance("bitcoin", 10, account.ReasonTrade))
+
+	// account with open orders can't be deleted.
+	assert.NotNil(t, s.DeleteAccount("user"))
+	_, err = s.orderManager.CancelOrder(cp, 1, "user")
+	assert.Nil(t, err)
+
+	assert.Nil(t, s.DeleteAccount("user"))
+	_, err = s.GetAccount("user")
+	assert.NotNil(t, err)
+
+	// the deletion is saved.
+	m, err := account.LoadManager()
+	assert.Nil(t, err)
+	_, err = m.GetAccount("user")
+	assert.NotNil(t, err)
+
+	// unknown account.
+	assert.NotNil(t, s.DeleteAccount("user"))
+}
+
+func TestCreditDeposit(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_deposit")
+	account.InitDir(filepath.Join(dir, "account"))
+	defer os.RemoveAll(dir)
+
+	s := &ExchangeServer{Manager: account.NewManager()}
+	acnt, err := s.CreateAccountWithPubkey("user")
+	assert.Nil(t, err)
+	assert.Nil(t, s.BindDepositAddress("bitcoin", "addr1", "user"))
+
+	s.creditDeposit("bitcoin", "addr1", "txid:0", 100)
+	assert.Equal(t, uint64(100), acnt.GetBalance("bitcoin"))
+
+	// the same deposit is reported again after restarting the utxo manager.
+	s.creditDeposit("bitcoin", "addr1", "txid:0", 100)
+	assert.Equal(t, uint64(100), acnt.GetBalance("bitcoin"))
+
+	// utxos of addresses not belonging to any account are ignored.
+	s.creditDeposit("bitcoin", "change", "txid:1", 100)
+	assert.Equal(t, uint64(100), acnt.GetBalance("bitcoin"))
+
+	// the credited deposit is saved.
+	m, err := account.LoadManager()
+	assert.Nil(t, err)
+	a, err := m.GetAccountByAddress("bitcoin", "addr1")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), a.GetBalance("bitcoin"))
+	assert.Equal(t, account.ErrDepositCredited, a.CreditDeposit("bitcoin", "txid:0", 100))
+}
+
+func TestCreditLitecoinDeposit(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_ltc_deposit")
+	account.InitDir(filepath.Join(dir, "account"))
+	order.InitDir(filepath.Join(dir, "orderbook"))
+	defer os.RemoveAll(dir)
+
+	cp := "litecoin/skycoin"
+	s := &ExchangeServer{
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+	}
+	assert.Nil(t, s.orderManager.AddBook(cp, &order.Book{}))
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		s.orderManager.Start(time.Hour, closing)
+		close(done)
+	}()
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	acnt, err := s.CreateAccountWithPubkey("user")
+	assert.Nil(t, err)
+	_, err = s.CreateAccountWithPubkey("other")
+	assert.Nil(t, err)
+	assert.Nil(t, s.BindDepositAddress(litecoin.Type, "ltcaddr", "user"))
+
+	// the account has no litecoin balance until the first deposit.
+	s.creditDeposit(litecoin.Type, "ltcaddr", "txid:0", 100)
+	assert.Equal(t, uint64(100), acnt.GetBalance(litecoin.Type))
+
+	// the credited litecoin can be reserved by the ask and transferred.
+	_, err = s.AddOrder(cp, order.Order{AccountID: "user", Type: order.Ask, Price: 10, Amount: 60, CreatedAt: 1})
+	assert.Nil(t, err)
+	assert.Nil(t, s.TransferBalance("user", "other", litecoin.Type, 40))
+	bals, err := s.GetAccountBalances("user")
+	assert.Nil(t, err)
+	assert.Equal(t, account.Balance{Available: 0, Reserved: 60}, bals[litecoin.Type])
+	bals, err = s.GetAccountBalances("other")
+	assert.Nil(t, err)
+	assert.Equal(t, account.Balance{Available: 40}, bals[litecoin.Type])
+
+	// the balance is saved.
+	m, err := account.LoadManager()
+	assert.Nil(t, err)
+	a, err := m.GetAccountByAddress(litecoin.Type, "ltcaddr")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(60), a.GetReservedBalance(litecoin.Type))
+}
+
+func TestReverseDeposit(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_reorg")
+	account.InitDir(filepath.Join(dir, "account"))
+	defer os.RemoveAll(dir)
+
+	s := &ExchangeServer{Manager: account.NewManager()}
+	acnt, err := s.CreateAccountWithPubkey("user")
+	assert.Nil(t, err)
+	assert.Nil(t, s.BindDepositAddress("bitcoin", "addr1", "user"))
+
+	s.creditDeposit("bitcoin", "addr1", "txid:0", 100)
+	s.creditDeposit("bitcoin", "addr1", "txid:1", 200)
+	assert.Equal(t, uint64(300), acnt.GetBalance("bitcoin"))
+
+	// the orphaned deposit is debited and recorded in the ledger.
+	s.reverseDeposit("bitcoin", "addr1", "txid:0", 100)
+	assert.Equal(t, uint64(200), acnt.GetBalance("bitcoin"))
+	entries := acnt.GetLedger("bitcoin", 0, time.Now().Unix())
+	last := entries[len(entries)-1]
+	assert.Equal(t, account.ReasonDepositReversal, last.Reason)
+	assert.Equal(t, int64(-100), last.Delta)
+	assert.False(t, s.IsFrozen("user"))
+
+	// reversed only once, and the uncredited deposit is ignored.
+	s.reverseDeposit("bitcoin", "addr1", "txid:0", 100)
+	s.reverseDeposit("bitcoin", "addr1", "txid:2", 100)
+	s.reverseDeposit("bitcoin", "change", "txid:1", 200)
+	assert.Equal(t, uint64(200), acnt.GetBalance("bitcoin"))
+
+	// the coins already spent can't be debited, the account is frozen.
+	assert.Nil(t, acnt.DecreaseBalance("bitcoin", 150, account.ReasonWithdraw))
+	s.reverseDeposit("bitcoin", "addr1", "txid:1", 200)
+	assert.Equal(t, uint64(0), acnt.GetBalance("bitcoin"))
+	assert.True(t, s.IsFrozen("user"))
+
+	// the reversal is saved.
+	m, err := account.LoadManager()
+	assert.Nil(t, err)
+	a, err := m.GetAccountByAddress("bitcoin", "addr1")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), a.GetBalance("bitcoin"))
+	assert.Nil(t, a.CreditDeposit("bitcoin", "txid:0", 100))
+}
+
+func TestGetSupportedCoinsInfo(t *testing.T) {
+	s := &ExchangeServer{coins: make(map[string]coin.Gateway)}
+	assert.Empty(t, s.GetSupportedCoinsInfo())
+
+	assert.Nil(t, s.BindCoins(&bitcoin.Bitcoin{}, litecoin.New(), skycoin.New(""), mzcoin.New("")))
+	infos := s.GetSupportedCoinsInfo()
+	assert.Equal(t, len(s.GetSupportCoins()), len(infos))
+
+	expect := []coin.Info{
+		{Symbol: "BTC", Name: "Bitcoin", Type: bitcoin.Type, Decimals: 8, MinAmount: 546, AddressFormat: "base58check, bech32"},
+		{Symbol: "LTC", Name: "Litecoin", Type: litecoin.Type, Decimals: 8, MinAmount: 5460, AddressFormat: "base58check"},
+		{Symbol: "MZC", Name: "Mzcoin", Type: mzcoin.Type, Decimals: 6, MinAmount: 1e6, AddressFormat: "base58check"},
+		{Symbol: "SKY", Name: "Skycoin", Type: skycoin.Type, Decimals: 6, MinAmount: 1e6, AddressFormat: "base58check"},
+	}
+	assert.Equal(t, expect, infos)
+
+	// the info matches the registered gateway.
+	for _, info := range infos {
+		gw, err := s.GetCoin(info.Type)
+		assert.Nil(t, err)
+		assert.Equal(t, gw.Symbol(), info.Symbol)
+		assert.Equal(t, gw.Decimals(), info.Decimals)
+	}
+}
+
+func TestBindCoinsConcurrent(t *testing.T) {
+	s := &ExchangeServer{coins: make(map[string]coin.Gateway)}
+	assert.Nil(t, s.BindCoins(healthGateway{tp: skycoin.Type}))
+
+	// run with -race to detect the unprotected accesses.
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			assert.Nil(t, s.BindCoins(healthGateway{tp: fmt.Sprintf("coin%d", i)}))
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := s.GetCoin(skycoin.Type)
+			assert.Nil(t, err)
+			s.GetSupportCoins()
+			s.GetSupportedCoinsInfo()
+			s.HealthCheck()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 21, len(s.coinList()))
+	for i := 0; i < 20; i++ {
+		_, err := s.GetCoin(fmt.Sprintf("coin%d", i))
+		assert.Nil(t, err)
+	}
+	assert.NotNil(t, s.BindCoins(healthGateway{tp: "coin0"}))
+}
+
+// healthGateway mocks the health check of coin gateway.
+type healthGateway struct {
+	coin.Gateway
+	tp  string
+	err error
+}
+
+func (g healthGateway) Type() string { return g.tp }
+
+func (g healthGateway) Symbol() string { return strings.ToUpper(g.tp) }
+
+func (g healthGateway) Decimals() int { return 8 }
+
+func (g healthGateway) HealthCheck() (bool, error) { return g.err == nil, g.err }
+
+// nodeStatusUtxoManager mocks the node status of skycoin utxo manager.
+type nodeStatusUtxoManager struct {
+	skycoin.UtxoManager
+	status skycoin.NodeStatus
+}
+
+func (um nodeStatusUtxoManager) Status() skycoin.NodeStatus { return um.status }
+
+func TestHealthCheck(t *testing.T) {
+	skyum := &nodeStatusUtxoManager{status: skycoin.NodeStatus{Healthy: true}}
+	s := &ExchangeServer{coins: make(map[string]coin.Gateway), skyum: skyum}
+	assert.Nil(t, s.BindCoins(
+		healthGateway{tp: skycoin.Type},
+		healthGateway{tp: bitcoin.Type, err: errors.New("connection refused")},
+		healthGateway{tp: litecoin.Type},
+	))
+
+	expect := []coin.Health{
+		{Type: bitcoin.Type, Error: "connection refused"},
+		{Type: litecoin.Type, Healthy: true},
+		{Type: skycoin.Type, Healthy: true},
+	}
+	assert.Equal(t, expect, s.HealthCheck())
+
+	// the skycoin node is down, and the utxo manager is reconnecting.
+	skyum.status = skycoin.NodeStatus{Error: "get outputs failed", Retries: 2}
+	hs := s.HealthCheck()
+	assert.Equal(t, coin.Health{Type: skycoin.Type, Error: "reconnecting node: get outputs failed"}, hs[2])
+}
+
+func TestDepositScanning(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_deposit_scan")
+	account.InitDir(filepath.Join(dir, "account"))
+	defer os.RemoveAll(dir)
+
+	addrA, addrB := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6"
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]bitcoin.BlkExplrUtxo{
+			{Address: addrB, Txid: fmt.Sprintf("%064x", 1), Amount: 100, Confirms: 2},
+			{Address: addrA, Txid: fmt.Sprintf("%064x", 2), Amount: 300, Confirms: 0},
+			{Address: "change", Txid: fmt.Sprintf("%064x", 3), Amount: 500, Confirms: 6},
+		})
+	}))
+	defer node.Close()
+	api := bitcoin.BlkExplrAPI
+	bitcoin.BlkExplrAPI = node.URL + "/api"
+	defer func() { bitcoin.BlkExplrAPI = api }()
+
+	tick := insight.CheckTick
+	insight.CheckTick = 10 * time.Millisecond
+	defer func() { insight.CheckTick = tick }()
+
+	btcum := bitcoin.NewUtxoManager(10, []string{addrA, addrB, "change"})
+	btcum.SetMinConfirmations(1)
+	s := &ExchangeServer{
+		Manager: account.NewManager(),
+		btcum:   btcum,
+		skyum:   skycoin.NewUtxoManager("", 10, []string{}),
+		ltcum:   litecoin.NewUtxoManager(10, []string{}),
+	}
+	s.setDepositHandlers()
+
+	a, err := s.CreateAccountWithPubkey("a")
+	assert.Nil(t, err)
+	b, err := s.CreateAccountWithPubkey("b")
+	assert.Nil(t, err)
+	assert.Nil(t, s.BindDepositAddress(bitcoin.Type, addrA, "a"))
+	assert.Nil(t, s.BindDepositAddress(bitcoin.Type, addrB, "b"))
+
+	closing := make(chan bool)
+	done := make(chan bool)
+	go func() {
+		btcum.Start(closing)
+		close(done)
+	}()
+	// the scanning uses the api, wait for it to stop before the api is restored.
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for b.GetBalance(bitcoin.Type) == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("deposit is not credited")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	// only the owner of the deposit address is credited, and the unconfirmed deposit is not.
+	assert.Equal(t, uint64(100), b.GetBalance(bitcoin.Type))
+	assert.Equal(t, uint64(0), a.GetBalance(bitcoin.Type))
+
+	// the mapping and the credited balance are saved.
+	m, err := account.LoadManager()
+	assert.Nil(t, err)
+	owner, err := m.GetAccountByAddress(bitcoin.Type, addrB)
+	assert.Nil(t, err)
+	assert.Equal(t, "b", owner.GetID())
+	assert.Equal(t, uint64(100), owner.GetBalance(bitcoin.Type))
+}
+
+func TestAddCoinPair(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_coin_pair")
+	account.InitDir(filepath.Join(dir, "account"))
+	order.InitDir(filepath.Join(dir, "orderbook"))
+	defer os.RemoveAll(dir)
+
+	tl, err := trade.NewTradeLog(filepath.Join(dir, "trades.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.Close()
+
+	s := &ExchangeServer{
+		Manager:       account.NewManager(),
+		orderManager:  order.NewManager(),
+		tradeLog:      tl,
+		coins:         make(map[string]coin.Gateway),
+		closing:       make(chan bool),
+		orderHandlers: make(map[string]chan order.Fill),
+	}
+	assert.Nil(t, s.BindCoins(healthGateway{tp: bitcoin.Type}, healthGateway{tp: skycoin.Type}))
+
+	// start the server like Run does.
+	assert.Nil(t, s.AddCoinPair("bitcoin/skycoin"))
+	s.goWait(func() { s.orderManager.Start(100*time.Millisecond, s.closing) })
+	s.handleOrders(s.closing)
+	s.running = true
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		assert.Nil(t, s.Shutdown(ctx))
+	}()
+
+	// the pair is added while the server is running.
+	cp := "skycoin/bitcoin"
+	assert.Nil(t, s.AddCoinPair(cp))
+	assert.Equal(t, []string{"bitcoin/skycoin", "skycoin/bitcoin"}, s.orderManager.Pairs())
+
+	// duplicate, malformed pairs and unregistered coins are rejected.
+	for _, p := range []string{cp, "skycoin", "skycoin/", "/skycoin", "skycoin/skycoin", "a/b/c", "litecoin/skycoin"} {
+		assert.NotNil(t, s.AddCoinPair(p), p)
+	}
+
+	// the book is persisted.
+	_, err = os.Stat(filepath.Join(dir, "orderbook", "skycoin_bitcoin.ods"))
+	assert.Nil(t, err)
+
+	bidder, err := s.CreateAccountWithPubkey("bidder")
+	assert.Nil(t, err)
+	bidder.IncreaseBalance(bitcoin.Type, 50, account.ReasonAdmin)
+	asker, err := s.CreateAccountWithPubkey("asker")
+	assert.Nil(t, err)
+	asker.IncreaseBalance(skycoin.Type, 5, account.ReasonAdmin)
+
+	_, err = s.AddOrder(cp, order.Order{AccountID: "bidder", Type: order.Bid, Price: 10, Amount: 5, CreatedAt: time.Now().Unix()})
+	assert.Nil(t, err)
+	_, err = s.AddOrder(cp, order.Order{AccountID: "asker", Type: order.Ask, Price: 10, Amount: 5, CreatedAt: time.Now().Unix()})
+	assert.Nil(t, err)
+
+	// the orders are matched and settled.
+	for i := 0; i < 100 && asker.GetBalance(bitcoin.Type) == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Equal(t, uint64(5), bidder.GetBalance(skycoin.Type))
+	assert.Equal(t, uint64(50), asker.GetBalance(bitcoin.Type))
+	assert.Equal(t, uint64(0), asker.GetReservedBalance(skycoin.Type))
+}
+
+func TestTransferBalance(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_transfer")
+	account.InitDir(filepath.Join(dir, "account"))
+	defer os.RemoveAll(dir)
+
+	s := &ExchangeServer{Manager: account.NewManager()}
+	a, err := s.CreateAccountWithPubkey("a")
+	assert.Nil(t, err)
+	b, err := s.CreateAccountWithPubkey("b")
+	assert.Nil(t, err)
+	a.IncreaseBalance("skycoin", 100, account.ReasonAdmin)
+
+	assert.Nil(t, s.TransferBalance("a", "b", "skycoin", 30))
+	assert.Equal(t, uint64(70), a.GetBalance("skycoin"))
+	assert.Equal(t, uint64(30), b.GetBalance("skycoin"))
+
+	assert.NotNil(t, s.TransferBalance("a", "b", "skycoin", 71))
+	assert.NotNil(t, s.TransferBalance("a", "a", "skycoin", 1))
+
+	// the transfer is saved.
+	m, err := account.LoadManager()
+	assert.Nil(t, err)
+	lb, err := m.GetAccount("b")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(30), lb.GetBalance("skycoin"))
+}
+
+func TestGetAccountBalances(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_balances")
+	account.InitDir(filepath.Join(dir, "account"))
+	order.InitDir(filepath.Join(dir, "orderbook"))
+	defer os.RemoveAll(dir)
+
+	cp := "bitcoin/skycoin"
+	s := &ExchangeServer{
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+	}
+	s.orderManager.AddBook(cp, &order.Book{})
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		s.orderManager.Start(time.Hour, closing)
+		close(done)
+	}()
+	// the books are flushed once the manager is stopped, wait before removing the dir.
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	a, err := s.CreateAccountWithPubkey("test")
+	assert.Nil(t, err)
+	a.IncreaseBalance("bitcoin", 100, account.ReasonAdmin)
+	a.IncreaseBalance("skycoin", 5000, account.ReasonAdmin)
+
+	// the ask reserves the bitcoin, and the bid pays the skycoin.
+	askID, err := s.AddOrder(cp, *order.New("test", order.Ask, 200, 30))
+	assert.Nil(t, err)
+	_, err = s.AddOrder(cp, *order.New("test", order.Bid, 100, 10))
+	assert.Nil(t, err)
+
+	bals, err := s.GetAccountBalances("test")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]account.Balance{
+		"bitcoin": {Available: 70, Reserved: 30},
+		"skycoin": {Available: 4000, Reserved: 0},
+	}, bals)
+
+	// the reserved balance is released once the ask is cancelled.
+	assert.Nil(t, s.CancelOrder(cp, askID, "test"))
+	bals, err = s.GetAccountBalances("test")
+	assert.Nil(t, err)
+	assert.Equal(t, account.Balance{Available: 100, Reserved: 0}, bals["bitcoin"])
+
+	_, err = s.GetAccountBalances("unknown")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	litecoin "github.com/skycoin/skycoin-exchange/src/coin/litecoin"
+	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
+)
+
+// SweepResult the consolidating transaction made by SweepWallet.
+type SweepResult struct {
+	Txid    string // txid of the broadcasted transaction.
+	Address string // address receiving the swept coins.
+	Inputs  int    // number of the utxos spent.
+	Fee     uint64 // transaction fee.
+	Amount  uint64 // coins of the only output, which is the total of inputs minus the fee.
+}
+
+// SweepWallet consolidates all the available utxos of the server wallet of specific coin type
+// into one output paid to toAddr, so that the later withdrawals spend fewer inputs. The coins
+// are sent to a new address of the default wallet if toAddr is empty. No account balance is
+// changed, the sweep is rejected if the output would be below the dust limit of the coin,
+// and the utxos are put back if any step fails.
+func (self *ExchangeServer) SweepWallet(cp, toAddr string) (SweepResult, error) {
+	if toAddr != "" {
+		if err := validateWithdrawAddr(cp, toAddr); err != nil {
+			return SweepResult{}, err
+		}
+	}
+
+	gateway, err := self.GetCoin(cp)
+	if err != nil {
+		return SweepResult{}, err
+	}
+
+	stats, err := self.GetUtxoStats(cp)
+	if err != nil {
+		return SweepResult{}, err
+	}
+
+	if stats.Available == 0 {
+		return SweepResult{}, fmt.Errorf("no available %s utxos to sweep", cp)
+	}
+
+	utxos, err := self.ChooseUtxos(cp, stats.TotalValue, WithdrawUtxoTm)
+	if err != nil {
+		return SweepResult{}, err
+	}
+
+	var success bool
+	defer func() {
+		if !success {
+			self.PutUtxos(cp, utxos)
+		}
+	}()
+
+	n, total, ins := sweepInputs(cp, utxos)
+	fee, err := self.sweepFee(cp, gateway, n, ins, toAddr)
+	if err != nil {
+		return SweepResult{}, err
+	}
+
+	if total < fee || total-fee < coinMeta[cp].MinAmount {
+		return SweepResult{}, fmt.Errorf("%s sweep of %d utxos totals %d, which is below the dust limit %d after fee %d",
+			cp, n, total, coinMeta[cp].MinAmount, fee)
+	}
+	amount := total - fee
+
+	var watch bool
+	if toAddr == "" {
+		toAddr, err = self.GetNewAddress(cp, "")
+		if err != nil {
+			return SweepResult{}, err
+		}
+		watch = true
+	}
+
+	// the inputs are all spent by the output and fee, so there's no change.
+	txIns, txOuts, _, err := self.makeWithdrawTx(cp, utxos, toAddr, amount, fee)
+	if err != nil {
+		return SweepResult{}, err
+	}
+
+	rawtx, err := gateway.CreateRawTx(txIns, txOuts)
+	if err != nil {
+		return SweepResult{}, fmt.Errorf("create %s raw tx failed: %v", cp, err)
+	}
+
+	rawtx, err = gateway.SignRawTx(rawtx, func(addr string) (string, error) {
+		return self.GetAddrPrivKey(cp, addr)
+	})
+	if err != nil {
+		return SweepResult{}, fmt.Errorf("sign %s raw tx failed: %v", cp, err)
+	}
+
+	txid, err := coin.BroadcastTx(gateway, rawtx, self.cfg.BroadcastRetries, self.cfg.BroadcastBackoff)
+	if err != nil {
+		return SweepResult{}, fmt.Errorf("broadcast %s tx failed: %w", cp, err)
+	}
+
+	success = true
+	logger.Debug("swept %d %s utxos into %s, amount:%d fee:%d txid:%s", n, cp, toAddr, amount, fee, txid)
+	if watch {
+		self.WatchAddress(cp, toAddr)
+	}
+
+	return SweepResult{
+		Txid:    txid,
+		Address: toAddr,
+		Inputs:  n,
+		Fee:     fee,
+		Amount:  amount,
+	}, nil
+}
+
+// sweepInputs returns the number and total coins of the chosen utxos, and the script
+// types of the inputs if they're bitcoin utxos.
+func sweepInputs(cp string, utxos interface{}) (int, uint64, []bitcoin.ScriptType) {
+	var total uint64
+	switch cp {
+	case bitcoin.Type:
+		uxs := utxos.([]bitcoin.Utxo)
+		ins := make([]bitcoin.ScriptType, len(uxs))
+		for i, u := range uxs {
+			ins[i] = bitcoin.AddrScriptType(u.GetAddress())
+			total += u.GetAmount()
+		}
+		return len(uxs), total, ins
+	case litecoin.Type:
+		uxs := utxos.([]litecoin.Utxo)
+		for _, u := range uxs {
+			total += u.GetAmount()
+		}
+		return len(uxs), total, nil
+	case skycoin.Type:
+		uxs := utxos.([]skycoin.Utxo)
+		for _, u := range uxs {
+			total += u.GetCoins()
+		}
+		return len(uxs), total, nil
+	}
+	return 0, 0, nil
+}
+
+// sweepFee returns the fee of the transaction spending n inputs to one output, bitcoin fee is
+// estimated by the script types of inputs at Config.BtcFeeRate, and the flat Config.BtcFee is
+// used if the rate is not set, other coins' fee is estimated by the coin gateway.
+func (self *ExchangeServer) sweepFee(cp string, gateway coin.Gateway, n int, ins []bitcoin.ScriptType, toAddr string) (uint64, error) {
+	if cp != bitcoin.Type {
+		return gateway.EstimateFee(n, 1)
+	}
+
+	if self.cfg.BtcFeeRate == 0 {
+		return self.GetBtcFee(), nil
+	}
+
+	// the new address of server wallet is a legacy one.
+	out := bitcoin.P2PKH
+	if toAddr != "" {
+		out = bitcoin.AddrScriptType(toAddr)
+	}
+	return bitcoin.EstimateFeeWithRate(ins, []bitcoin.ScriptType{out}, self.cfg.BtcFeeRate)
+}
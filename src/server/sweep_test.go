@@ -0,0 +1,147 @@
+package server
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newSweepTestServer makes the server whose bitcoin wallet has n utxos of amt satoshis.
+func newSweepTestServer(t *testing.T, gw coin.Gateway, cfg Config, n int, amt uint64) (*ExchangeServer, func()) {
+	dir := filepath.Join(os.TempDir(), ".server_sweep")
+	account.InitDir(filepath.Join(dir, "account"))
+	wallet.InitDir(filepath.Join(dir, "wallet"))
+	wlts, err := makeWallets(filepath.Join(dir, "wallet"), []walletItem{{bitcoin.Type, DefaultWallet, "seed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &ExchangeServer{
+		cfg:     cfg,
+		Manager: account.NewManager(),
+		btcum:   bitcoin.NewUtxoManager(n, []string{}),
+		wallets: wlts,
+		coins:   map[string]coin.Gateway{bitcoin.Type: gw},
+	}
+
+	for i := 0; i < n; i++ {
+		s.btcum.PutUtxo(bitcoin.BlkExplrUtxo{
+			Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+			Txid:    "txid",
+			Vout:    uint32(i),
+			Amount:  amt,
+		})
+	}
+	return s, func() { os.RemoveAll(dir) }
+}
+
+func TestSweepWallet(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("sweeptxid", nil)
+
+	s, teardown := newSweepTestServer(t, gw, Config{BtcFeeRate: 10}, 50, 2000)
+	defer teardown()
+
+	toAddr := "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"
+	r, err := s.SweepWallet(bitcoin.Type, toAddr)
+	assert.Nil(t, err)
+
+	ins := make([]bitcoin.ScriptType, 50)
+	for i := range ins {
+		ins[i] = bitcoin.P2PKH
+	}
+	fee, err := bitcoin.EstimateFeeWithRate(ins, []bitcoin.ScriptType{bitcoin.P2WPKH}, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, SweepResult{Txid: "sweeptxid", Address: toAddr, Inputs: 50, Fee: fee, Amount: 100000 - fee}, r)
+
+	// one transaction spends all the utxos to a single output.
+	gw.AssertNumberOfCalls(t, "CreateRawTx", 1)
+	txIns := gw.Calls[0].Arguments.Get(0).([]coin.TxIn)
+	assert.Equal(t, 50, len(txIns))
+	txOuts := gw.Calls[0].Arguments.Get(1).([]bitcoin.TxOut)
+	assert.Equal(t, []bitcoin.TxOut{{Addr: toAddr, Value: 100000 - fee}}, txOuts)
+
+	// the pool is drained.
+	_, err = s.ChooseUtxos(bitcoin.Type, 1, 100*time.Millisecond)
+	assert.True(t, errors.Is(err, coin.ErrUtxoTimeout))
+
+	_, err = s.SweepWallet(bitcoin.Type, toAddr)
+	assert.NotNil(t, err)
+	gw.AssertNumberOfCalls(t, "CreateRawTx", 1)
+}
+
+func TestSweepWalletNewAddress(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("sweeptxid", nil)
+
+	s, teardown := newSweepTestServer(t, gw, Config{BtcFee: 1000}, 20, 1000)
+	defer teardown()
+
+	// the coins are sent to a new address of server wallet, the flat fee is used.
+	r, err := s.SweepWallet(bitcoin.Type, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 20, r.Inputs)
+	assert.Equal(t, uint64(1000), r.Fee)
+	assert.Equal(t, uint64(19000), r.Amount)
+
+	txOuts := gw.Calls[0].Arguments.Get(1).([]bitcoin.TxOut)
+	assert.Equal(t, []bitcoin.TxOut{{Addr: r.Address, Value: 19000}}, txOuts)
+}
+
+func TestSweepWalletDust(t *testing.T) {
+	gw := &gatewayMock{}
+	s, teardown := newSweepTestServer(t, gw, Config{BtcFee: 10000}, 20, 500)
+	defer teardown()
+
+	// the 10000 satoshis left nothing after fee.
+	_, err := s.SweepWallet(bitcoin.Type, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH")
+	assert.NotNil(t, err)
+
+	// the output would be below the dust limit.
+	s.cfg.BtcFee = 9500
+	_, err = s.SweepWallet(bitcoin.Type, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH")
+	assert.NotNil(t, err)
+	gw.AssertNotCalled(t, "CreateRawTx", mock.Anything, mock.Anything)
+
+	// the utxos are put back.
+	uxs, err := s.ChooseUtxos(bitcoin.Type, 10000, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, len(uxs.([]bitcoin.Utxo)))
+}
+
+func TestSweepWalletBroadcastFailure(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("", errors.New("broadcast failed"))
+
+	s, teardown := newSweepTestServer(t, gw, Config{BtcFee: 1000}, 10, 1000)
+	defer teardown()
+
+	_, err := s.SweepWallet(bitcoin.Type, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH")
+	assert.NotNil(t, err)
+
+	// the utxos are put back.
+	uxs, err := s.ChooseUtxos(bitcoin.Type, 10000, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(uxs.([]bitcoin.Utxo)))
+
+	// invalid address and unsupported coin.
+	_, err = s.SweepWallet(bitcoin.Type, "invalid")
+	assert.NotNil(t, err)
+	_, err = s.SweepWallet("unknown", "")
+	assert.NotNil(t, err)
+}
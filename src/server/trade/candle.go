@@ -0,0 +1,123 @@
+package trade
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxCandles max candles returned by one query.
+const MaxCandles = 1000
+
+// Intervals the supported candle intervals, key: interval name used by api.
+var Intervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// Candle is the OHLCV of the trades in one interval, Time is the unix time of the
+// interval start, which is the multiple of the interval.
+type Candle struct {
+	Time   int64  `json:"time"`
+	Open   uint64 `json:"open"`
+	High   uint64 `json:"high"`
+	Low    uint64 `json:"low"`
+	Close  uint64 `json:"close"`
+	Volume uint64 `json:"volume"` // total traded amount.
+}
+
+// GetCandles aggregates the trades of specific coin pair in time range [start, end] into candles
+// of interval, start is aligned down to the interval. The intervals without trades are omitted,
+// unless fill is true, then they are carried forward with the close price of the previous candle
+// and zero volume.
+func (tl *TradeLog) GetCandles(cp string, interval time.Duration, start, end int64, fill bool) ([]Candle, error) {
+	secs, err := intervalSecs(interval)
+	if err != nil {
+		return []Candle{}, err
+	}
+
+	if start > end {
+		return []Candle{}, fmt.Errorf("start time %d is greater than end time %d", start, end)
+	}
+
+	start = alignTime(start, secs)
+	if n := (end-start)/secs + 1; n > MaxCandles {
+		return []Candle{}, fmt.Errorf("%d candles exceed the max %d", n, MaxCandles)
+	}
+
+	trades, err := tl.Query(cp, start, end)
+	if err != nil {
+		return []Candle{}, err
+	}
+	return MakeCandles(trades, interval, start, end, fill), nil
+}
+
+// MakeCandles aggregates the trades, which are in the order of time, into candles of interval
+// in time range [start, end], the interval must be positive whole seconds.
+func MakeCandles(trades []Trade, interval time.Duration, start, end int64, fill bool) []Candle {
+	secs := int64(interval / time.Second)
+	candles := []Candle{}
+	for _, t := range trades {
+		if t.Time < start || t.Time > end {
+			continue
+		}
+
+		tm := alignTime(t.Time, secs)
+		if n := len(candles); n > 0 && candles[n-1].Time == tm {
+			c := &candles[n-1]
+			if t.Price > c.High {
+				c.High = t.Price
+			}
+			if t.Price < c.Low {
+				c.Low = t.Price
+			}
+			c.Close = t.Price
+			c.Volume += t.Amount
+			continue
+		}
+
+		// carry forward the close price into the empty intervals since the previous candle.
+		if n := len(candles); fill && n > 0 {
+			p := candles[n-1].Close
+			for ct := candles[n-1].Time + secs; ct < tm; ct += secs {
+				candles = append(candles, Candle{Time: ct, Open: p, High: p, Low: p, Close: p})
+			}
+		}
+
+		candles = append(candles, Candle{
+			Time:   tm,
+			Open:   t.Price,
+			High:   t.Price,
+			Low:    t.Price,
+			Close:  t.Price,
+			Volume: t.Amount,
+		})
+	}
+
+	// the intervals after the last trade.
+	if n := len(candles); fill && n > 0 {
+		p := candles[n-1].Close
+		for ct := candles[n-1].Time + secs; ct <= end; ct += secs {
+			candles = append(candles, Candle{Time: ct, Open: p, High: p, Low: p, Close: p})
+		}
+	}
+	return candles
+}
+
+// intervalSecs returns the interval in seconds, the interval must be positive whole seconds.
+func intervalSecs(interval time.Duration) (int64, error) {
+	if interval < time.Second || interval%time.Second != 0 {
+		return 0, fmt.Errorf("invalid candle interval %v", interval)
+	}
+	return int64(interval / time.Second), nil
+}
+
+// alignTime returns the start of the interval which unix time tm falls in.
+func alignTime(tm, secs int64) int64 {
+	r := tm % secs
+	if r < 0 {
+		r += secs
+	}
+	return tm - r
+}
@@ -0,0 +1,93 @@
+package trade
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Trade records an execution between the maker and taker orders.
+type Trade struct {
+	Pair         string `json:"pair"`
+	Price        uint64 `json:"price"`
+	Amount       uint64 `json:"amount"`
+	Maker        string `json:"maker"` // maker account id
+	Taker        string `json:"taker"` // taker account id
+	MakerOrderID uint64 `json:"maker_order_id"`
+	TakerOrderID uint64 `json:"taker_order_id"`
+	Time         int64  `json:"time"` // unix time of the execution
+}
+
+// TradeLog appends trades to local disk, one json record per line.
+type TradeLog struct {
+	path string
+	f    *os.File
+	mtx  sync.Mutex
+}
+
+// NewTradeLog opens the trade log of specific path, the log file will be created if not exist.
+func NewTradeLog(path string) (*TradeLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &TradeLog{path: path, f: f}, nil
+}
+
+// Append writes the trade to the end of log, and flush it to disk.
+func (tl *TradeLog) Append(t Trade) error {
+	d, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	tl.mtx.Lock()
+	defer tl.mtx.Unlock()
+	if _, err := tl.f.Write(append(d, '\n')); err != nil {
+		return err
+	}
+	return tl.f.Sync()
+}
+
+// Query returns the trades of specific coin pair executed in time range [start, end].
+func (tl *TradeLog) Query(cp string, start, end int64) ([]Trade, error) {
+	tl.mtx.Lock()
+	defer tl.mtx.Unlock()
+
+	f, err := os.Open(tl.path)
+	if err != nil {
+		return []Trade{}, err
+	}
+	defer f.Close()
+
+	trades := []Trade{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		t := Trade{}
+		if err := json.Unmarshal(sc.Bytes(), &t); err != nil {
+			return []Trade{}, err
+		}
+
+		if t.Pair == cp && t.Time >= start && t.Time <= end {
+			trades = append(trades, t)
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return []Trade{}, err
+	}
+	return trades, nil
+}
+
+// Close closes the log file.
+func (tl *TradeLog) Close() error {
+	tl.mtx.Lock()
+	defer tl.mtx.Unlock()
+	return tl.f.Close()
+}
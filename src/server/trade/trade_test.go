@@ -0,0 +1,134 @@
+package trade
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTradeLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trade")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "orderbook", "trades.log")
+	tl, err := NewTradeLog(path)
+	assert.Nil(t, err)
+
+	trades := []Trade{
+		{Pair: "bitcoin/skycoin", Price: 100, Amount: 1, Maker: "a", Taker: "b", MakerOrderID: 1, TakerOrderID: 2, Time: 1000},
+		{Pair: "bitcoin/skycoin", Price: 101, Amount: 2, Maker: "a", Taker: "c", MakerOrderID: 1, TakerOrderID: 3, Time: 1001},
+		{Pair: "litecoin/skycoin", Price: 50, Amount: 3, Maker: "d", Taker: "b", MakerOrderID: 1, TakerOrderID: 2, Time: 1002},
+		{Pair: "bitcoin/skycoin", Price: 99, Amount: 4, Maker: "c", Taker: "a", MakerOrderID: 3, TakerOrderID: 4, Time: 1005},
+	}
+	for _, td := range trades {
+		assert.Nil(t, tl.Append(td))
+	}
+
+	ts, err := tl.Query("bitcoin/skycoin", 1000, 1005)
+	assert.Nil(t, err)
+	assert.Equal(t, []Trade{trades[0], trades[1], trades[3]}, ts)
+
+	ts, err = tl.Query("bitcoin/skycoin", 1001, 1004)
+	assert.Nil(t, err)
+	assert.Equal(t, []Trade{trades[1]}, ts)
+
+	ts, err = tl.Query("bitcoin/skycoin", 2000, 3000)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ts))
+
+	// trades are durable after reopening.
+	assert.Nil(t, tl.Close())
+	tl, err = NewTradeLog(path)
+	assert.Nil(t, err)
+	defer tl.Close()
+	assert.Nil(t, tl.Append(Trade{Pair: "litecoin/skycoin", Price: 51, Amount: 1, Time: 1003}))
+	ts, err = tl.Query("litecoin/skycoin", 0, 2000)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ts))
+	assert.Equal(t, trades[2], ts[0])
+}
+
+func TestMakeCandles(t *testing.T) {
+	// the buckets of 1m are [60, 120), [120, 180), [180, 240), [240, 300).
+	trades := []Trade{
+		{Price: 100, Amount: 1, Time: 60},
+		{Price: 105, Amount: 2, Time: 90},
+		{Price: 95, Amount: 3, Time: 100},
+		{Price: 98, Amount: 4, Time: 119},
+		{Price: 110, Amount: 5, Time: 120},
+		{Price: 90, Amount: 6, Time: 250},
+	}
+
+	candles := MakeCandles(trades, time.Minute, 60, 299, false)
+	assert.Equal(t, []Candle{
+		{Time: 60, Open: 100, High: 105, Low: 95, Close: 98, Volume: 10},
+		{Time: 120, Open: 110, High: 110, Low: 110, Close: 110, Volume: 5},
+		{Time: 240, Open: 90, High: 90, Low: 90, Close: 90, Volume: 6},
+	}, candles)
+
+	// the empty buckets are carried forward, including the ones after the last trade.
+	candles = MakeCandles(trades, time.Minute, 60, 300, true)
+	assert.Equal(t, []Candle{
+		{Time: 60, Open: 100, High: 105, Low: 95, Close: 98, Volume: 10},
+		{Time: 120, Open: 110, High: 110, Low: 110, Close: 110, Volume: 5},
+		{Time: 180, Open: 110, High: 110, Low: 110, Close: 110},
+		{Time: 240, Open: 90, High: 90, Low: 90, Close: 90, Volume: 6},
+		{Time: 300, Open: 90, High: 90, Low: 90, Close: 90},
+	}, candles)
+
+	// trades out of range are ignored.
+	candles = MakeCandles(trades, 5*time.Minute, 100, 200, false)
+	assert.Equal(t, []Candle{
+		{Time: 0, Open: 95, High: 110, Low: 95, Close: 110, Volume: 12},
+	}, candles)
+
+	assert.Equal(t, []Candle{}, MakeCandles([]Trade{}, time.Hour, 0, 3600, true))
+}
+
+func TestGetCandles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trade")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	tl, err := NewTradeLog(filepath.Join(dir, "trades.log"))
+	assert.Nil(t, err)
+	defer tl.Close()
+
+	trades := []Trade{
+		{Pair: "bitcoin/skycoin", Price: 100, Amount: 1, Time: 3590},
+		{Pair: "bitcoin/skycoin", Price: 102, Amount: 2, Time: 3600},
+		{Pair: "litecoin/skycoin", Price: 50, Amount: 3, Time: 3610},
+		{Pair: "bitcoin/skycoin", Price: 101, Amount: 4, Time: 7199},
+		{Pair: "bitcoin/skycoin", Price: 99, Amount: 5, Time: 7200},
+	}
+	for _, td := range trades {
+		assert.Nil(t, tl.Append(td))
+	}
+
+	// start is aligned down to the hour.
+	candles, err := tl.GetCandles("bitcoin/skycoin", time.Hour, 3601, 7200, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []Candle{
+		{Time: 3600, Open: 102, High: 102, Low: 101, Close: 101, Volume: 6},
+		{Time: 7200, Open: 99, High: 99, Low: 99, Close: 99, Volume: 5},
+	}, candles)
+
+	candles, err = tl.GetCandles("bitcoin/skycoin", Intervals["1d"], 0, 86399, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []Candle{{Time: 0, Open: 100, High: 102, Low: 99, Close: 99, Volume: 12}}, candles)
+
+	// invalid interval or range.
+	_, err = tl.GetCandles("bitcoin/skycoin", 0, 0, 100, false)
+	assert.NotNil(t, err)
+	_, err = tl.GetCandles("bitcoin/skycoin", 1500*time.Millisecond, 0, 100, false)
+	assert.NotNil(t, err)
+	_, err = tl.GetCandles("bitcoin/skycoin", time.Hour, 7200, 3600, false)
+	assert.NotNil(t, err)
+	_, err = tl.GetCandles("bitcoin/skycoin", time.Minute, 0, 60*MaxCandles, false)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/server/order"
+)
+
+// AddOrderValidator adds the custom validator to the pipeline run by ValidateOrder, the custom
+// validators are run after the built-in ones, in the order of adding.
+func (self *ExchangeServer) AddOrderValidator(v order.Validator) {
+	self.validatorMtx.Lock()
+	self.validators = append(self.validators, v)
+	self.validatorMtx.Unlock()
+}
+
+// ValidateOrder checks the new order of coin pair cp before its balance is reserved, the rules are:
+// trading not halted, positive amount, positive price of limit order, price on the tick grid, price within the price
+// band unless placed by the admin, amount not below the minimum, account not frozen, sufficient balance, room in the
+// book, then the custom validators.
+// The error of the first failing rule is returned.
+func (self *ExchangeServer) ValidateOrder(cp string, odr order.Order) error {
+	p := order.Pipeline{
+		order.ValidateNotHalted,
+		order.ValidateAmount,
+		order.ValidatePrice,
+		order.ValidateTick,
+		self.validatePriceBand,
+		order.ValidateMinAmount,
+		self.validateAccount,
+		self.validateBalance,
+		order.ValidateBookSize,
+	}
+	self.validatorMtx.RLock()
+	p = append(p, self.validators...)
+	self.validatorMtx.RUnlock()
+
+	return self.orderManager.ValidateOrder(cp, odr, p)
+}
+
+// OrderCost returns the coin type and amount the order needs, the market bid
+// needs the estimated cost with the current asks, the bid value is rounded up. The amount in
+// the quote coin is converted to the base coin first.
+func (self *ExchangeServer) OrderCost(cp string, odr order.Order) (string, uint64, error) {
+	pair := strings.Split(cp, "/")
+	if len(pair) != 2 {
+		return "", 0, errors.New("error coin pair")
+	}
+
+	odr, err := self.orderManager.ToBase(cp, odr)
+	if err != nil {
+		return "", 0, err
+	}
+
+	switch odr.Type {
+	case order.Bid:
+		if odr.Kind == order.Market {
+			cost, err := self.orderManager.MarketCost(cp, odr.Amount)
+			return pair[1], cost, err
+		}
+		v, err := self.orderManager.Value(cp, odr.Price, odr.Amount, order.RoundUp)
+		return pair[1], v, err
+	case order.Ask:
+		return pair[0], odr.Amount, nil
+	default:
+		return "", 0, errors.New("unknow order type")
+	}
+}
+
+// ReserveOrder reserves the balance the new order needs before it's added, so that the coins
+// can't be used twice, the limit bid decreases the balance by its value, the market bid decreases
+// the balance by its estimated cost and sets the Cost of odr, and the ask reserves its amount.
+// Nothing is reserved for the stop order. The returned release gives the reserved balance back,
+// it must be called if the order is not added. AddOrder reserves the balance itself, it's only
+// needed by the callers adding the orders to the order manager directly.
+func (self *ExchangeServer) ReserveOrder(cp string, odr *order.Order) (func(), error) {
+	acnt, err := self.GetAccount(odr.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, bal, err := self.OrderCost(cp, *odr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the balance of stop order is only validated, it's reserved once the order is triggered.
+	switch {
+	case odr.IsStop():
+	case odr.Type == order.Bid && odr.Kind == order.Limit:
+		// decrease the balance, in case of double use the coins.
+		logger.Info("account:%s decrease %s:%d", acnt.GetID(), ct, bal)
+		if err := acnt.DecreaseBalance(ct, bal, account.ReasonOrder); err != nil {
+			return nil, err
+		}
+		return func() { acnt.IncreaseBalance(ct, bal, account.ReasonOrderCancel) }, nil
+	case odr.Type == order.Bid:
+		// the market bid is only filled within the cost, what's left is given back when it's closed.
+		if bal == 0 {
+			return nil, errors.New("no ask orders for market bid")
+		}
+		logger.Info("account:%s decrease %s:%d", acnt.GetID(), ct, bal)
+		if err := acnt.DecreaseBalance(ct, bal, account.ReasonOrder); err != nil {
+			return nil, err
+		}
+		odr.Cost = bal
+		return func() { acnt.IncreaseBalance(ct, bal, account.ReasonOrderCancel) }, nil
+	case odr.Type == order.Ask:
+		// reserve the balance, so that the coins can't be committed by other asks.
+		logger.Info("account:%s reserve %s:%d", acnt.GetID(), ct, bal)
+		if err := acnt.ReserveBalance(ct, bal, account.ReasonOrder); err != nil {
+			return nil, err
+		}
+		return func() { acnt.ReleaseBalance(ct, bal, account.ReasonOrderCancel) }, nil
+	}
+	return func() {}, nil
+}
+
+// validatePriceBand checks the price band of the book, the orders of the admin accounts skip it.
+func (self *ExchangeServer) validatePriceBand(cp string, bk *order.Book, od order.Order) error {
+	if self.IsAdmin(od.AccountID) {
+		return nil
+	}
+	return order.ValidatePriceBand(cp, bk, od)
+}
+
+// validateAccount rejects the order of unknown or frozen account.
+func (self *ExchangeServer) validateAccount(cp string, bk *order.Book, od order.Order) error {
+	if _, err := self.GetAccount(od.AccountID); err != nil {
+		return err
+	}
+	if self.IsFrozen(od.AccountID) {
+		return account.ErrAccountFrozen
+	}
+	return nil
+}
+
+// validateBalance rejects the order if the account can't pay for it, the balance of stop
+// order is only validated here, it's reserved once the order is triggered.
+func (self *ExchangeServer) validateBalance(cp string, bk *order.Book, od order.Order) error {
+	acnt, err := self.GetAccount(od.AccountID)
+	if err != nil {
+		return err
+	}
+
+	ct, need, err := self.OrderCost(cp, od)
+	if err != nil {
+		return err
+	}
+	if acnt.GetBalance(ct) < need {
+		return fmt.Errorf("%s %w", ct, order.ErrInsufficientBalance)
+	}
+	return nil
+}
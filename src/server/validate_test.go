@@ -0,0 +1,312 @@
+package server
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/server/order"
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidateTestServer(t *testing.T) (*ExchangeServer, func()) {
+	dir := filepath.Join(os.TempDir(), ".server_validate_order")
+	account.InitDir(filepath.Join(dir, "account"))
+	order.InitDir(filepath.Join(dir, "orderbook"))
+
+	s := &ExchangeServer{
+		Manager:      account.NewManager(),
+		orderManager: order.NewManager(),
+	}
+	assert.Nil(t, s.orderManager.AddBook("bitcoin/skycoin", &order.Book{}))
+
+	a, err := s.CreateAccountWithPubkey("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.IncreaseBalance("bitcoin", 10, account.ReasonAdmin)
+	a.IncreaseBalance("skycoin", 1000, account.ReasonAdmin)
+	return s, func() { os.RemoveAll(dir) }
+}
+
+func TestValidateOrderAccount(t *testing.T) {
+	s, teardown := newValidateTestServer(t)
+	defer teardown()
+
+	cp := "bitcoin/skycoin"
+	ask := order.Order{AccountID: "a", Type: order.Ask, Price: 100, Amount: 10}
+	assert.Nil(t, s.ValidateOrder(cp, ask))
+
+	ask.AccountID = "unknown"
+	assert.NotNil(t, s.ValidateOrder(cp, ask))
+
+	ask.AccountID = "a"
+	assert.Nil(t, s.FreezeAccount("a", false))
+	assert.Equal(t, account.ErrAccountFrozen, s.ValidateOrder(cp, ask))
+}
+
+func TestValidateOrderBalance(t *testing.T) {
+	s, teardown := newValidateTestServer(t)
+	defer teardown()
+
+	cp := "bitcoin/skycoin"
+	// the ask needs the bitcoin, the bid needs the skycoin of price*amount.
+	assert.Nil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 100, Amount: 10}))
+	assert.NotNil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 100, Amount: 11}))
+	assert.Nil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Bid, Price: 100, Amount: 10}))
+	assert.NotNil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Bid, Price: 101, Amount: 10}))
+
+	// the stop order is validated the same.
+	assert.NotNil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 100, StopPrice: 90, Amount: 11}))
+
+	// the reserved balance can't be used.
+	acnt, err := s.GetAccount("a")
+	assert.Nil(t, err)
+	assert.Nil(t, acnt.ReserveBalance("bitcoin", 5, account.ReasonOrder))
+	assert.NotNil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 100, Amount: 6}))
+
+	ct, cost, err := s.OrderCost(cp, order.Order{Type: order.Bid, Price: 100, Amount: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, "skycoin", ct)
+	assert.Equal(t, uint64(300), cost)
+	_, _, err = s.OrderCost("bitcoin", order.Order{Type: order.Ask, Price: 100, Amount: 3})
+	assert.NotNil(t, err)
+}
+
+func TestValidateOrderRules(t *testing.T) {
+	s, teardown := newValidateTestServer(t)
+	defer teardown()
+
+	cp := "bitcoin/skycoin"
+	assert.Nil(t, s.SetTickSize(cp, 10))
+	assert.Nil(t, s.SetMinOrderAmount(cp, 2))
+	assert.Nil(t, s.SetMaxOrders(cp, 1, order.FullReject))
+
+	bid := order.Order{AccountID: "a", Type: order.Bid, Price: 100, Amount: 5}
+	assert.Nil(t, s.ValidateOrder(cp, bid))
+
+	for _, tc := range []struct {
+		name string
+		od   order.Order
+		err  error
+	}{
+		{"zero amount", order.Order{AccountID: "a", Type: order.Bid, Price: 100}, order.ErrZeroAmount},
+		{"zero price", order.Order{AccountID: "a", Type: order.Bid, Amount: 5}, order.ErrZeroPrice},
+		{"off tick", order.Order{AccountID: "a", Type: order.Bid, Price: 105, Amount: 5}, order.ErrOffTick},
+		{"below min amount", order.Order{AccountID: "a", Type: order.Bid, Price: 100, Amount: 1}, order.ErrBelowMinAmount},
+	} {
+		assert.True(t, errors.Is(s.ValidateOrder(cp, tc.od), tc.err), tc.name)
+	}
+
+	// the first failing rule wins, the zero amount is checked before the account.
+	assert.Equal(t, order.ErrZeroAmount, s.ValidateOrder(cp, order.Order{AccountID: "unknown", Type: order.Bid}))
+
+	// the halted pair rejects any order.
+	assert.Nil(t, s.HaltPair(cp))
+	assert.Equal(t, order.ErrPairHalted, s.ValidateOrder(cp, bid))
+	assert.Nil(t, s.ResumePair(cp))
+	assert.Nil(t, s.ValidateOrder(cp, bid))
+
+	// the book is full.
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		s.orderManager.Start(time.Hour, closing)
+		close(done)
+	}()
+	// the id files are saved until the manager is stopped, wait before removing the dir.
+	defer func() {
+		close(closing)
+		<-done
+	}()
+	_, err := s.AddOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 200, Amount: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, order.ErrBookFull, s.ValidateOrder(cp, bid))
+}
+
+func TestValidateOrderPriceBand(t *testing.T) {
+	s, teardown := newValidateTestServer(t)
+	defer teardown()
+
+	cp := "bitcoin/skycoin"
+	s.admins = map[string]bool{"admin": true}
+	admin, err := s.CreateAccountWithPubkey("admin")
+	assert.Nil(t, err)
+	admin.IncreaseBalance("skycoin", 1000, account.ReasonAdmin)
+	assert.Nil(t, s.SetPriceBand(cp, 1000))
+
+	// the first orders are allowed at any price.
+	assert.Nil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Bid, Price: 10, Amount: 5}))
+
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		s.orderManager.Start(10*time.Millisecond, closing)
+		close(done)
+	}()
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	// the last price is established by the trade at 100.
+	_, err = s.orderManager.AddOrder(cp, order.Order{AccountID: "b", Type: order.Ask, Price: 100, Amount: 1})
+	assert.Nil(t, err)
+	_, err = s.orderManager.AddOrder(cp, order.Order{AccountID: "c", Type: order.Bid, Price: 100, Amount: 1})
+	assert.Nil(t, err)
+	for i := 0; i < 100; i++ {
+		if bk := s.orderManager.GetBook(cp); bk.LastPrice() > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	bk := s.orderManager.GetBook(cp)
+	assert.Equal(t, uint64(100), bk.LastPrice())
+
+	assert.Nil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Bid, Price: 110, Amount: 5}))
+	assert.Nil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 90, Amount: 5}))
+	err = s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Bid, Price: 111, Amount: 5})
+	assert.True(t, errors.Is(err, order.ErrOutsidePriceBand))
+	err = s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 89, Amount: 5})
+	assert.True(t, errors.Is(err, order.ErrOutsidePriceBand))
+
+	// the admin skips the band.
+	assert.Nil(t, s.ValidateOrder(cp, order.Order{AccountID: "admin", Type: order.Bid, Price: 200, Amount: 5}))
+
+	// the band is removed by the admin.
+	assert.Nil(t, s.SetPriceBand(cp, 0))
+	assert.Nil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Bid, Price: 111, Amount: 5}))
+}
+
+func TestAddOrderValidator(t *testing.T) {
+	s, teardown := newValidateTestServer(t)
+	defer teardown()
+
+	cp := "bitcoin/skycoin"
+	errLarge := errors.New("order is too large")
+	var checked []uint64
+	s.AddOrderValidator(func(cp string, bk *order.Book, od order.Order) error {
+		checked = append(checked, od.Amount)
+		if od.Amount > 5 {
+			return errLarge
+		}
+		return nil
+	})
+
+	assert.Nil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 100, Amount: 5}))
+	assert.Equal(t, errLarge, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 100, Amount: 6}))
+
+	// the custom validators run after the built-in ones.
+	assert.NotNil(t, s.ValidateOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 100, Amount: 20}))
+	assert.Equal(t, []uint64{5, 6}, checked)
+}
+
+func TestAddOrders(t *testing.T) {
+	s, teardown := newValidateTestServer(t)
+	defer teardown()
+
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		s.orderManager.Start(time.Hour, closing)
+		close(done)
+	}()
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	cp := "bitcoin/skycoin"
+	ids, errs := s.AddOrders(cp, []order.Order{
+		// reserves 4 of the 10 bitcoin.
+		{AccountID: "a", Type: order.Ask, Price: 100, Amount: 4},
+		// needs 10 bitcoin but only 6 are left.
+		{AccountID: "a", Type: order.Ask, Price: 100, Amount: 10},
+		// decreases 450 of the 1000 skycoin.
+		{AccountID: "a", Type: order.Bid, Price: 90, Amount: 5},
+		// needs 600 skycoin but only 550 are left.
+		{AccountID: "a", Type: order.Bid, Price: 100, Amount: 6},
+		{AccountID: "a", Type: order.Bid, Price: 0, Amount: 1},
+		// passes the validation, its reserved balance is given back once the book rejects it.
+		{AccountID: "a", Type: order.Bid, Price: 90, Amount: 1, TimeInForce: order.GTD, ExpireAt: 1},
+		{AccountID: "unknown", Type: order.Ask, Price: 100, Amount: 1},
+	})
+	assert.Len(t, errs, 7)
+	for i, err := range errs {
+		if i == 0 || i == 2 {
+			assert.Nil(t, err)
+			assert.NotEqual(t, uint64(0), ids[i])
+			continue
+		}
+		assert.NotNil(t, err, "order %d", i)
+		assert.Equal(t, uint64(0), ids[i])
+	}
+	assert.Equal(t, order.ErrInvalidExpiry, errs[5])
+
+	acnt, err := s.GetAccount("a")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(6), acnt.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(4), acnt.GetReservedBalance("bitcoin"))
+	assert.Equal(t, uint64(550), acnt.GetBalance("skycoin"))
+
+	bk := s.orderManager.GetBook(cp)
+	assert.Equal(t, 2, bk.Len())
+}
+
+func TestAddOrderBalance(t *testing.T) {
+	s, teardown := newValidateTestServer(t)
+	defer teardown()
+
+	closing := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		s.orderManager.Start(time.Hour, closing)
+		close(done)
+	}()
+	defer func() {
+		close(closing)
+		<-done
+	}()
+
+	cp := "bitcoin/skycoin"
+	acnt, err := s.GetAccount("a")
+	assert.Nil(t, err)
+
+	// the under-funded orders are rejected before they touch the book, nothing is reserved.
+	for _, od := range []order.Order{
+		// needs 1100 of the 1000 skycoin.
+		{AccountID: "a", Type: order.Bid, Price: 100, Amount: 11},
+		// the stop bid reserves nothing, but is covered the same.
+		{AccountID: "a", Type: order.Bid, Price: 100, StopPrice: 150, Amount: 11},
+		// needs 11 of the 10 bitcoin.
+		{AccountID: "a", Type: order.Ask, Price: 100, Amount: 11},
+	} {
+		id, err := s.AddOrder(cp, od)
+		assert.True(t, errors.Is(err, order.ErrInsufficientBalance), "%v", err)
+		assert.Equal(t, uint64(0), id)
+	}
+	bk := s.orderManager.GetBook(cp)
+	assert.Equal(t, 0, bk.Len())
+	assert.False(t, s.orderManager.HasOpenOrders("a"))
+	assert.Equal(t, uint64(1000), acnt.GetBalance("skycoin"))
+	assert.Equal(t, uint64(10), acnt.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(0), acnt.GetReservedBalance("bitcoin"))
+
+	// the bid pays its value up front, so the coins can't be used by the next bid.
+	_, err = s.AddOrder(cp, order.Order{AccountID: "a", Type: order.Bid, Price: 100, Amount: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), acnt.GetBalance("skycoin"))
+	_, err = s.AddOrder(cp, order.Order{AccountID: "a", Type: order.Bid, Price: 100, Amount: 1})
+	assert.True(t, errors.Is(err, order.ErrInsufficientBalance), "%v", err)
+
+	// the balance reserved for the order rejected by the book is given back.
+	_, err = s.AddOrder(cp, order.Order{AccountID: "a", Type: order.Ask, Price: 200, Amount: 5, TimeInForce: order.GTD, ExpireAt: 1})
+	assert.Equal(t, order.ErrInvalidExpiry, err)
+	assert.Equal(t, uint64(10), acnt.GetBalance("bitcoin"))
+	assert.Equal(t, uint64(0), acnt.GetReservedBalance("bitcoin"))
+	bk = s.orderManager.GetBook(cp)
+	assert.Equal(t, 1, bk.Len())
+}
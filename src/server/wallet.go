@@ -8,13 +8,18 @@ import (
 	"github.com/skycoin/skycoin-exchange/src/wallet"
 )
 
+// DefaultWallet name of the wallet created from Config.Seed, the deposit and change
+// addresses are generated in this wallet if no wallet name is specified.
+const DefaultWallet = "default"
+
 // wallets wrap up the wallet package.
 type wallets struct {
-	ids map[string]string // key wallet type, value wallet id.
+	ids map[string]map[string]string // key wallet type, value map of wallet name and wallet id.
 }
 
 type walletItem struct {
 	Type string // coin type
+	Name string // wallet name
 	Seed string // seed
 }
 
@@ -26,41 +31,113 @@ func makeWallets(dir string, items []walletItem) (wallets, error) {
 		wallet.InitDir(dir)
 	}
 	initWalletOnce.Do(f)
-	wlts := wallets{ids: make(map[string]string)}
+	wlts := wallets{ids: make(map[string]map[string]string)}
 	// create wallets if not exist.
 	for _, item := range items {
-		id := wallet.MakeWltID(item.Type, item.Seed)
-		if !wallet.IsExist(id) {
-			_, err := wallet.New(item.Type, item.Seed)
-			if err != nil {
-				return wallets{}, err
+		name := item.Name
+		if name == "" {
+			name = DefaultWallet
+		}
+
+		if _, ok := wlts.ids[item.Type]; !ok {
+			wlts.ids[item.Type] = make(map[string]string)
+		}
+
+		if _, ok := wlts.ids[item.Type][name]; ok {
+			return wallets{}, fmt.Errorf("duplicate %s wallet %s", item.Type, name)
+		}
+
+		// the existing wallet of the seed is returned.
+		wlt, err := wallet.New(item.Type, item.Seed)
+		if err != nil {
+			return wallets{}, err
+		}
+
+		id := wlt.GetID()
+		for n, v := range wlts.ids[item.Type] {
+			if v == id {
+				return wallets{}, fmt.Errorf("%s wallet %s and %s have the same seed", item.Type, n, name)
 			}
 		}
-		wlts.ids[item.Type] = id
+		wlts.ids[item.Type][name] = id
 	}
 	return wlts, nil
 }
 
-// NewAddresses create specific coin addresses.
-func (wlts *wallets) NewAddresses(cp string, num int) ([]coin.AddressEntry, error) {
-	if id, ok := wlts.ids[cp]; ok {
-		return wallet.NewAddresses(id, num)
+// getID returns the id of the named wallet, the default wallet is chosen if name is empty.
+func (wlts wallets) getID(cp, name string) (string, error) {
+	ids, ok := wlts.ids[cp]
+	if !ok {
+		return "", fmt.Errorf("%s wallet not supported", cp)
+	}
+
+	if name == "" {
+		name = DefaultWallet
+	}
+
+	id, ok := ids[name]
+	if !ok {
+		return "", fmt.Errorf("%s wallet %s not exist", cp, name)
+	}
+	return id, nil
+}
+
+// NewAddresses create specific coin addresses in the named wallet.
+func (wlts *wallets) NewAddresses(cp, name string, num int) ([]coin.AddressEntry, error) {
+	id, err := wlts.getID(cp, name)
+	if err != nil {
+		return []coin.AddressEntry{}, err
 	}
-	return []coin.AddressEntry{}, fmt.Errorf("%s wallet not supported", cp)
+	return wallet.NewAddresses(id, num)
 }
 
-// GetKeypair get pub/sec keys of specific address.
-func (wlts wallets) GetKeypair(cp string, addr string) (string, string, error) {
-	if id, ok := wlts.ids[cp]; ok {
+// GetKeypair get pub/sec keys of specific address in the named wallet,
+// all wallets of the coin type are searched if name is empty.
+func (wlts wallets) GetKeypair(cp, name, addr string) (string, string, error) {
+	if name != "" {
+		id, err := wlts.getID(cp, name)
+		if err != nil {
+			return "", "", err
+		}
 		return wallet.GetKeypair(id, addr)
 	}
-	return "", "", fmt.Errorf("%s wallet not supported", cp)
+
+	ids, ok := wlts.ids[cp]
+	if !ok {
+		return "", "", fmt.Errorf("%s wallet not supported", cp)
+	}
+
+	for _, id := range ids {
+		if ok, err := wallet.IsContain(id, []string{addr}); err == nil && ok {
+			return wallet.GetKeypair(id, addr)
+		}
+	}
+	return "", "", fmt.Errorf("%s address %s not found in wallets", cp, addr)
 }
 
-// GetAddresses get all addresses in one specific wallet.
-func (wlts wallets) GetAddresses(cp string) ([]string, error) {
-	if id, ok := wlts.ids[cp]; ok {
+// GetAddresses get all addresses in the named wallet,
+// the addresses of all wallets of the coin type are returned if name is empty.
+func (wlts wallets) GetAddresses(cp, name string) ([]string, error) {
+	if name != "" {
+		id, err := wlts.getID(cp, name)
+		if err != nil {
+			return []string{}, err
+		}
 		return wallet.GetAddresses(id)
 	}
-	return []string{}, fmt.Errorf("%s wallet not supported", cp)
+
+	ids, ok := wlts.ids[cp]
+	if !ok {
+		return []string{}, fmt.Errorf("%s wallet not supported", cp)
+	}
+
+	addrs := []string{}
+	for _, id := range ids {
+		as, err := wallet.GetAddresses(id)
+		if err != nil {
+			return []string{}, err
+		}
+		addrs = append(addrs, as...)
+	}
+	return addrs, nil
 }
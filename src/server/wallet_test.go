@@ -0,0 +1,70 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
+	"github.com/skycoin/skycoin-exchange/src/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeWallets(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), ".server_wallet")
+	wallet.InitDir(dir)
+	defer os.RemoveAll(dir)
+
+	wlts, err := makeWallets(dir, []walletItem{
+		{bitcoin.Type, DefaultWallet, "hot seed"},
+		{bitcoin.Type, "cold", "cold seed"},
+		{skycoin.Type, "", "hot seed"},
+	})
+	assert.Nil(t, err)
+
+	// the addresses are created in the targeted wallet only.
+	_, err = wlts.NewAddresses(bitcoin.Type, "", 2)
+	assert.Nil(t, err)
+	_, err = wlts.NewAddresses(bitcoin.Type, "cold", 3)
+	assert.Nil(t, err)
+
+	hot, err := wlts.GetAddresses(bitcoin.Type, DefaultWallet)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(hot))
+
+	cold, err := wlts.GetAddresses(bitcoin.Type, "cold")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(cold))
+
+	all, err := wlts.GetAddresses(bitcoin.Type, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(all))
+
+	sky, err := wlts.GetAddresses(skycoin.Type, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(sky))
+
+	// unknown wallet.
+	_, err = wlts.NewAddresses(bitcoin.Type, "unknown", 1)
+	assert.NotNil(t, err)
+	_, err = wlts.GetAddresses(skycoin.Type, "cold")
+	assert.NotNil(t, err)
+	_, _, err = wlts.GetKeypair(bitcoin.Type, "unknown", cold[0])
+	assert.NotNil(t, err)
+	_, err = wlts.GetAddresses("unknown", "")
+	assert.NotNil(t, err)
+
+	// wallet name and seed must be unique in the coin type.
+	_, err = makeWallets(dir, []walletItem{
+		{bitcoin.Type, "hot", "hot seed"},
+		{bitcoin.Type, "hot", "cold seed"},
+	})
+	assert.NotNil(t, err)
+
+	_, err = makeWallets(dir, []walletItem{
+		{bitcoin.Type, "hot", "hot seed"},
+		{bitcoin.Type, "cold", "hot seed"},
+	})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,441 @@
+package server
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	litecoin "github.com/skycoin/skycoin-exchange/src/coin/litecoin"
+	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/sklog"
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// TOTPIssuer issuer of the totp secrets shown by the authenticator apps.
+var TOTPIssuer = "skycoin-exchange"
+
+// WithdrawUtxoTm max time that will be allowed in choosing utxos for withdrawal.
+var WithdrawUtxoTm = 5 * time.Second
+
+// Withdraw sends req.Amount coins from the server's default wallet to req.Address on behalf of
+// the account, and returns the txid. The amount and fee are reserved from the account balance while
+// the transaction is being made, and are only deducted after the transaction is broadcasted, if any
+// step fails, the reserved balance is released and the chosen utxos are put back.
+// If req.Key is not empty, it's used as the idempotency key, repeating the withdrawal with
+// the same key returns the txid of the original one instead of making a new transaction.
+// The req.FeeRate in satoshis per vbyte is only used by bitcoin, the default rate is used if it's 0.
+// The memo is recorded with the withdrawal for reconciling, none of the supported coins carries
+// data in transaction, so it never changes the signed transaction.
+// The bitcoin transaction is funded by the utxos of the server wallet in req.Utxos if it's
+// not empty, instead of the chosen ones, they must cover the amount and fee.
+// If the withdrawal whitelist of the account is enabled, the address must be whitelisted for longer
+// than the WithdrawalAddrCooldown.
+// If the totp of the account is enabled, req.TOTPCode must be its valid totp code, including the
+// repeated withdrawal of the same idempotency key, as each code is accepted only once, the repeat
+// needs a new code.
+func (self *ExchangeServer) Withdraw(req account.WithdrawalRequest) (string, error) {
+	return self.withdraw(req, true)
+}
+
+// withdraw makes the withdrawal as Withdraw does, the totp code is only checked if checkTOTP
+// is true, it's false for the withdrawals made by the server itself, like the fee sweep.
+func (self *ExchangeServer) withdraw(req account.WithdrawalRequest, checkTOTP bool) (string, error) {
+	accountID, cp, toAddr, amount, key := req.AccountID, req.CoinType, req.Address, req.Amount, req.Key
+	if amount == 0 {
+		return "", errors.New("withdrawal amount must be greater than 0")
+	}
+
+	if len(req.Utxos) > 0 && cp != bitcoin.Type {
+		return "", fmt.Errorf("specifying utxos is not supported by %s withdrawal", cp)
+	}
+
+	if len(req.Memo) > account.MaxMemoLen {
+		return "", fmt.Errorf("memo exceeds %d bytes", account.MaxMemoLen)
+	}
+
+	if err := validateWithdrawAddr(cp, toAddr); err != nil {
+		return "", err
+	}
+
+	acnt, err := self.GetAccount(accountID)
+	if err != nil {
+		return "", err
+	}
+
+	if self.IsFrozen(accountID) {
+		return "", account.ErrAccountFrozen
+	}
+
+	if err := acnt.CheckWithdrawalAddress(cp, toAddr, self.cfg.WithdrawalAddrCooldown); err != nil {
+		return "", err
+	}
+
+	// checked before the idempotent repeat, so that the txid of a withdrawal is not
+	// returned without the code.
+	if checkTOTP {
+		if err := acnt.VerifyTOTP(self.totpKey(), req.TOTPCode, self.cfg.TOTPSkew); err != nil {
+			return "", err
+		}
+	}
+
+	if key != "" {
+		release, err := self.claimWithdrawalKey(accountID, key)
+		if err != nil {
+			return "", err
+		}
+		defer release()
+
+		if r, ok := acnt.GetWithdrawal(key); ok {
+			if r.CoinType != cp || r.Address != toAddr || r.Amount != amount {
+				return "", fmt.Errorf("idempotency key %s is used by another withdrawal", key)
+			}
+			logger.Debug("account %s withdrawal of key %s is repeated, txid:%s", accountID, key, r.Txid)
+			return r.Txid, nil
+		}
+	}
+
+	gateway, err := self.GetCoin(cp)
+	if err != nil {
+		return "", err
+	}
+
+	fee, err := self.withdrawFee(cp, gateway, toAddr, req.FeeRate)
+	if err != nil {
+		return "", err
+	}
+
+	total := amount + fee
+	if err := acnt.ReserveBalance(cp, total, account.ReasonWithdraw); err != nil {
+		return "", err
+	}
+
+	var txUtxos interface{}
+	if len(req.Utxos) > 0 {
+		txUtxos, err = self.TakeUtxos(cp, req.Utxos)
+	} else {
+		txUtxos, err = self.ChooseUtxos(cp, total, WithdrawUtxoTm)
+	}
+	if err != nil {
+		acnt.ReleaseBalance(cp, total, account.ReasonWithdrawRollback)
+		return "", err
+	}
+
+	var success bool
+	defer func() {
+		if !success {
+			self.PutUtxos(cp, txUtxos)
+			acnt.ReleaseBalance(cp, total, account.ReasonWithdrawRollback)
+		}
+	}()
+
+	if cp == bitcoin.Type {
+		// the fee is estimated with one input before the utxos are chosen.
+		fee, err = self.adjustBtcWithdrawFee(acnt, txUtxos.([]bitcoin.Utxo), toAddr, amount, fee, req.FeeRate)
+		if err != nil {
+			return "", err
+		}
+		total = amount + fee
+	}
+
+	txIns, txOuts, chgAddr, err := self.makeWithdrawTx(cp, txUtxos, toAddr, amount, fee)
+	if err != nil {
+		return "", err
+	}
+
+	rawtx, err := gateway.CreateRawTx(txIns, txOuts)
+	if err != nil {
+		return "", fmt.Errorf("create %s raw tx failed: %v", cp, err)
+	}
+
+	rawtx, err = gateway.SignRawTx(rawtx, func(addr string) (string, error) {
+		return self.GetAddrPrivKey(cp, addr)
+	})
+	if err != nil {
+		return "", fmt.Errorf("sign %s raw tx failed: %v", cp, err)
+	}
+
+	txid, err := coin.BroadcastTx(gateway, rawtx, self.cfg.BroadcastRetries, self.cfg.BroadcastBackoff)
+	if err != nil {
+		return "", fmt.Errorf("broadcast %s tx failed: %w", cp, err)
+	}
+
+	success = true
+	if err := acnt.DecreaseReservedBalance(cp, total, account.ReasonWithdraw); err != nil {
+		logger.Error("account %s withdraw %s txid:%s, %v", accountID, cp, txid, err)
+	}
+
+	acnt.AddWithdrawal(account.WithdrawalRecord{
+		Key:      key,
+		CoinType: cp,
+		Address:  toAddr,
+		Amount:   amount,
+		Txid:     txid,
+		Memo:     req.Memo,
+	})
+
+	if err := self.SaveAccount(); err != nil {
+		logger.Error(err.Error())
+	}
+
+	if chgAddr != "" {
+		logger.Debug("change address:%s", chgAddr)
+		self.WatchAddress(cp, chgAddr)
+	}
+	return txid, nil
+}
+
+// claimWithdrawalKey marks the idempotency key of the account as in progress, so that the
+// concurrent withdrawals of the same key are rejected, the returned func releases the key.
+func (self *ExchangeServer) claimWithdrawalKey(accountID, key string) (func(), error) {
+	id := accountID + ":" + key
+	self.withdrawMtx.Lock()
+	defer self.withdrawMtx.Unlock()
+	if self.withdrawKeys[id] {
+		return nil, fmt.Errorf("withdrawal of idempotency key %s is in progress", key)
+	}
+
+	if self.withdrawKeys == nil {
+		self.withdrawKeys = make(map[string]bool)
+	}
+	self.withdrawKeys[id] = true
+	return func() {
+		self.withdrawMtx.Lock()
+		delete(self.withdrawKeys, id)
+		self.withdrawMtx.Unlock()
+	}, nil
+}
+
+// SetWithdrawalWhitelist enables or disables the withdrawal whitelist of the account, and saves it.
+func (self *ExchangeServer) SetWithdrawalWhitelist(accountID string, enabled bool) error {
+	acnt, err := self.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+	acnt.SetWhitelistEnabled(enabled)
+	return self.Save()
+}
+
+// AddWithdrawalAddress adds the address to the withdrawal whitelist of the account, and saves it,
+// the address can be withdrawn to once the WithdrawalAddrCooldown has passed.
+func (self *ExchangeServer) AddWithdrawalAddress(accountID, cp, addr string) error {
+	if err := validateWithdrawAddr(cp, addr); err != nil {
+		return err
+	}
+
+	acnt, err := self.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+	if err := acnt.AddWithdrawalAddress(cp, addr); err != nil {
+		return err
+	}
+	return self.Save()
+}
+
+// RemoveWithdrawalAddress removes the address from the withdrawal whitelist of the account, and saves it.
+func (self *ExchangeServer) RemoveWithdrawalAddress(accountID, cp, addr string) error {
+	acnt, err := self.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+	if err := acnt.RemoveWithdrawalAddress(cp, addr); err != nil {
+		return err
+	}
+	return self.Save()
+}
+
+// ListWithdrawalAddresses returns the whitelisted withdrawal addresses of the account.
+func (self *ExchangeServer) ListWithdrawalAddresses(accountID string) ([]account.WithdrawalAddress, error) {
+	acnt, err := self.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	return acnt.ListWithdrawalAddresses(), nil
+}
+
+func validateWithdrawAddr(cp, addr string) error {
+	var err error
+	switch cp {
+	case bitcoin.Type:
+		err = bitcoin.ValidateAddr(addr)
+	case litecoin.Type:
+		err = litecoin.ValidateAddr(addr)
+	case skycoin.Type:
+		_, err = cipher.DecodeBase58Address(addr)
+	default:
+		return fmt.Errorf("%s withdrawal is not supported", cp)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s address", cp)
+	}
+	return nil
+}
+
+// withdrawFee returns the coins charged for the withdrawal transaction, bitcoin fee is
+// estimated with one legacy input at the fee rate, other coins' fee is estimated by the coin gateway.
+func (self *ExchangeServer) withdrawFee(cp string, gateway coin.Gateway, toAddr string, feeRate uint64) (uint64, error) {
+	if cp == bitcoin.Type {
+		return self.btcWithdrawFee([]bitcoin.ScriptType{bitcoin.P2PKH}, toAddr, feeRate)
+	}
+	return gateway.EstimateFee(1, 2)
+}
+
+// btcWithdrawFee returns the fee of bitcoin withdrawal spending inputs of script types ins, the
+// change is paid to the legacy address of server wallet. The rate of request takes precedence
+// over Config.BtcFeeRate, and the flat Config.BtcFee is used if neither of them is set.
+func (self *ExchangeServer) btcWithdrawFee(ins []bitcoin.ScriptType, toAddr string, rate uint64) (uint64, error) {
+	if rate == 0 {
+		rate = self.cfg.BtcFeeRate
+	}
+	if rate == 0 {
+		return self.GetBtcFee(), nil
+	}
+	outs := []bitcoin.ScriptType{bitcoin.AddrScriptType(toAddr), bitcoin.P2PKH}
+	return bitcoin.EstimateFeeWithRate(ins, outs, rate)
+}
+
+// adjustBtcWithdrawFee recomputes the fee by the chosen utxos, and reserves or releases the
+// difference to the estimated fee. Returns error if the fee would leave negative change.
+func (self *ExchangeServer) adjustBtcWithdrawFee(acnt account.Accounter, utxos []bitcoin.Utxo, toAddr string, amount, fee, rate uint64) (uint64, error) {
+	var totalAmounts uint64
+	ins := make([]bitcoin.ScriptType, len(utxos))
+	for i, u := range utxos {
+		ins[i] = bitcoin.AddrScriptType(u.GetAddress())
+		totalAmounts += u.GetAmount()
+	}
+
+	newFee, err := self.btcWithdrawFee(ins, toAddr, rate)
+	if err != nil {
+		return 0, err
+	}
+
+	if totalAmounts < amount+newFee {
+		return 0, fmt.Errorf("fee %d of %d inputs would leave negative change", newFee, len(utxos))
+	}
+
+	switch {
+	case newFee > fee:
+		if err := acnt.ReserveBalance(bitcoin.Type, newFee-fee, account.ReasonWithdraw); err != nil {
+			return 0, err
+		}
+	case newFee < fee:
+		acnt.ReleaseBalance(bitcoin.Type, fee-newFee, account.ReasonWithdrawRollback)
+	}
+	return newFee, nil
+}
+
+// makeWithdrawTx makes the txIns and txOuts of withdrawal transaction from the chosen utxos,
+// the change address is empty if there's no change output.
+func (self *ExchangeServer) makeWithdrawTx(cp string, utxos interface{}, toAddr string, amount, fee uint64) ([]coin.TxIn, interface{}, string, error) {
+	switch cp {
+	case bitcoin.Type:
+		return self.makeBtcWithdrawTx(cp, utxos.([]bitcoin.Utxo), toAddr, amount, fee)
+	case litecoin.Type:
+		ltcUtxos := utxos.([]litecoin.Utxo)
+		uxs := make([]bitcoin.Utxo, len(ltcUtxos))
+		for i, u := range ltcUtxos {
+			uxs[i] = u
+		}
+		return self.makeBtcWithdrawTx(cp, uxs, toAddr, amount, fee)
+	case skycoin.Type:
+		return self.makeSkyWithdrawTx(utxos.([]skycoin.Utxo), toAddr, amount)
+	default:
+		return nil, nil, "", fmt.Errorf("%s withdrawal is not supported", cp)
+	}
+}
+
+// makeBtcWithdrawTx makes the withdrawal transaction of coins that share the utxo model of bitcoin.
+func (self *ExchangeServer) makeBtcWithdrawTx(cp string, utxos []bitcoin.Utxo, toAddr string, amount, fee uint64) ([]coin.TxIn, []bitcoin.TxOut, string, error) {
+	var totalAmounts uint64
+	txIns := make([]coin.TxIn, len(utxos))
+	for i, u := range utxos {
+		logger.Debug("using %s utxos: txid:%s vout:%d addr:%s", cp, u.GetTxid(), u.GetVout(), u.GetAddress())
+		txIns[i] = coin.TxIn{
+			Txid:    u.GetTxid(),
+			Vout:    u.GetVout(),
+			Address: u.GetAddress(),
+		}
+		totalAmounts += u.GetAmount()
+	}
+
+	txOuts := []bitcoin.TxOut{{Addr: toAddr, Value: amount}}
+	var chgAddr string
+	if chgAmt := totalAmounts - amount - fee; chgAmt > 0 {
+		var err error
+		chgAddr, err = self.GetNewAddress(cp, "")
+		if err != nil {
+			return nil, nil, "", err
+		}
+		txOuts = append(txOuts, bitcoin.TxOut{Addr: chgAddr, Value: chgAmt})
+	}
+	return txIns, txOuts, chgAddr, nil
+}
+
+func (self *ExchangeServer) makeSkyWithdrawTx(utxos []skycoin.Utxo, toAddr string, amount uint64) ([]coin.TxIn, []skycoin.TxOut, string, error) {
+	var totalAmounts, totalHours uint64
+	txIns := make([]coin.TxIn, len(utxos))
+	for i, u := range utxos {
+		logger.Debug("using skycoin utxos:%s", u.GetHash())
+		txIns[i] = coin.TxIn{
+			Txid:    u.GetHash(),
+			Address: u.GetAddress(),
+		}
+		totalAmounts += u.GetCoins()
+		totalHours += u.GetHours()
+	}
+
+	chgHours := totalHours / 4
+	txOuts := []skycoin.TxOut{skycoin.MakeUtxoOutput(toAddr, amount, chgHours/2)}
+	var chgAddr string
+	if chgAmt := totalAmounts - amount; chgAmt > 0 {
+		var err error
+		chgAddr, err = self.GetNewAddress(skycoin.Type, "")
+		if err != nil {
+			return nil, nil, "", err
+		}
+		txOuts = append(txOuts, skycoin.MakeUtxoOutput(chgAddr, chgAmt, chgHours/2))
+	}
+	return txIns, txOuts, chgAddr, nil
+}
+
+// EnrollTOTP generates the totp secret of the account, and saves it encrypted. The base32 secret
+// and its otpauth uri for the QR code are returned, the withdrawals require the code once the
+// enrollment is confirmed by ConfirmTOTP.
+func (self *ExchangeServer) EnrollTOTP(accountID string) (string, string, error) {
+	acnt, err := self.GetAccount(accountID)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := acnt.EnrollTOTP(self.totpKey())
+	if err != nil {
+		return "", "", err
+	}
+	if err := self.Save(); err != nil {
+		return "", "", err
+	}
+	return secret, account.TOTPURI(TOTPIssuer, accountID, secret), nil
+}
+
+// ConfirmTOTP confirms the totp enrollment of the account with the code, and saves it.
+func (self *ExchangeServer) ConfirmTOTP(accountID, code string) error {
+	acnt, err := self.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+	if err := acnt.ConfirmTOTP(self.totpKey(), code, self.cfg.TOTPSkew); err != nil {
+		return err
+	}
+	sklog.Info(logger, "totp enabled", sklog.Fields{"accountID": accountID})
+	return self.Save()
+}
+
+// totpKey returns the key encrypting the totp secrets, derived from the server's private key.
+func (self *ExchangeServer) totpKey() []byte {
+	key := sha256.Sum256([]byte(self.cfg.Seckey))
+	return key[:]
+}
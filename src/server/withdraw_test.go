@@ -0,0 +1,475 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	"github.com/skycoin/skycoin-exchange/src/coin/mockcoin"
+	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
+	"github.com/skycoin/skycoin-exchange/src/pp"
+	"github.com/skycoin/skycoin-exchange/src/server/account"
+	"github.com/skycoin/skycoin-exchange/src/wallet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// gatewayMock mocks the transaction methods of coin gateway, other methods are not implemented.
+type gatewayMock struct {
+	coin.Gateway
+	mock.Mock
+}
+
+func (m *gatewayMock) CreateRawTx(txIns []coin.TxIn, txOuts interface{}) (string, error) {
+	ret := m.Called(txIns, txOuts)
+	return ret.String(0), ret.Error(1)
+}
+
+func (m *gatewayMock) SignRawTx(rawtx string, getKey coin.GetPrivKey) (string, error) {
+	ret := m.Called(rawtx, getKey)
+	return ret.String(0), ret.Error(1)
+}
+
+func (m *gatewayMock) InjectTx(rawtx string) (string, error) {
+	ret := m.Called(rawtx)
+	return ret.String(0), ret.Error(1)
+}
+
+func (m *gatewayMock) GetTx(txid string) (*pp.Tx, error) {
+	ret := m.Called(txid)
+	tx, _ := ret.Get(0).(*pp.Tx)
+	return tx, ret.Error(1)
+}
+
+func newWithdrawTestServer(t *testing.T, gw coin.Gateway) (*ExchangeServer, account.Accounter, func()) {
+	dir := filepath.Join(os.TempDir(), ".server_withdraw")
+	account.InitDir(filepath.Join(dir, "account"))
+	wallet.InitDir(filepath.Join(dir, "wallet"))
+	wlts, err := makeWallets(filepath.Join(dir, "wallet"), []walletItem{{bitcoin.Type, DefaultWallet, "seed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &ExchangeServer{
+		cfg:     Config{BtcFee: 10000},
+		Manager: account.NewManager(),
+		btcum:   bitcoin.NewUtxoManager(10, []string{}),
+		wallets: wlts,
+		coins:   map[string]coin.Gateway{bitcoin.Type: gw},
+	}
+
+	acnt, err := s.CreateAccountWithPubkey("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	acnt.IncreaseBalance(bitcoin.Type, 100000, account.ReasonAdmin)
+
+	for i, amt := range []uint64{50000, 30000} {
+		s.btcum.PutUtxo(bitcoin.BlkExplrUtxo{Txid: "txid", Vout: uint32(i), Amount: amt})
+	}
+
+	return s, acnt, func() { os.RemoveAll(dir) }
+}
+
+func TestWithdraw(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("newtxid", nil)
+
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+
+	txid, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", Amount: 60000})
+	assert.Nil(t, err)
+	assert.Equal(t, "newtxid", txid)
+
+	// both utxos are spent, and the change is sent back to server wallet.
+	txIns := gw.Calls[0].Arguments.Get(0).([]coin.TxIn)
+	assert.Equal(t, 2, len(txIns))
+	txOuts := gw.Calls[0].Arguments.Get(1).([]bitcoin.TxOut)
+	assert.Equal(t, 2, len(txOuts))
+	assert.Equal(t, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", txOuts[0].Addr)
+	assert.Equal(t, uint64(60000), txOuts[0].Value)
+	assert.Equal(t, uint64(10000), txOuts[1].Value)
+
+	// amount and fee are deducted.
+	assert.Equal(t, uint64(30000), acnt.GetBalance(bitcoin.Type))
+	assert.Equal(t, uint64(0), acnt.GetReservedBalance(bitcoin.Type))
+
+	_, err = s.ChooseUtxos(bitcoin.Type, 1, 100*time.Millisecond)
+	assert.True(t, errors.Is(err, coin.ErrUtxoTimeout))
+
+	// insufficient balance.
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", Amount: 30000})
+	assert.NotNil(t, err)
+	assert.Equal(t, uint64(30000), acnt.GetBalance(bitcoin.Type))
+
+	// unknown account.
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: "unknown", CoinType: bitcoin.Type, Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", Amount: 100})
+	assert.NotNil(t, err)
+}
+
+func TestWithdrawWhitelist(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("newtxid", nil)
+
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+	s.cfg.WithdrawalAddrCooldown = time.Hour
+
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	assert.Nil(t, s.SetWithdrawalWhitelist(acnt.GetID(), true))
+	assert.Nil(t, s.AddWithdrawalAddress(acnt.GetID(), bitcoin.Type, addr))
+	assert.NotNil(t, s.AddWithdrawalAddress(acnt.GetID(), bitcoin.Type, addr))
+	assert.NotNil(t, s.AddWithdrawalAddress(acnt.GetID(), "dogecoin", addr))
+	addrs, err := s.ListWithdrawalAddresses(acnt.GetID())
+	assert.Nil(t, err)
+	assert.Len(t, addrs, 1)
+
+	// the non-whitelisted address is rejected.
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: "1EknG7EauSW4zxFtSrCQSHe5PJenkn55s6", Amount: 10000})
+	assert.Equal(t, account.ErrAddressNotWhitelisted, err)
+
+	// the newly whitelisted address is pending until the cooldown has passed.
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 10000})
+	assert.Equal(t, account.ErrAddressCoolingDown, err)
+	assert.Equal(t, uint64(100000), acnt.GetBalance(bitcoin.Type))
+	assert.Equal(t, 0, len(gw.Calls))
+
+	s.cfg.WithdrawalAddrCooldown = 0
+	txid, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 10000})
+	assert.Nil(t, err)
+	assert.Equal(t, "newtxid", txid)
+
+	// the whitelist is saved with the account.
+	m, err := account.LoadManager()
+	assert.Nil(t, err)
+	a, err := m.GetAccount(acnt.GetID())
+	assert.Nil(t, err)
+	assert.True(t, a.WhitelistEnabled())
+
+	assert.Nil(t, s.RemoveWithdrawalAddress(acnt.GetID(), bitcoin.Type, addr))
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 10000})
+	assert.Equal(t, account.ErrAddressNotWhitelisted, err)
+}
+
+func TestWithdrawTOTP(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("newtxid", nil)
+
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+	s.cfg.TOTPSkew = 1
+
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	assert.NotNil(t, s.ConfirmTOTP(acnt.GetID(), "000000"))
+	secret, uri, err := s.EnrollTOTP(acnt.GetID())
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/"))
+	assert.Contains(t, uri, "secret="+secret)
+
+	assert.False(t, acnt.TOTPEnabled())
+
+	code, err := account.TOTPCode(secret, time.Now())
+	assert.Nil(t, err)
+	assert.Nil(t, s.ConfirmTOTP(acnt.GetID(), code))
+	_, _, err = s.EnrollTOTP(acnt.GetID())
+	assert.Equal(t, account.ErrTOTPEnrolled, err)
+
+	bal := acnt.GetBalance(bitcoin.Type)
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 10000})
+	assert.Equal(t, account.ErrTOTPRequired, err)
+	// the code used by the confirmation can't be used again.
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 10000, TOTPCode: code})
+	assert.Equal(t, account.ErrInvalidTOTP, err)
+	assert.Equal(t, bal, acnt.GetBalance(bitcoin.Type))
+
+	// the code of the next time step is in the skew window.
+	code, err = account.TOTPCode(secret, time.Now().Add(account.TOTPPeriod*time.Second))
+	assert.Nil(t, err)
+	txid, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 10000, Key: "key", TOTPCode: code})
+	assert.Nil(t, err)
+	assert.Equal(t, "newtxid", txid)
+
+	// the repeated withdrawal of the same key can't replay the used code.
+	req := account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 10000, Key: "key", TOTPCode: code}
+	_, err = s.Withdraw(req)
+	assert.Equal(t, account.ErrInvalidTOTP, err)
+
+	s.cfg.TOTPSkew = 2
+	req.TOTPCode, err = account.TOTPCode(secret, time.Now().Add(2*account.TOTPPeriod*time.Second))
+	assert.Nil(t, err)
+	txid, err = s.Withdraw(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "newtxid", txid)
+	gw.AssertNumberOfCalls(t, "InjectTx", 1)
+
+	// the totp is saved with the account.
+	m, err := account.LoadManager()
+	assert.Nil(t, err)
+	a, err := m.GetAccount(acnt.GetID())
+	assert.Nil(t, err)
+	assert.True(t, a.TOTPEnabled())
+}
+
+func TestWithdrawBroadcastFailure(t *testing.T) {
+	gw := mockcoin.New(bitcoin.Type, "BTC", 8)
+	gw.Fail("InjectTx", errors.New("broadcast failed"), -1)
+
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+
+	_, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", Amount: 60000})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, gw.Calls("InjectTx"))
+
+	// the balance is rolled back.
+	assert.Equal(t, uint64(100000), acnt.GetBalance(bitcoin.Type))
+	assert.Equal(t, uint64(0), acnt.GetReservedBalance(bitcoin.Type))
+
+	// the utxos are put back.
+	uxs, err := s.ChooseUtxos(bitcoin.Type, 80000, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(uxs.([]bitcoin.Utxo)))
+}
+
+func TestWithdrawIdempotencyKey(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("newtxid", nil)
+
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	txid, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 20000, Key: "key1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "newtxid", txid)
+
+	// the retried withdrawal returns the original txid, no new transaction is built.
+	txid, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 20000, Key: "key1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "newtxid", txid)
+	gw.AssertNumberOfCalls(t, "CreateRawTx", 1)
+	gw.AssertNumberOfCalls(t, "InjectTx", 1)
+	assert.Equal(t, uint64(70000), acnt.GetBalance(bitcoin.Type))
+
+	// the key can't be reused by different withdrawal.
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 10000, Key: "key1"})
+	assert.NotNil(t, err)
+	gw.AssertNumberOfCalls(t, "CreateRawTx", 1)
+
+	// the key is in progress.
+	release, err := s.claimWithdrawalKey(acnt.GetID(), "key2")
+	assert.Nil(t, err)
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 10000, Key: "key2"})
+	assert.NotNil(t, err)
+	release()
+	gw.AssertNumberOfCalls(t, "CreateRawTx", 1)
+
+	// the keys of different accounts are independent.
+	_, err = s.claimWithdrawalKey("other", "key1")
+	assert.Nil(t, err)
+
+	// the withdrawal record is saved with the account.
+	m, err := account.LoadManager()
+	assert.Nil(t, err)
+	a, err := m.GetAccount(acnt.GetID())
+	assert.Nil(t, err)
+	r, ok := a.GetWithdrawal("key1")
+	assert.True(t, ok)
+	assert.Equal(t, "newtxid", r.Txid)
+}
+
+func TestWithdrawIdempotencyKeyFailure(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("", errors.New("broadcast failed")).Once()
+	gw.On("InjectTx", "signedtx").Return("newtxid", nil)
+
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+
+	// the failed withdrawal is not recorded, it can be retried with the same key.
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	_, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 20000, Key: "key"})
+	assert.NotNil(t, err)
+	_, ok := acnt.GetWithdrawal("key")
+	assert.False(t, ok)
+
+	txid, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 20000, Key: "key"})
+	assert.Nil(t, err)
+	assert.Equal(t, "newtxid", txid)
+	gw.AssertNumberOfCalls(t, "CreateRawTx", 2)
+	assert.Equal(t, uint64(70000), acnt.GetBalance(bitcoin.Type))
+}
+
+func TestWithdrawFeeRate(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("newtxid", nil)
+
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+	s.cfg.BtcFeeRate = 10
+
+	// the fee is estimated with one input, and recomputed with the two chosen inputs,
+	// 2 inputs and 2 outputs take 374 vbytes.
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	_, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 60000})
+	assert.Nil(t, err)
+	txOuts := gw.Calls[0].Arguments.Get(1).([]bitcoin.TxOut)
+	assert.Equal(t, 2, len(txOuts))
+	assert.Equal(t, uint64(80000-60000-3740), txOuts[1].Value)
+	assert.Equal(t, uint64(100000-60000-3740), acnt.GetBalance(bitcoin.Type))
+	assert.Equal(t, uint64(0), acnt.GetReservedBalance(bitcoin.Type))
+}
+
+func TestWithdrawMemo(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("newtxid", nil)
+
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+	_, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 20000, Memo: strings.Repeat("m", account.MaxMemoLen+1)})
+	assert.NotNil(t, err)
+	gw.AssertNotCalled(t, "CreateRawTx", mock.Anything, mock.Anything)
+
+	txid, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 20000, Memo: "invoice 42"})
+	assert.Nil(t, err)
+	assert.Equal(t, "newtxid", txid)
+
+	// the memo is not in the transaction outputs.
+	txOuts := gw.Calls[0].Arguments.Get(1).([]bitcoin.TxOut)
+	assert.Equal(t, addr, txOuts[0].Addr)
+	assert.Equal(t, uint64(20000), txOuts[0].Value)
+
+	// the memo is saved with the account.
+	m, err := account.LoadManager()
+	assert.Nil(t, err)
+	a, err := m.GetAccount(acnt.GetID())
+	assert.Nil(t, err)
+	r, ok := a.GetWithdrawalByTxid("newtxid")
+	assert.True(t, ok)
+	assert.Equal(t, "invoice 42", r.Memo)
+	assert.Equal(t, addr, r.Address)
+}
+
+func TestWithdrawNegativeChange(t *testing.T) {
+	gw := &gatewayMock{}
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+
+	// 226 vbytes are estimated for one input, but the two chosen inputs take 374 vbytes,
+	// the fee of request rate would leave negative change.
+	_, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", Amount: 70000, FeeRate: 30})
+	assert.NotNil(t, err)
+	gw.AssertNotCalled(t, "CreateRawTx", mock.Anything, mock.Anything)
+
+	// the balance is rolled back, and the utxos are put back.
+	assert.Equal(t, uint64(100000), acnt.GetBalance(bitcoin.Type))
+	assert.Equal(t, uint64(0), acnt.GetReservedBalance(bitcoin.Type))
+	uxs, err := s.ChooseUtxos(bitcoin.Type, 80000, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(uxs.([]bitcoin.Utxo)))
+}
+
+func TestWithdrawBroadcastRetry(t *testing.T) {
+	gw := mockcoin.New(bitcoin.Type, "BTC", 8)
+	gw.Fail("InjectTx", errors.New("connection reset"), 2)
+
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+	s.cfg.BroadcastRetries = 3
+	s.cfg.BroadcastBackoff = time.Millisecond
+
+	txid, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", Amount: 60000})
+	assert.Nil(t, err)
+	assert.True(t, gw.ValidateTxid(txid))
+	assert.Equal(t, 3, gw.Calls("InjectTx"))
+	assert.Equal(t, 1, gw.Calls("CreateRawTx"))
+	assert.Len(t, gw.Injected(), 1)
+	assert.Equal(t, uint64(30000), acnt.GetBalance(bitcoin.Type))
+}
+
+func TestWithdrawBroadcastRejected(t *testing.T) {
+	gw := mockcoin.New(bitcoin.Type, "BTC", 8)
+	gw.Fail("InjectTx", fmt.Errorf("%w: double spend", coin.ErrTxRejected), -1)
+
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+	s.cfg.BroadcastRetries = 3
+	s.cfg.BroadcastBackoff = time.Millisecond
+
+	// the rejected transaction is not retried, and the balance is rolled back.
+	_, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", Amount: 60000})
+	assert.True(t, errors.Is(err, coin.ErrTxRejected))
+	assert.Equal(t, 1, gw.Calls("InjectTx"))
+	assert.Equal(t, uint64(100000), acnt.GetBalance(bitcoin.Type))
+	assert.Equal(t, uint64(0), acnt.GetReservedBalance(bitcoin.Type))
+}
+
+func TestWithdrawCoinControl(t *testing.T) {
+	gw := &gatewayMock{}
+	gw.On("CreateRawTx", mock.Anything, mock.Anything).Return("rawtx", nil)
+	gw.On("SignRawTx", "rawtx", mock.Anything).Return("signedtx", nil)
+	gw.On("InjectTx", "signedtx").Return("newtxid", nil)
+
+	s, acnt, teardown := newWithdrawTestServer(t, gw)
+	defer teardown()
+	addr := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"
+
+	// the transaction spends exactly the specified utxo, though the other one is chosen first.
+	txid, err := s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 15000, Utxos: []string{"txid:1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "newtxid", txid)
+	txIns := gw.Calls[0].Arguments.Get(0).([]coin.TxIn)
+	assert.Equal(t, []coin.TxIn{{Txid: "txid", Vout: 1}}, txIns)
+	txOuts := gw.Calls[0].Arguments.Get(1).([]bitcoin.TxOut)
+	assert.Equal(t, 2, len(txOuts))
+	assert.Equal(t, bitcoin.TxOut{Addr: addr, Value: 15000}, txOuts[0])
+	assert.Equal(t, uint64(5000), txOuts[1].Value)
+	assert.Equal(t, uint64(75000), acnt.GetBalance(bitcoin.Type))
+
+	// the utxo spent by the last withdrawal is reserved.
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 1000, Utxos: []string{"txid:1"}})
+	assert.True(t, errors.Is(err, coin.ErrUtxoReserved))
+
+	// the utxo not in the wallet.
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 1000, Utxos: []string{"other:0"}})
+	assert.NotNil(t, err)
+
+	// the utxo doesn't cover the amount and fee.
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: bitcoin.Type, Address: addr, Amount: 45000, Utxos: []string{"txid:0"}})
+	assert.NotNil(t, err)
+
+	// only bitcoin supports it.
+	_, err = s.Withdraw(account.WithdrawalRequest{AccountID: acnt.GetID(), CoinType: skycoin.Type, Address: addr, Amount: 1000, Utxos: []string{"txid:0"}})
+	assert.NotNil(t, err)
+	gw.AssertNumberOfCalls(t, "CreateRawTx", 1)
+
+	// the balance is rolled back, and the utxo is put back.
+	assert.Equal(t, uint64(75000), acnt.GetBalance(bitcoin.Type))
+	assert.Equal(t, uint64(0), acnt.GetReservedBalance(bitcoin.Type))
+	uxs, err := s.TakeUtxos(bitcoin.Type, []string{"txid:0"})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(50000), uxs.([]bitcoin.Utxo)[0].GetAmount())
+}
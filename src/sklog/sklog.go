@@ -0,0 +1,97 @@
+// Package sklog adds structured logging to the op/go-logging loggers, the events
+// logged with fields are printed as `event key=value ...` by the text backends,
+// and as JSON lines by the backend created with NewJSONBackend.
+package sklog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	logging "github.com/op/go-logging"
+)
+
+// Fields the structured fields of the event.
+type Fields map[string]interface{}
+
+// Event the log event with structured fields.
+type Event struct {
+	Name   string
+	Fields Fields
+}
+
+// String formats the event as `name key=value ...`, the keys are sorted.
+func (e Event) String() string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString(e.Name)
+	for _, k := range keys {
+		fmt.Fprintf(&buf, " %s=%v", k, e.Fields[k])
+	}
+	return buf.String()
+}
+
+// Info logs the event with fields at info level.
+func Info(l *logging.Logger, event string, fields Fields) {
+	l.Info("%v", Event{Name: event, Fields: fields})
+}
+
+// Debug logs the event with fields at debug level.
+func Debug(l *logging.Logger, event string, fields Fields) {
+	l.Debug("%v", Event{Name: event, Fields: fields})
+}
+
+// Error logs the event with fields at error level.
+func Error(l *logging.Logger, event string, fields Fields) {
+	l.Error("%v", Event{Name: event, Fields: fields})
+}
+
+// JSONBackend writes each log record as a JSON line with the time, level and module,
+// the event and fields are added for the structured logs, and the message for others.
+type JSONBackend struct {
+	mtx sync.Mutex
+	w   io.Writer
+}
+
+// NewJSONBackend creates the backend writes to w.
+func NewJSONBackend(w io.Writer) *JSONBackend {
+	return &JSONBackend{w: w}
+}
+
+// Log implements the logging.Backend interface.
+func (b *JSONBackend) Log(level logging.Level, calldepth int, rec *logging.Record) error {
+	v := make(map[string]interface{})
+	if len(rec.Args) == 1 {
+		if e, ok := rec.Args[0].(Event); ok {
+			for k, f := range e.Fields {
+				v[k] = f
+			}
+			v["event"] = e.Name
+		}
+	}
+	if _, ok := v["event"]; !ok {
+		v["msg"] = rec.Message()
+	}
+	v["time"] = rec.Time.UTC().Format(time.RFC3339Nano)
+	v["level"] = level.String()
+	v["module"] = rec.Module
+
+	d, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	_, err = b.w.Write(append(d, '\n'))
+	return err
+}
@@ -0,0 +1,55 @@
+package sklog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	logging "github.com/op/go-logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONBackend(t *testing.T) {
+	var buf bytes.Buffer
+	l := logging.MustGetLogger("sklog.test")
+	bk := logging.AddModuleLevel(NewJSONBackend(&buf))
+	bk.SetLevel(logging.DEBUG, "sklog.test")
+	l.SetBackend(bk)
+
+	Info(l, "order matched", Fields{"pair": "bitcoin/skycoin", "price": 10, "amount": 5, "accountID": "abc"})
+	Debug(l, "get utxo", Fields{"txid": "t1"})
+	l.Error("record trade failed: %v", "disk full")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 3, len(lines))
+
+	var v map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &v))
+	assert.Equal(t, "order matched", v["event"])
+	assert.Equal(t, "bitcoin/skycoin", v["pair"])
+	assert.Equal(t, float64(10), v["price"])
+	assert.Equal(t, float64(5), v["amount"])
+	assert.Equal(t, "abc", v["accountID"])
+	assert.Equal(t, "INFO", v["level"])
+	assert.Equal(t, "sklog.test", v["module"])
+	assert.NotEmpty(t, v["time"])
+	assert.Nil(t, v["msg"])
+
+	v = nil
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &v))
+	assert.Equal(t, "get utxo", v["event"])
+	assert.Equal(t, "DEBUG", v["level"])
+
+	// the free-form messages are kept in msg.
+	v = nil
+	assert.Nil(t, json.Unmarshal([]byte(lines[2]), &v))
+	assert.Equal(t, "record trade failed: disk full", v["msg"])
+	assert.Equal(t, "ERROR", v["level"])
+	assert.Nil(t, v["event"])
+}
+
+func TestEventString(t *testing.T) {
+	e := Event{Name: "order matched", Fields: Fields{"price": 10, "pair": "bitcoin/skycoin"}}
+	assert.Equal(t, "order matched pair=bitcoin/skycoin price=10", e.String())
+}
@@ -58,6 +58,7 @@ func Authorize(servSeckey string) HandlerFunc {
 				}
 
 				c.Raw = data
+				c.Sig = req.GetSignature()
 
 				return c.Next()
 			}
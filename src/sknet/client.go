@@ -42,11 +42,17 @@ func Get(addr string, path string, v interface{}) (*Response, error) {
 
 // EncryGet will encrypt the request and decrypt the response.
 func EncryGet(addr string, path string, req interface{}, res interface{}) error {
-	if gSeckey == "" {
+	return SignedGet(addr, path, gSeckey, req, res)
+}
+
+// SignedGet encrypts and signs the request with the seckey, the server uses the signature
+// to verify that the request is sent by the owner of the account.
+func SignedGet(addr string, path string, seckey string, req interface{}, res interface{}) error {
+	if seckey == "" {
 		return errors.New("private key is empty")
 	}
 
-	encReq, err := encrypt(req, gPubkey, gSeckey)
+	encReq, err := encrypt(req, gPubkey, seckey)
 	if err != nil {
 		return err
 	}
@@ -57,7 +63,7 @@ func EncryGet(addr string, path string, req interface{}, res interface{}) error
 	}
 
 	// decode the response.
-	return decrypt(resp.Body, gPubkey, gSeckey, res)
+	return decrypt(resp.Body, gPubkey, seckey, res)
 }
 
 // SetPubkey updates the server's pubkey
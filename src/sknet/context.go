@@ -19,6 +19,7 @@ type Context struct {
 	Request    *Request               // Request from client
 	Raw        []byte                 // the decrypted raw data
 	Pubkey     string                 // client pubkey
+	Sig        string                 // client signature of the raw data
 	ServSeckey string                 // server seckey
 	Resp       ResponseWriter         // Response writer
 	handlers   []HandlerFunc          // request handlers, for records the middlewares.
@@ -21,11 +21,17 @@ func encrypt(r interface{}, pubkey string, seckey string) (*pp.EncryptReq, error
 		return nil, err
 	}
 
+	sig, err := pp.Sign(r, seckey)
+	if err != nil {
+		return nil, err
+	}
+
 	p := cipher.PubKeyFromSecKey(s)
 	return &pp.EncryptReq{
 		Pubkey:      pp.PtrString(p.Hex()),
 		Nonce:       nonce,
 		Encryptdata: encData,
+		Signature:   pp.PtrString(sig),
 	}, nil
 }
 
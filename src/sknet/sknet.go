@@ -29,6 +29,7 @@ type Engine struct {
 	handlerFunc   map[string]HandlerFunc
 	groupHandlers map[string]*Group
 	connPool      chan net.Conn
+	quit          chan bool
 }
 
 // New create an engine.
@@ -37,6 +38,7 @@ func New(seckey string, quit chan bool) *Engine {
 		handlerFunc:   make(map[string]HandlerFunc),
 		groupHandlers: make(map[string]*Group),
 		connPool:      make(chan net.Conn, queueSize),
+		quit:          quit,
 	}
 
 	e.Use(Authorize(seckey))
@@ -90,16 +92,27 @@ func (engine *Engine) Group(path string, handlers ...HandlerFunc) *Group {
 	return gp
 }
 
-// Run start the engine.
+// Run start the engine, returns after the quit channel is closed.
 func (engine *Engine) Run(ip string, port int) {
 	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", ip, port))
 	if err != nil {
 		panic(err)
 	}
+
+	go func() {
+		<-engine.quit
+		l.Close()
+	}()
+
 	for {
 		c, err := l.Accept()
 		if err != nil {
-			panic(err)
+			select {
+			case <-engine.quit:
+				return
+			default:
+				panic(err)
+			}
 		}
 		logger.Debug("new connection:%s", c.RemoteAddr())
 		engine.connPool <- c
@@ -2,18 +2,23 @@ package wallet
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 
 	"github.com/skycoin/skycoin-exchange/src/coin"
+	bip39 "github.com/tyler-smith/go-bip39"
 )
 
 type walletBase struct {
-	ID             string              `json:"id"`                // wallet id
-	InitSeed       string              `json:"init_seed"`         // Init seed, used to recover the wallet.
-	Seed           string              `json:"seed"`              // used to track the latset seed
-	AddressEntries []coin.AddressEntry `json:"entries,omitempty"` // address entries.
+	ID             string              `json:"id"`                   // wallet id
+	InitSeed       string              `json:"init_seed"`            // Init seed, used to recover the wallet.
+	Seed           string              `json:"seed"`                 // used to track the latset seed
+	Path           string              `json:"path,omitempty"`       // BIP44 derivation path, empty if not HD wallet.
+	Passphrase     string              `json:"passphrase,omitempty"` // BIP39 passphrase of the seed.
+	Imported       bool                `json:"imported,omitempty"`   // imported from private key, has no seed.
+	AddressEntries []coin.AddressEntry `json:"entries,omitempty"`    // address entries.
 }
 
 // GetID return wallet id.
@@ -32,6 +37,42 @@ func (wlt *walletBase) SetSeed(seed string) {
 	wlt.Seed = seed
 }
 
+// SetPath set the BIP44 derivation path, like m/44'/0'/0'/0.
+func (wlt *walletBase) SetPath(path string) error {
+	if _, err := parsePath(path); err != nil {
+		return err
+	}
+	wlt.Path = path
+	return nil
+}
+
+// SetPassphrase set the BIP39 passphrase, the wallets of the same seed and different
+// passphrases are different wallets, so the hash of passphrase is appended to the id.
+func (wlt *walletBase) SetPassphrase(passphrase string) {
+	if passphrase == "" {
+		return
+	}
+	wlt.Passphrase = passphrase
+	h := sha256.Sum256([]byte(passphrase))
+	wlt.ID = fmt.Sprintf("%s_%x", wlt.ID, h[:4])
+}
+
+// setImported sets the single address entry of the imported private key.
+func (wlt *walletBase) setImported(e coin.AddressEntry) {
+	wlt.Imported = true
+	wlt.AddressEntries = []coin.AddressEntry{e}
+}
+
+// firstSeed returns the seed for generating the first addresses of the wallet which has
+// no derivation path, it's the BIP39 seed of init seed and passphrase if passphrase is set,
+// otherwise the init seed itself as before.
+func (wlt walletBase) firstSeed() []byte {
+	if wlt.Passphrase == "" {
+		return []byte(wlt.InitSeed)
+	}
+	return bip39.NewSeed(wlt.InitSeed, wlt.Passphrase)
+}
+
 // GetAddresses return all addresses in wallet.
 func (wlt *walletBase) GetAddresses() []string {
 	addrs := []string{}
@@ -72,6 +113,9 @@ func (wlt walletBase) Copy() walletBase {
 		ID:             wlt.ID,
 		InitSeed:       wlt.InitSeed,
 		Seed:           wlt.Seed,
+		Path:           wlt.Path,
+		Passphrase:     wlt.Passphrase,
+		Imported:       wlt.Imported,
 		AddressEntries: wlt.AddressEntries,
 	}
 }
@@ -38,8 +38,18 @@ func (bt *BtcWallet) NewAddresses(num int) ([]coin.AddressEntry, error) {
 		bt.AddressEntries = append(bt.AddressEntries, entries...)
 	}()
 
+	if bt.Imported {
+		return entries, ErrImportedWallet
+	}
+
+	if bt.Path != "" {
+		var err error
+		entries, err = makeHDAddresses(bt.InitSeed, bt.Passphrase, bt.Path, len(bt.AddressEntries), num, bitcoin.NetParams(), bitcoin.HideSeckey)
+		return entries, err
+	}
+
 	if bt.Seed == bt.InitSeed {
-		bt.Seed, entries = bitcoin.GenerateAddresses([]byte(bt.Seed), num)
+		bt.Seed, entries = bitcoin.GenerateAddresses(bt.firstSeed(), num)
 		return entries, nil
 	}
 
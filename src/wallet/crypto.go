@@ -0,0 +1,178 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var (
+	// ErrWalletEncrypted is returned when the seed or keys of encrypted wallet are required.
+	ErrWalletEncrypted = errors.New("wallet is encrypted")
+	// ErrWrongPassword is returned when the wallet can't be decrypted by the password.
+	ErrWrongPassword = errors.New("wrong password")
+)
+
+// pbkdf2Iter iterations of PBKDF2 for deriving the encryption key from password.
+var pbkdf2Iter = 100000
+
+// cryptoWallet is the encrypted wallet, the whole wallet file is encrypted with AES-GCM,
+// and the key is derived from password with PBKDF2-SHA256. Only the addresses are kept
+// in plaintext, so the balance can be checked without the password.
+type cryptoWallet struct {
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	Encrypted bool     `json:"encrypted"` // always true, for distinguishing the encrypted wallet files.
+	Addresses []string `json:"addresses"`
+	Salt      []byte   `json:"salt"`
+	Nonce     []byte   `json:"nonce"`
+	Data      []byte   `json:"data"` // the encrypted wallet file.
+}
+
+// isEncrypted checks whether the wallet file is encrypted.
+func isEncrypted(d []byte) bool {
+	v := struct {
+		Encrypted bool `json:"encrypted"`
+	}{}
+	return json.Unmarshal(d, &v) == nil && v.Encrypted
+}
+
+// encrypt encrypts the wallet with password.
+func encrypt(wlt Walleter, password string) (*cryptoWallet, error) {
+	if password == "" {
+		return nil, errors.New("password is required")
+	}
+
+	var buf bytes.Buffer
+	if err := wlt.Save(&buf); err != nil {
+		return nil, err
+	}
+
+	cw := &cryptoWallet{
+		ID:        wlt.GetID(),
+		Type:      wlt.GetType(),
+		Encrypted: true,
+		Addresses: wlt.GetAddresses(),
+		Salt:      make([]byte, 16),
+	}
+	if _, err := io.ReadFull(rand.Reader, cw.Salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(password, cw.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	cw.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, cw.Nonce); err != nil {
+		return nil, err
+	}
+	cw.Data = gcm.Seal(nil, cw.Nonce, buf.Bytes(), []byte(cw.ID))
+	return cw, nil
+}
+
+// decrypt decrypts the wallet with password.
+func (cw cryptoWallet) decrypt(password string) (Walleter, error) {
+	newWlt, ok := gWalletCreators[cw.Type]
+	if !ok {
+		return nil, fmt.Errorf("%s wallet not supported", cw.Type)
+	}
+
+	gcm, err := newGCM(password, cw.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := gcm.Open(nil, cw.Nonce, cw.Data, []byte(cw.ID))
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	wlt := newWlt()
+	if err := wlt.Load(bytes.NewReader(d)); err != nil {
+		return nil, err
+	}
+	return wlt, nil
+}
+
+func newGCM(password string, salt []byte) (cipher.AEAD, error) {
+	key := pbkdf2.Key([]byte(password), salt, pbkdf2Iter, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// GetID return wallet id.
+func (cw cryptoWallet) GetID() string {
+	return cw.ID
+}
+
+// SetID set wallet id.
+func (cw *cryptoWallet) SetID(id string) {
+	cw.ID = id
+}
+
+// SetSeed the seed of encrypted wallet can't be changed.
+func (cw *cryptoWallet) SetSeed(seed string) {}
+
+// SetPath the path of encrypted wallet can't be changed.
+func (cw *cryptoWallet) SetPath(path string) error {
+	return ErrWalletEncrypted
+}
+
+// SetPassphrase the passphrase of encrypted wallet can't be changed.
+func (cw *cryptoWallet) SetPassphrase(passphrase string) {}
+
+// GetType return the wallet coin type.
+func (cw cryptoWallet) GetType() string {
+	return cw.Type
+}
+
+// NewAddresses the seed is required for generating addresses, decrypt the wallet first.
+func (cw *cryptoWallet) NewAddresses(num int) ([]coin.AddressEntry, error) {
+	return []coin.AddressEntry{}, ErrWalletEncrypted
+}
+
+// GetAddresses return all addresses in wallet.
+func (cw cryptoWallet) GetAddresses() []string {
+	return append([]string{}, cw.Addresses...)
+}
+
+// GetKeypair the keys are encrypted, use getKeypair of wallets with password.
+func (cw cryptoWallet) GetKeypair(addr string) (string, string, error) {
+	return "", "", ErrWalletEncrypted
+}
+
+// Save save the wallet.
+func (cw *cryptoWallet) Save(w io.Writer) error {
+	d, err := json.MarshalIndent(cw, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(d)
+	return err
+}
+
+// Load load wallet from reader.
+func (cw *cryptoWallet) Load(r io.Reader) error {
+	return json.NewDecoder(r).Decode(cw)
+}
+
+// Copy return the copy of self.
+func (cw cryptoWallet) Copy() Walleter {
+	c := cw
+	c.Addresses = cw.GetAddresses()
+	return &c
+}
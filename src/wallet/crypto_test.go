@@ -0,0 +1,88 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptWallet(t *testing.T) {
+	tmpDir := filepath.Join(os.TempDir(), ".wallet_crypto")
+	InitDir(tmpDir)
+	defer os.RemoveAll(tmpDir)
+
+	wlt, err := New(bitcoin.Type, testMnemonic, DerivationPath("m/44'/0'/0'/0"))
+	assert.Nil(t, err)
+	id := wlt.GetID()
+	entries, err := NewAddresses(id, 2)
+	assert.Nil(t, err)
+
+	// password is required.
+	assert.NotNil(t, Encrypt(id, ""))
+	assert.Nil(t, Encrypt(id, "pwd"))
+	assert.Equal(t, ErrWalletEncrypted, Encrypt(id, "pwd"))
+
+	// neither the seed nor the keys are stored in plaintext.
+	files, err := ioutil.ReadDir(tmpDir)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+	d, err := ioutil.ReadFile(storeAddr(wlt))
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(d), `"init_seed"`))
+	assert.False(t, strings.Contains(string(d), entries[0].Secret))
+
+	ok, err := IsEncrypted(id)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// the addresses are readable, but the keys require the password.
+	addrs, err := GetAddresses(id)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{entries[0].Address, entries[1].Address}, addrs)
+
+	_, _, err = GetKeypair(id, entries[0].Address)
+	assert.Equal(t, ErrWalletEncrypted, err)
+	_, _, err = GetKeypairWithPassword(id, entries[0].Address, "wrong")
+	assert.Equal(t, ErrWrongPassword, err)
+	p, s, err := GetKeypairWithPassword(id, entries[0].Address, "pwd")
+	assert.Nil(t, err)
+	assert.Equal(t, entries[0].Public, p)
+	assert.Equal(t, entries[0].Secret, s)
+
+	_, err = NewAddresses(id, 1)
+	assert.Equal(t, ErrWalletEncrypted, err)
+
+	// the wallet is still encrypted after reloaded.
+	InitDir(tmpDir)
+	ok, err = IsEncrypted(id)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	assert.Equal(t, ErrWrongPassword, Decrypt(id, "wrong"))
+	assert.Nil(t, Decrypt(id, "pwd"))
+	assert.NotNil(t, Decrypt(id, "pwd"))
+
+	// the decrypted wallet is the same as before.
+	InitDir(tmpDir)
+	ok, err = IsEncrypted(id)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	p, s, err = GetKeypair(id, entries[1].Address)
+	assert.Nil(t, err)
+	assert.Equal(t, entries[1].Public, p)
+	assert.Equal(t, entries[1].Secret, s)
+
+	es, err := NewAddresses(id, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(es))
+
+	// the password is ignored for plaintext wallet.
+	_, s, err = GetKeypairWithPassword(id, entries[1].Address, "")
+	assert.Nil(t, err)
+	assert.Equal(t, entries[1].Secret, s)
+}
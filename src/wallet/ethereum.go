@@ -0,0 +1,62 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	"github.com/skycoin/skycoin-exchange/src/coin/ethereum"
+)
+
+// EthWallet ethereum wallet, it's not registered by default, use RegisterCreator to enable it.
+type EthWallet struct {
+	walletBase
+}
+
+// NewEthWltCreator wallet generator
+func NewEthWltCreator() Creator {
+	return func() Walleter {
+		return &EthWallet{}
+	}
+}
+
+// GetType return the wallet coin type.
+func (et EthWallet) GetType() string {
+	return ethereum.Type
+}
+
+// Copy return the copy of self.
+func (et EthWallet) Copy() Walleter {
+	return &EthWallet{
+		et.walletBase.Copy(),
+	}
+}
+
+// SetPath ethereum wallet does not support derivation path.
+func (et *EthWallet) SetPath(path string) error {
+	return errors.New("derivation path is not supported by ethereum wallet")
+}
+
+// NewAddresses generate ethereum addresses.
+func (et *EthWallet) NewAddresses(num int) ([]coin.AddressEntry, error) {
+	entries := []coin.AddressEntry{}
+	defer func() {
+		et.AddressEntries = append(et.AddressEntries, entries...)
+	}()
+
+	if et.Imported {
+		return entries, ErrImportedWallet
+	}
+
+	if et.Seed == et.InitSeed {
+		et.Seed, entries = ethereum.GenerateAddresses(et.firstSeed(), num)
+		return entries, nil
+	}
+
+	s, err := hex.DecodeString(et.Seed)
+	if err != nil {
+		return entries, err
+	}
+	et.Seed, entries = ethereum.GenerateAddresses(s, num)
+	return entries, nil
+}
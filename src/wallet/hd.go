@@ -0,0 +1,127 @@
+package wallet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	bip39 "github.com/tyler-smith/go-bip39"
+)
+
+// parsePath parses the BIP32 derivation path, like m/44'/0'/0'/0.
+func parsePath(path string) ([]uint32, error) {
+	segs := strings.Split(path, "/")
+	if len(segs) < 2 || segs[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path: %s", path)
+	}
+
+	idxs := make([]uint32, len(segs)-1)
+	for i, s := range segs[1:] {
+		var hardened bool
+		if strings.HasSuffix(s, "'") {
+			hardened = true
+			s = strings.TrimSuffix(s, "'")
+		}
+
+		v, err := strconv.ParseUint(s, 10, 32)
+		if err != nil || v >= hdkeychain.HardenedKeyStart {
+			return nil, fmt.Errorf("invalid derivation path: %s", path)
+		}
+
+		idxs[i] = uint32(v)
+		if hardened {
+			idxs[i] += hdkeychain.HardenedKeyStart
+		}
+	}
+	return idxs, nil
+}
+
+// childKey derives the child key of index i. The child is reloaded from its serialized
+// string, for the leading zeros of private key are stripped by hdkeychain, which makes
+// the hardened derivation of its children different from other BIP32 implementations.
+func childKey(k *hdkeychain.ExtendedKey, i uint32) (*hdkeychain.ExtendedKey, error) {
+	c, err := k.Child(i)
+	if err != nil {
+		return nil, err
+	}
+	return hdkeychain.NewKeyFromString(c.String())
+}
+
+// bip84Purpose the purpose of BIP84 path, whose addresses are native segwit.
+const bip84Purpose = hdkeychain.HardenedKeyStart + 84
+
+// masterKey creates the BIP32 master key of the BIP39 seed of mnemonic and passphrase.
+func masterKey(mnemonic, passphrase string) (*hdkeychain.ExtendedKey, error) {
+	return hdkeychain.NewMaster(bip39.NewSeed(mnemonic, passphrase), &chaincfg.MainNetParams)
+}
+
+// makeHDAddresses derives num addresses from index start under the path, the mnemonic is
+// converted to BIP39 seed with the passphrase, so the addresses match other BIP44 wallets.
+// The addresses of BIP84 path like m/84'/0'/0'/0 are native segwit, only bitcoin supports it.
+func makeHDAddresses(mnemonic, passphrase, path string, start, num int, net *chaincfg.Params, hideSeckey bool) ([]coin.AddressEntry, error) {
+	idxs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	segwit := idxs[0] == bip84Purpose
+	if segwit && net != bitcoin.NetParams() {
+		return nil, fmt.Errorf("segwit address is not supported in %s", net.Name)
+	}
+
+	k, err := masterKey(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range idxs {
+		if k, err = childKey(k, i); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]coin.AddressEntry, num)
+	for i := range entries {
+		c, err := childKey(k, uint32(start+i))
+		if err != nil {
+			return nil, err
+		}
+
+		priv, err := c.ECPrivKey()
+		if err != nil {
+			return nil, err
+		}
+
+		pub := priv.PubKey().SerializeCompressed()
+		if segwit {
+			entries[i].Address, err = bitcoin.SegwitAddressFromPubkey(pub)
+		} else {
+			entries[i].Address, err = pubkeyHashAddress(pub, net)
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries[i].Public = fmt.Sprintf("%x", pub)
+		if !hideSeckey {
+			wif, err := btcutil.NewWIF(priv, net, true)
+			if err != nil {
+				return nil, err
+			}
+			entries[i].Secret = wif.String()
+		}
+	}
+	return entries, nil
+}
+
+func pubkeyHashAddress(pub []byte, net *chaincfg.Params) (string, error) {
+	addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(pub), net)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
+}
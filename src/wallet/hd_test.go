@@ -0,0 +1,198 @@
+package wallet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	litecoin "github.com/skycoin/skycoin-exchange/src/coin/litecoin"
+	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
+	"github.com/stretchr/testify/assert"
+)
+
+// test vectors are generated by https://iancoleman.io/bip39/
+var testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestParsePath(t *testing.T) {
+	idxs, err := parsePath("m/44'/0'/0'/0")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint32{0x8000002c, 0x80000000, 0x80000000, 0}, idxs)
+
+	for _, p := range []string{"", "m", "44'/0'", "m/a'/0", "m/44'/-1", "m/2147483648"} {
+		_, err := parsePath(p)
+		assert.NotNil(t, err, p)
+	}
+}
+
+func TestHDWallet(t *testing.T) {
+	tmpDir := filepath.Join(os.TempDir(), ".wallet_hd")
+	InitDir(tmpDir)
+	defer os.RemoveAll(tmpDir)
+
+	testData := []struct {
+		Type    string
+		Path    string
+		Entries [][2]string // address and secret key
+	}{
+		{
+			bitcoin.Type,
+			"m/44'/0'/0'/0",
+			[][2]string{
+				{"1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA", "L4p2b9VAf8k5aUahF1JCJUzZkgNEAqLfq8DDdQiyAprQAKSbu8hf"},
+				{"1Ak8PffB2meyfYnbXZR9EGfLfFZVpzJvQP", "KzJgGiEeGUVWmPR97pVWDnCVraZvM2fnrCVrg2irV4353HciE6Un"},
+				{"1MNF5RSaabFwcbtJirJwKnDytsXXEsVsNb", "L4BL9ZGzuQJFoRqGfjsgHeYzD1C72y2VmJaY6sqdtaRkfxUFrJXu"},
+			},
+		},
+		{
+			litecoin.Type,
+			"m/44'/2'/0'/0",
+			[][2]string{
+				{"LUWPbpM43E2p7ZSh8cyTBEkvpHmr3cB8Ez", "T5b4RiWRs7XG8xZ2bCHBoJcn4JrpMTbGRFYXgoZHd7nD8izwqhMK"},
+				{"Ldatw8ZjgMGNUo5HMN6RgCrjmh7q494Si3", "T4QBn73zHJgjKQ6cFGBqfiz52rHs4UYJHrhVRBdCzSepMjb9stje"},
+			},
+		},
+		{
+			bitcoin.Type,
+			"m/84'/0'/0'/0",
+			[][2]string{
+				{"bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", "KyZpNDKnfs94vbrwhJneDi77V6jF64PWPF8x5cdJb8ifgg2DUc9d"},
+				{"bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g", "Kxpf5b8p3qX56DKEe5NqWbNUP9MnqoRFzZwHRtsFqhzuvUJsYZCy"},
+			},
+		},
+	}
+
+	for _, d := range testData {
+		wlt, err := New(d.Type, testMnemonic, DerivationPath(d.Path))
+		assert.Nil(t, err)
+
+		// the addresses are derived incrementally.
+		entries, err := wlt.NewAddresses(1)
+		assert.Nil(t, err)
+		es, err := wlt.NewAddresses(len(d.Entries) - 1)
+		assert.Nil(t, err)
+		entries = append(entries, es...)
+
+		for i, e := range entries {
+			assert.Equal(t, d.Entries[i][0], e.Address)
+			assert.Equal(t, d.Entries[i][1], e.Secret)
+		}
+		assert.Nil(t, Remove(wlt.GetID()))
+	}
+
+	// invalid path.
+	_, err := New(bitcoin.Type, testMnemonic, DerivationPath("m/x"))
+	assert.NotNil(t, err)
+
+	// litecoin does not support segwit address.
+	wlt, err := New(litecoin.Type, testMnemonic, DerivationPath("m/84'/2'/0'/0"))
+	if err == nil {
+		_, err = wlt.NewAddresses(1)
+		Remove(wlt.GetID())
+	}
+	assert.NotNil(t, err)
+
+	// skycoin wallet does not support derivation path.
+	_, err = New(skycoin.Type, testMnemonic, DerivationPath("m/44'/8000'/0'/0"))
+	assert.NotNil(t, err)
+}
+
+func TestMasterKey(t *testing.T) {
+	// test vectors from the BIP39 spec, the passphrase is TREZOR, and the seeds of
+	// empty passphrase are generated by https://iancoleman.io/bip39/
+	testData := []struct {
+		Mnemonic   string
+		Passphrase string
+		Key        string
+	}{
+		{
+			testMnemonic,
+			"",
+			"xprv9s21ZrQH143K3GJpoapnV8SFfukcVBSfeCficPSGfubmSFDxo1kuHnLisriDvSnRRuL2Qrg5ggqHKNVpxR86QEC8w35uxmGoggxtQTPvfUu",
+		},
+		{
+			testMnemonic,
+			"TREZOR",
+			"xprv9s21ZrQH143K3h3fDYiay8mocZ3afhfULfb5GX8kCBdno77K4HiA15Tg23wpbeF1pLfs1c5SPmYHrEpTuuRhxMwvKDwqdKiGJS9XFKzUsAF",
+		},
+		{
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"",
+			"xprv9s21ZrQH143K2x4gnzRB1eZDq92Uuvy9CXbvgQGdvykXZ9mkkot6LBjzDpgaAfvzkuxJe9JKJXQ38VoPutxvACA5MsyoBs5UyQ4HZKGshGs",
+		},
+		{
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"TREZOR",
+			"xprv9s21ZrQH143K2gA81bYFHqU68xz1cX2APaSq5tt6MFSLeXnCKV1RVUJt9FWNTbrrryem4ZckN8k4Ls1H6nwdvDTvnV7zEXs2HgPezuVccsq",
+		},
+	}
+
+	for _, d := range testData {
+		k, err := masterKey(d.Mnemonic, d.Passphrase)
+		assert.Nil(t, err)
+		assert.Equal(t, d.Key, k.String())
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	tmpDir := filepath.Join(os.TempDir(), ".wallet_passphrase")
+	InitDir(tmpDir)
+	defer os.RemoveAll(tmpDir)
+
+	testData := []struct {
+		Type string
+		Path string
+	}{
+		{bitcoin.Type, "m/44'/0'/0'/0"},
+		{litecoin.Type, "m/44'/2'/0'/0"},
+	}
+
+	for _, d := range testData {
+		ops := []Option{DerivationPath(d.Path)}
+
+		wlt, err := New(d.Type, testMnemonic, ops...)
+		assert.Nil(t, err)
+		plain, err := NewAddresses(wlt.GetID(), 3)
+		assert.Nil(t, err)
+		assert.Nil(t, Remove(wlt.GetID()))
+
+		// empty passphrase creates the same wallet as before.
+		wlt, err = New(d.Type, testMnemonic, append(ops, Passphrase(""))...)
+		assert.Nil(t, err)
+		assert.Equal(t, MakeWltID(d.Type, testMnemonic), wlt.GetID())
+		es, err := NewAddresses(wlt.GetID(), 3)
+		assert.Nil(t, err)
+		assert.Equal(t, plain, es)
+
+		// the wallet of passphrase is a different wallet.
+		pwlt, err := New(d.Type, testMnemonic, append(ops, Passphrase("TREZOR"))...)
+		assert.Nil(t, err)
+		assert.NotEqual(t, wlt.GetID(), pwlt.GetID())
+		es, err = NewAddresses(pwlt.GetID(), 2)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(es))
+		for i := range es {
+			assert.NotEqual(t, plain[i].Address, es[i].Address)
+		}
+
+		// the passphrase is saved and reloaded with the wallet.
+		InitDir(tmpDir)
+		addrs, err := GetAddresses(pwlt.GetID())
+		assert.Nil(t, err)
+		assert.Equal(t, []string{es[0].Address, es[1].Address}, addrs)
+		es, err = NewAddresses(pwlt.GetID(), 1)
+		assert.Nil(t, err)
+		assert.NotEqual(t, plain[2].Address, es[0].Address)
+
+		assert.Nil(t, Remove(wlt.GetID()))
+		assert.Nil(t, Remove(pwlt.GetID()))
+	}
+
+	// the first seed of the wallet which is not HD wallet is the BIP39 seed.
+	wlt := walletBase{InitSeed: testMnemonic}
+	assert.Equal(t, []byte(testMnemonic), wlt.firstSeed())
+	wlt.SetPassphrase("TREZOR")
+	assert.Equal(t, "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		fmt.Sprintf("%x", wlt.firstSeed()))
+}
@@ -0,0 +1,114 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	litecoin "github.com/skycoin/skycoin-exchange/src/coin/litecoin"
+	"github.com/skycoin/skycoin-exchange/src/coin/mzcoin"
+	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// ErrImportedWallet is returned when generating addresses in the wallet imported from private key.
+var ErrImportedWallet = errors.New("imported wallet has only the address of the private key")
+
+// importer is implemented by walletBase, for setting the address of imported private key.
+type importer interface {
+	setImported(e coin.AddressEntry)
+}
+
+// ImportFromWIF creates the bitcoin or litecoin wallet of the private key in WIF, the wallet has
+// the single address of the key, which is compressed or not as the WIF says. The wallet id is made
+// from the address, so the key can't be imported twice.
+func ImportFromWIF(tp, wif string) (Walleter, error) {
+	var net *chaincfg.Params
+	switch tp {
+	case bitcoin.Type:
+		net = bitcoin.NetParams()
+	case litecoin.Type:
+		net = litecoin.NetParams()
+	default:
+		return nil, fmt.Errorf("importing WIF is not supported by %s wallet", tp)
+	}
+
+	w, err := btcutil.DecodeWIF(wif)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WIF: %v", err)
+	}
+
+	if !w.IsForNet(net) {
+		return nil, fmt.Errorf("WIF is not for %s", tp)
+	}
+
+	pub := w.SerializePubKey()
+	addr, err := pubkeyHashAddress(pub, net)
+	if err != nil {
+		return nil, err
+	}
+
+	return importEntry(tp, coin.AddressEntry{
+		Address: addr,
+		Public:  fmt.Sprintf("%x", pub),
+		Secret:  wif,
+	})
+}
+
+// ImportFromSeckey creates the skycoin or mzcoin wallet of the private key in hex, the wallet has
+// the single address of the key.
+func ImportFromSeckey(tp, hexSeckey string) (Walleter, error) {
+	if tp != skycoin.Type && tp != mzcoin.Type {
+		return nil, fmt.Errorf("importing seckey is not supported by %s wallet", tp)
+	}
+
+	b, err := hex.DecodeString(hexSeckey)
+	if err != nil || len(b) != 32 {
+		return nil, errors.New("invalid seckey, must be 32 bytes in hex")
+	}
+
+	sec, err := cipher.SecKeyFromHex(hexSeckey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seckey: %v", err)
+	}
+
+	if err := sec.Verify(); err != nil {
+		return nil, fmt.Errorf("invalid seckey: %v", err)
+	}
+
+	pub := cipher.PubKeyFromSecKey(sec)
+	return importEntry(tp, coin.AddressEntry{
+		Address: cipher.AddressFromPubKey(pub).String(),
+		Public:  pub.Hex(),
+		Secret:  hexSeckey,
+	})
+}
+
+// importEntry creates the wallet of coin type tp with the single address entry.
+func importEntry(tp string, e coin.AddressEntry) (Walleter, error) {
+	newWlt, ok := gWalletCreators[tp]
+	if !ok {
+		return nil, fmt.Errorf("%s wallet not regestered", tp)
+	}
+
+	wlt := newWlt()
+	im, ok := wlt.(importer)
+	if !ok {
+		return nil, fmt.Errorf("%s wallet doesn't support importing private key", tp)
+	}
+	wlt.SetID(MakeWltID(tp, e.Address))
+	im.setImported(e)
+
+	if legacy := legacyWltID(tp, e.Address); IsExist(legacy) {
+		return nil, fmt.Errorf("%s already exist", legacy)
+	}
+
+	if err := gWallets.add(wlt); err != nil {
+		return nil, err
+	}
+	return wlt.Copy(), nil
+}
@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// the labels of addresses are stored in $id.labels alongside the wallet file, so they
+// don't change the wallet file or the key derivation, and are kept when the wallet is
+// removed, so that they come back once the addresses are regenerated from the same seed.
+
+// MaxLabelLen max characters of address label.
+const MaxLabelLen = 64
+
+// setLabel sets the label of address in the wallet, empty label removes it.
+func (wlts *wallets) setLabel(id, addr, label string) error {
+	if utf8.RuneCountInString(label) > MaxLabelLen {
+		return fmt.Errorf("label exceeds %d characters", MaxLabelLen)
+	}
+
+	wlts.mtx.Lock()
+	defer wlts.mtx.Unlock()
+	wlt, ok := wlts.Value[id]
+	if !ok {
+		return fmt.Errorf("%s wallet does not exist", id)
+	}
+
+	var exist bool
+	for _, a := range wlt.GetAddresses() {
+		if a == addr {
+			exist = true
+			break
+		}
+	}
+	if !exist {
+		return fmt.Errorf("address %s is not in wallet %s", addr, id)
+	}
+
+	labels, err := loadLabels(id)
+	if err != nil {
+		return err
+	}
+
+	if label == "" {
+		delete(labels, addr)
+	} else {
+		labels[addr] = label
+	}
+	return storeLabels(id, labels)
+}
+
+// getLabels returns the labels of the addresses in the wallet, key: address.
+func (wlts *wallets) getLabels(id string) (map[string]string, error) {
+	wlts.mtx.Lock()
+	defer wlts.mtx.Unlock()
+	if _, ok := wlts.Value[id]; !ok {
+		return nil, fmt.Errorf("%s wallet does not exist", id)
+	}
+	return loadLabels(id)
+}
+
+func labelsAddr(id string) string {
+	return filepath.Join(wltDir, id+".labels")
+}
+
+func loadLabels(id string) (map[string]string, error) {
+	labels := make(map[string]string)
+	d, err := ioutil.ReadFile(labelsAddr(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return labels, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(d, &labels); err != nil {
+		return nil, fmt.Errorf("load labels of %s wallet failed: %v", id, err)
+	}
+	return labels, nil
+}
+
+func storeLabels(id string, labels map[string]string) error {
+	d, err := json.MarshalIndent(labels, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	path := labelsAddr(id)
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, d, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
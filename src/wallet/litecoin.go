@@ -0,0 +1,62 @@
+package wallet
+
+import (
+	"encoding/hex"
+
+	"github.com/skycoin/skycoin-exchange/src/coin"
+	litecoin "github.com/skycoin/skycoin-exchange/src/coin/litecoin"
+)
+
+// LtcWallet litecoin wallet.
+type LtcWallet struct {
+	walletBase
+}
+
+// NewLtcWltCreator wallet generator
+func NewLtcWltCreator() Creator {
+	return func() Walleter {
+		return &LtcWallet{}
+	}
+}
+
+// GetType return the wallet coin type.
+func (lt LtcWallet) GetType() string {
+	return "litecoin"
+}
+
+// Copy return the copy of self.
+func (lt LtcWallet) Copy() Walleter {
+	return &LtcWallet{
+		lt.walletBase.Copy(),
+	}
+}
+
+// NewAddresses generate litecoin addresses.
+func (lt *LtcWallet) NewAddresses(num int) ([]coin.AddressEntry, error) {
+	entries := []coin.AddressEntry{}
+	defer func() {
+		lt.AddressEntries = append(lt.AddressEntries, entries...)
+	}()
+
+	if lt.Imported {
+		return entries, ErrImportedWallet
+	}
+
+	if lt.Path != "" {
+		var err error
+		entries, err = makeHDAddresses(lt.InitSeed, lt.Passphrase, lt.Path, len(lt.AddressEntries), num, litecoin.NetParams(), litecoin.HideSeckey)
+		return entries, err
+	}
+
+	if lt.Seed == lt.InitSeed {
+		lt.Seed, entries = litecoin.GenerateAddresses(lt.firstSeed(), num)
+		return entries, nil
+	}
+
+	s, err := hex.DecodeString(lt.Seed)
+	if err != nil {
+		return entries, err
+	}
+	lt.Seed, entries = litecoin.GenerateAddresses(s, num)
+	return entries, nil
+}
@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"encoding/hex"
+	"errors"
 
 	"github.com/skycoin/skycoin-exchange/src/coin"
 	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
@@ -31,6 +32,11 @@ func (sk SkyWallet) Copy() Walleter {
 	}
 }
 
+// SetPath skycoin wallet does not support derivation path.
+func (sk *SkyWallet) SetPath(path string) error {
+	return errors.New("derivation path is not supported by skycoin wallet")
+}
+
 // NewAddresses generate skycoin addresses.
 func (sk *SkyWallet) NewAddresses(num int) ([]coin.AddressEntry, error) {
 	entries := []coin.AddressEntry{}
@@ -38,8 +44,12 @@ func (sk *SkyWallet) NewAddresses(num int) ([]coin.AddressEntry, error) {
 		sk.AddressEntries = append(sk.AddressEntries, entries...)
 	}()
 
+	if sk.Imported {
+		return entries, ErrImportedWallet
+	}
+
 	if sk.Seed == sk.InitSeed {
-		sk.Seed, entries = skycoin.GenerateAddresses([]byte(sk.Seed), num)
+		sk.Seed, entries = skycoin.GenerateAddresses(sk.firstSeed(), num)
 		return entries, nil
 	}
 
@@ -1,13 +1,16 @@
 package wallet
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/skycoin/skycoin-exchange/src/coin"
 	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	litecoin "github.com/skycoin/skycoin-exchange/src/coin/litecoin"
 	"github.com/skycoin/skycoin-exchange/src/coin/mzcoin"
 	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
 	"github.com/skycoin/skycoin/src/util"
@@ -18,6 +21,8 @@ type Walleter interface {
 	GetID() string                                     // get wallet id.
 	SetID(id string)                                   // set wallet id.
 	SetSeed(seed string)                               // init the wallet seed.
+	SetPath(path string) error                         // set the BIP44 derivation path.
+	SetPassphrase(passphrase string)                   // set the BIP39 passphrase.
 	GetType() string                                   // get the wallet coin type.
 	NewAddresses(num int) ([]coin.AddressEntry, error) // generate new addresses.
 	GetAddresses() []string                            // get all addresses in the wallet.
@@ -27,8 +32,8 @@ type Walleter interface {
 	Copy() Walleter                                    // copy of self, for thread safe.
 }
 
-// wltDir default wallet dir, wallet file name sturct: $type_$seed.wlt.
-// example: bitcoin_seed.wlt, skycoin_seed.wlt.
+// wltDir default wallet dir, wallet file name sturct: $id.wlt, the id is $type_$hash.
+// example: bitcoin_1f2e....wlt, the wallets created before have the name $type_$seed.wlt.
 var wltDir = filepath.Join(util.UserHome(), ".exchange-client/wallet")
 
 // Ext wallet file extension name
@@ -37,12 +42,34 @@ var Ext = "wlt"
 // Creator wallet creator.
 type Creator func() Walleter
 
+// Option wallet option, used in creating wallet.
+type Option func(wlt Walleter) error
+
+// DerivationPath option for creating BIP44 HD wallet, the addresses are derived
+// from the path, like m/44'/0'/0'/0, and the seed is used as BIP39 mnemonic.
+func DerivationPath(path string) Option {
+	return func(wlt Walleter) error {
+		return wlt.SetPath(path)
+	}
+}
+
+// Passphrase option for creating wallet with BIP39 passphrase, also known as the 25th
+// word, the seed is derived from the mnemonic and passphrase like other BIP39 wallets.
+// Empty passphrase creates the same wallet as no passphrase.
+func Passphrase(passphrase string) Option {
+	return func(wlt Walleter) error {
+		wlt.SetPassphrase(passphrase)
+		return nil
+	}
+}
+
 var gWalletCreators = make(map[string]Creator)
 
 func init() {
 	// the default wallet creator are registered here, using the following RegisterCreator function
 	// to extend new wallet type.
 	gWalletCreators[bitcoin.Type] = NewBtcWltCreator()
+	gWalletCreators[litecoin.Type] = NewLtcWltCreator()
 	gWalletCreators[skycoin.Type] = NewSkyWltCreator()
 	gWalletCreators[mzcoin.Type] = NewSkyWltCreator()
 }
@@ -82,8 +109,10 @@ func GetWalletDir() string {
 	return wltDir
 }
 
-// New create wallet base on seed and coin type.
-func New(tp, seed string) (Walleter, error) {
+// New create wallet base on seed and coin type, the wallet id is derived from them, so
+// creating the wallet of the same seed and coin type again returns the existing wallet,
+// including the one created before the ids are hashed, and the options are ignored.
+func New(tp, seed string, ops ...Option) (Walleter, error) {
 	newWlt, ok := gWalletCreators[tp]
 	if !ok {
 		return nil, fmt.Errorf("%s wallet not regestered", tp)
@@ -93,12 +122,15 @@ func New(tp, seed string) (Walleter, error) {
 	wlt := newWlt()
 	wlt.SetID(MakeWltID(tp, seed))
 	wlt.SetSeed(seed)
-
-	if err := gWallets.add(wlt); err != nil {
-		return nil, err
+	for _, op := range ops {
+		if err := op(wlt); err != nil {
+			return nil, err
+		}
 	}
 
-	return wlt.Copy(), nil
+	// the passphrase option appends its hash to the id.
+	suffix := strings.TrimPrefix(wlt.GetID(), MakeWltID(tp, seed))
+	return gWallets.addOrGet(wlt, legacyWltID(tp, seed)+suffix)
 }
 
 // IsExist check if the wallet is already exist.
@@ -106,8 +138,15 @@ func IsExist(id string) bool {
 	return gWallets.isExist(id)
 }
 
-// MakeWltID make wallet id base on coin type and seed.
+// MakeWltID make wallet id base on coin type and seed, the id is the coin type and the
+// hex of the first 16 bytes of the sha256 of them, so the seed can't be read from the id.
 func MakeWltID(cp, seed string) string {
+	h := sha256.Sum256([]byte(cp + ":" + seed))
+	return fmt.Sprintf("%s_%x", cp, h[:16])
+}
+
+// legacyWltID the wallet id of the coin type and seed used before the ids are hashed.
+func legacyWltID(cp, seed string) string {
 	return fmt.Sprintf("%s_%s", cp, seed)
 }
 
@@ -131,6 +170,40 @@ func GetKeypair(id string, addr string) (string, string, error) {
 	return gWallets.getKeypair(id, addr)
 }
 
+// GetKeypairWithPassword get pub/sec key pair of specific address in wallet, the password
+// is required if the wallet is encrypted, and ignored otherwise.
+func GetKeypairWithPassword(id, addr, password string) (string, string, error) {
+	return gWallets.getKeypairWithPassword(id, addr, password)
+}
+
+// Encrypt encrypts the wallet file with password, the addresses can still be read
+// without password, but new addresses can't be generated until it's decrypted, and
+// GetKeypairWithPassword is required for getting the keys.
+func Encrypt(id, password string) error {
+	return gWallets.encrypt(id, password)
+}
+
+// Decrypt decrypts the wallet, and stores it in plaintext again.
+func Decrypt(id, password string) error {
+	return gWallets.decrypt(id, password)
+}
+
+// IsEncrypted checks whether the wallet is encrypted.
+func IsEncrypted(id string) (bool, error) {
+	return gWallets.isEncrypted(id)
+}
+
+// SetLabel sets the label of address in the wallet, empty label removes it. The labels are
+// stored alongside the wallet file, and survive the wallet being removed and recreated.
+func SetLabel(id, addr, label string) error {
+	return gWallets.setLabel(id, addr, label)
+}
+
+// GetLabels returns the labels of addresses in the wallet, key: address.
+func GetLabels(id string) (map[string]string, error) {
+	return gWallets.getLabels(id)
+}
+
 // Remove remove wallet of specific id.
 func Remove(id string) error {
 	return gWallets.remove(id)
@@ -6,7 +6,8 @@ import (
 	"path/filepath"
 	"testing"
 
-	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,21 +22,21 @@ func TestMustLoad(t *testing.T) {
 	// create wallets.
 	testData := []struct {
 		ID   string
-		Type coin.Type
+		Type string
 		Seed string
 	}{
-		{"bitcoin_seed1", coin.Bitcoin, "seed1"},
-		{"bitcoin_seed2", coin.Bitcoin, "seed2"},
-		{"bitcoin_seed3", coin.Bitcoin, "seed3"},
-		{"bitcoin_seed4", coin.Bitcoin, "seed4"},
-		{"skycoin_seed1", coin.Skycoin, "seed1"},
-		{"skycoin_seed2", coin.Skycoin, "seed2"},
-		{"skycoin_seed3", coin.Skycoin, "seed3"},
+		{MakeWltID(bitcoin.Type, "seed1"), bitcoin.Type, "seed1"},
+		{MakeWltID(bitcoin.Type, "seed2"), bitcoin.Type, "seed2"},
+		{MakeWltID(bitcoin.Type, "seed3"), bitcoin.Type, "seed3"},
+		{MakeWltID(bitcoin.Type, "seed4"), bitcoin.Type, "seed4"},
+		{MakeWltID(skycoin.Type, "seed1"), skycoin.Type, "seed1"},
+		{MakeWltID(skycoin.Type, "seed2"), skycoin.Type, "seed2"},
+		{MakeWltID(skycoin.Type, "seed3"), skycoin.Type, "seed3"},
 	}
 
 	for _, d := range testData {
 		if _, err := New(d.Type, d.Seed); err != nil {
-			fmt.Println(d.Type.String(), " ", d.Seed)
+			fmt.Println(d.Type, " ", d.Seed)
 			t.Error(err)
 			return
 		}
@@ -50,3 +51,22 @@ func TestMustLoad(t *testing.T) {
 		}
 	}
 }
+
+func TestNewLegacyWallet(t *testing.T) {
+	tmpDir := filepath.Join(os.TempDir(), ".wallet1001")
+	InitDir(tmpDir)
+	defer os.RemoveAll(tmpDir)
+
+	// the wallet created before the ids are hashed.
+	legacy := &BtcWallet{}
+	legacy.SetID(legacyWltID(bitcoin.Type, "seed1"))
+	legacy.SetSeed("seed1")
+	assert.Nil(t, gWallets.add(legacy))
+
+	gWallets.reset()
+	gWallets.mustLoad()
+	wlt, err := New(bitcoin.Type, "seed1")
+	assert.Nil(t, err)
+	assert.Equal(t, "bitcoin_seed1", wlt.GetID())
+	assert.False(t, IsExist(MakeWltID(bitcoin.Type, "seed1")))
+}
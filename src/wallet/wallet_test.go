@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/skycoin/skycoin-exchange/src/coin"
+	bitcoin "github.com/skycoin/skycoin-exchange/src/coin/bitcoin"
+	skycoin "github.com/skycoin/skycoin-exchange/src/coin/skycoin"
 	"github.com/skycoin/skycoin-exchange/src/wallet"
 	"github.com/stretchr/testify/assert"
 )
@@ -65,14 +67,13 @@ func TestNewWallet(t *testing.T) {
 	defer teardown()
 
 	testData := []struct {
-		Type coin.Type
+		Type string
 		Seed string
-		Path string
 	}{
-		{coin.Bitcoin, "sd123", filepath.Join(wltDir, "bitcoin_sd123.wlt")},
-		{coin.Bitcoin, "sd234", filepath.Join(wltDir, "bitcoin_sd234.wlt")},
-		{coin.Skycoin, "sd123", filepath.Join(wltDir, "skycoin_sd123.wlt")},
-		{coin.Skycoin, "sd234", filepath.Join(wltDir, "skycoin_sd234.wlt")},
+		{bitcoin.Type, "sd123"},
+		{bitcoin.Type, "sd234"},
+		{skycoin.Type, "sd123"},
+		{skycoin.Type, "sd234"},
 	}
 
 	for _, d := range testData {
@@ -82,26 +83,64 @@ func TestNewWallet(t *testing.T) {
 		}
 
 		// check the existence of wallet file.
-		if _, err := os.Stat(d.Path); os.IsNotExist(err) {
+		path := filepath.Join(wltDir, wallet.MakeWltID(d.Type, d.Seed)+".wlt")
+		if _, err := os.Stat(path); os.IsNotExist(err) {
 			t.Error("create wallet failed")
 			return
 		}
 	}
 }
 
+func TestNewWalletSameSeed(t *testing.T) {
+	_, teardown, err := setup(t)
+	assert.Nil(t, err)
+	defer teardown()
+
+	wlt, err := wallet.New(bitcoin.Type, "sd345")
+	assert.Nil(t, err)
+	assert.Equal(t, wallet.MakeWltID(bitcoin.Type, "sd345"), wlt.GetID())
+	assert.True(t, strings.HasPrefix(wlt.GetID(), bitcoin.Type+"_"))
+	assert.False(t, strings.Contains(wlt.GetID(), "sd345"))
+	_, err = wallet.NewAddresses(wlt.GetID(), 2)
+	assert.Nil(t, err)
+
+	// importing the same seed again returns the existing wallet.
+	again, err := wallet.New(bitcoin.Type, "sd345")
+	assert.Nil(t, err)
+	assert.Equal(t, wlt.GetID(), again.GetID())
+	assert.Equal(t, 2, len(again.GetAddresses()))
+
+	// the coin type and passphrase make different wallets.
+	sky, err := wallet.New(skycoin.Type, "sd345")
+	assert.Nil(t, err)
+	assert.NotEqual(t, wlt.GetID(), sky.GetID())
+
+	p1, err := wallet.New(bitcoin.Type, "sd345", wallet.Passphrase("p1"))
+	assert.Nil(t, err)
+	assert.NotEqual(t, wlt.GetID(), p1.GetID())
+	p2, err := wallet.New(bitcoin.Type, "sd345", wallet.Passphrase("p1"))
+	assert.Nil(t, err)
+	assert.Equal(t, p1.GetID(), p2.GetID())
+
+	// the id is the same after reloading the wallets.
+	wallet.InitDir(wallet.GetWalletDir())
+	assert.True(t, wallet.IsExist(wlt.GetID()))
+	assert.True(t, wallet.IsExist(p1.GetID()))
+}
+
 func TestNewAddresses(t *testing.T) {
 	wltDir, teardown, err := setup(t)
 	// wltDir, _, err := setup(t)
 	assert.Nil(t, err)
 	defer teardown()
 	testData := []struct {
-		Type    coin.Type
+		Type    string
 		Seed    string
 		Num     int
 		Entries []coin.AddressEntry
 	}{
 		{
-			Type: coin.Bitcoin,
+			Type: bitcoin.Type,
 			Seed: "sd999",
 			Num:  2,
 			Entries: []coin.AddressEntry{
@@ -118,7 +157,7 @@ func TestNewAddresses(t *testing.T) {
 			},
 		},
 		{
-			Type: coin.Skycoin,
+			Type: skycoin.Type,
 			Seed: "sd888",
 			Num:  2,
 			Entries: []coin.AddressEntry{
@@ -171,13 +210,13 @@ func TestGetAddresses(t *testing.T) {
 	assert.Nil(t, err)
 	defer teardown()
 	testData := []struct {
-		Type    coin.Type
+		Type    string
 		Seed    string
 		Num     int
 		Entries []coin.AddressEntry
 	}{
 		{
-			Type: coin.Bitcoin,
+			Type: bitcoin.Type,
 			Seed: "sd999",
 			Num:  2,
 			Entries: []coin.AddressEntry{
@@ -194,7 +233,7 @@ func TestGetAddresses(t *testing.T) {
 			},
 		},
 		{
-			Type: coin.Skycoin,
+			Type: skycoin.Type,
 			Seed: "sd888",
 			Num:  2,
 			Entries: []coin.AddressEntry{
@@ -249,13 +288,13 @@ func TestGetKeypair(t *testing.T) {
 	assert.Nil(t, err)
 	defer teardown()
 	testData := []struct {
-		Type    coin.Type
+		Type    string
 		Seed    string
 		Num     int
 		Entries []coin.AddressEntry
 	}{
 		{
-			Type: coin.Bitcoin,
+			Type: bitcoin.Type,
 			Seed: "sd999",
 			Num:  2,
 			Entries: []coin.AddressEntry{
@@ -272,7 +311,7 @@ func TestGetKeypair(t *testing.T) {
 			},
 		},
 		{
-			Type: coin.Skycoin,
+			Type: skycoin.Type,
 			Seed: "sd888",
 			Num:  2,
 			Entries: []coin.AddressEntry{
@@ -322,12 +361,11 @@ func TestRemove(t *testing.T) {
 
 	// create wallet
 	testData := []struct {
-		Type coin.Type
+		Type string
 		Seed string
-		ID   string
 	}{
-		{coin.Bitcoin, "sd777", "bitcoin_sd777"},
-		{coin.Skycoin, "sd777", "skycoin_sd777"},
+		{bitcoin.Type, "sd777"},
+		{skycoin.Type, "sd777"},
 	}
 
 	for _, d := range testData {
@@ -342,7 +380,7 @@ func TestRemove(t *testing.T) {
 		}
 
 		// check if the wlt file is already removed.
-		path := filepath.Join(wltDir, fmt.Sprintf("%s.%s", d.ID, wallet.Ext))
+		path := filepath.Join(wltDir, fmt.Sprintf("%s.%s", wlt.GetID(), wallet.Ext))
 		if _, err := os.Stat(path); !os.IsNotExist(err) {
 			t.Fatal("remove wallet failed")
 		}
@@ -358,13 +396,13 @@ func TestIsExist(t *testing.T) {
 	defer teardown()
 
 	testData := []struct {
-		Type coin.Type
+		Type string
 		Seed string
 	}{
-		{coin.Bitcoin, "sd666"},
-		{coin.Bitcoin, "sd667"},
-		{coin.Skycoin, "sd666"},
-		{coin.Skycoin, "sd667"},
+		{bitcoin.Type, "sd666"},
+		{bitcoin.Type, "sd667"},
+		{skycoin.Type, "sd666"},
+		{skycoin.Type, "sd667"},
 	}
 
 	for _, d := range testData {
@@ -383,3 +421,147 @@ func TestIsExist(t *testing.T) {
 		}
 	}
 }
+
+func TestLabels(t *testing.T) {
+	_, teardown, err := setup(t)
+	assert.Nil(t, err)
+	defer teardown()
+
+	// the addresses of HD wallet are regenerated from the same mnemonic once it's recreated.
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	path := wallet.DerivationPath("m/44'/0'/0'/0")
+	wlt, err := wallet.New(bitcoin.Type, mnemonic, path)
+	assert.Nil(t, err)
+	id := wlt.GetID()
+	entries, err := wallet.NewAddresses(id, 3)
+	assert.Nil(t, err)
+	a0, a1 := entries[0].Address, entries[1].Address
+
+	labels, err := wallet.GetLabels(id)
+	assert.Nil(t, err)
+	assert.Empty(t, labels)
+
+	// set and overwrite.
+	assert.Nil(t, wallet.SetLabel(id, a0, "savings"))
+	assert.Nil(t, wallet.SetLabel(id, a1, "exchange"))
+	assert.Nil(t, wallet.SetLabel(id, a1, "cold storage"))
+	labels, err = wallet.GetLabels(id)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{a0: "savings", a1: "cold storage"}, labels)
+
+	// empty label removes it.
+	assert.Nil(t, wallet.SetLabel(id, a1, ""))
+	labels, err = wallet.GetLabels(id)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{a0: "savings"}, labels)
+
+	// the address must be in the wallet, and the label is limited.
+	assert.NotNil(t, wallet.SetLabel(id, "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", "other"))
+	assert.NotNil(t, wallet.SetLabel(id, a0, strings.Repeat("a", wallet.MaxLabelLen+1)))
+	assert.NotNil(t, wallet.SetLabel("bitcoin_unknown", a0, "savings"))
+	_, err = wallet.GetLabels("bitcoin_unknown")
+	assert.NotNil(t, err)
+
+	// the labels are persisted, and don't change the addresses or keys.
+	_, sec, err := wallet.GetKeypair(id, a0)
+	assert.Nil(t, err)
+	wallet.InitDir(wallet.GetWalletDir())
+	labels, err = wallet.GetLabels(id)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{a0: "savings"}, labels)
+	_, sec1, err := wallet.GetKeypair(id, a0)
+	assert.Nil(t, err)
+	assert.Equal(t, sec, sec1)
+
+	// the labels survive the wallet being recreated and the addresses regenerated.
+	assert.Nil(t, wallet.Remove(id))
+	_, err = wallet.New(bitcoin.Type, mnemonic, path)
+	assert.Nil(t, err)
+	entries, err = wallet.NewAddresses(id, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, a0, entries[0].Address)
+	labels, err = wallet.GetLabels(id)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{a0: "savings"}, labels)
+}
+
+func TestImportFromWIF(t *testing.T) {
+	_, teardown, err := setup(t)
+	assert.Nil(t, err)
+	defer teardown()
+
+	wif := "L4p2b9VAf8k5aUahF1JCJUzZkgNEAqLfq8DDdQiyAprQAKSbu8hf"
+	wlt, err := wallet.ImportFromWIF(bitcoin.Type, wif)
+	assert.Nil(t, err)
+	id := wlt.GetID()
+	assert.Equal(t, wallet.MakeWltID(bitcoin.Type, "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"), id)
+
+	addrs, err := wallet.GetAddresses(id)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"}, addrs)
+	_, sec, err := wallet.GetKeypair(id, addrs[0])
+	assert.Nil(t, err)
+	assert.Equal(t, wif, sec)
+
+	// no more addresses, and the key can't be imported twice.
+	_, err = wallet.NewAddresses(id, 1)
+	assert.Equal(t, wallet.ErrImportedWallet, err)
+	_, err = wallet.ImportFromWIF(bitcoin.Type, wif)
+	assert.NotNil(t, err)
+
+	// reloaded from the wallet file.
+	wallet.InitDir(wallet.GetWalletDir())
+	addrs, err = wallet.GetAddresses(id)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"}, addrs)
+	_, err = wallet.NewAddresses(id, 1)
+	assert.Equal(t, wallet.ErrImportedWallet, err)
+
+	testData := []struct {
+		tp  string
+		wif string
+	}{
+		{bitcoin.Type, ""},
+		{bitcoin.Type, "L4p2b9VAf8k5aUahF1JCJUzZkgNEAqLfq8DDdQiyAprQAKSbu8hg"},
+		{"litecoin", "KzJgGiEeGUVWmPR97pVWDnCVraZvM2fnrCVrg2irV4353HciE6Un"},
+		{skycoin.Type, "KzJgGiEeGUVWmPR97pVWDnCVraZvM2fnrCVrg2irV4353HciE6Un"},
+	}
+	for _, d := range testData {
+		_, err := wallet.ImportFromWIF(d.tp, d.wif)
+		assert.NotNil(t, err, d.tp+" "+d.wif)
+	}
+}
+
+func TestImportFromSeckey(t *testing.T) {
+	_, teardown, err := setup(t)
+	assert.Nil(t, err)
+	defer teardown()
+
+	sec := "1fb2f3a9ab1e2fcc5c0f4f6e33c86d4a0e2d7c1b6a73ec9e1f8c0f7a5d9d2e41"
+	wlt, err := wallet.ImportFromSeckey(skycoin.Type, sec)
+	assert.Nil(t, err)
+	id := wlt.GetID()
+	addrs, err := wallet.GetAddresses(id)
+	assert.Nil(t, err)
+	assert.Len(t, addrs, 1)
+	_, s, err := wallet.GetKeypair(id, addrs[0])
+	assert.Nil(t, err)
+	assert.Equal(t, sec, s)
+	_, err = wallet.NewAddresses(id, 1)
+	assert.Equal(t, wallet.ErrImportedWallet, err)
+
+	testData := []struct {
+		tp  string
+		sec string
+	}{
+		{skycoin.Type, ""},
+		{skycoin.Type, "xyz"},
+		{skycoin.Type, sec[:62]},
+		{skycoin.Type, sec + "00"},
+		{bitcoin.Type, sec},
+	}
+	for _, d := range testData {
+		_, err := wallet.ImportFromSeckey(d.tp, d.sec)
+		assert.NotNil(t, err, d.tp+" "+d.sec)
+	}
+}
@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -30,6 +31,24 @@ func (wlts *wallets) add(wlt Walleter) error {
 	return wlts.store(wlt)
 }
 
+// addOrGet adds the wallet if neither it nor the wallet of legacyID exists, otherwise returns
+// the copy of the existing one.
+func (wlts *wallets) addOrGet(wlt Walleter, legacyID string) (Walleter, error) {
+	wlts.mtx.Lock()
+	defer wlts.mtx.Unlock()
+	for _, id := range []string{wlt.GetID(), legacyID} {
+		if w, ok := wlts.Value[id]; ok {
+			return w.Copy(), nil
+		}
+	}
+
+	wlts.Value[wlt.GetID()] = wlt
+	if err := wlts.store(wlt); err != nil {
+		return nil, err
+	}
+	return wlt.Copy(), nil
+}
+
 func (wlts *wallets) remove(id string) error {
 	wlts.mtx.Lock()
 	defer wlts.mtx.Unlock()
@@ -74,14 +93,16 @@ func (wlts *wallets) mustLoad() {
 			panic(fmt.Sprintf("%s wallet not supported", tp))
 		}
 
-		f, err := os.Open(filepath.Join(wltDir, name))
+		d, err := ioutil.ReadFile(filepath.Join(wltDir, name))
 		if err != nil {
 			panic(err)
 		}
-		defer f.Close()
 
 		wlt := newWlt()
-		if err := wlt.Load(f); err != nil {
+		if isEncrypted(d) {
+			wlt = &cryptoWallet{}
+		}
+		if err := wlt.Load(bytes.NewReader(d)); err != nil {
 			panic(err)
 		}
 		if err := wlts.add(wlt); err != nil {
@@ -116,6 +137,90 @@ func (wlts *wallets) getAddresses(id string) ([]string, error) {
 	return []string{}, fmt.Errorf("%s wallet does not exist", id)
 }
 
+func (wlts *wallets) getKeypairWithPassword(id, addr, password string) (string, string, error) {
+	wlts.mtx.Lock()
+	defer wlts.mtx.Unlock()
+	wlt, ok := wlts.Value[id]
+	if !ok {
+		return "", "", fmt.Errorf("%s wallet does not exist", id)
+	}
+
+	if cw, ok := wlt.(*cryptoWallet); ok {
+		w, err := cw.decrypt(password)
+		if err != nil {
+			return "", "", err
+		}
+		return w.GetKeypair(addr)
+	}
+	return wlt.GetKeypair(addr)
+}
+
+// encrypt encrypts the wallet with password, the plaintext backup file is removed.
+func (wlts *wallets) encrypt(id, password string) error {
+	wlts.mtx.Lock()
+	defer wlts.mtx.Unlock()
+	wlt, ok := wlts.Value[id]
+	if !ok {
+		return fmt.Errorf("%s wallet does not exist", id)
+	}
+
+	if _, ok := wlt.(*cryptoWallet); ok {
+		return ErrWalletEncrypted
+	}
+
+	cw, err := encrypt(wlt, password)
+	if err != nil {
+		return err
+	}
+
+	if err := wlts.store(cw); err != nil {
+		return err
+	}
+	wlts.Value[id] = cw
+
+	if err := os.Remove(storeAddr(cw) + ".bak"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// decrypt decrypts the wallet with password, and stores it in plaintext.
+func (wlts *wallets) decrypt(id, password string) error {
+	wlts.mtx.Lock()
+	defer wlts.mtx.Unlock()
+	wlt, ok := wlts.Value[id]
+	if !ok {
+		return fmt.Errorf("%s wallet does not exist", id)
+	}
+
+	cw, ok := wlt.(*cryptoWallet)
+	if !ok {
+		return fmt.Errorf("%s wallet is not encrypted", id)
+	}
+
+	w, err := cw.decrypt(password)
+	if err != nil {
+		return err
+	}
+
+	if err := wlts.store(w); err != nil {
+		return err
+	}
+	wlts.Value[id] = w
+	return nil
+}
+
+func (wlts *wallets) isEncrypted(id string) (bool, error) {
+	wlts.mtx.Lock()
+	defer wlts.mtx.Unlock()
+	wlt, ok := wlts.Value[id]
+	if !ok {
+		return false, fmt.Errorf("%s wallet does not exist", id)
+	}
+	_, ok = wlt.(*cryptoWallet)
+	return ok, nil
+}
+
 func (wlts *wallets) isContain(id string, addrs []string) (bool, error) {
 	wlts.mtx.Lock()
 	defer wlts.mtx.Unlock()